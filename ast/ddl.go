@@ -19,6 +19,7 @@ import (
 )
 
 var (
+	_ DDLNode = &AlterDatabaseStmt{}
 	_ DDLNode = &AlterTableStmt{}
 	_ DDLNode = &CreateDatabaseStmt{}
 	_ DDLNode = &CreateIndexStmt{}
@@ -27,6 +28,7 @@ var (
 	_ DDLNode = &DropDatabaseStmt{}
 	_ DDLNode = &DropIndexStmt{}
 	_ DDLNode = &DropTableStmt{}
+	_ DDLNode = &RecoverTableStmt{}
 	_ DDLNode = &RenameTableStmt{}
 	_ DDLNode = &TruncateTableStmt{}
 
@@ -81,6 +83,25 @@ func (n *CreateDatabaseStmt) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// AlterDatabaseStmt is a statement to change the characteristics of a database.
+// See https://dev.mysql.com/doc/refman/5.7/en/alter-database.html
+type AlterDatabaseStmt struct {
+	ddlNode
+
+	Name    string
+	Options []*DatabaseOption
+}
+
+// Accept implements Node Accept interface.
+func (n *AlterDatabaseStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*AlterDatabaseStmt)
+	return v.Leave(n)
+}
+
 // DropDatabaseStmt is a statement to drop a database and all tables in the database.
 // See https://dev.mysql.com/doc/refman/5.7/en/drop-database.html
 type DropDatabaseStmt struct {
@@ -101,11 +122,13 @@ func (n *DropDatabaseStmt) Accept(v Visitor) (Node, bool) {
 }
 
 // IndexColName is used for parsing index column name from SQL.
+// Expr is the expression of a functional key part like "((a + b))", Column is nil then.
 type IndexColName struct {
 	node
 
 	Column *ColumnName
 	Length int
+	Expr   ExprNode
 }
 
 // Accept implements Node Accept interface.
@@ -115,11 +138,20 @@ func (n *IndexColName) Accept(v Visitor) (Node, bool) {
 		return v.Leave(newNode)
 	}
 	n = newNode.(*IndexColName)
-	node, ok := n.Column.Accept(v)
-	if !ok {
-		return n, false
+	if n.Column != nil {
+		node, ok := n.Column.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Column = node.(*ColumnName)
+	}
+	if n.Expr != nil {
+		node, ok := n.Expr.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Expr = node.(ExprNode)
 	}
-	n.Column = node.(*ColumnName)
 	return v.Leave(n)
 }
 
@@ -241,6 +273,7 @@ const (
 	ColumnOptionFulltext
 	ColumnOptionComment
 	ColumnOptionGenerated
+	ColumnOptionCheck
 )
 
 // ColumnOption is used for parsing column constraint info from SQL.
@@ -249,7 +282,7 @@ type ColumnOption struct {
 
 	Tp ColumnOptionType
 	// For ColumnOptionDefaultValue or ColumnOptionOnUpdate, it's the target value.
-	// For ColumnOptionGenerated, it's the target expression.
+	// For ColumnOptionGenerated and ColumnOptionCheck, it's the target expression.
 	Expr ExprNode
 	// Stored is only for ColumnOptionGenerated, default is false.
 	Stored bool
@@ -310,6 +343,7 @@ const (
 	ConstraintUniqIndex
 	ConstraintForeignKey
 	ConstraintFulltext
+	ConstraintCheck
 )
 
 // Constraint is constraint for table definition.
@@ -324,6 +358,8 @@ type Constraint struct {
 	Refer *ReferenceDef // Used for foreign key.
 
 	Option *IndexOption // Index Options
+
+	Expr ExprNode // Used for CHECK.
 }
 
 // Accept implements Node Accept interface.
@@ -354,6 +390,13 @@ func (n *Constraint) Accept(v Visitor) (Node, bool) {
 		}
 		n.Option = node.(*IndexOption)
 	}
+	if n.Expr != nil {
+		node, ok := n.Expr.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Expr = node.(ExprNode)
+	}
 	return v.Leave(n)
 }
 
@@ -399,6 +442,8 @@ type CreateTableStmt struct {
 	Cols        []*ColumnDef
 	Constraints []*Constraint
 	Options     []*TableOption
+	// Select is the select statement of CREATE TABLE ... SELECT.
+	Select ResultSetNode
 }
 
 // Accept implements Node Accept interface.
@@ -434,6 +479,13 @@ func (n *CreateTableStmt) Accept(v Visitor) (Node, bool) {
 		}
 		n.Constraints[i] = node.(*Constraint)
 	}
+	if n.Select != nil {
+		node, ok = n.Select.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Select = node.(ResultSetNode)
+	}
 	return v.Leave(n)
 }
 
@@ -708,10 +760,24 @@ const (
 	AlterTableRenameTable
 	AlterTableAlterColumn
 	AlterTableLock
+	AlterTableRenameIndex
+	AlterTableConvertCharset
+	AlterTableDropCheck
+	AlterTableIndexVisibility
 
 // TODO: Add more actions
 )
 
+// IndexVisibility is the visibility of the index in AlterTableSpec.
+type IndexVisibility int
+
+// Index visibilities.
+const (
+	IndexVisibilityDefault IndexVisibility = iota
+	IndexVisibilityVisible
+	IndexVisibilityInvisible
+)
+
 // LockType is the type for AlterTableSpec.
 // See https://dev.mysql.com/doc/refman/5.7/en/alter-table.html#alter-table-concurrency
 type LockType byte
@@ -730,6 +796,8 @@ type AlterTableSpec struct {
 
 	Tp            AlterTableType
 	Name          string
+	FromKey       model.CIStr
+	ToKey         model.CIStr
 	Constraint    *Constraint
 	Options       []*TableOption
 	NewTable      *TableName
@@ -737,6 +805,7 @@ type AlterTableSpec struct {
 	OldColumnName *ColumnName
 	Position      *ColumnPosition
 	LockType      LockType
+	Visibility    IndexVisibility
 }
 
 // Accept implements Node Accept interface.
@@ -837,3 +906,28 @@ func (n *TruncateTableStmt) Accept(v Visitor) (Node, bool) {
 	n.Table = node.(*TableName)
 	return v.Leave(n)
 }
+
+// RecoverTableStmt is a statement to recover a dropped or truncated table,
+// it is "RECOVER TABLE t" or "FLASHBACK TABLE t TO t_new". The recovered
+// table is named NewName if it's not empty.
+type RecoverTableStmt struct {
+	ddlNode
+
+	Table   *TableName
+	NewName model.CIStr
+}
+
+// Accept implements Node Accept interface.
+func (n *RecoverTableStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*RecoverTableStmt)
+	node, ok := n.Table.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.Table = node.(*TableName)
+	return v.Leave(n)
+}
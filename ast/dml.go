@@ -474,6 +474,8 @@ type SelectStmt struct {
 	LockTp SelectLockType
 	// TableHints represents the level Optimizer Hint
 	TableHints []*TableOptimizerHint
+	// SelectIntoOpt is the select into clause, the result is written into a file or variables.
+	SelectIntoOpt *SelectIntoOption
 }
 
 // Accept implements Node Accept interface.
@@ -656,12 +658,20 @@ func (n *Assignment) Accept(v Visitor) (Node, bool) {
 type LoadDataStmt struct {
 	dmlNode
 
-	IsLocal    bool
-	Path       string
-	Table      *TableName
-	Columns    []*ColumnName
-	FieldsInfo *FieldsClause
-	LinesInfo  *LinesClause
+	IsLocal     bool
+	Path        string
+	OnDuplicate OnDuplicateKeyHandlingType
+	Table       *TableName
+	// Columns are the column names in ColumnsAndUserVars.
+	Columns []*ColumnName
+	// ColumnsAndUserVars are the columns and the user variables which the fields of the lines are assigned to.
+	ColumnsAndUserVars []*ColumnNameOrUserVar
+	// ColumnAssignments is the SET clause, the columns are assigned by the expressions of the fields.
+	ColumnAssignments []*Assignment
+	FieldsInfo        *FieldsClause
+	LinesInfo         *LinesClause
+	// IgnoreLines is the number of the lines at the start of the file which are skipped.
+	IgnoreLines uint64
 }
 
 // Accept implements Node Accept interface.
@@ -685,9 +695,67 @@ func (n *LoadDataStmt) Accept(v Visitor) (Node, bool) {
 		}
 		n.Columns[i] = node.(*ColumnName)
 	}
+	for _, val := range n.ColumnsAndUserVars {
+		if val.UserVar == nil {
+			continue
+		}
+		node, ok := val.UserVar.Accept(v)
+		if !ok {
+			return n, false
+		}
+		val.UserVar = node.(*VariableExpr)
+	}
+	for i, assign := range n.ColumnAssignments {
+		node, ok := assign.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.ColumnAssignments[i] = node.(*Assignment)
+	}
 	return v.Leave(n)
 }
 
+// OnDuplicateKeyHandlingType is the way to handle the rows whose unique keys conflict with the existing rows.
+type OnDuplicateKeyHandlingType int
+
+// OnDuplicateKeyHandling types.
+const (
+	// OnDuplicateKeyHandlingError returns an error for the duplicate rows.
+	OnDuplicateKeyHandlingError OnDuplicateKeyHandlingType = iota
+	// OnDuplicateKeyHandlingIgnore skips the duplicate rows.
+	OnDuplicateKeyHandlingIgnore
+	// OnDuplicateKeyHandlingReplace replaces the existing rows with the duplicate rows.
+	OnDuplicateKeyHandlingReplace
+)
+
+// ColumnNameOrUserVar is a column name or a user variable in the column list of load data statement.
+type ColumnNameOrUserVar struct {
+	ColumnName *ColumnName
+	UserVar    *VariableExpr
+}
+
+// SelectIntoType is the type of the select into clause.
+type SelectIntoType int
+
+// Select into types.
+const (
+	SelectIntoOutfile SelectIntoType = iota + 1
+	SelectIntoDumpfile
+	SelectIntoVars
+)
+
+// SelectIntoOption represents the "INTO OUTFILE", "INTO DUMPFILE" or "INTO var_list" clause in select statement.
+// See https://dev.mysql.com/doc/refman/5.7/en/select-into.html
+type SelectIntoOption struct {
+	Tp         SelectIntoType
+	FileName   string
+	FieldsInfo *FieldsClause
+	LinesInfo  *LinesClause
+	// Vars are the variables of SelectIntoVars, a user variable is a *VariableExpr and a local variable
+	// of a stored routine is a *ColumnNameExpr.
+	Vars []ExprNode
+}
+
 // FieldsClause represents fields references clause in load data statement.
 type FieldsClause struct {
 	Terminated string
@@ -955,6 +1023,7 @@ const (
 	ShowStatsBuckets
 	ShowPlugins
 	ShowProfiles
+	ShowFunctionStatus
 )
 
 // ShowStmt is a statement to provide information about databases, tables, columns and so on.
@@ -1007,7 +1076,7 @@ func (n *ShowStmt) Accept(v Visitor) (Node, bool) {
 	}
 
 	switch n.Tp {
-	case ShowTriggers, ShowProcedureStatus, ShowProcessList, ShowEvents:
+	case ShowTriggers, ShowProcessList, ShowEvents:
 		// We don't have any data to return for those types,
 		// but visiting Where may cause resolving error, so return here to avoid error.
 		return v.Leave(n)
@@ -141,6 +141,10 @@ func (ts *testFlagSuite) TestFlag(c *C) {
 			"-a",
 			ast.FlagHasReference,
 		},
+		{
+			"a collate utf8mb4_general_ci",
+			ast.FlagHasReference,
+		},
 	}
 	for _, tt := range flagTests {
 		stmt, err := ts.ParseOneStmt("select "+tt.expr, "", "")
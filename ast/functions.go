@@ -198,6 +198,10 @@ const (
 	Ord             = "ord"
 	Position        = "position"
 	Quote           = "quote"
+	RegexpInStr     = "regexp_instr"
+	RegexpLike      = "regexp_like"
+	RegexpReplace   = "regexp_replace"
+	RegexpSubstr    = "regexp_substr"
 	Repeat          = "repeat"
 	Replace         = "replace"
 	Reverse         = "reverse"
@@ -289,18 +293,49 @@ const (
 	ValidatePasswordStrength = "validate_password_strength"
 
 	// json functions
-	JSONType     = "json_type"
-	JSONExtract  = "json_extract"
-	JSONUnquote  = "json_unquote"
-	JSONArray    = "json_array"
-	JSONObject   = "json_object"
-	JSONMerge    = "json_merge"
-	JSONValid    = "json_valid"
-	JSONSet      = "json_set"
-	JSONInsert   = "json_insert"
-	JSONReplace  = "json_replace"
-	JSONRemove   = "json_remove"
-	JSONContains = "json_contains"
+	JSONType          = "json_type"
+	JSONExtract       = "json_extract"
+	JSONUnquote       = "json_unquote"
+	JSONArray         = "json_array"
+	JSONObject        = "json_object"
+	JSONMerge         = "json_merge"
+	JSONValid         = "json_valid"
+	JSONSet           = "json_set"
+	JSONInsert        = "json_insert"
+	JSONReplace       = "json_replace"
+	JSONRemove        = "json_remove"
+	JSONContains      = "json_contains"
+	JSONContainsPath  = "json_contains_path"
+	JSONKeys          = "json_keys"
+	JSONLength        = "json_length"
+	JSONDepth         = "json_depth"
+	JSONSearch        = "json_search"
+	JSONArrayAppend   = "json_array_append"
+	JSONArrayInsert   = "json_array_insert"
+	JSONMergePatch    = "json_merge_patch"
+	JSONMergePreserve = "json_merge_preserve"
+	JSONQuote         = "json_quote"
+	JSONPretty        = "json_pretty"
+	JSONStorageSize   = "json_storage_size"
+
+	// spatial functions
+	MBRContains        = "mbrcontains"
+	MBRWithin          = "mbrwithin"
+	Point              = "point"
+	STAsBinary         = "st_asbinary"
+	STAsText           = "st_astext"
+	STAsWKB            = "st_aswkb"
+	STAsWKT            = "st_aswkt"
+	STContains         = "st_contains"
+	STDistance         = "st_distance"
+	STGeomFromText     = "st_geomfromtext"
+	STGeomFromWKB      = "st_geomfromwkb"
+	STGeometryFromText = "st_geometryfromtext"
+	STGeometryFromWKB  = "st_geometryfromwkb"
+	STSRID             = "st_srid"
+	STWithin           = "st_within"
+	STX                = "st_x"
+	STY                = "st_y"
 )
 
 // FuncCallExpr is for function expression.
@@ -469,6 +504,10 @@ const (
 	AggFuncBitXor = "bit_xor"
 	// AggFuncBitAnd is the name of bit_and function.
 	AggFuncBitAnd = "bit_and"
+	// AggFuncJSONArrayAgg is the name of json_arrayagg function.
+	AggFuncJSONArrayAgg = "json_arrayagg"
+	// AggFuncJSONObjectAgg is the name of json_objectagg function.
+	AggFuncJSONObjectAgg = "json_objectagg"
 )
 
 // AggregateFuncExpr represents aggregate function expression.
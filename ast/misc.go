@@ -21,6 +21,7 @@ import (
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/auth"
 )
 
@@ -30,9 +31,11 @@ var (
 	_ StmtNode = &BeginStmt{}
 	_ StmtNode = &BinlogStmt{}
 	_ StmtNode = &CommitStmt{}
+	_ StmtNode = &CreateFunctionStmt{}
 	_ StmtNode = &CreateUserStmt{}
 	_ StmtNode = &DeallocateStmt{}
 	_ StmtNode = &DoStmt{}
+	_ StmtNode = &DropFunctionStmt{}
 	_ StmtNode = &ExecuteStmt{}
 	_ StmtNode = &ExplainStmt{}
 	_ StmtNode = &GrantStmt{}
@@ -537,6 +540,48 @@ func (n *DropUserStmt) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// CreateFunctionStmt creates a user-defined function which is loaded from a shared library.
+// See https://dev.mysql.com/doc/refman/5.7/en/create-function-udf.html
+type CreateFunctionStmt struct {
+	stmtNode
+
+	FunctionName model.CIStr
+	// ReturnType is one of ETString, ETInt, ETReal and ETDecimal.
+	ReturnType types.EvalType
+	SharedLib  string
+}
+
+// Accept implements Node Accept interface.
+func (n *CreateFunctionStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*CreateFunctionStmt)
+	return v.Leave(n)
+}
+
+// DropFunctionStmt drops a stored function, or a user-defined function if there isn't such a stored function.
+// See https://dev.mysql.com/doc/refman/5.7/en/drop-function-udf.html
+type DropFunctionStmt struct {
+	stmtNode
+
+	IfExists bool
+	// Schema is the database of the stored function, it's empty if the name isn't qualified.
+	Schema       model.CIStr
+	FunctionName model.CIStr
+}
+
+// Accept implements Node Accept interface.
+func (n *DropFunctionStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*DropFunctionStmt)
+	return v.Leave(n)
+}
+
 // DoStmt is the struct for DO statement.
 type DoStmt struct {
 	stmtNode
@@ -570,15 +615,27 @@ const (
 	AdminCheckTable
 	AdminShowDDLJobs
 	AdminCancelDDLJobs
+	AdminChecksumTable
+	AdminCheckIndex
+	AdminRecoverIndex
+	AdminCleanupIndex
 )
 
+// HandleRange represents a range where handle value >= Begin and <= End.
+type HandleRange struct {
+	Begin int64
+	End   int64
+}
+
 // AdminStmt is the struct for Admin statement.
 type AdminStmt struct {
 	stmtNode
 
-	Tp     AdminStmtType
-	Tables []*TableName
-	JobIDs []int64
+	Tp           AdminStmtType
+	Index        string
+	Tables       []*TableName
+	JobIDs       []int64
+	HandleRanges []HandleRange
 }
 
 // Accept implements Node Accpet interface.
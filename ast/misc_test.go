@@ -74,6 +74,8 @@ func (ts *testMiscSuite) TestMiscVisitorCover(c *C) {
 		(&VariableAssignment{Value: &ValueExpr{}}),
 		(&KillStmt{}),
 		(&DropStatsStmt{Table: &TableName{}}),
+		(&CreateFunctionStmt{}),
+		(&DropFunctionStmt{}),
 	}
 
 	for _, v := range stmts {
@@ -0,0 +1,558 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ast
+
+import (
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/auth"
+)
+
+var (
+	_ StmtNode = &CreateRoutineStmt{}
+	_ StmtNode = &DropProcedureStmt{}
+	_ StmtNode = &CallStmt{}
+	_ StmtNode = &BlockStmt{}
+	_ StmtNode = &DeclareVarStmt{}
+	_ StmtNode = &DeclareCursorStmt{}
+	_ StmtNode = &DeclareHandlerStmt{}
+	_ StmtNode = &IfStmt{}
+	_ StmtNode = &WhileStmt{}
+	_ StmtNode = &RepeatStmt{}
+	_ StmtNode = &LoopStmt{}
+	_ StmtNode = &LeaveStmt{}
+	_ StmtNode = &IterateStmt{}
+	_ StmtNode = &ReturnStmt{}
+	_ StmtNode = &OpenCursorStmt{}
+	_ StmtNode = &FetchCursorStmt{}
+	_ StmtNode = &CloseCursorStmt{}
+
+	_ Node = &RoutineParam{}
+)
+
+// RoutineType is the type of a stored routine.
+type RoutineType int
+
+// Stored routine types.
+const (
+	RoutineProcedure RoutineType = iota + 1
+	RoutineFunction
+)
+
+// String implements fmt.Stringer interface.
+func (t RoutineType) String() string {
+	if t == RoutineFunction {
+		return "FUNCTION"
+	}
+	return "PROCEDURE"
+}
+
+// ParamMode is the mode of a parameter of a stored procedure.
+type ParamMode int
+
+// Parameter modes.
+const (
+	ParamIn ParamMode = iota
+	ParamOut
+	ParamInOut
+)
+
+// String implements fmt.Stringer interface.
+func (m ParamMode) String() string {
+	switch m {
+	case ParamOut:
+		return "OUT"
+	case ParamInOut:
+		return "INOUT"
+	default:
+		return "IN"
+	}
+}
+
+// SQL data access characteristics of the stored routines.
+const (
+	ContainsSQL     = "CONTAINS SQL"
+	NoSQL           = "NO SQL"
+	ReadsSQLData    = "READS SQL DATA"
+	ModifiesSQLData = "MODIFIES SQL DATA"
+)
+
+// RoutineParam is a parameter of a stored routine.
+type RoutineParam struct {
+	node
+
+	Mode ParamMode
+	Name string
+	Tp   *types.FieldType
+}
+
+// Accept implements Node Accept interface.
+func (n *RoutineParam) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*RoutineParam)
+	return v.Leave(n)
+}
+
+// CreateRoutineStmt is a statement to create a stored procedure or a stored function.
+// See https://dev.mysql.com/doc/refman/5.7/en/create-procedure.html
+type CreateRoutineStmt struct {
+	stmtNode
+
+	Tp      RoutineType
+	Definer *auth.UserIdentity
+	Schema  model.CIStr
+	Name    model.CIStr
+	Params  []*RoutineParam
+	// ParamsText is the source text of the parameter list.
+	ParamsText string
+	// ReturnType is the type of the value returned by a stored function.
+	ReturnType *types.FieldType
+
+	Comment       string
+	Deterministic bool
+	// DataAccess is one of ContainsSQL, NoSQL, ReadsSQLData and ModifiesSQLData.
+	DataAccess string
+	// Security is DEFINER or INVOKER.
+	Security string
+
+	Body StmtNode
+	// BodyText is the source text of Body.
+	BodyText string
+}
+
+// Accept implements Node Accept interface.
+// The body isn't visited, it's checked and executed statement by statement when the routine is called.
+func (n *CreateRoutineStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*CreateRoutineStmt)
+	return v.Leave(n)
+}
+
+// DropProcedureStmt is a statement to drop a stored procedure.
+// See https://dev.mysql.com/doc/refman/5.7/en/drop-procedure.html
+type DropProcedureStmt struct {
+	stmtNode
+
+	IfExists bool
+	Schema   model.CIStr
+	Name     model.CIStr
+}
+
+// Accept implements Node Accept interface.
+func (n *DropProcedureStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*DropProcedureStmt)
+	return v.Leave(n)
+}
+
+// CallStmt is a statement to call a stored procedure.
+// See https://dev.mysql.com/doc/refman/5.7/en/call.html
+type CallStmt struct {
+	stmtNode
+
+	Schema model.CIStr
+	Name   model.CIStr
+	Args   []ExprNode
+}
+
+// Accept implements Node Accept interface.
+func (n *CallStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*CallStmt)
+	for i, val := range n.Args {
+		node, ok := val.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Args[i] = node.(ExprNode)
+	}
+	return v.Leave(n)
+}
+
+// acceptStmts visits the statements of a compound statement.
+func acceptStmts(v Visitor, stmts []StmtNode) bool {
+	for i, stmt := range stmts {
+		node, ok := stmt.Accept(v)
+		if !ok {
+			return false
+		}
+		stmts[i] = node.(StmtNode)
+	}
+	return true
+}
+
+// BlockStmt is a BEGIN ... END compound statement of a stored routine, the declarations are
+// at the head of Stmts.
+// See https://dev.mysql.com/doc/refman/5.7/en/begin-end.html
+type BlockStmt struct {
+	stmtNode
+
+	Label string
+	Stmts []StmtNode
+}
+
+// Accept implements Node Accept interface.
+func (n *BlockStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*BlockStmt)
+	if !acceptStmts(v, n.Stmts) {
+		return n, false
+	}
+	return v.Leave(n)
+}
+
+// DeclareVarStmt declares local variables in a BEGIN ... END block.
+// See https://dev.mysql.com/doc/refman/5.7/en/declare-local-variable.html
+type DeclareVarStmt struct {
+	stmtNode
+
+	Names []string
+	Tp    *types.FieldType
+	// Default is the initial value of the variables, they are NULL if it's nil.
+	Default ExprNode
+}
+
+// Accept implements Node Accept interface.
+func (n *DeclareVarStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*DeclareVarStmt)
+	if n.Default != nil {
+		node, ok := n.Default.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Default = node.(ExprNode)
+	}
+	return v.Leave(n)
+}
+
+// DeclareCursorStmt declares a cursor in a BEGIN ... END block.
+// See https://dev.mysql.com/doc/refman/5.7/en/declare-cursor.html
+type DeclareCursorStmt struct {
+	stmtNode
+
+	Name string
+	// Select is a SelectStmt or a UnionStmt.
+	Select StmtNode
+}
+
+// Accept implements Node Accept interface.
+func (n *DeclareCursorStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*DeclareCursorStmt)
+	node, ok := n.Select.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.Select = node.(StmtNode)
+	return v.Leave(n)
+}
+
+// HandlerAction is the action of a handler after its statement is executed.
+type HandlerAction int
+
+// Handler actions.
+const (
+	// HandlerContinue continues with the statement after the one which raised the condition.
+	HandlerContinue HandlerAction = iota
+	// HandlerExit leaves the BEGIN ... END block in which the handler is declared.
+	HandlerExit
+)
+
+// HandlerConditionType is the type of a condition of a handler.
+type HandlerConditionType int
+
+// Handler condition types.
+const (
+	HandlerErrorCode HandlerConditionType = iota
+	HandlerSQLState
+	HandlerSQLWarning
+	HandlerNotFound
+	HandlerSQLException
+)
+
+// HandlerCondition is a condition which activates a handler.
+type HandlerCondition struct {
+	Tp HandlerConditionType
+	// ErrorCode is the MySQL error code of HandlerErrorCode.
+	ErrorCode uint16
+	// SQLState is the SQLSTATE value of HandlerSQLState.
+	SQLState string
+}
+
+// DeclareHandlerStmt declares a handler in a BEGIN ... END block.
+// See https://dev.mysql.com/doc/refman/5.7/en/declare-handler.html
+type DeclareHandlerStmt struct {
+	stmtNode
+
+	Action     HandlerAction
+	Conditions []*HandlerCondition
+	Stmt       StmtNode
+}
+
+// Accept implements Node Accept interface.
+func (n *DeclareHandlerStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*DeclareHandlerStmt)
+	node, ok := n.Stmt.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.Stmt = node.(StmtNode)
+	return v.Leave(n)
+}
+
+// IfStmt is an IF statement of a stored routine, an ELSEIF branch is an IfStmt in Else.
+// See https://dev.mysql.com/doc/refman/5.7/en/if.html
+type IfStmt struct {
+	stmtNode
+
+	Cond ExprNode
+	Then []StmtNode
+	Else []StmtNode
+}
+
+// Accept implements Node Accept interface.
+func (n *IfStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*IfStmt)
+	node, ok := n.Cond.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.Cond = node.(ExprNode)
+	if !acceptStmts(v, n.Then) || !acceptStmts(v, n.Else) {
+		return n, false
+	}
+	return v.Leave(n)
+}
+
+// WhileStmt is a WHILE loop of a stored routine.
+// See https://dev.mysql.com/doc/refman/5.7/en/while.html
+type WhileStmt struct {
+	stmtNode
+
+	Label string
+	Cond  ExprNode
+	Body  []StmtNode
+}
+
+// Accept implements Node Accept interface.
+func (n *WhileStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*WhileStmt)
+	node, ok := n.Cond.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.Cond = node.(ExprNode)
+	if !acceptStmts(v, n.Body) {
+		return n, false
+	}
+	return v.Leave(n)
+}
+
+// RepeatStmt is a REPEAT loop of a stored routine.
+// See https://dev.mysql.com/doc/refman/5.7/en/repeat.html
+type RepeatStmt struct {
+	stmtNode
+
+	Label string
+	Body  []StmtNode
+	Until ExprNode
+}
+
+// Accept implements Node Accept interface.
+func (n *RepeatStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*RepeatStmt)
+	if !acceptStmts(v, n.Body) {
+		return n, false
+	}
+	node, ok := n.Until.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.Until = node.(ExprNode)
+	return v.Leave(n)
+}
+
+// LoopStmt is a LOOP of a stored routine, it only ends with LEAVE or RETURN.
+// See https://dev.mysql.com/doc/refman/5.7/en/loop.html
+type LoopStmt struct {
+	stmtNode
+
+	Label string
+	Body  []StmtNode
+}
+
+// Accept implements Node Accept interface.
+func (n *LoopStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*LoopStmt)
+	if !acceptStmts(v, n.Body) {
+		return n, false
+	}
+	return v.Leave(n)
+}
+
+// LeaveStmt leaves the labeled block or loop.
+// See https://dev.mysql.com/doc/refman/5.7/en/leave.html
+type LeaveStmt struct {
+	stmtNode
+
+	Label string
+}
+
+// Accept implements Node Accept interface.
+func (n *LeaveStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*LeaveStmt)
+	return v.Leave(n)
+}
+
+// IterateStmt starts the next iteration of the labeled loop.
+// See https://dev.mysql.com/doc/refman/5.7/en/iterate.html
+type IterateStmt struct {
+	stmtNode
+
+	Label string
+}
+
+// Accept implements Node Accept interface.
+func (n *IterateStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*IterateStmt)
+	return v.Leave(n)
+}
+
+// ReturnStmt returns the value of a stored function.
+// See https://dev.mysql.com/doc/refman/5.7/en/return.html
+type ReturnStmt struct {
+	stmtNode
+
+	Expr ExprNode
+}
+
+// Accept implements Node Accept interface.
+func (n *ReturnStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*ReturnStmt)
+	node, ok := n.Expr.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.Expr = node.(ExprNode)
+	return v.Leave(n)
+}
+
+// OpenCursorStmt opens a cursor.
+// See https://dev.mysql.com/doc/refman/5.7/en/open.html
+type OpenCursorStmt struct {
+	stmtNode
+
+	Name string
+}
+
+// Accept implements Node Accept interface.
+func (n *OpenCursorStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*OpenCursorStmt)
+	return v.Leave(n)
+}
+
+// FetchCursorStmt fetches the next row of a cursor into local variables.
+// See https://dev.mysql.com/doc/refman/5.7/en/fetch.html
+type FetchCursorStmt struct {
+	stmtNode
+
+	Name string
+	Vars []string
+}
+
+// Accept implements Node Accept interface.
+func (n *FetchCursorStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*FetchCursorStmt)
+	return v.Leave(n)
+}
+
+// CloseCursorStmt closes a cursor.
+// See https://dev.mysql.com/doc/refman/5.7/en/close.html
+type CloseCursorStmt struct {
+	stmtNode
+
+	Name string
+}
+
+// Accept implements Node Accept interface.
+func (n *CloseCursorStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*CloseCursorStmt)
+	return v.Leave(n)
+}
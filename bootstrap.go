@@ -26,12 +26,16 @@ import (
 	"time"
 
 	"github.com/juju/errors"
+	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/ddl"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/auth"
+	"github.com/pingcap/tidb/util/collate"
+	"github.com/pingcap/tidb/util/timeutil"
 	log "github.com/sirupsen/logrus"
 	goctx "golang.org/x/net/context"
 )
@@ -49,6 +53,7 @@ const (
 		Create_priv			ENUM('N','Y') NOT NULL DEFAULT 'N',
 		Drop_priv			ENUM('N','Y') NOT NULL DEFAULT 'N',
 		Process_priv			ENUM('N','Y') NOT NULL DEFAULT 'N',
+		File_priv			ENUM('N','Y') NOT NULL DEFAULT 'N',
 		Grant_priv			ENUM('N','Y') NOT NULL DEFAULT 'N',
 		References_priv			ENUM('N','Y') NOT NULL DEFAULT 'N',
 		Alter_priv			ENUM('N','Y') NOT NULL DEFAULT 'N',
@@ -186,6 +191,81 @@ const (
 		UNIQUE KEY (element_id),
 		KEY (job_id, element_id)
 	);`
+
+	// CreateTimeZoneNameTable maps the names of the time zones to their IDs.
+	// The time zone tables are populated from the embedded tz database at bootstrap, the tools like
+	// mysql_tzinfo_to_sql can replace the rows.
+	CreateTimeZoneNameTable = `CREATE TABLE IF NOT EXISTS mysql.time_zone_name (
+		Name CHAR(64) NOT NULL,
+		Time_zone_id INT UNSIGNED NOT NULL,
+		PRIMARY KEY (Name)
+	);`
+
+	// CreateTimeZoneTable stores the time zones.
+	CreateTimeZoneTable = `CREATE TABLE IF NOT EXISTS mysql.time_zone (
+		Time_zone_id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+		Use_leap_seconds ENUM('Y','N') NOT NULL DEFAULT 'N',
+		PRIMARY KEY (Time_zone_id)
+	);`
+
+	// CreateTimeZoneTransitionTable stores the times since when the local time types are used.
+	CreateTimeZoneTransitionTable = `CREATE TABLE IF NOT EXISTS mysql.time_zone_transition (
+		Time_zone_id INT UNSIGNED NOT NULL,
+		Transition_time BIGINT SIGNED NOT NULL,
+		Transition_type_id INT UNSIGNED NOT NULL,
+		PRIMARY KEY (Time_zone_id, Transition_time)
+	);`
+
+	// CreateTimeZoneTransitionTypeTable stores the local time types of the time zones.
+	CreateTimeZoneTransitionTypeTable = `CREATE TABLE IF NOT EXISTS mysql.time_zone_transition_type (
+		Time_zone_id INT UNSIGNED NOT NULL,
+		Transition_type_id INT UNSIGNED NOT NULL,
+		Offset INT SIGNED NOT NULL DEFAULT 0,
+		Is_DST TINYINT UNSIGNED NOT NULL DEFAULT 0,
+		Abbreviation CHAR(8) NOT NULL DEFAULT '',
+		PRIMARY KEY (Time_zone_id, Transition_type_id)
+	);`
+
+	// CreateTimeZoneLeapSecondTable stores the leap seconds, it's kept for compatibility and the
+	// leap seconds are ignored.
+	CreateTimeZoneLeapSecondTable = `CREATE TABLE IF NOT EXISTS mysql.time_zone_leap_second (
+		Transition_time BIGINT SIGNED NOT NULL,
+		Correction INT SIGNED NOT NULL,
+		PRIMARY KEY (Transition_time)
+	);`
+
+	// CreateFuncTable stores the user-defined functions, ret is the type of the return value and dl is the plugin.
+	CreateFuncTable = `CREATE TABLE IF NOT EXISTS mysql.func (
+		name CHAR(64) NOT NULL DEFAULT '',
+		ret TINYINT(1) NOT NULL DEFAULT 0,
+		dl CHAR(128) NOT NULL DEFAULT '',
+		type ENUM('function','aggregate') NOT NULL,
+		PRIMARY KEY (name)
+	);`
+
+	// CreateProcTable stores the stored procedures and functions like the mysql.proc table of MySQL,
+	// definition is the CREATE statement which is parsed again when the routine is called.
+	CreateProcTable = `CREATE TABLE IF NOT EXISTS mysql.proc (
+		db CHAR(64) NOT NULL DEFAULT '',
+		name CHAR(64) NOT NULL DEFAULT '',
+		type ENUM('FUNCTION','PROCEDURE') NOT NULL,
+		sql_data_access ENUM('CONTAINS_SQL','NO_SQL','READS_SQL_DATA','MODIFIES_SQL_DATA') NOT NULL DEFAULT 'CONTAINS_SQL',
+		is_deterministic ENUM('YES','NO') NOT NULL DEFAULT 'NO',
+		security_type ENUM('INVOKER','DEFINER') NOT NULL DEFAULT 'DEFINER',
+		param_list BLOB NOT NULL,
+		returns LONGBLOB NOT NULL,
+		body LONGBLOB NOT NULL,
+		definer CHAR(93) NOT NULL DEFAULT '',
+		created TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		modified TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		sql_mode VARCHAR(8192) NOT NULL DEFAULT '',
+		comment TEXT NOT NULL,
+		character_set_client CHAR(32),
+		collation_connection CHAR(32),
+		db_collation CHAR(32),
+		definition LONGBLOB NOT NULL,
+		PRIMARY KEY (db, name, type)
+	);`
 )
 
 // bootstrap initiates system DB for a store.
@@ -212,6 +292,9 @@ const (
 	// The variable name in mysql.TiDB table.
 	// It is used for getting the version of the TiDB server which bootstrapped the store.
 	tidbServerVersionVar = "tidb_server_version" //
+	// The variable name in mysql.TiDB table.
+	// It records whether the strings are compared by their collations, it's decided when the store is bootstrapped.
+	tidbNewCollationEnabled = "new_collation_enabled"
 	// Const for TiDB server version 2.
 	version2  = 2
 	version3  = 3
@@ -228,6 +311,10 @@ const (
 	version14 = 14
 	version15 = 15
 	version16 = 16
+	version17 = 17
+	version18 = 18
+	version19 = 19
+	version20 = 20
 )
 
 func checkBootstrapped(s Session) (bool, error) {
@@ -279,6 +366,84 @@ func getTiDBVar(s Session, name string) (sVal string, isNull bool, e error) {
 	return row.GetString(0), false, nil
 }
 
+// loadNewCollationEnabled enables the new collations if the store is bootstrapped with them,
+// the stores which are bootstrapped by the older versions don't have the variable.
+func loadNewCollationEnabled(s Session) error {
+	sVal, isNull, err := getTiDBVar(s, tidbNewCollationEnabled)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	collate.SetNewCollationEnabled(!isNull && sVal == bootstrappedVarTrue)
+	return errors.Trace(s.CommitTxn(goctx.Background()))
+}
+
+// loadTimeZones loads the time zones in the mysql.time_zone* tables, they take precedence over the
+// embedded tz database. Like MySQL, the changes of the tables take effect after restarting.
+func loadTimeZones(s Session) error {
+	tzTypes := make(map[uint64][]timeutil.TransitionType)
+	err := iterSystemTable(s, "SELECT Time_zone_id, Transition_type_id, Offset, Is_DST, Abbreviation FROM mysql.time_zone_transition_type",
+		func(row types.Row) {
+			id := row.GetUint64(0)
+			tzTypes[id] = append(tzTypes[id], timeutil.TransitionType{
+				ID:           uint32(row.GetUint64(1)),
+				Offset:       int32(row.GetInt64(2)),
+				IsDST:        row.GetUint64(3) != 0,
+				Abbreviation: row.GetString(4),
+			})
+		})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	transitions := make(map[uint64][]timeutil.Transition)
+	err = iterSystemTable(s, "SELECT Time_zone_id, Transition_time, Transition_type_id FROM mysql.time_zone_transition",
+		func(row types.Row) {
+			id := row.GetUint64(0)
+			transitions[id] = append(transitions[id], timeutil.Transition{
+				Time:   row.GetInt64(1),
+				TypeID: uint32(row.GetUint64(2)),
+			})
+		})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	locs := make(map[string]*time.Location)
+	err = iterSystemTable(s, "SELECT Name, Time_zone_id FROM mysql.time_zone_name", func(row types.Row) {
+		name, id := row.GetString(0), row.GetUint64(1)
+		loc, err1 := timeutil.NewLocation(name, tzTypes[id], transitions[id])
+		if err1 != nil {
+			log.Warnf("[bootstrap] load time zone %s failed: %v", name, err1)
+			return
+		}
+		locs[name] = loc
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	timeutil.SetLoadedLocations(locs)
+	return errors.Trace(s.CommitTxn(goctx.Background()))
+}
+
+// iterSystemTable calls fn on every row of the query.
+func iterSystemTable(s Session, sql string, fn func(row types.Row)) error {
+	goCtx := goctx.Background()
+	rs, err := s.Execute(goCtx, sql)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	r := rs[0]
+	defer terror.Call(r.Close)
+	for {
+		row, err1 := r.Next(goCtx)
+		if err1 != nil {
+			return errors.Trace(err1)
+		}
+		if row == nil {
+			return nil
+		}
+		fn(row)
+	}
+}
+
 // upgrade function  will do some upgrade works, when the system is boostrapped by low version TiDB server
 // For example, add new system variables into mysql.global_variables table.
 func upgrade(s Session) {
@@ -348,6 +513,22 @@ func upgrade(s Session) {
 		upgradeToVer16(s)
 	}
 
+	if ver < version17 {
+		upgradeToVer17(s)
+	}
+
+	if ver < version18 {
+		upgradeToVer18(s)
+	}
+
+	if ver < version19 {
+		upgradeToVer19(s)
+	}
+
+	if ver < version20 {
+		upgradeToVer20(s)
+	}
+
 	updateBootstrapVer(s)
 	_, err = s.Execute(goctx.Background(), "COMMIT")
 
@@ -552,6 +733,69 @@ func upgradeToVer16(s Session) {
 	doReentrantDDL(s, "ALTER TABLE mysql.stats_histograms ADD COLUMN `cm_sketch` blob", infoschema.ErrColumnExists)
 }
 
+func upgradeToVer17(s Session) {
+	doReentrantDDL(s, "ALTER TABLE mysql.user ADD COLUMN `File_priv` enum('N','Y') CHARACTER SET utf8 NOT NULL DEFAULT 'N' AFTER `Process_priv`", infoschema.ErrColumnExists)
+	// The users who can do everything before the privilege is checked keep the privilege.
+	mustExecute(s, "UPDATE mysql.user SET File_priv='Y' WHERE Super_priv='Y'")
+}
+
+func upgradeToVer18(s Session) {
+	mustExecute(s, CreateFuncTable)
+}
+
+func upgradeToVer19(s Session) {
+	mustExecute(s, CreateProcTable)
+}
+
+func upgradeToVer20(s Session) {
+	mustExecute(s, CreateTimeZoneNameTable)
+	mustExecute(s, CreateTimeZoneTable)
+	mustExecute(s, CreateTimeZoneTransitionTable)
+	mustExecute(s, CreateTimeZoneTransitionTypeTable)
+	mustExecute(s, CreateTimeZoneLeapSecondTable)
+	populateTimeZoneTables(s)
+}
+
+// populateTimeZoneTables writes the time zones of the embedded tz database into the mysql.time_zone*
+// tables like mysql_tzinfo_to_sql. The rows which exist are kept, so it can be done again.
+func populateTimeZoneTables(s Session) {
+	var zones, names, tzTypes, transitions []string
+	for i, info := range timeutil.EmbeddedTimeZones() {
+		id := i + 1
+		zones = append(zones, fmt.Sprintf("(%d, 'N')", id))
+		for _, name := range info.Names {
+			names = append(names, fmt.Sprintf("('%s', %d)", name, id))
+		}
+		for _, tp := range info.Types {
+			isDST := 0
+			if tp.IsDST {
+				isDST = 1
+			}
+			tzTypes = append(tzTypes, fmt.Sprintf("(%d, %d, %d, %d, '%s')", id, tp.ID, tp.Offset, isDST, tp.Abbreviation))
+		}
+		for _, tr := range info.Transitions {
+			transitions = append(transitions, fmt.Sprintf("(%d, %d, %d)", id, tr.Time, tr.TypeID))
+		}
+	}
+	insertIgnoreRows(s, "mysql.time_zone (Time_zone_id, Use_leap_seconds)", zones)
+	insertIgnoreRows(s, "mysql.time_zone_name (Name, Time_zone_id)", names)
+	insertIgnoreRows(s, "mysql.time_zone_transition_type (Time_zone_id, Transition_type_id, Offset, Is_DST, Abbreviation)", tzTypes)
+	insertIgnoreRows(s, "mysql.time_zone_transition (Time_zone_id, Transition_time, Transition_type_id)", transitions)
+}
+
+// insertIgnoreRows inserts the rows into the table in batches.
+func insertIgnoreRows(s Session, table string, rows []string) {
+	const batchSize = 1024
+	for len(rows) > 0 {
+		n := len(rows)
+		if n > batchSize {
+			n = batchSize
+		}
+		mustExecute(s, fmt.Sprintf("INSERT IGNORE INTO %s VALUES %s", table, strings.Join(rows[:n], ", ")))
+		rows = rows[n:]
+	}
+}
+
 // updateBootstrapVer updates bootstrap version variable in mysql.TiDB table.
 func updateBootstrapVer(s Session) {
 	// Update bootstrap version.
@@ -598,6 +842,16 @@ func doDDLWorks(s Session) {
 	mustExecute(s, CreateStatsBucketsTable)
 	// Create gc_delete_range table.
 	mustExecute(s, CreateGCDeleteRangeTable)
+	// Create time zone tables.
+	mustExecute(s, CreateTimeZoneNameTable)
+	mustExecute(s, CreateTimeZoneTable)
+	mustExecute(s, CreateTimeZoneTransitionTable)
+	mustExecute(s, CreateTimeZoneTransitionTypeTable)
+	mustExecute(s, CreateTimeZoneLeapSecondTable)
+	// Create user-defined function table.
+	mustExecute(s, CreateFuncTable)
+	// Create stored routine table.
+	mustExecute(s, CreateProcTable)
 }
 
 // doDMLWorks executes DML statements in bootstrap stage.
@@ -607,7 +861,7 @@ func doDMLWorks(s Session) {
 
 	// Insert a default user with empty password.
 	mustExecute(s, `INSERT INTO mysql.user VALUES
-		("%", "root", "", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y")`)
+		("%", "root", "", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y")`)
 
 	// Init global system variables table.
 	values := make([]string, 0, len(variable.SysVars))
@@ -631,6 +885,16 @@ func doDMLWorks(s Session) {
 		mysql.SystemDB, mysql.TiDBTable, tidbServerVersionVar, currentBootstrapVersion)
 	mustExecute(s, sql)
 
+	newCollationEnabled := "False"
+	if config.GetGlobalConfig().NewCollationsEnabledOnFirstBootstrap {
+		newCollationEnabled = bootstrappedVarTrue
+	}
+	sql = fmt.Sprintf(`INSERT INTO %s.%s VALUES("%s", "%s", "If the new collations are enabled. Do not edit it.")`,
+		mysql.SystemDB, mysql.TiDBTable, tidbNewCollationEnabled, newCollationEnabled)
+	mustExecute(s, sql)
+
+	populateTimeZoneTables(s)
+
 	_, err := s.Execute(goctx.Background(), "COMMIT")
 	if err != nil {
 		time.Sleep(1 * time.Second)
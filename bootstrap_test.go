@@ -26,6 +26,7 @@ import (
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util/auth"
 	"github.com/pingcap/tidb/util/testleak"
+	"github.com/pingcap/tidb/util/timeutil"
 	goctx "golang.org/x/net/context"
 )
 
@@ -54,7 +55,7 @@ func (s *testBootstrapSuite) TestBootstrap(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(row, NotNil)
 	datums := ast.RowToDatums(row, r.Fields())
-	match(c, datums, []byte("%"), []byte("root"), []byte(""), "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y")
+	match(c, datums, []byte("%"), []byte("root"), []byte(""), "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y")
 
 	c.Assert(se.Auth(&auth.UserIdentity{Username: "root", Hostname: "anyhost"}, []byte(""), []byte("")), IsTrue)
 	mustExecSQL(c, se, "USE test;")
@@ -68,6 +69,11 @@ func (s *testBootstrapSuite) TestBootstrap(c *C) {
 	v, err := r.Next(goCtx)
 	c.Assert(err, IsNil)
 	c.Assert(v.GetInt64(0), Equals, globalVarsCount())
+	// The new collations aren't enabled by default.
+	r = mustExecSQL(c, se, `SELECT VARIABLE_VALUE from mysql.tidb where VARIABLE_NAME = "new_collation_enabled";`)
+	v, err = r.Next(goCtx)
+	c.Assert(err, IsNil)
+	match(c, ast.RowToDatums(v, r.Fields()), []byte("False"))
 
 	// Check a storage operations are default autocommit after the second start.
 	mustExecSQL(c, se, "USE test;")
@@ -233,6 +239,47 @@ func (s *testBootstrapSuite) TestUpgrade(c *C) {
 	c.Assert(ver, Equals, int64(currentBootstrapVersion))
 }
 
+func (s *testBootstrapSuite) TestLoadTimeZones(c *C) {
+	defer testleak.AfterTest(c)()
+	store, dom := newStoreWithBootstrap(c, s.dbName)
+	defer store.Close()
+	defer dom.Close()
+	se := newSession(c, store, s.dbName)
+	// The tables are populated from the embedded tz database, America/New_York switches to EDT at
+	// 2017-03-12 07:00:00 UTC.
+	r := mustExecSQL(c, se, `SELECT tt.Offset, tt.Is_DST, tt.Abbreviation FROM mysql.time_zone_name n, mysql.time_zone_transition t,
+		mysql.time_zone_transition_type tt WHERE n.Name = 'America/New_York' AND t.Time_zone_id = n.Time_zone_id
+		AND t.Transition_time = 1489302000 AND tt.Time_zone_id = t.Time_zone_id AND tt.Transition_type_id = t.Transition_type_id`)
+	row, err := r.Next(goctx.Background())
+	c.Assert(err, IsNil)
+	c.Assert(row, NotNil)
+	match(c, ast.RowToDatums(row, r.Fields()), -14400, 1, []byte("EDT"))
+	c.Assert(r.Close(), IsNil)
+	r = mustExecSQL(c, se, `SELECT COUNT(*) FROM mysql.time_zone_name n1, mysql.time_zone_name n2
+		WHERE n1.Name = 'US/Eastern' AND n2.Name = 'America/New_York' AND n1.Time_zone_id = n2.Time_zone_id`)
+	row, err = r.Next(goctx.Background())
+	c.Assert(err, IsNil)
+	match(c, ast.RowToDatums(row, r.Fields()), 1)
+	c.Assert(r.Close(), IsNil)
+
+	// The rows are written like what mysql_tzinfo_to_sql writes, the time zone is 1 hour ahead of UTC,
+	// and 2 hours since 2017-03-26 01:00:00 UTC.
+	mustExecSQL(c, se, `INSERT INTO mysql.time_zone (Time_zone_id, Use_leap_seconds) VALUES (10000, 'N')`)
+	mustExecSQL(c, se, `INSERT INTO mysql.time_zone_name (Name, Time_zone_id) VALUES ('Test/Zone', 10000)`)
+	mustExecSQL(c, se, `INSERT INTO mysql.time_zone_transition_type (Time_zone_id, Transition_type_id, Offset, Is_DST, Abbreviation)
+		VALUES (10000, 0, 3600, 0, 'TST'), (10000, 1, 7200, 1, 'TDT')`)
+	mustExecSQL(c, se, `INSERT INTO mysql.time_zone_transition (Time_zone_id, Transition_time, Transition_type_id) VALUES (10000, 1490490000, 1)`)
+	c.Assert(loadTimeZones(se), IsNil)
+	defer timeutil.SetLoadedLocations(nil)
+
+	r = mustExecSQL(c, se, `SELECT CONVERT_TZ('2017-03-26 00:59:59', '+00:00', 'test/zone'), CONVERT_TZ('2017-03-26 01:00:00', '+00:00', 'Test/Zone'),
+		CONVERT_TZ('2017-03-12 06:59:59', '+00:00', 'America/New_York'), CONVERT_TZ('2017-03-12 07:00:00', '+00:00', 'America/New_York')`)
+	row, err = r.Next(goctx.Background())
+	c.Assert(err, IsNil)
+	match(c, ast.RowToDatums(row, r.Fields()), "2017-03-26 01:59:59", "2017-03-26 03:00:00", "2017-03-12 01:59:59", "2017-03-12 03:00:00")
+	mustExecSQL(c, se, `SET time_zone = 'Test/Zone'`)
+}
+
 func (s *testBootstrapSuite) TestOldPasswordUpgrade(c *C) {
 	defer testleak.AfterTest(c)()
 	pwd := "abc"
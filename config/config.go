@@ -38,6 +38,11 @@ type Config struct {
 	SplitTable   bool   `toml:"split-table" json:"split-table"`
 	TokenLimit   int    `toml:"token-limit" json:"token-limit"`
 	EnableChunk  bool   `toml:"enable-chunk" json:"enable-chunk"`
+	// NewCollationsEnabledOnFirstBootstrap enables the collations when the cluster is bootstrapped,
+	// the clusters which are already bootstrapped aren't affected.
+	NewCollationsEnabledOnFirstBootstrap bool `toml:"new-collations-enabled-on-first-bootstrap" json:"new-collations-enabled-on-first-bootstrap"`
+	// PluginDir is the directory of the plugins which implement the user-defined functions.
+	PluginDir string `toml:"plugin-dir" json:"plugin-dir"`
 
 	Log               Log               `toml:"log" json:"log"`
 	Security          Security          `toml:"security" json:"security"`
@@ -76,6 +81,7 @@ type Security struct {
 	ClusterSSLCA   string `toml:"cluster-ssl-ca" json:"cluster-ssl-ca"`
 	ClusterSSLCert string `toml:"cluster-ssl-cert" json:"cluster-ssl-cert"`
 	ClusterSSLKey  string `toml:"cluster-ssl-key" json:"cluster-ssl-key"`
+	SecureFilePriv string `toml:"secure-file-priv" json:"secure-file-priv"`
 }
 
 // ToTLSConfig generates tls's config based on security section of the config.
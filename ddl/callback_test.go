@@ -23,10 +23,11 @@ import (
 type TestDDLCallback struct {
 	*BaseCallback
 
-	onJobRunBefore       func(*model.Job)
-	onJobUpdated         func(*model.Job)
-	OnJobUpdatedExported func(*model.Job)
-	onWatched            func(ctx goctx.Context)
+	onJobRunBefore         func(*model.Job)
+	OnJobRunBeforeExported func(*model.Job)
+	onJobUpdated           func(*model.Job)
+	OnJobUpdatedExported   func(*model.Job)
+	onWatched              func(ctx goctx.Context)
 }
 
 func (tc *TestDDLCallback) OnJobRunBefore(job *model.Job) {
@@ -34,6 +35,10 @@ func (tc *TestDDLCallback) OnJobRunBefore(job *model.Job) {
 		tc.onJobRunBefore(job)
 		return
 	}
+	if tc.OnJobRunBeforeExported != nil {
+		tc.OnJobRunBeforeExported(job)
+		return
+	}
 
 	tc.BaseCallback.OnJobRunBefore(job)
 }
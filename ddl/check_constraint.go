@@ -0,0 +1,455 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/table/tables"
+	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/types"
+	log "github.com/sirupsen/logrus"
+)
+
+// checkConstraintDisallowedFuncs are the non-deterministic functions which can't be used in CHECK constraints.
+var checkConstraintDisallowedFuncs = map[string]struct{}{
+	ast.Rand:         {},
+	ast.UUID:         {},
+	ast.Sleep:        {},
+	ast.FoundRows:    {},
+	ast.RowCount:     {},
+	ast.GetLock:      {},
+	ast.ReleaseLock:  {},
+	ast.SetVar:       {},
+	ast.GetVar:       {},
+	ast.LastInsertId: {},
+}
+
+// checkConstraintChecker collects the columns referred in the expression of a
+// CHECK constraint or an expression index, and checks the expression is deterministic.
+type checkConstraintChecker struct {
+	name string
+	cols []*ast.ColumnName
+	err  error
+	// errNotAllowed and errVariables are returned for the disallowed functions and the variables.
+	errNotAllowed *terror.Error
+	errVariables  *terror.Error
+}
+
+func (c *checkConstraintChecker) Enter(inNode ast.Node) (outNode ast.Node, skipChildren bool) {
+	switch x := inNode.(type) {
+	case *ast.SubqueryExpr, *ast.ExistsSubqueryExpr, *ast.AggregateFuncExpr, *ast.ParamMarkerExpr,
+		*ast.ValuesExpr, *ast.DefaultExpr:
+		c.err = c.errNotAllowed.GenByArgs(c.name)
+	case *ast.VariableExpr:
+		c.err = c.errVariables.GenByArgs(c.name)
+	case *ast.FuncCallExpr:
+		_, disallowed := checkConstraintDisallowedFuncs[x.FnName.L]
+		if _, ok := expression.UnCacheableFunctions[x.FnName.L]; ok || disallowed {
+			c.err = c.errNotAllowed.GenByArgs(c.name)
+		}
+	case *ast.ColumnName:
+		c.cols = append(c.cols, x)
+	}
+	return inNode, c.err != nil
+}
+
+func (c *checkConstraintChecker) Leave(inNode ast.Node) (node ast.Node, ok bool) {
+	return inNode, c.err == nil
+}
+
+// buildCheckConstraintInfo checks the expression of the CHECK constraint and builds its meta data.
+// If colName isn't empty, it's a column constraint which can only refer to the column.
+func buildCheckConstraintInfo(ctx context.Context, tblInfo *model.TableInfo, name model.CIStr, expr ast.ExprNode,
+	colName model.CIStr, state model.SchemaState) (*model.ConstraintInfo, error) {
+	checker := &checkConstraintChecker{
+		name:          name.O,
+		errNotAllowed: errCheckConstraintFunctionIsNotAllowed,
+		errVariables:  errCheckConstraintVariables,
+	}
+	expr.Accept(checker)
+	if checker.err != nil {
+		return nil, errors.Trace(checker.err)
+	}
+
+	info := &model.ConstraintInfo{Name: name, State: state, TimeZone: model.NewTimeZoneLocation(ctx.GetSessionVars().GetTimeZone())}
+	for _, colName := range checker.cols {
+		col := findCol(tblInfo.Columns, colName.Name.L)
+		if col == nil || col.State != model.StatePublic {
+			return nil, errCheckConstraintRefersUnknownColumn.GenByArgs(name.O, colName.Name.O)
+		}
+		if mysql.HasAutoIncrementFlag(col.Flag) {
+			return nil, errCheckConstraintRefersAutoIncrementColumn.GenByArgs(name.O)
+		}
+		found := false
+		for _, c := range info.Cols {
+			if c.L == col.Name.L {
+				found = true
+				break
+			}
+		}
+		if !found {
+			info.Cols = append(info.Cols, col.Name)
+		}
+	}
+	if colName.L != "" {
+		for _, c := range info.Cols {
+			if c.L != colName.L {
+				return nil, errColumnCheckConstraintReferencesOtherColumn.GenByArgs(name.O)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	expr.Format(&buf)
+	info.ExprString = buf.String()
+	// Make sure the expression can be evaluated.
+	if _, err := tables.BuildCheckConstraintExpr(ctx, tblInfo, info); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return info, nil
+}
+
+// genCheckConstraintName generates the name of the CHECK constraint like MySQL does,
+// the names are "<table name>_chk_<n>".
+func genCheckConstraintName(tblName model.CIStr, used map[string]struct{}) model.CIStr {
+	for i := 1; ; i++ {
+		name := fmt.Sprintf("%s_chk_%d", tblName.O, i)
+		if _, ok := used[name]; !ok {
+			return model.NewCIStr(name)
+		}
+	}
+}
+
+// buildTableCheckConstraints builds the CHECK constraints of the new table.
+func buildTableCheckConstraints(ctx context.Context, tbInfo *model.TableInfo, constraints []*ast.Constraint) error {
+	used := make(map[string]struct{})
+	for _, constr := range constraints {
+		if constr.Tp != ast.ConstraintCheck || constr.Name == "" {
+			continue
+		}
+		name := model.NewCIStr(constr.Name)
+		if _, ok := used[name.L]; ok {
+			return errCheckConstraintDupName.GenByArgs(name.O)
+		}
+		used[name.L] = struct{}{}
+	}
+	for _, constr := range constraints {
+		if constr.Tp != ast.ConstraintCheck {
+			continue
+		}
+		name := model.NewCIStr(constr.Name)
+		if constr.Name == "" {
+			name = genCheckConstraintName(tbInfo.Name, used)
+			used[name.L] = struct{}{}
+		}
+		// The column CHECK constraint has the column in its keys.
+		var colName model.CIStr
+		if len(constr.Keys) > 0 {
+			colName = constr.Keys[0].Column.Name
+		}
+		info, err := buildCheckConstraintInfo(ctx, tbInfo, name, constr.Expr, colName, model.StatePublic)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		info.ID = allocateIndexID(tbInfo)
+		tbInfo.Constraints = append(tbInfo.Constraints, info)
+	}
+	return nil
+}
+
+// checkColumnNotUsedByCheckConstraint checks the column can be dropped or renamed.
+func checkColumnNotUsedByCheckConstraint(tblInfo *model.TableInfo, colName model.CIStr) error {
+	for _, c := range tblInfo.Constraints {
+		for _, col := range c.Cols {
+			if col.L == colName.L {
+				return errDependentByCheckConstraint.GenByArgs(c.Name.O, colName.O)
+			}
+		}
+	}
+	return nil
+}
+
+func (d *ddl) onAddCheckConstraint(t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	schemaID := job.SchemaID
+	tblInfo, err := getTableInfo(t, job, schemaID)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+
+	info := &model.ConstraintInfo{}
+	if err = job.DecodeArgs(info); err != nil {
+		job.State = model.JobStateCancelled
+		return ver, errors.Trace(err)
+	}
+
+	constraintInfo := tblInfo.FindConstraintByName(info.Name.L)
+	if constraintInfo != nil && constraintInfo.State == model.StatePublic {
+		job.State = model.JobStateCancelled
+		return ver, errCheckConstraintDupName.GenByArgs(info.Name.O)
+	}
+	if job.IsCancelling() {
+		// If the value of SnapshotVer isn't zero, it means the work is validating the rows.
+		if job.SchemaState != model.StateWriteReorganization || job.SnapshotVer == 0 {
+			ver, err = d.rollbackAddCheckConstraint(t, job, tblInfo, info.Name, errCancelledDDLJob)
+			return ver, errors.Trace(err)
+		}
+		asyncNotify(d.reorgCtx.notifyCancelReorgJob)
+	}
+
+	if constraintInfo == nil {
+		for _, col := range info.Cols {
+			if c := findCol(tblInfo.Columns, col.L); c == nil || c.State != model.StatePublic {
+				job.State = model.JobStateCancelled
+				return ver, errCheckConstraintRefersUnknownColumn.GenByArgs(info.Name.O, col.O)
+			}
+		}
+		constraintInfo = info
+		constraintInfo.ID = allocateIndexID(tblInfo)
+		constraintInfo.State = model.StateNone
+		tblInfo.Constraints = append(tblInfo.Constraints, constraintInfo)
+	}
+
+	originalState := constraintInfo.State
+	switch constraintInfo.State {
+	case model.StateNone:
+		// none -> write only
+		// There is no data of the constraint, so it's enforced on the written rows directly.
+		job.SchemaState = model.StateWriteOnly
+		constraintInfo.State = model.StateWriteOnly
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+	case model.StateWriteOnly:
+		// write only -> reorganization
+		job.SchemaState = model.StateWriteReorganization
+		constraintInfo.State = model.StateWriteReorganization
+		// Initialize SnapshotVer to 0 for later reorganization check.
+		job.SnapshotVer = 0
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+	case model.StateWriteReorganization:
+		var done bool
+		ver, done, err = d.runCheckConstraintReorg(t, job, tblInfo, constraintInfo)
+		if !done {
+			return ver, errors.Trace(err)
+		}
+		// reorganization -> public
+		constraintInfo.State = model.StatePublic
+		job.SchemaState = model.StatePublic
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+		if err != nil {
+			return ver, errors.Trace(err)
+		}
+		// Finish this job.
+		job.State = model.JobStateDone
+		job.BinlogInfo.AddTableInfo(ver, tblInfo)
+	default:
+		err = ErrInvalidTableState.Gen("invalid constraint state %v", constraintInfo.State)
+	}
+	return ver, errors.Trace(err)
+}
+
+// runCheckConstraintReorg validates the existing rows in the write reorganization
+// state, done is true only if all the rows satisfy the constraint.
+func (d *ddl) runCheckConstraintReorg(t *meta.Meta, job *model.Job, tblInfo *model.TableInfo,
+	constraintInfo *model.ConstraintInfo) (ver int64, done bool, err error) {
+	tbl, err := d.getTable(job.SchemaID, tblInfo)
+	if err != nil {
+		return ver, false, errors.Trace(err)
+	}
+
+	reorgInfo, err := d.getReorgInfo(t, job)
+	if err != nil || reorgInfo.first {
+		if err == nil {
+			// Get the first handle of this table.
+			err = iterateSnapshotRows(d.store, tbl, reorgInfo.SnapshotVer, math.MinInt64,
+				func(h int64, rowKey kv.Key, rawRecord []byte) (bool, error) {
+					reorgInfo.Handle = h
+					return false, nil
+				})
+			return ver, false, errors.Trace(t.UpdateDDLReorgHandle(reorgInfo.Job, reorgInfo.Handle))
+		}
+		// If we run reorg firstly, we should update the job snapshot version
+		// and then run the reorg next time.
+		return ver, false, errors.Trace(err)
+	}
+
+	err = d.runReorgJob(t, job, func() error {
+		return d.validateCheckConstraint(tbl, constraintInfo, reorgInfo, job)
+	})
+	if err != nil {
+		if errWaitReorgTimeout.Equal(err) {
+			// if timeout, we should return, check for the owner and re-wait job done.
+			return ver, false, nil
+		}
+		if table.ErrCheckConstraintViolated.Equal(err) || errCancelledDDLJob.Equal(err) {
+			log.Warnf("[ddl] run DDL job %v err %v, convert job to rollback job", job, err)
+			ver, err = d.rollbackAddCheckConstraint(t, job, tblInfo, constraintInfo.Name, err)
+		}
+		// Clean up the channel of notifyCancelReorgJob. Make sure it can't affect other jobs.
+		cleanNotify(d.reorgCtx.notifyCancelReorgJob)
+		return ver, false, errors.Trace(err)
+	}
+	// Clean up the channel of notifyCancelReorgJob. Make sure it can't affect other jobs.
+	cleanNotify(d.reorgCtx.notifyCancelReorgJob)
+	return ver, true, nil
+}
+
+// validateCheckConstraint checks the rows in the snapshot of the reorganization satisfy the
+// constraint, the rows written later are checked by the statements which write them.
+func (d *ddl) validateCheckConstraint(t table.Table, constraintInfo *model.ConstraintInfo, reorgInfo *reorgInfo, job *model.Job) error {
+	// The rows are decoded in the time zone of the constraint, which it's evaluated in.
+	loc, err := constraintInfo.TimeZone.GetLocation()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	ctx := d.newContext()
+	ctx.GetSessionVars().TimeZone = loc
+	ctx.GetSessionVars().StmtCtx.TimeZone = loc
+	expr, err := tables.BuildCheckConstraintExpr(ctx, t.Meta(), constraintInfo)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	cols := t.Cols()
+	colMap := make(map[int64]*types.FieldType, len(cols))
+	for _, col := range cols {
+		colMap[col.ID] = &col.FieldType
+	}
+
+	checkedCount := job.GetRowCount()
+	startTime := time.Now()
+	err = iterateSnapshotRows(d.store, t, reorgInfo.SnapshotVer, reorgInfo.Handle,
+		func(h int64, rowKey kv.Key, rawRecord []byte) (bool, error) {
+			if checkedCount%int64(defaultTaskHandleCnt) == 0 {
+				if err1 := d.isReorgRunnable(); err1 != nil {
+					return false, errors.Trace(err1)
+				}
+				d.reorgCtx.setRowCountAndHandle(checkedCount, h)
+			}
+			row, err1 := decodeRowForCheck(ctx, t, cols, colMap, h, rawRecord)
+			if err1 != nil {
+				return false, errors.Trace(err1)
+			}
+			if err1 = tables.CheckConstraint(ctx, constraintInfo, expr, row); err1 != nil {
+				return false, errors.Trace(err1)
+			}
+			checkedCount++
+			return true, nil
+		})
+	d.reorgCtx.setRowCountAndHandle(checkedCount, reorgInfo.Handle)
+	log.Infof("[ddl] validated check constraint %s for %d rows, take time %v, err %v",
+		constraintInfo.Name, checkedCount, time.Since(startTime), err)
+	return errors.Trace(err)
+}
+
+// decodeRowForCheck decodes the public columns of the row, the columns which are
+// not in the row take their origin default values.
+func decodeRowForCheck(ctx context.Context, t table.Table, cols []*table.Column, colMap map[int64]*types.FieldType,
+	h int64, rawRecord []byte) ([]types.Datum, error) {
+	rowMap, err := tablecodec.DecodeRow(rawRecord, colMap, ctx.GetSessionVars().GetTimeZone())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	row := make([]types.Datum, len(cols))
+	for i, col := range cols {
+		if col.IsPKHandleColumn(t.Meta()) {
+			if mysql.HasUnsignedFlag(col.Flag) {
+				row[i].SetUint64(uint64(h))
+			} else {
+				row[i].SetInt64(h)
+			}
+			continue
+		}
+		val, ok := rowMap[col.ID]
+		if !ok {
+			val, err = table.GetColOriginDefaultValue(ctx, col.ToInfo())
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		row[i] = val
+	}
+	return row, nil
+}
+
+// rollbackAddCheckConstraint removes the constraint which isn't public yet. It's
+// removed at once, because the servers which still enforce it are stricter only.
+func (d *ddl) rollbackAddCheckConstraint(t *meta.Meta, job *model.Job, tblInfo *model.TableInfo,
+	name model.CIStr, err error) (ver int64, _ error) {
+	originalState := job.SchemaState
+	removeCheckConstraint(tblInfo, name)
+	job.State = model.JobStateRollbackDone
+	job.SchemaState = model.StateNone
+	ver, err1 := updateTableInfo(t, job, tblInfo, originalState)
+	if err1 != nil {
+		return ver, errors.Trace(err1)
+	}
+	job.BinlogInfo.AddTableInfo(ver, tblInfo)
+	return ver, errors.Trace(err)
+}
+
+func removeCheckConstraint(tblInfo *model.TableInfo, name model.CIStr) {
+	constraints := tblInfo.Constraints[:0]
+	for _, c := range tblInfo.Constraints {
+		if c.Name.L != name.L {
+			constraints = append(constraints, c)
+		}
+	}
+	tblInfo.Constraints = constraints
+}
+
+func (d *ddl) onDropCheckConstraint(t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	schemaID := job.SchemaID
+	tblInfo, err := getTableInfo(t, job, schemaID)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+
+	var name model.CIStr
+	if err = job.DecodeArgs(&name); err != nil {
+		job.State = model.JobStateCancelled
+		return ver, errors.Trace(err)
+	}
+
+	constraintInfo := tblInfo.FindConstraintByName(name.L)
+	if constraintInfo == nil || constraintInfo.State != model.StatePublic {
+		job.State = model.JobStateCancelled
+		return ver, errCheckConstraintNotFound.GenByArgs(name.O)
+	}
+
+	// The constraint has no data, and the servers which still enforce it are stricter only.
+	// public -> none
+	originalState := constraintInfo.State
+	removeCheckConstraint(tblInfo, name)
+	job.SchemaState = model.StateNone
+	ver, err = updateTableInfo(t, job, tblInfo, originalState)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	// Finish this job.
+	job.State = model.JobStateDone
+	job.BinlogInfo.AddTableInfo(ver, tblInfo)
+	return ver, nil
+}
@@ -94,6 +94,12 @@ func (d *ddl) createColumnInfo(tblInfo *model.TableInfo, colInfo *model.ColumnIn
 }
 
 func (d *ddl) onAddColumn(t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	// Handle the rolling back job.
+	if job.IsRollingback() {
+		ver, err := d.rollbackAddColumn(t, job)
+		return ver, errors.Trace(err)
+	}
+
 	schemaID := job.SchemaID
 	tblInfo, err := getTableInfo(t, job, schemaID)
 	if err != nil {
@@ -146,6 +152,11 @@ func (d *ddl) onAddColumn(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 		columnInfo.State = model.StateWriteReorganization
 		ver, err = updateTableInfo(t, job, tblInfo, originalState)
 	case model.StateWriteReorganization:
+		if isRevertibleSubJob(job) {
+			// Make the column public together with the other sub-jobs.
+			job.MultiSchemaInfo.Revertible = false
+			return ver, nil
+		}
 		// reorganization -> public
 		// Adjust column offset.
 		d.adjustColumnOffset(tblInfo.Columns, tblInfo.Indices, offset, true)
@@ -167,6 +178,59 @@ func (d *ddl) onAddColumn(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 	return ver, errors.Trace(err)
 }
 
+// rollbackAddColumn removes the column which isn't public yet, it's used by
+// the rolling back multi-schema change job.
+func (d *ddl) rollbackAddColumn(t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	schemaID := job.SchemaID
+	tblInfo, err := getTableInfo(t, job, schemaID)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+
+	var colName model.CIStr
+	err = job.DecodeArgs(&colName)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+
+	colInfo := findCol(tblInfo.Columns, colName.L)
+	if colInfo == nil {
+		job.State = model.JobStateRollbackDone
+		return ver, nil
+	}
+
+	originalState := colInfo.State
+	switch colInfo.State {
+	case model.StateWriteOnly, model.StateWriteReorganization:
+		// write only -> delete only
+		job.SchemaState = model.StateDeleteOnly
+		colInfo.State = model.StateDeleteOnly
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+	case model.StateDeleteOnly:
+		// delete only -> absent
+		// The column isn't public, so its offset is behind all the public columns.
+		newColumns := make([]*model.ColumnInfo, 0, len(tblInfo.Columns))
+		for _, col := range tblInfo.Columns {
+			if col.Name.L != colName.L {
+				newColumns = append(newColumns, col)
+			}
+		}
+		tblInfo.Columns = newColumns
+		job.SchemaState = model.StateNone
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+		if err != nil {
+			return ver, errors.Trace(err)
+		}
+
+		// Finish this job.
+		job.State = model.JobStateRollbackDone
+		job.BinlogInfo.AddTableInfo(ver, tblInfo)
+	default:
+		err = ErrInvalidColumnState.Gen("invalid column state %v", colInfo.State)
+	}
+	return ver, errors.Trace(err)
+}
+
 func (d *ddl) onDropColumn(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 	schemaID := job.SchemaID
 	tblInfo, err := getTableInfo(t, job, schemaID)
@@ -194,6 +258,11 @@ func (d *ddl) onDropColumn(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 	originalState := colInfo.State
 	switch colInfo.State {
 	case model.StatePublic:
+		if isRevertibleSubJob(job) {
+			// Hide the column together with the other sub-jobs.
+			job.MultiSchemaInfo.Revertible = false
+			return ver, nil
+		}
 		// public -> write only
 		job.SchemaState = model.StateWriteOnly
 		colInfo.State = model.StateWriteOnly
@@ -404,22 +473,27 @@ func (d *ddl) onModifyColumn(t *meta.Meta, job *model.Job) (ver int64, _ error)
 	newCol := &model.ColumnInfo{}
 	oldColName := &model.CIStr{}
 	pos := &ast.ColumnPosition{}
-	err := job.DecodeArgs(newCol, oldColName, pos)
+	var needReorg bool
+	var sqlMode mysql.SQLMode
+	var tz *model.TimeZoneLocation
+	err := job.DecodeArgs(newCol, oldColName, pos, &needReorg, &sqlMode, &tz)
 	if err != nil {
 		job.State = model.JobStateCancelled
 		return ver, errors.Trace(err)
 	}
 
-	return d.doModifyColumn(t, job, newCol, oldColName, pos)
-}
-
-// doModifyColumn updates the column information and reorders all columns.
-func (d *ddl) doModifyColumn(t *meta.Meta, job *model.Job, col *model.ColumnInfo, oldName *model.CIStr, pos *ast.ColumnPosition) (ver int64, _ error) {
 	tblInfo, err := getTableInfo(t, job, job.SchemaID)
 	if err != nil {
 		return ver, errors.Trace(err)
 	}
+	if needReorg {
+		return d.doModifyColumnWithData(t, job, tblInfo, newCol, oldColName, pos, sqlMode, tz)
+	}
+	return d.doModifyColumn(t, job, tblInfo, newCol, oldColName, pos)
+}
 
+// doModifyColumn updates the column information and reorders all columns.
+func (d *ddl) doModifyColumn(t *meta.Meta, job *model.Job, tblInfo *model.TableInfo, col *model.ColumnInfo, oldName *model.CIStr, pos *ast.ColumnPosition) (ver int64, _ error) {
 	oldCol := findCol(tblInfo.Columns, oldName.L)
 	if oldCol == nil || oldCol.State != model.StatePublic {
 		job.State = model.JobStateCancelled
@@ -486,7 +560,7 @@ func (d *ddl) doModifyColumn(t *meta.Meta, job *model.Job, col *model.ColumnInfo
 
 	originalState := job.SchemaState
 	job.SchemaState = model.StatePublic
-	ver, err = updateTableInfo(t, job, tblInfo, originalState)
+	ver, err := updateTableInfo(t, job, tblInfo, originalState)
 	if err != nil {
 		job.State = model.JobStateCancelled
 		return ver, errors.Trace(err)
@@ -911,6 +911,8 @@ func (s *testColumnSuite) TestModifyColumn(c *C) {
 		{"varchar(10)", "varchar(8)", errUnsupportedModifyColumn.GenByArgs("length 8 is less than origin 10")},
 		{"varchar(10)", "varchar(11)", nil},
 		{"varchar(10) character set utf8 collate utf8_bin", "varchar(10) character set utf8", nil},
+		{"decimal(10, 2)", "decimal(12, 2)", nil},
+		{"decimal(10, 2)", "decimal(10, 4)", errUnsupportedModifyColumn.GenByArgs("integer digits 6 is less than origin 8")},
 	}
 	for _, tt := range tests {
 		ftA := s.colDefStrToFieldType(c, tt.origin)
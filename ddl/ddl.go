@@ -36,6 +36,7 @@ import (
 	"github.com/pingcap/tidb/owner"
 	"github.com/pingcap/tidb/sessionctx/binloginfo"
 	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/terror"
 	log "github.com/sirupsen/logrus"
 	"github.com/twinj/uuid"
@@ -79,6 +80,12 @@ var (
 	errUnsupportedPKHandle     = terror.ClassDDL.New(codeUnsupportedDropPKHandle,
 		"unsupported drop integer primary key")
 	errUnsupportedCharset = terror.ClassDDL.New(codeUnsupportedCharset, "unsupported charset %s collate %s")
+	// errUnsupportedModifyCharset is returned when the values of a column can't be converted to the charset.
+	errUnsupportedModifyCharset = terror.ClassDDL.New(codeUnsupportedModifyCharset, "unsupported modify charset from %s to %s of column %s")
+	// errOperateSameColumn and errOperateSameIndex are returned when a multi-schema change changes
+	// a column or an index more than once.
+	errOperateSameColumn = terror.ClassDDL.New(codeOperateSameColumn, "column %s is changed more than once in one statement")
+	errOperateSameIndex  = terror.ClassDDL.New(codeOperateSameIndex, "index %s is changed more than once in one statement")
 
 	errBlobKeyWithoutLength = terror.ClassDDL.New(codeBlobKeyWithoutLength, "index for BLOB/TEXT column must specify a key length")
 	errIncorrectPrefixKey   = terror.ClassDDL.New(codeIncorrectPrefixKey, "Incorrect prefix key; the used key part isn't a string, the used length is longer than the key part, or the storage engine doesn't support unique prefix keys")
@@ -86,6 +93,7 @@ var (
 		fmt.Sprintf("Specified key was too long; max key length is %d bytes", maxPrefixLength))
 	errKeyColumnDoesNotExits = terror.ClassDDL.New(codeKeyColumnDoesNotExits, "this key column doesn't exist in table")
 	errDupKeyName            = terror.ClassDDL.New(codeDupKeyName, "duplicate key name")
+	errKeyDoesNotExist       = terror.ClassDDL.New(codeKeyDoesNotExist, mysql.MySQLErrName[mysql.ErrKeyDoesNotExist])
 	errUnknownTypeLength     = terror.ClassDDL.New(codeUnknownTypeLength, "Unknown length for type tp %d")
 	errUnknownFractionLength = terror.ClassDDL.New(codeUnknownFractionLength, "Unknown Length for type tp %d and fraction %d")
 	errInvalidJobVersion     = terror.ClassDDL.New(codeInvalidJobVersion, "DDL job with version %d greater than current %d")
@@ -108,6 +116,48 @@ var (
 	// errBlobCantHaveDefault forbiddens to give not null default value to TEXT/BLOB/JSON.
 	errBlobCantHaveDefault = terror.ClassDDL.New(codeBlobCantHaveDefault, mysql.MySQLErrName[mysql.ErrBlobCantHaveDefault])
 	errTooLongIndexComment = terror.ClassDDL.New(codeErrTooLongIndexComment, mysql.MySQLErrName[mysql.ErrTooLongIndexComment])
+	errTooLongTableComment = terror.ClassDDL.New(codeErrTooLongTableComment, mysql.MySQLErrName[mysql.ErrTooLongTableComment])
+
+	// errCheckConstraintDupName is for the CHECK constraint whose name is used by another one of the table.
+	errCheckConstraintDupName = terror.ClassDDL.New(codeCheckConstraintDupName, mysql.MySQLErrName[mysql.ErrCheckConstraintDupName])
+	// errCheckConstraintNotFound is for dropping a non-existent CHECK constraint.
+	errCheckConstraintNotFound = terror.ClassDDL.New(codeCheckConstraintNotFound, mysql.MySQLErrName[mysql.ErrCheckConstraintNotFound])
+	// errCheckConstraintRefersUnknownColumn is for the CHECK constraint which refers to a non-existent column.
+	errCheckConstraintRefersUnknownColumn = terror.ClassDDL.New(codeCheckConstraintRefersUnknownColumn,
+		mysql.MySQLErrName[mysql.ErrCheckConstraintRefersUnknownColumn])
+	// errColumnCheckConstraintReferencesOtherColumn is for the column CHECK constraint which refers to other columns.
+	errColumnCheckConstraintReferencesOtherColumn = terror.ClassDDL.New(codeColumnCheckConstraintReferencesOtherColumn,
+		mysql.MySQLErrName[mysql.ErrColumnCheckConstraintReferencesOtherColumn])
+	// errCheckConstraintFunctionIsNotAllowed is for the CHECK constraint with a subquery, an aggregate or a non-deterministic function.
+	errCheckConstraintFunctionIsNotAllowed = terror.ClassDDL.New(codeCheckConstraintFunctionIsNotAllowed,
+		mysql.MySQLErrName[mysql.ErrCheckConstraintFunctionIsNotAllowed])
+	// errCheckConstraintVariables is for the CHECK constraint which refers to variables.
+	errCheckConstraintVariables = terror.ClassDDL.New(codeCheckConstraintVariables, mysql.MySQLErrName[mysql.ErrCheckConstraintVariables])
+	// errCheckConstraintRefersAutoIncrementColumn is for the CHECK constraint which refers to the auto-increment column.
+	errCheckConstraintRefersAutoIncrementColumn = terror.ClassDDL.New(codeCheckConstraintRefersAutoIncrementColumn,
+		mysql.MySQLErrName[mysql.ErrCheckConstraintRefersAutoIncrementColumn])
+	// errDependentByCheckConstraint forbiddens to drop or rename the columns which are used by CHECK constraints.
+	errDependentByCheckConstraint = terror.ClassDDL.New(codeDependentByCheckConstraint, mysql.MySQLErrName[mysql.ErrDependentByCheckConstraint])
+	// errPKIndexCantBeInvisible is for making the primary key invisible.
+	errPKIndexCantBeInvisible = terror.ClassDDL.New(codePKIndexCantBeInvisible, mysql.MySQLErrName[mysql.ErrPKIndexCantBeInvisible])
+	// errFunctionalIndexOnJSONOrGeometryFunction is for the expression index on a JSON expression.
+	errFunctionalIndexOnJSONOrGeometryFunction = terror.ClassDDL.New(codeFunctionalIndexOnJSONOrGeometryFunction,
+		mysql.MySQLErrName[mysql.ErrFunctionalIndexOnJSONOrGeometryFunction])
+	// errFunctionalIndexRefAutoIncrement is for the expression index which refers to the auto-increment column.
+	errFunctionalIndexRefAutoIncrement = terror.ClassDDL.New(codeFunctionalIndexRefAutoIncrement,
+		mysql.MySQLErrName[mysql.ErrFunctionalIndexRefAutoIncrement])
+	// errCannotDropColumnFunctionalIndex forbiddens to drop the columns which are used by expression indexes.
+	errCannotDropColumnFunctionalIndex = terror.ClassDDL.New(codeCannotDropColumnFunctionalIndex,
+		mysql.MySQLErrName[mysql.ErrCannotDropColumnFunctionalIndex])
+	// errFunctionalIndexPrimaryKey is for the primary key with expression key parts.
+	errFunctionalIndexPrimaryKey = terror.ClassDDL.New(codeFunctionalIndexPrimaryKey, mysql.MySQLErrName[mysql.ErrFunctionalIndexPrimaryKey])
+	// errFunctionalIndexOnLob is for the expression index on a BLOB or TEXT expression.
+	errFunctionalIndexOnLob = terror.ClassDDL.New(codeFunctionalIndexOnLob, mysql.MySQLErrName[mysql.ErrFunctionalIndexOnLob])
+	// errFunctionalIndexFunctionIsNotAllowed is for the expression index with a subquery, a variable or a non-deterministic function.
+	errFunctionalIndexFunctionIsNotAllowed = terror.ClassDDL.New(codeFunctionalIndexFunctionIsNotAllowed,
+		mysql.MySQLErrName[mysql.ErrFunctionalIndexFunctionIsNotAllowed])
+	// errFunctionalIndexOnField is for the expression index whose expression is a bare column.
+	errFunctionalIndexOnField = terror.ClassDDL.New(codeFunctionalIndexOnField, mysql.MySQLErrName[mysql.ErrFunctionalIndexOnField])
 
 	// ErrInvalidDBState returns for invalid database state.
 	ErrInvalidDBState = terror.ClassDDL.New(codeInvalidDBState, "invalid database state")
@@ -119,7 +169,7 @@ var (
 	ErrInvalidIndexState = terror.ClassDDL.New(codeInvalidIndexState, "invalid index state")
 	// ErrInvalidForeignKeyState returns for invalid foreign key state.
 	ErrInvalidForeignKeyState = terror.ClassDDL.New(codeInvalidForeignKeyState, "invalid foreign key state")
-	// ErrUnsupportedModifyPrimaryKey returns an error when add or drop the primary key.
+	// ErrUnsupportedModifyPrimaryKey returns an error when drop the primary key which is the handle of the rows.
 	// It's exported for testing.
 	ErrUnsupportedModifyPrimaryKey = terror.ClassDDL.New(codeUnsupportedModifyPrimaryKey, "unsupported %s primary key")
 
@@ -147,8 +197,11 @@ var (
 type DDL interface {
 	CreateSchema(ctx context.Context, name model.CIStr, charsetInfo *ast.CharsetOpt) error
 	DropSchema(ctx context.Context, schema model.CIStr) error
+	AlterSchema(ctx context.Context, schema model.CIStr, charsetInfo *ast.CharsetOpt) error
 	CreateTable(ctx context.Context, ident ast.Ident, cols []*ast.ColumnDef,
 		constrs []*ast.Constraint, options []*ast.TableOption) error
+	CreateTableWithSelect(ctx context.Context, ident ast.Ident, cols []*ast.ColumnDef,
+		constrs []*ast.Constraint, options []*ast.TableOption, fill func(table.Table) error) error
 	CreateTableWithLike(ctx context.Context, ident, referIdent ast.Ident) error
 	DropTable(ctx context.Context, tableIdent ast.Ident) (err error)
 	CreateIndex(ctx context.Context, tableIdent ast.Ident, unique bool, indexName model.CIStr,
@@ -157,7 +210,10 @@ type DDL interface {
 	GetInformationSchema() infoschema.InfoSchema
 	AlterTable(ctx context.Context, tableIdent ast.Ident, spec []*ast.AlterTableSpec) error
 	TruncateTable(ctx context.Context, tableIdent ast.Ident) error
+	RecoverTable(ctx context.Context, tbInfo *model.TableInfo, schemaID, autoID, dropJobID int64, dropTS uint64) error
 	RenameTable(ctx context.Context, oldTableIdent, newTableIdent ast.Ident) error
+	// RenameTables renames several tables in one DDL job, the tables are renamed in order.
+	RenameTables(ctx context.Context, oldTableIdents, newTableIdents []ast.Ident) error
 	// SetLease will reset the lease time for online DDL change,
 	// it's a very dangerous function and you must guarantee that all servers have the same lease time.
 	SetLease(ctx goctx.Context, lease time.Duration)
@@ -207,6 +263,8 @@ type ddl struct {
 
 	workerVars      *variable.SessionVars
 	delRangeManager delRangeManager
+	// ctxPool is used to load the global variables.
+	ctxPool *pools.ResourcePool
 }
 
 // RegisterEventCh registers passed channel for ddl Event.
@@ -275,6 +333,7 @@ func newDDL(ctx goctx.Context, etcdCli *clientv3.Client, store kv.Storage,
 		ownerManager: manager,
 		schemaSyncer: syncer,
 		workerVars:   variable.NewSessionVars(),
+		ctxPool:      ctxPool,
 	}
 	d.workerVars.BinlogClient = binloginfo.GetPumpClient()
 
@@ -397,7 +456,7 @@ func (d *ddl) OwnerManager() owner.Manager {
 func checkJobMaxInterval(job *model.Job) time.Duration {
 	// The job of adding index takes more time to process.
 	// So it uses the longer time.
-	if job.Type == model.ActionAddIndex {
+	if job.Type == model.ActionAddIndex || job.Type == model.ActionAddPrimaryKey {
 		return 3 * time.Second
 	}
 	return 1 * time.Second
@@ -515,6 +574,9 @@ const (
 	codeUnsupportedDropPKHandle     = 204
 	codeUnsupportedCharset          = 205
 	codeUnsupportedModifyPrimaryKey = 206
+	codeOperateSameColumn           = 207
+	codeOperateSameIndex            = 208
+	codeUnsupportedModifyCharset    = 209
 
 	codeFileNotFound                 = 1017
 	codeErrorOnRename                = 1025
@@ -535,6 +597,7 @@ const (
 	codeWrongColumnName              = 1166
 	codeWrongKeyColumn               = 1167
 	codeBlobKeyWithoutLength         = 1170
+	codeKeyDoesNotExist              = 1176
 	codeInvalidOnUpdate              = 1294
 	codeUnsupportedOnGeneratedColumn = 3106
 	codeGeneratedColumnNonPrior      = 3107
@@ -542,6 +605,25 @@ const (
 	codeJSONUsedAsKey                = 3152
 	codeWrongNameForIndex            = terror.ErrCode(mysql.ErrWrongNameForIndex)
 	codeErrTooLongIndexComment       = terror.ErrCode(mysql.ErrTooLongIndexComment)
+	codeErrTooLongTableComment       = terror.ErrCode(mysql.ErrTooLongTableComment)
+
+	codeColumnCheckConstraintReferencesOtherColumn = 3813
+	codeCheckConstraintVariables                   = 3814
+	codeCheckConstraintFunctionIsNotAllowed        = 3815
+	codeCheckConstraintRefersAutoIncrementColumn   = 3818
+	codeCheckConstraintRefersUnknownColumn         = 3820
+	codeCheckConstraintNotFound                    = 3821
+	codeCheckConstraintDupName                     = 3822
+	codeDependentByCheckConstraint                 = 3959
+	codePKIndexCantBeInvisible                     = 3522
+
+	codeFunctionalIndexOnJSONOrGeometryFunction = 3753
+	codeFunctionalIndexRefAutoIncrement         = 3754
+	codeCannotDropColumnFunctionalIndex         = 3755
+	codeFunctionalIndexPrimaryKey               = 3756
+	codeFunctionalIndexOnLob                    = 3757
+	codeFunctionalIndexFunctionIsNotAllowed     = 3758
+	codeFunctionalIndexOnField                  = 3762
 )
 
 func init() {
@@ -551,6 +633,7 @@ func init() {
 		codeCantDropFieldOrKey:           mysql.ErrCantDropFieldOrKey,
 		codeInvalidOnUpdate:              mysql.ErrInvalidOnUpdate,
 		codeBlobKeyWithoutLength:         mysql.ErrBlobKeyWithoutLength,
+		codeKeyDoesNotExist:              mysql.ErrKeyDoesNotExist,
 		codeIncorrectPrefixKey:           mysql.ErrWrongSubKey,
 		codeTooLongIdent:                 mysql.ErrTooLongIdent,
 		codeTooLongKey:                   mysql.ErrTooLongKey,
@@ -572,6 +655,25 @@ func init() {
 		codeWrongNameForIndex:            mysql.ErrWrongNameForIndex,
 		codeTooManyFields:                mysql.ErrTooManyFields,
 		codeErrTooLongIndexComment:       mysql.ErrTooLongIndexComment,
+		codeErrTooLongTableComment:       mysql.ErrTooLongTableComment,
+
+		codeColumnCheckConstraintReferencesOtherColumn: mysql.ErrColumnCheckConstraintReferencesOtherColumn,
+		codeCheckConstraintVariables:                   mysql.ErrCheckConstraintVariables,
+		codeCheckConstraintFunctionIsNotAllowed:        mysql.ErrCheckConstraintFunctionIsNotAllowed,
+		codeCheckConstraintRefersAutoIncrementColumn:   mysql.ErrCheckConstraintRefersAutoIncrementColumn,
+		codeCheckConstraintRefersUnknownColumn:         mysql.ErrCheckConstraintRefersUnknownColumn,
+		codeCheckConstraintNotFound:                    mysql.ErrCheckConstraintNotFound,
+		codeCheckConstraintDupName:                     mysql.ErrCheckConstraintDupName,
+		codeDependentByCheckConstraint:                 mysql.ErrDependentByCheckConstraint,
+		codePKIndexCantBeInvisible:                     mysql.ErrPKIndexCantBeInvisible,
+
+		codeFunctionalIndexOnJSONOrGeometryFunction: mysql.ErrFunctionalIndexOnJSONOrGeometryFunction,
+		codeFunctionalIndexRefAutoIncrement:         mysql.ErrFunctionalIndexRefAutoIncrement,
+		codeCannotDropColumnFunctionalIndex:         mysql.ErrCannotDropColumnFunctionalIndex,
+		codeFunctionalIndexPrimaryKey:               mysql.ErrFunctionalIndexPrimaryKey,
+		codeFunctionalIndexOnLob:                    mysql.ErrFunctionalIndexOnLob,
+		codeFunctionalIndexFunctionIsNotAllowed:     mysql.ErrFunctionalIndexFunctionIsNotAllowed,
+		codeFunctionalIndexOnField:                  mysql.ErrFunctionalIndexOnField,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassDDL] = ddlMySQLErrCodes
 }
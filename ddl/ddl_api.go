@@ -29,13 +29,18 @@ import (
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/meta/autoid"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/types/geo"
 	"github.com/pingcap/tidb/util/charset"
+	"github.com/pingcap/tidb/util/collate"
+	log "github.com/sirupsen/logrus"
 )
 
 func (d *ddl) CreateSchema(ctx context.Context, schema model.CIStr, charsetInfo *ast.CharsetOpt) (err error) {
@@ -93,6 +98,64 @@ func (d *ddl) DropSchema(ctx context.Context, schema model.CIStr) (err error) {
 	return errors.Trace(err)
 }
 
+// AlterSchema changes the default charset and collation of the database.
+func (d *ddl) AlterSchema(ctx context.Context, schema model.CIStr, charsetInfo *ast.CharsetOpt) (err error) {
+	is := d.GetInformationSchema()
+	dbInfo, ok := is.SchemaByName(schema)
+	if !ok {
+		return infoschema.ErrDatabaseNotExists.GenByArgs(schema)
+	}
+
+	toCharset, toCollate, err := resolveCharsetAndCollate(charsetInfo.Chs, charsetInfo.Col)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if dbInfo.Charset == toCharset && dbInfo.Collate == toCollate {
+		return nil
+	}
+
+	job := &model.Job{
+		SchemaID:   dbInfo.ID,
+		Type:       model.ActionModifySchemaCharsetAndCollate,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{toCharset, toCollate},
+	}
+
+	err = d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
+// resolveCharsetAndCollate fills in the charset or the collation which isn't specified, and checks them.
+func resolveCharsetAndCollate(chs, coll string) (string, string, error) {
+	chs, coll = strings.ToLower(chs), strings.ToLower(coll)
+	if len(chs) == 0 {
+		for _, c := range charset.GetCollations() {
+			if c.Name == coll {
+				chs = c.CharsetName
+				break
+			}
+		}
+		if len(chs) == 0 {
+			return "", "", errUnsupportedCharset.GenByArgs(chs, coll)
+		}
+	}
+	if len(coll) == 0 {
+		var err error
+		coll, err = charset.GetDefaultCollation(chs)
+		if err != nil {
+			return "", "", errUnsupportedCharset.GenByArgs(chs, coll)
+		}
+	}
+	if !charset.ValidCharsetAndCollation(chs, coll) {
+		return "", "", errUnsupportedCharset.GenByArgs(chs, coll)
+	}
+	if !collate.IsSupportedCollation(coll) {
+		return "", "", expression.ErrUnknownCollation.GenByArgs(coll)
+	}
+	return chs, coll, nil
+}
+
 func checkTooLongSchema(schema model.CIStr) error {
 	if len(schema.L) > mysql.MaxDatabaseNameLength {
 		return ErrTooLongIdent.GenByArgs(schema)
@@ -125,6 +188,9 @@ func setColumnFlagWithConstraint(colMap map[string]*table.Column, v *ast.Constra
 	switch v.Tp {
 	case ast.ConstraintPrimaryKey:
 		for _, key := range v.Keys {
+			if key.Column == nil {
+				continue
+			}
 			c, ok := colMap[key.Column.Name.L]
 			if !ok {
 				continue
@@ -135,6 +201,9 @@ func setColumnFlagWithConstraint(colMap map[string]*table.Column, v *ast.Constra
 		}
 	case ast.ConstraintUniq, ast.ConstraintUniqIndex, ast.ConstraintUniqKey:
 		for i, key := range v.Keys {
+			if key.Column == nil {
+				continue
+			}
 			c, ok := colMap[key.Column.Name.L]
 			if !ok {
 				continue
@@ -153,6 +222,9 @@ func setColumnFlagWithConstraint(colMap map[string]*table.Column, v *ast.Constra
 		}
 	case ast.ConstraintKey, ast.ConstraintIndex:
 		for i, key := range v.Keys {
+			if key.Column == nil {
+				continue
+			}
 			c, ok := colMap[key.Column.Name.L]
 			if !ok {
 				continue
@@ -189,6 +261,14 @@ func buildColumnsAndConstraints(ctx context.Context, colDefs []*ast.ColumnDef,
 func setCharsetCollationFlenDecimal(tp *types.FieldType) error {
 	tp.Charset = strings.ToLower(tp.Charset)
 	tp.Collate = strings.ToLower(tp.Collate)
+	if len(tp.Charset) == 0 && len(tp.Collate) != 0 {
+		// The charset is the charset of the collation, like "varchar(10) collate utf8mb4_bin".
+		collation, err := charset.GetCollationByName(tp.Collate)
+		if err != nil {
+			return errUnsupportedCharset.GenByArgs(tp.Charset, tp.Collate)
+		}
+		tp.Charset = collation.CharsetName
+	}
 	if len(tp.Charset) == 0 {
 		switch tp.Tp {
 		case mysql.TypeString, mysql.TypeVarchar, mysql.TypeVarString, mysql.TypeBlob, mysql.TypeTinyBlob, mysql.TypeMediumBlob, mysql.TypeLongBlob, mysql.TypeEnum, mysql.TypeSet:
@@ -208,6 +288,9 @@ func setCharsetCollationFlenDecimal(tp *types.FieldType) error {
 				return errors.Trace(err)
 			}
 		}
+		if !collate.IsSupportedCollation(tp.Collate) {
+			return expression.ErrUnknownCollation.GenByArgs(tp.Collate)
+		}
 	}
 	// Use default value for flen or decimal when they are unspecified.
 	defaultFlen, defaultDecimal := mysql.GetDefaultFieldLengthAndDecimal(tp.Tp)
@@ -239,12 +322,12 @@ func buildColumnAndConstraint(ctx context.Context, offset int,
 }
 
 // checkColumnCantHaveDefaultValue checks the column can have value as default or not.
-// Now, TEXT/BLOB/JSON can't have not null value as default.
+// Now, TEXT/BLOB/JSON/GEOMETRY can't have not null value as default.
 func checkColumnCantHaveDefaultValue(col *table.Column, value interface{}) (err error) {
-	if value != nil && (col.Tp == mysql.TypeJSON ||
+	if value != nil && (col.Tp == mysql.TypeJSON || col.Tp == mysql.TypeGeometry ||
 		col.Tp == mysql.TypeTinyBlob || col.Tp == mysql.TypeMediumBlob ||
 		col.Tp == mysql.TypeLongBlob || col.Tp == mysql.TypeBlob) {
-		// TEXT/BLOB/JSON can't have not null default values.
+		// TEXT/BLOB/JSON/GEOMETRY can't have not null default values.
 		return errBlobCantHaveDefault.GenByArgs(col.Name.O)
 	}
 	return nil
@@ -325,6 +408,10 @@ func columnDefToCol(ctx context.Context, offset int, colDef *ast.ColumnDef) (*ta
 				col.GeneratedStored = v.Stored
 				_, dependColNames := findDependedColumnNames(colDef)
 				col.Dependences = dependColNames
+			case ast.ColumnOptionCheck:
+				// The keys of the column CHECK constraint are only used to check the columns referred by it.
+				constraint := &ast.Constraint{Tp: ast.ConstraintCheck, Keys: keys, Expr: v.Expr}
+				constraints = append(constraints, constraint)
 			case ast.ColumnOptionFulltext:
 				// TODO: Support this type.
 			}
@@ -539,7 +626,10 @@ func checkDuplicateConstraint(namesMap map[string]bool, name string, foreign boo
 
 func setEmptyConstraintName(namesMap map[string]bool, constr *ast.Constraint, foreign bool) {
 	if constr.Name == "" && len(constr.Keys) > 0 {
-		colName := constr.Keys[0].Column.Name.L
+		colName := expressionIndexName
+		if constr.Keys[0].Column != nil {
+			colName = constr.Keys[0].Column.Name.L
+		}
 		constrName := colName
 		i := 2
 		if strings.EqualFold(constrName, mysql.PrimaryKeyName) {
@@ -566,6 +656,10 @@ func checkConstraintNames(constraints []*ast.Constraint) error {
 
 	// Check not empty constraint name whether is duplicated.
 	for _, constr := range constraints {
+		if constr.Tp == ast.ConstraintCheck {
+			// The names of CHECK constraints are checked when building them.
+			continue
+		}
 		if constr.Tp == ast.ConstraintForeignKey {
 			err := checkDuplicateConstraint(fkNames, constr.Name, true)
 			if err != nil {
@@ -581,6 +675,9 @@ func checkConstraintNames(constraints []*ast.Constraint) error {
 
 	// Set empty constraint names.
 	for _, constr := range constraints {
+		if constr.Tp == ast.ConstraintCheck {
+			continue
+		}
 		if constr.Tp == ast.ConstraintForeignKey {
 			setEmptyConstraintName(fkNames, constr, true)
 		} else {
@@ -605,6 +702,9 @@ func (d *ddl) buildTableInfo(tableName model.CIStr, cols []*table.Column, constr
 	}
 	for _, constr := range constraints {
 		if constr.Tp == ast.ConstraintForeignKey {
+			if hasExpressionKey(constr.Keys) {
+				return nil, infoschema.ErrCannotAddForeign
+			}
 			for _, fk := range tbInfo.ForeignKeys {
 				if fk.Name.L == strings.ToLower(constr.Name) {
 					return nil, infoschema.ErrCannotAddForeign
@@ -632,6 +732,20 @@ func (d *ddl) buildTableInfo(tableName model.CIStr, cols []*table.Column, constr
 			tbInfo.ForeignKeys = append(tbInfo.ForeignKeys, &fk)
 			continue
 		}
+		if constr.Tp == ast.ConstraintCheck {
+			// CHECK constraints are built after all the indices.
+			continue
+		}
+		if hasExpressionKey(constr.Keys) {
+			if constr.Tp == ast.ConstraintPrimaryKey {
+				return nil, errFunctionalIndexPrimaryKey
+			}
+			hiddenCols, err := buildHiddenColumns(ctx, tbInfo, model.NewCIStr(constr.Name), constr.Keys)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			addHiddenColumns(tbInfo, hiddenCols)
+		}
 		if constr.Tp == ast.ConstraintPrimaryKey {
 			for _, key := range constr.Keys {
 				col := table.FindCol(cols, key.Column.Name.O)
@@ -694,6 +808,9 @@ func (d *ddl) buildTableInfo(tableName model.CIStr, cols []*table.Column, constr
 		idxInfo.ID = allocateIndexID(tbInfo)
 		tbInfo.Indices = append(tbInfo.Indices, idxInfo)
 	}
+	if err = buildTableCheckConstraints(ctx, tbInfo, constraints); err != nil {
+		return nil, errors.Trace(err)
+	}
 	return
 }
 
@@ -738,43 +855,137 @@ func (d *ddl) CreateTableWithLike(ctx context.Context, ident, referIdent ast.Ide
 
 func (d *ddl) CreateTable(ctx context.Context, ident ast.Ident, colDefs []*ast.ColumnDef,
 	constraints []*ast.Constraint, options []*ast.TableOption) (err error) {
+	job, tbInfo, err := d.buildCreateTableJob(ctx, ident, colDefs, constraints, options)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	err = d.doDDLJob(ctx, job)
+	if err == nil {
+		if tbInfo.AutoIncID > 1 {
+			// Default tableAutoIncID base is 0.
+			// If the first id is expected to greater than 1, we need to do rebase.
+			err = d.handleAutoIncID(tbInfo, job.SchemaID)
+		}
+	}
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
+// CreateTableWithSelect creates a table and inserts the rows of the select into
+// it by fill. The table is invisible until fill is done, if anything fails after
+// the table is created, the table is dropped.
+func (d *ddl) CreateTableWithSelect(ctx context.Context, ident ast.Ident, colDefs []*ast.ColumnDef,
+	constraints []*ast.Constraint, options []*ast.TableOption, fill func(table.Table) error) (err error) {
+	job, tbInfo, err := d.buildCreateTableJob(ctx, ident, colDefs, constraints, options)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	// The second arg makes the new table invisible.
+	job.Args = append(job.Args, true)
+	err = d.doDDLJob(ctx, job)
+	if err = d.callHookOnChanged(err); err != nil {
+		return errors.Trace(err)
+	}
+
+	if tbInfo.AutoIncID > 1 {
+		err = d.handleAutoIncID(tbInfo, job.SchemaID)
+	}
+	var tbl table.Table
+	if err == nil {
+		tbInfo.State = model.StateWriteReorganization
+		alloc := autoid.NewAllocator(d.store, tbInfo.GetDBID(job.SchemaID))
+		tbl, err = table.TableFromMeta(alloc, tbInfo)
+	}
+	if err == nil {
+		err = fill(tbl)
+	}
+	if err == nil {
+		publishJob := &model.Job{
+			SchemaID:   job.SchemaID,
+			TableID:    tbInfo.ID,
+			Type:       model.ActionPublishTable,
+			BinlogInfo: &model.HistoryInfo{},
+		}
+		err = d.callHookOnChanged(d.doDDLJob(ctx, publishJob))
+	}
+	if err != nil {
+		log.Warnf("[ddl] create the table %s by select err %v, drop it", ident, errors.ErrorStack(err))
+		// The inserted rows are deleted by the delete-range of dropping the table.
+		dropJob := &model.Job{
+			SchemaID:   job.SchemaID,
+			TableID:    tbInfo.ID,
+			Type:       model.ActionDropTable,
+			BinlogInfo: &model.HistoryInfo{},
+		}
+		if err1 := d.callHookOnChanged(d.doDDLJob(ctx, dropJob)); err1 != nil {
+			log.Errorf("[ddl] drop the table %s err %v", ident, errors.ErrorStack(err1))
+		}
+	}
+	return errors.Trace(err)
+}
+
+// invisibleTableByName returns the table which is being filled by CREATE TABLE ... SELECT.
+// It isn't in the information schema, but it holds its name until it's dropped, so it can
+// be dropped by name if the statement which creates it is interrupted, e.g. the server exits.
+func (d *ddl) invisibleTableByName(schemaID int64, name model.CIStr) (*model.TableInfo, error) {
+	var tblInfo *model.TableInfo
+	err := kv.RunInNewTxn(d.store, false, func(txn kv.Transaction) error {
+		tables, err := meta.NewMeta(txn).ListTables(schemaID)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		for _, t := range tables {
+			if t.Name.L == name.L && t.State == model.StateWriteReorganization {
+				tblInfo = t
+			}
+		}
+		return nil
+	})
+	return tblInfo, errors.Trace(err)
+}
+
+// buildCreateTableJob checks the definition of the new table and builds the job to create it.
+func (d *ddl) buildCreateTableJob(ctx context.Context, ident ast.Ident, colDefs []*ast.ColumnDef,
+	constraints []*ast.Constraint, options []*ast.TableOption) (*model.Job, *model.TableInfo, error) {
 	is := d.GetInformationSchema()
 	schema, ok := is.SchemaByName(ident.Schema)
 	if !ok {
-		return infoschema.ErrDatabaseNotExists.GenByArgs(ident.Schema)
+		return nil, nil, infoschema.ErrDatabaseNotExists.GenByArgs(ident.Schema)
 	}
 	if is.TableExists(ident.Schema, ident.Name) {
-		return infoschema.ErrTableExists.GenByArgs(ident)
+		return nil, nil, infoschema.ErrTableExists.GenByArgs(ident)
 	}
-	if err = checkTooLongTable(ident.Name); err != nil {
-		return errors.Trace(err)
+	if err := checkTooLongTable(ident.Name); err != nil {
+		return nil, nil, errors.Trace(err)
 	}
-	if err = checkDuplicateColumn(colDefs); err != nil {
-		return errors.Trace(err)
+	if err := checkDuplicateColumn(colDefs); err != nil {
+		return nil, nil, errors.Trace(err)
 	}
-	if err = checkGeneratedColumn(colDefs); err != nil {
-		return errors.Trace(err)
+	if err := checkGeneratedColumn(colDefs); err != nil {
+		return nil, nil, errors.Trace(err)
 	}
-	if err = checkTooLongColumn(colDefs); err != nil {
-		return errors.Trace(err)
+	if err := checkTooLongColumn(colDefs); err != nil {
+		return nil, nil, errors.Trace(err)
 	}
-	if err = checkTooManyColumns(colDefs); err != nil {
-		return errors.Trace(err)
+	if err := checkTooManyColumns(colDefs); err != nil {
+		return nil, nil, errors.Trace(err)
 	}
 
 	cols, newConstraints, err := buildColumnsAndConstraints(ctx, colDefs, constraints)
 	if err != nil {
-		return errors.Trace(err)
+		return nil, nil, errors.Trace(err)
 	}
 
 	err = checkConstraintNames(newConstraints)
 	if err != nil {
-		return errors.Trace(err)
+		return nil, nil, errors.Trace(err)
 	}
 
 	tbInfo, err := d.buildTableInfo(ident.Name, cols, newConstraints, ctx)
 	if err != nil {
-		return errors.Trace(err)
+		return nil, nil, errors.Trace(err)
 	}
 
 	job := &model.Job{
@@ -786,16 +997,7 @@ func (d *ddl) CreateTable(ctx context.Context, ident ast.Ident, colDefs []*ast.C
 	}
 
 	handleTableOptions(options, tbInfo)
-	err = d.doDDLJob(ctx, job)
-	if err == nil {
-		if tbInfo.AutoIncID > 1 {
-			// Default tableAutoIncID base is 0.
-			// If the first id is expected to greater than 1, we need to do rebase.
-			err = d.handleAutoIncID(tbInfo, schema.ID)
-		}
-	}
-	err = d.callHookOnChanged(err)
-	return errors.Trace(err)
+	return job, tbInfo, nil
 }
 
 // handleAutoIncID handles auto_increment option in DDL. It creates a ID counter for the table and initiates the counter to a proper value.
@@ -833,27 +1035,43 @@ func handleTableOptions(options []*ast.TableOption, tbInfo *model.TableInfo) {
 }
 
 func (d *ddl) AlterTable(ctx context.Context, ident ast.Ident, specs []*ast.AlterTableSpec) (err error) {
-	// Only handle valid specs, AlterTableLock is ignored.
+	// Only handle valid specs, AlterTableLock is ignored. The table options are merged into one spec,
+	// and it's ignored if none of the options is stored.
 	validSpecs := make([]*ast.AlterTableSpec, 0, len(specs))
+	var optionSpec *ast.AlterTableSpec
 	for _, spec := range specs {
 		if spec.Tp == ast.AlterTableLock {
 			continue
 		}
+		if spec.Tp == ast.AlterTableOption {
+			if optionSpec == nil {
+				optionSpec = &ast.AlterTableSpec{Tp: ast.AlterTableOption}
+			}
+			optionSpec.Options = append(optionSpec.Options, spec.Options...)
+			continue
+		}
 		validSpecs = append(validSpecs, spec)
 	}
+	if optionSpec != nil && hasStoredTableOption(optionSpec.Options) {
+		validSpecs = append(validSpecs, optionSpec)
+	}
 
-	if len(validSpecs) != 1 {
+	if len(validSpecs) == 0 {
+		if optionSpec != nil {
+			// Only the options which aren't stored are changed.
+			return nil
+		}
 		// TODO: Hanlde len(validSpecs) == 0.
-		// Now we only allow one schema changing at the same time.
 		return errRunMultiSchemaChanges
 	}
+	if len(validSpecs) > 1 || (validSpecs[0].Tp == ast.AlterTableAddColumns && len(validSpecs[0].NewColumns) > 1) {
+		err = d.multiSchemaChange(ctx, ident, validSpecs)
+		return errors.Trace(err)
+	}
 
 	for _, spec := range validSpecs {
 		switch spec.Tp {
 		case ast.AlterTableAddColumns:
-			if len(spec.NewColumns) != 1 {
-				return errRunMultiSchemaChanges
-			}
 			err = d.AddColumn(ctx, ident, spec)
 		case ast.AlterTableDropColumn:
 			err = d.DropColumn(ctx, ident, spec.OldColumnName.Name)
@@ -869,12 +1087,16 @@ func (d *ddl) AlterTable(ctx context.Context, ident ast.Ident, specs []*ast.Alte
 			case ast.ConstraintForeignKey:
 				err = d.CreateForeignKey(ctx, ident, model.NewCIStr(constr.Name), spec.Constraint.Keys, spec.Constraint.Refer)
 			case ast.ConstraintPrimaryKey:
-				err = ErrUnsupportedModifyPrimaryKey.GenByArgs("add")
+				err = d.CreatePrimaryKey(ctx, ident, spec.Constraint.Keys, constr.Option)
+			case ast.ConstraintCheck:
+				err = d.CreateCheckConstraint(ctx, ident, model.NewCIStr(constr.Name), constr.Expr)
 			default:
 				// Nothing to do now.
 			}
 		case ast.AlterTableDropForeignKey:
 			err = d.DropForeignKey(ctx, ident, model.NewCIStr(spec.Name))
+		case ast.AlterTableDropCheck:
+			err = d.DropCheckConstraint(ctx, ident, model.NewCIStr(spec.Name))
 		case ast.AlterTableModifyColumn:
 			err = d.ModifyColumn(ctx, ident, spec)
 		case ast.AlterTableChangeColumn:
@@ -885,23 +1107,180 @@ func (d *ddl) AlterTable(ctx context.Context, ident ast.Ident, specs []*ast.Alte
 			newIdent := ast.Ident{Schema: spec.NewTable.Schema, Name: spec.NewTable.Name}
 			err = d.RenameTable(ctx, ident, newIdent)
 		case ast.AlterTableDropPrimaryKey:
-			err = ErrUnsupportedModifyPrimaryKey.GenByArgs("drop")
+			err = d.DropPrimaryKey(ctx, ident)
+		case ast.AlterTableRenameIndex:
+			err = d.RenameIndex(ctx, ident, spec.FromKey, spec.ToKey)
+		case ast.AlterTableIndexVisibility:
+			err = d.AlterIndexVisibility(ctx, ident, model.NewCIStr(spec.Name), spec.Visibility == ast.IndexVisibilityInvisible)
+		case ast.AlterTableConvertCharset:
+			err = d.AlterTableCharsetAndCollate(ctx, ident, spec.Options, true)
 		case ast.AlterTableOption:
-			for _, opt := range spec.Options {
-				if opt.Tp == ast.TableOptionAutoIncrement {
-					err = d.RebaseAutoID(ctx, ident, int64(opt.UintValue))
-					break
+			err = d.alterTableOptions(ctx, ident, spec.Options)
+		default:
+			// Nothing to do now.
+		}
+
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+
+	return nil
+}
+
+// multiSchemaChange runs the specs of an ALTER TABLE statement in one job, the
+// changes become visible at the same time, or all of them are rolled back.
+// Now only adding and dropping columns and indices are supported.
+func (d *ddl) multiSchemaChange(ctx context.Context, ident ast.Ident, specs []*ast.AlterTableSpec) error {
+	t, err := d.infoHandle.Get().TableByName(ident.Schema, ident.Name)
+	if err != nil {
+		return errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ident.Schema, ident.Name))
+	}
+
+	jobs := make([]*model.Job, 0, len(specs))
+	// addedIndices holds the names of the indices added by the previous specs, the anonymous
+	// indices can't take them.
+	addedIndices := make(map[string]struct{})
+	for _, spec := range specs {
+		var job *model.Job
+		var err error
+		switch spec.Tp {
+		case ast.AlterTableAddColumns:
+			for _, colDef := range spec.NewColumns {
+				pos := spec.Position
+				if pos == nil {
+					pos = &ast.ColumnPosition{Tp: ast.ColumnPositionNone}
+				}
+				colSpec := &ast.AlterTableSpec{Tp: spec.Tp, NewColumns: []*ast.ColumnDef{colDef}, Position: pos}
+				job, err = d.buildAddColumnJob(ctx, ident, colSpec)
+				if err != nil {
+					return errors.Trace(err)
 				}
+				jobs = append(jobs, job)
+			}
+			continue
+		case ast.AlterTableDropColumn:
+			job, err = d.buildDropColumnJob(ident, spec.OldColumnName.Name)
+		case ast.AlterTableDropIndex:
+			job, err = d.buildDropIndexJob(ident, model.NewCIStr(spec.Name))
+		case ast.AlterTableAddConstraint:
+			constr := spec.Constraint
+			if hasExpressionKey(constr.Keys) {
+				// The hidden columns of the expression index can't be added with the other sub-jobs.
+				return errRunMultiSchemaChanges
+			}
+			indexName := model.NewCIStr(constr.Name)
+			if len(indexName.L) == 0 {
+				indexName = getAnonymousIndex(t, constr.Keys[0].Column.Name, addedIndices)
+			}
+			switch constr.Tp {
+			case ast.ConstraintKey, ast.ConstraintIndex:
+				job, err = d.buildCreateIndexJob(ctx, ident, false, indexName, constr.Keys, constr.Option)
+			case ast.ConstraintUniq, ast.ConstraintUniqIndex, ast.ConstraintUniqKey:
+				job, err = d.buildCreateIndexJob(ctx, ident, true, indexName, constr.Keys, constr.Option)
+			default:
+				return errRunMultiSchemaChanges
+			}
+			if err == nil {
+				addedIndices[indexName.L] = struct{}{}
 			}
 		default:
-			// Nothing to do now.
+			return errRunMultiSchemaChanges
+		}
+		if err != nil {
+			return errors.Trace(err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	if err = checkMultiSchemaChangeJobs(t.Meta(), jobs); err != nil {
+		return errors.Trace(err)
+	}
+
+	subJobs := make([]*model.SubJob, 0, len(jobs))
+	for _, job := range jobs {
+		subJobs = append(subJobs, model.NewSubJob(job))
+	}
+	job := &model.Job{
+		SchemaID:   jobs[0].SchemaID,
+		TableID:    jobs[0].TableID,
+		Type:       model.ActionMultiSchemaChange,
+		BinlogInfo: &model.HistoryInfo{},
+		MultiSchemaInfo: &model.MultiSchemaInfo{
+			SubJobs:    subJobs,
+			Revertible: true,
+		},
+	}
+
+	err = d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
+// checkMultiSchemaChangeJobs checks that a column or an index is changed by
+// at most one job, and the table still has columns after the changes. The
+// other checks are done by the jobs one by one.
+func checkMultiSchemaChangeJobs(tblInfo *model.TableInfo, jobs []*model.Job) error {
+	columns := make(map[string]struct{})
+	indices := make(map[string]struct{})
+	addColumn := func(name model.CIStr) error {
+		if _, ok := columns[name.L]; ok {
+			return errOperateSameColumn.GenByArgs(name)
+		}
+		columns[name.L] = struct{}{}
+		return nil
+	}
+	addIndex := func(name model.CIStr) error {
+		if _, ok := indices[name.L]; ok {
+			return errOperateSameIndex.GenByArgs(name)
 		}
+		indices[name.L] = struct{}{}
+		return nil
+	}
 
+	colCount := len(tblInfo.Columns)
+	var referredCols []model.CIStr
+	for _, job := range jobs {
+		var err error
+		switch job.Type {
+		case model.ActionAddColumn:
+			col := job.Args[0].(*table.Column)
+			for dep := range col.Dependences {
+				referredCols = append(referredCols, model.NewCIStr(dep))
+			}
+			colCount++
+			err = addColumn(col.Name)
+		case model.ActionDropColumn:
+			colCount--
+			err = addColumn(job.Args[0].(model.CIStr))
+		case model.ActionAddIndex:
+			for _, colName := range job.Args[2].([]*ast.IndexColName) {
+				referredCols = append(referredCols, colName.Column.Name)
+			}
+			err = addIndex(job.Args[1].(model.CIStr))
+		case model.ActionDropIndex:
+			err = addIndex(job.Args[0].(model.CIStr))
+		}
 		if err != nil {
 			return errors.Trace(err)
 		}
 	}
+	if colCount <= 0 {
+		return ErrCantRemoveAllFields.Gen("can't drop all columns in table %s", tblInfo.Name)
+	}
 
+	// The columns used by the new indices and generated columns can't be dropped at the same time.
+	for _, job := range jobs {
+		if job.Type != model.ActionDropColumn {
+			continue
+		}
+		colName := job.Args[0].(model.CIStr)
+		for _, referred := range referredCols {
+			if referred.L == colName.L {
+				return errOperateSameColumn.GenByArgs(colName)
+			}
+		}
+	}
 	return nil
 }
 
@@ -932,46 +1311,309 @@ func (d *ddl) RebaseAutoID(ctx context.Context, ident ast.Ident, newBase int64)
 	return errors.Trace(err)
 }
 
-func checkColumnConstraint(constraints []*ast.ColumnOption) error {
-	for _, constraint := range constraints {
-		switch constraint.Tp {
-		case ast.ColumnOptionAutoIncrement, ast.ColumnOptionPrimaryKey, ast.ColumnOptionUniqKey:
-			return errUnsupportedAddColumn.Gen("unsupported add column constraint - %v", constraint.Tp)
+// hasStoredTableOption returns whether any of the options is stored in the table info.
+func hasStoredTableOption(options []*ast.TableOption) bool {
+	for _, opt := range options {
+		switch opt.Tp {
+		case ast.TableOptionAutoIncrement, ast.TableOptionComment, ast.TableOptionCharset, ast.TableOptionCollate:
+			return true
 		}
 	}
-
-	return nil
+	return false
 }
 
-// AddColumn will add a new column to the table.
-func (d *ddl) AddColumn(ctx context.Context, ti ast.Ident, spec *ast.AlterTableSpec) error {
-	specNewColumn := spec.NewColumns[0]
-	// Check whether the added column constraints are supported.
-	err := checkColumnConstraint(specNewColumn.Options)
-	if err != nil {
+// alterTableOptions changes the options of the table, every kind of option is changed by a job.
+// The options which aren't stored, like ENGINE, are ignored.
+func (d *ddl) alterTableOptions(ctx context.Context, ident ast.Ident, options []*ast.TableOption) error {
+	var changeCharset bool
+	for _, opt := range options {
+		var err error
+		switch opt.Tp {
+		case ast.TableOptionAutoIncrement:
+			err = d.RebaseAutoID(ctx, ident, int64(opt.UintValue))
+		case ast.TableOptionComment:
+			err = d.AlterTableComment(ctx, ident, opt.StrValue)
+		case ast.TableOptionCharset, ast.TableOptionCollate:
+			changeCharset = true
+		}
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if changeCharset {
+		err := d.AlterTableCharsetAndCollate(ctx, ident, options, false)
 		return errors.Trace(err)
 	}
+	return nil
+}
 
-	is := d.infoHandle.Get()
-	schema, ok := is.SchemaByName(ti.Schema)
+// AlterTableComment changes the comment of the table.
+func (d *ddl) AlterTableComment(ctx context.Context, ident ast.Ident, comment string) error {
+	is := d.GetInformationSchema()
+	schema, ok := is.SchemaByName(ident.Schema)
 	if !ok {
-		return errors.Trace(infoschema.ErrDatabaseNotExists)
+		return infoschema.ErrDatabaseNotExists.GenByArgs(ident.Schema)
 	}
-	t, err := is.TableByName(ti.Schema, ti.Name)
+	t, err := is.TableByName(ident.Schema, ident.Name)
 	if err != nil {
-		return errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ti.Schema, ti.Name))
+		return errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ident.Schema, ident.Name))
 	}
-
-	// Check whether added column has existed.
-	colName := specNewColumn.Name.Name.O
-	col := table.FindCol(t.Cols(), colName)
-	if col != nil {
-		return infoschema.ErrColumnExists.GenByArgs(colName)
+	comment, err = validateCommentLength(ctx.GetSessionVars(),
+		comment,
+		maxTableCommentLength,
+		errTooLongTableComment.GenByArgs(ident.Name.String(), maxTableCommentLength))
+	if err != nil {
+		return errors.Trace(err)
 	}
 
-	// If new column is a generated column, do validation.
-	// NOTE: Because now we can only append columns to table,
-	// we dont't need check whether the column refers other
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    t.Meta().ID,
+		Type:       model.ActionModifyTableComment,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{comment},
+	}
+	err = d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
+// AlterTableCharsetAndCollate changes the default charset and collation of the table.
+// If convert is true, the string columns of the table are converted to the charset too,
+// now it's only supported when the stored values don't need to be changed.
+func (d *ddl) AlterTableCharsetAndCollate(ctx context.Context, ident ast.Ident, options []*ast.TableOption, convert bool) error {
+	var toCharset, toCollate string
+	for _, opt := range options {
+		switch opt.Tp {
+		case ast.TableOptionCharset:
+			toCharset = opt.StrValue
+		case ast.TableOptionCollate:
+			toCollate = opt.StrValue
+		}
+	}
+	toCharset, toCollate, err := resolveCharsetAndCollate(toCharset, toCollate)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	is := d.GetInformationSchema()
+	schema, ok := is.SchemaByName(ident.Schema)
+	if !ok {
+		return infoschema.ErrDatabaseNotExists.GenByArgs(ident.Schema)
+	}
+	t, err := is.TableByName(ident.Schema, ident.Name)
+	if err != nil {
+		return errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ident.Schema, ident.Name))
+	}
+	if convert {
+		for _, col := range t.Meta().Columns {
+			if err = checkModifyColumnCharset(col, toCharset); err != nil {
+				return errors.Trace(err)
+			}
+			if err = checkModifyIndexedColumnCollation(t.Meta(), col, toCollate); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    t.Meta().ID,
+		Type:       model.ActionModifyTableCharsetAndCollate,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{toCharset, toCollate, convert},
+	}
+	err = d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
+// hasCharset returns whether the values of the column are stored in a non-binary charset.
+func hasCharset(col *model.ColumnInfo) bool {
+	return len(col.Charset) != 0 && col.Charset != charset.CharsetBin
+}
+
+// checkModifyIndexedColumnCollation checks whether the collation of the column can be changed without
+// rebuilding its indexes. When the new collations are enabled, the index keys of the strings are the
+// sort keys of their collation, so they are wrong after the collation is changed in the metadata.
+func checkModifyIndexedColumnCollation(tblInfo *model.TableInfo, col *model.ColumnInfo, toCollate string) error {
+	if !collate.NewCollationEnabled() || !hasCharset(col) || collate.GetCollator(col.Collate) == collate.GetCollator(toCollate) {
+		return nil
+	}
+	for _, idx := range tblInfo.Indices {
+		if indexCoversColumn(idx, col.Name) {
+			msg := fmt.Sprintf("change the collation of column %s used by index %s", col.Name, idx.Name)
+			return errUnsupportedModifyColumn.GenByArgs(msg)
+		}
+	}
+	return nil
+}
+
+// checkModifyColumnCharset checks whether the stored values of the column can be used in the charset
+// without conversion. The values in ascii are valid in all the charsets, and the values in utf8 are
+// valid in utf8mb4.
+func checkModifyColumnCharset(col *model.ColumnInfo, toCharset string) error {
+	if !hasCharset(col) || col.Charset == toCharset {
+		return nil
+	}
+	switch {
+	case col.Charset == charset.CharsetASCII:
+		return nil
+	case col.Charset == charset.CharsetUTF8 && toCharset == charset.CharsetUTF8MB4:
+		return nil
+	}
+	return errUnsupportedModifyCharset.GenByArgs(col.Charset, toCharset, col.Name)
+}
+
+// RenameIndex renames the index of the table.
+func (d *ddl) RenameIndex(ctx context.Context, ident ast.Ident, fromKey, toKey model.CIStr) error {
+	is := d.GetInformationSchema()
+	schema, ok := is.SchemaByName(ident.Schema)
+	if !ok {
+		return infoschema.ErrDatabaseNotExists.GenByArgs(ident.Schema)
+	}
+	t, err := is.TableByName(ident.Schema, ident.Name)
+	if err != nil {
+		return errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ident.Schema, ident.Name))
+	}
+	if err = checkRenameIndex(t.Meta(), fromKey, toKey); err != nil {
+		return errors.Trace(err)
+	}
+	if fromKey.O == toKey.O {
+		return nil
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    t.Meta().ID,
+		Type:       model.ActionRenameIndex,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{fromKey, toKey},
+	}
+	err = d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
+// AlterIndexVisibility makes the index visible or invisible to the optimizer.
+func (d *ddl) AlterIndexVisibility(ctx context.Context, ident ast.Ident, indexName model.CIStr, invisible bool) error {
+	is := d.GetInformationSchema()
+	schema, ok := is.SchemaByName(ident.Schema)
+	if !ok {
+		return infoschema.ErrDatabaseNotExists.GenByArgs(ident.Schema)
+	}
+	t, err := is.TableByName(ident.Schema, ident.Name)
+	if err != nil {
+		return errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ident.Schema, ident.Name))
+	}
+	idx, err := checkAlterIndexVisibility(t.Meta(), indexName, invisible)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if idx.Invisible == invisible {
+		return nil
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    t.Meta().ID,
+		Type:       model.ActionAlterIndexVisibility,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{indexName, invisible},
+	}
+	err = d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
+// checkAlterIndexVisibility checks whether the visibility of the index can be changed, and returns the index.
+func checkAlterIndexVisibility(tblInfo *model.TableInfo, indexName model.CIStr, invisible bool) (*model.IndexInfo, error) {
+	if indexName.L == strings.ToLower(mysql.PrimaryKeyName) && tblInfo.PKIsHandle {
+		if invisible {
+			return nil, errPKIndexCantBeInvisible
+		}
+		// The handle is always visible.
+		return &model.IndexInfo{Name: indexName, Primary: true}, nil
+	}
+	idx := findIndexByName(indexName.L, tblInfo.Indices)
+	if idx == nil || idx.State != model.StatePublic {
+		return nil, errKeyDoesNotExist.GenByArgs(indexName.O, tblInfo.Name.O)
+	}
+	if idx.Primary && invisible {
+		return nil, errPKIndexCantBeInvisible
+	}
+	return idx, nil
+}
+
+// checkRenameIndex checks whether the index fromKey of the table can be renamed to toKey.
+func checkRenameIndex(tblInfo *model.TableInfo, fromKey, toKey model.CIStr) error {
+	if fromKey.L == strings.ToLower(mysql.PrimaryKeyName) {
+		return ErrWrongNameForIndex.GenByArgs(fromKey.O)
+	}
+	if toKey.L == strings.ToLower(mysql.PrimaryKeyName) {
+		return ErrWrongNameForIndex.GenByArgs(toKey.O)
+	}
+	if findIndexByName(fromKey.L, tblInfo.Indices) == nil {
+		return errKeyDoesNotExist.GenByArgs(fromKey.O, tblInfo.Name.O)
+	}
+	if fromKey.L != toKey.L && findIndexByName(toKey.L, tblInfo.Indices) != nil {
+		return errDupKeyName.Gen("index already exist %s", toKey)
+	}
+	return errors.Trace(checkTooLongIndex(toKey))
+}
+
+func checkColumnConstraint(constraints []*ast.ColumnOption) error {
+	for _, constraint := range constraints {
+		switch constraint.Tp {
+		case ast.ColumnOptionAutoIncrement, ast.ColumnOptionPrimaryKey, ast.ColumnOptionUniqKey, ast.ColumnOptionCheck:
+			return errUnsupportedAddColumn.Gen("unsupported add column constraint - %v", constraint.Tp)
+		}
+	}
+
+	return nil
+}
+
+// AddColumn will add a new column to the table.
+func (d *ddl) AddColumn(ctx context.Context, ti ast.Ident, spec *ast.AlterTableSpec) error {
+	job, err := d.buildAddColumnJob(ctx, ti, spec)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	err = d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
+// buildAddColumnJob checks the column of the spec and builds the job to add it.
+func (d *ddl) buildAddColumnJob(ctx context.Context, ti ast.Ident, spec *ast.AlterTableSpec) (*model.Job, error) {
+	specNewColumn := spec.NewColumns[0]
+	// Check whether the added column constraints are supported.
+	err := checkColumnConstraint(specNewColumn.Options)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	is := d.infoHandle.Get()
+	schema, ok := is.SchemaByName(ti.Schema)
+	if !ok {
+		return nil, errors.Trace(infoschema.ErrDatabaseNotExists)
+	}
+	t, err := is.TableByName(ti.Schema, ti.Name)
+	if err != nil {
+		return nil, errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ti.Schema, ti.Name))
+	}
+
+	// Check whether added column has existed.
+	colName := specNewColumn.Name.Name.O
+	col := table.FindCol(t.Cols(), colName)
+	if col != nil {
+		return nil, infoschema.ErrColumnExists.GenByArgs(colName)
+	}
+
+	// If new column is a generated column, do validation.
+	// NOTE: Because now we can only append columns to table,
+	// we dont't need check whether the column refers other
 	// generated columns occurring later in table.
 	for _, option := range specNewColumn.Options {
 		if option.Tp == ast.ColumnOptionGenerated {
@@ -981,13 +1623,13 @@ func (d *ddl) AddColumn(ctx context.Context, ti ast.Ident, spec *ast.AlterTableS
 			}
 			_, dependColNames := findDependedColumnNames(specNewColumn)
 			if err = columnNamesCover(referableColNames, dependColNames); err != nil {
-				return errors.Trace(err)
+				return nil, errors.Trace(err)
 			}
 		}
 	}
 
 	if len(colName) > mysql.MaxColumnNameLength {
-		return ErrTooLongIdent.GenByArgs(colName)
+		return nil, ErrTooLongIdent.GenByArgs(colName)
 	}
 
 	// Ingore table constraints now, maybe return error later.
@@ -995,14 +1637,14 @@ func (d *ddl) AddColumn(ctx context.Context, ti ast.Ident, spec *ast.AlterTableS
 	// column's offset later.
 	col, _, err = buildColumnAndConstraint(ctx, len(t.Cols()), specNewColumn)
 	if err != nil {
-		return errors.Trace(err)
+		return nil, errors.Trace(err)
 	}
 	col.OriginDefaultValue = col.DefaultValue
 	if col.OriginDefaultValue == nil && mysql.HasNotNullFlag(col.Flag) {
 		zeroVal := table.GetZeroValue(col.ToInfo())
 		col.OriginDefaultValue, err = zeroVal.ToString()
 		if err != nil {
-			return errors.Trace(err)
+			return nil, errors.Trace(err)
 		}
 	}
 
@@ -1018,37 +1660,46 @@ func (d *ddl) AddColumn(ctx context.Context, ti ast.Ident, spec *ast.AlterTableS
 		BinlogInfo: &model.HistoryInfo{},
 		Args:       []interface{}{col, spec.Position, 0},
 	}
+	return job, nil
+}
+
+// DropColumn will drop a column from the table, now we don't support drop the column with index covered.
+func (d *ddl) DropColumn(ctx context.Context, ti ast.Ident, colName model.CIStr) error {
+	job, err := d.buildDropColumnJob(ti, colName)
+	if err != nil {
+		return errors.Trace(err)
+	}
 
 	err = d.doDDLJob(ctx, job)
 	err = d.callHookOnChanged(err)
 	return errors.Trace(err)
 }
 
-// DropColumn will drop a column from the table, now we don't support drop the column with index covered.
-func (d *ddl) DropColumn(ctx context.Context, ti ast.Ident, colName model.CIStr) error {
+// buildDropColumnJob checks the column and builds the job to drop it.
+func (d *ddl) buildDropColumnJob(ti ast.Ident, colName model.CIStr) (*model.Job, error) {
 	is := d.infoHandle.Get()
 	schema, ok := is.SchemaByName(ti.Schema)
 	if !ok {
-		return errors.Trace(infoschema.ErrDatabaseNotExists)
+		return nil, errors.Trace(infoschema.ErrDatabaseNotExists)
 	}
 	t, err := is.TableByName(ti.Schema, ti.Name)
 	if err != nil {
-		return errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ti.Schema, ti.Name))
+		return nil, errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ti.Schema, ti.Name))
 	}
 
 	// Check whether dropped column has existed.
 	col := table.FindCol(t.Cols(), colName.L)
-	if col == nil {
-		return ErrCantDropFieldOrKey.Gen("column %s doesn't exist", colName)
+	if col == nil || col.Hidden {
+		return nil, ErrCantDropFieldOrKey.Gen("column %s doesn't exist", colName)
 	}
 
 	tblInfo := t.Meta()
 	if err = isDroppableColumn(tblInfo, colName); err != nil {
-		return errors.Trace(err)
+		return nil, errors.Trace(err)
 	}
 	// We don't support dropping column with PK handle covered now.
 	if col.IsPKHandleColumn(tblInfo) {
-		return errUnsupportedPKHandle
+		return nil, errUnsupportedPKHandle
 	}
 
 	job := &model.Job{
@@ -1058,10 +1709,7 @@ func (d *ddl) DropColumn(ctx context.Context, ti ast.Ident, colName model.CIStr)
 		BinlogInfo: &model.HistoryInfo{},
 		Args:       []interface{}{colName},
 	}
-
-	err = d.doDDLJob(ctx, job)
-	err = d.callHookOnChanged(err)
-	return errors.Trace(err)
+	return job, nil
 }
 
 // modifiable checks if the 'origin' type can be modified to 'to' type with out the need to
@@ -1077,6 +1725,10 @@ func modifiable(origin *types.FieldType, to *types.FieldType) error {
 		msg := fmt.Sprintf("decimal %d is less than origin %d", to.Decimal, origin.Decimal)
 		return errUnsupportedModifyColumn.GenByArgs(msg)
 	}
+	if origin.Tp == mysql.TypeNewDecimal && to.Flen-to.Decimal < origin.Flen-origin.Decimal {
+		msg := fmt.Sprintf("integer digits %d is less than origin %d", to.Flen-to.Decimal, origin.Flen-origin.Decimal)
+		return errUnsupportedModifyColumn.GenByArgs(msg)
+	}
 	if to.Charset != origin.Charset {
 		msg := fmt.Sprintf("charset %s not match origin %s", to.Charset, origin.Charset)
 		return errUnsupportedModifyColumn.GenByArgs(msg)
@@ -1106,6 +1758,11 @@ func modifiable(origin *types.FieldType, to *types.FieldType) error {
 		}
 	case mysql.TypeEnum:
 		return errUnsupportedModifyColumn.GenByArgs("modify enum column is not supported")
+	case mysql.TypeGeometry:
+		// The stored geometries are not checked again, so a spatial column can only be widened to GEOMETRY.
+		if to.Tp == mysql.TypeGeometry && (to.GeomType == origin.GeomType || to.GeomType == geo.TypeGeometry) {
+			return nil
+		}
 	default:
 		if origin.Tp == to.Tp {
 			return nil
@@ -1213,9 +1870,18 @@ func (d *ddl) getModifiableColumnJob(ctx context.Context, ident ast.Ident, origi
 	}
 
 	col := table.FindCol(t.Cols(), originalColName.L)
-	if col == nil {
+	if col == nil || col.Hidden {
 		return nil, infoschema.ErrColumnNotExists.GenByArgs(originalColName, ident.Name)
 	}
+	if originalColName.L != specNewColumn.Name.Name.L {
+		if err = checkColumnNotUsedByCheckConstraint(t.Meta(), originalColName); err != nil {
+			return nil, errors.Trace(err)
+		}
+		// The hidden columns of the expression indexes refer to the columns by their names.
+		if err = checkColumnNotUsedByExpressionIndex(t.Meta(), originalColName); err != nil {
+			return nil, errDependentByGeneratedColumn.GenByArgs(originalColName.O)
+		}
+	}
 
 	// Constraints in the new column means adding new constraints. Errors should thrown,
 	// which will be done by `setDefaultAndComment` later.
@@ -1237,9 +1903,13 @@ func (d *ddl) getModifiableColumnJob(ctx context.Context, ident ast.Ident, origi
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	err = modifiable(&col.FieldType, &newCol.FieldType)
-	if err != nil {
-		return nil, errors.Trace(err)
+	// The data of the column is converted in a reorganization if the new type
+	// can't hold all the values of the origin type.
+	needReorg := modifiable(&col.FieldType, &newCol.FieldType) != nil
+	if needReorg {
+		if err = checkModifyColumnWithReorg(t, col); err != nil {
+			return nil, errors.Trace(err)
+		}
 	}
 	if err = setDefaultAndComment(ctx, newCol, specNewColumn.Options); err != nil {
 		return nil, errors.Trace(err)
@@ -1272,14 +1942,31 @@ func (d *ddl) getModifiableColumnJob(ctx context.Context, ident ast.Ident, origi
 		TableID:    t.Meta().ID,
 		Type:       model.ActionModifyColumn,
 		BinlogInfo: &model.HistoryInfo{},
-		Args:       []interface{}{&newCol, originalColName, spec.Position},
+		Args: []interface{}{&newCol, originalColName, spec.Position, needReorg, ctx.GetSessionVars().SQLMode,
+			model.NewTimeZoneLocation(ctx.GetSessionVars().GetTimeZone())},
 	}
 	return job, nil
 }
 
+// checkModifyColumnWithReorg checks if the column can be modified by converting its data.
+func checkModifyColumnWithReorg(t table.Table, col *table.Column) error {
+	if col.IsPKHandleColumn(t.Meta()) {
+		return errUnsupportedModifyColumn.GenByArgs("change the type of the primary key handle column")
+	}
+	if col.IsGenerated() {
+		return errUnsupportedModifyColumn.GenByArgs("change the type of the generated column")
+	}
+	for _, c := range t.Cols() {
+		if _, ok := c.Dependences[col.Name.L]; ok {
+			return errUnsupportedModifyColumn.GenByArgs(fmt.Sprintf("change the type of column %s depended by the generated column %s", col.Name, c.Name))
+		}
+	}
+	return nil
+}
+
 // ChangeColumn renames an existing column and modifies the column's definition,
-// currently we only support limited kind of changes
-// that do not need to change or check data on the table.
+// if the new type can't hold all the values of the origin type, the data of
+// the column is converted in a reorganization.
 func (d *ddl) ChangeColumn(ctx context.Context, ident ast.Ident, spec *ast.AlterTableSpec) error {
 	specNewColumn := spec.NewColumns[0]
 	if len(specNewColumn.Name.Schema.O) != 0 && ident.Schema.L != specNewColumn.Name.Schema.L {
@@ -1305,8 +1992,8 @@ func (d *ddl) ChangeColumn(ctx context.Context, ident ast.Ident, spec *ast.Alter
 	return errors.Trace(err)
 }
 
-// ModifyColumn does modification on an existing column, currently we only support limited kind of changes
-// that do not need to change or check data on the table.
+// ModifyColumn does modification on an existing column, if the new type can't hold
+// all the values of the origin type, the data of the column is converted in a reorganization.
 func (d *ddl) ModifyColumn(ctx context.Context, ident ast.Ident, spec *ast.AlterTableSpec) error {
 	specNewColumn := spec.NewColumns[0]
 	if len(specNewColumn.Name.Schema.O) != 0 && ident.Schema.L != specNewColumn.Name.Schema.L {
@@ -1379,14 +2066,24 @@ func (d *ddl) DropTable(ctx context.Context, ti ast.Ident) (err error) {
 		return infoschema.ErrDatabaseNotExists.GenByArgs(ti.Schema)
 	}
 
+	var tableID int64
 	tb, err := is.TableByName(ti.Schema, ti.Name)
-	if err != nil {
-		return errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ti.Schema, ti.Name))
+	if err == nil {
+		tableID = tb.Meta().ID
+	} else {
+		tblInfo, err1 := d.invisibleTableByName(schema.ID, ti.Name)
+		if err1 != nil {
+			return errors.Trace(err1)
+		}
+		if tblInfo == nil {
+			return errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ti.Schema, ti.Name))
+		}
+		tableID = tblInfo.ID
 	}
 
 	job := &model.Job{
 		SchemaID:   schema.ID,
-		TableID:    tb.Meta().ID,
+		TableID:    tableID,
 		Type:       model.ActionDropTable,
 		BinlogInfo: &model.HistoryInfo{},
 	}
@@ -1396,6 +2093,30 @@ func (d *ddl) DropTable(ctx context.Context, ti ast.Ident) (err error) {
 	return errors.Trace(err)
 }
 
+// RecoverTable recovers the table tbInfo dropped or truncated by the DDL job dropJobID into the schema schemaID,
+// the auto ID of the table is rebased to autoID. The caller should make sure the data of the table is not deleted.
+func (d *ddl) RecoverTable(ctx context.Context, tbInfo *model.TableInfo, schemaID, autoID, dropJobID int64, dropTS uint64) error {
+	is := d.GetInformationSchema()
+	schema, ok := is.SchemaByID(schemaID)
+	if !ok {
+		return infoschema.ErrDatabaseNotExists.GenByArgs(fmt.Sprintf("(Schema ID %d)", schemaID))
+	}
+	if is.TableExists(schema.Name, tbInfo.Name) {
+		return infoschema.ErrTableExists.GenByArgs(tbInfo.Name)
+	}
+
+	job := &model.Job{
+		SchemaID:   schemaID,
+		TableID:    tbInfo.ID,
+		Type:       model.ActionRecoverTable,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{tbInfo, autoID, dropJobID, dropTS},
+	}
+	err := d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
 func (d *ddl) TruncateTable(ctx context.Context, ti ast.Ident) error {
 	is := d.GetInformationSchema()
 	schema, ok := is.SchemaByName(ti.Schema)
@@ -1453,43 +2174,128 @@ func (d *ddl) RenameTable(ctx context.Context, oldIdent, newIdent ast.Ident) err
 	return errors.Trace(err)
 }
 
-func getAnonymousIndex(t table.Table, colName model.CIStr) model.CIStr {
+func (d *ddl) RenameTables(ctx context.Context, oldIdents, newIdents []ast.Ident) error {
+	if len(oldIdents) != len(newIdents) {
+		return errors.Errorf("the numbers of the old tables and the new tables are different")
+	}
+	is := d.GetInformationSchema()
+	oldSchemaIDs := make([]int64, 0, len(oldIdents))
+	newSchemaIDs := make([]int64, 0, len(oldIdents))
+	tableNames := make([]model.CIStr, 0, len(oldIdents))
+	tableIDs := make([]int64, 0, len(oldIdents))
+	// renamed records the tables renamed by the former pairs, the value is 0 if the name is released.
+	renamed := make(map[string]int64)
+	tableKey := func(ident ast.Ident) string {
+		return ident.Schema.L + "." + ident.Name.L
+	}
+	for i, oldIdent := range oldIdents {
+		newIdent := newIdents[i]
+		oldSchema, ok := is.SchemaByName(oldIdent.Schema)
+		if !ok {
+			return errFileNotFound.GenByArgs(oldIdent.Schema, oldIdent.Name)
+		}
+		tableID, ok := renamed[tableKey(oldIdent)]
+		if !ok {
+			oldTbl, err := is.TableByName(oldIdent.Schema, oldIdent.Name)
+			if err == nil {
+				tableID = oldTbl.Meta().ID
+			}
+		}
+		if tableID == 0 {
+			return errFileNotFound.GenByArgs(oldIdent.Schema, oldIdent.Name)
+		}
+		newSchema, ok := is.SchemaByName(newIdent.Schema)
+		if !ok {
+			return errErrorOnRename.GenByArgs(oldIdent.Schema, oldIdent.Name, newIdent.Schema, newIdent.Name)
+		}
+		newTableID, ok := renamed[tableKey(newIdent)]
+		if (ok && newTableID != 0) || (!ok && is.TableExists(newIdent.Schema, newIdent.Name)) {
+			return infoschema.ErrTableExists.GenByArgs(newIdent)
+		}
+
+		renamed[tableKey(oldIdent)] = 0
+		renamed[tableKey(newIdent)] = tableID
+		oldSchemaIDs = append(oldSchemaIDs, oldSchema.ID)
+		newSchemaIDs = append(newSchemaIDs, newSchema.ID)
+		tableNames = append(tableNames, newIdent.Name)
+		tableIDs = append(tableIDs, tableID)
+	}
+	if len(tableIDs) == 0 {
+		return nil
+	}
+
+	job := &model.Job{
+		SchemaID:   newSchemaIDs[0],
+		TableID:    tableIDs[0],
+		Type:       model.ActionRenameTables,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{oldSchemaIDs, newSchemaIDs, tableNames, tableIDs},
+	}
+
+	err := d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
+// getAnonymousIndex returns the name of the anonymous index on colName, it
+// isn't the name of any index of t or any name in allocated.
+func getAnonymousIndex(t table.Table, colName model.CIStr, allocated map[string]struct{}) model.CIStr {
 	id := 2
 	l := len(t.Indices())
 	indexName := colName
-	for i := 0; i < l; i++ {
-		if t.Indices()[i].Meta().Name.L == indexName.L {
-			indexName = model.NewCIStr(fmt.Sprintf("%s_%d", colName.O, id))
-			i = -1
-			id++
+	for {
+		_, used := allocated[indexName.L]
+		for i := 0; i < l && !used; i++ {
+			used = t.Indices()[i].Meta().Name.L == indexName.L
+		}
+		if !used {
+			return indexName
 		}
+		indexName = model.NewCIStr(fmt.Sprintf("%s_%d", colName.O, id))
+		id++
 	}
-	return indexName
 }
 
 func (d *ddl) CreateIndex(ctx context.Context, ti ast.Ident, unique bool, indexName model.CIStr,
 	idxColNames []*ast.IndexColName, indexOption *ast.IndexOption) error {
+	job, err := d.buildCreateIndexJob(ctx, ti, unique, indexName, idxColNames, indexOption)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	err = d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
+// buildCreateIndexJob checks the index and builds the job to add it.
+func (d *ddl) buildCreateIndexJob(ctx context.Context, ti ast.Ident, unique bool, indexName model.CIStr,
+	idxColNames []*ast.IndexColName, indexOption *ast.IndexOption) (*model.Job, error) {
 	is := d.infoHandle.Get()
 	schema, ok := is.SchemaByName(ti.Schema)
 	if !ok {
-		return infoschema.ErrDatabaseNotExists.GenByArgs(ti.Schema)
+		return nil, infoschema.ErrDatabaseNotExists.GenByArgs(ti.Schema)
 	}
 	t, err := is.TableByName(ti.Schema, ti.Name)
 	if err != nil {
-		return errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ti.Schema, ti.Name))
+		return nil, errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ti.Schema, ti.Name))
 	}
 
 	// Deal with anonymous index.
 	if len(indexName.L) == 0 {
-		indexName = getAnonymousIndex(t, idxColNames[0].Column.Name)
+		if idxColNames[0].Column == nil {
+			indexName = getAnonymousIndex(t, model.NewCIStr(expressionIndexName), nil)
+		} else {
+			indexName = getAnonymousIndex(t, idxColNames[0].Column.Name, nil)
+		}
 	}
 
 	if indexInfo := findIndexByName(indexName.L, t.Meta().Indices); indexInfo != nil {
-		return errDupKeyName.Gen("index already exist %s", indexName)
+		return nil, errDupKeyName.Gen("index already exist %s", indexName)
 	}
 
 	if err = checkTooLongIndex(indexName); err != nil {
-		return errors.Trace(err)
+		return nil, errors.Trace(err)
 	}
 
 	if indexOption != nil {
@@ -1499,7 +2305,16 @@ func (d *ddl) CreateIndex(ctx context.Context, ti ast.Ident, unique bool, indexN
 			maxCommentLength,
 			errTooLongIndexComment.GenByArgs(indexName.String(), maxCommentLength))
 		if err != nil {
-			return errors.Trace(err)
+			return nil, errors.Trace(err)
+		}
+	}
+
+	// The expression key parts are replaced with the hidden columns, which are added with the index.
+	var hiddenCols []*model.ColumnInfo
+	if hasExpressionKey(idxColNames) {
+		hiddenCols, err = buildHiddenColumns(ctx, t.Meta(), indexName, idxColNames)
+		if err != nil {
+			return nil, errors.Trace(err)
 		}
 	}
 
@@ -1508,12 +2323,9 @@ func (d *ddl) CreateIndex(ctx context.Context, ti ast.Ident, unique bool, indexN
 		TableID:    t.Meta().ID,
 		Type:       model.ActionAddIndex,
 		BinlogInfo: &model.HistoryInfo{},
-		Args:       []interface{}{unique, indexName, idxColNames, indexOption},
+		Args:       []interface{}{unique, indexName, idxColNames, indexOption, hiddenCols},
 	}
-
-	err = d.doDDLJob(ctx, job)
-	err = d.callHookOnChanged(err)
-	return errors.Trace(err)
+	return job, nil
 }
 
 func buildFKInfo(fkName model.CIStr, keys []*ast.IndexColName, refer *ast.ReferenceDef) (*model.FKInfo, error) {
@@ -1550,6 +2362,9 @@ func (d *ddl) CreateForeignKey(ctx context.Context, ti ast.Ident, fkName model.C
 		return errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ti.Schema, ti.Name))
 	}
 
+	if hasExpressionKey(keys) {
+		return infoschema.ErrCannotAddForeign
+	}
 	fkInfo, err := buildFKInfo(fkName, keys, refer)
 	if err != nil {
 		return errors.Trace(err)
@@ -1594,19 +2409,101 @@ func (d *ddl) DropForeignKey(ctx context.Context, ti ast.Ident, fkName model.CIS
 	return errors.Trace(err)
 }
 
-func (d *ddl) DropIndex(ctx context.Context, ti ast.Ident, indexName model.CIStr) error {
+func (d *ddl) CreateCheckConstraint(ctx context.Context, ti ast.Ident, name model.CIStr, expr ast.ExprNode) error {
 	is := d.infoHandle.Get()
 	schema, ok := is.SchemaByName(ti.Schema)
 	if !ok {
-		return errors.Trace(infoschema.ErrDatabaseNotExists)
+		return infoschema.ErrDatabaseNotExists.GenByArgs(ti.Schema)
+	}
+
+	t, err := is.TableByName(ti.Schema, ti.Name)
+	if err != nil {
+		return errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ti.Schema, ti.Name))
+	}
+
+	tblInfo := t.Meta()
+	if name.L == "" {
+		used := make(map[string]struct{}, len(tblInfo.Constraints))
+		for _, c := range tblInfo.Constraints {
+			used[c.Name.L] = struct{}{}
+		}
+		name = genCheckConstraintName(tblInfo.Name, used)
+	} else if tblInfo.FindConstraintByName(name.L) != nil {
+		return errCheckConstraintDupName.GenByArgs(name.O)
+	}
+
+	info, err := buildCheckConstraintInfo(ctx, tblInfo, name, expr, model.CIStr{}, model.StateNone)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    tblInfo.ID,
+		Type:       model.ActionAddCheckConstraint,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{info},
+	}
+
+	err = d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
+func (d *ddl) DropCheckConstraint(ctx context.Context, ti ast.Ident, name model.CIStr) error {
+	is := d.infoHandle.Get()
+	schema, ok := is.SchemaByName(ti.Schema)
+	if !ok {
+		return infoschema.ErrDatabaseNotExists.GenByArgs(ti.Schema)
 	}
+
 	t, err := is.TableByName(ti.Schema, ti.Name)
 	if err != nil {
 		return errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ti.Schema, ti.Name))
 	}
 
+	if c := t.Meta().FindConstraintByName(name.L); c == nil || c.State != model.StatePublic {
+		return errCheckConstraintNotFound.GenByArgs(name.O)
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    t.Meta().ID,
+		Type:       model.ActionDropCheckConstraint,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{name},
+	}
+
+	err = d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
+func (d *ddl) DropIndex(ctx context.Context, ti ast.Ident, indexName model.CIStr) error {
+	job, err := d.buildDropIndexJob(ti, indexName)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	err = d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
+// buildDropIndexJob checks the index and builds the job to drop it.
+func (d *ddl) buildDropIndexJob(ti ast.Ident, indexName model.CIStr) (*model.Job, error) {
+	is := d.infoHandle.Get()
+	schema, ok := is.SchemaByName(ti.Schema)
+	if !ok {
+		return nil, errors.Trace(infoschema.ErrDatabaseNotExists)
+	}
+	t, err := is.TableByName(ti.Schema, ti.Name)
+	if err != nil {
+		return nil, errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ti.Schema, ti.Name))
+	}
+
 	if indexInfo := findIndexByName(indexName.L, t.Meta().Indices); indexInfo == nil {
-		return ErrCantDropFieldOrKey.Gen("index %s doesn't exist", indexName)
+		return nil, ErrCantDropFieldOrKey.Gen("index %s doesn't exist", indexName)
 	}
 
 	job := &model.Job{
@@ -1616,6 +2513,96 @@ func (d *ddl) DropIndex(ctx context.Context, ti ast.Ident, indexName model.CIStr
 		BinlogInfo: &model.HistoryInfo{},
 		Args:       []interface{}{indexName},
 	}
+	return job, nil
+}
+
+// CreatePrimaryKey adds a primary key to the table. The rows of the table are
+// identified by the handle, so the primary key is added as a unique index
+// whose columns become NOT NULL.
+func (d *ddl) CreatePrimaryKey(ctx context.Context, ti ast.Ident, idxColNames []*ast.IndexColName,
+	indexOption *ast.IndexOption) error {
+	is := d.infoHandle.Get()
+	schema, ok := is.SchemaByName(ti.Schema)
+	if !ok {
+		return infoschema.ErrDatabaseNotExists.GenByArgs(ti.Schema)
+	}
+	t, err := is.TableByName(ti.Schema, ti.Name)
+	if err != nil {
+		return errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ti.Schema, ti.Name))
+	}
+
+	indexName := model.NewCIStr(mysql.PrimaryKeyName)
+	tblInfo := t.Meta()
+	if tblInfo.PKIsHandle || findIndexByName(indexName.L, tblInfo.Indices) != nil {
+		return infoschema.ErrMultiplePriKey
+	}
+	if hasExpressionKey(idxColNames) {
+		return errFunctionalIndexPrimaryKey
+	}
+	for _, key := range idxColNames {
+		col := findCol(tblInfo.Columns, key.Column.Name.L)
+		if col == nil {
+			return errKeyColumnDoesNotExits.Gen("key column %s doesn't exist in table", key.Column.Name)
+		}
+		// Virtual columns cannot be used in primary key.
+		if col.IsGenerated() && !col.GeneratedStored {
+			return errUnsupportedOnGeneratedColumn.GenByArgs("Defining a virtual generated column as primary key")
+		}
+	}
+
+	if indexOption != nil {
+		// May be truncate comment here, when index comment too long and sql_mode is't strict.
+		indexOption.Comment, err = validateCommentLength(ctx.GetSessionVars(),
+			indexOption.Comment,
+			maxCommentLength,
+			errTooLongIndexComment.GenByArgs(indexName.String(), maxCommentLength))
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    tblInfo.ID,
+		Type:       model.ActionAddPrimaryKey,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{true, indexName, idxColNames, indexOption},
+	}
+
+	err = d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
+// DropPrimaryKey drops the primary key of the table, the integer primary key
+// which is the handle of the rows can't be dropped.
+func (d *ddl) DropPrimaryKey(ctx context.Context, ti ast.Ident) error {
+	is := d.infoHandle.Get()
+	schema, ok := is.SchemaByName(ti.Schema)
+	if !ok {
+		return errors.Trace(infoschema.ErrDatabaseNotExists)
+	}
+	t, err := is.TableByName(ti.Schema, ti.Name)
+	if err != nil {
+		return errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ti.Schema, ti.Name))
+	}
+
+	indexName := model.NewCIStr(mysql.PrimaryKeyName)
+	tblInfo := t.Meta()
+	if tblInfo.PKIsHandle {
+		return ErrUnsupportedModifyPrimaryKey.GenByArgs("drop")
+	}
+	if indexInfo := findIndexByName(indexName.L, tblInfo.Indices); indexInfo == nil {
+		return ErrCantDropFieldOrKey.Gen("index %s doesn't exist", indexName)
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    tblInfo.ID,
+		Type:       model.ActionDropPrimaryKey,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{indexName},
+	}
 
 	err = d.doDDLJob(ctx, job)
 	err = d.callHookOnChanged(err)
@@ -1635,6 +2622,9 @@ func findCol(cols []*model.ColumnInfo, name string) *model.ColumnInfo {
 }
 
 func isDroppableColumn(tblInfo *model.TableInfo, colName model.CIStr) error {
+	if err := checkColumnNotUsedByExpressionIndex(tblInfo, colName); err != nil {
+		return errors.Trace(err)
+	}
 	// Check whether there are other columns depend on this column or not.
 	for _, col := range tblInfo.Columns {
 		for dep := range col.Dependences {
@@ -1652,7 +2642,7 @@ func isDroppableColumn(tblInfo *model.TableInfo, colName model.CIStr) error {
 	if isColumnWithIndex(colName.L, tblInfo.Indices) {
 		return errCantDropColWithIndex.Gen("can't drop column %s with index covered now", colName)
 	}
-	return nil
+	return errors.Trace(checkColumnNotUsedByCheckConstraint(tblInfo, colName))
 }
 
 // validateCommentLength checks comment length of table, column, index and partition.
@@ -14,10 +14,13 @@
 package ddl_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"math"
 	"math/rand"
+	"os"
 	"strconv"
 	"strings"
 	"time"
@@ -25,6 +28,7 @@ import (
 	"github.com/juju/errors"
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/ddl"
 	"github.com/pingcap/tidb/domain"
@@ -34,7 +38,9 @@ import (
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	tmysql "github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/store/tikv"
+	"github.com/pingcap/tidb/store/tikv/mocktikv"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/table/tables"
 	"github.com/pingcap/tidb/tablecodec"
@@ -60,6 +66,8 @@ var _ = Suite(&testDBSuite{})
 const defaultBatchSize = 2048
 
 type testDBSuite struct {
+	cluster    *mocktikv.Cluster
+	mvccStore  *mocktikv.MvccStore
 	store      kv.Storage
 	dom        *domain.Domain
 	schemaName string
@@ -78,7 +86,13 @@ func (s *testDBSuite) SetUpSuite(c *C) {
 	tidb.SetStatsLease(0)
 	s.schemaName = "test_db"
 
-	s.store, err = tikv.NewMockTikvStore()
+	s.cluster = mocktikv.NewCluster()
+	mocktikv.BootstrapWithSingleStore(s.cluster)
+	s.mvccStore = mocktikv.NewMvccStore()
+	s.store, err = tikv.NewMockTikvStore(
+		tikv.WithCluster(s.cluster),
+		tikv.WithMVCCStore(s.mvccStore),
+	)
 	c.Assert(err, IsNil)
 
 	s.dom, err = tidb.BootstrapSession(s.store)
@@ -205,6 +219,41 @@ func (s *testDBSuite) TestAddIndexWithPK(c *C) {
 	s.tk.MustQuery("select * from test_add_index_with_pk2").Check(testkit.Rows("1 1 1 1", "2 2 2 2"))
 }
 
+func (s *testDBSuite) TestAddIndexWithReorgVars(c *C) {
+	s.tk = testkit.NewTestKit(c, s.store)
+	s.tk.MustExec("use " + s.schemaName)
+	defer func() {
+		s.tk.MustExec(fmt.Sprintf("set @@global.tidb_ddl_reorg_worker_cnt = %d", variable.DefTiDBDDLReorgWorkerCount))
+		s.tk.MustExec(fmt.Sprintf("set @@global.tidb_ddl_reorg_batch_size = %d", variable.DefTiDBDDLReorgBatchSize))
+		s.tk.MustExec("set @@global.tidb_ddl_reorg_rate_limit = 0")
+		variable.SetDDLReorgWorkerCounter(variable.DefTiDBDDLReorgWorkerCount)
+		variable.SetDDLReorgBatchSize(variable.DefTiDBDDLReorgBatchSize)
+		variable.SetDDLReorgRateLimit(variable.DefTiDBDDLReorgRateLimit)
+	}()
+
+	s.tk.MustExec("set @@global.tidb_ddl_reorg_worker_cnt = 3")
+	s.tk.MustExec("set @@global.tidb_ddl_reorg_batch_size = 7")
+	s.tk.MustExec("set @@global.tidb_ddl_reorg_rate_limit = 100000")
+	s.tk.MustQuery("select @@global.tidb_ddl_reorg_worker_cnt, @@global.tidb_ddl_reorg_batch_size, @@global.tidb_ddl_reorg_rate_limit").
+		Check(testkit.Rows("3 7 100000"))
+
+	s.tk.MustExec("create table test_reorg_vars (a int primary key, b int)")
+	for i := 0; i < 100; i++ {
+		s.tk.MustExec(fmt.Sprintf("insert into test_reorg_vars values (%d, %d)", i*10, i))
+	}
+	// Split the table into several regions, so the rows are backfilled by several workers.
+	tbl := s.testGetTable(c, "test_reorg_vars")
+	s.cluster.SplitTable(s.mvccStore, tbl.Meta().ID, 5)
+
+	s.tk.MustExec("alter table test_reorg_vars add index idx_b (b)")
+	c.Assert(variable.GetDDLReorgWorkerCounter(), Equals, int32(3))
+	c.Assert(variable.GetDDLReorgBatchSize(), Equals, int32(7))
+	c.Assert(variable.GetDDLReorgRateLimit(), Equals, int64(100000))
+	s.tk.MustExec("admin check table test_reorg_vars")
+	s.tk.MustQuery("select count(*) from test_reorg_vars use index(idx_b) where b >= 0").Check(testkit.Rows("100"))
+	s.tk.MustExec("drop table test_reorg_vars")
+}
+
 func (s *testDBSuite) testGetTable(c *C, name string) table.Table {
 	ctx := s.s.(context.Context)
 	dom := domain.GetDomain(ctx)
@@ -987,10 +1036,76 @@ func (s *testDBSuite) TestPrimaryKey(c *C) {
 	s.tk = testkit.NewTestKit(c, s.store)
 	s.tk.MustExec("use " + s.schemaName)
 
-	s.mustExec(c, "create table primary_key_test (a int, b varchar(10))")
+	s.mustExec(c, "create table primary_key_test (a int, b varchar(10), c int)")
+	s.mustExec(c, "insert into primary_key_test values (1, 'a', 1), (null, 'b', 2), (2, 'c', 1)")
+
+	// The existing NULL values fail the job.
 	_, err := s.tk.Exec("alter table primary_key_test add primary key(a)")
-	c.Assert(ddl.ErrUnsupportedModifyPrimaryKey.Equal(err), IsTrue)
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Equals, "[ddl:1138]Invalid use of NULL value")
+	s.mustExec(c, "insert into primary_key_test values (null, 'd', 3)")
+	s.mustExec(c, "delete from primary_key_test where a is null")
+
+	// The duplicated entries fail the job.
+	_, err = s.tk.Exec("alter table primary_key_test add primary key(c)")
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Equals, "[kv:1062]Duplicate for key PRIMARY")
+	t := s.testGetTable(c, "primary_key_test")
+	c.Assert(t.Meta().Indices, HasLen, 0)
+	c.Assert(tmysql.HasPreventNullInsertFlag(t.Meta().Columns[2].Flag), IsFalse)
+
+	// NULL values can't be inserted when the primary key is being added.
+	tk2 := testkit.NewTestKit(c, s.store)
+	tk2.MustExec("use " + s.schemaName)
+	var checkErr error
+	hook := &ddl.TestDDLCallback{}
+	hook.OnJobUpdatedExported = func(job *model.Job) {
+		if job.Type != model.ActionAddPrimaryKey || job.SchemaState != model.StateWriteOnly || checkErr != nil {
+			return
+		}
+		if _, err1 := tk2.Exec("insert into primary_key_test values (null, 'e', 4)"); err1 == nil {
+			checkErr = errors.New("insert null into the primary key succeeded")
+			return
+		}
+		_, checkErr = tk2.Exec("insert into primary_key_test values (3, 'e', 4)")
+	}
+	originHook := s.dom.DDL().GetHook()
+	s.dom.DDL().SetHook(hook)
+	s.mustExec(c, "alter table primary_key_test add primary key(a, b)")
+	s.dom.DDL().SetHook(originHook)
+	c.Assert(checkErr, IsNil)
+	s.tk.MustExec("admin check table primary_key_test")
+	s.tk.MustQuery("select a, b from primary_key_test use index(`primary`) order by a").Check(testkit.Rows("1 a", "2 c", "3 e"))
+	t = s.testGetTable(c, "primary_key_test")
+	c.Assert(t.Meta().Indices, HasLen, 1)
+	c.Assert(t.Meta().Indices[0].Primary, IsTrue)
+	for _, col := range t.Meta().Columns[:2] {
+		c.Assert(col.Flag&(tmysql.PriKeyFlag|tmysql.NotNullFlag|tmysql.PreventNullInsertFlag), Equals, tmysql.PriKeyFlag|tmysql.NotNullFlag)
+	}
+	_, err = s.tk.Exec("insert into primary_key_test values (1, 'a', 5)")
+	c.Assert(err, NotNil)
+	_, err = s.tk.Exec("insert into primary_key_test values (null, 'a', 5)")
+	c.Assert(err, NotNil)
+	_, err = s.tk.Exec("alter table primary_key_test add primary key(c)")
+	c.Assert(infoschema.ErrMultiplePriKey.Equal(err), IsTrue)
+
+	// The columns are still NOT NULL after dropping the primary key.
+	s.mustExec(c, "alter table primary_key_test drop primary key")
+	s.mustExec(c, "insert into primary_key_test values (1, 'a', 5)")
+	_, err = s.tk.Exec("insert into primary_key_test values (null, 'a', 5)")
+	c.Assert(err, NotNil)
+	t = s.testGetTable(c, "primary_key_test")
+	c.Assert(t.Meta().Indices, HasLen, 0)
+	c.Assert(tmysql.HasPriKeyFlag(t.Meta().Columns[0].Flag), IsFalse)
+	c.Assert(tmysql.HasNotNullFlag(t.Meta().Columns[0].Flag), IsTrue)
 	_, err = s.tk.Exec("alter table primary_key_test drop primary key")
+	c.Assert(ddl.ErrCantDropFieldOrKey.Equal(err), IsTrue)
+
+	// The primary key which is the handle of the rows can't be changed.
+	s.mustExec(c, "create table primary_key_handle (a int primary key, b int)")
+	_, err = s.tk.Exec("alter table primary_key_handle add primary key(b)")
+	c.Assert(infoschema.ErrMultiplePriKey.Equal(err), IsTrue)
+	_, err = s.tk.Exec("alter table primary_key_handle drop primary key")
 	c.Assert(ddl.ErrUnsupportedModifyPrimaryKey.Equal(err), IsTrue)
 }
 
@@ -1049,8 +1164,9 @@ func (s *testDBSuite) TestChangeColumn(c *C) {
 	s.testErrorCode(c, sql, tmysql.ErrWrongTableName)
 	sql = "alter table t3 change aa a bigint not null"
 	s.testErrorCode(c, sql, tmysql.ErrUnknown)
-	sql = "alter table t3 modify en enum('a', 'z', 'b', 'c') not null default 'a'"
-	s.testErrorCode(c, sql, tmysql.ErrUnknown)
+	// The enum column is modified with its data converted.
+	s.mustExec(c, "alter table t3 modify en enum('a', 'z', 'b', 'c') not null default 'a'")
+	s.tk.MustQuery("select en from t3").Check(testkit.Rows("a", "a", "a"))
 
 	s.tk.MustExec("drop table t3")
 }
@@ -1329,6 +1445,59 @@ func (s *testDBSuite) TestCreateTable(c *C) {
 	s.tk.MustExec("drop table t")
 }
 
+func (s *testDBSuite) TestCreateTableWithSelect(c *C) {
+	s.tk = testkit.NewTestKit(c, s.store)
+	s.tk.MustExec("use test")
+	tk2 := testkit.NewTestKit(c, s.store)
+	tk2.MustExec("use test")
+	ctx := s.tk.Se.(context.Context)
+	ident := ast.Ident{Schema: model.NewCIStr("test"), Name: model.NewCIStr("t_select")}
+	colDefs := []*ast.ColumnDef{{
+		Name: &ast.ColumnName{Name: model.NewCIStr("a")},
+		Tp:   types.NewFieldType(mysql.TypeLong),
+	}}
+
+	// The table is dropped if it fails to be filled.
+	fillErr := errors.New("mock fill error")
+	err := s.dom.DDL().CreateTableWithSelect(ctx, ident, colDefs, nil, nil, func(tbl table.Table) error {
+		return fillErr
+	})
+	c.Assert(errors.Cause(err), Equals, fillErr)
+	_, err = tk2.Exec("select * from t_select")
+	c.Assert(infoschema.ErrTableNotExists.Equal(err), IsTrue)
+
+	// The table is invisible until it's filled.
+	err = s.dom.DDL().CreateTableWithSelect(ctx, ident, colDefs, nil, nil, func(tbl table.Table) error {
+		c.Assert(tbl.Meta().State, Equals, model.StateWriteReorganization)
+		_, err1 := tk2.Exec("select * from t_select")
+		c.Assert(infoschema.ErrTableNotExists.Equal(err1), IsTrue)
+		c.Assert(s.dom.InfoSchema().TableExists(ident.Schema, ident.Name), IsFalse)
+		// The transaction writes the table in the latest schema version.
+		is := s.dom.InfoSchema()
+		ctx.GetSessionVars().TxnCtx.InfoSchema = is
+		ctx.GetSessionVars().TxnCtx.SchemaVersion = is.SchemaMetaVersion()
+		_, err1 = tbl.AddRecord(ctx, types.MakeDatums(1), false)
+		c.Assert(err1, IsNil)
+		return ctx.NewTxn()
+	})
+	c.Assert(err, IsNil)
+	tk2.MustQuery("select * from t_select").Check(testkit.Rows("1"))
+	tk2.MustExec("drop table t_select")
+
+	// The invisible table holds its name, but it can be dropped by name, e.g. if it's left by an
+	// interrupted statement.
+	err = s.dom.DDL().CreateTableWithSelect(ctx, ident, colDefs, nil, nil, func(tbl table.Table) error {
+		_, err1 := tk2.Exec("create table t_select (b int)")
+		c.Assert(infoschema.ErrTableExists.Equal(err1), IsTrue)
+		tk2.MustExec("drop table t_select")
+		tk2.MustExec("create table t_select (b int)")
+		return fillErr
+	})
+	c.Assert(errors.Cause(err), Equals, fillErr)
+	tk2.MustQuery("select b from t_select").Check(testkit.Rows())
+	tk2.MustExec("drop table t_select")
+}
+
 func (s *testDBSuite) TestTruncateTable(c *C) {
 	tk := testkit.NewTestKit(c, s.store)
 	tk.MustExec("use test")
@@ -1438,14 +1607,309 @@ func (s *testDBSuite) TestRenameMultiTables(c *C) {
 	s.tk.MustExec("use test")
 	s.tk.MustExec("create table t1(id int)")
 	s.tk.MustExec("create table t2(id int)")
-	// Currently it will fail only.
-	sql := fmt.Sprintf("rename table t1 to t3, t2 to t4")
-	_, err := s.tk.Exec(sql)
+	s.tk.MustExec("insert t1 values (1)")
+	s.tk.MustExec("insert t2 values (2)")
+	ctx := s.tk.Se.(context.Context)
+	is := domain.GetDomain(ctx).InfoSchema()
+	t1, err := is.TableByName(model.NewCIStr("test"), model.NewCIStr("t1"))
+	c.Assert(err, IsNil)
+	t2, err := is.TableByName(model.NewCIStr("test"), model.NewCIStr("t2"))
+	c.Assert(err, IsNil)
+
+	// Swap the tables in one schema version.
+	s.tk.MustExec("rename table t1 to tmp, t2 to t1, tmp to t2")
+	job := s.testGetLatestHistoryJob(c)
+	c.Assert(job.Type, Equals, model.ActionRenameTables)
+	c.Assert(job.BinlogInfo.MultipleTableInfos, HasLen, 2)
+	newIs := domain.GetDomain(ctx).InfoSchema()
+	c.Assert(newIs.SchemaMetaVersion(), Equals, is.SchemaMetaVersion()+1)
+	newT1, err := newIs.TableByName(model.NewCIStr("test"), model.NewCIStr("t1"))
+	c.Assert(err, IsNil)
+	c.Assert(newT1.Meta().ID, Equals, t2.Meta().ID)
+	newT2, err := newIs.TableByName(model.NewCIStr("test"), model.NewCIStr("t2"))
+	c.Assert(err, IsNil)
+	c.Assert(newT2.Meta().ID, Equals, t1.Meta().ID)
+	c.Assert(newIs.TableExists(model.NewCIStr("test"), model.NewCIStr("tmp")), IsFalse)
+	s.tk.MustQuery("select * from t1").Check(testkit.Rows("2"))
+	s.tk.MustQuery("select * from t2").Check(testkit.Rows("1"))
+
+	// Nothing is renamed if one of the pairs fails.
+	s.tk.MustExec("create table t3(id int)")
+	s.testErrorCode(c, "rename table t1 to t4, t2 to t3", tmysql.ErrTableExists)
+	s.testErrorCode(c, "rename table t1 to t4, t5 to t6", tmysql.ErrFileNotFound)
+	s.testErrorCode(c, "rename table t1 to t4, t1 to t5", tmysql.ErrFileNotFound)
+	s.tk.MustQuery("select * from t1").Check(testkit.Rows("2"))
+	s.tk.MustQuery("select * from t2").Check(testkit.Rows("1"))
+	_, err = s.tk.Exec("select * from t4")
 	c.Assert(err, NotNil)
-	originErr := errors.Cause(err)
-	c.Assert(originErr.Error(), Equals, "can't run multi schema change")
 
-	s.tk.MustExec("drop table t1, t2")
+	// Rename the tables across databases.
+	s.tk.MustExec("create database test_rename_multi")
+	s.tk.MustExec("rename table t1 to test_rename_multi.t1, t2 to t4, t3 to test_rename_multi.t3")
+	s.tk.MustQuery("select * from test_rename_multi.t1").Check(testkit.Rows("2"))
+	s.tk.MustQuery("select * from t4").Check(testkit.Rows("1"))
+	s.tk.MustExec("insert test_rename_multi.t3 values (3)")
+	s.tk.MustQuery("select * from test_rename_multi.t3").Check(testkit.Rows("3"))
+	s.testErrorCode(c, "select * from t1", tmysql.ErrNoSuchTable)
+
+	s.tk.MustExec("drop database test_rename_multi")
+	s.tk.MustExec("drop table t4")
+}
+
+func (s *testDBSuite) TestMultiSchemaChange(c *C) {
+	s.tk = testkit.NewTestKit(c, s.store)
+	s.tk.MustExec("use " + s.schemaName)
+	s.tk.MustExec("create table t_multi (a int, b int)")
+	s.tk.MustExec("insert into t_multi values (1, 1), (2, 2), (3, 2)")
+
+	// The new columns become public at the same time.
+	var checkErr error
+	hook := &ddl.TestDDLCallback{}
+	hook.OnJobUpdatedExported = func(job *model.Job) {
+		if job.Type != model.ActionMultiSchemaChange || checkErr != nil {
+			return
+		}
+		checkErr = kv.RunInNewTxn(s.store, false, func(txn kv.Transaction) error {
+			tblInfo, err := meta.NewMeta(txn).GetTable(job.SchemaID, job.TableID)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			publicCount := 0
+			for _, col := range tblInfo.Columns {
+				if (col.Name.L == "c" || col.Name.L == "d") && col.State == model.StatePublic {
+					publicCount++
+				}
+			}
+			if publicCount == 1 {
+				return errors.Errorf("only one new column is public in %s", job)
+			}
+			return nil
+		})
+	}
+	originHook := s.dom.DDL().GetHook()
+	s.dom.DDL().SetHook(hook)
+	s.tk.MustExec("alter table t_multi add column c int default 5, add column d int not null, add index idx_c(c)")
+	s.dom.DDL().SetHook(originHook)
+	c.Assert(checkErr, IsNil)
+	s.tk.MustQuery("select * from t_multi use index(idx_c) where c = 5").Check(testkit.Rows("1 1 5 0", "2 2 5 0", "3 2 5 0"))
+	t := s.testGetTable(c, "t_multi")
+	c.Assert(t.Indices(), HasLen, 1)
+	c.Assert(t.Indices()[0].Meta().Columns[0].Offset, Equals, 2)
+
+	s.tk.MustExec("alter table t_multi add column (e int, f int)")
+	s.tk.MustExec("insert into t_multi values (4, 4, 4, 4, 4, 4)")
+	s.tk.MustExec("alter table t_multi drop column e, drop column f, drop index idx_c")
+	s.tk.MustQuery("select * from t_multi where a = 4").Check(testkit.Rows("4 4 4 4"))
+	t = s.testGetTable(c, "t_multi")
+	c.Assert(t.Cols(), HasLen, 4)
+	c.Assert(t.Indices(), HasLen, 0)
+
+	// All the sub-jobs are rolled back if one of them fails.
+	_, err := s.tk.Exec("alter table t_multi add column g int, add index idx_a(a), add unique index idx_b(b)")
+	c.Assert(err, NotNil)
+	c.Assert(kv.ErrKeyExists.Equal(err), IsTrue, Commentf("err %v", err))
+	t = s.testGetTable(c, "t_multi")
+	c.Assert(t.Meta().Columns, HasLen, 4)
+	c.Assert(t.Meta().Indices, HasLen, 0)
+
+	// A column or an index can only be changed once.
+	_, err = s.tk.Exec("alter table t_multi add column h int, add column h int")
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Matches, ".*column h is changed more than once.*")
+	_, err = s.tk.Exec("alter table t_multi add index idx_a(a), drop column a")
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Matches, ".*column a is changed more than once.*")
+	_, err = s.tk.Exec("alter table t_multi add index i(a), add index i(b)")
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Matches, ".*index i is changed more than once.*")
+	s.testErrorCode(c, "alter table t_multi drop column a, drop column b, drop column c, drop column d", tmysql.ErrCantRemoveAllFields)
+	// Other kinds of changes can't run together.
+	_, err = s.tk.Exec("alter table t_multi modify column a bigint, add column h int")
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Matches, ".*can't run multi schema change")
+
+	// The anonymous indices don't take the names of the indices added before them.
+	s.tk.MustExec("alter table t_multi add index (a), add index (a), add index (b)")
+	s.tk.MustExec("alter table t_multi add index (a), add index a_4(a), add index (a)")
+	t = s.testGetTable(c, "t_multi")
+	var indexNames []string
+	for _, idx := range t.Meta().Indices {
+		indexNames = append(indexNames, idx.Name.O)
+	}
+	c.Assert(indexNames, DeepEquals, []string{"a", "a_2", "b", "a_3", "a_4", "a_5"})
+	s.tk.MustExec("drop table t_multi")
+
+	// The job which keeps failing after it becomes non-revertible is cancelled, the columns and
+	// indices which aren't public are removed.
+	s.tk.MustExec("create table t_multi (a int, b int, c int, index idx_b(b))")
+	hook = &ddl.TestDDLCallback{}
+	hook.OnJobRunBeforeExported = func(job *model.Job) {
+		if job.Type != model.ActionMultiSchemaChange {
+			return
+		}
+		// Every non-revertible step fails since the dropped column is changed, after it reaches
+		// the state that can't be rolled back.
+		for _, sub := range job.MultiSchemaInfo.SubJobs {
+			if sub.Type == model.ActionDropColumn && !sub.Revertible {
+				sub.Args = []interface{}{model.NewCIStr("no_such_column")}
+				sub.RawArgs, _ = json.Marshal(sub.Args)
+			}
+		}
+	}
+	s.dom.DDL().SetHook(hook)
+	_, err = s.tk.Exec("alter table t_multi drop column c, add column d int, add index idx_d(d), drop index idx_b")
+	s.dom.DDL().SetHook(originHook)
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Matches, ".*column no_such_column doesn't exist")
+	t = s.testGetTable(c, "t_multi")
+	c.Assert(t.Meta().Columns, HasLen, 3)
+	c.Assert(t.Meta().Indices, HasLen, 1)
+	c.Assert(t.Meta().Indices[0].State, Equals, model.StatePublic)
+	s.tk.MustExec("alter table t_multi add column e int")
+	s.tk.MustExec("insert into t_multi values (1, 2, 3, 4)")
+	s.tk.MustQuery("select * from t_multi").Check(testkit.Rows("1 2 3 4"))
+	s.tk.MustExec("admin check table t_multi")
+
+	s.tk.MustExec("drop table t_multi")
+}
+
+func (s *testDBSuite) TestModifyColumnWithData(c *C) {
+	s.tk = testkit.NewTestKit(c, s.store)
+	s.tk.MustExec("use " + s.schemaName)
+	s.tk.MustExec("create table t_mc (a int, b varchar(10), c int, index idx_b(b), unique index idx_cb(c, b))")
+	s.tk.MustExec("insert into t_mc values (1, '10', 1), (2, '20', 2), (3, '3', 3)")
+
+	// Write the table when the changing column is writable.
+	tk2 := testkit.NewTestKit(c, s.store)
+	tk2.MustExec("use " + s.schemaName)
+	var checkErr error
+	hook := &ddl.TestDDLCallback{}
+	hook.OnJobUpdatedExported = func(job *model.Job) {
+		if job.Type != model.ActionModifyColumn || checkErr != nil {
+			return
+		}
+		switch job.SchemaState {
+		case model.StateWriteOnly:
+			_, checkErr = tk2.Exec("insert into t_mc values (4, '40', 4)")
+		case model.StateWriteReorganization:
+			if _, checkErr = tk2.Exec("update t_mc set b = '30' where a = 3"); checkErr != nil {
+				return
+			}
+			_, checkErr = tk2.Exec("delete from t_mc where a = 2")
+		}
+	}
+	originHook := s.dom.DDL().GetHook()
+	s.dom.DDL().SetHook(hook)
+	s.tk.MustExec("alter table t_mc modify column b int")
+	s.dom.DDL().SetHook(originHook)
+	c.Assert(checkErr, IsNil)
+	s.tk.MustQuery("select * from t_mc").Check(testkit.Rows("1 10 1", "3 30 3", "4 40 4"))
+	s.tk.MustQuery("select a from t_mc use index(idx_b) where b > 9 order by b").Check(testkit.Rows("1", "3", "4"))
+	s.tk.MustQuery("select a from t_mc use index(idx_cb) where c = 3 and b = 30").Check(testkit.Rows("3"))
+	s.tk.MustExec("admin check table t_mc")
+	t := s.testGetTable(c, "t_mc")
+	c.Assert(t.Cols(), HasLen, 3)
+	c.Assert(t.Cols()[1].Tp, Equals, tmysql.TypeLong)
+	c.Assert(t.Indices(), HasLen, 2)
+	c.Assert(t.Indices()[0].Meta().Name.L, Equals, "idx_b")
+
+	// The values which can't be converted fail the job in the strict mode.
+	s.tk.MustExec("insert into t_mc values (5, 300, 5)")
+	_, err := s.tk.Exec("alter table t_mc modify column b tinyint")
+	c.Assert(err, NotNil)
+	c.Assert(types.ErrOverflow.Equal(err), IsTrue, Commentf("err %v", err))
+	t = s.testGetTable(c, "t_mc")
+	c.Assert(t.Meta().Columns, HasLen, 3)
+	c.Assert(t.Meta().Indices, HasLen, 2)
+	s.tk.MustQuery("select b from t_mc where a = 5").Check(testkit.Rows("300"))
+
+	_, err = s.tk.Exec("alter table t_mc modify column b varchar(2)")
+	c.Assert(err, NotNil)
+	s.tk.MustExec("set @@sql_mode = ''")
+	s.tk.MustExec("alter table t_mc modify column b varchar(2)")
+	s.tk.MustExec("set @@sql_mode = default")
+	s.tk.MustQuery("select b from t_mc order by a").Check(testkit.Rows("10", "30", "40", "30"))
+
+	// The duplicated entries of the new unique index roll back the job.
+	s.tk.MustExec("update t_mc set b = '50' where a = 5")
+	s.tk.MustExec("alter table t_mc drop index idx_cb")
+	s.tk.MustExec("create unique index idx_b2 on t_mc(b)")
+	s.tk.MustExec("insert into t_mc values (6, '11', 6)")
+	s.tk.MustExec("set @@sql_mode = ''")
+	_, err = s.tk.Exec("alter table t_mc modify column b char(1)")
+	s.tk.MustExec("set @@sql_mode = default")
+	c.Assert(err, NotNil)
+	c.Assert(kv.ErrKeyExists.Equal(err), IsTrue, Commentf("err %v", err))
+	t = s.testGetTable(c, "t_mc")
+	c.Assert(t.Meta().Columns, HasLen, 3)
+	c.Assert(t.Meta().Indices, HasLen, 2)
+	s.tk.MustQuery("select a from t_mc use index(idx_b2) where b = '11'").Check(testkit.Rows("6"))
+
+	// Other changes of the types.
+	s.tk.MustExec("create table t_mc2 (a decimal(10, 2), b enum('x', 'y'), c datetime)")
+	s.tk.MustExec("insert into t_mc2 values (123.45, 'y', '2017-11-11 11:11:11')")
+	s.tk.MustExec("alter table t_mc2 modify column a decimal(5, 2)")
+	_, err = s.tk.Exec("alter table t_mc2 modify column a decimal(4, 2)")
+	c.Assert(err, NotNil)
+	s.tk.MustExec("alter table t_mc2 modify column b enum('z', 'y', 'x')")
+	s.tk.MustExec("alter table t_mc2 modify column c date")
+	s.tk.MustExec("insert into t_mc2 values (1, 'z', '2017-12-12')")
+	s.tk.MustQuery("select * from t_mc2").Check(testkit.Rows("123.45 y 2017-11-11", "1.00 z 2017-12-12"))
+
+	// The handle column and the columns of generated columns can't be converted.
+	s.tk.MustExec("create table t_mc3 (a int primary key, b varchar(10), c int as (b + 1))")
+	_, err = s.tk.Exec("alter table t_mc3 modify column a tinyint")
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Matches, ".*unsupported modify column change the type of the primary key handle column")
+	_, err = s.tk.Exec("alter table t_mc3 modify column b int")
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Matches, ".*unsupported modify column change the type of column b depended by .*")
+
+	// The values are converted in the time zone of the statement which modifies the column,
+	// by both the job and the DMLs in the other time zones.
+	s.tk.MustExec("create table t_mc4 (a int, b timestamp)")
+	s.tk.MustExec("set @@time_zone = '+08:00'")
+	s.tk.MustExec("insert into t_mc4 values (1, '2017-11-11 11:11:11')")
+	tk2.MustExec("set @@time_zone = '+00:00'")
+	written := false
+	hook.OnJobUpdatedExported = func(job *model.Job) {
+		if job.Type != model.ActionModifyColumn || job.SchemaState != model.StateWriteReorganization || written {
+			return
+		}
+		written = true
+		_, checkErr = tk2.Exec("insert into t_mc4 values (2, '2017-11-11 03:11:11')")
+	}
+	s.dom.DDL().SetHook(hook)
+	s.tk.MustExec("alter table t_mc4 modify column b datetime")
+	s.dom.DDL().SetHook(originHook)
+	c.Assert(checkErr, IsNil)
+	s.tk.MustQuery("select b from t_mc4 order by a").Check(testkit.Rows("2017-11-11 11:11:11", "2017-11-11 11:11:11"))
+	s.tk.MustExec("set @@time_zone = default")
+
+	// The values which can't be converted by the DMLs cancel the job instead of failing the DMLs.
+	s.tk.MustExec("create table t_mc5 (a int, b varchar(10))")
+	s.tk.MustExec("insert into t_mc5 values (1, '1')")
+	written = false
+	hook.OnJobUpdatedExported = func(job *model.Job) {
+		if job.Type != model.ActionModifyColumn || job.SchemaState != model.StateWriteReorganization || written {
+			return
+		}
+		written = true
+		_, checkErr = tk2.Exec("insert into t_mc5 values (2, 'x')")
+	}
+	s.dom.DDL().SetHook(hook)
+	_, err = s.tk.Exec("alter table t_mc5 modify column b int")
+	s.dom.DDL().SetHook(originHook)
+	c.Assert(checkErr, IsNil)
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Equals, "[ddl:12]cancelled DDL job")
+	s.tk.MustQuery("select * from t_mc5").Check(testkit.Rows("1 1", "2 x"))
+	t = s.testGetTable(c, "t_mc5")
+	c.Assert(t.Meta().Columns, HasLen, 2)
+	c.Assert(t.Meta().Columns[1].Tp, Equals, tmysql.TypeVarchar)
+	s.tk.MustExec("admin check table t_mc5")
+
+	s.tk.MustExec("drop table t_mc, t_mc2, t_mc3, t_mc4, t_mc5")
 }
 
 func (s *testDBSuite) TestAddNotNullColumn(c *C) {
@@ -1704,3 +2168,395 @@ func (s *testDBSuite) TestRebaseAutoID(c *C) {
 	s.tk.MustExec("create table tidb.test2 (a int);")
 	s.testErrorCode(c, "alter table tidb.test2 add column b int auto_increment key, auto_increment=10;", tmysql.ErrUnknown)
 }
+
+func (s *testDBSuite) testGetLatestHistoryJob(c *C) *model.Job {
+	txn, err := s.store.Begin()
+	c.Assert(err, IsNil)
+	defer txn.Rollback()
+	jobs, err := admin.GetHistoryDDLJobs(txn)
+	c.Assert(err, IsNil)
+	c.Assert(len(jobs), Greater, 0)
+	return jobs[0]
+}
+
+func (s *testDBSuite) TestRenameIndex(c *C) {
+	s.tk = testkit.NewTestKit(c, s.store)
+	s.tk.MustExec("use " + s.schemaName)
+	s.tk.MustExec("create table t_rename_index (a int primary key, b int, c int, index idx_b (b), unique key uk_c (c))")
+	s.tk.MustExec("insert into t_rename_index values (1, 1, 1), (2, 2, 2)")
+
+	s.tk.MustExec("alter table t_rename_index rename index idx_b to idx_b2")
+	c.Assert(s.testGetLatestHistoryJob(c).Type, Equals, model.ActionRenameIndex)
+	t := s.testGetTable(c, "t_rename_index")
+	c.Assert(t.Meta().Indices[0].Name.O, Equals, "idx_b2")
+	s.tk.MustQuery("select a from t_rename_index use index (idx_b2) where b = 2").Check(testkit.Rows("2"))
+	s.tk.MustExec("admin check index t_rename_index idx_b2")
+	_, err := s.tk.Exec("select a from t_rename_index use index (idx_b)")
+	c.Assert(err, NotNil)
+
+	// Change the case of the name.
+	s.tk.MustExec("alter table t_rename_index rename key idx_b2 to IDX_B2")
+	t = s.testGetTable(c, "t_rename_index")
+	c.Assert(t.Meta().Indices[0].Name.O, Equals, "IDX_B2")
+
+	s.testErrorCode(c, "alter table t_rename_index rename index idx_x to idx_y", tmysql.ErrKeyDoesNotExist)
+	s.testErrorCode(c, "alter table t_rename_index rename index idx_b2 to uk_c", tmysql.ErrDupKeyName)
+	s.testErrorCode(c, "alter table t_rename_index rename index idx_b2 to `primary`", tmysql.ErrWrongNameForIndex)
+	s.testErrorCode(c, "alter table t_rename_index rename index `primary` to idx_a", tmysql.ErrWrongNameForIndex)
+	s.tk.MustExec("drop table t_rename_index")
+}
+
+func (s *testDBSuite) TestAlterTableOptions(c *C) {
+	s.tk = testkit.NewTestKit(c, s.store)
+	s.tk.MustExec("use " + s.schemaName)
+	s.tk.MustExec("create table t_alter_options (a int, b varchar(10), c blob) charset utf8")
+
+	s.tk.MustExec("alter table t_alter_options comment = 'table comment'")
+	c.Assert(s.testGetLatestHistoryJob(c).Type, Equals, model.ActionModifyTableComment)
+	t := s.testGetTable(c, "t_alter_options")
+	c.Assert(t.Meta().Comment, Equals, "table comment")
+	s.testErrorCode(c, "alter table t_alter_options comment = '"+strings.Repeat("a", 2049)+"'", tmysql.ErrTooLongTableComment)
+	s.tk.MustExec("set @@sql_mode = ''")
+	s.tk.MustExec("alter table t_alter_options comment = '" + strings.Repeat("a", 2049) + "'")
+	s.tk.MustQuery("show warnings").Check(testutil.RowsWithSep("|", "Warning|1628|Comment for table 't_alter_options' is too long (max = 2048)"))
+	s.tk.MustExec("set @@sql_mode = default")
+	t = s.testGetTable(c, "t_alter_options")
+	c.Assert(t.Meta().Comment, Equals, strings.Repeat("a", 2048))
+	s.tk.MustExec("alter table t_alter_options comment = 'table comment'")
+
+	// Only the default charset of the table is changed.
+	s.tk.MustExec("alter table t_alter_options charset = utf8mb4")
+	c.Assert(s.testGetLatestHistoryJob(c).Type, Equals, model.ActionModifyTableCharsetAndCollate)
+	t = s.testGetTable(c, "t_alter_options")
+	c.Assert(t.Meta().Charset, Equals, "utf8mb4")
+	c.Assert(t.Meta().Collate, Equals, "utf8mb4_bin")
+	c.Assert(t.Meta().Columns[1].Charset, Equals, "utf8")
+	s.tk.MustExec("alter table t_alter_options collate = utf8_general_ci")
+	t = s.testGetTable(c, "t_alter_options")
+	c.Assert(t.Meta().Charset, Equals, "utf8")
+	c.Assert(t.Meta().Collate, Equals, "utf8_general_ci")
+	s.testErrorCode(c, "alter table t_alter_options charset = gbk", tmysql.ErrUnknown)
+	s.testErrorCode(c, "alter table t_alter_options charset = utf8 collate = latin1_bin", tmysql.ErrUnknown)
+
+	// The string columns are converted too.
+	s.tk.MustExec("insert into t_alter_options values (1, 'abc', 'abc')")
+	s.tk.MustExec("alter table t_alter_options convert to character set utf8mb4")
+	c.Assert(s.testGetLatestHistoryJob(c).Type, Equals, model.ActionModifyTableCharsetAndCollate)
+	t = s.testGetTable(c, "t_alter_options")
+	c.Assert(t.Meta().Charset, Equals, "utf8mb4")
+	c.Assert(t.Meta().Columns[0].Charset, Equals, "binary")
+	c.Assert(t.Meta().Columns[1].Charset, Equals, "utf8mb4")
+	c.Assert(t.Meta().Columns[1].Collate, Equals, "utf8mb4_bin")
+	c.Assert(t.Meta().Columns[2].Charset, Equals, "binary")
+	s.tk.MustQuery("select * from t_alter_options").Check(testkit.Rows("1 abc abc"))
+	s.testErrorCode(c, "alter table t_alter_options convert to character set latin1", tmysql.ErrUnknown)
+	t = s.testGetTable(c, "t_alter_options")
+	c.Assert(t.Meta().Charset, Equals, "utf8mb4")
+
+	// The options which aren't stored are ignored.
+	s.tk.MustExec("alter table t_alter_options engine = innodb, comment = 'new comment'")
+	t = s.testGetTable(c, "t_alter_options")
+	c.Assert(t.Meta().Comment, Equals, "new comment")
+	s.tk.MustExec("drop table t_alter_options")
+}
+
+func (s *testDBSuite) TestAlterDatabase(c *C) {
+	s.tk = testkit.NewTestKit(c, s.store)
+	s.tk.MustExec("create database test_alter_db charset utf8")
+	s.tk.MustExec("alter database test_alter_db charset = utf8mb4")
+	c.Assert(s.testGetLatestHistoryJob(c).Type, Equals, model.ActionModifySchemaCharsetAndCollate)
+	dbInfo, ok := s.dom.InfoSchema().SchemaByName(model.NewCIStr("test_alter_db"))
+	c.Assert(ok, IsTrue)
+	c.Assert(dbInfo.Charset, Equals, "utf8mb4")
+	c.Assert(dbInfo.Collate, Equals, "utf8mb4_bin")
+
+	s.tk.MustExec("alter database test_alter_db default collate utf8_general_ci")
+	dbInfo, ok = s.dom.InfoSchema().SchemaByName(model.NewCIStr("test_alter_db"))
+	c.Assert(ok, IsTrue)
+	c.Assert(dbInfo.Charset, Equals, "utf8")
+	c.Assert(dbInfo.Collate, Equals, "utf8_general_ci")
+
+	s.testErrorCode(c, "alter database test_alter_db charset = gbk", tmysql.ErrUnknown)
+	s.testErrorCode(c, "alter database test_alter_db_not_exists charset = utf8", tmysql.ErrBadDB)
+	s.tk.MustExec("drop database test_alter_db")
+}
+
+func (s *testDBSuite) TestCheckConstraint(c *C) {
+	s.tk = testkit.NewTestKit(c, s.store)
+	s.tk.MustExec("use " + s.schemaName)
+	s.tk.MustExec("drop table if exists t_check")
+	s.tk.MustExec("create table t_check (a int check (a > 0), b int, c int auto_increment key, constraint b_lt_10 check (b < 10), check (a < b))")
+	s.tk.MustQuery("show create table t_check").Check(testkit.Rows("t_check CREATE TABLE `t_check` (\n" +
+		"  `a` int(11) DEFAULT NULL,\n" +
+		"  `b` int(11) DEFAULT NULL,\n" +
+		"  `c` int(11) NOT NULL AUTO_INCREMENT,\n" +
+		"  PRIMARY KEY (`c`),\n" +
+		"  CONSTRAINT `b_lt_10` CHECK (`b` < 10),\n" +
+		"  CONSTRAINT `t_check_chk_1` CHECK (`a` < `b`),\n" +
+		"  CONSTRAINT `t_check_chk_2` CHECK (`a` > 0)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin"))
+	s.tk.MustQuery("select constraint_name from information_schema.table_constraints where table_name = 't_check' and constraint_type = 'CHECK'").
+		Check(testkit.Rows("b_lt_10", "t_check_chk_1", "t_check_chk_2"))
+
+	// The rows are checked when they are written.
+	s.tk.MustExec("insert into t_check (a, b) values (1, 2), (null, 3)")
+	s.testErrorCode(c, "insert into t_check (a, b) values (0, 2)", tmysql.ErrCheckConstraintViolated)
+	s.testErrorCode(c, "insert into t_check (a, b) values (3, 2)", tmysql.ErrCheckConstraintViolated)
+	s.testErrorCode(c, "replace into t_check (a, b, c) values (1, 10, 1)", tmysql.ErrCheckConstraintViolated)
+	s.testErrorCode(c, "update t_check set b = 1 where c = 1", tmysql.ErrCheckConstraintViolated)
+	s.testErrorCode(c, "insert into t_check (a, b, c) values (1, 2, 1) on duplicate key update b = 20", tmysql.ErrCheckConstraintViolated)
+	s.tk.MustExec("insert ignore into t_check (a, b) values (5, 6), (0, 1)")
+	c.Assert(s.tk.Se.GetSessionVars().StmtCtx.WarningCount(), Equals, uint16(1))
+	s.tk.MustExec("update ignore t_check set b = b + 4")
+	c.Assert(s.tk.Se.GetSessionVars().StmtCtx.WarningCount(), Equals, uint16(1))
+	s.tk.MustQuery("select a, b from t_check").Check(testkit.Rows("1 6", "<nil> 7", "5 6"))
+
+	// Invalid constraints.
+	s.testErrorCode(c, "create table t_check_err (a int check (b > 0), b int)", tmysql.ErrColumnCheckConstraintReferencesOtherColumn)
+	s.testErrorCode(c, "create table t_check_err (a int, check (c > 0))", tmysql.ErrCheckConstraintRefersUnknownColumn)
+	s.testErrorCode(c, "create table t_check_err (a int auto_increment key, check (a > 0))", tmysql.ErrCheckConstraintRefersAutoIncrementColumn)
+	s.testErrorCode(c, "create table t_check_err (a int, check (a > rand()))", tmysql.ErrCheckConstraintFunctionIsNotAllowed)
+	s.testErrorCode(c, "create table t_check_err (a int, check (a > @x))", tmysql.ErrCheckConstraintVariables)
+	s.testErrorCode(c, "create table t_check_err (a int, constraint c1 check (a > 0), constraint c1 check (a < 10))", tmysql.ErrCheckConstraintDupName)
+	s.testErrorCode(c, "alter table t_check add constraint b_lt_10 check (b < 20)", tmysql.ErrCheckConstraintDupName)
+	s.testErrorCode(c, "alter table t_check drop check c1", tmysql.ErrCheckConstraintNotFound)
+	s.testErrorCode(c, "alter table t_check drop column b", tmysql.ErrDependentByCheckConstraint)
+	s.testErrorCode(c, "alter table t_check change b d int", tmysql.ErrDependentByCheckConstraint)
+
+	// The existing rows are validated when adding a constraint.
+	s.testErrorCode(c, "alter table t_check add constraint b_gt_6 check (b > 6)", tmysql.ErrCheckConstraintViolated)
+	job := s.testGetLatestHistoryJob(c)
+	c.Assert(job.Type, Equals, model.ActionAddCheckConstraint)
+	c.Assert(job.State, Equals, model.JobStateRollbackDone)
+	t := s.testGetTable(c, "t_check")
+	c.Assert(t.Meta().FindConstraintByName("b_gt_6"), IsNil)
+	s.tk.MustExec("insert into t_check (a, b) values (1, 2)")
+
+	s.tk.MustExec("alter table t_check add check (b > 1)")
+	t = s.testGetTable(c, "t_check")
+	info := t.Meta().FindConstraintByName("t_check_chk_3")
+	c.Assert(info, NotNil)
+	c.Assert(info.State, Equals, model.StatePublic)
+	s.testErrorCode(c, "insert into t_check (a, b) values (null, 1)", tmysql.ErrCheckConstraintViolated)
+
+	s.tk.MustExec("alter table t_check drop check t_check_chk_3")
+	s.tk.MustExec("alter table t_check drop check b_lt_10")
+	s.tk.MustExec("insert into t_check (a, b) values (null, 1), (null, 100)")
+	s.tk.MustQuery("select count(*) from information_schema.table_constraints where table_name = 't_check' and constraint_type = 'CHECK'").
+		Check(testkit.Rows("2"))
+	s.tk.MustExec("drop table t_check")
+
+	// The constraints on timestamps are evaluated in the time zone of the statement which adds them.
+	s.tk.MustExec("set @@time_zone = '+08:00'")
+	s.tk.MustExec("create table t_check (a timestamp null, check (a > '2017-11-11 10:00:00'))")
+	s.tk.MustExec("set @@time_zone = '+00:00'")
+	// They're 2017-11-11 09:00:00 and 13:00:00 in +08:00.
+	s.testErrorCode(c, "insert into t_check values ('2017-11-11 01:00:00')", tmysql.ErrCheckConstraintViolated)
+	s.tk.MustExec("insert into t_check values ('2017-11-11 05:00:00')")
+	s.tk.MustExec("set @@time_zone = '+08:00'")
+	s.tk.MustExec("insert into t_check values ('2017-11-11 11:00:00')")
+	s.tk.MustExec("alter table t_check add constraint a_lt_14 check (a < '2017-11-11 14:00:00')")
+	s.tk.MustExec("set @@time_zone = '+00:00'")
+	s.tk.MustExec("insert into t_check values ('2017-11-11 05:30:00')")
+	s.testErrorCode(c, "insert into t_check values ('2017-11-11 06:00:00')", tmysql.ErrCheckConstraintViolated)
+	// The rows are validated in +08:00 too, the latest one is 2017-11-11 13:30:00 in it.
+	s.tk.MustExec("set @@time_zone = '+08:00'")
+	s.testErrorCode(c, "alter table t_check add check (a < '2017-11-11 13:20:00')", tmysql.ErrCheckConstraintViolated)
+	s.tk.MustExec("alter table t_check add check (a < '2017-11-11 13:40:00')")
+	s.tk.MustExec("set @@time_zone = default")
+	s.tk.MustExec("drop table t_check")
+}
+
+func (s *testDBSuite) TestAlterIndexVisibility(c *C) {
+	s.tk = testkit.NewTestKit(c, s.store)
+	s.tk.MustExec("use " + s.schemaName)
+	s.tk.MustExec("drop table if exists t_invisible")
+	s.tk.MustExec("create table t_invisible (a int, b int, c int, d int, primary key (c, d), index idx_a (a), unique key uk_b (b))")
+	s.tk.MustExec("insert into t_invisible values (1, 1, 1, 1), (2, 2, 2, 2)")
+	// Only idx_a can be used by the queries.
+	usesIndex := func(sql string) bool {
+		for _, row := range s.tk.MustQuery("explain " + sql).Rows() {
+			if strings.Contains(fmt.Sprintf("%v", row), "IndexScan") {
+				return true
+			}
+		}
+		return false
+	}
+	c.Assert(usesIndex("select a from t_invisible where a = 1"), IsTrue)
+
+	s.tk.MustExec("alter table t_invisible alter index idx_a invisible")
+	t := s.testGetTable(c, "t_invisible")
+	for _, idx := range t.Meta().Indices {
+		c.Assert(idx.Invisible, Equals, idx.Name.L == "idx_a")
+	}
+	job := s.testGetLatestHistoryJob(c)
+	c.Assert(job.Type, Equals, model.ActionAlterIndexVisibility)
+	// The optimizer ignores the index, but it's still maintained.
+	c.Assert(usesIndex("select a from t_invisible where a = 1"), IsFalse)
+	s.testErrorCode(c, "select a from t_invisible use index (idx_a) where a = 1", tmysql.ErrKeyDoesNotExist)
+	s.tk.MustExec("insert into t_invisible values (3, 3, 3, 3)")
+	s.tk.MustExec("update t_invisible set a = 4 where c = 1")
+	s.tk.MustExec("admin check table t_invisible")
+	s.tk.MustQuery("select index_name, is_visible from information_schema.statistics where table_name = 't_invisible'").
+		Check(testkit.Rows("PRIMARY YES", "PRIMARY YES", "idx_a NO", "uk_b YES"))
+	s.tk.MustQuery("show index from t_invisible where Key_name = 'idx_a'").Check(testkit.Rows(
+		"t_invisible 1 idx_a 1 a A 0 <nil> <nil> YES BTREE   NO"))
+	// The unique index still checks the duplicated keys when it's invisible.
+	s.tk.MustExec("alter table t_invisible alter index uk_b invisible")
+	s.testErrorCode(c, "insert into t_invisible values (5, 1, 5, 5)", tmysql.ErrDupEntry)
+
+	s.tk.MustExec("alter table t_invisible alter index idx_a visible")
+	c.Assert(usesIndex("select a from t_invisible where a = 4"), IsTrue)
+	s.tk.MustQuery("select a from t_invisible use index (idx_a) where a = 4").Check(testkit.Rows("4"))
+
+	s.testErrorCode(c, "alter table t_invisible alter index idx_x invisible", tmysql.ErrKeyDoesNotExist)
+	s.testErrorCode(c, "alter table t_invisible alter index `primary` invisible", tmysql.ErrPKIndexCantBeInvisible)
+	s.tk.MustExec("drop table t_invisible")
+}
+
+func (s *testDBSuite) TestRecoverTable(c *C) {
+	// The stores which don't support delete-range delete the data of the dropped tables at once.
+	s.tk = testkit.NewTestKit(c, s.store)
+	s.tk.MustExec("use " + s.schemaName)
+	s.tk.MustExec("create table t_recover (a int)")
+	s.tk.MustExec("drop table t_recover")
+	_, err := s.tk.Exec("recover table t_recover")
+	c.Assert(err, ErrorMatches, "recover table is not supported by the store.*")
+
+	// The stores which support delete-range keep the data of the dropped tables until GC.
+	dir, err := ioutil.TempDir("", "recover_table")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+	store, err := tikv.NewUniStore(dir)
+	c.Assert(err, IsNil)
+	defer store.Close()
+	dom, err := tidb.BootstrapSession(store)
+	c.Assert(err, IsNil)
+	defer dom.Close()
+
+	tk := testkit.NewTestKit(c, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t_recover (a int primary key auto_increment, b int, index (b))")
+	tk.MustExec("insert t_recover (b) values (1), (2), (3)")
+	tk.MustExec("drop table t_recover")
+	_, err = tk.Exec("recover table t_not_exist")
+	c.Assert(err, NotNil)
+	tk.MustExec("recover table t_recover")
+	tk.MustQuery("select * from t_recover").Check(testkit.Rows("1 1", "2 2", "3 3"))
+	tk.MustExec("admin check table t_recover")
+	// The auto ID is rebased to the value before the table is dropped.
+	tk.MustExec("insert t_recover (b) values (4)")
+	tk.MustQuery("select a from t_recover where b = 4").Check(testkit.Rows("5001"))
+	// The table is not dropped anymore.
+	_, err = tk.Exec("recover table t_recover")
+	c.Assert(err, NotNil)
+
+	tk.MustExec("truncate table t_recover")
+	tk.MustQuery("select count(*) from t_recover").Check(testkit.Rows("0"))
+	_, err = tk.Exec("recover table t_recover")
+	c.Assert(err, NotNil)
+	tk.MustExec("flashback table t_recover to t_flashback")
+	tk.MustQuery("select b from t_flashback").Check(testkit.Rows("1", "2", "3", "4"))
+	tk.MustExec("admin check table t_flashback")
+	_, err = tk.Exec("flashback table t_recover to t_flashback2")
+	c.Assert(err, NotNil)
+
+	// The table can't be recovered after its data is deleted by GC.
+	tk.MustExec("drop table t_flashback")
+	tk.MustExec("delete from mysql.gc_delete_range")
+	_, err = tk.Exec("recover table t_flashback")
+	c.Assert(err, NotNil)
+
+	// The DDL job checks the delete range again, GC may delete it after the statement checks it.
+	tk.MustExec("create table t_recover2 (a int)")
+	tk.MustExec("drop table t_recover2")
+	var hookErr error
+	hook := &ddl.TestDDLCallback{}
+	hook.OnJobRunBeforeExported = func(job *model.Job) {
+		if job.Type == model.ActionRecoverTable && hookErr == nil {
+			_, hookErr = testkit.NewTestKit(c, store).Exec("delete from mysql.gc_delete_range")
+		}
+	}
+	dom.DDL().SetHook(hook)
+	_, err = tk.Exec("recover table t_recover2")
+	c.Assert(hookErr, IsNil)
+	c.Assert(err, ErrorMatches, ".*can't recover table t_recover2.*")
+	tk.MustQuery("show tables like 't_recover2'").Check(testkit.Rows())
+}
+
+func (s *testDBSuite) TestExpressionIndex(c *C) {
+	s.tk = testkit.NewTestKit(c, s.store)
+	s.tk.MustExec("use " + s.schemaName)
+	s.tk.MustExec("drop table if exists t_expr_idx")
+	s.tk.MustExec("create table t_expr_idx (id int primary key, email varchar(64), a int, b int, key idx_lower ((lower(email))))")
+	// The hidden columns aren't visible to the users.
+	s.tk.MustExec("insert into t_expr_idx values (1, 'Foo@x.com', 1, 2), (2, 'bar@x.com', 3, 4)")
+	s.tk.MustQuery("select * from t_expr_idx where id = 1").Check(testkit.Rows("1 Foo@x.com 1 2"))
+	s.tk.MustQuery("show create table t_expr_idx").Check(testkit.Rows("t_expr_idx CREATE TABLE `t_expr_idx` (\n" +
+		"  `id` int(11) NOT NULL,\n" +
+		"  `email` varchar(64) DEFAULT NULL,\n" +
+		"  `a` int(11) DEFAULT NULL,\n" +
+		"  `b` int(11) DEFAULT NULL,\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  KEY `idx_lower` ((lower(`email`)))\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin"))
+	c.Assert(s.tk.MustQuery("show columns from t_expr_idx").Rows(), HasLen, 4)
+	s.tk.MustQuery("select column_name from information_schema.statistics where table_name = 't_expr_idx' and index_name = 'idx_lower'").
+		Check(testkit.Rows("<nil>"))
+
+	usesIndex := func(sql string) bool {
+		for _, row := range s.tk.MustQuery("explain " + sql).Rows() {
+			if strings.Contains(fmt.Sprintf("%v", row), "IndexScan") {
+				return true
+			}
+		}
+		return false
+	}
+	c.Assert(usesIndex("select id from t_expr_idx where lower(email) = 'foo@x.com'"), IsTrue)
+	s.tk.MustQuery("select id from t_expr_idx where lower(email) = 'foo@x.com'").Check(testkit.Rows("1"))
+
+	// The existing rows are backfilled.
+	s.tk.MustExec("create index idx_sum on t_expr_idx ((a + b) desc, id)")
+	s.tk.MustExec("admin check table t_expr_idx")
+	c.Assert(usesIndex("select id from t_expr_idx where a + b = 7"), IsTrue)
+	s.tk.MustQuery("select id from t_expr_idx where a + b = 7").Check(testkit.Rows("2"))
+	s.tk.MustExec("update t_expr_idx set a = 10 where id = 1")
+	s.tk.MustExec("delete from t_expr_idx where id = 2")
+	s.tk.MustExec("admin check table t_expr_idx")
+	s.tk.MustQuery("select id from t_expr_idx where a + b = 12").Check(testkit.Rows("1"))
+
+	// Invalid expression indexes.
+	s.testErrorCode(c, "create index idx_x on t_expr_idx ((a))", tmysql.ErrFunctionalIndexOnField)
+	s.testErrorCode(c, "create index idx_x on t_expr_idx ((a + rand()))", tmysql.ErrFunctionalIndexFunctionIsNotAllowed)
+	s.testErrorCode(c, "create table t_expr_idx1 (a int, primary key ((a + 1)))", tmysql.ErrFunctionalIndexPrimaryKey)
+	s.testErrorCode(c, "create table t_expr_idx1 (a int auto_increment, key (a), key ((a + 1)))", tmysql.ErrFunctionalIndexRefAutoIncrement)
+	s.testErrorCode(c, "alter table t_expr_idx drop column email", tmysql.ErrCannotDropColumnFunctionalIndex)
+
+	// Dropping the index drops its hidden columns.
+	s.tk.MustExec("drop index idx_lower on t_expr_idx")
+	t := s.testGetTable(c, "t_expr_idx")
+	hiddenCount := 0
+	for _, col := range t.Meta().Columns {
+		if col.Hidden {
+			hiddenCount++
+		}
+	}
+	c.Assert(hiddenCount, Equals, 1)
+	s.tk.MustExec("alter table t_expr_idx drop column email")
+	s.tk.MustExec("admin check table t_expr_idx")
+	s.tk.MustExec("drop table t_expr_idx")
+
+	// The points in a constant geometry are looked up by the indexes on their coordinates.
+	s.tk.MustExec("drop table if exists t_expr_idx_point")
+	s.tk.MustExec("create table t_expr_idx_point (id int primary key, p point, key idx_xy ((st_x(p)), (st_y(p))))")
+	s.tk.MustExec("insert into t_expr_idx_point values (1, st_geomfromtext('point(1 1)')), (2, st_geomfromtext('point(3 5)')), " +
+		"(3, st_geomfromtext('point(10 10)')), (4, st_geomfromtext('point(3.5 0.5)'))")
+	polygon := "st_geomfromtext('polygon((0 0,4 0,0 4,0 0))')"
+	c.Assert(usesIndex("select id from t_expr_idx_point where st_contains("+polygon+", p)"), IsTrue)
+	c.Assert(usesIndex("select id from t_expr_idx_point where st_within(p, "+polygon+")"), IsTrue)
+	c.Assert(usesIndex("select id from t_expr_idx_point where mbrcontains("+polygon+", p)"), IsTrue)
+	s.tk.MustQuery("select id from t_expr_idx_point where st_contains(" + polygon + ", p) order by id").Check(testkit.Rows("1"))
+	s.tk.MustQuery("select id from t_expr_idx_point where st_within(p, " + polygon + ") order by id").Check(testkit.Rows("1"))
+	s.tk.MustQuery("select id from t_expr_idx_point where mbrcontains(" + polygon + ", p) order by id").Check(testkit.Rows("1", "4"))
+	s.tk.MustExec("drop table t_expr_idx_point")
+}
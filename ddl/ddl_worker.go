@@ -133,7 +133,8 @@ func (d *ddl) updateDDLJob(t *meta.Meta, job *model.Job, updateTS uint64) error
 // If the DDL job need to handle in background, it will prepare a background job.
 func (d *ddl) finishDDLJob(t *meta.Meta, job *model.Job) (err error) {
 	switch job.Type {
-	case model.ActionDropSchema, model.ActionDropTable, model.ActionTruncateTable, model.ActionDropIndex:
+	case model.ActionDropSchema, model.ActionDropTable, model.ActionTruncateTable, model.ActionDropIndex,
+		model.ActionDropPrimaryKey, model.ActionMultiSchemaChange, model.ActionModifyColumn:
 		if job.Version <= currentVersion {
 			err = d.delRangeManager.addDelRangeJob(job)
 		} else {
@@ -256,9 +257,14 @@ func (d *ddl) runDDLJob(t *meta.Meta, job *model.Job) (ver int64) {
 		return
 	}
 	// The cause of this job state is that the job is cancelled by client.
-	if job.IsCancelling() {
+	// The multi-schema change job handles it by itself, because its sub-jobs may need to be rolled back.
+	// So does the modifying column job which has added the changing column, and the adding
+	// CHECK constraint job which has added the constraint.
+	if job.IsCancelling() && job.Type != model.ActionMultiSchemaChange &&
+		!(job.Type == model.ActionModifyColumn && job.SchemaState != model.StateNone) &&
+		!(job.Type == model.ActionAddCheckConstraint && job.SchemaState != model.StateNone) {
 		// If the value of SnapshotVer isn't zero, it means the work is backfilling the indexes.
-		if job.Type == model.ActionAddIndex && job.SchemaState == model.StateWriteReorganization && job.SnapshotVer != 0 {
+		if (job.Type == model.ActionAddIndex || job.Type == model.ActionAddPrimaryKey) && job.SchemaState == model.StateWriteReorganization && job.SnapshotVer != 0 {
 			log.Infof("[ddl] run the cancelling DDL job %s", job)
 			asyncNotify(d.reorgCtx.notifyCancelReorgJob)
 		} else {
@@ -279,8 +285,12 @@ func (d *ddl) runDDLJob(t *meta.Meta, job *model.Job) (ver int64) {
 		ver, err = d.onCreateSchema(t, job)
 	case model.ActionDropSchema:
 		ver, err = d.onDropSchema(t, job)
+	case model.ActionModifySchemaCharsetAndCollate:
+		ver, err = d.onModifySchemaCharsetAndCollate(t, job)
 	case model.ActionCreateTable:
 		ver, err = d.onCreateTable(t, job)
+	case model.ActionPublishTable:
+		ver, err = d.onPublishTable(t, job)
 	case model.ActionDropTable:
 		ver, err = d.onDropTable(t, job)
 	case model.ActionAddColumn:
@@ -290,21 +300,41 @@ func (d *ddl) runDDLJob(t *meta.Meta, job *model.Job) (ver int64) {
 	case model.ActionModifyColumn:
 		ver, err = d.onModifyColumn(t, job)
 	case model.ActionAddIndex:
-		ver, err = d.onCreateIndex(t, job)
-	case model.ActionDropIndex:
+		ver, err = d.onCreateIndex(t, job, false)
+	case model.ActionAddPrimaryKey:
+		ver, err = d.onCreateIndex(t, job, true)
+	case model.ActionDropIndex, model.ActionDropPrimaryKey:
 		ver, err = d.onDropIndex(t, job)
+	case model.ActionRenameIndex:
+		ver, err = d.onRenameIndex(t, job)
+	case model.ActionAlterIndexVisibility:
+		ver, err = d.onAlterIndexVisibility(t, job)
 	case model.ActionAddForeignKey:
 		ver, err = d.onCreateForeignKey(t, job)
 	case model.ActionDropForeignKey:
 		ver, err = d.onDropForeignKey(t, job)
+	case model.ActionAddCheckConstraint:
+		ver, err = d.onAddCheckConstraint(t, job)
+	case model.ActionDropCheckConstraint:
+		ver, err = d.onDropCheckConstraint(t, job)
 	case model.ActionTruncateTable:
 		ver, err = d.onTruncateTable(t, job)
 	case model.ActionRebaseAutoID:
 		ver, err = d.onRebaseAutoID(t, job)
 	case model.ActionRenameTable:
 		ver, err = d.onRenameTable(t, job)
+	case model.ActionRenameTables:
+		ver, err = d.onRenameTables(t, job)
+	case model.ActionModifyTableComment:
+		ver, err = d.onModifyTableComment(t, job)
+	case model.ActionModifyTableCharsetAndCollate:
+		ver, err = d.onModifyTableCharsetAndCollate(t, job)
 	case model.ActionSetDefaultValue:
 		ver, err = d.onSetDefaultValue(t, job)
+	case model.ActionRecoverTable:
+		ver, err = d.onRecoverTable(t, job)
+	case model.ActionMultiSchemaChange:
+		ver, err = d.onMultiSchemaChange(t, job)
 	default:
 		// Invalid job, cancel it.
 		job.State = model.JobStateCancelled
@@ -427,6 +457,22 @@ func updateSchemaVersion(t *meta.Meta, job *model.Job) (int64, error) {
 			return 0, errors.Trace(err)
 		}
 		diff.TableID = job.TableID
+	} else if job.Type == model.ActionRenameTables {
+		var oldSchemaIDs, newSchemaIDs, tableIDs []int64
+		var tableNames []model.CIStr
+		err = job.DecodeArgs(&oldSchemaIDs, &newSchemaIDs, &tableNames, &tableIDs)
+		if err != nil {
+			return 0, errors.Trace(err)
+		}
+		diff.TableID = job.TableID
+		diff.AffectedOpts = make([]*model.AffectedOption, 0, len(tableIDs))
+		for i, tableID := range tableIDs {
+			diff.AffectedOpts = append(diff.AffectedOpts, &model.AffectedOption{
+				SchemaID:    newSchemaIDs[i],
+				TableID:     tableID,
+				OldSchemaID: oldSchemaIDs[i],
+			})
+		}
 	} else {
 		diff.TableID = job.TableID
 	}
@@ -44,6 +44,10 @@ const (
 type delRangeManager interface {
 	// addDelRangeJob add a DDL job into gc_delete_range table.
 	addDelRangeJob(job *model.Job) error
+	// removeFromGCDeleteRange removes the delete range of the element in the job
+	// from gc_delete_range table, so that its data won't be deleted. It returns false
+	// if the range isn't there anymore or the GC safe point isn't earlier than dropTS.
+	removeFromGCDeleteRange(jobID, elementID int64, dropTS uint64) (bool, error)
 	start()
 	clear()
 }
@@ -92,6 +96,52 @@ func (dr *delRange) addDelRangeJob(job *model.Job) error {
 	return nil
 }
 
+// removeFromGCDeleteRange implements delRangeManager interface.
+func (dr *delRange) removeFromGCDeleteRange(jobID, elementID int64, dropTS uint64) (_ bool, err error) {
+	resource, err := dr.ctxPool.Get()
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	defer dr.ctxPool.Put(resource)
+	ctx := resource.(context.Context)
+	ctx.GetSessionVars().SetStatusFlag(mysql.ServerStatusAutocommit, true)
+	ctx.GetSessionVars().InRestrictedSQL = true
+
+	// Check and remove the range in one transaction which locks the range, so it conflicts with
+	// the GC worker which deletes the range at the same time, and it isn't retried.
+	exec := ctx.(sqlexec.SQLExecutor)
+	if _, err = exec.Execute(goctx.Background(), "BEGIN"); err != nil {
+		return false, errors.Trace(err)
+	}
+	defer func() {
+		if err != nil {
+			_, err1 := exec.Execute(goctx.Background(), "ROLLBACK")
+			terror.Log(errors.Trace(err1))
+		}
+	}()
+	exists, err := util.LockDeleteRange(ctx, jobID, elementID)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	safePoint, err := util.LoadGCSafePoint(ctx)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	if !exists || (!safePoint.IsZero() && oracle.ComposeTS(oracle.GetPhysical(safePoint), 0) >= dropTS) {
+		_, err = exec.Execute(goctx.Background(), "ROLLBACK")
+		return false, errors.Trace(err)
+	}
+	err = util.CompleteDeleteRange(ctx, util.DelRangeTask{JobID: jobID, ElementID: elementID})
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	if _, err = exec.Execute(goctx.Background(), "COMMIT"); err != nil {
+		return false, errors.Trace(err)
+	}
+	log.Infof("[ddl] remove job (%d,%d) from delete-range table", jobID, elementID)
+	return true, nil
+}
+
 // start implements delRangeManager interface.
 func (dr *delRange) start() {
 	if !dr.storeSupport {
@@ -234,7 +284,7 @@ func insertJobIntoDeleteRangeTable(ctx context.Context, job *model.Job) error {
 		startKey := tablecodec.EncodeTablePrefix(tableID)
 		endKey := tablecodec.EncodeTablePrefix(tableID + 1)
 		return doInsert(s, job.ID, tableID, startKey, endKey, now)
-	case model.ActionDropIndex:
+	case model.ActionDropIndex, model.ActionDropPrimaryKey:
 		tableID := job.TableID
 		var indexName interface{}
 		var indexID int64
@@ -244,6 +294,40 @@ func insertJobIntoDeleteRangeTable(ctx context.Context, job *model.Job) error {
 		startKey := tablecodec.EncodeTableIndexPrefix(tableID, indexID)
 		endKey := tablecodec.EncodeTableIndexPrefix(tableID, indexID+1)
 		return doInsert(s, job.ID, indexID, startKey, endKey, now)
+	case model.ActionModifyColumn:
+		tableID := job.TableID
+		// The IDs of the indexes replaced or rolled back by converting the column data are the last arg.
+		var newCol, oldColName, pos, needReorg, sqlMode, tz interface{}
+		var indexIDs []int64
+		if err := job.DecodeArgs(&newCol, &oldColName, &pos, &needReorg, &sqlMode, &tz, &indexIDs); err != nil {
+			return errors.Trace(err)
+		}
+		for _, indexID := range indexIDs {
+			startKey := tablecodec.EncodeTableIndexPrefix(tableID, indexID)
+			endKey := tablecodec.EncodeTableIndexPrefix(tableID, indexID+1)
+			if err := doInsert(s, job.ID, indexID, startKey, endKey, now); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	case model.ActionMultiSchemaChange:
+		tableID := job.TableID
+		for _, sub := range job.MultiSchemaInfo.SubJobs {
+			// Both the dropped index and the rolled back adding index have the args of dropping index.
+			if !(sub.Type == model.ActionDropIndex && sub.State == model.JobStateDone) &&
+				!(sub.Type == model.ActionAddIndex && sub.State == model.JobStateRollbackDone) {
+				continue
+			}
+			var indexName interface{}
+			var indexID int64
+			if err := sub.ToProxyJob(job).DecodeArgs(&indexName, &indexID); err != nil {
+				return errors.Trace(err)
+			}
+			startKey := tablecodec.EncodeTableIndexPrefix(tableID, indexID)
+			endKey := tablecodec.EncodeTableIndexPrefix(tableID, indexID+1)
+			if err := doInsert(s, job.ID, indexID, startKey, endKey, now); err != nil {
+				return errors.Trace(err)
+			}
+		}
 	}
 	return nil
 }
@@ -0,0 +1,185 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/table/tables"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/charset"
+)
+
+const (
+	// expressionIndexName is the name of the anonymous expression index, like MySQL does.
+	expressionIndexName = "functional_index"
+	// hiddenColumnPrefix is the name prefix of the hidden columns of the expression indexes.
+	hiddenColumnPrefix = "_V$_"
+)
+
+// hasExpressionKey returns whether some key parts of the index are expressions.
+func hasExpressionKey(keys []*ast.IndexColName) bool {
+	for _, key := range keys {
+		if key.Expr != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// buildHiddenColumns builds a hidden virtual generated column for every expression key
+// part of the index, and replaces the key part with the column. The expressions are built
+// on the public columns of the table, the IDs and offsets of the columns are left to the caller.
+func buildHiddenColumns(ctx context.Context, tblInfo *model.TableInfo, indexName model.CIStr,
+	keys []*ast.IndexColName) ([]*model.ColumnInfo, error) {
+	var hiddenCols []*model.ColumnInfo
+	for i, key := range keys {
+		if key.Expr == nil {
+			continue
+		}
+		if _, ok := key.Expr.(*ast.ColumnNameExpr); ok {
+			return nil, errFunctionalIndexOnField
+		}
+		checker := &checkConstraintChecker{
+			name:          indexName.O,
+			errNotAllowed: errFunctionalIndexFunctionIsNotAllowed,
+			errVariables:  errFunctionalIndexFunctionIsNotAllowed,
+		}
+		key.Expr.Accept(checker)
+		if checker.err != nil {
+			return nil, errors.Trace(checker.err)
+		}
+		deps := make(map[string]struct{}, len(checker.cols))
+		for _, colName := range checker.cols {
+			col := findCol(tblInfo.Columns, colName.Name.L)
+			if col == nil || col.State != model.StatePublic || col.Hidden {
+				return nil, errBadField.GenByArgs(colName.Name.O, "expression index")
+			}
+			if mysql.HasAutoIncrementFlag(col.Flag) {
+				return nil, errFunctionalIndexRefAutoIncrement.GenByArgs(indexName.O)
+			}
+			deps[col.Name.L] = struct{}{}
+		}
+
+		expr, err := tables.RewriteTableExpr(ctx, tblInfo, key.Expr)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		tp := *expr.GetType()
+		switch {
+		case tp.Tp == mysql.TypeJSON || tp.Tp == mysql.TypeGeometry:
+			return nil, errFunctionalIndexOnJSONOrGeometryFunction
+		case types.IsTypeBlob(tp.Tp):
+			return nil, errFunctionalIndexOnLob
+		}
+		// The flags of the keys are set when the index is built.
+		tp.Flag &= mysql.UnsignedFlag | mysql.BinaryFlag
+		// The collations of the function results aren't always valid ones, use the default collation instead.
+		if !charset.ValidCharsetAndCollation(tp.Charset, tp.Collate) {
+			tp.Collate = ""
+		}
+		if err = setCharsetCollationFlenDecimal(&tp); err != nil {
+			return nil, errors.Trace(err)
+		}
+
+		var buf bytes.Buffer
+		key.Expr.Format(&buf)
+		col := &model.ColumnInfo{
+			Name:                genHiddenColumnName(tblInfo, indexName, i),
+			GeneratedExprString: buf.String(),
+			Dependences:         deps,
+			FieldType:           tp,
+			State:               model.StatePublic,
+			Hidden:              true,
+		}
+		hiddenCols = append(hiddenCols, col)
+		key.Column = &ast.ColumnName{Name: col.Name}
+		key.Expr = nil
+	}
+	return hiddenCols, nil
+}
+
+// genHiddenColumnName generates the name of the hidden column for the i-th key part of the index,
+// the name of a renamed index may be used by the hidden columns of another index.
+func genHiddenColumnName(tblInfo *model.TableInfo, indexName model.CIStr, i int) model.CIStr {
+	name := fmt.Sprintf("%s%s_%d", hiddenColumnPrefix, indexName.O, i)
+	for j := 2; findCol(tblInfo.Columns, strings.ToLower(name)) != nil; j++ {
+		name = fmt.Sprintf("%s%s_%d_%d", hiddenColumnPrefix, indexName.O, i, j)
+	}
+	return model.NewCIStr(name)
+}
+
+// addHiddenColumns appends the hidden columns of the expression index to the table.
+func addHiddenColumns(tblInfo *model.TableInfo, hiddenCols []*model.ColumnInfo) {
+	for _, col := range hiddenCols {
+		col.ID = allocateColumnID(tblInfo)
+		col.Offset = len(tblInfo.Columns)
+		tblInfo.Columns = append(tblInfo.Columns, col)
+	}
+}
+
+// dropHiddenColumns removes the hidden columns of the expression index which is dropped,
+// the offsets of the columns after them are adjusted.
+func dropHiddenColumns(tblInfo *model.TableInfo, indexInfo *model.IndexInfo) {
+	var offsets []int
+	for _, idxCol := range indexInfo.Columns {
+		col := findCol(tblInfo.Columns, idxCol.Name.L)
+		if col != nil && col.Hidden {
+			offsets = append(offsets, col.Offset)
+		}
+	}
+	// Remove the columns from the last one, so the offsets of the others are still valid.
+	sort.Sort(sort.Reverse(sort.IntSlice(offsets)))
+	for _, offset := range offsets {
+		newColumns := make([]*model.ColumnInfo, 0, len(tblInfo.Columns))
+		for _, col := range tblInfo.Columns {
+			if col.Offset == offset {
+				continue
+			}
+			if col.Offset > offset {
+				col.Offset--
+			}
+			newColumns = append(newColumns, col)
+		}
+		tblInfo.Columns = newColumns
+		for _, idx := range tblInfo.Indices {
+			for _, idxCol := range idx.Columns {
+				if idxCol.Offset > offset {
+					idxCol.Offset--
+				}
+			}
+		}
+	}
+}
+
+// checkColumnNotUsedByExpressionIndex checks the column isn't used by the hidden columns of the expression indexes.
+func checkColumnNotUsedByExpressionIndex(tblInfo *model.TableInfo, colName model.CIStr) error {
+	for _, col := range tblInfo.Columns {
+		if !col.Hidden {
+			continue
+		}
+		if _, ok := col.Dependences[colName.L]; ok {
+			return errCannotDropColumnFunctionalIndex.GenByArgs(colName.O)
+		}
+	}
+	return nil
+}
@@ -22,10 +22,13 @@ import (
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
 	ddlutil "github.com/pingcap/tidb/ddl/util"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/table/tables"
 	"github.com/pingcap/tidb/tablecodec"
@@ -36,6 +39,7 @@ import (
 
 const maxPrefixLength = 3072
 const maxCommentLength = 1024
+const maxTableCommentLength = 2048
 
 func buildIndexColumns(columns []*model.ColumnInfo, idxColNames []*ast.IndexColName) ([]*model.IndexColumn, error) {
 	// Build offsets.
@@ -60,7 +64,9 @@ func buildIndexColumns(columns []*model.ColumnInfo, idxColNames []*ast.IndexColN
 		}
 
 		// Length must be specified for BLOB and TEXT column indexes.
-		if types.IsTypeBlob(col.FieldType.Tp) && ic.Length == types.UnspecifiedLength {
+		// Spatial columns are stored like BLOB but can't be prefixed, so they can't be indexed,
+		// the points can be indexed by the expression indexes on ST_X and ST_Y.
+		if (types.IsTypeBlob(col.FieldType.Tp) || col.FieldType.Tp == mysql.TypeGeometry) && ic.Length == types.UnspecifiedLength {
 			return nil, errors.Trace(errBlobKeyWithoutLength)
 		}
 
@@ -142,8 +148,15 @@ func buildIndexInfo(tblInfo *model.TableInfo, indexName model.CIStr, idxColNames
 }
 
 func addIndexColumnFlag(tblInfo *model.TableInfo, indexInfo *model.IndexInfo) {
-	col := indexInfo.Columns[0]
+	if indexInfo.Primary {
+		for _, col := range indexInfo.Columns {
+			tblInfo.Columns[col.Offset].Flag |= mysql.PriKeyFlag | mysql.NotNullFlag
+			tblInfo.Columns[col.Offset].Flag &= ^mysql.PreventNullInsertFlag
+		}
+		return
+	}
 
+	col := indexInfo.Columns[0]
 	if indexInfo.Unique && len(indexInfo.Columns) == 1 {
 		tblInfo.Columns[col.Offset].Flag |= mysql.UniqueKeyFlag
 	} else {
@@ -154,7 +167,12 @@ func addIndexColumnFlag(tblInfo *model.TableInfo, indexInfo *model.IndexInfo) {
 func dropIndexColumnFlag(tblInfo *model.TableInfo, indexInfo *model.IndexInfo) {
 	col := indexInfo.Columns[0]
 
-	if indexInfo.Unique && len(indexInfo.Columns) == 1 {
+	if indexInfo.Primary {
+		// The columns are still NOT NULL after dropping the primary key.
+		for _, c := range indexInfo.Columns {
+			tblInfo.Columns[c.Offset].Flag &= ^(mysql.PriKeyFlag | mysql.PreventNullInsertFlag)
+		}
+	} else if indexInfo.Unique && len(indexInfo.Columns) == 1 {
 		tblInfo.Columns[col.Offset].Flag &= ^mysql.UniqueKeyFlag
 	} else {
 		tblInfo.Columns[col.Offset].Flag &= ^mysql.MultipleKeyFlag
@@ -174,7 +192,23 @@ func dropIndexColumnFlag(tblInfo *model.TableInfo, indexInfo *model.IndexInfo) {
 	}
 }
 
-func (d *ddl) onCreateIndex(t *meta.Meta, job *model.Job) (ver int64, err error) {
+// setPreventNullInsertFlag prevents inserting NULL values into the nullable
+// columns of the primary key which is being added.
+func setPreventNullInsertFlag(tblInfo *model.TableInfo, indexInfo *model.IndexInfo) {
+	for _, col := range indexInfo.Columns {
+		colInfo := tblInfo.Columns[col.Offset]
+		if !mysql.HasNotNullFlag(colInfo.Flag) {
+			colInfo.Flag |= mysql.PreventNullInsertFlag
+		}
+	}
+}
+
+// onCreateIndex adds an index, or a primary key if isPK is true. The rows are
+// identified by the handle, so the primary key is a unique index whose
+// columns are NOT NULL. Inserting NULL values into the columns is prevented
+// from the delete only state, and the existing rows are checked when
+// backfilling the index.
+func (d *ddl) onCreateIndex(t *meta.Meta, job *model.Job, isPK bool) (ver int64, err error) {
 	// Handle the rolling back job.
 	if job.IsRollingback() {
 		ver, err = d.onDropIndex(t, job)
@@ -196,20 +230,33 @@ func (d *ddl) onCreateIndex(t *meta.Meta, job *model.Job) (ver int64, err error)
 		indexName   model.CIStr
 		idxColNames []*ast.IndexColName
 		indexOption *ast.IndexOption
+		hiddenCols  []*model.ColumnInfo
 	)
-	err = job.DecodeArgs(&unique, &indexName, &idxColNames, &indexOption)
+	err = job.DecodeArgs(&unique, &indexName, &idxColNames, &indexOption, &hiddenCols)
 	if err != nil {
 		job.State = model.JobStateCancelled
 		return ver, errors.Trace(err)
 	}
 
 	indexInfo := findIndexByName(indexName.L, tblInfo.Indices)
+	if isPK && (tblInfo.PKIsHandle || (indexInfo != nil && indexInfo.State == model.StatePublic)) {
+		job.State = model.JobStateCancelled
+		return ver, infoschema.ErrMultiplePriKey
+	}
 	if indexInfo != nil && indexInfo.State == model.StatePublic {
 		job.State = model.JobStateCancelled
 		return ver, errDupKeyName.Gen("index already exist %s", indexName)
 	}
 
 	if indexInfo == nil {
+		// The hidden columns of the expression index are virtual, so they are public at once.
+		for _, col := range hiddenCols {
+			if findCol(tblInfo.Columns, col.Name.L) != nil {
+				job.State = model.JobStateCancelled
+				return ver, infoschema.ErrColumnExists.GenByArgs(col.Name.O)
+			}
+		}
+		addHiddenColumns(tblInfo, hiddenCols)
 		indexInfo, err = buildIndexInfo(tblInfo, indexName, idxColNames, model.StateNone)
 		if err != nil {
 			job.State = model.JobStateCancelled
@@ -227,7 +274,7 @@ func (d *ddl) onCreateIndex(t *meta.Meta, job *model.Job) (ver int64, err error)
 			// Use btree as default index type.
 			indexInfo.Tp = model.IndexTypeBtree
 		}
-		indexInfo.Primary = false
+		indexInfo.Primary = isPK
 		indexInfo.Unique = unique
 		indexInfo.ID = allocateIndexID(tblInfo)
 		tblInfo.Indices = append(tblInfo.Indices, indexInfo)
@@ -236,6 +283,9 @@ func (d *ddl) onCreateIndex(t *meta.Meta, job *model.Job) (ver int64, err error)
 	switch indexInfo.State {
 	case model.StateNone:
 		// none -> delete only
+		if isPK {
+			setPreventNullInsertFlag(tblInfo, indexInfo)
+		}
 		job.SchemaState = model.StateDeleteOnly
 		indexInfo.State = model.StateDeleteOnly
 		ver, err = updateTableInfo(t, job, tblInfo, originalState)
@@ -252,49 +302,21 @@ func (d *ddl) onCreateIndex(t *meta.Meta, job *model.Job) (ver int64, err error)
 		job.SnapshotVer = 0
 		ver, err = updateTableInfo(t, job, tblInfo, originalState)
 	case model.StateWriteReorganization:
-		// reorganization -> public
-		var tbl table.Table
-		tbl, err = d.getTable(schemaID, tblInfo)
-		if err != nil {
-			return ver, errors.Trace(err)
-		}
-
-		var reorgInfo *reorgInfo
-		reorgInfo, err = d.getReorgInfo(t, job)
-		if err != nil || reorgInfo.first {
-			if err == nil {
-				// Get the first handle of this table.
-				err = iterateSnapshotRows(d.store, tbl, reorgInfo.SnapshotVer, math.MinInt64,
-					func(h int64, rowKey kv.Key, rawRecord []byte) (bool, error) {
-						reorgInfo.Handle = h
-						return false, nil
-					})
-				return ver, errors.Trace(t.UpdateDDLReorgHandle(reorgInfo.Job, reorgInfo.Handle))
+		// The sub-job of a multi-schema change only becomes non-revertible after the reorganization is done.
+		if job.MultiSchemaInfo == nil || job.MultiSchemaInfo.Revertible {
+			var done bool
+			ver, done, err = d.runAddIndexReorg(t, job, tblInfo, indexInfo)
+			if !done {
+				return ver, errors.Trace(err)
 			}
-			// If we run reorg firstly, we should update the job snapshot version
-			// and then run the reorg next time.
-			return ver, errors.Trace(err)
 		}
-
-		err = d.runReorgJob(t, job, func() error {
-			return d.addTableIndex(tbl, indexInfo, reorgInfo, job)
-		})
-		if err != nil {
-			if errWaitReorgTimeout.Equal(err) {
-				// if timeout, we should return, check for the owner and re-wait job done.
-				return ver, nil
-			}
-			if kv.ErrKeyExists.Equal(err) || errCancelledDDLJob.Equal(err) {
-				log.Warnf("[ddl] run DDL job %v err %v, convert job to rollback job", job, err)
-				ver, err = d.convert2RollbackJob(t, job, tblInfo, indexInfo, err)
-			}
-			// Clean up the channel of notifyCancelReorgJob. Make sure it can't affect other jobs.
-			cleanNotify(d.reorgCtx.notifyCancelReorgJob)
-			return ver, errors.Trace(err)
+		if isRevertibleSubJob(job) {
+			// Make the index public together with the other sub-jobs.
+			job.MultiSchemaInfo.Revertible = false
+			return ver, nil
 		}
-		// Clean up the channel of notifyCancelReorgJob. Make sure it can't affect other jobs.
-		cleanNotify(d.reorgCtx.notifyCancelReorgJob)
 
+		// reorganization -> public
 		indexInfo.State = model.StatePublic
 		// Set column index flag.
 		addIndexColumnFlag(tblInfo, indexInfo)
@@ -314,6 +336,51 @@ func (d *ddl) onCreateIndex(t *meta.Meta, job *model.Job) (ver int64, err error)
 	return ver, errors.Trace(err)
 }
 
+// runAddIndexReorg backfills the index in the write reorganization state, done
+// is true only if all the rows have been backfilled.
+func (d *ddl) runAddIndexReorg(t *meta.Meta, job *model.Job, tblInfo *model.TableInfo, indexInfo *model.IndexInfo) (ver int64, done bool, err error) {
+	tbl, err := d.getTable(job.SchemaID, tblInfo)
+	if err != nil {
+		return ver, false, errors.Trace(err)
+	}
+
+	reorgInfo, err := d.getReorgInfo(t, job)
+	if err != nil || reorgInfo.first {
+		if err == nil {
+			// Get the first handle of this table.
+			err = iterateSnapshotRows(d.store, tbl, reorgInfo.SnapshotVer, math.MinInt64,
+				func(h int64, rowKey kv.Key, rawRecord []byte) (bool, error) {
+					reorgInfo.Handle = h
+					return false, nil
+				})
+			return ver, false, errors.Trace(t.UpdateDDLReorgHandle(reorgInfo.Job, reorgInfo.Handle))
+		}
+		// If we run reorg firstly, we should update the job snapshot version
+		// and then run the reorg next time.
+		return ver, false, errors.Trace(err)
+	}
+
+	err = d.runReorgJob(t, job, func() error {
+		return d.addTableIndex(tbl, indexInfo, reorgInfo, job)
+	})
+	if err != nil {
+		if errWaitReorgTimeout.Equal(err) {
+			// if timeout, we should return, check for the owner and re-wait job done.
+			return ver, false, nil
+		}
+		if kv.ErrKeyExists.Equal(err) || errCancelledDDLJob.Equal(err) || errInvalidUseOfNull.Equal(err) {
+			log.Warnf("[ddl] run DDL job %v err %v, convert job to rollback job", job, err)
+			ver, err = d.convert2RollbackJob(t, job, tblInfo, indexInfo, err)
+		}
+		// Clean up the channel of notifyCancelReorgJob. Make sure it can't affect other jobs.
+		cleanNotify(d.reorgCtx.notifyCancelReorgJob)
+		return ver, false, errors.Trace(err)
+	}
+	// Clean up the channel of notifyCancelReorgJob. Make sure it can't affect other jobs.
+	cleanNotify(d.reorgCtx.notifyCancelReorgJob)
+	return ver, true, nil
+}
+
 func (d *ddl) convert2RollbackJob(t *meta.Meta, job *model.Job, tblInfo *model.TableInfo, indexInfo *model.IndexInfo, err error) (ver int64, _ error) {
 	job.State = model.JobStateRollingback
 	job.Args = []interface{}{indexInfo.Name}
@@ -336,6 +403,68 @@ func (d *ddl) convert2RollbackJob(t *meta.Meta, job *model.Job, tblInfo *model.T
 	return ver, errors.Trace(err)
 }
 
+func (d *ddl) onRenameIndex(t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	var fromKey, toKey model.CIStr
+	if err := job.DecodeArgs(&fromKey, &toKey); err != nil {
+		job.State = model.JobStateCancelled
+		return ver, errors.Trace(err)
+	}
+
+	tblInfo, err := getTableInfo(t, job, job.SchemaID)
+	if err != nil {
+		job.State = model.JobStateCancelled
+		return ver, errors.Trace(err)
+	}
+	// Double check. The index may be changed after the job is added.
+	if err = checkRenameIndex(tblInfo, fromKey, toKey); err != nil {
+		job.State = model.JobStateCancelled
+		return ver, errors.Trace(err)
+	}
+	idx := findIndexByName(fromKey.L, tblInfo.Indices)
+	idx.Name = toKey
+
+	ver, err = updateTableInfo(t, job, tblInfo, tblInfo.State)
+	if err != nil {
+		job.State = model.JobStateCancelled
+		return ver, errors.Trace(err)
+	}
+	job.State = model.JobStateDone
+	job.BinlogInfo.AddTableInfo(ver, tblInfo)
+	return ver, nil
+}
+
+func (d *ddl) onAlterIndexVisibility(t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	var indexName model.CIStr
+	var invisible bool
+	if err := job.DecodeArgs(&indexName, &invisible); err != nil {
+		job.State = model.JobStateCancelled
+		return ver, errors.Trace(err)
+	}
+
+	tblInfo, err := getTableInfo(t, job, job.SchemaID)
+	if err != nil {
+		job.State = model.JobStateCancelled
+		return ver, errors.Trace(err)
+	}
+	// Double check. The index may be changed after the job is added.
+	idx, err := checkAlterIndexVisibility(tblInfo, indexName, invisible)
+	if err != nil {
+		job.State = model.JobStateCancelled
+		return ver, errors.Trace(err)
+	}
+	// The index is maintained in both visibilities, so it's changed in one step.
+	idx.Invisible = invisible
+
+	ver, err = updateTableInfo(t, job, tblInfo, tblInfo.State)
+	if err != nil {
+		job.State = model.JobStateCancelled
+		return ver, errors.Trace(err)
+	}
+	job.State = model.JobStateDone
+	job.BinlogInfo.AddTableInfo(ver, tblInfo)
+	return ver, nil
+}
+
 func (d *ddl) onDropIndex(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 	schemaID := job.SchemaID
 	tblInfo, err := getTableInfo(t, job, schemaID)
@@ -358,12 +487,18 @@ func (d *ddl) onDropIndex(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 	originalState := indexInfo.State
 	switch indexInfo.State {
 	case model.StatePublic:
+		if isRevertibleSubJob(job) {
+			// Hide the index together with the other sub-jobs.
+			job.MultiSchemaInfo.Revertible = false
+			return ver, nil
+		}
 		// public -> write only
 		job.SchemaState = model.StateWriteOnly
 		indexInfo.State = model.StateWriteOnly
 		ver, err = updateTableInfo(t, job, tblInfo, originalState)
-	case model.StateWriteOnly:
+	case model.StateWriteOnly, model.StateWriteReorganization:
 		// write only -> delete only
+		// The rolling back adding index job may be in the write reorganization state.
 		job.SchemaState = model.StateDeleteOnly
 		indexInfo.State = model.StateDeleteOnly
 		ver, err = updateTableInfo(t, job, tblInfo, originalState)
@@ -383,6 +518,7 @@ func (d *ddl) onDropIndex(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 		tblInfo.Indices = newIndices
 		// Set column index flag.
 		dropIndexColumnFlag(tblInfo, indexInfo)
+		dropHiddenColumns(tblInfo, indexInfo)
 
 		job.SchemaState = model.StateNone
 		ver, err = updateTableInfo(t, job, tblInfo, originalState)
@@ -405,15 +541,16 @@ func (d *ddl) onDropIndex(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 	return ver, errors.Trace(err)
 }
 
-func (w *worker) fetchRowColVals(txn kv.Transaction, t table.Table, colMap map[int64]*types.FieldType) (
+// fetchRowColVals fetches at most batchSize rows in the range, and returns their index records.
+func (w *worker) fetchRowColVals(txn kv.Transaction, t table.Table, batchRange handleInfo, colMap map[int64]*types.FieldType) (
 	[]*indexRecord, *taskResult) {
 	startTime := time.Now()
 	w.idxRecords = w.idxRecords[:0]
-	ret := &taskResult{outOfRangeHandle: w.taskRange.endHandle}
+	ret := &taskResult{outOfRangeHandle: batchRange.endHandle}
 	isEnd := true
-	err := iterateSnapshotRows(w.ctx.GetStore(), t, txn.StartTS(), w.taskRange.startHandle,
+	err := iterateSnapshotRows(w.ctx.GetStore(), t, txn.StartTS(), batchRange.startHandle,
 		func(h int64, rowKey kv.Key, rawRecord []byte) (bool, error) {
-			if h >= w.taskRange.endHandle {
+			if !batchRange.contains(h) || len(w.idxRecords) >= w.batchSize {
 				ret.outOfRangeHandle = h
 				isEnd = false
 				return false, nil
@@ -435,57 +572,97 @@ func (w *worker) fetchRowColVals(txn kv.Transaction, t table.Table, colMap map[i
 		ret.isAllDone = true
 	}
 	ret.count = len(w.idxRecords)
-	log.Debugf("[ddl] txn %v fetches handle info %v, ret %v, takes time %v", txn.StartTS(), w.taskRange, ret, time.Since(startTime))
+	log.Debugf("[ddl] txn %v fetches handle info %v, ret %v, takes time %v", txn.StartTS(), batchRange, ret, time.Since(startTime))
 
 	return w.idxRecords, ret
 }
 
 func (w *worker) getIndexRecord(t table.Table, colMap map[int64]*types.FieldType, rawRecord []byte, idxRecord *indexRecord) error {
-	cols := t.Cols()
+	cols := t.WritableCols()
 	idxInfo := w.index.Meta()
 	_, err := tablecodec.DecodeRowWithMap(rawRecord, colMap, time.UTC, w.rowMap)
 	if err != nil {
 		return errors.Trace(err)
 	}
+	var genRow []types.Datum
+	if w.genExprs != nil {
+		// Compute the virtual generated columns before the dependent values are cleaned up.
+		genRow = make([]types.Datum, len(cols))
+		for _, col := range cols {
+			if _, ok := colMap[col.ID]; !ok {
+				continue
+			}
+			if genRow[col.Offset], err = w.getRowColVal(t, col, idxRecord.handle); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
 	idxVal := make([]types.Datum, len(idxInfo.Columns))
 	for j, v := range idxInfo.Columns {
 		col := cols[v.Offset]
-		if col.IsPKHandleColumn(t.Meta()) {
-			if mysql.HasUnsignedFlag(col.Flag) {
-				idxVal[j].SetUint64(uint64(idxRecord.handle))
-			} else {
-				idxVal[j].SetInt64(idxRecord.handle)
+		if genExpr, ok := w.genExprs[col.ID]; ok {
+			val, err := genExpr.Eval(types.DatumRow(genRow))
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if idxVal[j], err = table.CastValue(w.ctx, val, col.ToInfo()); err != nil {
+				return errors.Trace(err)
 			}
 			continue
 		}
-		idxColumnVal := w.rowMap[col.ID]
-		if _, ok := w.rowMap[col.ID]; ok {
-			idxVal[j] = idxColumnVal
-			// Make sure there is no dirty data.
-			delete(w.rowMap, col.ID)
-			continue
-		}
-		idxColumnVal, err = tables.GetColDefaultValue(w.ctx, col, w.defaultVals)
-		if err != nil {
+		if idxVal[j], err = w.getRowColVal(t, col, idxRecord.handle); err != nil {
 			return errors.Trace(err)
 		}
-		idxVal[j] = idxColumnVal
+	}
+	// Make sure there is no dirty data.
+	for id := range w.rowMap {
+		delete(w.rowMap, id)
+	}
+	if idxInfo.Primary {
+		// The primary key which is being added can't have NULL values.
+		for _, v := range idxVal {
+			if v.IsNull() {
+				return errInvalidUseOfNull
+			}
+		}
 	}
 	idxRecord.vals = idxVal
 	return nil
 }
 
+// getRowColVal gets the value of the column from the decoded row.
+func (w *worker) getRowColVal(t table.Table, col *table.Column, handle int64) (types.Datum, error) {
+	var val types.Datum
+	if col.IsPKHandleColumn(t.Meta()) {
+		if mysql.HasUnsignedFlag(col.Flag) {
+			val.SetUint64(uint64(handle))
+		} else {
+			val.SetInt64(handle)
+		}
+		return val, nil
+	}
+	if val, ok := w.rowMap[col.ID]; ok {
+		return val, nil
+	}
+	var err error
+	if col.State != model.StatePublic {
+		// The column is added by the same multi-schema change, the rows without it have its origin default value.
+		val, err = table.GetColOriginDefaultValue(w.ctx, col.ToInfo())
+	} else {
+		val, err = tables.GetColDefaultValue(w.ctx, col, w.defaultVals)
+	}
+	return val, errors.Trace(err)
+}
+
 const (
-	minTaskHandledCnt    = 32 // minTaskHandledCnt is the minimum number of handles per batch.
+	// defaultTaskHandleCnt is the number of rows backfilled in a transaction by modifying a column.
 	defaultTaskHandleCnt = 128
-	maxTaskHandleCnt     = 1 << 20 // maxTaskHandleCnt is the maximum number of handles per batch.
-	defaultWorkers       = 16
 )
 
 // taskResult is the result of the task.
 type taskResult struct {
 	count            int   // The number of records that has been processed in the task.
-	outOfRangeHandle int64 // This is the handle out of the range.
+	outOfRangeHandle int64 // This is the first handle which hasn't been processed.
 	isAllDone        bool  // If all rows are all done.
 	err              error
 }
@@ -499,6 +676,7 @@ type indexRecord struct {
 
 type worker struct {
 	id          int
+	d           *ddl
 	ctx         context.Context
 	index       table.Index
 	defaultVals []types.Datum  // It's used to reduce the number of new slice.
@@ -506,16 +684,21 @@ type worker struct {
 	taskRange   handleInfo     // Every task's handle range.
 	taskRet     *taskResult
 	batchSize   int
+	throttle    *reorgThrottle
 	rowMap      map[int64]types.Datum // It's the index column values map. It is used to reduce the number of making map.
+	// genExprs are the expressions of the virtual generated columns in the index, keyed by the column IDs.
+	genExprs map[int64]expression.Expression
 }
 
-func newWorker(ctx context.Context, id, batch, colsLen, indexColsLen int) *worker {
+func newWorker(d *ddl, id, batch, colsLen, indexColsLen int, throttle *reorgThrottle) *worker {
 	return &worker{
 		id:          id,
-		ctx:         ctx,
+		d:           d,
+		ctx:         d.newContext(),
 		batchSize:   batch,
 		idxRecords:  make([]*indexRecord, 0, batch),
 		defaultVals: make([]types.Datum, colsLen),
+		throttle:    throttle,
 		rowMap:      make(map[int64]types.Datum, indexColsLen),
 	}
 }
@@ -526,69 +709,83 @@ func (w *worker) setTaskNewRange(startHandle, endHandle int64) {
 }
 
 // handleInfo records the range of [start handle, end handle) that is used in a task.
+// If the end handle is math.MaxInt64, the range covers the rest of the table.
 type handleInfo struct {
 	startHandle int64
 	endHandle   int64
 }
 
-func getEndHandle(baseHandle, batch int64) int64 {
-	if baseHandle >= math.MaxInt64-batch {
-		return math.MaxInt64
-	}
-	return baseHandle + batch
+// contains returns whether the handle is in the range.
+func (r handleInfo) contains(h int64) bool {
+	return h >= r.startHandle && (h < r.endHandle || r.endHandle == math.MaxInt64)
 }
 
 // addTableIndex adds index into table.
-// TODO: Move this to doc or wiki.
 // How to add index in reorganization state?
-// Concurrently process the defaultTaskHandleCnt tasks. Each task deals with a handle range of the index record.
-// The handle range size is defaultTaskHandleCnt.
-// Each handle range by estimation, concurrent processing needs to perform after the handle range has been acquired.
-// The operation flow of the each task of data is as follows:
-//  1. Open a goroutine. Traverse the snapshot to obtain the handle range, while accessing the corresponding row key and
-// raw index value. Then notify to start the next task.
-//  2. Decode this task of raw index value to get the corresponding index value.
+// The backfilling runs in rounds. Every round splits the unprocessed handles of the table by the regions,
+// and backfills the first tidb_ddl_reorg_worker_cnt regions concurrently, one worker for a region.
+// The operation flow of a worker is as follows:
+//  1. Traverse the snapshot to obtain the next tidb_ddl_reorg_batch_size rows of the region, while accessing
+// the corresponding row key and raw index value.
+//  2. Decode the raw index value to get the corresponding index value.
 //  3. Deal with these index records one by one. If the index record exists, skip to the next row.
 // If the index doesn't exist, create the index and then continue to handle the next row.
-//  4. When the handle of a range is completed, return the corresponding task result.
-// The above operations are completed in a transaction.
-// When concurrent tasks are processed, the task result returned by each task is sorted by the worker number. Then traverse the
-// task results, get the total number of rows in the concurrent task and update the processed handle value. If
-// an error message is displayed, exit the traversal.
-// Finally, update the concurrent processing of the total number of rows, and store the completed handle value.
+// The above operations are completed in a transaction, and they are repeated until the region is done.
+// The total number of rows backfilled by the workers per second is limited by tidb_ddl_reorg_rate_limit.
+// When the workers are done, the task results are traversed in the order of the regions, the total number of rows
+// and the processed handle are updated. If an error happens, exit the traversal.
+// The global variables are loaded before every round, so they can be changed while the job is running.
 func (d *ddl) addTableIndex(t table.Table, indexInfo *model.IndexInfo, reorgInfo *reorgInfo, job *model.Job) error {
-	cols := t.Cols()
+	// The index may cover a column added by the same multi-schema change, which isn't public yet.
+	cols := t.WritableCols()
 	colMap := make(map[int64]*types.FieldType)
 	for _, v := range indexInfo.Columns {
 		col := cols[v.Offset]
 		colMap[col.ID] = &col.FieldType
+		// The virtual generated columns are computed from the columns they depend on.
+		if col.IsGenerated() && !col.GeneratedStored {
+			for _, dep := range cols {
+				if _, ok := col.Dependences[dep.Name.L]; ok {
+					colMap[dep.ID] = &dep.FieldType
+				}
+			}
+		}
 	}
-	workerCnt := defaultWorkers
 	addedCount := job.GetRowCount()
 	baseHandle, logStartHandle := reorgInfo.Handle, reorgInfo.Handle
 
-	workers := make([]*worker, workerCnt)
-	for i := 0; i < workerCnt; i++ {
-		ctx := d.newContext()
-		workers[i] = newWorker(ctx, i, defaultTaskHandleCnt, len(cols), len(colMap))
-		// Make sure every worker has its own index buffer.
-		workers[i].index = tables.NewIndexWithBuffer(t.Meta(), indexInfo)
-	}
+	throttle := &reorgThrottle{}
+	var workers []*worker
 	for {
+		d.loadReorgVars()
+		workerCnt := int(variable.GetDDLReorgWorkerCounter())
+		batchSize := int(variable.GetDDLReorgBatchSize())
+		throttle.setLimit(variable.GetDDLReorgRateLimit())
+		ranges, err := d.splitTableRanges(t, baseHandle, workerCnt)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		for i := len(workers); i < len(ranges); i++ {
+			w := newWorker(d, i, batchSize, len(cols), len(colMap), throttle)
+			// Make sure every worker has its own index buffer.
+			w.index = tables.NewIndexWithBuffer(t.Meta(), indexInfo)
+			if w.genExprs, err = tables.BuildIndexGenExprs(w.ctx, t, indexInfo); err != nil {
+				return errors.Trace(err)
+			}
+			workers = append(workers, w)
+		}
+
 		startTime := time.Now()
 		wg := sync.WaitGroup{}
-		currentBatchSize := int64(workers[0].batchSize)
-		for i := 0; i < workerCnt; i++ {
+		for i, r := range ranges {
 			wg.Add(1)
-			endHandle := getEndHandle(baseHandle, currentBatchSize)
-			workers[i].setTaskNewRange(baseHandle, endHandle)
-			// TODO: Consider one worker to one goroutine.
+			workers[i].batchSize = batchSize
+			workers[i].setTaskNewRange(r.startHandle, r.endHandle)
 			go workers[i].doBackfillIndexTask(t, colMap, &wg)
-			baseHandle = endHandle
 		}
 		wg.Wait()
 
-		taskAddedCount, nextHandle, isEnd, err := getCountAndHandle(workers)
+		taskAddedCount, nextHandle, isEnd, err := getCountAndHandle(workers[:len(ranges)])
 		addedCount += taskAddedCount
 		sub := time.Since(startTime).Seconds()
 		if err == nil {
@@ -599,14 +796,15 @@ func (d *ddl) addTableIndex(t table.Table, indexInfo *model.IndexInfo, reorgInfo
 			err1 := kv.RunInNewTxn(d.store, true, func(txn kv.Transaction) error {
 				return errors.Trace(reorgInfo.UpdateHandle(txn, nextHandle))
 			})
-			log.Warnf("[ddl] total added index for %d rows, this task [%d,%d) add index for %d failed %v, batch %d, take time %v, update handle err %v",
-				addedCount, logStartHandle, nextHandle, taskAddedCount, err, currentBatchSize, sub, err1)
+			d.reorgCtx.setRowCountAndHandle(addedCount, nextHandle)
+			log.Warnf("[ddl] total added index for %d rows, this task [%d,%d) add index for %d failed %v, workers %d, batch %d, take time %v, update handle err %v",
+				addedCount, logStartHandle, nextHandle, taskAddedCount, err, len(ranges), batchSize, sub, err1)
 			return errors.Trace(err)
 		}
 		d.reorgCtx.setRowCountAndHandle(addedCount, nextHandle)
 		batchHandleDataHistogram.WithLabelValues(batchAddIdx).Observe(sub)
-		log.Infof("[ddl] total added index for %d rows, this task [%d,%d) added index for %d rows, batch %d, take time %v",
-			addedCount, logStartHandle, nextHandle, taskAddedCount, currentBatchSize, sub)
+		log.Infof("[ddl] total added index for %d rows, this task [%d,%d) added index for %d rows, workers %d, batch %d, take time %v",
+			addedCount, logStartHandle, nextHandle, taskAddedCount, len(ranges), batchSize, sub)
 
 		if isEnd {
 			return nil
@@ -615,56 +813,56 @@ func (d *ddl) addTableIndex(t table.Table, indexInfo *model.IndexInfo, reorgInfo
 	}
 }
 
+// getCountAndHandle returns the number of the rows processed by the workers and the first handle that hasn't been
+// processed. The handles after the first failed task will be processed again.
 func getCountAndHandle(workers []*worker) (int64, int64, bool, error) {
 	taskAddedCount, nextHandle := int64(0), workers[0].taskRange.startHandle
-	var err error
 	var isEnd bool
-	starvingWorkers := 0
-	largerDefaultWorkers := 0
 	for _, worker := range workers {
 		ret := worker.taskRet
-		if ret.err != nil {
-			err = ret.err
-			break
-		}
 		taskAddedCount += int64(ret.count)
-		if ret.count < minTaskHandledCnt {
-			starvingWorkers++
-		} else if ret.count > defaultTaskHandleCnt {
-			largerDefaultWorkers++
-		}
 		nextHandle = ret.outOfRangeHandle
-		isEnd = ret.isAllDone
-	}
-
-	// Adjust the worker's batch size.
-	halfWorkers := len(workers) / 2
-	if starvingWorkers >= halfWorkers && workers[0].batchSize < maxTaskHandleCnt {
-		// If the index data is discrete, we need to increase the batch size to speed up.
-		for _, worker := range workers {
-			worker.batchSize *= 2
-		}
-	} else if largerDefaultWorkers >= halfWorkers && workers[0].batchSize > defaultTaskHandleCnt {
-		// If the batch size exceeds the limit after we increase it,
-		// we need to decrease the batch size to reduce write conflict.
-		for _, worker := range workers {
-			worker.batchSize /= 2
+		if ret.err != nil {
+			return taskAddedCount, nextHandle, false, errors.Trace(ret.err)
 		}
+		isEnd = ret.isAllDone
 	}
-	return taskAddedCount, nextHandle, isEnd, errors.Trace(err)
+	return taskAddedCount, nextHandle, isEnd, nil
 }
 
+// doBackfillIndexTask backfills the index of the rows in the task range, every batch of the rows is backfilled
+// in its own transaction.
 func (w *worker) doBackfillIndexTask(t table.Table, colMap map[int64]*types.FieldType, wg *sync.WaitGroup) {
 	defer wg.Done()
 
 	startTime := time.Now()
-	var ret *taskResult
-	err := kv.RunInNewTxn(w.ctx.GetStore(), true, func(txn kv.Transaction) error {
-		ret = w.doBackfillIndexTaskInTxn(t, txn, colMap)
-		return errors.Trace(ret.err)
-	})
-	if err != nil {
-		ret.err = errors.Trace(err)
+	ret := &taskResult{outOfRangeHandle: w.taskRange.startHandle}
+	batchRange := w.taskRange
+	for {
+		var batchRet *taskResult
+		err := kv.RunInNewTxn(w.ctx.GetStore(), true, func(txn kv.Transaction) error {
+			batchRet = w.doBackfillIndexTaskInTxn(t, txn, batchRange, colMap)
+			return errors.Trace(batchRet.err)
+		})
+		if err == nil {
+			ret.count += batchRet.count
+			ret.outOfRangeHandle = batchRet.outOfRangeHandle
+			ret.isAllDone = batchRet.isAllDone
+			if ret.isAllDone || !w.taskRange.contains(ret.outOfRangeHandle) {
+				break
+			}
+			w.throttle.wait(batchRet.count)
+			err = w.d.isReorgRunnable()
+			if errCancelledDDLJob.Equal(err) {
+				// Notify the other workers and the backfilling loop too.
+				asyncNotify(w.d.reorgCtx.notifyCancelReorgJob)
+			}
+		}
+		if err != nil {
+			ret.err = errors.Trace(err)
+			break
+		}
+		batchRange.startHandle = ret.outOfRangeHandle
 	}
 
 	w.taskRet = ret
@@ -673,9 +871,9 @@ func (w *worker) doBackfillIndexTask(t table.Table, colMap map[int64]*types.Fiel
 }
 
 // doBackfillIndexTaskInTxn deals with a part of backfilling index data in a Transaction.
-// This part of the index data rows is defaultTaskHandleCnt.
-func (w *worker) doBackfillIndexTaskInTxn(t table.Table, txn kv.Transaction, colMap map[int64]*types.FieldType) *taskResult {
-	idxRecords, taskRet := w.fetchRowColVals(txn, t, colMap)
+// This part of the index data rows is at most the batch size of the worker.
+func (w *worker) doBackfillIndexTaskInTxn(t table.Table, txn kv.Transaction, batchRange handleInfo, colMap map[int64]*types.FieldType) *taskResult {
+	idxRecords, taskRet := w.fetchRowColVals(txn, t, batchRange, colMap)
 	if taskRet.err != nil {
 		taskRet.err = errors.Trace(taskRet.err)
 		return taskRet
@@ -110,6 +110,11 @@ func (dr *mockDelRange) addDelRangeJob(job *model.Job) error {
 	return nil
 }
 
+// removeFromGCDeleteRange implements delRangeManager interface.
+func (dr *mockDelRange) removeFromGCDeleteRange(jobID, elementID int64, dropTS uint64) (bool, error) {
+	return true, nil
+}
+
 // start implements delRangeManager interface.
 func (dr *mockDelRange) start() {
 	return
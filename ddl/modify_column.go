@@ -0,0 +1,500 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/stmtctx"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/table/tables"
+	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/codec"
+	log "github.com/sirupsen/logrus"
+)
+
+// If the new type of a column can't hold all the values of the origin type,
+// the column is modified with its data converted:
+//  1. A hidden changing column with the new type is added, and a hidden
+//     changing index is added for every index on the column. The changing
+//     column and indexes go through the states of adding a column.
+//  2. In the write only state, the writes of the column are converted and
+//     written to the changing column and indexes too.
+//  3. In the write reorganization state, the rows are backfilled with the
+//     converted values. If a value can't be converted under the SQL mode of
+//     the statement, or a changing unique index has duplicated entries, the
+//     job is rolled back.
+//  4. Finally the changing column and indexes take the place of the origin
+//     ones in one step, and the data of the origin indexes is deleted.
+
+const (
+	changingColumnPrefix = "_Col$_"
+	changingIndexPrefix  = "_Idx$_"
+)
+
+func getChangingColumnName(name model.CIStr) model.CIStr {
+	return model.NewCIStr(changingColumnPrefix + name.O)
+}
+
+func getChangingIndexName(name model.CIStr) model.CIStr {
+	return model.NewCIStr(changingIndexPrefix + name.O)
+}
+
+// findChangingIndexes returns the changing indexes, and the origin indexes
+// they are going to take the place of.
+func findChangingIndexes(tblInfo *model.TableInfo) (changingIdxs, oldIdxs []*model.IndexInfo) {
+	for _, idx := range tblInfo.Indices {
+		if !strings.HasPrefix(idx.Name.O, changingIndexPrefix) {
+			continue
+		}
+		oldIdx := findIndexByName(strings.ToLower(strings.TrimPrefix(idx.Name.O, changingIndexPrefix)), tblInfo.Indices)
+		changingIdxs = append(changingIdxs, idx)
+		oldIdxs = append(oldIdxs, oldIdx)
+	}
+	return changingIdxs, oldIdxs
+}
+
+// doModifyColumnWithData modifies the column and converts its data in a reorganization.
+func (d *ddl) doModifyColumnWithData(t *meta.Meta, job *model.Job, tblInfo *model.TableInfo, newCol *model.ColumnInfo,
+	oldName *model.CIStr, pos *ast.ColumnPosition, sqlMode mysql.SQLMode, tz *model.TimeZoneLocation) (ver int64, err error) {
+	if job.IsRollingback() {
+		ver, err = d.rollbackModifyColumn(t, job, tblInfo)
+		return ver, errors.Trace(err)
+	}
+
+	oldCol := findCol(tblInfo.Columns, oldName.L)
+	if oldCol == nil || oldCol.State != model.StatePublic {
+		job.State = model.JobStateCancelled
+		return ver, infoschema.ErrColumnNotExists.GenByArgs(oldName, tblInfo.Name)
+	}
+	changingCol := findCol(tblInfo.Columns, getChangingColumnName(oldCol.Name).L)
+	changingIdxs, _ := findChangingIndexes(tblInfo)
+
+	if job.IsCancelling() {
+		// If the value of SnapshotVer isn't zero, it means the work is backfilling the column.
+		if job.SchemaState != model.StateWriteReorganization || job.SnapshotVer == 0 {
+			ver, err = d.convertModifyColumn2RollbackJob(t, job, tblInfo, changingCol, changingIdxs, errCancelledDDLJob)
+			return ver, errors.Trace(err)
+		}
+		asyncNotify(d.reorgCtx.notifyCancelReorgJob)
+	}
+
+	originalState := job.SchemaState
+	switch job.SchemaState {
+	case model.StateNone:
+		// none -> delete only
+		changingCol, changingIdxs = createChangingColumn(tblInfo, oldCol, newCol, tz)
+		job.SchemaState = model.StateDeleteOnly
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+	case model.StateDeleteOnly:
+		// delete only -> write only
+		setChangingState(changingCol, changingIdxs, model.StateWriteOnly)
+		job.SchemaState = model.StateWriteOnly
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+	case model.StateWriteOnly:
+		// write only -> reorganization
+		setChangingState(changingCol, changingIdxs, model.StateWriteReorganization)
+		job.SchemaState = model.StateWriteReorganization
+		// Initialize SnapshotVer to 0 for later reorganization check.
+		job.SnapshotVer = 0
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+	case model.StateWriteReorganization:
+		var done bool
+		ver, done, err = d.runModifyColumnReorg(t, job, tblInfo, oldCol, changingCol, changingIdxs, sqlMode)
+		if !done {
+			return ver, errors.Trace(err)
+		}
+		// reorganization -> public
+		ver, err = d.swapChangingColumn(t, job, tblInfo, newCol, oldName, pos)
+	default:
+		err = ErrInvalidColumnState.Gen("invalid column state %v", job.SchemaState)
+	}
+	return ver, errors.Trace(err)
+}
+
+// createChangingColumn adds the changing column and indexes of oldCol in delete only state,
+// the values of oldCol are converted into the changing column in the time zone tz.
+func createChangingColumn(tblInfo *model.TableInfo, oldCol, newCol *model.ColumnInfo, tz *model.TimeZoneLocation) (*model.ColumnInfo, []*model.IndexInfo) {
+	changingCol := newCol.Clone()
+	changingCol.ID = allocateColumnID(tblInfo)
+	changingCol.Name = getChangingColumnName(oldCol.Name)
+	changingCol.Offset = len(tblInfo.Columns)
+	changingCol.State = model.StateDeleteOnly
+	changingCol.OriginDefaultValue = nil
+	// The rows which aren't backfilled have no value of the changing column.
+	changingCol.Flag &= ^mysql.NotNullFlag
+	changingCol.ChangeStateInfo = &model.ChangeStateInfo{DependencyColumnOffset: oldCol.Offset, TimeZone: tz}
+	tblInfo.Columns = append(tblInfo.Columns, changingCol)
+
+	var changingIdxs []*model.IndexInfo
+	for _, idx := range tblInfo.Indices {
+		if !indexCoversColumn(idx, oldCol.Name) {
+			continue
+		}
+		changingIdx := idx.Clone()
+		changingIdx.ID = allocateIndexID(tblInfo)
+		changingIdx.Name = getChangingIndexName(idx.Name)
+		changingIdx.State = model.StateDeleteOnly
+		for _, ic := range changingIdx.Columns {
+			if ic.Name.L != oldCol.Name.L {
+				continue
+			}
+			ic.Name = changingCol.Name
+			ic.Offset = changingCol.Offset
+			if !types.IsTypePrefixable(changingCol.Tp) || (changingCol.Flen > 0 && ic.Length >= changingCol.Flen) {
+				ic.Length = types.UnspecifiedLength
+			}
+		}
+		changingIdxs = append(changingIdxs, changingIdx)
+	}
+	tblInfo.Indices = append(tblInfo.Indices, changingIdxs...)
+	return changingCol, changingIdxs
+}
+
+func indexCoversColumn(idx *model.IndexInfo, colName model.CIStr) bool {
+	for _, ic := range idx.Columns {
+		if ic.Name.L == colName.L {
+			return true
+		}
+	}
+	return false
+}
+
+func setChangingState(changingCol *model.ColumnInfo, changingIdxs []*model.IndexInfo, state model.SchemaState) {
+	if changingCol != nil {
+		changingCol.State = state
+	}
+	for _, idx := range changingIdxs {
+		idx.State = state
+	}
+}
+
+// runModifyColumnReorg backfills the changing column and indexes in the write
+// reorganization state, done is true only if all the rows have been backfilled.
+func (d *ddl) runModifyColumnReorg(t *meta.Meta, job *model.Job, tblInfo *model.TableInfo, oldCol, changingCol *model.ColumnInfo,
+	changingIdxs []*model.IndexInfo, sqlMode mysql.SQLMode) (ver int64, done bool, err error) {
+	tbl, err := d.getTable(job.SchemaID, tblInfo)
+	if err != nil {
+		return ver, false, errors.Trace(err)
+	}
+
+	reorgInfo, err := d.getReorgInfo(t, job)
+	if err != nil || reorgInfo.first {
+		if err == nil {
+			// Get the first handle of this table.
+			err = iterateSnapshotRows(d.store, tbl, reorgInfo.SnapshotVer, math.MinInt64,
+				func(h int64, rowKey kv.Key, rawRecord []byte) (bool, error) {
+					reorgInfo.Handle = h
+					return false, nil
+				})
+			return ver, false, errors.Trace(t.UpdateDDLReorgHandle(reorgInfo.Job, reorgInfo.Handle))
+		}
+		// If we run reorg firstly, we should update the job snapshot version
+		// and then run the reorg next time.
+		return ver, false, errors.Trace(err)
+	}
+
+	err = d.runReorgJob(t, job, func() error {
+		return d.backfillChangingColumn(tbl, oldCol, changingCol, changingIdxs, reorgInfo, job, sqlMode)
+	})
+	if err != nil {
+		if errWaitReorgTimeout.Equal(err) {
+			// if timeout, we should return, check for the owner and re-wait job done.
+			return ver, false, nil
+		}
+		if kv.ErrKeyExists.Equal(err) || errCancelledDDLJob.Equal(err) || isConvertDataErr(err) {
+			log.Warnf("[ddl] run DDL job %v err %v, convert job to rollback job", job, err)
+			ver, err = d.convertModifyColumn2RollbackJob(t, job, tblInfo, changingCol, changingIdxs, err)
+		}
+		// Clean up the channel of notifyCancelReorgJob. Make sure it can't affect other jobs.
+		cleanNotify(d.reorgCtx.notifyCancelReorgJob)
+		return ver, false, errors.Trace(err)
+	}
+	// Clean up the channel of notifyCancelReorgJob. Make sure it can't affect other jobs.
+	cleanNotify(d.reorgCtx.notifyCancelReorgJob)
+	return ver, true, nil
+}
+
+// isConvertDataErr returns whether the error is returned by converting a value to the new type.
+func isConvertDataErr(err error) bool {
+	tErr, ok := errors.Cause(err).(*terror.Error)
+	if !ok {
+		return false
+	}
+	return tErr.Class() == terror.ClassTypes || tErr.Class() == terror.ClassJSON || table.ErrTruncateWrongValue.Equal(tErr)
+}
+
+// swapChangingColumn makes the changing column and indexes take the place of the
+// origin ones, the IDs of the origin indexes are appended to the job args, so
+// their data can be deleted.
+func (d *ddl) swapChangingColumn(t *meta.Meta, job *model.Job, tblInfo *model.TableInfo, newCol *model.ColumnInfo,
+	oldName *model.CIStr, pos *ast.ColumnPosition) (ver int64, err error) {
+	oldCol := findCol(tblInfo.Columns, oldName.L)
+	changingCol := findCol(tblInfo.Columns, getChangingColumnName(oldCol.Name).L)
+	changingIdxs, oldIdxs := findChangingIndexes(tblInfo)
+
+	// Remove the changing column, its ID is taken by the new column. The changing
+	// column and indexes are the last ones, which are added by createChangingColumn.
+	tblInfo.Columns = tblInfo.Columns[:changingCol.Offset]
+	newCol.ID = changingCol.ID
+
+	oldIdxIDs := make([]int64, 0, len(oldIdxs))
+	indices := make([]*model.IndexInfo, 0, len(tblInfo.Indices)-len(oldIdxs))
+	for _, idx := range tblInfo.Indices[:len(tblInfo.Indices)-len(changingIdxs)] {
+		for i, oldIdx := range oldIdxs {
+			if oldIdx != idx {
+				continue
+			}
+			oldIdxIDs = append(oldIdxIDs, idx.ID)
+			idx = changingIdxs[i]
+			idx.Name = oldIdx.Name
+			idx.State = model.StatePublic
+			for _, ic := range idx.Columns {
+				if ic.Name.L == changingCol.Name.L {
+					// It's changed to the new column by doModifyColumn.
+					ic.Name, ic.Offset = oldCol.Name, oldCol.Offset
+				}
+			}
+			break
+		}
+		indices = append(indices, idx)
+	}
+	tblInfo.Indices = indices
+
+	ver, err = d.doModifyColumn(t, job, tblInfo, newCol, oldName, pos)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	job.Args = append(job.Args, oldIdxIDs)
+	return ver, nil
+}
+
+// convertModifyColumn2RollbackJob makes the changing column and indexes delete
+// only, and rolls back the job.
+func (d *ddl) convertModifyColumn2RollbackJob(t *meta.Meta, job *model.Job, tblInfo *model.TableInfo, changingCol *model.ColumnInfo,
+	changingIdxs []*model.IndexInfo, err error) (ver int64, _ error) {
+	setChangingState(changingCol, changingIdxs, model.StateDeleteOnly)
+	originalState := job.SchemaState
+	job.State = model.JobStateRollingback
+	job.SchemaState = model.StateDeleteOnly
+	ver, err1 := updateTableInfo(t, job, tblInfo, originalState)
+	if err1 != nil {
+		return ver, errors.Trace(err1)
+	}
+	return ver, errors.Trace(err)
+}
+
+// rollbackModifyColumn removes the changing column and indexes, the IDs of the
+// changing indexes are appended to the job args, so their data can be deleted.
+func (d *ddl) rollbackModifyColumn(t *meta.Meta, job *model.Job, tblInfo *model.TableInfo) (ver int64, _ error) {
+	columns := tblInfo.Columns[:0]
+	for _, col := range tblInfo.Columns {
+		if col.ChangeStateInfo == nil {
+			columns = append(columns, col)
+		}
+	}
+	tblInfo.Columns = columns
+
+	changingIdxIDs := make([]int64, 0)
+	indices := tblInfo.Indices[:0]
+	for _, idx := range tblInfo.Indices {
+		if strings.HasPrefix(idx.Name.O, changingIndexPrefix) {
+			changingIdxIDs = append(changingIdxIDs, idx.ID)
+			continue
+		}
+		indices = append(indices, idx)
+	}
+	tblInfo.Indices = indices
+
+	originalState := job.SchemaState
+	job.SchemaState = model.StateNone
+	ver, err := updateTableInfo(t, job, tblInfo, originalState)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	job.State = model.JobStateRollbackDone
+	job.BinlogInfo.AddTableInfo(ver, tblInfo)
+	job.Args = append(job.Args, changingIdxIDs)
+	return ver, nil
+}
+
+// backfillChangingColumn writes the converted values of oldCol to the changing
+// column and indexes, batch by batch. Every batch is done in a transaction.
+func (d *ddl) backfillChangingColumn(t table.Table, oldCol, changingCol *model.ColumnInfo, changingIdxInfos []*model.IndexInfo,
+	reorgInfo *reorgInfo, job *model.Job, sqlMode mysql.SQLMode) error {
+	// The values are converted in the time zone of the changing column like the DMLs do.
+	loc, err := changingCol.ChangeStateInfo.TimeZone.GetLocation()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	ctx := d.newContext()
+	ctx.GetSessionVars().SQLMode = sqlMode
+	ctx.GetSessionVars().StrictSQLMode = sqlMode.HasStrictMode()
+	ctx.GetSessionVars().TimeZone = loc
+
+	cols := t.WritableCols()
+	colMap := map[int64]*types.FieldType{oldCol.ID: &oldCol.FieldType, changingCol.ID: &changingCol.FieldType}
+	changingIdxs := make([]table.Index, 0, len(changingIdxInfos))
+	for _, idxInfo := range changingIdxInfos {
+		for _, ic := range idxInfo.Columns {
+			colMap[cols[ic.Offset].ID] = &cols[ic.Offset].FieldType
+		}
+		changingIdxs = append(changingIdxs, tables.NewIndex(t.Meta(), idxInfo))
+	}
+
+	addedCount := job.GetRowCount()
+	handle := reorgInfo.Handle
+	for {
+		startTime := time.Now()
+		var count int
+		var nextHandle int64
+		var isEnd bool
+		err := kv.RunInNewTxn(d.store, true, func(txn kv.Transaction) error {
+			// Convert the values as the statement which modifies the column does.
+			ctx.GetSessionVars().StmtCtx = &stmtctx.StatementContext{TruncateAsWarning: !sqlMode.HasStrictMode(), TimeZone: loc}
+			var err1 error
+			count, nextHandle, isEnd, err1 = backfillChangingColumnBatch(ctx, txn, t, cols, colMap, oldCol, changingCol, changingIdxs, handle)
+			return errors.Trace(err1)
+		})
+		if err == nil {
+			err = d.isReorgRunnable()
+		}
+		if err != nil {
+			// Update the reorg handle that has been processed.
+			err1 := kv.RunInNewTxn(d.store, true, func(txn kv.Transaction) error {
+				return errors.Trace(reorgInfo.UpdateHandle(txn, handle))
+			})
+			log.Warnf("[ddl] total backfilled column %s for %d rows, this task from %d failed %v, update handle err %v",
+				oldCol.Name, addedCount, handle, err, err1)
+			return errors.Trace(err)
+		}
+		addedCount += int64(count)
+		d.reorgCtx.setRowCountAndHandle(addedCount, nextHandle)
+		log.Infof("[ddl] total backfilled column %s for %d rows, this task [%d,%d) backfilled %d rows, take time %v",
+			oldCol.Name, addedCount, handle, nextHandle, count, time.Since(startTime))
+		if isEnd {
+			return nil
+		}
+		handle = nextHandle
+	}
+}
+
+// backfillChangingColumnBatch backfills at most defaultTaskHandleCnt rows from
+// startHandle, it returns the number of the backfilled rows and the next handle.
+func backfillChangingColumnBatch(ctx context.Context, txn kv.Transaction, t table.Table, cols []*table.Column,
+	colMap map[int64]*types.FieldType, oldCol, changingCol *model.ColumnInfo, changingIdxs []table.Index,
+	startHandle int64) (count int, nextHandle int64, isEnd bool, err error) {
+	isEnd = true
+	err = iterateSnapshotRows(ctx.GetStore(), t, txn.StartTS(), startHandle,
+		func(h int64, rowKey kv.Key, rawRecord []byte) (bool, error) {
+			if count >= defaultTaskHandleCnt {
+				nextHandle, isEnd = h, false
+				return false, nil
+			}
+			count++
+			rowMap, err1 := tablecodec.DecodeRow(rawRecord, colMap, ctx.GetSessionVars().GetTimeZone())
+			if err1 != nil {
+				return false, errors.Trace(err1)
+			}
+			if _, ok := rowMap[changingCol.ID]; ok {
+				// The row is written after the changing column becomes writable.
+				return true, nil
+			}
+
+			oldVal, ok := rowMap[oldCol.ID]
+			if !ok {
+				oldVal, err1 = table.GetColOriginDefaultValue(ctx, oldCol)
+				if err1 != nil {
+					return false, errors.Trace(err1)
+				}
+			}
+			newVal, err1 := table.CastValue(ctx, oldVal, changingCol)
+			if err1 != nil {
+				return false, errors.Trace(err1)
+			}
+
+			// Append the value of the changing column to the row.
+			value, err1 := tablecodec.EncodeRow([]types.Datum{newVal}, []int64{changingCol.ID}, ctx.GetSessionVars().GetTimeZone())
+			if err1 != nil {
+				return false, errors.Trace(err1)
+			}
+			if len(rawRecord) != 1 || rawRecord[0] != codec.NilFlag {
+				value = append(append(make([]byte, 0, len(rawRecord)+len(value)), rawRecord...), value...)
+			}
+			if err1 = txn.Set(rowKey, value); err1 != nil {
+				return false, errors.Trace(err1)
+			}
+
+			rowMap[changingCol.ID] = newVal
+			for _, idx := range changingIdxs {
+				vals, err1 := fetchChangingIndexValues(ctx, t, cols, idx, h, rowMap)
+				if err1 != nil {
+					return false, errors.Trace(err1)
+				}
+				dupHandle, err1 := idx.Create(txn, vals, h)
+				if err1 != nil {
+					if kv.ErrKeyExists.Equal(err1) && dupHandle != h {
+						return false, kv.ErrKeyExists.FastGen("Duplicate for key %s",
+							strings.TrimPrefix(idx.Meta().Name.O, changingIndexPrefix))
+					}
+					if !kv.ErrKeyExists.Equal(err1) {
+						return false, errors.Trace(err1)
+					}
+				}
+			}
+			return true, nil
+		})
+	if isEnd {
+		nextHandle = math.MaxInt64
+	}
+	return count, nextHandle, isEnd, errors.Trace(err)
+}
+
+func fetchChangingIndexValues(ctx context.Context, t table.Table, cols []*table.Column, idx table.Index, h int64,
+	rowMap map[int64]types.Datum) ([]types.Datum, error) {
+	vals := make([]types.Datum, 0, len(idx.Meta().Columns))
+	for _, ic := range idx.Meta().Columns {
+		col := cols[ic.Offset]
+		if col.IsPKHandleColumn(t.Meta()) {
+			if mysql.HasUnsignedFlag(col.Flag) {
+				vals = append(vals, types.NewUintDatum(uint64(h)))
+			} else {
+				vals = append(vals, types.NewIntDatum(h))
+			}
+			continue
+		}
+		val, ok := rowMap[col.ID]
+		if !ok {
+			var err error
+			val, err = table.GetColOriginDefaultValue(ctx, col.ToInfo())
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		vals = append(vals, val)
+	}
+	return vals, nil
+}
@@ -0,0 +1,332 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"encoding/json"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// A multi-schema change job runs several sub-jobs on the same table, every
+// sub-job is run by the handler of its action type with a proxy job.
+//
+// The job has two phases:
+//  1. Revertible: the sub-jobs run one by one, until each of them reaches the
+//     state that can't be rolled back. Adding a column or an index stops before
+//     it becomes public, dropping a column or an index stops before it becomes
+//     invisible. If any sub-job fails, all the sub-jobs are rolled back in the
+//     reverse order.
+//  2. Non-revertible: every step runs one step of all the unfinished sub-jobs in
+//     the same transaction, so the changes of the first step become visible
+//     atomically. A failed step is retried. After maxNonRevertibleErrorCount
+//     failures the job is cancelled, so it doesn't block the DDL queue forever.
+//     The sub-jobs can't be rolled back then, the columns and indices which are
+//     still being added or dropped are removed from the table at once.
+
+// maxNonRevertibleErrorCount is the max number of the failed steps of a
+// non-revertible multi-schema change job.
+const maxNonRevertibleErrorCount = 100
+
+// isRevertibleSubJob returns whether the job runs a sub-job of a multi-schema
+// change which hasn't reached the state that can't be rolled back.
+func isRevertibleSubJob(job *model.Job) bool {
+	return job.MultiSchemaInfo != nil && job.MultiSchemaInfo.Revertible
+}
+
+func (d *ddl) onMultiSchemaChange(t *meta.Meta, job *model.Job) (ver int64, err error) {
+	// Handle the rolling back job.
+	if job.IsRollingback() {
+		ver, err = d.rollbackMultiSchemaChange(t, job)
+		return ver, errors.Trace(err)
+	}
+
+	info := job.MultiSchemaInfo
+	if job.IsCancelling() && !info.Revertible {
+		log.Infof("[ddl] the multi-schema change job %s can't be cancelled now", job)
+		job.State = model.JobStateRunning
+	}
+
+	if info.Revertible {
+		for _, sub := range info.SubJobs {
+			if !sub.Revertible {
+				continue
+			}
+			if job.IsCancelling() {
+				// If the value of SnapshotVer isn't zero, it means the work is backfilling the indexes.
+				if sub.Type != model.ActionAddIndex || sub.SchemaState != model.StateWriteReorganization || sub.SnapshotVer == 0 {
+					job.State = model.JobStateRollingback
+					return ver, errCancelledDDLJob
+				}
+				asyncNotify(d.reorgCtx.notifyCancelReorgJob)
+			}
+
+			proxy := sub.ToProxyJob(job)
+			if proxy.State == model.JobStateNone {
+				proxy.State = model.JobStateRunning
+			}
+			ver, err = d.runSubJob(t, proxy)
+			sub.FromProxyJob(proxy)
+			job.SchemaState = sub.SchemaState
+			job.SetRowCount(subJobsRowCount(info.SubJobs))
+			if proxy.IsCancelled() || proxy.IsRollingback() {
+				log.Warnf("[ddl] run the sub-job %s err %v, roll back the multi-schema change job", proxy, err)
+				job.State = model.JobStateRollingback
+				return ver, errors.Trace(err)
+			}
+			if err != nil || sub.Revertible || ver != 0 {
+				// The sub-job enters another state, wait for the schema to be synced.
+				return ver, errors.Trace(err)
+			}
+		}
+		info.Revertible = false
+	}
+
+	ver, err = d.runNonRevertibleStep(t, job)
+	return ver, errors.Trace(err)
+}
+
+// runNonRevertibleStep runs one step of all the unfinished sub-jobs in the same transaction.
+func (d *ddl) runNonRevertibleStep(t *meta.Meta, job *model.Job) (ver int64, err error) {
+	tblInfo, err := getTableInfo(t, job, job.SchemaID)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+
+	subJobs := job.MultiSchemaInfo.SubJobs
+	proxies := make([]*model.Job, len(subJobs))
+	for i, sub := range subJobs {
+		if sub.IsFinished() {
+			continue
+		}
+		proxy := sub.ToProxyJob(job)
+		proxy.State = model.JobStateRunning
+		var subVer int64
+		subVer, err = d.runSubJob(t, proxy)
+		if err != nil {
+			// Restore the table, so the other sub-jobs don't enter the next state alone.
+			if err1 := t.UpdateTable(job.SchemaID, tblInfo); err1 != nil {
+				return ver, errors.Trace(err1)
+			}
+			job.MultiSchemaInfo.NonRevertibleErrorCount++
+			if job.MultiSchemaInfo.NonRevertibleErrorCount < maxNonRevertibleErrorCount {
+				return 0, errors.Trace(err)
+			}
+			log.Errorf("[ddl] the sub-job %s of the non-revertible multi-schema change job %s failed %d times, cancel the job",
+				proxy, job, job.MultiSchemaInfo.NonRevertibleErrorCount)
+			cancelVer, err1 := removeUnfinishedSubJobs(t, job, tblInfo)
+			if err1 != nil {
+				return ver, errors.Trace(err1)
+			}
+			job.State = model.JobStateCancelled
+			return cancelVer, errors.Trace(err)
+		}
+		if subVer > ver {
+			ver = subVer
+		}
+		proxies[i] = proxy
+	}
+
+	finished := true
+	for i, proxy := range proxies {
+		if proxy != nil {
+			subJobs[i].FromProxyJob(proxy)
+		}
+		if !subJobs[i].IsFinished() {
+			finished = false
+		}
+	}
+	job.SchemaState = model.StatePublic
+	job.SetRowCount(subJobsRowCount(subJobs))
+	if !finished {
+		return ver, nil
+	}
+
+	tblInfo, err = getTableInfo(t, job, job.SchemaID)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	// Finish this job.
+	job.State = model.JobStateDone
+	job.BinlogInfo.AddTableInfo(ver, tblInfo)
+	return ver, nil
+}
+
+// removeUnfinishedSubJobs removes the columns and indices of the unfinished
+// sub-jobs which aren't public from the table, so that no column or index is
+// left in the middle of a schema change when the job is cancelled. The added
+// ones haven't become public, and the dropped ones have been hidden.
+func removeUnfinishedSubJobs(t *meta.Meta, job *model.Job, tblInfo *model.TableInfo) (ver int64, err error) {
+	for _, sub := range job.MultiSchemaInfo.SubJobs {
+		if sub.IsFinished() {
+			continue
+		}
+		proxy := sub.ToProxyJob(job)
+		var name model.CIStr
+		switch sub.Type {
+		case model.ActionAddColumn:
+			col := &model.ColumnInfo{}
+			err = proxy.DecodeArgs(col)
+			name = col.Name
+		case model.ActionAddIndex:
+			var unique bool
+			err = proxy.DecodeArgs(&unique, &name)
+		default:
+			err = proxy.DecodeArgs(&name)
+		}
+		sub.State = model.JobStateCancelled
+		if err != nil {
+			// Don't fail the cancellation, or the job runs forever again.
+			log.Errorf("[ddl] decode the args of the sub-job %s err %v", proxy, err)
+			continue
+		}
+
+		switch sub.Type {
+		case model.ActionAddColumn, model.ActionDropColumn:
+			colInfo := findCol(tblInfo.Columns, name.L)
+			if colInfo == nil || colInfo.State == model.StatePublic {
+				continue
+			}
+			// The column isn't public, so its offset is behind all the public columns.
+			newColumns := make([]*model.ColumnInfo, 0, len(tblInfo.Columns))
+			for _, col := range tblInfo.Columns {
+				if col.Name.L != name.L {
+					newColumns = append(newColumns, col)
+				}
+			}
+			tblInfo.Columns = newColumns
+		case model.ActionAddIndex, model.ActionDropIndex:
+			indexInfo := findIndexByName(name.L, tblInfo.Indices)
+			if indexInfo == nil || indexInfo.State == model.StatePublic {
+				continue
+			}
+			newIndices := make([]*model.IndexInfo, 0, len(tblInfo.Indices))
+			for _, idx := range tblInfo.Indices {
+				if idx.Name.L != name.L {
+					newIndices = append(newIndices, idx)
+				}
+			}
+			tblInfo.Indices = newIndices
+			// The data of the index is deleted by the delete-range worker like the dropped index
+			// and the rolled back adding index, they have the same args.
+			sub.Args = []interface{}{name, indexInfo.ID}
+			if sub.RawArgs, err = json.Marshal(sub.Args); err != nil {
+				return ver, errors.Trace(err)
+			}
+			if sub.Type == model.ActionAddIndex {
+				sub.State = model.JobStateRollbackDone
+			} else {
+				dropIndexColumnFlag(tblInfo, indexInfo)
+				sub.State = model.JobStateDone
+			}
+		}
+	}
+
+	ver, err = updateSchemaVersion(t, job)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	return ver, errors.Trace(t.UpdateTable(job.SchemaID, tblInfo))
+}
+
+// rollbackMultiSchemaChange rolls back the sub-jobs in the reverse order, one step at a time.
+func (d *ddl) rollbackMultiSchemaChange(t *meta.Meta, job *model.Job) (ver int64, err error) {
+	subJobs := job.MultiSchemaInfo.SubJobs
+	for i := len(subJobs) - 1; i >= 0; i-- {
+		sub := subJobs[i]
+		if sub.IsFinished() {
+			continue
+		}
+		if sub.SchemaState == model.StateNone {
+			// The sub-job doesn't change anything.
+			sub.State = model.JobStateCancelled
+			continue
+		}
+
+		proxy := sub.ToProxyJob(job)
+		if !proxy.IsRollingback() {
+			if err = convertSubJob2RollbackJob(proxy); err != nil {
+				return ver, errors.Trace(err)
+			}
+		}
+		ver, err = d.runSubJob(t, proxy)
+		sub.FromProxyJob(proxy)
+		job.SchemaState = sub.SchemaState
+		return ver, errors.Trace(err)
+	}
+
+	// Finish this job. Its error is the one that makes it roll back.
+	job.State = model.JobStateRollbackDone
+	job.SchemaState = model.StateNone
+	return ver, nil
+}
+
+// convertSubJob2RollbackJob changes the args of adding a column or an index to
+// the name of it, which are used by the rolling back jobs.
+func convertSubJob2RollbackJob(job *model.Job) error {
+	var name model.CIStr
+	switch job.Type {
+	case model.ActionAddColumn:
+		col := &model.ColumnInfo{}
+		if err := job.DecodeArgs(col); err != nil {
+			return errors.Trace(err)
+		}
+		name = col.Name
+	case model.ActionAddIndex:
+		var unique bool
+		if err := job.DecodeArgs(&unique, &name); err != nil {
+			return errors.Trace(err)
+		}
+	default:
+		return errInvalidDDLJob.Gen("invalid rolling back sub-job %v", job)
+	}
+	job.Args = []interface{}{name}
+	// The rolling back job decodes the args from the raw args.
+	rawArgs, err := json.Marshal(job.Args)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	job.RawArgs = rawArgs
+	job.State = model.JobStateRollingback
+	return nil
+}
+
+// runSubJob runs one step of the sub-job with its proxy job.
+func (d *ddl) runSubJob(t *meta.Meta, job *model.Job) (ver int64, err error) {
+	switch job.Type {
+	case model.ActionAddColumn:
+		ver, err = d.onAddColumn(t, job)
+	case model.ActionDropColumn:
+		ver, err = d.onDropColumn(t, job)
+	case model.ActionAddIndex:
+		ver, err = d.onCreateIndex(t, job, false)
+	case model.ActionDropIndex:
+		ver, err = d.onDropIndex(t, job)
+	default:
+		job.State = model.JobStateCancelled
+		err = errInvalidDDLJob.Gen("invalid sub-job %v", job)
+	}
+	return ver, errors.Trace(err)
+}
+
+func subJobsRowCount(subJobs []*model.SubJob) int64 {
+	var count int64
+	for _, sub := range subJobs {
+		count += sub.RowCount
+	}
+	return count
+}
@@ -14,6 +14,8 @@
 package ddl
 
 import (
+	"math"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -23,8 +25,15 @@ import (
 	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/sessionctx/varsutil"
+	"github.com/pingcap/tidb/store/tikv"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/codec"
 	"github.com/pingcap/tidb/util/mock"
 	log "github.com/sirupsen/logrus"
+	goctx "golang.org/x/net/context"
 )
 
 // reorgCtx is for reorganization.
@@ -177,3 +186,111 @@ func (r *reorgInfo) UpdateHandle(txn kv.Transaction, handle int64) error {
 	t := meta.NewMeta(txn)
 	return errors.Trace(t.UpdateDDLReorgHandle(r.Job, handle))
 }
+
+// reorgVars are the global variables which control the backfilling.
+var reorgVars = []string{variable.TiDBDDLReorgWorkerCount, variable.TiDBDDLReorgBatchSize, variable.TiDBDDLReorgRateLimit}
+
+// loadReorgVars loads the global variables which control the backfilling, so they can be changed
+// while a job is running. If it fails, the last loaded values are used.
+func (d *ddl) loadReorgVars() {
+	if d.ctxPool == nil {
+		return
+	}
+	resource, err := d.ctxPool.Get()
+	if err != nil {
+		log.Warnf("[ddl] get context to load reorg variables err %v", err)
+		return
+	}
+	defer d.ctxPool.Put(resource)
+
+	vars := resource.(context.Context).GetSessionVars()
+	for _, name := range reorgVars {
+		val, err := vars.GlobalVarsAccessor.GetGlobalSysVar(name)
+		if err == nil {
+			err = varsutil.SetSessionSystemVar(vars, name, types.NewStringDatum(val))
+		}
+		if err != nil {
+			log.Warnf("[ddl] load reorg variable %s err %v", name, err)
+		}
+	}
+}
+
+// splitRegionMaxBackoff is the max backoff time in milliseconds to locate the regions of a table.
+const splitRegionMaxBackoff = 20000
+
+// splitTableRanges splits the handles of the table from startHandle by the regions, and returns at most
+// limit ranges. If the store doesn't have regions, the rest of the table is returned as one range.
+func (d *ddl) splitTableRanges(t table.Table, startHandle int64, limit int) ([]handleInfo, error) {
+	store, ok := d.store.(tikv.Storage)
+	if !ok {
+		return []handleInfo{{startHandle: startHandle, endHandle: math.MaxInt64}}, nil
+	}
+
+	bo := tikv.NewBackoffer(splitRegionMaxBackoff, goctx.Background())
+	ranges := make([]handleInfo, 0, limit)
+	for len(ranges) < limit {
+		loc, err := store.GetRegionCache().LocateKey(bo, t.RecordKey(startHandle))
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		endHandle := regionEndHandle(t, loc.EndKey)
+		ranges = append(ranges, handleInfo{startHandle: startHandle, endHandle: endHandle})
+		if endHandle == math.MaxInt64 {
+			break
+		}
+		startHandle = endHandle
+	}
+	return ranges, nil
+}
+
+// regionEndHandle returns the first handle whose record key isn't less than the end key of a region.
+func regionEndHandle(t table.Table, endKey kv.Key) int64 {
+	recordPrefix := t.RecordPrefix()
+	if len(endKey) == 0 || !endKey.HasPrefix(recordPrefix) {
+		// The region covers the rest of the table.
+		return math.MaxInt64
+	}
+	// The end key may not be a complete record key, pad it to the smallest record key that is not less than it.
+	handleKey := make([]byte, 8)
+	copy(handleKey, endKey[len(recordPrefix):])
+	_, handle, err := codec.DecodeInt(handleKey)
+	if err != nil {
+		return math.MaxInt64
+	}
+	if len(endKey) > len(recordPrefix)+len(handleKey) && handle < math.MaxInt64 {
+		handle++
+	}
+	return handle
+}
+
+// reorgThrottle limits the number of the rows backfilled per second by all the workers.
+type reorgThrottle struct {
+	mu sync.Mutex
+	// limit is the max number of rows per second, 0 means no limit.
+	limit int64
+	// next is the time when the next batch can start.
+	next time.Time
+}
+
+func (r *reorgThrottle) setLimit(limit int64) {
+	r.mu.Lock()
+	r.limit = limit
+	r.mu.Unlock()
+}
+
+// wait reserves the time for the rows which have been backfilled, and waits until the next batch can start.
+func (r *reorgThrottle) wait(rows int) {
+	r.mu.Lock()
+	if r.limit <= 0 {
+		r.mu.Unlock()
+		return
+	}
+	now := time.Now()
+	if r.next.Before(now) {
+		r.next = now
+	}
+	r.next = r.next.Add(time.Duration(int64(rows) * int64(time.Second) / r.limit))
+	delay := r.next.Sub(now)
+	r.mu.Unlock()
+	time.Sleep(delay)
+}
@@ -14,12 +14,17 @@
 package ddl
 
 import (
+	"math"
 	"time"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/store/tikv"
+	"github.com/pingcap/tidb/store/tikv/mocktikv"
+	"github.com/pingcap/tidb/table/tables"
+	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/testleak"
 	goctx "golang.org/x/net/context"
@@ -192,3 +197,61 @@ func (s *testDDLSuite) TestReorgOwner(c *C) {
 	})
 	c.Assert(err, IsNil)
 }
+
+func (s *testDDLSuite) TestSplitTableRanges(c *C) {
+	defer testleak.AfterTest(c)()
+	cluster := mocktikv.NewCluster()
+	_, _, regionID := mocktikv.BootstrapWithSingleStore(cluster)
+	store, err := tikv.NewMockTikvStore(tikv.WithCluster(cluster))
+	c.Assert(err, IsNil)
+	defer store.Close()
+
+	d := testNewDDL(goctx.Background(), nil, store, nil, nil, testLease)
+	defer d.Stop()
+
+	tblInfo := &model.TableInfo{ID: 1, Name: model.NewCIStr("t"), State: model.StatePublic}
+	tbl, err := tables.TableFromMeta(nil, tblInfo)
+	c.Assert(err, IsNil)
+
+	// Split the table at a complete record key, a record key with a suffix and a prefix of a record key.
+	splitKeys := [][]byte{
+		tablecodec.EncodeRowKeyWithHandle(tblInfo.ID, 100),
+		append(tablecodec.EncodeRowKeyWithHandle(tblInfo.ID, 300), 0),
+		tablecodec.EncodeRowKeyWithHandle(tblInfo.ID, 1<<40)[:len(tbl.RecordPrefix())+4],
+	}
+	for _, key := range splitKeys {
+		ids := cluster.AllocIDs(2)
+		cluster.Split(regionID, ids[0], key, []uint64{ids[1]}, ids[1])
+		regionID = ids[0]
+	}
+
+	ranges, err := d.splitTableRanges(tbl, 0, 10)
+	c.Assert(err, IsNil)
+	c.Assert(ranges, DeepEquals, []handleInfo{
+		{startHandle: 0, endHandle: 100},
+		{startHandle: 100, endHandle: 301},
+		{startHandle: 301, endHandle: 1 << 40},
+		{startHandle: 1 << 40, endHandle: math.MaxInt64},
+	})
+	ranges, err = d.splitTableRanges(tbl, 200, 2)
+	c.Assert(err, IsNil)
+	c.Assert(ranges, DeepEquals, []handleInfo{
+		{startHandle: 200, endHandle: 301},
+		{startHandle: 301, endHandle: 1 << 40},
+	})
+	c.Assert(ranges[0].contains(300), IsTrue)
+	c.Assert(ranges[0].contains(301), IsFalse)
+}
+
+func (s *testDDLSuite) TestReorgThrottle(c *C) {
+	throttle := &reorgThrottle{}
+	start := time.Now()
+	throttle.wait(1000)
+	c.Assert(time.Since(start), Less, 50*time.Millisecond)
+
+	throttle.setLimit(1000)
+	start = time.Now()
+	throttle.wait(50)
+	throttle.wait(50)
+	c.Assert(time.Since(start) >= 100*time.Millisecond, IsTrue)
+}
@@ -73,6 +73,37 @@ func (d *ddl) onCreateSchema(t *meta.Meta, job *model.Job) (ver int64, _ error)
 	}
 }
 
+func (d *ddl) onModifySchemaCharsetAndCollate(t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	var toCharset, toCollate string
+	if err := job.DecodeArgs(&toCharset, &toCollate); err != nil {
+		job.State = model.JobStateCancelled
+		return ver, errors.Trace(err)
+	}
+
+	dbInfo, err := t.GetDatabase(job.SchemaID)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	if dbInfo == nil {
+		job.State = model.JobStateCancelled
+		return ver, infoschema.ErrDatabaseNotExists.GenByArgs("")
+	}
+
+	dbInfo.Charset = toCharset
+	dbInfo.Collate = toCollate
+	if err = t.UpdateDatabase(dbInfo); err != nil {
+		return ver, errors.Trace(err)
+	}
+	ver, err = updateSchemaVersion(t, job)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	// Finish this job.
+	job.State = model.JobStateDone
+	job.BinlogInfo.AddDBInfo(ver, dbInfo)
+	return ver, nil
+}
+
 func (d *ddl) onDropSchema(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 	dbInfo, err := t.GetDatabase(job.SchemaID)
 	if err != nil {
@@ -31,7 +31,9 @@ import (
 func (d *ddl) onCreateTable(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 	schemaID := job.SchemaID
 	tbInfo := &model.TableInfo{}
-	if err := job.DecodeArgs(tbInfo); err != nil {
+	// invisible is true if the table is created by CREATE TABLE ... SELECT.
+	var invisible bool
+	if err := job.DecodeArgs(tbInfo, &invisible); err != nil {
 		// Invalid arguments, cancel this job.
 		job.State = model.JobStateCancelled
 		return ver, errors.Trace(err)
@@ -53,6 +55,11 @@ func (d *ddl) onCreateTable(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 		// none -> public
 		job.SchemaState = model.StatePublic
 		tbInfo.State = model.StatePublic
+		if invisible {
+			// none -> write reorganization, the table becomes public after its rows are inserted.
+			job.SchemaState = model.StateWriteReorganization
+			tbInfo.State = model.StateWriteReorganization
+		}
 		err = t.CreateTable(schemaID, tbInfo)
 		if err != nil {
 			return ver, errors.Trace(err)
@@ -74,6 +81,39 @@ func (d *ddl) onCreateTable(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 	}
 }
 
+// onPublishTable makes the table created by CREATE TABLE ... SELECT public
+// after its rows are inserted.
+func (d *ddl) onPublishTable(t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	tblInfo, err := t.GetTable(job.SchemaID, job.TableID)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	if tblInfo == nil {
+		job.State = model.JobStateCancelled
+		return ver, errors.Trace(infoschema.ErrTableNotExists.GenByArgs(
+			fmt.Sprintf("(Schema ID %d)", job.SchemaID),
+			fmt.Sprintf("(Table ID %d)", job.TableID),
+		))
+	}
+	if tblInfo.State != model.StateWriteReorganization {
+		job.State = model.JobStateCancelled
+		return ver, ErrInvalidTableState.Gen("invalid table state %v", tblInfo.State)
+	}
+
+	originalState := job.SchemaState
+	// write reorganization -> public
+	job.SchemaState = model.StatePublic
+	tblInfo.State = model.StatePublic
+	ver, err = updateTableInfo(t, job, tblInfo, originalState)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	// Finish this job.
+	job.State = model.JobStateDone
+	job.BinlogInfo.AddTableInfo(ver, tblInfo)
+	return ver, nil
+}
+
 func (d *ddl) onDropTable(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 	schemaID := job.SchemaID
 	tableID := job.TableID
@@ -111,7 +151,8 @@ func (d *ddl) onDropTable(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 		job.SchemaState = model.StateDeleteOnly
 		tblInfo.State = model.StateDeleteOnly
 		ver, err = updateTableInfo(t, job, tblInfo, originalState)
-	case model.StateDeleteOnly:
+	case model.StateDeleteOnly, model.StateWriteReorganization:
+		// The table in write reorganization is invisible, so it's dropped at once.
 		tblInfo.State = model.StateNone
 		job.SchemaState = model.StateNone
 		ver, err = updateTableInfo(t, job, tblInfo, originalState)
@@ -156,7 +197,11 @@ func (d *ddl) getTable(schemaID int64, tblInfo *model.TableInfo) (table.Table, e
 }
 
 func getTableInfo(t *meta.Meta, job *model.Job, schemaID int64) (*model.TableInfo, error) {
-	tableID := job.TableID
+	return getTableInfoByID(t, job, schemaID, job.TableID)
+}
+
+// getTableInfoByID gets the public table of the job which changes several tables.
+func getTableInfoByID(t *meta.Meta, job *model.Job, schemaID, tableID int64) (*model.TableInfo, error) {
 	tblInfo, err := t.GetTable(schemaID, tableID)
 	if err != nil {
 		if meta.ErrDBNotExists.Equal(err) {
@@ -222,6 +267,60 @@ func (d *ddl) onTruncateTable(t *meta.Meta, job *model.Job) (ver int64, _ error)
 	return ver, nil
 }
 
+// onRecoverTable recovers a dropped or truncated table with its original table ID, its data isn't touched
+// by the drop or truncate job, we only need to remove it from gc_delete_range table and create the table meta.
+func (d *ddl) onRecoverTable(t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	schemaID := job.SchemaID
+	tblInfo := &model.TableInfo{}
+	var autoID, dropJobID int64
+	var dropTS uint64
+	if err := job.DecodeArgs(tblInfo, &autoID, &dropJobID, &dropTS); err != nil {
+		// Invalid arguments, cancel this job.
+		job.State = model.JobStateCancelled
+		return ver, errors.Trace(err)
+	}
+
+	err := checkTableNotExists(t, job, schemaID, tblInfo.Name.L)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	if existing, err := t.GetTable(schemaID, tblInfo.ID); err != nil {
+		return ver, errors.Trace(err)
+	} else if existing != nil {
+		job.State = model.JobStateCancelled
+		return ver, errors.Trace(infoschema.ErrTableExists.GenByArgs(existing.Name))
+	}
+
+	// Remove the table from gc_delete_range table first. The range must be still there and the GC safe point
+	// must be earlier than the drop, otherwise the data may have been deleted, so we cancel the job.
+	removed, err := d.delRangeManager.removeFromGCDeleteRange(dropJobID, tblInfo.ID, dropTS)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	if !removed {
+		job.State = model.JobStateCancelled
+		return ver, errors.Errorf("can't recover table %s, its data may have been deleted by GC", tblInfo.Name)
+	}
+
+	tblInfo.State = model.StatePublic
+	if err = t.CreateTable(schemaID, tblInfo); err != nil {
+		return ver, errors.Trace(err)
+	}
+	if _, err = t.GenAutoTableID(schemaID, tblInfo.ID, autoID); err != nil {
+		return ver, errors.Trace(err)
+	}
+
+	ver, err = updateSchemaVersion(t, job)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	// The statistics of the table are kept after it's dropped, so we don't notify the create table event.
+	job.State = model.JobStateDone
+	job.SchemaState = model.StatePublic
+	job.BinlogInfo.AddTableInfo(ver, tblInfo)
+	return ver, nil
+}
+
 func (d *ddl) onRebaseAutoID(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 	schemaID := job.SchemaID
 	var newBase int64
@@ -259,6 +358,73 @@ func (d *ddl) onRebaseAutoID(t *meta.Meta, job *model.Job) (ver int64, _ error)
 	return ver, nil
 }
 
+func (d *ddl) onModifyTableComment(t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	var comment string
+	if err := job.DecodeArgs(&comment); err != nil {
+		job.State = model.JobStateCancelled
+		return ver, errors.Trace(err)
+	}
+
+	tblInfo, err := getTableInfo(t, job, job.SchemaID)
+	if err != nil {
+		job.State = model.JobStateCancelled
+		return ver, errors.Trace(err)
+	}
+	tblInfo.Comment = comment
+
+	ver, err = updateTableInfo(t, job, tblInfo, tblInfo.State)
+	if err != nil {
+		job.State = model.JobStateCancelled
+		return ver, errors.Trace(err)
+	}
+	job.State = model.JobStateDone
+	job.BinlogInfo.AddTableInfo(ver, tblInfo)
+	return ver, nil
+}
+
+func (d *ddl) onModifyTableCharsetAndCollate(t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	var toCharset, toCollate string
+	var convert bool
+	if err := job.DecodeArgs(&toCharset, &toCollate, &convert); err != nil {
+		job.State = model.JobStateCancelled
+		return ver, errors.Trace(err)
+	}
+
+	tblInfo, err := getTableInfo(t, job, job.SchemaID)
+	if err != nil {
+		job.State = model.JobStateCancelled
+		return ver, errors.Trace(err)
+	}
+	tblInfo.Charset = toCharset
+	tblInfo.Collate = toCollate
+	if convert {
+		for _, col := range tblInfo.Columns {
+			// Double check. The columns may be changed after the job is added.
+			if err = checkModifyColumnCharset(col, toCharset); err != nil {
+				job.State = model.JobStateCancelled
+				return ver, errors.Trace(err)
+			}
+			if err = checkModifyIndexedColumnCollation(tblInfo, col, toCollate); err != nil {
+				job.State = model.JobStateCancelled
+				return ver, errors.Trace(err)
+			}
+			if hasCharset(col) {
+				col.Charset = toCharset
+				col.Collate = toCollate
+			}
+		}
+	}
+
+	ver, err = updateTableInfo(t, job, tblInfo, tblInfo.State)
+	if err != nil {
+		job.State = model.JobStateCancelled
+		return ver, errors.Trace(err)
+	}
+	job.State = model.JobStateDone
+	job.BinlogInfo.AddTableInfo(ver, tblInfo)
+	return ver, nil
+}
+
 func (d *ddl) onRenameTable(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 	var oldSchemaID int64
 	var tableName model.CIStr
@@ -272,19 +438,144 @@ func (d *ddl) onRenameTable(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 	if err != nil {
 		return ver, errors.Trace(err)
 	}
-	var baseID int64
-	shouldDelAutoID := false
 	newSchemaID := job.SchemaID
 	if newSchemaID != oldSchemaID {
-		shouldDelAutoID = true
 		err = checkTableNotExists(t, job, newSchemaID, tblInfo.Name.L)
 		if err != nil {
 			return ver, errors.Trace(err)
 		}
+	}
+	if err = renameTable(t, job, tblInfo, oldSchemaID, newSchemaID, tableName); err != nil {
+		return ver, errors.Trace(err)
+	}
+
+	ver, err = updateSchemaVersion(t, job)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	job.State = model.JobStateDone
+	job.SchemaState = model.StatePublic
+	job.BinlogInfo.AddTableInfo(ver, tblInfo)
+	return ver, nil
+}
+
+// onRenameTables renames several tables in one schema version, the tables are renamed in order
+// like they are renamed one by one.
+func (d *ddl) onRenameTables(t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	var oldSchemaIDs, newSchemaIDs, tableIDs []int64
+	var tableNames []model.CIStr
+	if err := job.DecodeArgs(&oldSchemaIDs, &newSchemaIDs, &tableNames, &tableIDs); err != nil {
+		// Invalid arguments, cancel this job.
+		job.State = model.JobStateCancelled
+		return ver, errors.Trace(err)
+	}
+	if len(oldSchemaIDs) != len(tableIDs) || len(newSchemaIDs) != len(tableIDs) || len(tableNames) != len(tableIDs) {
+		job.State = model.JobStateCancelled
+		return ver, errInvalidDDLJob.Gen("invalid rename tables job %v", job)
+	}
+	// Check all the renames before changing anything, the changes are committed even if the job is cancelled.
+	if err := checkRenameTables(t, job, oldSchemaIDs, newSchemaIDs, tableNames, tableIDs); err != nil {
+		return ver, errors.Trace(err)
+	}
+
+	tblInfos := make([]*model.TableInfo, 0, len(tableIDs))
+	tblOffsets := make(map[int64]int, len(tableIDs))
+	for i, tableID := range tableIDs {
+		tblInfo, err := getTableInfoByID(t, job, oldSchemaIDs[i], tableID)
+		if err != nil {
+			return ver, errors.Trace(err)
+		}
+		if err = renameTable(t, job, tblInfo, oldSchemaIDs[i], newSchemaIDs[i], tableNames[i]); err != nil {
+			return ver, errors.Trace(err)
+		}
+		// A table may be renamed more than once.
+		if offset, ok := tblOffsets[tableID]; ok {
+			tblInfos[offset] = tblInfo
+			continue
+		}
+		tblOffsets[tableID] = len(tblInfos)
+		tblInfos = append(tblInfos, tblInfo)
+	}
+
+	ver, err := updateSchemaVersion(t, job)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	job.State = model.JobStateDone
+	job.SchemaState = model.StatePublic
+	job.BinlogInfo.AddMultipleTableInfos(ver, tblInfos)
+	return ver, nil
+}
+
+// checkRenameTables checks that every table to be renamed exists and its new name isn't used,
+// after the former tables have been renamed.
+func checkRenameTables(t *meta.Meta, job *model.Job, oldSchemaIDs, newSchemaIDs []int64, tableNames []model.CIStr, tableIDs []int64) error {
+	// tables maps the schema ID to the IDs of the tables in it by the table names.
+	tables := make(map[int64]map[string]int64)
+	getTables := func(schemaID int64) (map[string]int64, error) {
+		if names, ok := tables[schemaID]; ok {
+			return names, nil
+		}
+		tblInfos, err := t.ListTables(schemaID)
+		if err != nil {
+			if meta.ErrDBNotExists.Equal(err) {
+				job.State = model.JobStateCancelled
+				return nil, infoschema.ErrDatabaseNotExists.GenByArgs(fmt.Sprintf("(Schema ID %d)", schemaID))
+			}
+			return nil, errors.Trace(err)
+		}
+		names := make(map[string]int64, len(tblInfos))
+		for _, tblInfo := range tblInfos {
+			names[tblInfo.Name.L] = tblInfo.ID
+		}
+		tables[schemaID] = names
+		return names, nil
+	}
+
+	for i, tableID := range tableIDs {
+		oldNames, err := getTables(oldSchemaIDs[i])
+		if err != nil {
+			return errors.Trace(err)
+		}
+		newNames, err := getTables(newSchemaIDs[i])
+		if err != nil {
+			return errors.Trace(err)
+		}
+		oldName := ""
+		for name, id := range oldNames {
+			if id == tableID {
+				oldName = name
+				break
+			}
+		}
+		if len(oldName) == 0 {
+			job.State = model.JobStateCancelled
+			return infoschema.ErrTableNotExists.GenByArgs(
+				fmt.Sprintf("(Schema ID %d)", oldSchemaIDs[i]),
+				fmt.Sprintf("(Table ID %d)", tableID),
+			)
+		}
+		if _, ok := newNames[tableNames[i].L]; ok {
+			job.State = model.JobStateCancelled
+			return infoschema.ErrTableExists.GenByArgs(tableNames[i])
+		}
+		delete(oldNames, oldName)
+		newNames[tableNames[i].L] = tableID
+	}
+	return nil
+}
+
+// renameTable moves the table from the old schema to the new schema with the new name.
+func renameTable(t *meta.Meta, job *model.Job, tblInfo *model.TableInfo, oldSchemaID, newSchemaID int64, tableName model.CIStr) error {
+	var baseID int64
+	var err error
+	shouldDelAutoID := false
+	if newSchemaID != oldSchemaID {
+		shouldDelAutoID = true
 		baseID, err = t.GetAutoTableID(tblInfo.GetDBID(oldSchemaID), tblInfo.ID)
 		if err != nil {
 			job.State = model.JobStateCancelled
-			return ver, errors.Trace(err)
+			return errors.Trace(err)
 		}
 		// It's compatible with old version.
 		// TODO: Remove it.
@@ -294,31 +585,23 @@ func (d *ddl) onRenameTable(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 	err = t.DropTable(oldSchemaID, tblInfo.ID, shouldDelAutoID)
 	if err != nil {
 		job.State = model.JobStateCancelled
-		return ver, errors.Trace(err)
+		return errors.Trace(err)
 	}
 	tblInfo.Name = tableName
 	err = t.CreateTable(newSchemaID, tblInfo)
 	if err != nil {
 		job.State = model.JobStateCancelled
-		return ver, errors.Trace(err)
+		return errors.Trace(err)
 	}
 	// Update the table's auto-increment ID.
 	if newSchemaID != oldSchemaID {
 		_, err = t.GenAutoTableID(newSchemaID, tblInfo.ID, baseID)
 		if err != nil {
 			job.State = model.JobStateCancelled
-			return ver, errors.Trace(err)
+			return errors.Trace(err)
 		}
 	}
-
-	ver, err = updateSchemaVersion(t, job)
-	if err != nil {
-		return ver, errors.Trace(err)
-	}
-	job.State = model.JobStateDone
-	job.SchemaState = model.StatePublic
-	job.BinlogInfo.AddTableInfo(ver, tblInfo)
-	return ver, nil
+	return nil
 }
 
 func checkTableNotExists(t *meta.Meta, job *model.Job, schemaID int64, tableName string) error {
@@ -16,6 +16,7 @@ package util
 import (
 	"encoding/hex"
 	"fmt"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/context"
@@ -28,6 +29,11 @@ const (
 	loadDeleteRangeSQL     = `SELECT job_id, element_id, start_key, end_key FROM mysql.gc_delete_range WHERE ts < %v ORDER BY ts`
 	completeDeleteRangeSQL = `DELETE FROM mysql.gc_delete_range WHERE job_id = %d AND element_id = %d`
 	updateDeleteRangeSQL   = `UPDATE mysql.gc_delete_range SET start_key = "%s" WHERE job_id = %d AND element_id = %d AND start_key = "%s"`
+	existDeleteRangeSQL    = `SELECT job_id FROM mysql.gc_delete_range WHERE job_id = %d AND element_id = %d`
+	loadGCSafePointSQL     = `SELECT variable_value FROM mysql.tidb WHERE variable_name = "tikv_gc_safe_point"`
+
+	// gcTimeFormat is the format the GC worker saves the time in mysql.tidb.
+	gcTimeFormat = "20060102-15:04:05 -0700 MST"
 )
 
 // DelRangeTask is for run delete-range command in gc_worker.
@@ -92,3 +98,49 @@ func UpdateDeleteRange(ctx context.Context, dr DelRangeTask, newStartKey, oldSta
 	_, err := ctx.(sqlexec.SQLExecutor).Execute(goctx.TODO(), sql)
 	return errors.Trace(err)
 }
+
+// DeleteRangeExists checks if the delete range of the element in the job is
+// still in gc_delete_range table, which means it hasn't been deleted.
+func DeleteRangeExists(ctx context.Context, jobID, elementID int64) (bool, error) {
+	return deleteRangeExists(ctx, fmt.Sprintf(existDeleteRangeSQL, jobID, elementID))
+}
+
+// LockDeleteRange is like DeleteRangeExists, but it also locks the delete range
+// in the current transaction, so the transaction conflicts with the GC worker
+// which deletes the range at the same time.
+func LockDeleteRange(ctx context.Context, jobID, elementID int64) (bool, error) {
+	return deleteRangeExists(ctx, fmt.Sprintf(existDeleteRangeSQL+" FOR UPDATE", jobID, elementID))
+}
+
+func deleteRangeExists(ctx context.Context, sql string) (bool, error) {
+	rss, err := ctx.(sqlexec.SQLExecutor).Execute(goctx.TODO(), sql)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	row, err := rss[0].Next(goctx.TODO())
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	return row != nil, errors.Trace(rss[0].Close())
+}
+
+// LoadGCSafePoint loads the GC safe point saved by the GC worker. It returns
+// the zero time if GC hasn't run yet.
+func LoadGCSafePoint(ctx context.Context) (time.Time, error) {
+	rss, err := ctx.(sqlexec.SQLExecutor).Execute(goctx.TODO(), loadGCSafePointSQL)
+	if err != nil {
+		return time.Time{}, errors.Trace(err)
+	}
+	row, err := rss[0].Next(goctx.TODO())
+	if err != nil {
+		return time.Time{}, errors.Trace(err)
+	}
+	if err = rss[0].Close(); err != nil {
+		return time.Time{}, errors.Trace(err)
+	}
+	if row == nil {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(gcTimeFormat, row.GetString(0))
+	return t, errors.Trace(err)
+}
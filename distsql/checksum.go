@@ -0,0 +1,105 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distsql
+
+import (
+	"hash/crc64"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/util/codec"
+)
+
+// ChecksumAlgorithm is the algorithm used to calculate a checksum.
+type ChecksumAlgorithm uint64
+
+// ChecksumAlgorithm values.
+const (
+	// ChecksumCrc64Xor XORs the CRC64 (ECMA) digests of all key-value pairs,
+	// so the result does not depend on the order of the pairs.
+	ChecksumCrc64Xor ChecksumAlgorithm = 1
+)
+
+var crc64Table = crc64.MakeTable(crc64.ECMA)
+
+// ChecksumRequest is the request data of a kv.ReqTypeChecksum request. The key
+// ranges to calculate on are carried by the kv.Request.
+type ChecksumRequest struct {
+	StartTs   uint64
+	Algorithm ChecksumAlgorithm
+}
+
+// Marshal encodes the request.
+func (r *ChecksumRequest) Marshal() ([]byte, error) {
+	data := codec.EncodeUint(nil, r.StartTs)
+	return codec.EncodeUint(data, uint64(r.Algorithm)), nil
+}
+
+// Unmarshal decodes the request.
+func (r *ChecksumRequest) Unmarshal(data []byte) error {
+	data, startTs, err := codec.DecodeUint(data)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	_, algorithm, err := codec.DecodeUint(data)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	r.StartTs, r.Algorithm = startTs, ChecksumAlgorithm(algorithm)
+	return nil
+}
+
+// ChecksumResponse is the response data of a kv.ReqTypeChecksum request, it
+// is also used to accumulate the checksum of key-value pairs.
+type ChecksumResponse struct {
+	Checksum   uint64
+	TotalKvs   uint64
+	TotalBytes uint64
+}
+
+// Update adds a key-value pair into the checksum.
+func (r *ChecksumResponse) Update(key, value []byte) {
+	digest := crc64.Update(0, crc64Table, key)
+	digest = crc64.Update(digest, crc64Table, value)
+	r.Checksum ^= digest
+	r.TotalKvs++
+	r.TotalBytes += uint64(len(key) + len(value))
+}
+
+// Merge adds the checksum of other key-value pairs into the checksum.
+func (r *ChecksumResponse) Merge(other *ChecksumResponse) {
+	r.Checksum ^= other.Checksum
+	r.TotalKvs += other.TotalKvs
+	r.TotalBytes += other.TotalBytes
+}
+
+// Marshal encodes the response.
+func (r *ChecksumResponse) Marshal() ([]byte, error) {
+	data := codec.EncodeUint(nil, r.Checksum)
+	data = codec.EncodeUint(data, r.TotalKvs)
+	return codec.EncodeUint(data, r.TotalBytes), nil
+}
+
+// Unmarshal decodes the response.
+func (r *ChecksumResponse) Unmarshal(data []byte) error {
+	values := make([]uint64, 3)
+	for i := range values {
+		var err error
+		data, values[i], err = codec.DecodeUint(data)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	r.Checksum, r.TotalKvs, r.TotalBytes = values[0], values[1], values[2]
+	return nil
+}
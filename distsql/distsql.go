@@ -337,6 +337,32 @@ func Analyze(ctx goctx.Context, client kv.Client, kvReq *kv.Request) (SelectResu
 	return result, nil
 }
 
+// Checksum sends a checksum request.
+func Checksum(ctx goctx.Context, client kv.Client, kvReq *kv.Request) (SelectResult, error) {
+	var err error
+	defer func() {
+		// Add metrics.
+		if err != nil {
+			queryCounter.WithLabelValues(queryFailed).Inc()
+		} else {
+			queryCounter.WithLabelValues(querySucc).Inc()
+		}
+	}()
+
+	resp := client.Send(ctx, kvReq)
+	if resp == nil {
+		err = errors.New("client returns nil response")
+		return nil, errors.Trace(err)
+	}
+	result := &selectResult{
+		label:   "checksum",
+		resp:    resp,
+		results: make(chan newResultWithErr, kvReq.Concurrency),
+		closed:  make(chan struct{}),
+	}
+	return result, nil
+}
+
 // XAPI error codes.
 const (
 	codeInvalidResp = 1
@@ -35,6 +35,7 @@ import (
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/statistics"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/udf"
 	log "github.com/sirupsen/logrus"
 	goctx "golang.org/x/net/context"
 	"google.golang.org/grpc"
@@ -46,6 +47,7 @@ type Domain struct {
 	store           kv.Storage
 	infoHandle      *infoschema.Handle
 	privHandle      *privileges.Handle
+	routines        routineCache
 	statsHandle     unsafe.Pointer
 	statsLease      time.Duration
 	ddl             ddl.DDL
@@ -560,6 +562,54 @@ func (do *Domain) LoadPrivilegeLoop(ctx context.Context) error {
 	return nil
 }
 
+// LoadUDFLoop loads the user-defined functions, and starts a goroutine to reload them in a loop.
+// It should be called only once in BootstrapSession.
+func (do *Domain) LoadUDFLoop(ctx context.Context) error {
+	ctx.GetSessionVars().InRestrictedSQL = true
+	err := udf.Reload(ctx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	var watchCh clientv3.WatchChan
+	duration := 5 * time.Minute
+	if do.etcdClient != nil {
+		watchCh = do.etcdClient.Watch(goctx.Background(), udfKey)
+		duration = 10 * time.Minute
+	}
+
+	go func() {
+		var count int
+		for {
+			ok := true
+			select {
+			case <-do.exit:
+				return
+			case _, ok = <-watchCh:
+			case <-time.After(duration):
+			}
+			if !ok {
+				log.Error("[domain] load udf loop watch channel closed.")
+				watchCh = do.etcdClient.Watch(goctx.Background(), udfKey)
+				count++
+				if count > 10 {
+					time.Sleep(time.Duration(count) * time.Second)
+				}
+				continue
+			}
+
+			count = 0
+			err := udf.Reload(ctx)
+			if err != nil {
+				log.Error("[domain] load udf fail:", errors.ErrorStack(err))
+			} else {
+				log.Info("[domain] reload udf success.")
+			}
+		}
+	}()
+	return nil
+}
+
 // PrivilegeHandle returns the MySQLPrivilege.
 func (do *Domain) PrivilegeHandle() *privileges.Handle {
 	return do.privHandle
@@ -696,6 +746,20 @@ func (do *Domain) NotifyUpdatePrivilege(ctx context.Context) {
 	}
 }
 
+const udfKey = "/tidb/udf"
+
+// NotifyUpdateUDF updates udf key in etcd, TiDB client that watches
+// the key will get notification.
+func (do *Domain) NotifyUpdateUDF(ctx context.Context) {
+	if do.etcdClient != nil {
+		kv := do.etcdClient.KV
+		_, err := kv.Put(goctx.Background(), udfKey, "")
+		if err != nil {
+			log.Warn("notify update udf failed:", err)
+		}
+	}
+}
+
 // Domain error codes.
 const (
 	codeInfoSchemaExpired terror.ErrCode = 1
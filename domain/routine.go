@@ -0,0 +1,181 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/etcd/clientv3"
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/sqlexec"
+	log "github.com/sirupsen/logrus"
+	goctx "golang.org/x/net/context"
+)
+
+// RoutineInfo is a stored routine in mysql.proc.
+type RoutineInfo struct {
+	// DB is the database of the routine.
+	DB string
+	// Definition is the CREATE PROCEDURE or CREATE FUNCTION statement which creates the routine.
+	Definition string
+	// SQLMode is the sql_mode when the routine is created, the routine runs with it.
+	SQLMode string
+	// Definer is the user@host whose privileges the routine runs with if its SQL SECURITY is DEFINER.
+	Definer string
+}
+
+// routineCache holds the stored routines, the keys are the lower case types, databases and names.
+type routineCache struct {
+	sync.RWMutex
+	routines map[string]*RoutineInfo
+}
+
+func routineKey(tp ast.RoutineType, db, name string) string {
+	return strings.ToLower(tp.String() + " " + db + "." + name)
+}
+
+// Routine returns the stored routine, it returns nil if the routine doesn't exist.
+func (do *Domain) Routine(tp ast.RoutineType, db, name string) *RoutineInfo {
+	do.routines.RLock()
+	info := do.routines.routines[routineKey(tp, db, name)]
+	do.routines.RUnlock()
+	return info
+}
+
+// SetRoutine adds the stored routine name or replaces it.
+func (do *Domain) SetRoutine(tp ast.RoutineType, name string, info *RoutineInfo) {
+	do.routines.Lock()
+	if do.routines.routines == nil {
+		do.routines.routines = make(map[string]*RoutineInfo)
+	}
+	do.routines.routines[routineKey(tp, info.DB, name)] = info
+	do.routines.Unlock()
+}
+
+// DeleteRoutine removes the stored routine.
+func (do *Domain) DeleteRoutine(tp ast.RoutineType, db, name string) {
+	do.routines.Lock()
+	delete(do.routines.routines, routineKey(tp, db, name))
+	do.routines.Unlock()
+}
+
+// DeleteRoutinesOfDB removes the stored routines of the database db.
+func (do *Domain) DeleteRoutinesOfDB(db string) {
+	db = strings.ToLower(db)
+	do.routines.Lock()
+	for key, info := range do.routines.routines {
+		if strings.ToLower(info.DB) == db {
+			delete(do.routines.routines, key)
+		}
+	}
+	do.routines.Unlock()
+}
+
+// reloadRoutines loads all the stored routines in mysql.proc and replaces the cached ones with them.
+func (do *Domain) reloadRoutines(ctx context.Context) error {
+	rss, err := ctx.(sqlexec.SQLExecutor).Execute(goctx.Background(), "SELECT db, name, type, definition, sql_mode, definer FROM mysql.proc")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	rs := rss[0]
+	defer terror.Call(rs.Close)
+
+	routines := make(map[string]*RoutineInfo)
+	for {
+		row, err := rs.Next(goctx.TODO())
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if row == nil {
+			break
+		}
+		tp := ast.RoutineProcedure
+		if row.GetEnum(2).String() == ast.RoutineFunction.String() {
+			tp = ast.RoutineFunction
+		}
+		info := &RoutineInfo{DB: row.GetString(0), Definition: row.GetString(3), SQLMode: row.GetString(4), Definer: row.GetString(5)}
+		routines[routineKey(tp, info.DB, row.GetString(1))] = info
+	}
+	do.routines.Lock()
+	do.routines.routines = routines
+	do.routines.Unlock()
+	return nil
+}
+
+// LoadRoutineLoop loads the stored routines, and starts a goroutine to reload them in a loop.
+// It should be called only once in BootstrapSession.
+func (do *Domain) LoadRoutineLoop(ctx context.Context) error {
+	ctx.GetSessionVars().InRestrictedSQL = true
+	err := do.reloadRoutines(ctx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	var watchCh clientv3.WatchChan
+	duration := 5 * time.Minute
+	if do.etcdClient != nil {
+		watchCh = do.etcdClient.Watch(goctx.Background(), routineEtcdKey)
+		duration = 10 * time.Minute
+	}
+
+	go func() {
+		var count int
+		for {
+			ok := true
+			select {
+			case <-do.exit:
+				return
+			case _, ok = <-watchCh:
+			case <-time.After(duration):
+			}
+			if !ok {
+				log.Error("[domain] load routine loop watch channel closed.")
+				watchCh = do.etcdClient.Watch(goctx.Background(), routineEtcdKey)
+				count++
+				if count > 10 {
+					time.Sleep(time.Duration(count) * time.Second)
+				}
+				continue
+			}
+
+			count = 0
+			err := do.reloadRoutines(ctx)
+			if err != nil {
+				log.Error("[domain] load routine fail:", errors.ErrorStack(err))
+			} else {
+				log.Info("[domain] reload routine success.")
+			}
+		}
+	}()
+	return nil
+}
+
+const routineEtcdKey = "/tidb/routine"
+
+// NotifyUpdateRoutine updates routine key in etcd, TiDB client that watches
+// the key will get notification.
+func (do *Domain) NotifyUpdateRoutine(ctx context.Context) {
+	if do.etcdClient != nil {
+		kv := do.etcdClient.KV
+		_, err := kv.Put(goctx.Background(), routineEtcdKey, "")
+		if err != nil {
+			log.Warn("notify update routine failed:", err)
+		}
+	}
+}
@@ -391,6 +391,11 @@ func IsPointGetWithPKOrUniqueKeyByAutoCommit(ctx context.Context, p plan.Plan) b
 		return false
 	}
 
+	// the stored functions may write in the transaction
+	if ctx.GetSessionVars().StmtCtx.CallsStoredFunction {
+		return false
+	}
+
 	// check txn
 	if ctx.Txn() != nil {
 		return false
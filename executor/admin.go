@@ -0,0 +1,488 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"math"
+	"sync"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/admin"
+	"github.com/pingcap/tidb/util/chunk"
+	goctx "golang.org/x/net/context"
+)
+
+var (
+	_ Executor = &CheckIndexExec{}
+	_ Executor = &RecoverIndexExec{}
+	_ Executor = &CleanupIndexExec{}
+)
+
+const (
+	// checkIndexBatchSize is the number of records or index entries checked by a worker at a time.
+	checkIndexBatchSize = 1024
+	// fixIndexBatchSize is the number of index entries fixed in a transaction.
+	fixIndexBatchSize = 256
+)
+
+// getTableIndex returns the table and its public index named indexName.
+func getTableIndex(is infoschema.InfoSchema, tn *ast.TableName, indexName string) (table.Table, table.Index, error) {
+	t, err := is.TableByName(tn.Schema, tn.Name)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	for _, idx := range t.Indices() {
+		if idx.Meta().Name.L == model.NewCIStr(indexName).L && idx.Meta().State == model.StatePublic {
+			return t, idx, nil
+		}
+	}
+	return nil, nil, plan.ErrKeyDoesNotExist.GenByArgs(indexName, tn.Name.O)
+}
+
+// CheckIndexExec represents a check index executor.
+// It is built from the "admin check index" statement, and it checks if the
+// index matches the records whose handles are in the handle ranges. The
+// records and the index entries are scanned at the start timestamp of the
+// transaction, and they are checked by several workers concurrently.
+type CheckIndexExec struct {
+	baseExecutor
+
+	table        *ast.TableName
+	indexName    string
+	handleRanges []ast.HandleRange
+	is           infoschema.InfoSchema
+	done         bool
+}
+
+// checkIndexTask is a batch of records to check against the index, or a batch
+// of index entries to check against the records.
+type checkIndexTask struct {
+	records []*admin.RecordData
+	entries []*admin.RecordData
+}
+
+// Open implements the Executor Open interface.
+func (e *CheckIndexExec) Open(goCtx goctx.Context) error {
+	if err := e.baseExecutor.Open(goCtx); err != nil {
+		return errors.Trace(err)
+	}
+	e.done = false
+	return nil
+}
+
+// Next implements the Executor Next interface.
+func (e *CheckIndexExec) Next(goCtx goctx.Context) (Row, error) {
+	if e.done {
+		return nil, nil
+	}
+	err := e.run(goCtx)
+	e.done = true
+	return nil, errors.Trace(err)
+}
+
+// NextChunk implements the Executor NextChunk interface.
+func (e *CheckIndexExec) NextChunk(goCtx goctx.Context, chk *chunk.Chunk) error {
+	chk.Reset()
+	if e.done {
+		return nil
+	}
+	err := e.run(goCtx)
+	e.done = true
+	return errors.Trace(err)
+}
+
+func (e *CheckIndexExec) run(goCtx goctx.Context) error {
+	t, idx, err := getTableIndex(e.is, e.table, e.indexName)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	ranges := e.handleRanges
+	if len(ranges) == 0 {
+		ranges = []ast.HandleRange{{Begin: math.MinInt64, End: math.MaxInt64}}
+	}
+	store := e.ctx.GetStore()
+	startTS := e.ctx.Txn().StartTS()
+	snapshot, err := store.GetSnapshot(kv.NewVersion(startTS))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	cols := admin.IndexColumns(t, idx)
+
+	goCtx, cancel := goctx.WithCancel(goCtx)
+	defer cancel()
+	var (
+		mu       sync.Mutex
+		firstErr error
+	)
+	setErr := func(err error) {
+		mu.Lock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+		mu.Unlock()
+	}
+
+	taskCh := make(chan *checkIndexTask, e.ctx.GetSessionVars().IndexLookupConcurrency)
+	producers := &sync.WaitGroup{}
+	producers.Add(2)
+	go func() {
+		defer producers.Done()
+		if err := e.produceRecordTasks(goCtx, snapshot, t, cols, ranges, taskCh); err != nil {
+			setErr(err)
+		}
+	}()
+	go func() {
+		defer producers.Done()
+		if err := e.produceIndexTasks(goCtx, snapshot, idx, ranges, taskCh); err != nil {
+			setErr(err)
+		}
+	}()
+	go func() {
+		producers.Wait()
+		close(taskCh)
+	}()
+
+	workers := &sync.WaitGroup{}
+	for i := 0; i < e.ctx.GetSessionVars().IndexLookupConcurrency; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			if err := checkIndexWorker(goCtx, store, startTS, t, idx, cols, taskCh); err != nil {
+				setErr(err)
+			}
+		}()
+	}
+	workers.Wait()
+
+	if firstErr != nil {
+		return errors.Errorf("%v err:%v", e.table.Name, firstErr)
+	}
+	return nil
+}
+
+// produceRecordTasks scans the records in the handle ranges and sends them to the workers in batches.
+func (e *CheckIndexExec) produceRecordTasks(goCtx goctx.Context, snapshot kv.Snapshot, t table.Table,
+	cols []*table.Column, ranges []ast.HandleRange, taskCh chan<- *checkIndexTask) error {
+	var records []*admin.RecordData
+	send := func() bool {
+		select {
+		case taskCh <- &checkIndexTask{records: records}:
+			records = nil
+			return true
+		case <-goCtx.Done():
+			return false
+		}
+	}
+	for _, ran := range ranges {
+		err := admin.IterRecordsInRange(snapshot, t, cols, ran.Begin, ran.End, func(r *admin.RecordData) (bool, error) {
+			records = append(records, r)
+			if len(records) < checkIndexBatchSize {
+				return true, nil
+			}
+			return send(), nil
+		})
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if len(records) > 0 {
+		send()
+	}
+	return nil
+}
+
+// produceIndexTasks scans the index entries whose handles are in the handle
+// ranges and sends them to the workers in batches.
+func (e *CheckIndexExec) produceIndexTasks(goCtx goctx.Context, snapshot kv.Snapshot, idx table.Index,
+	ranges []ast.HandleRange, taskCh chan<- *checkIndexTask) error {
+	var entries []*admin.RecordData
+	send := func() bool {
+		select {
+		case taskCh <- &checkIndexTask{entries: entries}:
+			entries = nil
+			return true
+		case <-goCtx.Done():
+			return false
+		}
+	}
+	err := admin.IterIndexEntries(snapshot, idx, func(entry *admin.RecordData) (bool, error) {
+		if !handleInRanges(entry.Handle, ranges) {
+			return true, nil
+		}
+		entries = append(entries, entry)
+		if len(entries) < checkIndexBatchSize {
+			return true, nil
+		}
+		return send(), nil
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if len(entries) > 0 {
+		send()
+	}
+	return nil
+}
+
+// checkIndexWorker checks the tasks from taskCh in its own transaction that
+// starts at startTS, until taskCh is closed or goCtx is done.
+func checkIndexWorker(goCtx goctx.Context, store kv.Storage, startTS uint64, t table.Table, idx table.Index,
+	cols []*table.Column, taskCh <-chan *checkIndexTask) error {
+	txn, err := store.BeginWithStartTS(startTS)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer func() {
+		terror.Log(errors.Trace(txn.Rollback()))
+	}()
+
+	for task := range taskCh {
+		if goCtx.Err() != nil {
+			// Drain the channel so that the producers are not blocked.
+			continue
+		}
+		for _, r := range task.records {
+			if err = admin.CheckRecordIndex(txn, idx, r); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		for _, entry := range task.entries {
+			if err = admin.CheckIndexRecord(txn, t, cols, entry); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+	return nil
+}
+
+func handleInRanges(h int64, ranges []ast.HandleRange) bool {
+	for _, ran := range ranges {
+		if h >= ran.Begin && h <= ran.End {
+			return true
+		}
+	}
+	return false
+}
+
+// RecoverIndexExec represents a recover index executor.
+// It is built from the "admin recover index" statement, and it adds the
+// missing index entries of the records when it's opened, like
+// ChecksumTableExec. The result is the number of added entries and the number
+// of scanned records.
+type RecoverIndexExec struct {
+	baseExecutor
+
+	table     *ast.TableName
+	indexName string
+	is        infoschema.InfoSchema
+	added     int64
+	scanned   int64
+	done      bool
+}
+
+// Open implements the Executor Open interface.
+func (e *RecoverIndexExec) Open(goCtx goctx.Context) error {
+	if err := e.baseExecutor.Open(goCtx); err != nil {
+		return errors.Trace(err)
+	}
+	var err error
+	e.added, e.scanned, err = e.run()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	e.done = false
+	return nil
+}
+
+// Next implements the Executor Next interface.
+func (e *RecoverIndexExec) Next(goCtx goctx.Context) (Row, error) {
+	if e.done {
+		return nil, nil
+	}
+	e.done = true
+	return types.MakeDatums(e.added, e.scanned), nil
+}
+
+// NextChunk implements the Executor NextChunk interface.
+func (e *RecoverIndexExec) NextChunk(goCtx goctx.Context, chk *chunk.Chunk) error {
+	chk.Reset()
+	if e.done {
+		return nil
+	}
+	e.done = true
+	chk.AppendInt64(0, e.added)
+	chk.AppendInt64(1, e.scanned)
+	return nil
+}
+
+func (e *RecoverIndexExec) run() (added, scanned int64, err error) {
+	t, idx, err := getTableIndex(e.is, e.table, e.indexName)
+	if err != nil {
+		return 0, 0, errors.Trace(err)
+	}
+	cols := admin.IndexColumns(t, idx)
+	txn := e.ctx.Txn()
+	var missing []*admin.RecordData
+	flush := func() error {
+		n, err := fixIndexEntries(e.ctx.GetStore(), missing, func(txn kv.Transaction, r *admin.RecordData) (bool, error) {
+			return admin.RecoverRecordIndex(txn, t, idx, cols, r)
+		})
+		added += n
+		missing = missing[:0]
+		return errors.Trace(err)
+	}
+	err = admin.IterRecordsInRange(txn, t, cols, math.MinInt64, math.MaxInt64, func(r *admin.RecordData) (bool, error) {
+		scanned++
+		err := admin.CheckRecordIndex(txn, idx, r)
+		if err == nil {
+			return true, nil
+		}
+		if !admin.IsDataNotEqual(err) {
+			return false, errors.Trace(err)
+		}
+		missing = append(missing, r)
+		if len(missing) < fixIndexBatchSize {
+			return true, nil
+		}
+		return true, errors.Trace(flush())
+	})
+	if err != nil {
+		return 0, 0, errors.Trace(err)
+	}
+	if len(missing) > 0 {
+		if err = flush(); err != nil {
+			return 0, 0, errors.Trace(err)
+		}
+	}
+	return added, scanned, nil
+}
+
+// CleanupIndexExec represents a cleanup index executor.
+// It is built from the "admin cleanup index" statement, and it removes the
+// index entries that don't match any record when it's opened. The result is
+// the number of removed entries.
+type CleanupIndexExec struct {
+	baseExecutor
+
+	table     *ast.TableName
+	indexName string
+	is        infoschema.InfoSchema
+	removed   int64
+	done      bool
+}
+
+// Open implements the Executor Open interface.
+func (e *CleanupIndexExec) Open(goCtx goctx.Context) error {
+	if err := e.baseExecutor.Open(goCtx); err != nil {
+		return errors.Trace(err)
+	}
+	var err error
+	e.removed, err = e.run()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	e.done = false
+	return nil
+}
+
+// Next implements the Executor Next interface.
+func (e *CleanupIndexExec) Next(goCtx goctx.Context) (Row, error) {
+	if e.done {
+		return nil, nil
+	}
+	e.done = true
+	return types.MakeDatums(e.removed), nil
+}
+
+// NextChunk implements the Executor NextChunk interface.
+func (e *CleanupIndexExec) NextChunk(goCtx goctx.Context, chk *chunk.Chunk) error {
+	chk.Reset()
+	if e.done {
+		return nil
+	}
+	e.done = true
+	chk.AppendInt64(0, e.removed)
+	return nil
+}
+
+func (e *CleanupIndexExec) run() (removed int64, err error) {
+	t, idx, err := getTableIndex(e.is, e.table, e.indexName)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	cols := admin.IndexColumns(t, idx)
+	txn := e.ctx.Txn()
+	var dangling []*admin.RecordData
+	flush := func() error {
+		n, err := fixIndexEntries(e.ctx.GetStore(), dangling, func(txn kv.Transaction, entry *admin.RecordData) (bool, error) {
+			return admin.CleanupIndexEntry(txn, t, idx, cols, entry)
+		})
+		removed += n
+		dangling = dangling[:0]
+		return errors.Trace(err)
+	}
+	err = admin.IterIndexEntries(txn, idx, func(entry *admin.RecordData) (bool, error) {
+		err := admin.CheckIndexRecord(txn, t, cols, entry)
+		if err == nil {
+			return true, nil
+		}
+		if !admin.IsDataNotEqual(err) {
+			return false, errors.Trace(err)
+		}
+		dangling = append(dangling, entry)
+		if len(dangling) < fixIndexBatchSize {
+			return true, nil
+		}
+		return true, errors.Trace(flush())
+	})
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	if len(dangling) > 0 {
+		if err = flush(); err != nil {
+			return 0, errors.Trace(err)
+		}
+	}
+	return removed, nil
+}
+
+// fixIndexEntries calls fix with each of the data in a new transaction, and
+// returns the number of the data that fix returns true.
+func fixIndexEntries(store kv.Storage, data []*admin.RecordData,
+	fix func(txn kv.Transaction, r *admin.RecordData) (bool, error)) (int64, error) {
+	var fixed int64
+	err := kv.RunInNewTxn(store, true, func(txn kv.Transaction) error {
+		fixed = 0
+		for _, r := range data {
+			ok, err := fix(txn, r)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if ok {
+				fixed++
+			}
+		}
+		return nil
+	})
+	return fixed, errors.Trace(err)
+}
@@ -99,7 +99,7 @@ func (e *HashAggExec) getGroupKey(row Row) ([]byte, error) {
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
-		vals = append(vals, v)
+		vals = append(vals, expression.CollationKeyDatum(v, item.GetType()))
 	}
 	bs, err := codec.EncodeValue([]byte{}, vals...)
 	if err != nil {
@@ -241,6 +241,7 @@ func (e *StreamAggExec) meetNewGroup(row Row) (bool, error) {
 		if err != nil {
 			return false, errors.Trace(err)
 		}
+		v = expression.CollationKeyDatum(v, item.GetType())
 		if matched {
 			c, err := v.CompareDatum(e.StmtCtx, &e.curGroupKey[i])
 			if err != nil {
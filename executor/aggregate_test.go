@@ -15,9 +15,12 @@ package executor_test
 
 import (
 	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb"
 	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/types/json"
 	"github.com/pingcap/tidb/util/testkit"
+	goctx "golang.org/x/net/context"
 )
 
 func (s *testSuite) TestAggregation(c *C) {
@@ -239,7 +242,7 @@ func (s *testSuite) TestAggregation(c *C) {
 
 	result = tk.MustQuery("select count(*) from information_schema.columns")
 	// When adding new memory table in information_schema, please update this variable.
-	columnCountOfAllInformationSchemaTables := "743"
+	columnCountOfAllInformationSchemaTables := "781"
 	result.Check(testkit.Rows(columnCountOfAllInformationSchemaTables))
 
 	tk.MustExec("drop table if exists t1")
@@ -337,6 +340,27 @@ func (s *testSuite) TestGroupConcatAggr(c *C) {
 	result.Check(testkit.Rows("1 102030", "2 20", "3 200500"))
 }
 
+func (s *testSuite) TestJSONAggr(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t(id int, k varchar(10), v int)")
+	tk.MustExec(`insert into t values(1, "a", 10), (1, "b", 20), (1, "a", 30), (2, "c", null), (3, null, 1)`)
+	result := tk.MustQuery("select id, json_arrayagg(v) from t where id < 3 group by id order by id")
+	result.Check(testkit.Rows("1 [10,20,30]", "2 [null]"))
+	result = tk.MustQuery("select id, json_objectagg(k, v) from t where id < 3 group by id order by id")
+	result.Check(testkit.Rows(`1 {"a":30,"b":20}`, `2 {"c":null}`))
+	result = tk.MustQuery("select json_arrayagg(k), json_objectagg(k, v) from t where id > 5")
+	result.Check(testkit.Rows("<nil> <nil>"))
+	result = tk.MustQuery(`select json_arrayagg(json_object("k", k)) from t where id = 2`)
+	result.Check(testkit.Rows(`[{"k":"c"}]`))
+	rs, err := tk.Exec("select json_objectagg(k, v) from t")
+	c.Assert(err, IsNil)
+	_, err = tidb.GetRows4Test(goctx.Background(), rs)
+	c.Assert(terror.ErrorEqual(err, json.ErrJSONDocumentNULLKey), IsTrue)
+	c.Assert(rs.Close(), IsNil)
+}
+
 func (s *testSuite) TestSelectDistinct(c *C) {
 	tk := testkit.NewTestKit(c, s.store)
 	tk.MustExec("use test")
@@ -66,6 +66,14 @@ func (b *executorBuilder) build(p plan.Plan) Executor {
 		return nil
 	case *plan.CheckTable:
 		return b.buildCheckTable(v)
+	case *plan.ChecksumTable:
+		return b.buildChecksumTable(v)
+	case *plan.CheckIndex:
+		return b.buildCheckIndex(v)
+	case *plan.RecoverIndex:
+		return b.buildRecoverIndex(v)
+	case *plan.CleanupIndex:
+		return b.buildCleanupIndex(v)
 	case *plan.DDL:
 		return b.buildDDL(v)
 	case *plan.Deallocate:
@@ -80,6 +88,8 @@ func (b *executorBuilder) build(p plan.Plan) Executor {
 		return b.buildInsert(v)
 	case *plan.LoadData:
 		return b.buildLoadData(v)
+	case *plan.SelectInto:
+		return b.buildSelectInto(v)
 	case *plan.PhysicalLimit:
 		return b.buildLimit(v)
 	case *plan.Prepare:
@@ -207,6 +217,49 @@ func (b *executorBuilder) buildCheckTable(v *plan.CheckTable) Executor {
 	return e
 }
 
+func (b *executorBuilder) buildChecksumTable(v *plan.ChecksumTable) Executor {
+	e := &ChecksumTableExec{
+		baseExecutor: newBaseExecutor(v.Schema(), b.ctx),
+		tables:       v.Tables,
+	}
+	e.supportChk = true
+	return e
+}
+
+func (b *executorBuilder) buildCheckIndex(v *plan.CheckIndex) Executor {
+	e := &CheckIndexExec{
+		baseExecutor: newBaseExecutor(v.Schema(), b.ctx),
+		table:        v.Table,
+		indexName:    v.IndexName,
+		handleRanges: v.HandleRanges,
+		is:           b.is,
+	}
+	e.supportChk = true
+	return e
+}
+
+func (b *executorBuilder) buildRecoverIndex(v *plan.RecoverIndex) Executor {
+	e := &RecoverIndexExec{
+		baseExecutor: newBaseExecutor(v.Schema(), b.ctx),
+		table:        v.Table,
+		indexName:    v.IndexName,
+		is:           b.is,
+	}
+	e.supportChk = true
+	return e
+}
+
+func (b *executorBuilder) buildCleanupIndex(v *plan.CleanupIndex) Executor {
+	e := &CleanupIndexExec{
+		baseExecutor: newBaseExecutor(v.Schema(), b.ctx),
+		table:        v.Table,
+		indexName:    v.IndexName,
+		is:           b.is,
+	}
+	e.supportChk = true
+	return e
+}
+
 func (b *executorBuilder) buildDeallocate(v *plan.Deallocate) Executor {
 	e := &DeallocateExec{
 		baseExecutor: newBaseExecutor(nil, b.ctx),
@@ -385,18 +438,39 @@ func (b *executorBuilder) buildLoadData(v *plan.LoadData) Executor {
 		b.err = errors.Trace(err)
 		return nil
 	}
+	fieldCount := len(columns) - hiddenColumnCount(columns)
+	if len(v.ColumnsAndUserVars) > 0 && len(v.Columns) == 0 {
+		// All the fields are read into user variables, only the hidden columns are left.
+		columns = columns[fieldCount:]
+		fieldCount = 0
+	}
+	if len(v.ColumnAssignments) > 0 {
+		// The assigned columns are put after the columns of the fields.
+		cols := make([]*table.Column, 0, len(columns)+len(v.ColumnAssignments))
+		cols = append(cols, columns[:fieldCount]...)
+		for _, assign := range v.ColumnAssignments {
+			cols = append(cols, table.FindCol(tableCols, assign.Col.ColName.L))
+		}
+		columns = append(cols, columns[fieldCount:]...)
+	}
 	loadDataExec := &LoadData{
 		baseExecutor: newBaseExecutor(nil, b.ctx),
 		IsLocal:      v.IsLocal,
 		loadDataInfo: &LoadDataInfo{
-			row:        make([]types.Datum, len(columns)),
-			insertVal:  insertVal,
-			Path:       v.Path,
-			Table:      tbl,
-			FieldsInfo: v.FieldsInfo,
-			LinesInfo:  v.LinesInfo,
-			Ctx:        b.ctx,
-			columns:    columns,
+			row:                make([]types.Datum, fieldCount),
+			insertVal:          insertVal,
+			Path:               v.Path,
+			Table:              tbl,
+			OnDuplicate:        v.OnDuplicate,
+			ColumnsAndUserVars: v.ColumnsAndUserVars,
+			ColumnAssignments:  v.ColumnAssignments,
+			FieldsInfo:         v.FieldsInfo,
+			LinesInfo:          v.LinesInfo,
+			IgnoreLines:        v.IgnoreLines,
+			Ctx:                b.ctx,
+			columns:            columns,
+			// LOCAL can't stop the client from sending the file, so the errors are always warnings like IGNORE.
+			abortOnErr: !v.IsLocal && v.OnDuplicate == ast.OnDuplicateKeyHandlingError,
 		},
 	}
 
@@ -404,6 +478,20 @@ func (b *executorBuilder) buildLoadData(v *plan.LoadData) Executor {
 	return loadDataExec
 }
 
+func (b *executorBuilder) buildSelectInto(v *plan.SelectInto) Executor {
+	childExec := b.build(v.TargetPlan)
+	if b.err != nil {
+		b.err = errors.Trace(b.err)
+		return nil
+	}
+	e := &SelectIntoExec{
+		baseExecutor: newBaseExecutor(v.Schema(), b.ctx, childExec),
+		intoOpt:      v.IntoOpt,
+	}
+	e.supportChk = true
+	return e
+}
+
 func (b *executorBuilder) buildReplace(vals *InsertValues) Executor {
 	replaceExec := &ReplaceExec{
 		InsertValues: vals,
@@ -445,6 +533,13 @@ func (b *executorBuilder) buildDDL(v *plan.DDL) Executor {
 		stmt:         v.Statement,
 		is:           b.is,
 	}
+	if v.SelectPlan != nil {
+		e.selectExec = b.build(v.SelectPlan)
+		if b.err != nil {
+			b.err = errors.Trace(b.err)
+			return nil
+		}
+	}
 	e.supportChk = true
 	return e
 }
@@ -0,0 +1,163 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/distsql"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/chunk"
+	goctx "golang.org/x/net/context"
+)
+
+var _ Executor = &ChecksumTableExec{}
+
+// ChecksumTableExec represents ChecksumTable executor.
+// It is built from the "admin checksum table" statement, and it calculates
+// the checksum of the records and indices of the tables at the start
+// timestamp of the transaction, so the checksums of all the tables are
+// calculated on a consistent snapshot.
+type ChecksumTableExec struct {
+	baseExecutor
+
+	tables  []*ast.TableName
+	results []*distsql.ChecksumResponse
+	cursor  int
+}
+
+// Open implements the Executor Open interface.
+func (e *ChecksumTableExec) Open(goCtx goctx.Context) error {
+	if err := e.baseExecutor.Open(goCtx); err != nil {
+		return errors.Trace(err)
+	}
+	e.results = make([]*distsql.ChecksumResponse, 0, len(e.tables))
+	for _, t := range e.tables {
+		result, err := e.checksumTable(goCtx, t.TableInfo)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		e.results = append(e.results, result)
+	}
+	e.cursor = 0
+	return nil
+}
+
+// Next implements the Executor Next interface.
+func (e *ChecksumTableExec) Next(goCtx goctx.Context) (Row, error) {
+	if e.cursor >= len(e.results) {
+		return nil, nil
+	}
+	t, result := e.tables[e.cursor], e.results[e.cursor]
+	row := types.MakeDatums(t.Schema.O, t.Name.O, result.Checksum, result.TotalKvs, result.TotalBytes)
+	e.cursor++
+	return row, nil
+}
+
+// NextChunk implements the Executor NextChunk interface.
+func (e *ChecksumTableExec) NextChunk(goCtx goctx.Context, chk *chunk.Chunk) error {
+	chk.Reset()
+	for ; e.cursor < len(e.results) && chk.NumRows() < e.maxChunkSize; e.cursor++ {
+		t, result := e.tables[e.cursor], e.results[e.cursor]
+		chk.AppendString(0, t.Schema.O)
+		chk.AppendString(1, t.Name.O)
+		chk.AppendUint64(2, result.Checksum)
+		chk.AppendUint64(3, result.TotalKvs)
+		chk.AppendUint64(4, result.TotalBytes)
+	}
+	return nil
+}
+
+func (e *ChecksumTableExec) checksumTable(goCtx goctx.Context, tblInfo *model.TableInfo) (*distsql.ChecksumResponse, error) {
+	ranges := checksumKeyRanges(tblInfo)
+	startTS := e.ctx.Txn().StartTS()
+	client := e.ctx.GetClient()
+	if !client.IsRequestTypeSupported(kv.ReqTypeChecksum, 0) {
+		return e.checksumByScan(ranges, startTS)
+	}
+
+	var builder requestBuilder
+	kvReq, err := builder.SetKeyRanges(ranges).
+		SetChecksumRequest(&distsql.ChecksumRequest{StartTs: startTS, Algorithm: distsql.ChecksumCrc64Xor}).
+		SetFromSessionVars(e.ctx.GetSessionVars()).
+		Build()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	kvReq.IsolationLevel = kv.SI
+	result, err := distsql.Checksum(goCtx, client, kvReq)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	result.Fetch(goCtx)
+	defer terror.Call(result.Close)
+
+	checksum := &distsql.ChecksumResponse{}
+	for {
+		data, err := result.NextRaw()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if data == nil {
+			break
+		}
+		resp := &distsql.ChecksumResponse{}
+		if err = resp.Unmarshal(data); err != nil {
+			return nil, errors.Trace(err)
+		}
+		checksum.Merge(resp)
+	}
+	return checksum, nil
+}
+
+// checksumByScan calculates the checksum in TiDB, it's used when the storage
+// does not support checksum requests.
+func (e *ChecksumTableExec) checksumByScan(ranges []kv.KeyRange, startTS uint64) (*distsql.ChecksumResponse, error) {
+	snapshot, err := e.ctx.GetStore().GetSnapshot(kv.NewVersion(startTS))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	checksum := &distsql.ChecksumResponse{}
+	for _, ran := range ranges {
+		it, err := snapshot.Seek(ran.StartKey)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		for it.Valid() && it.Key().Cmp(ran.EndKey) < 0 {
+			checksum.Update(it.Key(), it.Value())
+			if err = it.Next(); err != nil {
+				it.Close()
+				return nil, errors.Trace(err)
+			}
+		}
+		it.Close()
+	}
+	return checksum, nil
+}
+
+// checksumKeyRanges returns the key ranges of the records and the indices of
+// the table.
+func checksumKeyRanges(tblInfo *model.TableInfo) []kv.KeyRange {
+	recordPrefix := tablecodec.GenTableRecordPrefix(tblInfo.ID)
+	ranges := []kv.KeyRange{{StartKey: recordPrefix, EndKey: recordPrefix.PrefixNext()}}
+	for _, idx := range tblInfo.Indices {
+		indexPrefix := tablecodec.EncodeTableIndexPrefix(tblInfo.ID, idx.ID)
+		ranges = append(ranges, kv.KeyRange{StartKey: indexPrefix, EndKey: indexPrefix.PrefixNext()})
+	}
+	return ranges
+}
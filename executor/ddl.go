@@ -14,18 +14,27 @@
 package executor
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/ddl/util"
 	"github.com/pingcap/tidb/domain"
+	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/sessionctx/varsutil"
+	"github.com/pingcap/tidb/store/tikv/oracle"
+	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/sqlexec"
 	goctx "golang.org/x/net/context"
 )
 
@@ -37,6 +46,9 @@ type DDLExec struct {
 	stmt ast.StmtNode
 	is   infoschema.InfoSchema
 	done bool
+
+	// selectExec is the executor of the select in CREATE TABLE ... SELECT.
+	selectExec Executor
 }
 
 // Next implements Execution Next interface.
@@ -65,8 +77,10 @@ func (e *DDLExec) run(goCtx goctx.Context) (err error) {
 		err = e.executeTruncateTable(x)
 	case *ast.CreateDatabaseStmt:
 		err = e.executeCreateDatabase(x)
+	case *ast.AlterDatabaseStmt:
+		err = e.executeAlterDatabase(x)
 	case *ast.CreateTableStmt:
-		err = e.executeCreateTable(x)
+		err = e.executeCreateTable(goCtx, x)
 	case *ast.CreateIndexStmt:
 		err = e.executeCreateIndex(x)
 	case *ast.DropDatabaseStmt:
@@ -79,6 +93,8 @@ func (e *DDLExec) run(goCtx goctx.Context) (err error) {
 		err = e.executeAlterTable(x)
 	case *ast.RenameTableStmt:
 		err = e.executeRenameTable(x)
+	case *ast.RecoverTableStmt:
+		err = e.executeRecoverTable(x)
 	}
 	if err != nil {
 		return errors.Trace(err)
@@ -102,9 +118,15 @@ func (e *DDLExec) executeTruncateTable(s *ast.TruncateTableStmt) error {
 }
 
 func (e *DDLExec) executeRenameTable(s *ast.RenameTableStmt) error {
-	if len(s.TableToTables) != 1 {
-		// Now we only allow one schema changing at the same time.
-		return errors.Errorf("can't run multi schema change")
+	if len(s.TableToTables) > 1 {
+		oldIdents := make([]ast.Ident, 0, len(s.TableToTables))
+		newIdents := make([]ast.Ident, 0, len(s.TableToTables))
+		for _, tables := range s.TableToTables {
+			oldIdents = append(oldIdents, ast.Ident{Schema: tables.OldTable.Schema, Name: tables.OldTable.Name})
+			newIdents = append(newIdents, ast.Ident{Schema: tables.NewTable.Schema, Name: tables.NewTable.Name})
+		}
+		err := domain.GetDomain(e.ctx).DDL().RenameTables(e.ctx, oldIdents, newIdents)
+		return errors.Trace(err)
 	}
 	oldIdent := ast.Ident{Schema: s.OldTable.Schema, Name: s.OldTable.Name}
 	newIdent := ast.Ident{Schema: s.NewTable.Schema, Name: s.NewTable.Name}
@@ -134,10 +156,26 @@ func (e *DDLExec) executeCreateDatabase(s *ast.CreateDatabaseStmt) error {
 	return errors.Trace(err)
 }
 
-func (e *DDLExec) executeCreateTable(s *ast.CreateTableStmt) error {
+func (e *DDLExec) executeAlterDatabase(s *ast.AlterDatabaseStmt) error {
+	opt := &ast.CharsetOpt{}
+	for _, val := range s.Options {
+		switch val.Tp {
+		case ast.DatabaseOptionCharset:
+			opt.Chs = val.Value
+		case ast.DatabaseOptionCollate:
+			opt.Col = val.Value
+		}
+	}
+	err := domain.GetDomain(e.ctx).DDL().AlterSchema(e.ctx, model.NewCIStr(s.Name), opt)
+	return errors.Trace(err)
+}
+
+func (e *DDLExec) executeCreateTable(goCtx goctx.Context, s *ast.CreateTableStmt) error {
 	ident := ast.Ident{Schema: s.Table.Schema, Name: s.Table.Name}
 	var err error
-	if s.ReferTable == nil {
+	if s.Select != nil {
+		err = e.executeCreateTableWithSelect(goCtx, ident, s)
+	} else if s.ReferTable == nil {
 		err = domain.GetDomain(e.ctx).DDL().CreateTable(e.ctx, ident, s.Cols, s.Constraints, s.Options)
 	} else {
 		referIdent := ast.Ident{Schema: s.ReferTable.Schema, Name: s.ReferTable.Name}
@@ -152,6 +190,104 @@ func (e *DDLExec) executeCreateTable(s *ast.CreateTableStmt) error {
 	return errors.Trace(err)
 }
 
+// executeCreateTableWithSelect creates the table of CREATE TABLE ... SELECT,
+// the rows of the select are inserted into it in batches, so they don't have
+// to be committed in one transaction.
+func (e *DDLExec) executeCreateTableWithSelect(goCtx goctx.Context, ident ast.Ident, s *ast.CreateTableStmt) error {
+	for _, colDef := range s.Cols {
+		for _, option := range colDef.Options {
+			if option.Tp == ast.ColumnOptionGenerated {
+				return errors.Errorf("can't create a table with generated columns by CREATE TABLE ... SELECT")
+			}
+		}
+	}
+	colDefs, insertCols := buildColumnDefsFromSelect(s.Cols, e.selectExec.Schema())
+	fill := func(tbl table.Table) error {
+		// The new table is created in the latest schema version, update it in TxnCtx, so the
+		// transactions of the batches will pass schema check.
+		is := domain.GetDomain(e.ctx).InfoSchema()
+		txnCtx := e.ctx.GetSessionVars().TxnCtx
+		txnCtx.InfoSchema = is
+		txnCtx.SchemaVersion = is.SchemaMetaVersion()
+
+		insert := &InsertExec{
+			InsertValues: &InsertValues{
+				baseExecutor: newBaseExecutor(nil, e.ctx, e.selectExec),
+				SelectExec:   e.selectExec,
+				Table:        tbl,
+				Columns:      insertCols,
+			},
+			forceBatch: true,
+		}
+		insert.supportChk = true
+		if err := insert.Open(goCtx); err != nil {
+			return errors.Trace(err)
+		}
+		var err error
+		if e.ctx.GetSessionVars().EnableChunk && insert.supportChunk() {
+			err = insert.NextChunk(goCtx, insert.newChunk())
+		} else {
+			_, err = insert.Next(goCtx)
+		}
+		if err1 := insert.Close(); err == nil {
+			err = err1
+		}
+		if err != nil {
+			return errors.Trace(err)
+		}
+		// Commit the last batch before the table becomes public.
+		return errors.Trace(e.ctx.NewTxn())
+	}
+	err := domain.GetDomain(e.ctx).DDL().CreateTableWithSelect(e.ctx, ident, colDefs, s.Constraints, s.Options, fill)
+	return errors.Trace(err)
+}
+
+// buildColumnDefsFromSelect returns the column definitions of the table of
+// CREATE TABLE ... SELECT and the columns which the rows of the select are
+// inserted into. The columns of the select which aren't defined explicitly
+// follow the defined columns, and their types are derived from the select.
+func buildColumnDefsFromSelect(colDefs []*ast.ColumnDef, schema *expression.Schema) ([]*ast.ColumnDef, []*ast.ColumnName) {
+	defined := make(map[string]struct{}, len(colDefs))
+	for _, colDef := range colDefs {
+		defined[colDef.Name.Name.L] = struct{}{}
+	}
+	newColDefs := make([]*ast.ColumnDef, 0, len(colDefs)+schema.Len())
+	newColDefs = append(newColDefs, colDefs...)
+	insertCols := make([]*ast.ColumnName, 0, schema.Len())
+	for _, col := range schema.Columns {
+		name := &ast.ColumnName{Name: col.ColName}
+		insertCols = append(insertCols, name)
+		if _, ok := defined[col.ColName.L]; ok {
+			continue
+		}
+		newColDefs = append(newColDefs, buildColumnDefFromSelect(name, col.RetType))
+	}
+	return newColDefs, insertCols
+}
+
+func buildColumnDefFromSelect(name *ast.ColumnName, retType *types.FieldType) *ast.ColumnDef {
+	tp := *retType
+	// Only keep the flags which are a part of the type, the nullability is set by the options.
+	tp.Flag &= mysql.UnsignedFlag | mysql.ZerofillFlag | mysql.BinaryFlag
+	switch tp.Tp {
+	case mysql.TypeNull:
+		// The column of NULL is BINARY(0), like MySQL.
+		tp = *types.NewFieldType(mysql.TypeString)
+		tp.Flen = 0
+		tp.Charset, tp.Collate = charset.CharsetBin, charset.CollationBin
+		tp.Flag |= mysql.BinaryFlag
+	case mysql.TypeVarchar, mysql.TypeVarString:
+		if tp.Flen == types.UnspecifiedLength {
+			tp.Tp = mysql.TypeLongBlob
+		}
+	}
+	option := &ast.ColumnOption{Tp: ast.ColumnOptionNull}
+	if mysql.HasNotNullFlag(retType.Flag) {
+		option.Tp = ast.ColumnOptionNotNull
+	}
+	return &ast.ColumnDef{Name: name, Tp: &tp, Options: []*ast.ColumnOption{option}}
+}
+
 func (e *DDLExec) executeCreateIndex(s *ast.CreateIndexStmt) error {
 	ident := ast.Ident{Schema: s.Table.Schema, Name: s.Table.Name}
 	err := domain.GetDomain(e.ctx).DDL().CreateIndex(e.ctx, ident, s.Unique, model.NewCIStr(s.IndexName), s.IndexColNames, s.IndexOption)
@@ -168,6 +304,16 @@ func (e *DDLExec) executeDropDatabase(s *ast.DropDatabaseStmt) error {
 			err = infoschema.ErrDatabaseDropExists.GenByArgs(s.Name)
 		}
 	}
+	if err == nil {
+		// The stored routines are dropped with the database.
+		sql := fmt.Sprintf("DELETE FROM %s.proc WHERE LOWER(db) = %s", mysql.SystemDB, quoteString(dbName.L))
+		if _, _, err = e.ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(e.ctx, sql); err != nil {
+			return errors.Trace(err)
+		}
+		dom := domain.GetDomain(e.ctx)
+		dom.DeleteRoutinesOfDB(dbName.L)
+		dom.NotifyUpdateRoutine(e.ctx)
+	}
 	sessionVars := e.ctx.GetSessionVars()
 	if err == nil && strings.ToLower(sessionVars.CurrentDB) == dbName.L {
 		sessionVars.CurrentDB = ""
@@ -194,15 +340,9 @@ func (e *DDLExec) executeDropTable(s *ast.DropTableStmt) error {
 			notExistTables = append(notExistTables, fullti.String())
 			continue
 		}
-		_, err := e.is.TableByName(tn.Schema, tn.Name)
-		if err != nil && infoschema.ErrTableNotExists.Equal(err) {
-			notExistTables = append(notExistTables, fullti.String())
-			continue
-		} else if err != nil {
-			return errors.Trace(err)
-		}
-
-		err = domain.GetDomain(e.ctx).DDL().DropTable(e.ctx, fullti)
+		// The table which isn't in the information schema may be the invisible table left by
+		// CREATE TABLE ... SELECT, DropTable checks whether the table exists.
+		err := domain.GetDomain(e.ctx).DDL().DropTable(e.ctx, fullti)
 		if infoschema.ErrDatabaseNotExists.Equal(err) || infoschema.ErrTableNotExists.Equal(err) {
 			notExistTables = append(notExistTables, fullti.String())
 		} else if err != nil {
@@ -229,3 +369,84 @@ func (e *DDLExec) executeAlterTable(s *ast.AlterTableStmt) error {
 	err := domain.GetDomain(e.ctx).DDL().AlterTable(e.ctx, ti, s.Specs)
 	return errors.Trace(err)
 }
+
+// executeRecoverTable recovers the table dropped or truncated by the latest DDL job on it. The data of the table
+// is kept until GC deletes the ranges in gc_delete_range table, so it can be recovered before that happens.
+func (e *DDLExec) executeRecoverTable(s *ast.RecoverTableStmt) error {
+	store := e.ctx.GetStore()
+	if !store.SupportDeleteRange() {
+		return errors.Errorf("recover table is not supported by the store, the data of dropped tables are deleted at once")
+	}
+	job, tblInfo, err := e.getDroppedTable(s.Table)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	// The table is dropped or truncated in a transaction that starts at LastUpdateTS.
+	dropTS := uint64(job.LastUpdateTS)
+	safePoint, err := util.LoadGCSafePoint(e.ctx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !safePoint.IsZero() && oracle.ComposeTS(oracle.GetPhysical(safePoint), 0) >= dropTS {
+		return errors.Errorf("can't recover table %s, it's dropped before the GC safe point %s", s.Table.Name, safePoint)
+	}
+	exists, err := util.DeleteRangeExists(e.ctx, job.ID, job.TableID)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !exists {
+		return errors.Errorf("can't recover table %s, its data has been deleted", s.Table.Name)
+	}
+
+	// Get the auto ID of the table before it's dropped.
+	snapshot, err := store.GetSnapshot(kv.NewVersion(dropTS))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	autoID, err := meta.NewSnapshotMeta(snapshot).GetAutoTableID(job.SchemaID, job.TableID)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	if s.NewName.L != "" {
+		tblInfo.Name = s.NewName
+	}
+	err = domain.GetDomain(e.ctx).DDL().RecoverTable(e.ctx, tblInfo, job.SchemaID, autoID, job.ID, dropTS)
+	return errors.Trace(err)
+}
+
+// getDroppedTable finds the latest DDL job that drops or truncates the table in DDL history, and returns
+// the job and the table info before the job.
+func (e *DDLExec) getDroppedTable(tn *ast.TableName) (*model.Job, *model.TableInfo, error) {
+	dbInfo, ok := e.is.SchemaByName(tn.Schema)
+	if !ok {
+		return nil, nil, infoschema.ErrDatabaseNotExists.GenByArgs(tn.Schema)
+	}
+	// Scan the history jobs one by one, the jobs are in the order of their IDs, so the last matched one is the latest.
+	var dropJob *model.Job
+	err := kv.RunInNewTxn(e.ctx.GetStore(), false, func(txn kv.Transaction) error {
+		return errors.Trace(meta.NewMeta(txn).IterateHistoryDDLJobs(func(job *model.Job) error {
+			if job.Type != model.ActionDropTable && job.Type != model.ActionTruncateTable {
+				return nil
+			}
+			if !job.IsSynced() || job.SchemaID != dbInfo.ID || job.BinlogInfo == nil || job.BinlogInfo.TableInfo == nil {
+				return nil
+			}
+			if job.BinlogInfo.TableInfo.Name.L == tn.Name.L {
+				dropJob = job
+			}
+			return nil
+		}))
+	})
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	if dropJob == nil {
+		return nil, nil, errors.Errorf("can't find dropped or truncated table %s in DDL history", tn.Name)
+	}
+	// For truncate table, the table info in the job is the new table.
+	tblInfo := dropJob.BinlogInfo.TableInfo.Clone()
+	tblInfo.ID = dropJob.TableID
+	return dropJob, tblInfo, nil
+}
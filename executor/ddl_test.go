@@ -16,10 +16,12 @@ package executor_test
 import (
 	"fmt"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/domain"
+	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/plan"
@@ -104,6 +106,69 @@ func (s *testSuite) TestCreateTable(c *C) {
 	r.Check(testkit.Rows("1000 aa"))
 }
 
+func (s *testSuite) TestCreateTableWithSelect(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists ctas_src, ctas_t")
+	tk.MustExec("create table ctas_src (a int not null, b varchar(10), c decimal(10, 2))")
+	tk.MustExec("insert into ctas_src values (1, 'a', 1.5), (2, 'b', 2.5), (3, null, null)")
+
+	// The types of the columns are derived from the select.
+	tk.MustExec("create table ctas_t select a, b, c, a + 1 as d, null as e from ctas_src")
+	tk.MustQuery("select * from ctas_t").Check(testkit.Rows("1 a 1.50 2 <nil>", "2 b 2.50 3 <nil>", "3 <nil> <nil> 4 <nil>"))
+	tk.MustQuery("show create table ctas_t").Check(testkit.Rows("ctas_t CREATE TABLE `ctas_t` (\n" +
+		"  `a` int(11) NOT NULL,\n" +
+		"  `b` varchar(10) DEFAULT NULL,\n" +
+		"  `c` decimal(10,2) DEFAULT NULL,\n" +
+		"  `d` bigint(20) DEFAULT NULL,\n" +
+		"  `e` binary(1) DEFAULT NULL\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin"))
+	tk.MustExec("create table if not exists ctas_t as select 100")
+	tk.MustQuery("select count(*) from ctas_t").Check(testkit.Rows("3"))
+	_, err := tk.Exec("create table ctas_t as select 100")
+	c.Assert(err, NotNil)
+
+	// The defined columns come first, the other columns of the select follow them.
+	tk.MustExec("drop table ctas_t")
+	tk.MustExec("create table ctas_t (id int auto_increment primary key, a bigint, unique key(b)) select a, b from ctas_src where a < 3 order by a")
+	tk.MustQuery("select * from ctas_t").Check(testkit.Rows("1 1 a", "2 2 b"))
+	tk.MustQuery("select b from ctas_t use index(b) where b = 'b'").Check(testkit.Rows("b"))
+
+	// The table is dropped if it fails to insert the rows.
+	tk.MustExec("drop table ctas_t")
+	tk.MustExec("insert into ctas_src values (4, 'a', 0)")
+	_, err = tk.Exec("create table ctas_t (unique key(b)) select b from ctas_src")
+	c.Assert(kv.ErrKeyExists.Equal(err), IsTrue, Commentf("err %v", err))
+	_, err = tk.Exec("select * from ctas_t")
+	c.Assert(err, NotNil)
+}
+
+func (s *testSuite) TestCreateTableWithSelectInBatches(c *C) {
+	originLimit := atomic.LoadUint64(&kv.TxnEntryCountLimit)
+	defer func() {
+		atomic.StoreUint64(&kv.TxnEntryCountLimit, originLimit)
+	}()
+	// Set the limitation to a small value, the rows can't be inserted in one transaction.
+	atomic.StoreUint64(&kv.TxnEntryCountLimit, 100)
+
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists ctas_src, ctas_t")
+	tk.MustExec("create table ctas_src (a int)")
+	for i := 0; i < 4; i++ {
+		tk.MustExec("insert into ctas_src values (1),(2),(3),(4),(5),(6),(7),(8),(9),(10),(11),(12),(13),(14),(15),(16),(17),(18),(19),(20)")
+	}
+	tk.MustExec("insert into ctas_src select * from ctas_src")
+	tk.MustExec("set @@session.tidb_dml_batch_size=50")
+	tk.MustExec("create table ctas_t select * from ctas_src")
+	tk.MustQuery("select count(*), sum(a) from ctas_t").Check(testkit.Rows("160 1680"))
+
+	// The rows of INSERT ... SELECT are inserted in batches too.
+	tk.MustExec("set @@session.tidb_batch_insert=1")
+	tk.MustExec("insert into ctas_t select * from ctas_t")
+	tk.MustQuery("select count(*), sum(a) from ctas_t").Check(testkit.Rows("320 3360"))
+}
+
 func (s *testSuite) TestCreateDropDatabase(c *C) {
 	tk := testkit.NewTestKit(c, s.store)
 	tk.MustExec("create database if not exists drop_test;")
@@ -183,11 +248,8 @@ func (s *testSuite) TestAlterTableModifyColumn(c *C) {
 	c.Assert(err, NotNil)
 	tk.MustExec("alter table mc modify column c1 bigint")
 
-	_, err = tk.Exec("alter table mc modify column c2 blob")
-	c.Assert(err, NotNil)
-
-	_, err = tk.Exec("alter table mc modify column c2 varchar(8)")
-	c.Assert(err, NotNil)
+	tk.MustExec("alter table mc modify column c2 blob")
+	tk.MustExec("alter table mc modify column c2 varchar(8)")
 	tk.MustExec("alter table mc modify column c2 varchar(11)")
 	tk.MustExec("alter table mc modify column c2 text(13)")
 	tk.MustExec("alter table mc modify column c2 text")
@@ -334,3 +396,15 @@ func (s *testSuite) TestTooLargeIdentifierLength(c *C) {
 	_, err = tk.Exec(fmt.Sprintf("create index %s on t(c)", indexName2))
 	c.Assert(err.Error(), Equals, fmt.Sprintf("[ddl:1059]Identifier name '%s' is too long", indexName2))
 }
+
+func (s *testSuite) TestRecoverTable(c *C) {
+	// The data of dropped tables are deleted at once by the stores that don't support delete-range.
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t_recover (a int)")
+	tk.MustExec("drop table t_recover")
+	_, err := tk.Exec("recover table t_recover")
+	c.Assert(err, ErrorMatches, "recover table is not supported by the store.*")
+	_, err = tk.Exec("flashback table t_recover to t_flashback")
+	c.Assert(err, ErrorMatches, "recover table is not supported by the store.*")
+}
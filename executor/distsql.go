@@ -923,6 +923,18 @@ func (builder *requestBuilder) SetAnalyzeRequest(ana *tipb.AnalyzeReq) *requestB
 	return builder
 }
 
+func (builder *requestBuilder) SetChecksumRequest(checksum *distsql.ChecksumRequest) *requestBuilder {
+	if builder.err != nil {
+		return builder
+	}
+
+	builder.Request.Tp = kv.ReqTypeChecksum
+	builder.Request.StartTs = checksum.StartTs
+	builder.Request.Data, builder.err = checksum.Marshal()
+	builder.Request.NotFillCache = true
+	return builder
+}
+
 func (builder *requestBuilder) SetKeyRanges(keyRanges []kv.KeyRange) *requestBuilder {
 	builder.Request.KeyRanges = keyRanges
 	return builder
@@ -17,18 +17,22 @@ import (
 	"fmt"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/cznic/mathutil"
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/domain"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/store/tikv/oracle"
 	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/table/tables"
 	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/types"
@@ -68,6 +72,34 @@ var (
 	ErrBatchInsertFail      = terror.ClassExecutor.New(codeBatchInsertFail, "Batch insert failed, please clean the table and try again.")
 	ErrWrongValueCountOnRow = terror.ClassExecutor.New(codeWrongValueCountOnRow, "Column count doesn't match value count at row %d")
 	ErrPasswordFormat       = terror.ClassExecutor.New(codePasswordFormat, "The password hash doesn't have the expected format. Check if the correct password algorithm is being used with the PASSWORD() function.")
+	ErrFileExists           = terror.ClassExecutor.New(codeFileExists, mysql.MySQLErrName[mysql.ErrFileExists])
+	ErrTooManyRows          = terror.ClassExecutor.New(codeTooManyRows, mysql.MySQLErrName[mysql.ErrTooManyRows])
+	ErrOptionPrevents       = terror.ClassExecutor.New(codeOptionPrevents, mysql.MySQLErrName[mysql.ErrOptionPreventsStatement])
+
+	ErrNoData                  = terror.ClassExecutor.New(codeNoData, mysql.MySQLErrName[mysql.ErrSpFetchNoData])
+	ErrRoutineExists           = terror.ClassExecutor.New(codeRoutineExists, mysql.MySQLErrName[mysql.ErrSpAlreadyExists])
+	ErrRoutineNotExists        = terror.ClassExecutor.New(codeRoutineNotExists, mysql.MySQLErrName[mysql.ErrSpDoesNotExist])
+	ErrLabelMismatch           = terror.ClassExecutor.New(codeLabelMismatch, mysql.MySQLErrName[mysql.ErrSpLilabelMismatch])
+	ErrBadReturn               = terror.ClassExecutor.New(codeBadReturn, mysql.MySQLErrName[mysql.ErrSpBadreturn])
+	ErrWrongNoOfArgs           = terror.ClassExecutor.New(codeWrongNoOfArgs, "Incorrect number of arguments for %s %s; expected %d, got %d")
+	ErrNoReturn                = terror.ClassExecutor.New(codeNoReturn, mysql.MySQLErrName[mysql.ErrSpNoreturn])
+	ErrNoReturnEnd             = terror.ClassExecutor.New(codeNoReturnEnd, mysql.MySQLErrName[mysql.ErrSpNoreturnend])
+	ErrCursorMismatch          = terror.ClassExecutor.New(codeCursorMismatch, mysql.MySQLErrName[mysql.ErrSpCursorMismatch])
+	ErrCursorAlreadyOpen       = terror.ClassExecutor.New(codeCursorAlreadyOpen, mysql.MySQLErrName[mysql.ErrSpCursorAlreadyOpen])
+	ErrCursorNotOpen           = terror.ClassExecutor.New(codeCursorNotOpen, mysql.MySQLErrName[mysql.ErrSpCursorNotOpen])
+	ErrWrongNoOfFetchArgs      = terror.ClassExecutor.New(codeWrongNoOfFetchArgs, mysql.MySQLErrName[mysql.ErrSpWrongNoOfFetchArgs])
+	ErrDupParam                = terror.ClassExecutor.New(codeDupParam, mysql.MySQLErrName[mysql.ErrSpDupParam])
+	ErrDupVar                  = terror.ClassExecutor.New(codeDupVar, mysql.MySQLErrName[mysql.ErrSpDupVar])
+	ErrDupCursor               = terror.ClassExecutor.New(codeDupCursor, mysql.MySQLErrName[mysql.ErrSpDupCurs])
+	ErrVarAfterCursorOrHandler = terror.ClassExecutor.New(codeVarAfterCursorOrHandler, mysql.MySQLErrName[mysql.ErrSpVarcondAfterCurshndlr])
+	ErrCursorAfterHandler      = terror.ClassExecutor.New(codeCursorAfterHandler, mysql.MySQLErrName[mysql.ErrSpCursorAfterHandler])
+	ErrBadSQLState             = terror.ClassExecutor.New(codeBadSQLState, mysql.MySQLErrName[mysql.ErrSpBadSQLstate])
+	ErrDupHandler              = terror.ClassExecutor.New(codeDupHandler, mysql.MySQLErrName[mysql.ErrSpDupHandler])
+	ErrNotVarArg               = terror.ClassExecutor.New(codeNotVarArg, mysql.MySQLErrName[mysql.ErrSpNotVarArg])
+	ErrNoRetset                = terror.ClassExecutor.New(codeNoRetset, mysql.MySQLErrName[mysql.ErrSpNoRetset])
+	ErrNoRecursion             = terror.ClassExecutor.New(codeNoRecursion, mysql.MySQLErrName[mysql.ErrSpNoRecursion])
+	ErrRecursionLimit          = terror.ClassExecutor.New(codeRecursionLimit, mysql.MySQLErrName[mysql.ErrSpRecursionLimit])
+	ErrBadSelect               = terror.ClassExecutor.New(codeBadSelect, mysql.MySQLErrName[mysql.ErrSpBadselect])
 )
 
 // Error codes.
@@ -82,6 +114,34 @@ const (
 	CodeCannotUser           terror.ErrCode = 1396 // MySQL error code
 	codeWrongValueCountOnRow terror.ErrCode = 1136 // MySQL error code
 	codePasswordFormat       terror.ErrCode = 1827 // MySQL error code
+	codeFileExists           terror.ErrCode = 1086 // MySQL error code
+	codeTooManyRows          terror.ErrCode = 1172 // MySQL error code
+	codeOptionPrevents       terror.ErrCode = 1290 // MySQL error code
+
+	codeNoData                  terror.ErrCode = mysql.ErrSpFetchNoData
+	codeRoutineExists           terror.ErrCode = mysql.ErrSpAlreadyExists
+	codeRoutineNotExists        terror.ErrCode = mysql.ErrSpDoesNotExist
+	codeLabelMismatch           terror.ErrCode = mysql.ErrSpLilabelMismatch
+	codeBadReturn               terror.ErrCode = mysql.ErrSpBadreturn
+	codeWrongNoOfArgs           terror.ErrCode = mysql.ErrSpWrongNoOfArgs
+	codeNoReturn                terror.ErrCode = mysql.ErrSpNoreturn
+	codeNoReturnEnd             terror.ErrCode = mysql.ErrSpNoreturnend
+	codeCursorMismatch          terror.ErrCode = mysql.ErrSpCursorMismatch
+	codeCursorAlreadyOpen       terror.ErrCode = mysql.ErrSpCursorAlreadyOpen
+	codeCursorNotOpen           terror.ErrCode = mysql.ErrSpCursorNotOpen
+	codeWrongNoOfFetchArgs      terror.ErrCode = mysql.ErrSpWrongNoOfFetchArgs
+	codeDupParam                terror.ErrCode = mysql.ErrSpDupParam
+	codeDupVar                  terror.ErrCode = mysql.ErrSpDupVar
+	codeDupCursor               terror.ErrCode = mysql.ErrSpDupCurs
+	codeVarAfterCursorOrHandler terror.ErrCode = mysql.ErrSpVarcondAfterCurshndlr
+	codeCursorAfterHandler      terror.ErrCode = mysql.ErrSpCursorAfterHandler
+	codeBadSQLState             terror.ErrCode = mysql.ErrSpBadSQLstate
+	codeDupHandler              terror.ErrCode = mysql.ErrSpDupHandler
+	codeNotVarArg               terror.ErrCode = mysql.ErrSpNotVarArg
+	codeNoRetset                terror.ErrCode = mysql.ErrSpNoRetset
+	codeNoRecursion             terror.ErrCode = mysql.ErrSpNoRecursion
+	codeRecursionLimit          terror.ErrCode = mysql.ErrSpRecursionLimit
+	codeBadSelect               terror.ErrCode = mysql.ErrSpBadselect
 )
 
 // Row represents a result set row, it may be returned from a table, a join, or a projection.
@@ -307,7 +367,7 @@ func (e *ShowDDLJobsExec) Next(goCtx goctx.Context) (Row, error) {
 	}
 
 	job := e.jobs[e.cursor]
-	row := types.MakeDatums(job.String(), job.State.String())
+	row := types.MakeDatums(job.String(), job.State.String(), job.GetRowCount(), e.jobETA(job))
 	e.cursor++
 
 	return row, nil
@@ -321,13 +381,45 @@ func (e *ShowDDLJobsExec) NextChunk(goCtx goctx.Context, chk *chunk.Chunk) error
 	}
 	numCurBatch := mathutil.Min(e.maxChunkSize, len(e.jobs)-e.cursor)
 	for i := e.cursor; i < e.cursor+numCurBatch; i++ {
-		chk.AppendString(0, e.jobs[i].String())
-		chk.AppendString(1, e.jobs[i].State.String())
+		job := e.jobs[i]
+		chk.AppendString(0, job.String())
+		chk.AppendString(1, job.State.String())
+		chk.AppendInt64(2, job.GetRowCount())
+		chk.AppendString(3, e.jobETA(job))
 	}
 	e.cursor += numCurBatch
 	return nil
 }
 
+// jobETA estimates the remaining time of a running job which is backfilling
+// an index, from the rows it has handled and the row count in the statistics
+// of the table. It returns an empty string if the time can't be estimated.
+func (e *ShowDDLJobsExec) jobETA(job *model.Job) string {
+	if job.Type != model.ActionAddIndex && job.Type != model.ActionAddPrimaryKey {
+		return ""
+	}
+	// If the value of SnapshotVer isn't zero, it means the work is backfilling the indexes.
+	if job.State != model.JobStateRunning || job.SchemaState != model.StateWriteReorganization || job.SnapshotVer == 0 {
+		return ""
+	}
+	done := job.GetRowCount()
+	statsHandle := domain.GetDomain(e.ctx).StatsHandle()
+	if done <= 0 || statsHandle == nil {
+		return ""
+	}
+	stats := statsHandle.GetTableStats(job.TableID)
+	if stats.Pseudo || stats.Count <= done {
+		return ""
+	}
+	start := time.Unix(0, oracle.ExtractPhysical(job.SnapshotVer)*int64(time.Millisecond))
+	elapsed := time.Since(start)
+	if elapsed <= 0 {
+		return ""
+	}
+	remaining := time.Duration(float64(elapsed) * float64(stats.Count-done) / float64(done))
+	return (remaining / time.Second * time.Second).String()
+}
+
 // CheckTableExec represents a check table executor.
 // It is built from the "admin check table" statement, and it checks if the
 // index matches the records in the table.
@@ -377,7 +469,15 @@ func (e *CheckTableExec) run(goCtx goctx.Context) error {
 		}
 		for _, idx := range tb.Indices() {
 			txn := e.ctx.Txn()
-			err = admin.CompareIndexData(txn, tb, idx)
+			genExprs, err := tables.BuildIndexGenExprs(e.ctx, tb, idx.Meta())
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if len(genExprs) > 0 {
+				err = admin.CompareIndexDataWithGenCols(e.ctx, txn, tb, idx, genExprs)
+			} else {
+				err = admin.CompareIndexData(txn, tb, idx)
+			}
 			if err != nil {
 				return errors.Errorf("%v err:%v", t.Name, err)
 			}
@@ -590,6 +690,34 @@ func init() {
 		CodePasswordNoMatch:      mysql.ErrPasswordNoMatch,
 		codeWrongValueCountOnRow: mysql.ErrWrongValueCountOnRow,
 		codePasswordFormat:       mysql.ErrPasswordFormat,
+		codeFileExists:           mysql.ErrFileExists,
+		codeTooManyRows:          mysql.ErrTooManyRows,
+		codeOptionPrevents:       mysql.ErrOptionPreventsStatement,
+
+		codeNoData:                  mysql.ErrSpFetchNoData,
+		codeRoutineExists:           mysql.ErrSpAlreadyExists,
+		codeRoutineNotExists:        mysql.ErrSpDoesNotExist,
+		codeLabelMismatch:           mysql.ErrSpLilabelMismatch,
+		codeBadReturn:               mysql.ErrSpBadreturn,
+		codeWrongNoOfArgs:           mysql.ErrSpWrongNoOfArgs,
+		codeNoReturn:                mysql.ErrSpNoreturn,
+		codeNoReturnEnd:             mysql.ErrSpNoreturnend,
+		codeCursorMismatch:          mysql.ErrSpCursorMismatch,
+		codeCursorAlreadyOpen:       mysql.ErrSpCursorAlreadyOpen,
+		codeCursorNotOpen:           mysql.ErrSpCursorNotOpen,
+		codeWrongNoOfFetchArgs:      mysql.ErrSpWrongNoOfFetchArgs,
+		codeDupParam:                mysql.ErrSpDupParam,
+		codeDupVar:                  mysql.ErrSpDupVar,
+		codeDupCursor:               mysql.ErrSpDupCurs,
+		codeVarAfterCursorOrHandler: mysql.ErrSpVarcondAfterCurshndlr,
+		codeCursorAfterHandler:      mysql.ErrSpCursorAfterHandler,
+		codeBadSQLState:             mysql.ErrSpBadSQLstate,
+		codeDupHandler:              mysql.ErrSpDupHandler,
+		codeNotVarArg:               mysql.ErrSpNotVarArg,
+		codeNoRetset:                mysql.ErrSpNoRetset,
+		codeNoRecursion:             mysql.ErrSpNoRecursion,
+		codeRecursionLimit:          mysql.ErrSpRecursionLimit,
+		codeBadSelect:               mysql.ErrSpBadselect,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassExecutor] = tableMySQLErrCodes
 }
@@ -17,6 +17,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -26,8 +27,10 @@ import (
 	pb "github.com/pingcap/kvproto/pkg/kvrpcpb"
 	"github.com/pingcap/tidb"
 	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/distsql"
 	"github.com/pingcap/tidb/domain"
 	"github.com/pingcap/tidb/executor"
+	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
@@ -37,9 +40,11 @@ import (
 	"github.com/pingcap/tidb/store/tikv"
 	mocktikv "github.com/pingcap/tidb/store/tikv/mocktikv"
 	"github.com/pingcap/tidb/store/tikv/tikvrpc"
+	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/admin"
+	"github.com/pingcap/tidb/util/collate"
 	"github.com/pingcap/tidb/util/logutil"
 	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/testleak"
@@ -155,7 +160,7 @@ func (s *testSuite) TestAdmin(c *C) {
 	c.Assert(err, IsNil)
 	row, err = r.Next(goCtx)
 	c.Assert(err, IsNil)
-	c.Assert(row.Len(), Equals, 2)
+	c.Assert(row.Len(), Equals, 4)
 	txn, err = s.store.Begin()
 	c.Assert(err, IsNil)
 	historyJobs, err := admin.GetHistoryDDLJobs(txn)
@@ -163,6 +168,8 @@ func (s *testSuite) TestAdmin(c *C) {
 	c.Assert(len(row.GetString(0)), Greater, 0)
 	c.Assert(err, IsNil)
 	c.Assert(row.GetString(1), Equals, historyJobs[0].State.String())
+	c.Assert(row.GetInt64(2), Equals, historyJobs[0].GetRowCount())
+	c.Assert(row.GetString(3), Equals, "")
 	c.Assert(err, IsNil)
 
 	// check table test
@@ -190,6 +197,104 @@ func (s *testSuite) TestAdmin(c *C) {
 	c.Assert(err, NotNil)
 }
 
+func (s *testSuite) TestAdminChecksumTable(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("create table checksum_test (a int primary key, b int, index (b))")
+	tk.MustExec("create table checksum_test1 (a int, b varchar(10))")
+	tk.MustExec("insert checksum_test values (1, 1), (2, 2), (3, NULL)")
+	tk.MustExec("insert checksum_test1 values (1, 'a'), (2, 'b')")
+
+	// checksumOf calculates the checksum of the table by scanning it.
+	checksumOf := func(name string) string {
+		is := domain.GetDomain(tk.Se.(context.Context)).InfoSchema()
+		tbl, err := is.TableByName(model.NewCIStr("test"), model.NewCIStr(name))
+		c.Assert(err, IsNil)
+		ver, err := s.store.CurrentVersion()
+		c.Assert(err, IsNil)
+		snapshot, err := s.store.GetSnapshot(ver)
+		c.Assert(err, IsNil)
+		prefix := tablecodec.EncodeTablePrefix(tbl.Meta().ID)
+		it, err := snapshot.Seek(prefix)
+		c.Assert(err, IsNil)
+		defer it.Close()
+		checksum := &distsql.ChecksumResponse{}
+		for it.Valid() && it.Key().HasPrefix(prefix) {
+			checksum.Update(it.Key(), it.Value())
+			c.Assert(it.Next(), IsNil)
+		}
+		return fmt.Sprintf("test %s %d %d %d", name, checksum.Checksum, checksum.TotalKvs, checksum.TotalBytes)
+	}
+
+	expect := checksumOf("checksum_test")
+	c.Assert(strings.Fields(expect)[3], Equals, "6")
+	tk.MustQuery("admin checksum table checksum_test").Check(testkit.Rows(expect))
+	expect1 := checksumOf("checksum_test1")
+	c.Assert(strings.Fields(expect1)[3], Equals, "2")
+	tk.MustQuery("admin checksum table checksum_test, test.checksum_test1").Check(testkit.Rows(expect, expect1))
+
+	// The checksum changes with the data, and does not depend on the order of rows.
+	tk.MustExec("delete from checksum_test where a = 1")
+	tk.MustQuery("admin checksum table checksum_test").Check(testkit.Rows(checksumOf("checksum_test")))
+	tk.MustExec("insert checksum_test values (1, 1)")
+	tk.MustQuery("admin checksum table checksum_test").Check(testkit.Rows(expect))
+
+	// The checksum is calculated at the start timestamp of the transaction.
+	tk.MustExec("begin")
+	tk.MustQuery("select * from checksum_test1 where a = 1").Check(testkit.Rows("1 a"))
+	tk1 := testkit.NewTestKit(c, s.store)
+	tk1.MustExec("insert test.checksum_test1 values (3, 'c')")
+	tk.MustQuery("admin checksum table checksum_test1").Check(testkit.Rows(expect1))
+	tk.MustExec("commit")
+	tk.MustQuery("admin checksum table checksum_test1").Check(testkit.Rows(checksumOf("checksum_test1")))
+
+	_, err := tk.Exec("admin checksum table checksum_test_error")
+	c.Assert(err, NotNil)
+}
+
+func (s *testSuite) TestAdminCheckIndex(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("create table admin_index (a int primary key, b int, index idx (b))")
+	tk.MustExec("insert admin_index values (-10, -10), (1, 1), (5, 5), (10, 10), (20, 20)")
+	tk.MustExec("admin check index admin_index idx")
+	tk.MustExec("admin check index admin_index idx (-20, 0), (5, 10)")
+	_, err := tk.Exec("admin check index admin_index idx_error")
+	c.Assert(err, NotNil)
+
+	is := domain.GetDomain(tk.Se.(context.Context)).InfoSchema()
+	tb, err := is.TableByName(model.NewCIStr("test"), model.NewCIStr("admin_index"))
+	c.Assert(err, IsNil)
+	idx := tb.Indices()[0]
+
+	// Add a dangling index entry for handle 15 and remove the entry of handle 5.
+	txn, err := s.store.Begin()
+	c.Assert(err, IsNil)
+	_, err = idx.Create(txn, types.MakeDatums(int64(15)), 15)
+	c.Assert(err, IsNil)
+	err = idx.Delete(txn, types.MakeDatums(int64(5)), 5)
+	c.Assert(err, IsNil)
+	err = txn.Commit(goctx.Background())
+	c.Assert(err, IsNil)
+
+	tk.MustExec("admin check index admin_index idx (-20, 4), (16, 30)")
+	_, err = tk.Exec("admin check index admin_index idx (1, 10)")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("admin check index admin_index idx (11, 15)")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("admin check index admin_index idx")
+	c.Assert(err, NotNil)
+
+	tk.MustQuery("admin recover index admin_index idx").Check(testkit.Rows("1 5"))
+	tk.MustQuery("admin recover index admin_index idx").Check(testkit.Rows("0 5"))
+	_, err = tk.Exec("admin check index admin_index idx")
+	c.Assert(err, NotNil)
+	tk.MustQuery("admin cleanup index admin_index idx").Check(testkit.Rows("1"))
+	tk.MustQuery("admin cleanup index admin_index idx").Check(testkit.Rows("0"))
+	tk.MustExec("admin check index admin_index idx")
+	tk.MustExec("admin check table admin_index")
+}
+
 func (s *testSuite) fillData(tk *testkit.TestKit, table string) {
 	tk.MustExec("use test")
 	tk.MustExec(fmt.Sprintf("create table %s(id int not null default 1, name varchar(255), PRIMARY KEY(id));", table))
@@ -2294,3 +2399,92 @@ func (s *testSuite) TestTableScanWithPointRanges(c *C) {
 	tk.MustExec("insert into t values(1), (5), (10)")
 	tk.MustQuery("select * from t where id in(1, 2, 10)").Check(testkit.Rows("1", "10"))
 }
+
+func (s *testSuite) TestNewCollation(c *C) {
+	collate.SetNewCollationEnabled(true)
+	defer collate.SetNewCollationEnabled(false)
+	tk := testkit.NewTestKitWithInit(c, s.store)
+
+	tk.MustExec("drop table if exists t, t1, t2, t3")
+	tk.MustExec("create table t(id int primary key, a varchar(20) collate utf8mb4_general_ci, b varchar(20) charset utf8mb4 collate utf8mb4_bin, unique key ua(a), key ib(b))")
+	tk.MustExec("insert into t values (1, 'abc', 'abc '), (2, 'Def', 'DEF'), (3, 'ghi', 'def')")
+	_, err := tk.Exec("insert into t values (4, 'ABC ', 'x')")
+	c.Assert(terror.ErrorEqual(err, kv.ErrKeyExists), IsTrue)
+	tk.MustQuery("select id from t where a = 'ABC'").Check(testkit.Rows("1"))
+	// The index keys of the strings are their sort keys, so the equal conditions can use the index.
+	rows := tk.MustQuery("explain select id from t where a = 'ABC'").Rows()
+	c.Assert(rows[0][0], Matches, "IndexScan.*")
+	tk.MustQuery("select id from t where a in ('DEF', 'GHI') order by id").Check(testkit.Rows("2", "3"))
+	tk.MustQuery("select id from t where b = 'abc'").Check(testkit.Rows("1"))
+	tk.MustQuery("select id from t where a > 'b' order by id").Check(testkit.Rows("2", "3"))
+	tk.MustQuery("select a from t order by a desc").Check(testkit.Rows("ghi", "Def", "abc"))
+	tk.MustQuery("select b from t order by b").Check(testkit.Rows("DEF", "abc ", "def"))
+	tk.MustQuery("select max(a), min(b) from t").Check(testkit.Rows("ghi DEF"))
+	tk.MustQuery("select 'a' = 'A', 'a' collate utf8mb4_bin = 'A', strcmp('a', 'A' collate utf8mb4_general_ci)").Check(testkit.Rows("0 0 0"))
+	tk.MustQuery("select count(*) from t where a collate utf8mb4_bin = 'ABC'").Check(testkit.Rows("0"))
+	tk.MustExec("admin check table t")
+	rows = tk.MustQuery("show create table t").Rows()
+	c.Assert(rows[0][1], Matches, "(?s).*`a` varchar\\(20\\) COLLATE utf8mb4_general_ci DEFAULT NULL.*`b` varchar\\(20\\) DEFAULT NULL.*")
+
+	tk.MustExec("create table t1(a varchar(20) collate utf8mb4_general_ci, b int)")
+	tk.MustExec("insert into t1 values ('straße', 1), ('STRASSE', 2), ('Äsir', 3), ('asir', 4)")
+	tk.MustQuery("select count(distinct a) from t1").Check(testkit.Rows("3"))
+	tk.MustQuery("select sum(b) from t1 group by a order by sum(b)").Check(testkit.Rows("1", "2", "7"))
+	tk.MustQuery("select count(*) from t1 x join t1 y on x.a = y.a").Check(testkit.Rows("6"))
+
+	// The collations without collators, e.g. the _unicode_ci collations, aren't supported by the new collations.
+	_, err = tk.Exec("create table t2(a varchar(20) collate utf8mb4_unicode_ci)")
+	c.Assert(expression.ErrUnknownCollation.Equal(errors.Cause(err)), IsTrue)
+	_, err = tk.Exec("create table t2(a varchar(20) charset latin1 collate latin1_swedish_ci)")
+	c.Assert(expression.ErrUnknownCollation.Equal(errors.Cause(err)), IsTrue)
+	_, err = tk.Exec("select 'a' collate utf8mb4_spanish_ci")
+	c.Assert(expression.ErrUnknownCollation.Equal(errors.Cause(err)), IsTrue)
+	_, err = tk.Exec("alter table t1 convert to character set utf8mb4 collate utf8mb4_unicode_ci")
+	c.Assert(expression.ErrUnknownCollation.Equal(errors.Cause(err)), IsTrue)
+	_, err = tk.Exec("select 'a' collate utf8mb4_unicode_ci")
+	c.Assert(expression.ErrUnknownCollation.Equal(errors.Cause(err)), IsTrue)
+
+	// The indexes are rebuilt when the collation of the column is modified, the collation can't be
+	// changed by converting the table, which doesn't rebuild the indexes.
+	tk.MustExec("create table t2(id int primary key, a varchar(20) charset utf8mb4 collate utf8mb4_bin, key ia(a))")
+	tk.MustExec("insert into t2 values (1, 'abc'), (2, 'ABC')")
+	tk.MustQuery("select id from t2 use index(ia) where a = 'Abc'").Check(testkit.Rows())
+	tk.MustExec("alter table t2 modify a varchar(20) collate utf8mb4_general_ci")
+	tk.MustQuery("select id from t2 use index(ia) where a = 'Abc' order by id").Check(testkit.Rows("1", "2"))
+	tk.MustExec("admin check table t2")
+	_, err = tk.Exec("alter table t2 convert to character set utf8mb4 collate utf8mb4_bin")
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Equals, "[ddl:203]unsupported modify column change the collation of column a used by index ia")
+	tk.MustExec("alter table t1 convert to character set utf8mb4 collate utf8mb4_bin")
+	tk.MustQuery("select count(distinct a) from t1").Check(testkit.Rows("4"))
+
+	// The keys of the padding _bin collations keep the order of the strings, the ranges can be built
+	// from the comparisons and "like", and the strings are read from the index with their trailing spaces.
+	tk.MustExec("create table t3(id int primary key, b varchar(20) charset utf8mb4 collate utf8mb4_bin, key ib(b))")
+	tk.MustExec("insert into t3 values (1, 'abc '), (2, 'abc'), (3, 'abd'), (4, 'ab'), (5, 'abc x'), (6, 'b')")
+	rows = tk.MustQuery("explain select id, b from t3 where b >= 'abc' and b < 'abd'").Rows()
+	c.Assert(rows[0][0], Matches, "IndexScan.*")
+	c.Assert(rows[0][4], Matches, ".*range:\\[abc,abd\\).*")
+	c.Assert(rows[1][0], Matches, "IndexReader.*")
+	tk.MustQuery("select id, b from t3 where b >= 'abc' and b < 'abd' order by id").Check(testkit.Rows("1 abc ", "2 abc", "5 abc x"))
+	tk.MustQuery("select id from t3 where b between 'ab' and 'abc ' order by id").Check(testkit.Rows("1", "2", "4"))
+	tk.MustQuery("select id from t3 where b > 'abc ' order by id").Check(testkit.Rows("3", "5", "6"))
+	rows = tk.MustQuery("explain select id from t3 where b like 'abc%'").Rows()
+	c.Assert(rows[0][0], Matches, "IndexScan.*")
+	tk.MustQuery("select id from t3 where b like 'abc%' order by id").Check(testkit.Rows("1", "2", "5"))
+	tk.MustQuery("select id from t3 where b like 'abc %' order by id").Check(testkit.Rows("1", "5"))
+	tk.MustQuery("select id from t3 where b like 'abc' order by id").Check(testkit.Rows("2"))
+	tk.MustQuery("select b from t3 where b in ('abc', 'b') order by id").Check(testkit.Rows("abc ", "abc", "b"))
+	tk.MustExec("admin check table t3")
+
+	_, err = tk.Exec("select * from t where a = b")
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Equals, "[expression:1267]Illegal mix of collations (utf8mb4_general_ci,IMPLICIT) and (utf8mb4_bin,IMPLICIT) for operation 'eq'")
+	_, err = tk.Exec("select 'a' collate utf8mb4_foo_ci")
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Equals, "[expression:1273]Unknown collation: 'utf8mb4_foo_ci'")
+	_, err = tk.Exec("select a collate latin1_bin from t")
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Equals, "[expression:1253]COLLATION 'latin1_bin' is not valid for CHARACTER SET 'utf8mb4'")
+	tk.MustQuery("select a = b collate utf8mb4_bin from t order by id").Check(testkit.Rows("1", "0", "0"))
+}
@@ -155,6 +155,7 @@ func getJoinKey(cols []*expression.Column, row Row, vals []types.Datum, bytes []
 		if vals[i].IsNull() {
 			return true, nil, nil
 		}
+		vals[i] = expression.CollationKeyDatum(vals[i], col.GetType())
 	}
 	if len(vals) == 0 {
 		return false, nil, nil
@@ -279,6 +279,8 @@ func compareKeys(stmtCtx *stmtctx.StatementContext,
 			return 0, errors.Trace(err)
 		}
 
+		lVal = expression.CollationKeyDatum(lVal, leftKey.GetType())
+		rVal = expression.CollationKeyDatum(rVal, rightKeys[i].GetType())
 		ret, err := lVal.CompareDatum(stmtCtx, &rVal)
 		if err != nil {
 			return 0, errors.Trace(err)
@@ -258,6 +258,8 @@ func (pa *stmtAttributes) fromPlan(p plan.Plan) {
 		if x.SelectPlan != nil {
 			pa.fromPlan(x.SelectPlan)
 		}
+	case *plan.SelectInto:
+		pa.fromPlan(x.TargetPlan)
 	case *plan.Delete:
 		pa.fromPlan(x.SelectPlan)
 	case *plan.Update:
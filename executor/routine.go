@@ -0,0 +1,468 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/domain"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/sessionctx/varsutil"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/udf"
+	"github.com/pingcap/tidb/util/charset"
+	"github.com/pingcap/tidb/util/sqlexec"
+	goctx "golang.org/x/net/context"
+)
+
+// quoteString quotes s as a string literal of a SQL statement.
+func quoteString(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `'`, `\'`, -1)
+	return "'" + s + "'"
+}
+
+// RoutineFieldType returns a copy of the type of a parameter, a local variable or the return value
+// of a stored routine, with the unspecified charset, collation, length and decimal set to the defaults.
+func RoutineFieldType(tp *types.FieldType) *types.FieldType {
+	ft := *tp
+	if ft.Charset == "" {
+		switch ft.Tp {
+		case mysql.TypeString, mysql.TypeVarchar, mysql.TypeVarString, mysql.TypeBlob, mysql.TypeTinyBlob,
+			mysql.TypeMediumBlob, mysql.TypeLongBlob, mysql.TypeEnum, mysql.TypeSet:
+			ft.Charset, ft.Collate = mysql.DefaultCharset, mysql.DefaultCollationName
+		default:
+			ft.Charset, ft.Collate = charset.CharsetBin, charset.CollationBin
+		}
+	}
+	defaultFlen, defaultDecimal := mysql.GetDefaultFieldLengthAndDecimal(ft.Tp)
+	if ft.Flen == types.UnspecifiedLength {
+		ft.Flen = defaultFlen
+	}
+	if ft.Decimal == types.UnspecifiedLength {
+		ft.Decimal = defaultDecimal
+	}
+	return &ft
+}
+
+// routineLabel is a label of a BEGIN ... END block or a loop.
+type routineLabel struct {
+	name   string
+	isLoop bool
+}
+
+// routineChecker checks the body of a stored routine when it's created, so the errors which
+// MySQL reports at CREATE time aren't postponed to the first call.
+type routineChecker struct {
+	stmt *ast.CreateRoutineStmt
+	// vars and cursors are the names declared in the enclosing blocks, the innermost one is the last.
+	vars      []map[string]struct{}
+	cursors   []map[string]struct{}
+	labels    []routineLabel
+	hasReturn bool
+}
+
+func checkRoutine(stmt *ast.CreateRoutineStmt) error {
+	c := &routineChecker{stmt: stmt}
+	params := make(map[string]struct{}, len(stmt.Params))
+	for _, param := range stmt.Params {
+		name := strings.ToLower(param.Name)
+		if _, ok := params[name]; ok {
+			return ErrDupParam.GenByArgs(param.Name)
+		}
+		params[name] = struct{}{}
+	}
+	c.vars = append(c.vars, params)
+	c.cursors = append(c.cursors, map[string]struct{}{})
+	if err := c.check(stmt.Body); err != nil {
+		return errors.Trace(err)
+	}
+	if stmt.Tp == ast.RoutineFunction && !c.hasReturn {
+		return ErrNoReturn.GenByArgs(stmt.Name.O)
+	}
+	return nil
+}
+
+func (c *routineChecker) checkStmts(stmts []ast.StmtNode) error {
+	for _, stmt := range stmts {
+		if err := c.check(stmt); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+func (c *routineChecker) check(stmt ast.StmtNode) error {
+	isFunction := c.stmt.Tp == ast.RoutineFunction
+	switch x := stmt.(type) {
+	case *ast.BlockStmt:
+		return errors.Trace(c.checkBlock(x))
+	case *ast.IfStmt:
+		if err := c.checkStmts(x.Then); err != nil {
+			return errors.Trace(err)
+		}
+		return errors.Trace(c.checkStmts(x.Else))
+	case *ast.WhileStmt:
+		return errors.Trace(c.checkLoop(x.Label, x.Body))
+	case *ast.RepeatStmt:
+		return errors.Trace(c.checkLoop(x.Label, x.Body))
+	case *ast.LoopStmt:
+		return errors.Trace(c.checkLoop(x.Label, x.Body))
+	case *ast.LeaveStmt:
+		if !c.hasLabel(x.Label, false) {
+			return ErrLabelMismatch.GenByArgs("LEAVE", x.Label)
+		}
+	case *ast.IterateStmt:
+		if !c.hasLabel(x.Label, true) {
+			return ErrLabelMismatch.GenByArgs("ITERATE", x.Label)
+		}
+	case *ast.ReturnStmt:
+		if !isFunction {
+			return ErrBadReturn
+		}
+		c.hasReturn = true
+	case *ast.OpenCursorStmt:
+		return errors.Trace(c.checkCursor(x.Name))
+	case *ast.CloseCursorStmt:
+		return errors.Trace(c.checkCursor(x.Name))
+	case *ast.FetchCursorStmt:
+		if err := c.checkCursor(x.Name); err != nil {
+			return errors.Trace(err)
+		}
+		for _, name := range x.Vars {
+			if !c.hasVar(name) {
+				return plan.ErrUndeclaredVar.GenByArgs(name)
+			}
+		}
+	case *ast.SelectStmt:
+		if x.SelectIntoOpt == nil || x.SelectIntoOpt.Tp != ast.SelectIntoVars {
+			if isFunction {
+				return ErrNoRetset.GenByArgs("FUNCTION")
+			}
+			return nil
+		}
+		for _, v := range x.SelectIntoOpt.Vars {
+			if col, ok := v.(*ast.ColumnNameExpr); ok && !c.hasVar(col.Name.Name.L) {
+				return plan.ErrUndeclaredVar.GenByArgs(col.Name.Name.O)
+			}
+		}
+	case *ast.UnionStmt, *ast.ShowStmt, *ast.ExplainStmt:
+		if isFunction {
+			return ErrNoRetset.GenByArgs("FUNCTION")
+		}
+	}
+	return nil
+}
+
+func (c *routineChecker) checkBlock(block *ast.BlockStmt) error {
+	vars := make(map[string]struct{})
+	cursors := make(map[string]struct{})
+	c.vars = append(c.vars, vars)
+	c.cursors = append(c.cursors, cursors)
+	c.labels = append(c.labels, routineLabel{name: strings.ToLower(block.Label)})
+	defer func() {
+		c.vars = c.vars[:len(c.vars)-1]
+		c.cursors = c.cursors[:len(c.cursors)-1]
+		c.labels = c.labels[:len(c.labels)-1]
+	}()
+
+	// The declarations must be in the order of variables, cursors and handlers.
+	hasCursor, hasHandler := false, false
+	handlers := make(map[ast.HandlerCondition]struct{})
+	for _, stmt := range block.Stmts {
+		switch x := stmt.(type) {
+		case *ast.DeclareVarStmt:
+			if hasCursor || hasHandler {
+				return ErrVarAfterCursorOrHandler
+			}
+			for _, name := range x.Names {
+				lower := strings.ToLower(name)
+				if _, ok := vars[lower]; ok {
+					return ErrDupVar.GenByArgs(name)
+				}
+				vars[lower] = struct{}{}
+			}
+		case *ast.DeclareCursorStmt:
+			if hasHandler {
+				return ErrCursorAfterHandler
+			}
+			hasCursor = true
+			lower := strings.ToLower(x.Name)
+			if _, ok := cursors[lower]; ok {
+				return ErrDupCursor.GenByArgs(x.Name)
+			}
+			cursors[lower] = struct{}{}
+		case *ast.DeclareHandlerStmt:
+			hasHandler = true
+			for _, cond := range x.Conditions {
+				if cond.Tp == ast.HandlerSQLState && (len(cond.SQLState) != 5 || strings.HasPrefix(cond.SQLState, "00")) {
+					return ErrBadSQLState.GenByArgs(cond.SQLState)
+				}
+				if _, ok := handlers[*cond]; ok {
+					return ErrDupHandler
+				}
+				handlers[*cond] = struct{}{}
+			}
+			if err := c.check(x.Stmt); err != nil {
+				return errors.Trace(err)
+			}
+		default:
+			if err := c.check(stmt); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+	return nil
+}
+
+func (c *routineChecker) checkLoop(label string, body []ast.StmtNode) error {
+	c.labels = append(c.labels, routineLabel{name: strings.ToLower(label), isLoop: true})
+	err := c.checkStmts(body)
+	c.labels = c.labels[:len(c.labels)-1]
+	return errors.Trace(err)
+}
+
+func (c *routineChecker) hasLabel(label string, isLoop bool) bool {
+	label = strings.ToLower(label)
+	for i := len(c.labels) - 1; i >= 0; i-- {
+		if c.labels[i].name == label {
+			return c.labels[i].isLoop || !isLoop
+		}
+	}
+	return false
+}
+
+func (c *routineChecker) hasVar(name string) bool {
+	name = strings.ToLower(name)
+	for _, vars := range c.vars {
+		if _, ok := vars[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *routineChecker) checkCursor(name string) error {
+	lower := strings.ToLower(name)
+	for _, cursors := range c.cursors {
+		if _, ok := cursors[lower]; ok {
+			return nil
+		}
+	}
+	return ErrCursorMismatch.GenByArgs(name)
+}
+
+// routineExists checks whether the stored routine exists in mysql.proc.
+func routineExists(ctx context.Context, tp ast.RoutineType, db, name string) (bool, error) {
+	sql := fmt.Sprintf(`SELECT name FROM %s.proc WHERE LOWER(db) = %s AND LOWER(name) = %s AND type = '%s'`,
+		mysql.SystemDB, quoteString(strings.ToLower(db)), quoteString(strings.ToLower(name)), tp)
+	rows, _, err := ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(ctx, sql)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	return len(rows) > 0, nil
+}
+
+// deleteRoutine deletes the stored routine from mysql.proc.
+func deleteRoutine(ctx context.Context, tp ast.RoutineType, db, name string) error {
+	sql := fmt.Sprintf(`DELETE FROM %s.proc WHERE LOWER(db) = %s AND LOWER(name) = %s AND type = '%s'`,
+		mysql.SystemDB, quoteString(strings.ToLower(db)), quoteString(strings.ToLower(name)), tp)
+	_, _, err := ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(ctx, sql)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	dom := domain.GetDomain(ctx)
+	dom.DeleteRoutine(tp, db, name)
+	dom.NotifyUpdateRoutine(ctx)
+	return nil
+}
+
+// routineDB returns the database of a stored routine, it's the current database if schema is empty.
+func (e *SimpleExec) routineDB(schema model.CIStr) (*model.DBInfo, error) {
+	name := schema.O
+	if name == "" {
+		name = e.ctx.GetSessionVars().CurrentDB
+	}
+	if name == "" {
+		return nil, plan.ErrNoDB
+	}
+	dbInfo, ok := e.is.SchemaByName(model.NewCIStr(name))
+	if !ok {
+		return nil, infoschema.ErrDatabaseNotExists.GenByArgs(name)
+	}
+	return dbInfo, nil
+}
+
+func (e *SimpleExec) executeCreateRoutine(s *ast.CreateRoutineStmt) error {
+	dbInfo, err := e.routineDB(s.Schema)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err = checkRoutine(s); err != nil {
+		return errors.Trace(err)
+	}
+	exists, err := routineExists(e.ctx, s.Tp, dbInfo.Name.O, s.Name.O)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if exists {
+		return ErrRoutineExists.GenByArgs(s.Tp, s.Name.O)
+	}
+	sessionVars := e.ctx.GetSessionVars()
+	var returns string
+	if s.Tp == ast.RoutineFunction {
+		if expression.IsBuiltinFunc(s.Name.L) {
+			// The builtin function takes precedence when the name is called.
+			sessionVars.StmtCtx.AppendWarning(udf.ErrNativeNameCollision.GenByArgs(s.Name.O))
+		}
+		returns = RoutineFieldType(s.ReturnType).String()
+	}
+	var definer string
+	if s.Definer != nil {
+		definer = s.Definer.String()
+	} else if sessionVars.User != nil {
+		definer = sessionVars.User.String()
+	}
+	deterministic := "NO"
+	if s.Deterministic {
+		deterministic = "YES"
+	}
+	dbCollation := dbInfo.Collate
+	if dbCollation == "" {
+		dbCollation = mysql.DefaultCollationName
+	}
+	charsetClient, err := varsutil.GetSessionSystemVar(sessionVars, variable.CharacterSetClient)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	collationConnection, err := varsutil.GetSessionSystemVar(sessionVars, variable.CollationConnection)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	sqlMode, err := varsutil.GetSessionSystemVar(sessionVars, variable.SQLModeVar)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	sql := fmt.Sprintf(`INSERT INTO %s.proc (db, name, type, sql_data_access, is_deterministic, security_type,
+		param_list, returns, body, definer, sql_mode, comment, character_set_client, collation_connection,
+		db_collation, definition) VALUES (%s, %s, '%s', '%s', '%s', '%s', %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)`,
+		mysql.SystemDB, quoteString(dbInfo.Name.O), quoteString(s.Name.O), s.Tp,
+		strings.Replace(s.DataAccess, " ", "_", -1), deterministic, s.Security,
+		quoteString(s.ParamsText), quoteString(returns), quoteString(s.BodyText), quoteString(definer),
+		quoteString(sqlMode), quoteString(s.Comment), quoteString(charsetClient),
+		quoteString(collationConnection), quoteString(dbCollation), quoteString(s.Text()))
+	_, _, err = e.ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(e.ctx, sql)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	dom := domain.GetDomain(e.ctx)
+	dom.SetRoutine(s.Tp, s.Name.O, &domain.RoutineInfo{DB: dbInfo.Name.O, Definition: s.Text(), SQLMode: sqlMode, Definer: definer})
+	dom.NotifyUpdateRoutine(e.ctx)
+	return nil
+}
+
+func (e *SimpleExec) executeDropProcedure(s *ast.DropProcedureStmt) error {
+	dbInfo, err := e.routineDB(s.Schema)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	exists, err := routineExists(e.ctx, ast.RoutineProcedure, dbInfo.Name.O, s.Name.O)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !exists {
+		err = ErrRoutineNotExists.GenByArgs("PROCEDURE", dbInfo.Name.O+"."+s.Name.O)
+		if s.IfExists {
+			e.ctx.GetSessionVars().StmtCtx.AppendWarning(err)
+			return nil
+		}
+		return err
+	}
+	return errors.Trace(deleteRoutine(e.ctx, ast.RoutineProcedure, dbInfo.Name.O, s.Name.O))
+}
+
+// dropStoredFunction drops the stored function of s if it exists, the returned bool reports
+// whether it's dropped.
+func (e *SimpleExec) dropStoredFunction(s *ast.DropFunctionStmt) (bool, error) {
+	dbInfo, err := e.routineDB(s.Schema)
+	if err != nil {
+		if s.Schema.L == "" && terror.ErrorEqual(err, plan.ErrNoDB) {
+			// There is no stored function without the current database, try the UDF.
+			return false, nil
+		}
+		return false, errors.Trace(err)
+	}
+	exists, err := routineExists(e.ctx, ast.RoutineFunction, dbInfo.Name.O, s.FunctionName.O)
+	if !exists || err != nil {
+		return false, errors.Trace(err)
+	}
+	return true, errors.Trace(deleteRoutine(e.ctx, ast.RoutineFunction, dbInfo.Name.O, s.FunctionName.O))
+}
+
+func (e *SimpleExec) executeCall(goCtx goctx.Context, s *ast.CallStmt) error {
+	recordSets, err := e.ctx.(sqlexec.RoutineExecutor).CallProcedure(goCtx, s)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, rs := range recordSets {
+		if err = rs.Close(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if len(recordSets) > 0 {
+		// The result sets can only be returned by CALL as a top-level statement.
+		return ErrBadSelect.GenByArgs(s.Name.O)
+	}
+	return nil
+}
+
+func (e *ShowExec) fetchShowProcedureStatus() error {
+	tp := ast.RoutineProcedure
+	if e.Tp == ast.ShowFunctionStatus {
+		tp = ast.RoutineFunction
+	}
+	sql := fmt.Sprintf(`SELECT db, name, type, definer, modified, created, security_type, comment,
+		character_set_client, collation_connection, db_collation FROM %s.proc WHERE type = '%s' ORDER BY db, name`,
+		mysql.SystemDB, tp)
+	rows, _, err := e.ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(e.ctx, sql)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, row := range rows {
+		e.appendRow([]interface{}{
+			row.GetString(0),
+			row.GetString(1),
+			row.GetEnum(2).String(),
+			row.GetString(3),
+			row.GetTime(4),
+			row.GetTime(5),
+			row.GetEnum(6).String(),
+			row.GetString(7),
+			row.GetString(8),
+			row.GetString(9),
+			row.GetString(10),
+		})
+	}
+	return nil
+}
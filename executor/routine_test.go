@@ -0,0 +1,329 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor_test
+
+import (
+	"github.com/juju/errors"
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/domain"
+	"github.com/pingcap/tidb/executor"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/util/testkit"
+	goctx "golang.org/x/net/context"
+)
+
+func (s *testSuite) TestStoredProcedure(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("create database routine_proc")
+	tk.MustExec("use routine_proc")
+	tk.MustExec("create table t (a int primary key, b varchar(20))")
+	tk.MustExec(`create procedure add_row(in x int, inout cnt int, out msg varchar(20))
+	begin
+		declare y varchar(20) default concat('row', x);
+		insert into t values (x, y);
+		set cnt = cnt + 1;
+		select count(*) into msg from t;
+	end`)
+	tk.MustExec("set @cnt = 10")
+	tk.MustExec("call add_row(1, @cnt, @msg)")
+	tk.MustExec("call routine_proc.add_row(2, @cnt, @msg)")
+	tk.MustQuery("select * from t").Check(testkit.Rows("1 row1", "2 row2"))
+	tk.MustQuery("select @cnt, @msg").Check(testkit.Rows("12 2"))
+
+	// The statement errors are returned by CALL.
+	_, err := tk.Exec("call add_row(1, @cnt, @msg)")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("call add_row(3)")
+	c.Assert(executor.ErrWrongNoOfArgs.Equal(errors.Cause(err)), IsTrue)
+	_, err = tk.Exec("call add_row(3, 1, @msg)")
+	c.Assert(executor.ErrNotVarArg.Equal(errors.Cause(err)), IsTrue)
+	_, err = tk.Exec("call no_such_proc()")
+	c.Assert(executor.ErrRoutineNotExists.Equal(errors.Cause(err)), IsTrue)
+
+	// IF, WHILE, REPEAT and LOOP with labels.
+	tk.MustExec(`create procedure loops(n int, out r varchar(100))
+	begin
+		declare i int default 0;
+		set r = '';
+		while i < n do
+			set i = i + 1;
+			if i % 2 = 0 then
+				set r = concat(r, 'e');
+			elseif i = 3 then
+				set r = concat(r, 't');
+			else
+				set r = concat(r, 'o');
+			end if;
+		end while;
+		repeat
+			set i = i - 1;
+		until i <= 2 end repeat;
+		l: loop
+			set i = i - 1;
+			if i > 0 then
+				iterate l;
+			end if;
+			leave l;
+		end loop l;
+		set r = concat(r, i);
+	end`)
+	tk.MustExec("call loops(5, @r)")
+	tk.MustQuery("select @r").Check(testkit.Rows("oeteo0"))
+
+	// The result sets of the statements are returned in order.
+	tk.MustExec(`create procedure results()
+	begin
+		select a from t order by a;
+		update t set b = 'x' where a = 1;
+		select b from t where a = 1;
+	end`)
+	tk.Se.GetSessionVars().ClientCapability |= mysql.ClientMultiResults
+	rss, err := tk.Se.Execute(goctx.Background(), "call results()")
+	c.Assert(err, IsNil)
+	c.Assert(rss, HasLen, 2)
+	rows, err := tidb.GetRows4Test(goctx.Background(), rss[0])
+	c.Assert(err, IsNil)
+	c.Assert(rows, HasLen, 2)
+	c.Assert(rows[1].GetInt64(0), Equals, int64(2))
+	rows, err = tidb.GetRows4Test(goctx.Background(), rss[1])
+	c.Assert(err, IsNil)
+	c.Assert(rows[0].GetString(0), Equals, "x")
+	// A procedure which returns result sets can't be called by another statement.
+	tk.MustExec("create function call_results() returns int begin call results(); return 1; end")
+	rs, err := tk.Exec("select call_results()")
+	c.Assert(err, IsNil)
+	_, err = tidb.GetRows4Test(goctx.Background(), rs)
+	c.Assert(executor.ErrBadSelect.Equal(errors.Cause(err)), IsTrue)
+
+	// A procedure can't call itself.
+	tk.MustExec("create procedure recursive() begin call recursive(); end")
+	_, err = tk.Exec("call recursive()")
+	c.Assert(executor.ErrRecursionLimit.Equal(errors.Cause(err)), IsTrue)
+
+	_, err = tk.Exec("create procedure add_row() begin end")
+	c.Assert(executor.ErrRoutineExists.Equal(errors.Cause(err)), IsTrue)
+	tk.MustExec("drop procedure add_row")
+	_, err = tk.Exec("drop procedure add_row")
+	c.Assert(executor.ErrRoutineNotExists.Equal(errors.Cause(err)), IsTrue)
+	tk.MustExec("drop procedure if exists add_row")
+	c.Assert(tk.Se.GetSessionVars().StmtCtx.WarningCount(), Equals, uint16(1))
+
+	// The routines are dropped with the database.
+	tk.MustExec("create database routine_db")
+	tk.MustExec("create procedure routine_db.p() begin end")
+	tk.MustQuery("select count(*) from mysql.proc where db = 'routine_db'").Check(testkit.Rows("1"))
+	tk.MustExec("drop database routine_db")
+	tk.MustQuery("select count(*) from mysql.proc where db = 'routine_db'").Check(testkit.Rows("0"))
+	tk.MustExec("drop database routine_proc")
+}
+
+func (s *testSuite) TestStoredFunction(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("create database routine_func")
+	tk.MustExec("use routine_func")
+	tk.MustExec("create table t (a int)")
+	tk.MustExec("insert into t values (1), (2), (null)")
+	tk.MustExec(`create function fact(n int) returns bigint deterministic
+	begin
+		declare r bigint default 1;
+		while n > 1 do
+			set r = r * n, n = n - 1;
+		end while;
+		return r;
+	end`)
+	tk.MustExec(`create function total() returns int reads sql data
+	begin
+		declare s int;
+		select sum(a) into s from t;
+		return s;
+	end`)
+	tk.MustQuery("select fact(5), fact('3'), fact(null), total()").Check(testkit.Rows("120 6 1 3"))
+	tk.MustQuery("select a, fact(a + 1) from t where fact(a) > 1").Check(testkit.Rows("2 6"))
+	_, err := tk.Exec("select fact()")
+	c.Assert(err, NotNil)
+
+	// The return value is converted to the return type.
+	tk.MustExec("create function half(x int) returns decimal(5,2) return x / 2")
+	tk.MustQuery("select half(3)").Check(testkit.Rows("1.50"))
+	tk.MustExec("create function no_return(x int) returns int begin if x > 0 then return x; end if; end")
+	tk.MustQuery("select no_return(1)").Check(testkit.Rows("1"))
+	rs, err := tk.Exec("select no_return(0)")
+	c.Assert(err, IsNil)
+	_, err = tidb.GetRows4Test(goctx.Background(), rs)
+	c.Assert(executor.ErrNoReturnEnd.Equal(errors.Cause(err)), IsTrue)
+
+	// The body is checked when the function is created.
+	_, err = tk.Exec("create function f() returns int begin select 1; return 1; end")
+	c.Assert(executor.ErrNoRetset.Equal(errors.Cause(err)), IsTrue)
+	_, err = tk.Exec("create function f() returns int begin end")
+	c.Assert(executor.ErrNoReturn.Equal(errors.Cause(err)), IsTrue)
+	_, err = tk.Exec("create procedure p() begin return 1; end")
+	c.Assert(executor.ErrBadReturn.Equal(errors.Cause(err)), IsTrue)
+	_, err = tk.Exec("create procedure p(a int, A int) begin end")
+	c.Assert(executor.ErrDupParam.Equal(errors.Cause(err)), IsTrue)
+	_, err = tk.Exec("create procedure p() begin declare a int; declare a int; end")
+	c.Assert(executor.ErrDupVar.Equal(errors.Cause(err)), IsTrue)
+	_, err = tk.Exec("create procedure p() begin declare c cursor for select 1; declare a int; end")
+	c.Assert(executor.ErrVarAfterCursorOrHandler.Equal(errors.Cause(err)), IsTrue)
+	_, err = tk.Exec("create procedure p() begin declare continue handler for sqlstate '00000' begin end; end")
+	c.Assert(executor.ErrBadSQLState.Equal(errors.Cause(err)), IsTrue)
+	_, err = tk.Exec("create procedure p() begin leave l; end")
+	c.Assert(executor.ErrLabelMismatch.Equal(errors.Cause(err)), IsTrue)
+	_, err = tk.Exec("create procedure p() begin open c; end")
+	c.Assert(executor.ErrCursorMismatch.Equal(errors.Cause(err)), IsTrue)
+	_, err = tk.Exec("create procedure p() begin select 1 into x; end")
+	c.Assert(plan.ErrUndeclaredVar.Equal(errors.Cause(err)), IsTrue)
+
+	// The statements of the functions run in the transaction of the statement which calls them.
+	tk.MustExec("create table log (x int primary key)")
+	tk.MustExec("create function add_log(x int) returns int modifies sql data begin insert into log values (x); return x; end")
+	tk.MustQuery("select add_log(a) from t where a is not null order by a").Check(testkit.Rows("1", "2"))
+	tk.MustQuery("select x from log order by x").Check(testkit.Rows("1", "2"))
+	rs, err = tk.Exec("select add_log(3) from t")
+	c.Assert(err, IsNil)
+	_, err = tidb.GetRows4Test(goctx.Background(), rs)
+	c.Assert(err, NotNil)
+	c.Assert(rs.Close(), IsNil)
+	tk.MustQuery("select x from log order by x").Check(testkit.Rows("1", "2"))
+	tk.MustExec("begin")
+	tk.MustQuery("select add_log(4)").Check(testkit.Rows("4"))
+	tk.MustExec("rollback")
+	tk.MustQuery("select x from log order by x").Check(testkit.Rows("1", "2"))
+	tk.MustExec("update t set a = add_log(a + 10) where a = 1")
+	tk.MustQuery("select x from log order by x").Check(testkit.Rows("1", "2", "11"))
+
+	// The routines are cached in the domain.
+	dom := domain.GetDomain(tk.Se)
+	c.Assert(dom.Routine(ast.RoutineFunction, "ROUTINE_FUNC", "Fact"), NotNil)
+	c.Assert(dom.Routine(ast.RoutineProcedure, "routine_func", "fact"), IsNil)
+	tk.MustExec("drop function fact")
+	c.Assert(dom.Routine(ast.RoutineFunction, "routine_func", "fact"), IsNil)
+	_, err = tk.Exec("select fact(1)")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("drop function routine_func.fact")
+	c.Assert(executor.ErrRoutineNotExists.Equal(errors.Cause(err)), IsTrue)
+	tk.MustExec("drop database routine_func")
+	c.Assert(dom.Routine(ast.RoutineFunction, "routine_func", "total"), IsNil)
+}
+
+func (s *testSuite) TestRoutineCursorAndHandler(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("create database routine_cursor")
+	tk.MustExec("use routine_cursor")
+	tk.MustExec("create table t (a int primary key, b int)")
+	tk.MustExec("insert into t values (1, 10), (2, 20), (3, 30)")
+	tk.MustExec(`create procedure sum_rows(out total int, out cnt int)
+	begin
+		declare done int default 0;
+		declare x, y int;
+		declare cur cursor for select a, b from t order by a;
+		declare continue handler for not found set done = 1;
+		set total = 0, cnt = 0;
+		open cur;
+		read_loop: loop
+			fetch cur into x, y;
+			if done then
+				leave read_loop;
+			end if;
+			set total = total + x * y, cnt = cnt + 1;
+		end loop;
+		close cur;
+	end`)
+	tk.MustExec("call sum_rows(@total, @cnt)")
+	tk.MustQuery("select @total, @cnt").Check(testkit.Rows("140 3"))
+
+	// An EXIT handler leaves the block, a CONTINUE handler goes on with the next statement.
+	tk.MustExec(`create procedure insert_dup(in x int, out r varchar(20))
+	begin
+		declare continue handler for 1062 set r = concat(r, 'dup;');
+		set r = '';
+		insert into t values (x, 0);
+		set r = concat(r, 'next;');
+		begin
+			declare exit handler for sqlexception set r = concat(r, 'exit;');
+			insert into t values (x, 0);
+			set r = concat(r, 'unreachable;');
+		end;
+		set r = concat(r, 'end');
+	end`)
+	tk.MustExec("call insert_dup(1, @r)")
+	tk.MustQuery("select @r").Check(testkit.Rows("dup;next;exit;end"))
+
+	// The errors of cursors.
+	tk.MustExec(`create procedure bad_cursor(n int)
+	begin
+		declare x int;
+		declare cur cursor for select a from t;
+		if n = 1 then
+			fetch cur into x;
+		elseif n = 2 then
+			open cur;
+			open cur;
+		else
+			open cur;
+			fetch cur into x, x;
+		end if;
+	end`)
+	_, err := tk.Exec("call bad_cursor(1)")
+	c.Assert(executor.ErrCursorNotOpen.Equal(errors.Cause(err)), IsTrue)
+	_, err = tk.Exec("call bad_cursor(2)")
+	c.Assert(executor.ErrCursorAlreadyOpen.Equal(errors.Cause(err)), IsTrue)
+	_, err = tk.Exec("call bad_cursor(3)")
+	c.Assert(executor.ErrWrongNoOfFetchArgs.Equal(errors.Cause(err)), IsTrue)
+
+	// An unhandled FETCH without rows is an error.
+	tk.MustExec(`create procedure fetch_all()
+	begin
+		declare x int;
+		declare cur cursor for select a from t;
+		open cur;
+		loop
+			fetch cur into x;
+		end loop;
+	end`)
+	_, err = tk.Exec("call fetch_all()")
+	c.Assert(executor.ErrNoData.Equal(errors.Cause(err)), IsTrue)
+	tk.MustExec("drop database routine_cursor")
+}
+
+func (s *testSuite) TestShowRoutineStatus(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("create database routine_show")
+	tk.MustExec("use routine_show")
+	tk.MustExec("create procedure p1() comment 'first' begin end")
+	tk.MustExec("create definer = 'root'@'%' procedure p2() sql security invoker select 1")
+	tk.MustExec("create function f1(x int) returns varchar(10) deterministic return concat(x)")
+	result := tk.MustQuery("show procedure status where db = 'routine_show'")
+	c.Assert(result.Rows(), HasLen, 2)
+	c.Assert(result.Rows()[0][1], Equals, "p1")
+	c.Assert(result.Rows()[0][2], Equals, "PROCEDURE")
+	c.Assert(result.Rows()[0][7], Equals, "first")
+	c.Assert(result.Rows()[1][3], Equals, "root@%")
+	c.Assert(result.Rows()[1][6], Equals, "INVOKER")
+	tk.MustQuery("show procedure status like 'p2'").Check(result.Rows()[1:])
+	result = tk.MustQuery("show function status where db = 'routine_show'")
+	c.Assert(result.Rows(), HasLen, 1)
+	c.Assert(result.Rows()[0][1], Equals, "f1")
+
+	tk.MustQuery(`select routine_schema, routine_name, routine_type, data_type, dtd_identifier, routine_body,
+		routine_definition, is_deterministic, sql_data_access, security_type, routine_comment
+		from information_schema.routines where routine_schema = 'routine_show' order by routine_name`).Check(testkit.Rows(
+		"routine_show f1 FUNCTION varchar varchar(10) CHARACTER SET utf8 COLLATE utf8_bin SQL return concat(x) YES CONTAINS SQL DEFINER ",
+		"routine_show p1 PROCEDURE  <nil> SQL begin end NO CONTAINS SQL DEFINER first",
+		"routine_show p2 PROCEDURE  <nil> SQL select 1 NO CONTAINS SQL INVOKER "))
+	tk.MustExec("drop database routine_show")
+}
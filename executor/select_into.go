@@ -0,0 +1,271 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/chunk"
+	goctx "golang.org/x/net/context"
+)
+
+var _ Executor = &SelectIntoExec{}
+
+// SelectIntoExec represents a SelectInto executor.
+// It is built from the "SELECT ... INTO OUTFILE" and "SELECT ... INTO DUMPFILE" statements,
+// it writes the result of its child into a file which must not exist.
+// For "SELECT ... INTO var_list", the single row of its child is assigned to the variables.
+type SelectIntoExec struct {
+	baseExecutor
+
+	intoOpt *ast.SelectIntoOption
+	file    *os.File
+	writer  *bufio.Writer
+	// lineBuf is reused to format every line.
+	lineBuf []byte
+	rows    uint64
+	done    bool
+}
+
+// Open implements the Executor Open interface.
+func (e *SelectIntoExec) Open(goCtx goctx.Context) error {
+	if err := e.baseExecutor.Open(goCtx); err != nil {
+		return errors.Trace(err)
+	}
+	e.rows, e.done = 0, false
+	if e.intoOpt.Tp == ast.SelectIntoVars {
+		return nil
+	}
+	path, err := checkSecureFilePath(e.intoOpt.FileName)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	// Like MySQL, an existing file is never overwritten.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+	if err != nil {
+		if os.IsExist(err) {
+			return ErrFileExists.GenByArgs(e.intoOpt.FileName)
+		}
+		return errors.Trace(err)
+	}
+	e.file = f
+	e.writer = bufio.NewWriter(f)
+	return nil
+}
+
+// Next implements the Executor Next interface.
+func (e *SelectIntoExec) Next(goCtx goctx.Context) (Row, error) {
+	if e.done {
+		return nil, nil
+	}
+	for {
+		row, err := e.children[0].Next(goCtx)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if row == nil {
+			break
+		}
+		if err = e.dumpRow(row); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return nil, errors.Trace(e.finish())
+}
+
+// NextChunk implements the Executor NextChunk interface.
+func (e *SelectIntoExec) NextChunk(goCtx goctx.Context, chk *chunk.Chunk) error {
+	chk.Reset()
+	if e.done {
+		return nil
+	}
+	child := e.children[0]
+	fields := child.Schema().GetTypes()
+	childChk := child.newChunk()
+	row := make(Row, len(fields))
+	for {
+		err := child.NextChunk(goCtx, childChk)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if childChk.NumRows() == 0 {
+			break
+		}
+		for r := childChk.Begin(); r != childChk.End(); r = r.Next() {
+			for i, tp := range fields {
+				row[i] = r.GetDatum(i, tp)
+			}
+			if err = e.dumpRow(row); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+	return errors.Trace(e.finish())
+}
+
+// Close implements the Executor Close interface.
+func (e *SelectIntoExec) Close() error {
+	if e.file != nil {
+		if err := e.file.Close(); err != nil {
+			return errors.Trace(err)
+		}
+		e.file = nil
+	}
+	return errors.Trace(e.baseExecutor.Close())
+}
+
+func (e *SelectIntoExec) finish() error {
+	e.done = true
+	sc := e.ctx.GetSessionVars().StmtCtx
+	if e.intoOpt.Tp == ast.SelectIntoVars {
+		if e.rows == 0 {
+			sc.AppendWarning(ErrNoData)
+		}
+		return nil
+	}
+	if err := e.writer.Flush(); err != nil {
+		return errors.Trace(err)
+	}
+	sc.AddAffectedRows(e.rows)
+	return nil
+}
+
+func (e *SelectIntoExec) dumpRow(row Row) error {
+	e.rows++
+	if e.intoOpt.Tp == ast.SelectIntoVars {
+		if e.rows > 1 {
+			return ErrTooManyRows
+		}
+		return errors.Trace(e.assignVars(row))
+	}
+	if e.intoOpt.Tp == ast.SelectIntoDumpfile {
+		// DUMPFILE writes a single row without any formatting.
+		if e.rows > 1 {
+			return ErrTooManyRows
+		}
+		for _, d := range row {
+			if d.IsNull() {
+				continue
+			}
+			s, err := d.ToString()
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if _, err = e.writer.WriteString(s); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		return nil
+	}
+
+	fields, lines := e.intoOpt.FieldsInfo, e.intoOpt.LinesInfo
+	buf := append(e.lineBuf[:0], lines.Starting...)
+	for i, d := range row {
+		if i > 0 {
+			buf = append(buf, fields.Terminated...)
+		}
+		if d.IsNull() {
+			if fields.Escaped != 0 {
+				buf = append(buf, fields.Escaped, 'N')
+			} else {
+				buf = append(buf, "NULL"...)
+			}
+			continue
+		}
+		s, err := d.ToString()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if fields.Enclosed != 0 {
+			buf = append(buf, fields.Enclosed)
+		}
+		buf = e.appendEscaped(buf, s, d.Kind())
+		if fields.Enclosed != 0 {
+			buf = append(buf, fields.Enclosed)
+		}
+	}
+	buf = append(buf, lines.Terminated...)
+	e.lineBuf = buf
+	_, err := e.writer.Write(buf)
+	return errors.Trace(err)
+}
+
+// appendEscaped appends the field value to buf, the characters which make the file ambiguous to
+// read back by LOAD DATA are prefixed with the escape character. Like MySQL, only the numbers
+// aren't escaped.
+func (e *SelectIntoExec) appendEscaped(buf []byte, s string, kind byte) []byte {
+	fields, lines := e.intoOpt.FieldsInfo, e.intoOpt.LinesInfo
+	if fields.Escaped == 0 || isNumericKind(kind) {
+		return append(buf, s...)
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == 0:
+			buf = append(buf, fields.Escaped, '0')
+			continue
+		case c == fields.Escaped,
+			fields.Enclosed != 0 && c == fields.Enclosed,
+			// The terminators inside an enclosed field are not ambiguous.
+			fields.Enclosed == 0 && len(fields.Terminated) > 0 && c == fields.Terminated[0],
+			fields.Enclosed == 0 && len(lines.Terminated) > 0 && c == lines.Terminated[0]:
+			buf = append(buf, fields.Escaped)
+		}
+		buf = append(buf, c)
+	}
+	return buf
+}
+
+func isNumericKind(kind byte) bool {
+	switch kind {
+	case types.KindInt64, types.KindUint64, types.KindFloat32, types.KindFloat64, types.KindMysqlDecimal:
+		return true
+	}
+	return false
+}
+
+// assignVars assigns the values of row to the user variables and the local variables of INTO.
+func (e *SelectIntoExec) assignVars(row Row) error {
+	sessionVars := e.ctx.GetSessionVars()
+	for i, v := range e.intoOpt.Vars {
+		switch x := v.(type) {
+		case *ast.VariableExpr:
+			name := strings.ToLower(x.Name)
+			if row[i].IsNull() {
+				delete(sessionVars.Users, name)
+				continue
+			}
+			s, err := row[i].ToString()
+			if err != nil {
+				return errors.Trace(err)
+			}
+			sessionVars.Users[name] = s
+		case *ast.ColumnNameExpr:
+			rv, ok := sessionVars.RoutineVars.Get(x.Name.Name.L)
+			if !ok {
+				return plan.ErrUndeclaredVar.GenByArgs(x.Name.Name.O)
+			}
+			if err := rv.SetValue(sessionVars.StmtCtx, row[i]); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+	return nil
+}
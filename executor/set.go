@@ -108,6 +108,21 @@ func (e *SetExecutor) executeSet() error {
 			continue
 		}
 
+		if rv, ok := sessionVars.RoutineVars.Get(name); ok && !v.IsGlobal {
+			// Set local variable of the stored routine, which hides the system variable.
+			if v.IsDefault {
+				return errors.Errorf("Variable '%s' doesn't have a default value", name)
+			}
+			value, err := v.Expr.Eval(nil)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if err = rv.SetValue(sessionVars.StmtCtx, value); err != nil {
+				return errors.Trace(err)
+			}
+			continue
+		}
+
 		// Set system variable
 		sysVar := variable.GetSysVar(name)
 		if sysVar == nil {
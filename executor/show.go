@@ -134,7 +134,7 @@ func (e *ShowExec) fetchAll() error {
 		return e.fetchShowGrants()
 	case ast.ShowIndex:
 		return e.fetchShowIndex()
-	case ast.ShowProcedureStatus:
+	case ast.ShowProcedureStatus, ast.ShowFunctionStatus:
 		return e.fetchShowProcedureStatus()
 	case ast.ShowStatus:
 		return e.fetchShowStatus()
@@ -283,6 +283,9 @@ func (e *ShowExec) fetchShowColumns() error {
 		if e.Column != nil && e.Column.Name.L != col.Name.L {
 			continue
 		}
+		if col.Hidden {
+			continue
+		}
 
 		desc := table.NewColDesc(col)
 
@@ -343,6 +346,7 @@ func (e *ShowExec) fetchShowIndex() error {
 			"BTREE",          // Index_type
 			"",               // Comment
 			"",               // Index_comment
+			"YES",            // Visible
 		})
 	}
 	for _, idx := range tb.Indices() {
@@ -355,20 +359,30 @@ func (e *ShowExec) fetchShowIndex() error {
 			if col.Length != types.UnspecifiedLength {
 				subPart = col.Length
 			}
+			visible := "YES"
+			if idx.Meta().Invisible {
+				visible = "NO"
+			}
+			// The key part which is an expression has no column name.
+			var colName interface{} = col.Name.O
+			if c := table.FindCol(tb.Cols(), col.Name.L); c != nil && c.Hidden {
+				colName = nil
+			}
 			e.appendRow([]interface{}{
-				tb.Meta().Name.O,  // Table
-				nonUniq,           // Non_unique
-				idx.Meta().Name.O, // Key_name
-				i + 1,             // Seq_in_index
-				col.Name.O,        // Column_name
-				"A",               // Collation
-				0,                 // Cardinality
-				subPart,           // Sub_part
-				nil,               // Packed
-				"YES",             // Null
+				tb.Meta().Name.O,       // Table
+				nonUniq,                // Non_unique
+				idx.Meta().Name.O,      // Key_name
+				i + 1,                  // Seq_in_index
+				colName,                // Column_name
+				"A",                    // Collation
+				0,                      // Cardinality
+				subPart,                // Sub_part
+				nil,                    // Packed
+				"YES",                  // Null
 				idx.Meta().Tp.String(), // Index_type
-				"",                 // Comment
-				idx.Meta().Comment, // Index_comment
+				"",                     // Comment
+				idx.Meta().Comment,     // Index_comment
+				visible,                // Visible
 			})
 		}
 	}
@@ -469,11 +483,20 @@ func (e *ShowExec) fetchShowCreateTable() error {
 	buf.WriteString(fmt.Sprintf("CREATE TABLE `%s` (\n", tb.Meta().Name.O))
 	var pkCol *table.Column
 	var hasAutoIncID bool
-	for i, col := range tb.Cols() {
-		if col.State != model.StatePublic {
-			continue
+	// The hidden columns are shown as the expressions of the indices.
+	cols := make([]*table.Column, 0, len(tb.Cols()))
+	for _, col := range tb.Cols() {
+		if col.State == model.StatePublic && !col.Hidden {
+			cols = append(cols, col)
 		}
+	}
+	for i, col := range cols {
 		buf.WriteString(fmt.Sprintf("  `%s` %s", col.Name.O, col.GetTypeDesc()))
+		if col.Collate != "" && col.Charset != charset.CharsetBin {
+			if defaultCollate, err := charset.GetDefaultCollation(col.Charset); err == nil && defaultCollate != col.Collate {
+				buf.WriteString(fmt.Sprintf(" COLLATE %s", col.Collate))
+			}
+		}
 		if col.IsGenerated() {
 			// It's a generated column.
 			buf.WriteString(fmt.Sprintf(" GENERATED ALWAYS AS (%s)", col.GeneratedExprString))
@@ -518,7 +541,7 @@ func (e *ShowExec) fetchShowCreateTable() error {
 		if len(col.Comment) > 0 {
 			buf.WriteString(fmt.Sprintf(" COMMENT '%s'", format.OutputFormat(col.Comment)))
 		}
-		if i != len(tb.Cols())-1 {
+		if i != len(cols)-1 {
 			buf.WriteString(",\n")
 		}
 		if tb.Meta().PKIsHandle && mysql.HasPriKeyFlag(col.Flag) {
@@ -551,6 +574,10 @@ func (e *ShowExec) fetchShowCreateTable() error {
 
 		cols := make([]string, 0, len(idxInfo.Columns))
 		for _, c := range idxInfo.Columns {
+			if col := table.FindCol(tb.Cols(), c.Name.L); col != nil && col.Hidden {
+				cols = append(cols, fmt.Sprintf("(%s)", col.GeneratedExprString))
+				continue
+			}
 			colInfo := fmt.Sprintf("`%s`", c.Name.String())
 			if c.Length != types.UnspecifiedLength {
 				colInfo = fmt.Sprintf("%s(%s)", colInfo, strconv.Itoa(c.Length))
@@ -597,6 +624,14 @@ func (e *ShowExec) fetchShowCreateTable() error {
 			buf.WriteString(fmt.Sprintf(" ON UPDATE %s", ast.ReferOptionType(fk.OnUpdate)))
 		}
 	}
+
+	for _, c := range tb.Meta().Constraints {
+		if c.State != model.StatePublic {
+			continue
+		}
+		buf.WriteString(",\n")
+		buf.WriteString(fmt.Sprintf("  CONSTRAINT `%s` CHECK (%s)", c.Name.O, c.ExprString))
+	}
 	buf.WriteString("\n")
 
 	buf.WriteString(") ENGINE=InnoDB")
@@ -687,10 +722,6 @@ func (e *ShowExec) fetchShowTriggers() error {
 	return nil
 }
 
-func (e *ShowExec) fetchShowProcedureStatus() error {
-	return nil
-}
-
 func (e *ShowExec) fetchShowPlugins() error {
 	return nil
 }
@@ -115,15 +115,15 @@ func (s *testSuite) TestShow(c *C) {
 	tk.MustExec(`create index idx7 on show_index (id);`)
 	testSQL = "SHOW index from show_index;"
 	tk.MustQuery(testSQL).Check(testutil.RowsWithSep("|",
-		"show_index|0|PRIMARY|1|id|A|0|<nil>|<nil>||BTREE||",
-		"show_index|1|cIdx|1|c|A|0|<nil>|<nil>|YES|HASH||index_comment_for_cIdx",
-		"show_index|1|idx1|1|id|A|0|<nil>|<nil>|YES|HASH||",
-		"show_index|1|idx2|1|id|A|0|<nil>|<nil>|YES|BTREE||idx",
-		"show_index|1|idx3|1|id|A|0|<nil>|<nil>|YES|HASH||idx",
-		"show_index|1|idx4|1|id|A|0|<nil>|<nil>|YES|BTREE||idx",
-		"show_index|1|idx5|1|id|A|0|<nil>|<nil>|YES|BTREE||idx",
-		"show_index|1|idx6|1|id|A|0|<nil>|<nil>|YES|HASH||",
-		"show_index|1|idx7|1|id|A|0|<nil>|<nil>|YES|BTREE||",
+		"show_index|0|PRIMARY|1|id|A|0|<nil>|<nil>||BTREE|||YES",
+		"show_index|1|cIdx|1|c|A|0|<nil>|<nil>|YES|HASH||index_comment_for_cIdx|YES",
+		"show_index|1|idx1|1|id|A|0|<nil>|<nil>|YES|HASH|||YES",
+		"show_index|1|idx2|1|id|A|0|<nil>|<nil>|YES|BTREE||idx|YES",
+		"show_index|1|idx3|1|id|A|0|<nil>|<nil>|YES|HASH||idx|YES",
+		"show_index|1|idx4|1|id|A|0|<nil>|<nil>|YES|BTREE||idx|YES",
+		"show_index|1|idx5|1|id|A|0|<nil>|<nil>|YES|BTREE||idx|YES",
+		"show_index|1|idx6|1|id|A|0|<nil>|<nil>|YES|HASH|||YES",
+		"show_index|1|idx7|1|id|A|0|<nil>|<nil>|YES|BTREE|||YES",
 	))
 
 	// For show like with escape
@@ -22,11 +22,13 @@ import (
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/ddl/util"
 	"github.com/pingcap/tidb/domain"
+	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/udf"
 	"github.com/pingcap/tidb/util/auth"
 	"github.com/pingcap/tidb/util/chunk"
 	"github.com/pingcap/tidb/util/sqlexec"
@@ -87,6 +89,16 @@ func (e *SimpleExec) run(goCtx goctx.Context) (err error) {
 		return nil
 	case *ast.DropStatsStmt:
 		err = e.executeDropStats(x)
+	case *ast.CreateFunctionStmt:
+		err = e.executeCreateFunction(x)
+	case *ast.DropFunctionStmt:
+		err = e.executeDropFunction(x)
+	case *ast.CreateRoutineStmt:
+		err = e.executeCreateRoutine(x)
+	case *ast.DropProcedureStmt:
+		err = e.executeDropProcedure(x)
+	case *ast.CallStmt:
+		err = e.executeCall(goCtx, x)
 	}
 	e.done = true
 	return errors.Trace(err)
@@ -338,3 +350,77 @@ func (e *SimpleExec) executeDropStats(s *ast.DropStatsStmt) error {
 	h.DDLEventCh() <- &util.Event{Tp: model.ActionDropTable, TableInfo: s.Table.TableInfo}
 	return nil
 }
+
+func (e *SimpleExec) executeCreateFunction(s *ast.CreateFunctionStmt) error {
+	name := s.FunctionName.L
+	if expression.IsBuiltinFunc(name) {
+		return udf.ErrNativeNameCollision.GenByArgs(s.FunctionName.O)
+	}
+	exists, err := udfExists(e.ctx, name)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if exists {
+		return udf.ErrExists.GenByArgs(s.FunctionName.O)
+	}
+	retTp := udf.TypeFromEvalType(s.ReturnType)
+	fn, err := udf.Load(name, s.SharedLib, retTp)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	sql := fmt.Sprintf(`INSERT INTO %s.func (name, ret, dl, type) VALUES (%s, %d, %s, "function");`,
+		mysql.SystemDB, quoteString(name), retTp, quoteString(s.SharedLib))
+	_, err = e.ctx.(sqlexec.SQLExecutor).Execute(goctx.Background(), sql)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	udf.Register(name, fn)
+	domain.GetDomain(e.ctx).NotifyUpdateUDF(e.ctx)
+	return nil
+}
+
+func (e *SimpleExec) executeDropFunction(s *ast.DropFunctionStmt) error {
+	dropped, err := e.dropStoredFunction(s)
+	if dropped || err != nil {
+		return errors.Trace(err)
+	}
+	if s.Schema.L != "" {
+		// UDFs don't belong to any database.
+		err = ErrRoutineNotExists.GenByArgs("FUNCTION", s.Schema.O+"."+s.FunctionName.O)
+		if s.IfExists {
+			e.ctx.GetSessionVars().StmtCtx.AppendWarning(err)
+			return nil
+		}
+		return err
+	}
+	name := s.FunctionName.L
+	exists, err := udfExists(e.ctx, name)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !exists {
+		err = udf.ErrNotExists.GenByArgs("FUNCTION", s.FunctionName.O)
+		if s.IfExists {
+			e.ctx.GetSessionVars().StmtCtx.AppendWarning(err)
+			return nil
+		}
+		return err
+	}
+	sql := fmt.Sprintf(`DELETE FROM %s.func WHERE name = %s;`, mysql.SystemDB, quoteString(name))
+	_, err = e.ctx.(sqlexec.SQLExecutor).Execute(goctx.Background(), sql)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	udf.Unregister(name)
+	domain.GetDomain(e.ctx).NotifyUpdateUDF(e.ctx)
+	return nil
+}
+
+func udfExists(ctx context.Context, name string) (bool, error) {
+	sql := fmt.Sprintf(`SELECT * FROM %s.func WHERE name = %s;`, mysql.SystemDB, quoteString(name))
+	rows, _, err := ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(ctx, sql)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	return len(rows) > 0, nil
+}
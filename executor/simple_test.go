@@ -14,6 +14,9 @@
 package executor_test
 
 import (
+	"fmt"
+
+	"github.com/juju/errors"
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb"
 	"github.com/pingcap/tidb/context"
@@ -23,6 +26,7 @@ import (
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/privilege/privileges"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/udf"
 	"github.com/pingcap/tidb/util/auth"
 	"github.com/pingcap/tidb/util/testkit"
 	goctx "golang.org/x/net/context"
@@ -261,3 +265,97 @@ func (s *testSuite) TestDropStats(c *C) {
 	statsTbl = h.GetTableStats(tableInfo.ID)
 	c.Assert(statsTbl.Pseudo, IsTrue)
 }
+
+// testUDF is a user-defined function implemented in the test, because the tests can't build plugins.
+type testUDF struct {
+	argTps []udf.Type
+	retTp  udf.Type
+	eval   func(args []interface{}) interface{}
+}
+
+func (f *testUDF) ArgTypes() []udf.Type {
+	return f.argTps
+}
+
+func (f *testUDF) RetType() udf.Type {
+	return f.retTp
+}
+
+func (f *testUDF) Eval(args []interface{}) (interface{}, error) {
+	return f.eval(args), nil
+}
+
+func testUDFLoader(name, lib string) (udf.Function, error) {
+	if lib != "udf_test.so" && lib != `udf_"test'.so` {
+		return nil, udf.ErrCantOpenLibrary.GenByArgs(lib, 0, "no such file")
+	}
+	switch name {
+	case "udf_add":
+		return &testUDF{argTps: []udf.Type{udf.TypeInt, udf.TypeInt}, retTp: udf.TypeInt, eval: func(args []interface{}) interface{} {
+			if args[0] == nil || args[1] == nil {
+				return nil
+			}
+			return args[0].(int64) + args[1].(int64)
+		}}, nil
+	case `udf_"quoted'\`:
+		return &testUDF{retTp: udf.TypeString, eval: func(args []interface{}) interface{} {
+			return "quoted"
+		}}, nil
+	case "udf_label":
+		return &testUDF{argTps: []udf.Type{udf.TypeString, udf.TypeDecimal}, retTp: udf.TypeString, eval: func(args []interface{}) interface{} {
+			return fmt.Sprintf("%v:%v", args[0], args[1])
+		}}, nil
+	}
+	return nil, udf.ErrCantInitialize.GenByArgs(name, "unknown function")
+}
+
+func (s *testSuite) TestUDF(c *C) {
+	origLoader := udf.Loader
+	udf.Loader = testUDFLoader
+	defer func() { udf.Loader = origLoader }()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("create function udf_add returns integer soname 'udf_test.so'")
+	tk.MustExec("create function UDF_LABEL returns string soname 'udf_test.so'")
+	tk.MustQuery("select name, ret, dl, type from mysql.func").Sort().Check(testkit.Rows(
+		"udf_add 2 udf_test.so function", "udf_label 0 udf_test.so function"))
+
+	tk.MustExec("create table t (a int, b decimal(4,2))")
+	tk.MustExec("insert into t values (1, 1.5), (2, null), (null, 3)")
+	tk.MustQuery("select udf_add(a, '10'), udf_label(a, b) from t").Check(testkit.Rows(
+		"11 1:1.50", "12 2:<nil>", "<nil> <nil>:3.00"))
+	tk.MustQuery("select a from t where udf_add(a, a) = 4").Check(testkit.Rows("2"))
+	_, err := tk.Exec("select udf_add(1)")
+	c.Assert(udf.ErrCantInitialize.Equal(errors.Cause(err)), IsTrue)
+
+	_, err = tk.Exec("create function udf_add returns integer soname 'udf_test.so'")
+	c.Assert(udf.ErrExists.Equal(errors.Cause(err)), IsTrue)
+	_, err = tk.Exec("create function concat returns string soname 'udf_test.so'")
+	c.Assert(udf.ErrNativeNameCollision.Equal(errors.Cause(err)), IsTrue)
+	_, err = tk.Exec("create function udf_other returns string soname '../udf_test.so'")
+	c.Assert(udf.ErrNoPaths.Equal(errors.Cause(err)), IsTrue)
+	_, err = tk.Exec("create function udf_other returns string soname 'other.so'")
+	c.Assert(udf.ErrCantOpenLibrary.Equal(errors.Cause(err)), IsTrue)
+	tk.MustExec("drop function udf_label")
+	// The declared return type must match the function.
+	_, err = tk.Exec("create function udf_label returns real soname 'udf_test.so'")
+	c.Assert(udf.ErrCantInitialize.Equal(errors.Cause(err)), IsTrue)
+
+	tk.MustExec("drop function udf_add")
+	_, err = tk.Exec("select udf_add(1, 2)")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("drop function udf_add")
+	c.Assert(udf.ErrNotExists.Equal(errors.Cause(err)), IsTrue)
+	tk.MustExec("drop function if exists udf_add")
+	c.Assert(tk.Se.GetSessionVars().StmtCtx.WarningCount(), Equals, uint16(1))
+	tk.MustQuery("select count(*) from mysql.func").Check(testkit.Rows("0"))
+
+	// The names are quoted in the statements on mysql.func.
+	tk.MustExec("create function `udf_\"quoted'\\` returns string soname 'udf_\"test\\'.so'")
+	tk.MustQuery("select name, dl from mysql.func").Check(testkit.Rows(`udf_"quoted'\ udf_"test'.so`))
+	tk.MustQuery("select `udf_\"quoted'\\`()").Check(testkit.Rows("quoted"))
+	_, err = tk.Exec("create function `udf_\"quoted'\\` returns string soname 'udf_test.so'")
+	c.Assert(udf.ErrExists.Equal(errors.Cause(err)), IsTrue)
+	tk.MustExec("drop function `udf_\"quoted'\\`")
+	tk.MustQuery("select count(*) from mysql.func").Check(testkit.Rows("0"))
+}
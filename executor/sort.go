@@ -127,6 +127,7 @@ func (e *SortExec) Next(goCtx goctx.Context) (Row, error) {
 				if err != nil {
 					return nil, errors.Trace(err)
 				}
+				key = expression.CollationKeyDatum(key, byItem.Expr.GetType())
 				orderRow.key[i] = &key
 			}
 			e.Rows = append(e.Rows, orderRow)
@@ -368,6 +369,7 @@ func (e *TopNExec) Next(goCtx goctx.Context) (Row, error) {
 				if err != nil {
 					return nil, errors.Trace(err)
 				}
+				key = expression.CollationKeyDatum(key, byItem.Expr.GetType())
 				orderRow.key[i] = &key
 			}
 			if e.totalLimit == e.heapSize {
@@ -231,8 +231,9 @@ func (us *UnionScanExec) shouldPickFirstRow(a, b Row) (bool, error) {
 func (us *UnionScanExec) compare(a, b Row) (int, error) {
 	sc := us.ctx.GetSessionVars().StmtCtx
 	for _, colOff := range us.usedIndex {
-		aColumn := a[colOff]
-		bColumn := b[colOff]
+		tp := us.schema.Columns[colOff].RetType
+		aColumn := expression.CollationKeyDatum(a[colOff], tp)
+		bColumn := expression.CollationKeyDatum(b[colOff], tp)
 		cmp, err := aColumn.CompareDatum(sc, &bColumn)
 		if err != nil {
 			return 0, errors.Trace(err)
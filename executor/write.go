@@ -16,16 +16,21 @@ package executor
 import (
 	"bytes"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/table/tables"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/chunk"
 	log "github.com/sirupsen/logrus"
@@ -43,9 +48,11 @@ var (
 // updateRecord updates the row specified by the handle `h`, from `oldData` to `newData`.
 // `modified` means which columns are really modified. It's used for secondary indices.
 // Length of `oldData` and `newData` equals to length of `t.WritableCols()`.
+// `checks` are the CHECK constraints of the table which the new row must satisfy.
 // ignoreErr indicate that update statement has the `IGNORE` modifier, in this situation, update statement will not update
 // the keys which cause duplicate conflicts and ignore the error.
-func updateRecord(ctx context.Context, h int64, oldData, newData []types.Datum, modified []bool, t table.Table, onDup, ignoreErr bool) (bool, error) {
+func updateRecord(ctx context.Context, h int64, oldData, newData []types.Datum, modified []bool, t table.Table,
+	checks *tables.CheckConstraints, onDup, ignoreErr bool) (bool, error) {
 	var sc = ctx.GetSessionVars().StmtCtx
 	var changed, handleChanged = false, false
 	// onUpdateSpecified is for "UPDATE SET ts_field = old_value", the
@@ -126,6 +133,11 @@ func updateRecord(ctx context.Context, h int64, oldData, newData []types.Datum,
 		}
 	}
 
+	// Check the CHECK constraints.
+	if err = checks.Check(newData); err != nil {
+		return false, errors.Trace(err)
+	}
+
 	if handleChanged {
 		skipHandleCheck := false
 		if ignoreErr {
@@ -480,12 +492,22 @@ type LoadDataInfo struct {
 	row       []types.Datum
 	insertVal *InsertValues
 
-	Path       string
-	Table      table.Table
-	FieldsInfo *ast.FieldsClause
-	LinesInfo  *ast.LinesClause
-	Ctx        context.Context
-	columns    []*table.Column
+	Path               string
+	Table              table.Table
+	OnDuplicate        ast.OnDuplicateKeyHandlingType
+	ColumnsAndUserVars []*ast.ColumnNameOrUserVar
+	ColumnAssignments  []*expression.Assignment
+	FieldsInfo         *ast.FieldsClause
+	LinesInfo          *ast.LinesClause
+	IgnoreLines        uint64
+	Ctx                context.Context
+	// columns are the columns of the fields, followed by the columns of ColumnAssignments and the generated columns.
+	columns []*table.Column
+	// ignoredLines is the number of the lines skipped by IgnoreLines.
+	ignoredLines uint64
+	// abortOnErr means the statement fails on the first row that can't be inserted,
+	// otherwise the error is appended as a warning and the row is skipped.
+	abortOnErr bool
 }
 
 // SetBatchCount sets the number of rows to insert in a batch.
@@ -626,11 +648,17 @@ func (e *LoadDataInfo) InsertData(prevData, curData []byte) ([]byte, bool, error
 			curData = nil
 		}
 
+		if e.ignoredLines < e.IgnoreLines {
+			e.ignoredLines++
+			continue
+		}
 		cols, err := GetFieldsFromLine(line, e.FieldsInfo)
 		if err != nil {
 			return nil, false, errors.Trace(err)
 		}
-		e.insertData(cols)
+		if err = e.insertData(cols); err != nil {
+			return nil, false, errors.Trace(err)
+		}
 		e.insertVal.currRow++
 		if e.insertVal.batchRows != 0 && e.insertVal.currRow%e.insertVal.batchRows == 0 {
 			reachLimit = true
@@ -714,25 +742,103 @@ func escapeChar(c byte) byte {
 	return c
 }
 
-func (e *LoadDataInfo) insertData(cols []string) {
-	for i := 0; i < len(e.row); i++ {
-		if i >= len(cols) {
-			e.row[i].SetString("")
-			continue
+func (e *LoadDataInfo) insertData(cols []string) error {
+	if len(e.ColumnsAndUserVars) == 0 {
+		for i := 0; i < len(e.row); i++ {
+			if i >= len(cols) {
+				e.row[i].SetString("")
+				continue
+			}
+			e.row[i].SetString(cols[i])
 		}
-		e.row[i].SetString(cols[i])
+	} else {
+		e.setFieldValues(cols)
 	}
-	row, err := e.insertVal.fillRowData(e.columns, e.row, true)
+	vals := e.row
+	if len(e.ColumnAssignments) > 0 {
+		var err error
+		vals, err = e.evalAssignments()
+		if err != nil {
+			warnLog := fmt.Sprintf("Load Data: insert data:%v failed:%v", e.row, errors.ErrorStack(err))
+			return e.handleInsertErr(err, warnLog)
+		}
+	}
+	row, err := e.insertVal.fillRowData(e.columns, vals, true)
 	if err != nil {
-		warnLog := fmt.Sprintf("Load Data: insert data:%v failed:%v", e.row, errors.ErrorStack(err))
-		e.insertVal.handleLoadDataWarnings(err, warnLog)
-		return
+		warnLog := fmt.Sprintf("Load Data: insert data:%v failed:%v", vals, errors.ErrorStack(err))
+		return e.handleInsertErr(err, warnLog)
+	}
+	if err = e.insertVal.checkRow(row); err != nil {
+		warnLog := fmt.Sprintf("Load Data: insert data:%v failed:%v", row, errors.ErrorStack(err))
+		return e.handleInsertErr(err, warnLog)
+	}
+	if e.OnDuplicate == ast.OnDuplicateKeyHandlingReplace {
+		err = e.insertVal.replaceRow(row)
+	} else {
+		_, err = e.Table.AddRecord(e.insertVal.ctx, row, false)
 	}
-	_, err = e.Table.AddRecord(e.insertVal.ctx, row, false)
 	if err != nil {
 		warnLog := fmt.Sprintf("Load Data: insert data:%v failed:%v", row, errors.ErrorStack(err))
-		e.insertVal.handleLoadDataWarnings(err, warnLog)
+		return e.handleInsertErr(err, warnLog)
 	}
+	return nil
+}
+
+// setFieldValues sets the fields of a line to the columns and the user variables they are read into.
+func (e *LoadDataInfo) setFieldValues(cols []string) {
+	sessionVars := e.Ctx.GetSessionVars()
+	colIdx := 0
+	for i, v := range e.ColumnsAndUserVars {
+		if v.UserVar != nil {
+			name := strings.ToLower(v.UserVar.Name)
+			sessionVars.UsersLock.Lock()
+			if i >= len(cols) {
+				delete(sessionVars.Users, name)
+			} else {
+				sessionVars.Users[name] = cols[i]
+			}
+			sessionVars.UsersLock.Unlock()
+			continue
+		}
+		if i >= len(cols) {
+			e.row[colIdx].SetString("")
+		} else {
+			e.row[colIdx].SetString(cols[i])
+		}
+		colIdx++
+	}
+}
+
+// evalAssignments evaluates the SET clause on the row of the fields,
+// it returns the values of the fields followed by the assigned values.
+func (e *LoadDataInfo) evalAssignments() ([]types.Datum, error) {
+	input := make([]types.Datum, len(e.Table.Cols()))
+	for i, v := range e.row {
+		// The columns in the expressions are read in the types of the columns.
+		casted, err := table.CastValue(e.Ctx, v, e.columns[i].ToInfo())
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		input[e.columns[i].Offset] = casted
+	}
+	vals := make([]types.Datum, 0, len(e.row)+len(e.ColumnAssignments))
+	vals = append(vals, e.row...)
+	for _, assign := range e.ColumnAssignments {
+		val, err := assign.Expr.Eval(types.DatumRow(input))
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		vals = append(vals, val)
+	}
+	return vals, nil
+}
+
+func (e *LoadDataInfo) handleInsertErr(err error, logInfo string) error {
+	if e.abortOnErr {
+		return errors.Trace(err)
+	}
+	e.insertVal.handleLoadDataWarnings(err, logInfo)
+	return nil
 }
 
 func (e *InsertValues) handleLoadDataWarnings(err error, logInfo string) {
@@ -760,15 +866,22 @@ func (k loadDataVarKeyType) String() string {
 // LoadDataVarKey is a variable key for load data.
 const LoadDataVarKey loadDataVarKeyType = 0
 
+// loadDataReadBlockSize is the size of the blocks read from the file of "LOAD DATA INFILE".
+const loadDataReadBlockSize = 64 * 1024
+
+// LoadDataBatchCnt is the number of rows committed in a transaction by "LOAD DATA INFILE", like
+// the server does for "LOAD DATA LOCAL INFILE".
+var LoadDataBatchCnt int64 = 20000
+
 func (e *LoadData) exec(goCtx goctx.Context) (Row, error) {
-	// TODO: support load data without local field.
-	if !e.IsLocal {
-		return nil, errors.New("Load Data: don't support load data without local field")
-	}
 	// TODO: support lines terminated is "".
 	if len(e.loadDataInfo.LinesInfo.Terminated) == 0 {
 		return nil, errors.New("Load Data: don't support load data terminated is nil")
 	}
+	if !e.IsLocal {
+		// The file is on the server, it's read in the current statement.
+		return nil, errors.Trace(e.loadServerFile(goCtx))
+	}
 
 	ctx := e.loadDataInfo.insertVal.ctx
 	val := ctx.Value(LoadDataVarKey)
@@ -784,6 +897,103 @@ func (e *LoadData) exec(goCtx goctx.Context) (Row, error) {
 	return nil, nil
 }
 
+func (e *LoadData) loadServerFile(goCtx goctx.Context) error {
+	if e.loadDataInfo.Path == "" {
+		return errors.New("Load Data: infile path is empty")
+	}
+	path, err := checkSecureFilePath(e.loadDataInfo.Path)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer terror.Call(f.Close)
+
+	e.loadDataInfo.SetBatchCount(LoadDataBatchCnt)
+	var prevData []byte
+	for {
+		// InsertData may return the rest of the block, so a new block is allocated for every read.
+		curData := make([]byte, loadDataReadBlockSize)
+		n, err := io.ReadFull(f, curData)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return errors.Trace(err)
+		}
+		curData = curData[:n]
+		if n == 0 && len(prevData) == 0 {
+			return nil
+		}
+		if prevData, err = e.insertDataWithCommit(goCtx, prevData, curData); err != nil {
+			return errors.Trace(err)
+		}
+	}
+}
+
+// insertDataWithCommit inserts the data, the transaction is committed whenever the rows reach the batch count.
+func (e *LoadData) insertDataWithCommit(goCtx goctx.Context, prevData, curData []byte) ([]byte, error) {
+	for {
+		var reachLimit bool
+		var err error
+		prevData, reachLimit, err = e.loadDataInfo.InsertData(prevData, curData)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if !reachLimit {
+			return prevData, nil
+		}
+		// Make sure that there are no retries when committing.
+		if err = e.loadDataInfo.Ctx.RefreshTxnCtx(goCtx); err != nil {
+			return nil, errors.Trace(err)
+		}
+		curData, prevData = prevData, nil
+	}
+}
+
+// checkSecureFilePath checks the file on the server is in the directory of the secure-file-priv config,
+// and returns the path to read. The symbolic links are resolved before checking, so the returned path
+// is the resolved one if the config is set.
+func checkSecureFilePath(path string) (string, error) {
+	secureDir := config.GetGlobalConfig().Security.SecureFilePriv
+	if secureDir == "" {
+		return path, nil
+	}
+	dir, err := resolvePath(secureDir)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	file, err := resolvePath(path)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	rel, err := filepath.Rel(dir, file)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", ErrOptionPrevents.GenByArgs("--secure-file-priv")
+	}
+	return file, nil
+}
+
+// resolvePath returns the absolute path without symbolic links. If the file doesn't exist, which
+// happens to "SELECT ... INTO OUTFILE", its directory is resolved.
+func resolvePath(path string) (string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err == nil {
+		return resolved, nil
+	}
+	if !os.IsNotExist(err) {
+		return "", errors.Trace(err)
+	}
+	dir, err := filepath.EvalSymlinks(filepath.Dir(path))
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	return filepath.Join(dir, filepath.Base(path)), nil
+}
+
 // Next implements the Executor Next interface.
 func (e *LoadData) Next(goCtx goctx.Context) (Row, error) {
 	return e.exec(goCtx)
@@ -825,6 +1035,22 @@ type InsertValues struct {
 
 	GenColumns []*ast.ColumnName
 	GenExprs   []expression.Expression
+
+	// checks are the CHECK constraints of the table, they're built when the first row is checked.
+	checks      *tables.CheckConstraints
+	checksBuilt bool
+}
+
+// checkRow checks the row against the CHECK constraints of the table.
+func (e *InsertValues) checkRow(row []types.Datum) error {
+	if !e.checksBuilt {
+		checks, err := tables.NewCheckConstraints(e.ctx, e.Table.Meta())
+		if err != nil {
+			return errors.Trace(err)
+		}
+		e.checks, e.checksBuilt = checks, true
+	}
+	return errors.Trace(e.checks.Check(row))
 }
 
 // InsertExec represents an insert executor.
@@ -836,24 +1062,43 @@ type InsertExec struct {
 	Priority  mysql.PriorityEnum
 	IgnoreErr bool
 
+	// forceBatch is true if the rows are always inserted in batches, it's used
+	// by CREATE TABLE ... SELECT.
+	forceBatch bool
+	// rowCount is the number of rows inserted in the current batch.
+	rowCount int
 	finished bool
 }
 
-func (e *InsertExec) exec(goCtx goctx.Context, rows [][]types.Datum) (Row, error) {
+// isBatchInsert returns whether the rows are inserted in batches, every batch
+// is committed in its own transaction.
+func (e *InsertExec) isBatchInsert() bool {
 	// If tidb_batch_insert is ON and not in a transaction, we could use BatchInsert mode.
-	batchInsert := e.ctx.GetSessionVars().BatchInsert && !e.ctx.GetSessionVars().InTxn()
+	sessVars := e.ctx.GetSessionVars()
+	return e.forceBatch || (sessVars.BatchInsert && !sessVars.InTxn())
+}
+
+func (e *InsertExec) exec(goCtx goctx.Context, rows [][]types.Datum) (Row, error) {
+	batchInsert := e.isBatchInsert()
 	batchSize := e.ctx.GetSessionVars().DMLBatchSize
 
 	txn := e.ctx.Txn()
-	rowCount := 0
 	for _, row := range rows {
-		if batchInsert && rowCount >= batchSize {
+		if batchInsert && e.rowCount >= batchSize {
 			if err := e.ctx.NewTxn(); err != nil {
 				// We should return a special error for batch insert.
 				return nil, ErrBatchInsertFail.Gen("BatchInsert failed with error: %v", err)
 			}
 			txn = e.ctx.Txn()
-			rowCount = 0
+			e.rowCount = 0
+		}
+		if err := e.checkRow(row); err != nil {
+			// With IGNORE, the row which violates a CHECK constraint is discarded like the duplicate one.
+			if e.IgnoreErr && table.ErrCheckConstraintViolated.Equal(err) {
+				e.ctx.GetSessionVars().StmtCtx.AppendWarning(err)
+				continue
+			}
+			return nil, errors.Trace(err)
 		}
 		if len(e.OnDuplicate) == 0 && !e.IgnoreErr {
 			txn.SetOption(kv.PresumeKeyNotExists, nil)
@@ -862,7 +1107,7 @@ func (e *InsertExec) exec(goCtx goctx.Context, rows [][]types.Datum) (Row, error
 		txn.DelOption(kv.PresumeKeyNotExists)
 		if err == nil {
 			getDirtyDB(e.ctx).addRow(e.Table.Meta().ID, h, row)
-			rowCount++
+			e.rowCount++
 			continue
 		}
 
@@ -878,7 +1123,7 @@ func (e *InsertExec) exec(goCtx goctx.Context, rows [][]types.Datum) (Row, error
 				if err = e.onDuplicateUpdate(row, h, e.OnDuplicate); err != nil {
 					return nil, errors.Trace(err)
 				}
-				rowCount++
+				e.rowCount++
 				continue
 			}
 		}
@@ -902,9 +1147,13 @@ func (e *InsertExec) Next(goCtx goctx.Context) (Row, error) {
 		return nil, errors.Trace(err)
 	}
 
+	if e.SelectExec != nil && e.isBatchInsert() {
+		return nil, errors.Trace(e.execSelectInBatches(goCtx, cols, false))
+	}
+
 	var rows [][]types.Datum
 	if e.SelectExec != nil {
-		rows, err = e.getRowsSelect(goCtx, cols, e.IgnoreErr)
+		rows, err = e.getRowsSelect(goCtx, cols, e.IgnoreErr, 0)
 	} else {
 		rows, err = e.getRows(cols, e.IgnoreErr)
 	}
@@ -926,9 +1175,14 @@ func (e *InsertExec) NextChunk(goCtx goctx.Context, chk *chunk.Chunk) error {
 		return errors.Trace(err)
 	}
 
+	hasSelect := len(e.children) > 0 && e.children[0] != nil
+	if hasSelect && e.isBatchInsert() {
+		return errors.Trace(e.execSelectInBatches(goCtx, cols, true))
+	}
+
 	var rows [][]types.Datum
-	if len(e.children) > 0 && e.children[0] != nil {
-		rows, err = e.getRowsSelectChunk(goCtx, cols, e.IgnoreErr)
+	if hasSelect {
+		rows, err = e.getRowsSelectChunk(goCtx, cols, e.IgnoreErr, 0)
 	} else {
 		rows, err = e.getRows(cols, e.IgnoreErr)
 	}
@@ -940,6 +1194,31 @@ func (e *InsertExec) NextChunk(goCtx goctx.Context, chk *chunk.Chunk) error {
 	return errors.Trace(err)
 }
 
+// execSelectInBatches reads the rows of the select and inserts them batch by
+// batch, so all the rows aren't held in memory at the same time.
+func (e *InsertExec) execSelectInBatches(goCtx goctx.Context, cols []*table.Column, useChunk bool) error {
+	batchSize := e.ctx.GetSessionVars().DMLBatchSize
+	for {
+		var rows [][]types.Datum
+		var err error
+		if useChunk {
+			rows, err = e.getRowsSelectChunk(goCtx, cols, e.IgnoreErr, batchSize)
+		} else {
+			rows, err = e.getRowsSelect(goCtx, cols, e.IgnoreErr, batchSize)
+		}
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if _, err = e.exec(goCtx, rows); err != nil {
+			return errors.Trace(err)
+		}
+		// The select is drained if it returns fewer rows than the limit.
+		if batchSize <= 0 || len(rows) < batchSize {
+			return nil
+		}
+	}
+}
+
 // Close implements the Executor Close interface.
 func (e *InsertExec) Close() error {
 	e.ctx.GetSessionVars().CurrInsertValues = nil
@@ -998,7 +1277,17 @@ func (e *InsertValues) getColumns(tableCols []*table.Column) ([]*table.Column, e
 		}
 	} else {
 		// If e.Columns are empty, use all columns instead.
-		cols = tableCols
+		// The hidden columns of the expression indexes are put at last, their values are always computed.
+		cols = make([]*table.Column, 0, len(tableCols))
+		var hiddenCols []*table.Column
+		for _, col := range tableCols {
+			if col.Hidden {
+				hiddenCols = append(hiddenCols, col)
+			} else {
+				cols = append(cols, col)
+			}
+		}
+		cols = append(cols, hiddenCols...)
 	}
 
 	// Check column whether is specified only once.
@@ -1046,13 +1335,24 @@ func (e *InsertValues) checkValueCount(insertValueCount, valueCount, genColsCoun
 		}
 		if explicitSetLen > 0 && valueCount+genColsCount != len(cols) {
 			return ErrWrongValueCountOnRow.GenByArgs(num + 1)
-		} else if explicitSetLen == 0 && valueCount != len(cols) {
+		} else if explicitSetLen == 0 && valueCount != len(cols)-hiddenColumnCount(cols) {
 			return ErrWrongValueCountOnRow.GenByArgs(num + 1)
 		}
 	}
 	return nil
 }
 
+// hiddenColumnCount returns the number of the hidden columns, whose values can't be specified.
+func hiddenColumnCount(cols []*table.Column) int {
+	cnt := 0
+	for _, col := range cols {
+		if col.Hidden {
+			cnt++
+		}
+	}
+	return cnt
+}
+
 func (e *InsertValues) getRows(cols []*table.Column, ignoreErr bool) (rows [][]types.Datum, err error) {
 	// process `insert|replace ... set x=y...`
 	if err = e.fillValueList(); err != nil {
@@ -1131,13 +1431,15 @@ func (e *InsertValues) fillDefaultValues(row []types.Datum, hasValue []bool, ign
 	return nil
 }
 
-func (e *InsertValues) getRowsSelect(goCtx goctx.Context, cols []*table.Column, ignoreErr bool) ([][]types.Datum, error) {
+// getRowsSelect reads the rows of the select, at least limit rows are read
+// unless the select is drained. If limit isn't positive, all the rows are read.
+func (e *InsertValues) getRowsSelect(goCtx goctx.Context, cols []*table.Column, ignoreErr bool, limit int) ([][]types.Datum, error) {
 	// process `insert|replace into ... select ... from ...`
-	if e.SelectExec.Schema().Len() != len(cols) {
+	if e.SelectExec.Schema().Len() != len(cols)-hiddenColumnCount(cols) {
 		return nil, ErrWrongValueCountOnRow.GenByArgs(1)
 	}
 	var rows [][]types.Datum
-	for {
+	for limit <= 0 || len(rows) < limit {
 		innerRow, err := e.SelectExec.Next(goCtx)
 		if err != nil {
 			return nil, errors.Trace(err)
@@ -1155,15 +1457,16 @@ func (e *InsertValues) getRowsSelect(goCtx goctx.Context, cols []*table.Column,
 	return rows, nil
 }
 
-func (e *InsertValues) getRowsSelectChunk(goCtx goctx.Context, cols []*table.Column, ignoreErr bool) ([][]types.Datum, error) {
+// getRowsSelectChunk is like getRowsSelect, but reads the rows chunk by chunk.
+func (e *InsertValues) getRowsSelectChunk(goCtx goctx.Context, cols []*table.Column, ignoreErr bool, limit int) ([][]types.Datum, error) {
 	// process `insert|replace into ... select ... from ...`
 	selectExec := e.children[0]
-	if selectExec.Schema().Len() != len(cols) {
+	if selectExec.Schema().Len() != len(cols)-hiddenColumnCount(cols) {
 		return nil, ErrWrongValueCountOnRow.GenByArgs(1)
 	}
 	var rows [][]types.Datum
 	fields := selectExec.Schema().GetTypes()
-	for {
+	for limit <= 0 || len(rows) < limit {
 		chk := selectExec.newChunk()
 		err := selectExec.NextChunk(goCtx, chk)
 		if err != nil {
@@ -1363,7 +1666,7 @@ func (e *InsertExec) onDuplicateUpdate(row []types.Datum, h int64, cols []*expre
 		newData[col.Col.Index] = val
 		assignFlag[col.Col.Index] = true
 	}
-	if _, err = updateRecord(e.ctx, h, data, newData, assignFlag, e.Table, true, false); err != nil {
+	if _, err = updateRecord(e.ctx, h, data, newData, assignFlag, e.Table, e.checks, true, false); err != nil {
 		return errors.Trace(err)
 	}
 	return nil
@@ -1405,51 +1708,55 @@ func (e *ReplaceExec) exec(goCtx goctx.Context, rows [][]types.Datum) (Row, erro
 	 * because in this case, one row was inserted after the duplicate was deleted.
 	 * See http://dev.mysql.com/doc/refman/5.7/en/mysql-affected-rows.html
 	 */
-	idx := 0
-	rowsLen := len(rows)
+	for _, row := range rows {
+		if err := e.checkRow(row); err != nil {
+			return nil, errors.Trace(err)
+		}
+		if err := e.replaceRow(row); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+
+	if e.lastInsertID != 0 {
+		e.ctx.GetSessionVars().SetLastInsertID(e.lastInsertID)
+	}
+	e.finished = true
+	return nil, nil
+}
+
+// replaceRow inserts row into the table, the rows which have the duplicate key values are removed first.
+func (e *InsertValues) replaceRow(row []types.Datum) error {
 	sc := e.ctx.GetSessionVars().StmtCtx
 	for {
-		if idx >= rowsLen {
-			break
-		}
-		row := rows[idx]
-		h, err1 := e.Table.AddRecord(e.ctx, row, false)
-		if err1 == nil {
+		h, err := e.Table.AddRecord(e.ctx, row, false)
+		if err == nil {
 			getDirtyDB(e.ctx).addRow(e.Table.Meta().ID, h, row)
-			idx++
-			continue
+			return nil
 		}
-		if err1 != nil && !kv.ErrKeyExists.Equal(err1) {
-			return nil, errors.Trace(err1)
+		if !kv.ErrKeyExists.Equal(err) {
+			return errors.Trace(err)
 		}
-		oldRow, err1 := e.Table.Row(e.ctx, h)
-		if err1 != nil {
-			return nil, errors.Trace(err1)
+		oldRow, err := e.Table.Row(e.ctx, h)
+		if err != nil {
+			return errors.Trace(err)
 		}
-		rowUnchanged, err1 := types.EqualDatums(sc, oldRow, row)
-		if err1 != nil {
-			return nil, errors.Trace(err1)
+		rowUnchanged, err := types.EqualDatums(sc, oldRow, row)
+		if err != nil {
+			return errors.Trace(err)
 		}
 		if rowUnchanged {
 			// If row unchanged, we do not need to do insert.
-			e.ctx.GetSessionVars().StmtCtx.AddAffectedRows(1)
-			idx++
-			continue
+			sc.AddAffectedRows(1)
+			return nil
 		}
 		// Remove current row and try replace again.
-		err1 = e.Table.RemoveRecord(e.ctx, h, oldRow)
-		if err1 != nil {
-			return nil, errors.Trace(err1)
+		err = e.Table.RemoveRecord(e.ctx, h, oldRow)
+		if err != nil {
+			return errors.Trace(err)
 		}
 		getDirtyDB(e.ctx).deleteRow(e.Table.Meta().ID, h)
-		e.ctx.GetSessionVars().StmtCtx.AddAffectedRows(1)
-	}
-
-	if e.lastInsertID != 0 {
-		e.ctx.GetSessionVars().SetLastInsertID(e.lastInsertID)
+		sc.AddAffectedRows(1)
 	}
-	e.finished = true
-	return nil, nil
 }
 
 // Next implements the Executor Next interface.
@@ -1464,7 +1771,7 @@ func (e *ReplaceExec) Next(goCtx goctx.Context) (Row, error) {
 
 	var rows [][]types.Datum
 	if e.SelectExec != nil {
-		rows, err = e.getRowsSelect(goCtx, cols, false)
+		rows, err = e.getRowsSelect(goCtx, cols, false, 0)
 	} else {
 		rows, err = e.getRows(cols, false)
 	}
@@ -1488,7 +1795,7 @@ func (e *ReplaceExec) NextChunk(goCtx goctx.Context, chk *chunk.Chunk) error {
 
 	var rows [][]types.Datum
 	if len(e.children) > 0 && e.children[0] != nil {
-		rows, err = e.getRowsSelectChunk(goCtx, cols, false)
+		rows, err = e.getRowsSelectChunk(goCtx, cols, false, 0)
 	} else {
 		rows, err = e.getRows(cols, false)
 	}
@@ -1511,6 +1818,8 @@ type UpdateExec struct {
 	// updatedRowKeys is a map for unique (Table, handle) pair.
 	updatedRowKeys map[int64]map[int64]struct{}
 	tblID2table    map[int64]table.Table
+	// tblID2checks are the CHECK constraints of the updated tables.
+	tblID2checks map[int64]*tables.CheckConstraints
 
 	rows        []Row           // The rows fetched from TableExec.
 	newRowsData [][]types.Datum // The new values to be set.
@@ -1531,11 +1840,22 @@ func (e *UpdateExec) exec(goCtx goctx.Context, schema *expression.Schema) (Row,
 	}
 	row := e.rows[e.cursor]
 	newData := e.newRowsData[e.cursor]
+	if e.tblID2checks == nil {
+		e.tblID2checks = make(map[int64]*tables.CheckConstraints)
+	}
 	for id, cols := range schema.TblID2Handle {
 		tbl := e.tblID2table[id]
 		if e.updatedRowKeys[id] == nil {
 			e.updatedRowKeys[id] = make(map[int64]struct{})
 		}
+		checks, ok := e.tblID2checks[id]
+		if !ok {
+			checks, err = tables.NewCheckConstraints(e.ctx, tbl.Meta())
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			e.tblID2checks[id] = checks
+		}
 		for _, col := range cols {
 			offset := getTableOffset(schema, col)
 			end := offset + len(tbl.WritableCols())
@@ -1549,7 +1869,7 @@ func (e *UpdateExec) exec(goCtx goctx.Context, schema *expression.Schema) (Row,
 				continue
 			}
 			// Update row
-			changed, err1 := updateRecord(e.ctx, handle, oldData, newTableData, flags, tbl, false, e.IgnoreErr)
+			changed, err1 := updateRecord(e.ctx, handle, oldData, newTableData, flags, tbl, checks, false, e.IgnoreErr)
 			if err1 == nil {
 				if changed {
 					e.updatedRowKeys[id][handle] = struct{}{}
@@ -1557,7 +1877,7 @@ func (e *UpdateExec) exec(goCtx goctx.Context, schema *expression.Schema) (Row,
 				continue
 			}
 
-			if kv.ErrKeyExists.Equal(err1) && e.IgnoreErr {
+			if (kv.ErrKeyExists.Equal(err1) || table.ErrCheckConstraintViolated.Equal(err1)) && e.IgnoreErr {
 				e.ctx.GetSessionVars().StmtCtx.AppendWarning(err1)
 				continue
 			}
@@ -16,16 +16,22 @@ package executor_test
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"sync/atomic"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/domain"
 	"github.com/pingcap/tidb/executor"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/testkit"
 )
@@ -1025,6 +1031,123 @@ func (s *testSuite) TestLoadDataEscape(c *C) {
 	checkCases(tests, ld, c, tk, ctx, selectSQL, deleteSQL)
 }
 
+func (s *testSuite) TestSelectIntoOutfile(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (id int, s varchar(20), d decimal(5, 2))")
+	tk.MustExec(`insert into t values (1, 'a,b', 1.5), (2, 'x"y\\z', null), (3, 'l1\nl2', 0), (4, null, -2.25)`)
+	dir, err := ioutil.TempDir("", "select_into")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+
+	readFile := func(name string) string {
+		data, err1 := ioutil.ReadFile(filepath.Join(dir, name))
+		c.Assert(err1, IsNil)
+		return string(data)
+	}
+	for _, enableChunk := range []bool{false, true} {
+		tk.Se.GetSessionVars().EnableChunk = enableChunk
+		name := fmt.Sprintf("default_%v.txt", enableChunk)
+		tk.MustExec(fmt.Sprintf("select * from t order by id into outfile '%s'", filepath.Join(dir, name)))
+		c.Assert(tk.Se.AffectedRows(), Equals, uint64(4))
+		c.Assert(readFile(name), Equals, "1\ta,b\t1.50\n2\tx\"y\\\\z\t\\N\n3\tl1\\\nl2\t0.00\n4\t\\N\t-2.25\n")
+	}
+
+	csv := filepath.Join(dir, "t.csv")
+	tk.MustExec(fmt.Sprintf(`select id, s from t where id < 4 order by id into outfile '%s'
+		fields terminated by ',' enclosed by '"' lines starting by '>' terminated by '\r\n'`, csv))
+	c.Assert(readFile("t.csv"), Equals, ">\"1\",\"a,b\"\r\n>\"2\",\"x\\\"y\\\\z\"\r\n>\"3\",\"l1\nl2\"\r\n")
+
+	// All the values but the numbers are escaped.
+	tk.MustExec("drop table if exists t_kinds")
+	tk.MustExec(`create table t_kinds (e enum('a,b', 'c'), st set('x', 'y'), j json, dt datetime, f double)`)
+	tk.MustExec(`insert into t_kinds values ('a,b', 'x,y', '{"k": "v,w"}', '2017-11-11 11:11:11', 1.5)`)
+	tk.MustExec(fmt.Sprintf("select * from t_kinds into outfile '%s' fields terminated by ','", filepath.Join(dir, "kinds.csv")))
+	c.Assert(readFile("kinds.csv"), Equals, "a\\,b,x\\,y,{\"k\":\"v\\,w\"},2017-11-11 11:11:11,1.5\n")
+
+	// An existing file is never overwritten.
+	_, err = tk.Exec(fmt.Sprintf("select * from t into outfile '%s'", csv))
+	c.Assert(terror.ErrorEqual(err, executor.ErrFileExists), IsTrue)
+
+	tk.MustExec(fmt.Sprintf("select s from t where id = 2 into dumpfile '%s'", filepath.Join(dir, "t.bin")))
+	c.Assert(readFile("t.bin"), Equals, `x"y\z`)
+	_, err = tk.Exec(fmt.Sprintf("select s from t into dumpfile '%s'", filepath.Join(dir, "t2.bin")))
+	c.Assert(terror.ErrorEqual(err, executor.ErrTooManyRows), IsTrue)
+
+	_, err = tk.Exec(fmt.Sprintf("select * from t where id in (select 1 into outfile '%s')", filepath.Join(dir, "t3.csv")))
+	c.Assert(terror.ErrorEqual(err, plan.ErrWrongUsage), IsTrue)
+	_, err = tk.Exec(fmt.Sprintf("select 1 union select 2 into outfile '%s'", filepath.Join(dir, "t3.csv")))
+	c.Assert(terror.ErrorEqual(err, plan.ErrWrongUsage), IsTrue)
+}
+
+func (s *testSuite) TestLoadDataServerFile(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (id int primary key, s varchar(20), n int default 7)")
+	dir, err := ioutil.TempDir("", "load_data")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "t.csv")
+	err = ioutil.WriteFile(path, []byte("id,s\n1,a\n2,b\n3,c"), 0644)
+	c.Assert(err, IsNil)
+
+	tk.MustExec(fmt.Sprintf("load data infile '%s' into table t fields terminated by ',' ignore 1 lines (id, @v) set s = upper(@v)", path))
+	c.Assert(tk.Se.AffectedRows(), Equals, uint64(3))
+	tk.MustQuery("select * from t").Check(testkit.Rows("1 A 7", "2 B 7", "3 C 7"))
+	tk.MustQuery("select @v").Check(testkit.Rows("c"))
+
+	// The duplicate rows fail the statement by default.
+	tk.MustExec("delete from t where id > 1")
+	_, err = tk.Exec(fmt.Sprintf("load data infile '%s' into table t fields terminated by ',' ignore 1 lines (id, s)", path))
+	c.Assert(err, NotNil)
+	tk.MustQuery("select * from t").Check(testkit.Rows("1 A 7"))
+	tk.MustExec(fmt.Sprintf("load data infile '%s' ignore into table t fields terminated by ',' ignore 1 lines (id, s)", path))
+	tk.MustQuery("select * from t").Check(testkit.Rows("1 A 7", "2 b 7", "3 c 7"))
+	tk.MustExec(fmt.Sprintf("load data infile '%s' replace into table t fields terminated by ',' ignore 2 rows (id, s) set n = id * 10", path))
+	tk.MustQuery("select * from t").Check(testkit.Rows("1 A 7", "2 b 20", "3 c 30"))
+
+	_, err = tk.Exec("load data infile '/tmp/nonexistence.csv' into table t")
+	c.Assert(err, NotNil)
+
+	secureFilePriv := config.GetGlobalConfig().Security.SecureFilePriv
+	defer func() {
+		config.GetGlobalConfig().Security.SecureFilePriv = secureFilePriv
+	}()
+	config.GetGlobalConfig().Security.SecureFilePriv = filepath.Join(dir, "secure")
+	_, err = tk.Exec(fmt.Sprintf("load data infile '%s' replace into table t fields terminated by ','", path))
+	c.Assert(terror.ErrorEqual(err, executor.ErrOptionPrevents), IsTrue)
+	_, err = tk.Exec(fmt.Sprintf("select * from t into outfile '%s'", filepath.Join(dir, "t2.csv")))
+	c.Assert(terror.ErrorEqual(err, executor.ErrOptionPrevents), IsTrue)
+	// The symbolic links are resolved before checking.
+	secureDir := filepath.Join(dir, "secure")
+	c.Assert(os.Mkdir(secureDir, 0755), IsNil)
+	c.Assert(os.Symlink(path, filepath.Join(secureDir, "link.csv")), IsNil)
+	c.Assert(os.Symlink(dir, filepath.Join(secureDir, "parent")), IsNil)
+	_, err = tk.Exec(fmt.Sprintf("load data infile '%s' replace into table t fields terminated by ','", filepath.Join(secureDir, "link.csv")))
+	c.Assert(terror.ErrorEqual(err, executor.ErrOptionPrevents), IsTrue)
+	_, err = tk.Exec(fmt.Sprintf("select * from t into outfile '%s'", filepath.Join(secureDir, "parent", "t2.csv")))
+	c.Assert(terror.ErrorEqual(err, executor.ErrOptionPrevents), IsTrue)
+	config.GetGlobalConfig().Security.SecureFilePriv = dir
+	tk.MustExec(fmt.Sprintf("load data infile '%s' replace into table t fields terminated by ',' ignore 1 lines", filepath.Join(secureDir, "link.csv")))
+	tk.MustQuery("select * from t").Check(testkit.Rows("1 a 0", "2 b 0", "3 c 0"))
+
+	// The rows are committed in batches, the batches before the failed one are kept.
+	defer func(cnt int64) {
+		executor.LoadDataBatchCnt = cnt
+	}(executor.LoadDataBatchCnt)
+	executor.LoadDataBatchCnt = 2
+	tk.MustExec("delete from t where id < 3")
+	_, err = tk.Exec(fmt.Sprintf("load data infile '%s' into table t fields terminated by ',' ignore 1 lines (id, s)", path))
+	c.Assert(err, NotNil)
+	tk.MustQuery("select * from t").Check(testkit.Rows("1 a 7", "2 b 7", "3 c 0"))
+	tk.MustExec("delete from t")
+	tk.MustExec(fmt.Sprintf("load data infile '%s' into table t fields terminated by ',' ignore 1 lines (id, s)", path))
+	c.Assert(tk.Se.AffectedRows(), Equals, uint64(3))
+	tk.MustQuery("select * from t").Check(testkit.Rows("1 a 7", "2 b 7", "3 c 7"))
+}
+
 // TestLoadDataSpecifiedCoumns reuse TestLoadDataEscape's test case :-)
 func (s *testSuite) TestLoadDataSpecifiedCoumns(c *C) {
 	tk := testkit.NewTestKit(c, s.store)
@@ -1048,6 +1171,22 @@ func (s *testSuite) TestLoadDataSpecifiedCoumns(c *C) {
 	checkCases(tests, ld, c, tk, ctx, selectSQL, deleteSQL)
 }
 
+func (s *testSuite) TestLoadDataCheckConstraint(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test; drop table if exists load_data_test;")
+	tk.MustExec("create table load_data_test (id int, c1 int check (c1 > 0))")
+	tk.MustExec("load data local infile '/tmp/nonexistence.csv' into table load_data_test")
+	ctx := tk.Se.(context.Context)
+	ld := makeLoadDataInfo(2, nil, ctx, c)
+	// The rows which violate the CHECK constraint are skipped with warnings.
+	tests := []testCase{
+		{nil, []byte("1\t1\n2\t0\n3\t3\n4\t-1\n"), []string{"1|1", "3|3"}, nil},
+	}
+	deleteSQL := "delete from load_data_test"
+	selectSQL := "select * from load_data_test;"
+	checkCases(tests, ld, c, tk, ctx, selectSQL, deleteSQL)
+}
+
 func makeLoadDataInfo(column int, specifiedColumns []string, ctx context.Context, c *C) (ld *executor.LoadDataInfo) {
 	dom := domain.GetDomain(ctx)
 	is := dom.InfoSchema()
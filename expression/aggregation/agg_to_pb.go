@@ -26,6 +26,13 @@ func AggFuncToPBExpr(sc *stmtctx.StatementContext, client kv.Client, aggFunc Agg
 	if aggFunc.IsDistinct() {
 		return nil
 	}
+	switch aggFunc.GetName() {
+	case ast.AggFuncMax, ast.AggFuncMin:
+		// The storage compares the strings by their bytes.
+		if expression.IsCollationKeyNeeded(aggFunc.GetArgs()[0].GetType()) {
+			return nil
+		}
+	}
 	pc := expression.NewPBConverter(client, sc)
 	var tp tipb.ExprType
 	switch aggFunc.GetName() {
@@ -49,6 +56,9 @@ func AggFuncToPBExpr(sc *stmtctx.StatementContext, client kv.Client, aggFunc Agg
 		tp = tipb.ExprType_Agg_BitXor
 	case ast.AggFuncBitAnd:
 		tp = tipb.ExprType_Agg_BitAnd
+	default:
+		// The coprocessor doesn't support json_arrayagg and json_objectagg yet.
+		return nil
 	}
 	if !client.IsRequestTypeSupported(kv.ReqTypeSelect, int64(tp)) {
 		return nil
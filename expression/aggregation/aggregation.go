@@ -102,6 +102,10 @@ func NewAggFunction(funcType string, funcArgs []expression.Expression, distinct
 		return &bitXorFunction{aggFunction: newAggFunc(tp, funcArgs, distinct)}
 	case ast.AggFuncBitAnd:
 		return &bitAndFunction{aggFunction: newAggFunc(tp, funcArgs, distinct)}
+	case ast.AggFuncJSONArrayAgg:
+		return &jsonArrayAggFunction{aggFunction: newAggFunc(tp, funcArgs, distinct)}
+	case ast.AggFuncJSONObjectAgg:
+		return &jsonObjectAggFunction{aggFunction: newAggFunc(tp, funcArgs, distinct)}
 	}
 	return nil
 }
@@ -146,8 +150,10 @@ type AggEvaluateContext struct {
 	DistinctChecker *distinctChecker
 	Count           int64
 	Value           types.Datum
-	Buffer          *bytes.Buffer // Buffer is used for group_concat.
-	GotFirstRow     bool          // It will check if the agg has met the first row key.
+	Buffer          *bytes.Buffer          // Buffer is used for group_concat.
+	GotFirstRow     bool                   // It will check if the agg has met the first row key.
+	JSONArray       []interface{}          // JSONArray is used for json_arrayagg.
+	JSONObject      map[string]interface{} // JSONObject is used for json_objectagg.
 }
 
 // AggFunctionMode stands for the aggregation function's mode.
@@ -18,6 +18,7 @@ import (
 	"fmt"
 
 	"github.com/juju/errors"
+	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx/stmtctx"
 	"github.com/pingcap/tidb/types"
@@ -86,7 +87,11 @@ func (cf *concatFunction) Update(ctx *AggEvaluateContext, sc *stmtctx.StatementC
 		datumBuf = append(datumBuf, value)
 	}
 	if cf.Distinct {
-		d, err := ctx.DistinctChecker.Check(datumBuf)
+		keys := make([]types.Datum, 0, len(datumBuf))
+		for i, val := range datumBuf {
+			keys = append(keys, expression.CollationKeyDatum(val, cf.Args[i].GetType()))
+		}
+		d, err := ctx.DistinctChecker.Check(keys)
 		if err != nil {
 			return errors.Trace(err)
 		}
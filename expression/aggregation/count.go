@@ -76,7 +76,7 @@ func (cf *countFunction) Update(ctx *AggEvaluateContext, sc *stmtctx.StatementCo
 			ctx.Count += value.GetInt64()
 		}
 		if cf.Distinct {
-			datumBuf = append(datumBuf, value)
+			datumBuf = append(datumBuf, expression.CollationKeyDatum(value, a.GetType()))
 		}
 	}
 	if cf.Distinct {
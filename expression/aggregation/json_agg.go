@@ -0,0 +1,135 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregation
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/stmtctx"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/types/json"
+)
+
+type jsonArrayAggFunction struct {
+	aggFunction
+}
+
+// Clone implements Aggregation interface.
+func (jf *jsonArrayAggFunction) Clone() Aggregation {
+	nf := *jf
+	for i, arg := range jf.Args {
+		nf.Args[i] = arg.Clone()
+	}
+	return &nf
+}
+
+// GetType implements Aggregation interface.
+func (jf *jsonArrayAggFunction) GetType() *types.FieldType {
+	return types.NewFieldType(mysql.TypeJSON)
+}
+
+// Update implements Aggregation interface.
+func (jf *jsonArrayAggFunction) Update(ctx *AggEvaluateContext, sc *stmtctx.StatementContext, row types.Row) error {
+	value, err := jf.Args[0].Eval(row)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	// Unlike the other aggregate functions, a NULL value is kept as a JSON null.
+	j, err := value.ToMysqlJSON()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if ctx.JSONArray == nil {
+		ctx.JSONArray = make([]interface{}, 0, 1)
+	}
+	ctx.JSONArray = append(ctx.JSONArray, j)
+	return nil
+}
+
+// GetResult implements Aggregation interface.
+func (jf *jsonArrayAggFunction) GetResult(ctx *AggEvaluateContext) (d types.Datum) {
+	if ctx.JSONArray == nil {
+		d.SetNull()
+		return
+	}
+	d.SetMysqlJSON(json.CreateBinary(ctx.JSONArray))
+	return
+}
+
+// GetPartialResult implements Aggregation interface.
+func (jf *jsonArrayAggFunction) GetPartialResult(ctx *AggEvaluateContext) []types.Datum {
+	return []types.Datum{jf.GetResult(ctx)}
+}
+
+type jsonObjectAggFunction struct {
+	aggFunction
+}
+
+// Clone implements Aggregation interface.
+func (jf *jsonObjectAggFunction) Clone() Aggregation {
+	nf := *jf
+	for i, arg := range jf.Args {
+		nf.Args[i] = arg.Clone()
+	}
+	return &nf
+}
+
+// GetType implements Aggregation interface.
+func (jf *jsonObjectAggFunction) GetType() *types.FieldType {
+	return types.NewFieldType(mysql.TypeJSON)
+}
+
+// Update implements Aggregation interface.
+func (jf *jsonObjectAggFunction) Update(ctx *AggEvaluateContext, sc *stmtctx.StatementContext, row types.Row) error {
+	key, err := jf.Args[0].Eval(row)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if key.IsNull() {
+		return json.ErrJSONDocumentNULLKey
+	}
+	keyStr, err := key.ToString()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	value, err := jf.Args[1].Eval(row)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	j, err := value.ToMysqlJSON()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if ctx.JSONObject == nil {
+		ctx.JSONObject = make(map[string]interface{})
+	}
+	// The last value wins if a key appears more than once.
+	ctx.JSONObject[keyStr] = j
+	return nil
+}
+
+// GetResult implements Aggregation interface.
+func (jf *jsonObjectAggFunction) GetResult(ctx *AggEvaluateContext) (d types.Datum) {
+	if ctx.JSONObject == nil {
+		d.SetNull()
+		return
+	}
+	d.SetMysqlJSON(json.CreateBinary(ctx.JSONObject))
+	return
+}
+
+// GetPartialResult implements Aggregation interface.
+func (jf *jsonObjectAggFunction) GetPartialResult(ctx *AggEvaluateContext) []types.Datum {
+	return []types.Datum{jf.GetResult(ctx)}
+}
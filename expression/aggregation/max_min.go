@@ -74,7 +74,9 @@ func (mmf *maxMinFunction) Update(ctx *AggEvaluateContext, sc *stmtctx.Statement
 		return nil
 	}
 	var c int
-	c, err = ctx.Value.CompareDatum(sc, &value)
+	// The strings are compared by the sort keys of their collation.
+	curKey, key := expression.CollationKeyDatum(ctx.Value, a.GetType()), expression.CollationKeyDatum(value, a.GetType())
+	c, err = curKey.CompareDatum(sc, &key)
 	if err != nil {
 		return errors.Trace(err)
 	}
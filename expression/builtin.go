@@ -25,6 +25,8 @@ import (
 	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/types/json"
 	"github.com/pingcap/tidb/util/charset"
+	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/collate"
 	"github.com/pingcap/tipb/go-tipb"
 )
 
@@ -34,6 +36,8 @@ type baseBuiltinFunc struct {
 	ctx    context.Context
 	tp     *types.FieldType
 	pbCode tipb.ScalarFuncSig
+	// collation is the collation which the string arguments are compared by.
+	collation string
 }
 
 func (b *baseBuiltinFunc) PbCode() tipb.ScalarFuncSig {
@@ -142,12 +146,24 @@ func newBaseBuiltinFuncWithTp(ctx context.Context, args []Expression, retType ty
 	if mysql.HasBinaryFlag(fieldType.Flag) && fieldType.Tp != mysql.TypeJSON {
 		fieldType.Charset, fieldType.Collate = charset.CharsetBin, charset.CollationBin
 	} else {
-		fieldType.Charset, fieldType.Collate = charset.CharsetUTF8, charset.CharsetUTF8
+		fieldType.Charset, fieldType.Collate = charset.CharsetUTF8, charset.CollationUTF8
+	}
+	var chs, collation string
+	for _, tp := range argTps {
+		if tp == types.ETString {
+			chs, collation, _, _ = deriveCollation(args)
+			break
+		}
+	}
+	if retType == types.ETString && collation != "" && collate.NewCollationEnabled() {
+		// The string result has the collation of the string arguments.
+		fieldType.Charset, fieldType.Collate = chs, collation
 	}
 	return baseBuiltinFunc{
-		args: args,
-		ctx:  ctx,
-		tp:   fieldType,
+		args:      args,
+		ctx:       ctx,
+		tp:        fieldType,
+		collation: collation,
 	}
 }
 
@@ -183,12 +199,31 @@ func (b *baseBuiltinFunc) evalJSON(row types.Row) (json.BinaryJSON, bool, error)
 	panic("baseBuiltinFunc.evalJSON() should never be called.")
 }
 
+func (b *baseBuiltinFunc) vectorized() bool {
+	return false
+}
+
+func (b *baseBuiltinFunc) vecEvalInt(input *chunk.Chunk, result *chunk.Column) error {
+	panic("baseBuiltinFunc.vecEvalInt() should never be called.")
+}
+
+func (b *baseBuiltinFunc) vecEvalReal(input *chunk.Chunk, result *chunk.Column) error {
+	panic("baseBuiltinFunc.vecEvalReal() should never be called.")
+}
+
+func (b *baseBuiltinFunc) vecEvalString(input *chunk.Chunk, result *chunk.Column) error {
+	panic("baseBuiltinFunc.vecEvalString() should never be called.")
+}
+
 func (b *baseBuiltinFunc) getRetTp() *types.FieldType {
 	switch b.tp.EvalType() {
 	case types.ETString:
-		if b.tp.Flen >= mysql.MaxBlobWidth {
+		switch {
+		case b.tp.Tp == mysql.TypeGeometry:
+			// Geometries are evaluated as strings, but keep their own type.
+		case b.tp.Flen >= mysql.MaxBlobWidth:
 			b.tp.Tp = mysql.TypeLongBlob
-		} else if b.tp.Flen >= 65536 {
+		case b.tp.Flen >= 65536:
 			b.tp.Tp = mysql.TypeMediumBlob
 		}
 		if len(b.tp.Charset) <= 0 {
@@ -231,6 +266,14 @@ type builtinFunc interface {
 	evalDuration(row types.Row) (val types.Duration, isNull bool, err error)
 	// evalJSON evaluates JSON representation of builtinFunc by given row.
 	evalJSON(row types.Row) (val json.BinaryJSON, isNull bool, err error)
+	// vectorized returns whether builtinFunc can be evaluated by the vecEvalXXX functions.
+	vectorized() bool
+	// vecEvalInt evaluates int results of builtinFunc for all the rows in input and stores them in result.
+	vecEvalInt(input *chunk.Chunk, result *chunk.Column) error
+	// vecEvalReal evaluates real results of builtinFunc for all the rows in input and stores them in result.
+	vecEvalReal(input *chunk.Chunk, result *chunk.Column) error
+	// vecEvalString evaluates string results of builtinFunc for all the rows in input and stores them in result.
+	vecEvalString(input *chunk.Chunk, result *chunk.Column) error
 	// getArgs returns the arguments expressions.
 	getArgs() []Expression
 	// equal check if this function equals to another function.
@@ -399,6 +442,10 @@ var funcs = map[string]functionClass{
 	ast.Ord:             &ordFunctionClass{baseFunctionClass{ast.Ord, 1, 1}},
 	ast.Position:        &locateFunctionClass{baseFunctionClass{ast.Position, 2, 2}},
 	ast.Quote:           &quoteFunctionClass{baseFunctionClass{ast.Quote, 1, 1}},
+	ast.RegexpInStr:     &regexpInStrFunctionClass{baseFunctionClass{ast.RegexpInStr, 2, 6}},
+	ast.RegexpLike:      &regexpLikeFunctionClass{baseFunctionClass{ast.RegexpLike, 2, 3}},
+	ast.RegexpReplace:   &regexpReplaceFunctionClass{baseFunctionClass{ast.RegexpReplace, 3, 6}},
+	ast.RegexpSubstr:    &regexpSubstrFunctionClass{baseFunctionClass{ast.RegexpSubstr, 2, 5}},
 	ast.Repeat:          &repeatFunctionClass{baseFunctionClass{ast.Repeat, 2, 2}},
 	ast.Replace:         &replaceFunctionClass{baseFunctionClass{ast.Replace, 3, 3}},
 	ast.Reverse:         &reverseFunctionClass{baseFunctionClass{ast.Reverse, 1, 1}},
@@ -528,14 +575,45 @@ var funcs = map[string]functionClass{
 	ast.ValidatePasswordStrength: &validatePasswordStrengthFunctionClass{baseFunctionClass{ast.ValidatePasswordStrength, 1, 1}},
 
 	// json functions
-	ast.JSONType:    &jsonTypeFunctionClass{baseFunctionClass{ast.JSONType, 1, 1}},
-	ast.JSONExtract: &jsonExtractFunctionClass{baseFunctionClass{ast.JSONExtract, 2, -1}},
-	ast.JSONUnquote: &jsonUnquoteFunctionClass{baseFunctionClass{ast.JSONUnquote, 1, 1}},
-	ast.JSONSet:     &jsonSetFunctionClass{baseFunctionClass{ast.JSONSet, 3, -1}},
-	ast.JSONInsert:  &jsonInsertFunctionClass{baseFunctionClass{ast.JSONInsert, 3, -1}},
-	ast.JSONReplace: &jsonReplaceFunctionClass{baseFunctionClass{ast.JSONReplace, 3, -1}},
-	ast.JSONRemove:  &jsonRemoveFunctionClass{baseFunctionClass{ast.JSONRemove, 2, -1}},
-	ast.JSONMerge:   &jsonMergeFunctionClass{baseFunctionClass{ast.JSONMerge, 2, -1}},
-	ast.JSONObject:  &jsonObjectFunctionClass{baseFunctionClass{ast.JSONObject, 0, -1}},
-	ast.JSONArray:   &jsonArrayFunctionClass{baseFunctionClass{ast.JSONArray, 0, -1}},
+	ast.JSONType:          &jsonTypeFunctionClass{baseFunctionClass{ast.JSONType, 1, 1}},
+	ast.JSONExtract:       &jsonExtractFunctionClass{baseFunctionClass{ast.JSONExtract, 2, -1}},
+	ast.JSONUnquote:       &jsonUnquoteFunctionClass{baseFunctionClass{ast.JSONUnquote, 1, 1}},
+	ast.JSONSet:           &jsonSetFunctionClass{baseFunctionClass{ast.JSONSet, 3, -1}},
+	ast.JSONInsert:        &jsonInsertFunctionClass{baseFunctionClass{ast.JSONInsert, 3, -1}},
+	ast.JSONReplace:       &jsonReplaceFunctionClass{baseFunctionClass{ast.JSONReplace, 3, -1}},
+	ast.JSONRemove:        &jsonRemoveFunctionClass{baseFunctionClass{ast.JSONRemove, 2, -1}},
+	ast.JSONMerge:         &jsonMergeFunctionClass{baseFunctionClass{ast.JSONMerge, 2, -1}},
+	ast.JSONObject:        &jsonObjectFunctionClass{baseFunctionClass{ast.JSONObject, 0, -1}},
+	ast.JSONArray:         &jsonArrayFunctionClass{baseFunctionClass{ast.JSONArray, 0, -1}},
+	ast.JSONContainsPath:  &jsonContainsPathFunctionClass{baseFunctionClass{ast.JSONContainsPath, 3, -1}},
+	ast.JSONKeys:          &jsonKeysFunctionClass{baseFunctionClass{ast.JSONKeys, 1, 2}},
+	ast.JSONLength:        &jsonLengthFunctionClass{baseFunctionClass{ast.JSONLength, 1, 2}},
+	ast.JSONDepth:         &jsonDepthFunctionClass{baseFunctionClass{ast.JSONDepth, 1, 1}},
+	ast.JSONSearch:        &jsonSearchFunctionClass{baseFunctionClass{ast.JSONSearch, 3, -1}},
+	ast.JSONArrayAppend:   &jsonArrayAppendFunctionClass{baseFunctionClass{ast.JSONArrayAppend, 3, -1}},
+	ast.JSONArrayInsert:   &jsonArrayInsertFunctionClass{baseFunctionClass{ast.JSONArrayInsert, 3, -1}},
+	ast.JSONMergePatch:    &jsonMergePatchFunctionClass{baseFunctionClass{ast.JSONMergePatch, 2, -1}},
+	ast.JSONMergePreserve: &jsonMergeFunctionClass{baseFunctionClass{ast.JSONMergePreserve, 2, -1}},
+	ast.JSONQuote:         &jsonQuoteFunctionClass{baseFunctionClass{ast.JSONQuote, 1, 1}},
+	ast.JSONPretty:        &jsonPrettyFunctionClass{baseFunctionClass{ast.JSONPretty, 1, 1}},
+	ast.JSONStorageSize:   &jsonStorageSizeFunctionClass{baseFunctionClass{ast.JSONStorageSize, 1, 1}},
+
+	// spatial functions
+	ast.STGeomFromText:     &stGeomFromTextFunctionClass{baseFunctionClass{ast.STGeomFromText, 1, 2}},
+	ast.STGeometryFromText: &stGeomFromTextFunctionClass{baseFunctionClass{ast.STGeometryFromText, 1, 2}},
+	ast.STGeomFromWKB:      &stGeomFromWKBFunctionClass{baseFunctionClass{ast.STGeomFromWKB, 1, 2}},
+	ast.STGeometryFromWKB:  &stGeomFromWKBFunctionClass{baseFunctionClass{ast.STGeometryFromWKB, 1, 2}},
+	ast.STAsText:           &stAsTextFunctionClass{baseFunctionClass{ast.STAsText, 1, 1}},
+	ast.STAsWKT:            &stAsTextFunctionClass{baseFunctionClass{ast.STAsWKT, 1, 1}},
+	ast.STAsBinary:         &stAsBinaryFunctionClass{baseFunctionClass{ast.STAsBinary, 1, 1}},
+	ast.STAsWKB:            &stAsBinaryFunctionClass{baseFunctionClass{ast.STAsWKB, 1, 1}},
+	ast.Point:              &pointFunctionClass{baseFunctionClass{ast.Point, 2, 2}},
+	ast.STX:                &stXFunctionClass{baseFunctionClass{ast.STX, 1, 1}},
+	ast.STY:                &stYFunctionClass{baseFunctionClass{ast.STY, 1, 1}},
+	ast.STSRID:             &stSRIDFunctionClass{baseFunctionClass{ast.STSRID, 1, 1}},
+	ast.STDistance:         &stDistanceFunctionClass{baseFunctionClass{ast.STDistance, 2, 2}},
+	ast.STContains:         &stContainsFunctionClass{baseFunctionClass{ast.STContains, 2, 2}},
+	ast.STWithin:           &stWithinFunctionClass{baseFunctionClass{ast.STWithin, 2, 2}},
+	ast.MBRContains:        &mbrContainsFunctionClass{baseFunctionClass{ast.MBRContains, 2, 2}},
+	ast.MBRWithin:          &mbrWithinFunctionClass{baseFunctionClass{ast.MBRWithin, 2, 2}},
 }
@@ -180,7 +180,10 @@ func (s *builtinArithmeticPlusIntSig) evalInt(row types.Row) (val int64, isNull
 	if isNull || err != nil {
 		return 0, isNull, errors.Trace(err)
 	}
+	return s.plus(a, b)
+}
 
+func (s *builtinArithmeticPlusIntSig) plus(a, b int64) (val int64, isNull bool, err error) {
 	isLHSUnsigned := mysql.HasUnsignedFlag(s.args[0].GetType().Flag)
 	isRHSUnsigned := mysql.HasUnsignedFlag(s.args[1].GetType().Flag)
 
@@ -248,6 +251,10 @@ func (s *builtinArithmeticPlusRealSig) evalReal(row types.Row) (float64, bool, e
 	if isNull || err != nil {
 		return 0, isNull, errors.Trace(err)
 	}
+	return s.plus(a, b)
+}
+
+func (s *builtinArithmeticPlusRealSig) plus(a, b float64) (float64, bool, error) {
 	if (a > 0 && b > math.MaxFloat64-a) || (a < 0 && b < -math.MaxFloat64-a) {
 		return 0, true, types.ErrOverflow.GenByArgs("DOUBLE", fmt.Sprintf("(%s + %s)", s.args[0].String(), s.args[1].String()))
 	}
@@ -303,6 +310,10 @@ func (s *builtinArithmeticMinusRealSig) evalReal(row types.Row) (float64, bool,
 	if isNull || err != nil {
 		return 0, isNull, errors.Trace(err)
 	}
+	return s.minus(a, b)
+}
+
+func (s *builtinArithmeticMinusRealSig) minus(a, b float64) (float64, bool, error) {
 	if (a > 0 && -b > math.MaxFloat64-a) || (a < 0 && -b < -math.MaxFloat64-a) {
 		return 0, true, types.ErrOverflow.GenByArgs("DOUBLE", fmt.Sprintf("(%s - %s)", s.args[0].String(), s.args[1].String()))
 	}
@@ -347,6 +358,10 @@ func (s *builtinArithmeticMinusIntSig) evalInt(row types.Row) (val int64, isNull
 	if isNull || err != nil {
 		return 0, isNull, errors.Trace(err)
 	}
+	return s.minus(a, b)
+}
+
+func (s *builtinArithmeticMinusIntSig) minus(a, b int64) (val int64, isNull bool, err error) {
 	forceToSigned := s.ctx.GetSessionVars().SQLMode.HasNoUnsignedSubtractionMode()
 	isLHSUnsigned := !forceToSigned && mysql.HasUnsignedFlag(s.args[0].GetType().Flag)
 	isRHSUnsigned := !forceToSigned && mysql.HasUnsignedFlag(s.args[1].GetType().Flag)
@@ -439,6 +454,10 @@ func (s *builtinArithmeticMultiplyRealSig) evalReal(row types.Row) (float64, boo
 	if isNull || err != nil {
 		return 0, isNull, errors.Trace(err)
 	}
+	return s.multiply(a, b)
+}
+
+func (s *builtinArithmeticMultiplyRealSig) multiply(a, b float64) (float64, bool, error) {
 	result := a * b
 	if math.IsInf(result, 0) {
 		return 0, true, types.ErrOverflow.GenByArgs("DOUBLE", fmt.Sprintf("(%s * %s)", s.args[0].String(), s.args[1].String()))
@@ -470,12 +489,15 @@ func (s *builtinArithmeticMultiplyIntUnsignedSig) evalInt(row types.Row) (val in
 	if isNull || err != nil {
 		return 0, isNull, errors.Trace(err)
 	}
-	unsignedA := uint64(a)
 	b, isNull, err := s.args[1].EvalInt(row, sc)
 	if isNull || err != nil {
 		return 0, isNull, errors.Trace(err)
 	}
-	unsignedB := uint64(b)
+	return s.multiply(a, b)
+}
+
+func (s *builtinArithmeticMultiplyIntUnsignedSig) multiply(a, b int64) (val int64, isNull bool, err error) {
+	unsignedA, unsignedB := uint64(a), uint64(b)
 	result := unsignedA * unsignedB
 	if unsignedA != 0 && result/unsignedA != unsignedB {
 		return 0, true, types.ErrOverflow.GenByArgs("BIGINT UNSIGNED", fmt.Sprintf("(%s * %s)", s.args[0].String(), s.args[1].String()))
@@ -493,6 +515,10 @@ func (s *builtinArithmeticMultiplyIntSig) evalInt(row types.Row) (val int64, isN
 	if isNull || err != nil {
 		return 0, isNull, errors.Trace(err)
 	}
+	return s.multiply(a, b)
+}
+
+func (s *builtinArithmeticMultiplyIntSig) multiply(a, b int64) (val int64, isNull bool, err error) {
 	result := a * b
 	if a != 0 && result/a != b {
 		return 0, true, types.ErrOverflow.GenByArgs("BIGINT", fmt.Sprintf("(%s * %s)", s.args[0].String(), s.args[1].String()))
@@ -536,6 +562,10 @@ func (s *builtinArithmeticDivideRealSig) evalReal(row types.Row) (float64, bool,
 	if isNull || err != nil {
 		return 0, isNull, errors.Trace(err)
 	}
+	return s.divide(a, b)
+}
+
+func (s *builtinArithmeticDivideRealSig) divide(a, b float64) (float64, bool, error) {
 	if b == 0 {
 		return 0, true, errors.Trace(handleDivisionByZeroError(s.ctx))
 	}
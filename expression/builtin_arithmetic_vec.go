@@ -0,0 +1,130 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/chunk"
+)
+
+// vecArithmeticInt applies calc to the two int arguments of b on the rows where none of them is null.
+func vecArithmeticInt(b *baseBuiltinFunc, input *chunk.Chunk, result *chunk.Column, calc func(a, b int64) (int64, bool, error)) error {
+	arg0, arg1, err := vecEvalIntArgs(b, input, result)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	vals0, vals1, res := arg0.Int64s(), arg1.Int64s(), result.Int64s()
+	for i := range res {
+		if result.IsNull(i) {
+			continue
+		}
+		val, isNull, err := calc(vals0[i], vals1[i])
+		if err != nil {
+			return errors.Trace(err)
+		}
+		res[i] = val
+		if isNull {
+			result.SetNull(i, true)
+		}
+	}
+	return nil
+}
+
+// vecArithmeticReal applies calc to the two real arguments of b on the rows where none of them is null.
+func vecArithmeticReal(b *baseBuiltinFunc, input *chunk.Chunk, result *chunk.Column, calc func(a, b float64) (float64, bool, error)) error {
+	arg0, arg1, err := vecEvalRealArgs(b, input, types.ETReal, result)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	vals0, vals1, res := arg0.Float64s(), arg1.Float64s(), result.Float64s()
+	for i := range res {
+		if result.IsNull(i) {
+			continue
+		}
+		val, isNull, err := calc(vals0[i], vals1[i])
+		if err != nil {
+			return errors.Trace(err)
+		}
+		res[i] = val
+		if isNull {
+			result.SetNull(i, true)
+		}
+	}
+	return nil
+}
+
+func (s *builtinArithmeticPlusIntSig) vectorized() bool {
+	return true
+}
+
+func (s *builtinArithmeticPlusIntSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column) error {
+	return errors.Trace(vecArithmeticInt(&s.baseBuiltinFunc, input, result, s.plus))
+}
+
+func (s *builtinArithmeticMinusIntSig) vectorized() bool {
+	return true
+}
+
+func (s *builtinArithmeticMinusIntSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column) error {
+	return errors.Trace(vecArithmeticInt(&s.baseBuiltinFunc, input, result, s.minus))
+}
+
+func (s *builtinArithmeticMultiplyIntSig) vectorized() bool {
+	return true
+}
+
+func (s *builtinArithmeticMultiplyIntSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column) error {
+	return errors.Trace(vecArithmeticInt(&s.baseBuiltinFunc, input, result, s.multiply))
+}
+
+func (s *builtinArithmeticMultiplyIntUnsignedSig) vectorized() bool {
+	return true
+}
+
+func (s *builtinArithmeticMultiplyIntUnsignedSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column) error {
+	return errors.Trace(vecArithmeticInt(&s.baseBuiltinFunc, input, result, s.multiply))
+}
+
+func (s *builtinArithmeticPlusRealSig) vectorized() bool {
+	return true
+}
+
+func (s *builtinArithmeticPlusRealSig) vecEvalReal(input *chunk.Chunk, result *chunk.Column) error {
+	return errors.Trace(vecArithmeticReal(&s.baseBuiltinFunc, input, result, s.plus))
+}
+
+func (s *builtinArithmeticMinusRealSig) vectorized() bool {
+	return true
+}
+
+func (s *builtinArithmeticMinusRealSig) vecEvalReal(input *chunk.Chunk, result *chunk.Column) error {
+	return errors.Trace(vecArithmeticReal(&s.baseBuiltinFunc, input, result, s.minus))
+}
+
+func (s *builtinArithmeticMultiplyRealSig) vectorized() bool {
+	return true
+}
+
+func (s *builtinArithmeticMultiplyRealSig) vecEvalReal(input *chunk.Chunk, result *chunk.Column) error {
+	return errors.Trace(vecArithmeticReal(&s.baseBuiltinFunc, input, result, s.multiply))
+}
+
+func (s *builtinArithmeticDivideRealSig) vectorized() bool {
+	return true
+}
+
+func (s *builtinArithmeticDivideRealSig) vecEvalReal(input *chunk.Chunk, result *chunk.Column) error {
+	return errors.Trace(vecArithmeticReal(&s.baseBuiltinFunc, input, result, s.divide))
+}
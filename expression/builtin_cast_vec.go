@@ -0,0 +1,102 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/chunk"
+)
+
+func (b *builtinCastIntAsIntSig) vectorized() bool {
+	return true
+}
+
+func (b *builtinCastIntAsIntSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column) error {
+	return errors.Trace(vecEvalExprInt(b.ctx.GetSessionVars().StmtCtx, b.args[0], input, result))
+}
+
+func (b *builtinCastIntAsRealSig) vectorized() bool {
+	return true
+}
+
+func (b *builtinCastIntAsRealSig) vecEvalReal(input *chunk.Chunk, result *chunk.Column) error {
+	numRows := input.NumRows()
+	arg := newVecBuffer(types.ETInt, numRows)
+	if err := vecEvalExprInt(b.ctx.GetSessionVars().StmtCtx, b.args[0], input, arg); err != nil {
+		return errors.Trace(err)
+	}
+	result.ResizeFloat64(numRows)
+	result.MergeNulls(arg)
+	isUnsigned := mysql.HasUnsignedFlag(b.args[0].GetType().Flag)
+	vals, res := arg.Int64s(), result.Float64s()
+	for i, val := range vals {
+		if !isUnsigned {
+			res[i] = float64(val)
+			continue
+		}
+		if result.IsNull(i) {
+			continue
+		}
+		uVal, err := types.ConvertIntToUint(val, types.UnsignedUpperBound[mysql.TypeLonglong], mysql.TypeLonglong)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		res[i] = float64(uVal)
+	}
+	return nil
+}
+
+func (b *builtinCastRealAsRealSig) vectorized() bool {
+	return true
+}
+
+func (b *builtinCastRealAsRealSig) vecEvalReal(input *chunk.Chunk, result *chunk.Column) error {
+	return errors.Trace(vecEvalExprReal(b.ctx.GetSessionVars().StmtCtx, b.args[0], input, result))
+}
+
+func (b *builtinCastRealAsIntSig) vectorized() bool {
+	return true
+}
+
+func (b *builtinCastRealAsIntSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column) error {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	numRows := input.NumRows()
+	arg := newVecBuffer(types.ETReal, numRows)
+	if err := vecEvalExprReal(sc, b.args[0], input, arg); err != nil {
+		return errors.Trace(err)
+	}
+	result.ResizeInt64(numRows)
+	result.MergeNulls(arg)
+	isUnsigned := mysql.HasUnsignedFlag(b.tp.Flag)
+	vals, res := arg.Float64s(), result.Int64s()
+	for i, val := range vals {
+		if result.IsNull(i) {
+			continue
+		}
+		var err error
+		if !isUnsigned {
+			res[i], err = types.ConvertFloatToInt(sc, val, types.SignedLowerBound[mysql.TypeLonglong], types.SignedUpperBound[mysql.TypeLonglong], mysql.TypeDouble)
+		} else {
+			var uintVal uint64
+			uintVal, err = types.ConvertFloatToUint(sc, val, types.UnsignedUpperBound[mysql.TypeLonglong], mysql.TypeDouble)
+			res[i] = int64(uintVal)
+		}
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
@@ -361,6 +361,11 @@ func (c *greatestFunctionClass) getFunction(ctx context.Context, args []Expressi
 		return nil, errors.Trace(err)
 	}
 	tp, cmpAsDatetime := getCmpTp4MinMax(args), false
+	if tp == types.ETString {
+		if _, err = checkIllegalMixCollation(c.funcName, args); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
 	if tp == types.ETDatetime {
 		cmpAsDatetime = true
 		tp = types.ETString
@@ -481,7 +486,7 @@ func (b *builtinGreatestStringSig) evalString(row types.Row) (max string, isNull
 		if isNull || err != nil {
 			return max, isNull, errors.Trace(err)
 		}
-		if types.CompareString(v, max) > 0 {
+		if compareStringWithCollation(v, max, b.collation) > 0 {
 			max = v
 		}
 	}
@@ -529,6 +534,11 @@ func (c *leastFunctionClass) getFunction(ctx context.Context, args []Expression)
 		return nil, errors.Trace(err)
 	}
 	tp, cmpAsDatetime := getCmpTp4MinMax(args), false
+	if tp == types.ETString {
+		if _, err = checkIllegalMixCollation(c.funcName, args); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
 	if tp == types.ETDatetime {
 		cmpAsDatetime = true
 		tp = types.ETString
@@ -649,7 +659,7 @@ func (b *builtinLeastStringSig) evalString(row types.Row) (min string, isNull bo
 		if isNull || err != nil {
 			return min, isNull, errors.Trace(err)
 		}
-		if types.CompareString(v, min) < 0 {
+		if compareStringWithCollation(v, min, b.collation) < 0 {
 			min = v
 		}
 	}
@@ -1012,6 +1022,11 @@ func (c *compareFunctionClass) getFunction(ctx context.Context, rawArgs []Expres
 	}
 	args := c.refineArgs(ctx, rawArgs)
 	cmpType := GetAccurateCmpType(args[0], args[1])
+	if cmpType == types.ETString {
+		if _, err = checkIllegalMixCollation(c.funcName, args); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
 	sig, err = c.generateCmpSigs(ctx, args, cmpType)
 	return sig, errors.Trace(err)
 }
@@ -1228,7 +1243,7 @@ type builtinLTStringSig struct {
 }
 
 func (s *builtinLTStringSig) evalInt(row types.Row) (val int64, isNull bool, err error) {
-	return resOfLT(compareString(s.args, row, s.ctx))
+	return resOfLT(compareString(s.args, row, s.ctx, s.collation))
 }
 
 type builtinLTDurationSig struct {
@@ -1284,7 +1299,7 @@ type builtinLEStringSig struct {
 }
 
 func (s *builtinLEStringSig) evalInt(row types.Row) (val int64, isNull bool, err error) {
-	return resOfLE(compareString(s.args, row, s.ctx))
+	return resOfLE(compareString(s.args, row, s.ctx, s.collation))
 }
 
 type builtinLEDurationSig struct {
@@ -1340,7 +1355,7 @@ type builtinGTStringSig struct {
 }
 
 func (s *builtinGTStringSig) evalInt(row types.Row) (val int64, isNull bool, err error) {
-	return resOfGT(compareString(s.args, row, s.ctx))
+	return resOfGT(compareString(s.args, row, s.ctx, s.collation))
 }
 
 type builtinGTDurationSig struct {
@@ -1396,7 +1411,7 @@ type builtinGEStringSig struct {
 }
 
 func (s *builtinGEStringSig) evalInt(row types.Row) (val int64, isNull bool, err error) {
-	return resOfGE(compareString(s.args, row, s.ctx))
+	return resOfGE(compareString(s.args, row, s.ctx, s.collation))
 }
 
 type builtinGEDurationSig struct {
@@ -1452,7 +1467,7 @@ type builtinEQStringSig struct {
 }
 
 func (s *builtinEQStringSig) evalInt(row types.Row) (val int64, isNull bool, err error) {
-	return resOfEQ(compareString(s.args, row, s.ctx))
+	return resOfEQ(compareString(s.args, row, s.ctx, s.collation))
 }
 
 type builtinEQDurationSig struct {
@@ -1508,7 +1523,7 @@ type builtinNEStringSig struct {
 }
 
 func (s *builtinNEStringSig) evalInt(row types.Row) (val int64, isNull bool, err error) {
-	return resOfNE(compareString(s.args, row, s.ctx))
+	return resOfNE(compareString(s.args, row, s.ctx, s.collation))
 }
 
 type builtinNEDurationSig struct {
@@ -1650,7 +1665,7 @@ func (s *builtinNullEQStringSig) evalInt(row types.Row) (val int64, isNull bool,
 		res = 1
 	case isNull0 != isNull1:
 		break
-	case types.CompareString(arg0, arg1) == 0:
+	case compareStringWithCollation(arg0, arg1, s.collation) == 0:
 		res = 1
 	}
 	return res, false, nil
@@ -1820,29 +1835,27 @@ func compareInt(args []Expression, row types.Row, ctx context.Context) (val int6
 		return 0, isNull1, errors.Trace(err)
 	}
 	isUnsigned0, isUnsigned1 := mysql.HasUnsignedFlag(args[0].GetType().Flag), mysql.HasUnsignedFlag(args[1].GetType().Flag)
-	var res int
+	return int64(compareIntWithSign(arg0, arg1, isUnsigned0, isUnsigned1)), false, nil
+}
+
+// compareIntWithSign compares two int64 values, isUnsigned0 and isUnsigned1 indicate whether they hold uint64 values.
+func compareIntWithSign(arg0, arg1 int64, isUnsigned0, isUnsigned1 bool) int {
 	switch {
 	case isUnsigned0 && isUnsigned1:
-		res = types.CompareUint64(uint64(arg0), uint64(arg1))
+		return types.CompareUint64(uint64(arg0), uint64(arg1))
 	case isUnsigned0 && !isUnsigned1:
 		if arg1 < 0 || arg0 > math.MaxInt64 {
-			res = 1
-		} else {
-			res = types.CompareInt64(arg0, arg1)
+			return 1
 		}
 	case !isUnsigned0 && isUnsigned1:
 		if arg0 < 0 || arg1 > math.MaxInt64 {
-			res = -1
-		} else {
-			res = types.CompareInt64(arg0, arg1)
+			return -1
 		}
-	case !isUnsigned0 && !isUnsigned1:
-		res = types.CompareInt64(arg0, arg1)
 	}
-	return int64(res), false, nil
+	return types.CompareInt64(arg0, arg1)
 }
 
-func compareString(args []Expression, row types.Row, ctx context.Context) (val int64, isNull bool, err error) {
+func compareString(args []Expression, row types.Row, ctx context.Context, collation string) (val int64, isNull bool, err error) {
 	sc := ctx.GetSessionVars().StmtCtx
 	arg0, isNull0, err := args[0].EvalString(row, sc)
 	if isNull0 || err != nil {
@@ -1852,7 +1865,7 @@ func compareString(args []Expression, row types.Row, ctx context.Context) (val i
 	if isNull1 || err != nil {
 		return 0, isNull1, errors.Trace(err)
 	}
-	return int64(types.CompareString(arg0, arg1)), false, nil
+	return int64(compareStringWithCollation(arg0, arg1, collation)), false, nil
 }
 
 func compareReal(args []Expression, row types.Row, ctx context.Context) (val int64, isNull bool, err error) {
@@ -0,0 +1,353 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/chunk"
+)
+
+// vecCompareInt stores the compare results of the two int arguments of b in result.
+func vecCompareInt(b *baseBuiltinFunc, input *chunk.Chunk, result *chunk.Column) error {
+	arg0, arg1, err := vecEvalIntArgs(b, input, result)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	isUnsigned0, isUnsigned1 := mysql.HasUnsignedFlag(b.args[0].GetType().Flag), mysql.HasUnsignedFlag(b.args[1].GetType().Flag)
+	vals0, vals1, res := arg0.Int64s(), arg1.Int64s(), result.Int64s()
+	for i := range res {
+		res[i] = int64(compareIntWithSign(vals0[i], vals1[i], isUnsigned0, isUnsigned1))
+	}
+	return nil
+}
+
+// vecCompareReal stores the compare results of the two real arguments of b in result.
+func vecCompareReal(b *baseBuiltinFunc, input *chunk.Chunk, result *chunk.Column) error {
+	arg0, arg1, err := vecEvalRealArgs(b, input, types.ETInt, result)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	vals0, vals1, res := arg0.Float64s(), arg1.Float64s(), result.Int64s()
+	for i := range res {
+		res[i] = int64(types.CompareFloat64(vals0[i], vals1[i]))
+	}
+	return nil
+}
+
+// vecCompareString stores the compare results of the two string arguments of b in result.
+func vecCompareString(b *baseBuiltinFunc, input *chunk.Chunk, result *chunk.Column) error {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	numRows := input.NumRows()
+	arg0, arg1 := newVecBuffer(types.ETString, numRows), newVecBuffer(types.ETString, numRows)
+	if err := vecEvalExprString(sc, b.args[0], input, arg0); err != nil {
+		return errors.Trace(err)
+	}
+	if err := vecEvalExprString(sc, b.args[1], input, arg1); err != nil {
+		return errors.Trace(err)
+	}
+	result.ResizeInt64(numRows)
+	result.MergeNulls(arg0, arg1)
+	res := result.Int64s()
+	for i := range res {
+		if result.IsNull(i) {
+			continue
+		}
+		res[i] = int64(compareStringWithCollation(arg0.GetString(i), arg1.GetString(i), b.collation))
+	}
+	return nil
+}
+
+// vecResOfLT converts the compare results in vals to whether the left argument is less than the right one.
+func vecResOfLT(vals []int64) {
+	for i, val := range vals {
+		if val < 0 {
+			vals[i] = 1
+		} else {
+			vals[i] = 0
+		}
+	}
+}
+
+// vecResOfLE converts the compare results in vals to whether the left argument is less than or equal to the right one.
+func vecResOfLE(vals []int64) {
+	for i, val := range vals {
+		if val <= 0 {
+			vals[i] = 1
+		} else {
+			vals[i] = 0
+		}
+	}
+}
+
+// vecResOfGT converts the compare results in vals to whether the left argument is greater than the right one.
+func vecResOfGT(vals []int64) {
+	for i, val := range vals {
+		if val > 0 {
+			vals[i] = 1
+		} else {
+			vals[i] = 0
+		}
+	}
+}
+
+// vecResOfGE converts the compare results in vals to whether the left argument is greater than or equal to the right one.
+func vecResOfGE(vals []int64) {
+	for i, val := range vals {
+		if val >= 0 {
+			vals[i] = 1
+		} else {
+			vals[i] = 0
+		}
+	}
+}
+
+// vecResOfEQ converts the compare results in vals to whether the left argument is equal to the right one.
+func vecResOfEQ(vals []int64) {
+	for i, val := range vals {
+		if val == 0 {
+			vals[i] = 1
+		} else {
+			vals[i] = 0
+		}
+	}
+}
+
+// vecResOfNE converts the compare results in vals to whether the left argument is not equal to the right one.
+func vecResOfNE(vals []int64) {
+	for i, val := range vals {
+		if val != 0 {
+			vals[i] = 1
+		} else {
+			vals[i] = 0
+		}
+	}
+}
+
+func (s *builtinLTIntSig) vectorized() bool {
+	return true
+}
+
+func (s *builtinLTIntSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column) error {
+	if err := vecCompareInt(&s.baseBuiltinFunc, input, result); err != nil {
+		return errors.Trace(err)
+	}
+	vecResOfLT(result.Int64s())
+	return nil
+}
+
+func (s *builtinLTRealSig) vectorized() bool {
+	return true
+}
+
+func (s *builtinLTRealSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column) error {
+	if err := vecCompareReal(&s.baseBuiltinFunc, input, result); err != nil {
+		return errors.Trace(err)
+	}
+	vecResOfLT(result.Int64s())
+	return nil
+}
+
+func (s *builtinLTStringSig) vectorized() bool {
+	return true
+}
+
+func (s *builtinLTStringSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column) error {
+	if err := vecCompareString(&s.baseBuiltinFunc, input, result); err != nil {
+		return errors.Trace(err)
+	}
+	vecResOfLT(result.Int64s())
+	return nil
+}
+
+func (s *builtinLEIntSig) vectorized() bool {
+	return true
+}
+
+func (s *builtinLEIntSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column) error {
+	if err := vecCompareInt(&s.baseBuiltinFunc, input, result); err != nil {
+		return errors.Trace(err)
+	}
+	vecResOfLE(result.Int64s())
+	return nil
+}
+
+func (s *builtinLERealSig) vectorized() bool {
+	return true
+}
+
+func (s *builtinLERealSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column) error {
+	if err := vecCompareReal(&s.baseBuiltinFunc, input, result); err != nil {
+		return errors.Trace(err)
+	}
+	vecResOfLE(result.Int64s())
+	return nil
+}
+
+func (s *builtinLEStringSig) vectorized() bool {
+	return true
+}
+
+func (s *builtinLEStringSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column) error {
+	if err := vecCompareString(&s.baseBuiltinFunc, input, result); err != nil {
+		return errors.Trace(err)
+	}
+	vecResOfLE(result.Int64s())
+	return nil
+}
+
+func (s *builtinGTIntSig) vectorized() bool {
+	return true
+}
+
+func (s *builtinGTIntSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column) error {
+	if err := vecCompareInt(&s.baseBuiltinFunc, input, result); err != nil {
+		return errors.Trace(err)
+	}
+	vecResOfGT(result.Int64s())
+	return nil
+}
+
+func (s *builtinGTRealSig) vectorized() bool {
+	return true
+}
+
+func (s *builtinGTRealSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column) error {
+	if err := vecCompareReal(&s.baseBuiltinFunc, input, result); err != nil {
+		return errors.Trace(err)
+	}
+	vecResOfGT(result.Int64s())
+	return nil
+}
+
+func (s *builtinGTStringSig) vectorized() bool {
+	return true
+}
+
+func (s *builtinGTStringSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column) error {
+	if err := vecCompareString(&s.baseBuiltinFunc, input, result); err != nil {
+		return errors.Trace(err)
+	}
+	vecResOfGT(result.Int64s())
+	return nil
+}
+
+func (s *builtinGEIntSig) vectorized() bool {
+	return true
+}
+
+func (s *builtinGEIntSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column) error {
+	if err := vecCompareInt(&s.baseBuiltinFunc, input, result); err != nil {
+		return errors.Trace(err)
+	}
+	vecResOfGE(result.Int64s())
+	return nil
+}
+
+func (s *builtinGERealSig) vectorized() bool {
+	return true
+}
+
+func (s *builtinGERealSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column) error {
+	if err := vecCompareReal(&s.baseBuiltinFunc, input, result); err != nil {
+		return errors.Trace(err)
+	}
+	vecResOfGE(result.Int64s())
+	return nil
+}
+
+func (s *builtinGEStringSig) vectorized() bool {
+	return true
+}
+
+func (s *builtinGEStringSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column) error {
+	if err := vecCompareString(&s.baseBuiltinFunc, input, result); err != nil {
+		return errors.Trace(err)
+	}
+	vecResOfGE(result.Int64s())
+	return nil
+}
+
+func (s *builtinEQIntSig) vectorized() bool {
+	return true
+}
+
+func (s *builtinEQIntSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column) error {
+	if err := vecCompareInt(&s.baseBuiltinFunc, input, result); err != nil {
+		return errors.Trace(err)
+	}
+	vecResOfEQ(result.Int64s())
+	return nil
+}
+
+func (s *builtinEQRealSig) vectorized() bool {
+	return true
+}
+
+func (s *builtinEQRealSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column) error {
+	if err := vecCompareReal(&s.baseBuiltinFunc, input, result); err != nil {
+		return errors.Trace(err)
+	}
+	vecResOfEQ(result.Int64s())
+	return nil
+}
+
+func (s *builtinEQStringSig) vectorized() bool {
+	return true
+}
+
+func (s *builtinEQStringSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column) error {
+	if err := vecCompareString(&s.baseBuiltinFunc, input, result); err != nil {
+		return errors.Trace(err)
+	}
+	vecResOfEQ(result.Int64s())
+	return nil
+}
+
+func (s *builtinNEIntSig) vectorized() bool {
+	return true
+}
+
+func (s *builtinNEIntSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column) error {
+	if err := vecCompareInt(&s.baseBuiltinFunc, input, result); err != nil {
+		return errors.Trace(err)
+	}
+	vecResOfNE(result.Int64s())
+	return nil
+}
+
+func (s *builtinNERealSig) vectorized() bool {
+	return true
+}
+
+func (s *builtinNERealSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column) error {
+	if err := vecCompareReal(&s.baseBuiltinFunc, input, result); err != nil {
+		return errors.Trace(err)
+	}
+	vecResOfNE(result.Int64s())
+	return nil
+}
+
+func (s *builtinNEStringSig) vectorized() bool {
+	return true
+}
+
+func (s *builtinNEStringSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column) error {
+	if err := vecCompareString(&s.baseBuiltinFunc, input, result); err != nil {
+		return errors.Trace(err)
+	}
+	vecResOfNE(result.Int64s())
+	return nil
+}
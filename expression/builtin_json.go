@@ -14,6 +14,8 @@
 package expression
 
 import (
+	"strings"
+
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
@@ -21,6 +23,7 @@ import (
 	"github.com/pingcap/tidb/sessionctx/stmtctx"
 	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/types/json"
+	"github.com/pingcap/tidb/util/stringutil"
 	"github.com/pingcap/tipb/go-tipb"
 )
 
@@ -51,6 +54,17 @@ var (
 	_ functionClass = &jsonMergeFunctionClass{}
 	_ functionClass = &jsonObjectFunctionClass{}
 	_ functionClass = &jsonArrayFunctionClass{}
+	_ functionClass = &jsonContainsPathFunctionClass{}
+	_ functionClass = &jsonKeysFunctionClass{}
+	_ functionClass = &jsonLengthFunctionClass{}
+	_ functionClass = &jsonDepthFunctionClass{}
+	_ functionClass = &jsonSearchFunctionClass{}
+	_ functionClass = &jsonArrayAppendFunctionClass{}
+	_ functionClass = &jsonArrayInsertFunctionClass{}
+	_ functionClass = &jsonMergePatchFunctionClass{}
+	_ functionClass = &jsonQuoteFunctionClass{}
+	_ functionClass = &jsonPrettyFunctionClass{}
+	_ functionClass = &jsonStorageSizeFunctionClass{}
 
 	// Type of JSON value.
 	_ builtinFunc = &builtinJSONTypeSig{}
@@ -72,6 +86,34 @@ var (
 	_ builtinFunc = &builtinJSONRemoveSig{}
 	// Merge JSON documents, preserving duplicate keys.
 	_ builtinFunc = &builtinJSONMergeSig{}
+	// Whether JSON document contains any data at path.
+	_ builtinFunc = &builtinJSONContainsPathSig{}
+	// Keys from JSON object.
+	_ builtinFunc = &builtinJSONKeysSig{}
+	// Number of elements in JSON document.
+	_ builtinFunc = &builtinJSONLengthSig{}
+	// Maximum depth of JSON document.
+	_ builtinFunc = &builtinJSONDepthSig{}
+	// Path to value within JSON document.
+	_ builtinFunc = &builtinJSONSearchSig{}
+	// Append data to JSON document.
+	_ builtinFunc = &builtinJSONArrayAppendSig{}
+	// Insert into JSON array.
+	_ builtinFunc = &builtinJSONArrayInsertSig{}
+	// Merge JSON documents, replacing values of duplicate keys.
+	_ builtinFunc = &builtinJSONMergePatchSig{}
+	// Quote JSON document.
+	_ builtinFunc = &builtinJSONQuoteSig{}
+	// Print a JSON document in human-readable format.
+	_ builtinFunc = &builtinJSONPrettySig{}
+	// Space used to store binary representation of a JSON document.
+	_ builtinFunc = &builtinJSONStorageSizeSig{}
+)
+
+// The values of the oneOrAll argument of JSON_CONTAINS_PATH and JSON_SEARCH.
+const (
+	jsonOneOrAllOne = "one"
+	jsonOneOrAllAll = "all"
 )
 
 type jsonTypeFunctionClass struct {
@@ -416,8 +458,7 @@ func (b *builtinJSONObjectSig) evalJSON(row types.Row) (res json.BinaryJSON, isN
 				return res, true, errors.Trace(err)
 			}
 			if isNull {
-				err = errors.New("JSON documents may not contain NULL member names")
-				return res, true, errors.Trace(err)
+				return res, true, json.ErrJSONDocumentNULLKey
 			}
 		} else {
 			value, isNull, err = arg.EvalJSON(row, sc)
@@ -511,3 +552,507 @@ func jsonModify(args []Expression, row types.Row, mt json.ModifyType, sc *stmtct
 	}
 	return res, false, nil
 }
+
+// evalJSONPathExprs evaluates the path expressions in args.
+func evalJSONPathExprs(args []Expression, row types.Row, sc *stmtctx.StatementContext) (pathExprs []json.PathExpression, isNull bool, err error) {
+	pathExprs = make([]json.PathExpression, 0, len(args))
+	for _, arg := range args {
+		var s string
+		s, isNull, err = arg.EvalString(row, sc)
+		if isNull || err != nil {
+			return nil, isNull, errors.Trace(err)
+		}
+		var pathExpr json.PathExpression
+		pathExpr, err = json.ParseJSONPathExpr(s)
+		if err != nil {
+			return nil, true, errors.Trace(err)
+		}
+		pathExprs = append(pathExprs, pathExpr)
+	}
+	return pathExprs, false, nil
+}
+
+// evalJSONOneOrAll evaluates the oneOrAll argument of JSON_CONTAINS_PATH and JSON_SEARCH.
+func evalJSONOneOrAll(arg Expression, row types.Row, sc *stmtctx.StatementContext, funcName string) (oneOrAll string, isNull bool, err error) {
+	oneOrAll, isNull, err = arg.EvalString(row, sc)
+	if isNull || err != nil {
+		return "", isNull, errors.Trace(err)
+	}
+	oneOrAll = strings.ToLower(oneOrAll)
+	if oneOrAll != jsonOneOrAllOne && oneOrAll != jsonOneOrAllAll {
+		return "", true, json.ErrJSONBadOneOrAllArg.GenByArgs(funcName)
+	}
+	return oneOrAll, false, nil
+}
+
+type jsonContainsPathFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinJSONContainsPathSig struct {
+	baseBuiltinFunc
+}
+
+func (c *jsonContainsPathFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	argTps := make([]types.EvalType, 0, len(args))
+	argTps = append(argTps, types.ETJson)
+	for range args[1:] {
+		argTps = append(argTps, types.ETString)
+	}
+	bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETInt, argTps...)
+	bf.tp.Flen = 1
+	sig := &builtinJSONContainsPathSig{bf}
+	return sig, nil
+}
+
+func (b *builtinJSONContainsPathSig) evalInt(row types.Row) (res int64, isNull bool, err error) {
+	sc := b.getCtx().GetSessionVars().StmtCtx
+	obj, isNull, err := b.args[0].EvalJSON(row, sc)
+	if isNull || err != nil {
+		return res, isNull, errors.Trace(err)
+	}
+	oneOrAll, isNull, err := evalJSONOneOrAll(b.args[1], row, sc, ast.JSONContainsPath)
+	if isNull || err != nil {
+		return res, isNull, errors.Trace(err)
+	}
+	pathExprs, isNull, err := evalJSONPathExprs(b.args[2:], row, sc)
+	if isNull || err != nil {
+		return res, isNull, errors.Trace(err)
+	}
+	for _, pathExpr := range pathExprs {
+		_, found := obj.Extract([]json.PathExpression{pathExpr})
+		if found && oneOrAll == jsonOneOrAllOne {
+			return 1, false, nil
+		}
+		if !found && oneOrAll == jsonOneOrAllAll {
+			return 0, false, nil
+		}
+	}
+	if oneOrAll == jsonOneOrAllOne {
+		return 0, false, nil
+	}
+	return 1, false, nil
+}
+
+type jsonKeysFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinJSONKeysSig struct {
+	baseBuiltinFunc
+}
+
+func (c *jsonKeysFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	argTps := []types.EvalType{types.ETJson}
+	if len(args) == 2 {
+		argTps = append(argTps, types.ETString)
+	}
+	bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETJson, argTps...)
+	sig := &builtinJSONKeysSig{bf}
+	return sig, nil
+}
+
+func (b *builtinJSONKeysSig) evalJSON(row types.Row) (res json.BinaryJSON, isNull bool, err error) {
+	sc := b.getCtx().GetSessionVars().StmtCtx
+	res, isNull, err = extractJSONWithoutWildcard(b.args, row, sc)
+	if isNull || err != nil {
+		return res, isNull, errors.Trace(err)
+	}
+	if res.TypeCode != json.TypeCodeObject {
+		return res, true, nil
+	}
+	return res.GetKeys(), false, nil
+}
+
+// extractJSONWithoutWildcard evaluates the JSON document in args[0], and extracts
+// the value of the optional path in args[1], which can't contain any wildcard.
+func extractJSONWithoutWildcard(args []Expression, row types.Row, sc *stmtctx.StatementContext) (res json.BinaryJSON, isNull bool, err error) {
+	res, isNull, err = args[0].EvalJSON(row, sc)
+	if isNull || err != nil || len(args) == 1 {
+		return res, isNull, errors.Trace(err)
+	}
+	pathExprs, isNull, err := evalJSONPathExprs(args[1:], row, sc)
+	if isNull || err != nil {
+		return res, isNull, errors.Trace(err)
+	}
+	if pathExprs[0].ContainsAnyAsterisk() {
+		return res, true, json.ErrInvalidJSONPathWildcard
+	}
+	res, found := res.Extract(pathExprs)
+	return res, !found, nil
+}
+
+type jsonLengthFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinJSONLengthSig struct {
+	baseBuiltinFunc
+}
+
+func (c *jsonLengthFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	argTps := []types.EvalType{types.ETJson}
+	if len(args) == 2 {
+		argTps = append(argTps, types.ETString)
+	}
+	bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETInt, argTps...)
+	sig := &builtinJSONLengthSig{bf}
+	return sig, nil
+}
+
+func (b *builtinJSONLengthSig) evalInt(row types.Row) (res int64, isNull bool, err error) {
+	sc := b.getCtx().GetSessionVars().StmtCtx
+	obj, isNull, err := extractJSONWithoutWildcard(b.args, row, sc)
+	if isNull || err != nil {
+		return res, isNull, errors.Trace(err)
+	}
+	if obj.TypeCode != json.TypeCodeObject && obj.TypeCode != json.TypeCodeArray {
+		return 1, false, nil
+	}
+	return int64(obj.GetElemCount()), false, nil
+}
+
+type jsonDepthFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinJSONDepthSig struct {
+	baseBuiltinFunc
+}
+
+func (c *jsonDepthFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETInt, types.ETJson)
+	sig := &builtinJSONDepthSig{bf}
+	return sig, nil
+}
+
+func (b *builtinJSONDepthSig) evalInt(row types.Row) (res int64, isNull bool, err error) {
+	obj, isNull, err := b.args[0].EvalJSON(row, b.getCtx().GetSessionVars().StmtCtx)
+	if isNull || err != nil {
+		return res, isNull, errors.Trace(err)
+	}
+	return int64(obj.GetElemDepth()), false, nil
+}
+
+type jsonSearchFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinJSONSearchSig struct {
+	baseBuiltinFunc
+}
+
+func (c *jsonSearchFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	// json_doc, one_or_all, search_str[, escape_char[, path] ...]
+	argTps := make([]types.EvalType, 0, len(args))
+	argTps = append(argTps, types.ETJson)
+	for range args[1:] {
+		argTps = append(argTps, types.ETString)
+	}
+	bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETJson, argTps...)
+	sig := &builtinJSONSearchSig{bf}
+	return sig, nil
+}
+
+func (b *builtinJSONSearchSig) evalJSON(row types.Row) (res json.BinaryJSON, isNull bool, err error) {
+	sc := b.getCtx().GetSessionVars().StmtCtx
+	obj, isNull, err := b.args[0].EvalJSON(row, sc)
+	if isNull || err != nil {
+		return res, isNull, errors.Trace(err)
+	}
+	oneOrAll, isNull, err := evalJSONOneOrAll(b.args[1], row, sc, ast.JSONSearch)
+	if isNull || err != nil {
+		return res, isNull, errors.Trace(err)
+	}
+	searchStr, isNull, err := b.args[2].EvalString(row, sc)
+	if isNull || err != nil {
+		return res, isNull, errors.Trace(err)
+	}
+	// The escape character is backslash if it's NULL or empty, like LIKE.
+	escape := byte('\\')
+	if len(b.args) >= 4 {
+		escapeStr, isNull, err := b.args[3].EvalString(row, sc)
+		if err != nil {
+			return res, true, errors.Trace(err)
+		}
+		if !isNull && len(escapeStr) > 1 {
+			return res, true, errIncorrectArgs.GenByArgs("ESCAPE")
+		}
+		if !isNull && len(escapeStr) == 1 {
+			escape = escapeStr[0]
+		}
+	}
+	var pathExprs []json.PathExpression
+	if len(b.args) >= 5 {
+		pathExprs, isNull, err = evalJSONPathExprs(b.args[4:], row, sc)
+		if isNull || err != nil {
+			return res, isNull, errors.Trace(err)
+		}
+	}
+	patChars, patTypes := stringutil.CompilePattern(searchStr, escape)
+	// The paths matched by several path expressions are returned only once.
+	var matched []interface{}
+	visited := make(map[string]struct{})
+	err = obj.Walk(func(fullpath json.PathExpression, bj json.BinaryJSON) (bool, error) {
+		if bj.TypeCode != json.TypeCodeString || !stringutil.DoMatch(string(bj.GetString()), patChars, patTypes) {
+			return false, nil
+		}
+		path := fullpath.String()
+		if _, ok := visited[path]; !ok {
+			visited[path] = struct{}{}
+			matched = append(matched, path)
+		}
+		return oneOrAll == jsonOneOrAllOne, nil
+	}, pathExprs...)
+	if err != nil {
+		return res, true, errors.Trace(err)
+	}
+	switch len(matched) {
+	case 0:
+		return res, true, nil
+	case 1:
+		return json.CreateBinary(matched[0]), false, nil
+	}
+	return json.CreateBinary(matched), false, nil
+}
+
+type jsonArrayAppendFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinJSONArrayAppendSig struct {
+	baseBuiltinFunc
+}
+
+func (c *jsonArrayAppendFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	bf, err := newJSONArrayModifyFunc(ctx, &c.baseFunctionClass, args)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinJSONArrayAppendSig{bf}
+	return sig, nil
+}
+
+func (b *builtinJSONArrayAppendSig) evalJSON(row types.Row) (res json.BinaryJSON, isNull bool, err error) {
+	sc := b.getCtx().GetSessionVars().StmtCtx
+	res, isNull, err = jsonModifyArray(b.args, row, json.BinaryJSON.ArrayAppend, sc)
+	return res, isNull, errors.Trace(err)
+}
+
+type jsonArrayInsertFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinJSONArrayInsertSig struct {
+	baseBuiltinFunc
+}
+
+func (c *jsonArrayInsertFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	bf, err := newJSONArrayModifyFunc(ctx, &c.baseFunctionClass, args)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinJSONArrayInsertSig{bf}
+	return sig, nil
+}
+
+func (b *builtinJSONArrayInsertSig) evalJSON(row types.Row) (res json.BinaryJSON, isNull bool, err error) {
+	sc := b.getCtx().GetSessionVars().StmtCtx
+	res, isNull, err = jsonModifyArray(b.args, row, json.BinaryJSON.ArrayInsert, sc)
+	return res, isNull, errors.Trace(err)
+}
+
+// newJSONArrayModifyFunc builds the base of JSON_ARRAY_APPEND and JSON_ARRAY_INSERT, whose
+// arguments are a JSON document followed by the pairs of path and value.
+func newJSONArrayModifyFunc(ctx context.Context, c *baseFunctionClass, args []Expression) (bf baseBuiltinFunc, err error) {
+	if err = c.verifyArgs(args); err != nil {
+		return bf, errors.Trace(err)
+	}
+	if len(args)&1 != 1 {
+		return bf, ErrIncorrectParameterCount.GenByArgs(c.funcName)
+	}
+	argTps := make([]types.EvalType, 0, len(args))
+	argTps = append(argTps, types.ETJson)
+	for i := 1; i < len(args)-1; i += 2 {
+		argTps = append(argTps, types.ETString, types.ETJson)
+	}
+	bf = newBaseBuiltinFuncWithTp(ctx, args, types.ETJson, argTps...)
+	for i := 2; i < len(args); i += 2 {
+		args[i].GetType().Flag &= ^mysql.ParseToJSONFlag
+	}
+	return bf, nil
+}
+
+// jsonModifyArray applies modifyFn to the JSON document in args[0] with every pair of path and value in args[1:].
+func jsonModifyArray(args []Expression, row types.Row, modifyFn func(json.BinaryJSON, json.PathExpression, json.BinaryJSON) (json.BinaryJSON, error),
+	sc *stmtctx.StatementContext) (res json.BinaryJSON, isNull bool, err error) {
+	res, isNull, err = args[0].EvalJSON(row, sc)
+	if isNull || err != nil {
+		return res, isNull, errors.Trace(err)
+	}
+	for i := 1; i < len(args); i += 2 {
+		var pathExprs []json.PathExpression
+		pathExprs, isNull, err = evalJSONPathExprs(args[i:i+1], row, sc)
+		if isNull || err != nil {
+			return res, isNull, errors.Trace(err)
+		}
+		var value json.BinaryJSON
+		value, isNull, err = args[i+1].EvalJSON(row, sc)
+		if err != nil {
+			return res, true, errors.Trace(err)
+		}
+		if isNull {
+			value = json.CreateBinary(nil)
+		}
+		res, err = modifyFn(res, pathExprs[0], value)
+		if err != nil {
+			return res, true, errors.Trace(err)
+		}
+	}
+	return res, false, nil
+}
+
+type jsonMergePatchFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinJSONMergePatchSig struct {
+	baseBuiltinFunc
+}
+
+func (c *jsonMergePatchFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	argTps := make([]types.EvalType, 0, len(args))
+	for range args {
+		argTps = append(argTps, types.ETJson)
+	}
+	bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETJson, argTps...)
+	sig := &builtinJSONMergePatchSig{bf}
+	return sig, nil
+}
+
+func (b *builtinJSONMergePatchSig) evalJSON(row types.Row) (res json.BinaryJSON, isNull bool, err error) {
+	sc := b.getCtx().GetSessionVars().StmtCtx
+	// The NULL documents are kept as nil, a later document which isn't an object may override them.
+	values := make([]*json.BinaryJSON, 0, len(b.args))
+	for _, arg := range b.args {
+		var value json.BinaryJSON
+		value, isNull, err = arg.EvalJSON(row, sc)
+		if err != nil {
+			return res, true, errors.Trace(err)
+		}
+		if isNull {
+			values = append(values, nil)
+		} else {
+			values = append(values, &value)
+		}
+	}
+	merged := json.MergePatchBinary(values)
+	if merged == nil {
+		return res, true, nil
+	}
+	return *merged, false, nil
+}
+
+type jsonQuoteFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinJSONQuoteSig struct {
+	baseBuiltinFunc
+}
+
+func (c *jsonQuoteFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETString, types.ETString)
+	// Every character may be escaped as \uXXXX.
+	bf.tp.Flen = 6*args[0].GetType().Flen + 2
+	if bf.tp.Flen > mysql.MaxBlobWidth || args[0].GetType().Flen < 0 {
+		bf.tp.Flen = mysql.MaxBlobWidth
+	}
+	sig := &builtinJSONQuoteSig{bf}
+	return sig, nil
+}
+
+func (b *builtinJSONQuoteSig) evalString(row types.Row) (res string, isNull bool, err error) {
+	str, isNull, err := b.args[0].EvalString(row, b.getCtx().GetSessionVars().StmtCtx)
+	if isNull || err != nil {
+		return "", isNull, errors.Trace(err)
+	}
+	return json.QuoteString(str), false, nil
+}
+
+type jsonPrettyFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinJSONPrettySig struct {
+	baseBuiltinFunc
+}
+
+func (c *jsonPrettyFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETString, types.ETJson)
+	bf.tp.Flen = mysql.MaxBlobWidth
+	sig := &builtinJSONPrettySig{bf}
+	return sig, nil
+}
+
+func (b *builtinJSONPrettySig) evalString(row types.Row) (res string, isNull bool, err error) {
+	obj, isNull, err := b.args[0].EvalJSON(row, b.getCtx().GetSessionVars().StmtCtx)
+	if isNull || err != nil {
+		return "", isNull, errors.Trace(err)
+	}
+	buf, err := obj.MarshalPrettyJSON()
+	if err != nil {
+		return "", true, errors.Trace(err)
+	}
+	return string(buf), false, nil
+}
+
+type jsonStorageSizeFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinJSONStorageSizeSig struct {
+	baseBuiltinFunc
+}
+
+func (c *jsonStorageSizeFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETInt, types.ETJson)
+	sig := &builtinJSONStorageSizeSig{bf}
+	return sig, nil
+}
+
+func (b *builtinJSONStorageSizeSig) evalInt(row types.Row) (res int64, isNull bool, err error) {
+	obj, isNull, err := b.args[0].EvalJSON(row, b.getCtx().GetSessionVars().StmtCtx)
+	if isNull || err != nil {
+		return res, isNull, errors.Trace(err)
+	}
+	// The binary representation is the type code followed by the value.
+	return int64(len(obj.Value)) + 1, false, nil
+}
@@ -307,3 +307,242 @@ func (s *testEvaluatorSuite) TestJSONORemove(c *C) {
 		}
 	}
 }
+
+func (s *testEvaluatorSuite) TestJSONContainsPath(c *C) {
+	defer testleak.AfterTest(c)()
+	fc := funcs[ast.JSONContainsPath]
+	jstr := `{"a": [{"aa": [{"aaa": 1}]}], "aaa": 2}`
+	tbl := []struct {
+		Input    []interface{}
+		Expected interface{}
+		Success  bool
+	}{
+		{[]interface{}{nil, "one", "$.aaa"}, nil, true},
+		{[]interface{}{jstr, nil, "$.aaa"}, nil, true},
+		{[]interface{}{jstr, "one", nil}, nil, true},
+		{[]interface{}{jstr, "one", "$.aaa"}, int64(1), true},
+		{[]interface{}{jstr, "One", "$.aaa", "$.b"}, int64(1), true},
+		{[]interface{}{jstr, "all", "$.aaa", "$.b"}, int64(0), true},
+		{[]interface{}{jstr, "ALL", "$.aaa", "$.a[0].aa[*]"}, int64(1), true},
+		{[]interface{}{jstr, "one", "$.b", "$**.bbb"}, int64(0), true},
+		{[]interface{}{jstr, "any", "$.aaa"}, nil, false},
+		{[]interface{}{jstr, "one", "$InvalidPath"}, nil, false},
+	}
+	for _, t := range tbl {
+		args := types.MakeDatums(t.Input...)
+		f, err := fc.getFunction(s.ctx, s.datumsToConstants(args))
+		c.Assert(err, IsNil)
+		d, err := evalBuiltinFunc(f, nil)
+		if t.Success {
+			c.Assert(err, IsNil)
+			c.Assert(d, testutil.DatumEquals, types.NewDatum(t.Expected))
+		} else {
+			c.Assert(err, NotNil)
+		}
+	}
+}
+
+func (s *testEvaluatorSuite) TestJSONKeysLengthDepth(c *C) {
+	defer testleak.AfterTest(c)()
+	jstr := `{"a": [1, {"b": 2, "c": [3]}], "d": {}}`
+	tbl := []struct {
+		fc       functionClass
+		Input    []interface{}
+		Expected interface{}
+		Success  bool
+	}{
+		{funcs[ast.JSONKeys], []interface{}{nil}, nil, true},
+		{funcs[ast.JSONKeys], []interface{}{jstr}, `["a", "d"]`, true},
+		{funcs[ast.JSONKeys], []interface{}{jstr, "$.a[1]"}, `["b", "c"]`, true},
+		{funcs[ast.JSONKeys], []interface{}{jstr, "$.d"}, `[]`, true},
+		{funcs[ast.JSONKeys], []interface{}{jstr, "$.a"}, nil, true},
+		{funcs[ast.JSONKeys], []interface{}{jstr, "$.x"}, nil, true},
+		{funcs[ast.JSONKeys], []interface{}{jstr, "$.a[*]"}, nil, false},
+
+		{funcs[ast.JSONLength], []interface{}{nil}, nil, true},
+		{funcs[ast.JSONLength], []interface{}{jstr}, int64(2), true},
+		{funcs[ast.JSONLength], []interface{}{`3`}, int64(1), true},
+		{funcs[ast.JSONLength], []interface{}{jstr, "$.a[1]"}, int64(2), true},
+		{funcs[ast.JSONLength], []interface{}{jstr, "$.a[1].b"}, int64(1), true},
+		{funcs[ast.JSONLength], []interface{}{jstr, "$.x"}, nil, true},
+		{funcs[ast.JSONLength], []interface{}{jstr, "$**.b"}, nil, false},
+
+		{funcs[ast.JSONDepth], []interface{}{nil}, nil, true},
+		{funcs[ast.JSONDepth], []interface{}{`"a"`}, int64(1), true},
+		{funcs[ast.JSONDepth], []interface{}{`[]`}, int64(1), true},
+		{funcs[ast.JSONDepth], []interface{}{`[10, {"a": 20}]`}, int64(3), true},
+		{funcs[ast.JSONDepth], []interface{}{jstr}, int64(5), true},
+	}
+	for _, t := range tbl {
+		args := types.MakeDatums(t.Input...)
+		f, err := t.fc.getFunction(s.ctx, s.datumsToConstants(args))
+		c.Assert(err, IsNil)
+		d, err := evalBuiltinFunc(f, nil)
+		if !t.Success {
+			c.Assert(err, NotNil)
+			continue
+		}
+		c.Assert(err, IsNil)
+		switch x := t.Expected.(type) {
+		case string:
+			j1, err := json.ParseBinaryFromString(x)
+			c.Assert(err, IsNil)
+			c.Assert(json.CompareBinary(j1, d.GetMysqlJSON()), Equals, 0)
+		default:
+			c.Assert(d, testutil.DatumEquals, types.NewDatum(t.Expected))
+		}
+	}
+}
+
+func (s *testEvaluatorSuite) TestJSONSearch(c *C) {
+	defer testleak.AfterTest(c)()
+	fc := funcs[ast.JSONSearch]
+	jstr := `["abc", [{"k": "10"}, "def"], {"x": "abc"}, {"y": "bcd"}]`
+	tbl := []struct {
+		Input    []interface{}
+		Expected interface{}
+		Success  bool
+	}{
+		{[]interface{}{nil, "one", "abc"}, nil, true},
+		{[]interface{}{jstr, "one", nil}, nil, true},
+		{[]interface{}{jstr, "one", "abc"}, `"$[0]"`, true},
+		{[]interface{}{jstr, "all", "abc"}, `["$[0]", "$[2].x"]`, true},
+		{[]interface{}{jstr, "all", "ghi"}, nil, true},
+		{[]interface{}{jstr, "all", "10"}, `"$[1][0].k"`, true},
+		{[]interface{}{jstr, "all", "%b%"}, `["$[0]", "$[2].x", "$[3].y"]`, true},
+		{[]interface{}{jstr, "all", "%b%", nil, "$[2]"}, `"$[2].x"`, true},
+		{[]interface{}{jstr, "all", "%b%", "", "$[3]", "$[*].y"}, `"$[3].y"`, true},
+		{[]interface{}{jstr, "all", "%b%", nil, "$[1]"}, nil, true},
+		{[]interface{}{jstr, "all", "%b%", nil, nil}, nil, true},
+		{[]interface{}{`["a%c", "abc"]`, "all", "a|%c", "|"}, `"$[0]"`, true},
+		{[]interface{}{jstr, "any", "abc"}, nil, false},
+		{[]interface{}{jstr, "all", "abc", "||"}, nil, false},
+		{[]interface{}{jstr, "all", "abc", nil, "$InvalidPath"}, nil, false},
+	}
+	for _, t := range tbl {
+		args := types.MakeDatums(t.Input...)
+		f, err := fc.getFunction(s.ctx, s.datumsToConstants(args))
+		c.Assert(err, IsNil)
+		d, err := evalBuiltinFunc(f, nil)
+		if !t.Success {
+			c.Assert(err, NotNil)
+			continue
+		}
+		c.Assert(err, IsNil)
+		if t.Expected == nil {
+			c.Assert(d.IsNull(), IsTrue)
+			continue
+		}
+		j1, err := json.ParseBinaryFromString(t.Expected.(string))
+		c.Assert(err, IsNil)
+		j2 := d.GetMysqlJSON()
+		c.Assert(json.CompareBinary(j1, j2), Equals, 0, Commentf("got %s expect %s", j2, j1))
+	}
+}
+
+func (s *testEvaluatorSuite) TestJSONArrayAppendInsert(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		fc           functionClass
+		Input        []interface{}
+		Expected     interface{}
+		BuildSuccess bool
+		Success      bool
+	}{
+		{funcs[ast.JSONArrayAppend], []interface{}{nil, `$`, 1}, nil, true, true},
+		{funcs[ast.JSONArrayAppend], []interface{}{`[1, [2]]`, nil, 1}, nil, true, true},
+		{funcs[ast.JSONArrayAppend], []interface{}{`[1, [2]]`, `$[1]`, 3, `$`, "x"}, `[1, [2, 3], "x"]`, true, true},
+		{funcs[ast.JSONArrayAppend], []interface{}{`{"a": 1}`, `$.a`, nil}, `{"a": [1, null]}`, true, true},
+		{funcs[ast.JSONArrayAppend], []interface{}{`{"a": 1}`, `$.a`, `{"b": 2}`}, `{"a": [1, "{\"b\": 2}"]}`, true, true},
+		{funcs[ast.JSONArrayAppend], []interface{}{`[1]`, `$[*]`, 1}, nil, true, false},
+		{funcs[ast.JSONArrayAppend], []interface{}{`[1]`, `$`}, nil, false, false},
+
+		{funcs[ast.JSONArrayInsert], []interface{}{`[1, [2]]`, `$[1]`, 3, `$[2][5]`, 4}, `[1, 3, [2, 4]]`, true, true},
+		{funcs[ast.JSONArrayInsert], []interface{}{`{"a": [1]}`, `$.a[0]`, "x", `$.b[0]`, 2}, `{"a": ["x", 1]}`, true, true},
+		{funcs[ast.JSONArrayInsert], []interface{}{`[1]`, `$`, 1}, nil, true, false},
+		{funcs[ast.JSONArrayInsert], []interface{}{`{"a": [1]}`, `$.a`, 1}, nil, true, false},
+	}
+	for _, t := range tbl {
+		args := types.MakeDatums(t.Input...)
+		f, err := t.fc.getFunction(s.ctx, s.datumsToConstants(args))
+		if !t.BuildSuccess {
+			c.Assert(err, NotNil)
+			continue
+		}
+		c.Assert(err, IsNil)
+		d, err := evalBuiltinFunc(f, nil)
+		if !t.Success {
+			c.Assert(err, NotNil)
+			continue
+		}
+		c.Assert(err, IsNil)
+		if t.Expected == nil {
+			c.Assert(d.IsNull(), IsTrue)
+			continue
+		}
+		j1, err := json.ParseBinaryFromString(t.Expected.(string))
+		c.Assert(err, IsNil)
+		j2 := d.GetMysqlJSON()
+		c.Assert(json.CompareBinary(j1, j2), Equals, 0, Commentf("got %s expect %s", j2, j1))
+	}
+}
+
+func (s *testEvaluatorSuite) TestJSONMergePatchPreserve(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		fc       functionClass
+		Input    []interface{}
+		Expected interface{}
+	}{
+		{funcs[ast.JSONMergePatch], []interface{}{`{"a": 1, "b": 2}`, `{"a": 3, "c": 4}`}, `{"a": 3, "b": 2, "c": 4}`},
+		{funcs[ast.JSONMergePatch], []interface{}{`{"a": 1, "b": 2}`, `{"b": null}`, `{"c": [1]}`}, `{"a": 1, "c": [1]}`},
+		{funcs[ast.JSONMergePatch], []interface{}{`[1, 2]`, `[3]`}, `[3]`},
+		{funcs[ast.JSONMergePatch], []interface{}{nil, `{"a": 1}`}, nil},
+		{funcs[ast.JSONMergePatch], []interface{}{`{"a": 1}`, nil, `true`}, `true`},
+		{funcs[ast.JSONMergePreserve], []interface{}{`{"a": 1, "b": 2}`, `{"a": 3, "c": 4}`}, `{"a": [1, 3], "b": 2, "c": 4}`},
+		{funcs[ast.JSONMergePreserve], []interface{}{`[1, 2]`, `[3]`}, `[1, 2, 3]`},
+		{funcs[ast.JSONMergePreserve], []interface{}{`[1, 2]`, nil}, nil},
+	}
+	for _, t := range tbl {
+		args := types.MakeDatums(t.Input...)
+		f, err := t.fc.getFunction(s.ctx, s.datumsToConstants(args))
+		c.Assert(err, IsNil)
+		d, err := evalBuiltinFunc(f, nil)
+		c.Assert(err, IsNil)
+		if t.Expected == nil {
+			c.Assert(d.IsNull(), IsTrue)
+			continue
+		}
+		j1, err := json.ParseBinaryFromString(t.Expected.(string))
+		c.Assert(err, IsNil)
+		j2 := d.GetMysqlJSON()
+		c.Assert(json.CompareBinary(j1, j2), Equals, 0, Commentf("got %s expect %s", j2, j1))
+	}
+}
+
+func (s *testEvaluatorSuite) TestJSONQuotePrettyStorageSize(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		fc       functionClass
+		Input    interface{}
+		Expected interface{}
+	}{
+		{funcs[ast.JSONQuote], nil, nil},
+		{funcs[ast.JSONQuote], `null`, `"null"`},
+		{funcs[ast.JSONQuote], `a"b`, `"a\"b"`},
+		{funcs[ast.JSONQuote], "[1]\n", `"[1]\n"`},
+		{funcs[ast.JSONPretty], nil, nil},
+		{funcs[ast.JSONPretty], `{"a": [1, 2], "b": {}}`, "{\n  \"a\": [\n    1,\n    2\n  ],\n  \"b\": {}\n}"},
+		{funcs[ast.JSONPretty], `"abc"`, `"abc"`},
+		{funcs[ast.JSONStorageSize], nil, nil},
+		{funcs[ast.JSONStorageSize], `true`, int64(2)},
+		{funcs[ast.JSONStorageSize], `[1]`, int64(22)},
+	}
+	for _, t := range tbl {
+		f, err := t.fc.getFunction(s.ctx, s.datumsToConstants(types.MakeDatums(t.Input)))
+		c.Assert(err, IsNil)
+		d, err := evalBuiltinFunc(f, nil)
+		c.Assert(err, IsNil)
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.Expected))
+	}
+}
@@ -14,8 +14,6 @@
 package expression
 
 import (
-	"regexp"
-
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/types"
@@ -91,15 +89,16 @@ func (c *regexpFunctionClass) getFunction(ctx context.Context, args []Expression
 	bf.tp.Flen = 1
 	var sig builtinFunc
 	if types.IsBinaryStr(args[0].GetType()) {
-		sig = &builtinRegexpBinarySig{bf}
+		sig = &builtinRegexpBinarySig{baseBuiltinFunc: bf, regexpMemorizedSig: regexpMemorizedSig{binary: true}}
 	} else {
-		sig = &builtinRegexpSig{bf}
+		sig = &builtinRegexpSig{baseBuiltinFunc: bf}
 	}
 	return sig, nil
 }
 
 type builtinRegexpBinarySig struct {
 	baseBuiltinFunc
+	regexpMemorizedSig
 }
 
 func (b *builtinRegexpBinarySig) evalInt(row types.Row) (int64, bool, error) {
@@ -110,21 +109,16 @@ func (b *builtinRegexpBinarySig) evalInt(row types.Row) (int64, bool, error) {
 		return 0, true, errors.Trace(err)
 	}
 
-	pat, isNull, err := b.args[1].EvalString(row, sc)
+	re, isNull, err := b.getRegexp(row, sc, b.args[1], nil)
 	if isNull || err != nil {
 		return 0, true, errors.Trace(err)
 	}
-
-	// TODO: We don't need to compile pattern if it has been compiled or it is static.
-	re, err := regexp.Compile(pat)
-	if err != nil {
-		return 0, true, errors.Trace(err)
-	}
 	return boolToInt64(re.MatchString(expr)), false, nil
 }
 
 type builtinRegexpSig struct {
 	baseBuiltinFunc
+	regexpMemorizedSig
 }
 
 // evalInt evals `expr REGEXP pat`, or `expr RLIKE pat`.
@@ -137,15 +131,9 @@ func (b *builtinRegexpSig) evalInt(row types.Row) (int64, bool, error) {
 		return 0, true, errors.Trace(err)
 	}
 
-	pat, isNull, err := b.args[1].EvalString(row, sc)
+	re, isNull, err := b.getRegexp(row, sc, b.args[1], nil)
 	if isNull || err != nil {
 		return 0, true, errors.Trace(err)
 	}
-
-	// TODO: We don't need to compile pattern if it has been compiled or it is static.
-	re, err := regexp.Compile("(?i)" + pat)
-	if err != nil {
-		return 0, true, errors.Trace(err)
-	}
 	return boolToInt64(re.MatchString(expr)), false, nil
 }
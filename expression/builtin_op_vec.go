@@ -0,0 +1,113 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/chunk"
+)
+
+// vecEvalLogicArgs evaluates the two arguments of a short-circuit logic function. Like the row based
+// evaluation, the second argument is only evaluated on the rows where needArg1 returns true for the
+// value of the first argument.
+func vecEvalLogicArgs(b *baseBuiltinFunc, input *chunk.Chunk, needArg1 func(val int64, isNull bool) bool) (arg0, arg1 *chunk.Column, err error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	numRows := input.NumRows()
+	arg0, arg1 = newVecBuffer(types.ETInt, numRows), newVecBuffer(types.ETInt, numRows)
+	if err = vecEvalExprInt(sc, b.args[0], input, arg0); err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	sel := make([]bool, numRows)
+	for i, val := range arg0.Int64s() {
+		sel[i] = needArg1(val, arg0.IsNull(i))
+	}
+	if err = vecEvalExprIntSelected(sc, b.args[1], input, sel, arg1); err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	return arg0, arg1, nil
+}
+
+func (b *builtinLogicAndSig) vectorized() bool {
+	return true
+}
+
+func (b *builtinLogicAndSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column) error {
+	arg0, arg1, err := vecEvalLogicArgs(&b.baseBuiltinFunc, input, func(val int64, isNull bool) bool {
+		return isNull || val != 0
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	result.ResizeInt64(input.NumRows())
+	vals0, vals1, res := arg0.Int64s(), arg1.Int64s(), result.Int64s()
+	for i := range res {
+		isNull0, isNull1 := arg0.IsNull(i), arg1.IsNull(i)
+		switch {
+		case !isNull0 && vals0[i] == 0, !isNull1 && vals1[i] == 0:
+			res[i] = 0
+		case isNull0 || isNull1:
+			result.SetNull(i, true)
+		default:
+			res[i] = 1
+		}
+	}
+	return nil
+}
+
+func (b *builtinLogicOrSig) vectorized() bool {
+	return true
+}
+
+func (b *builtinLogicOrSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column) error {
+	arg0, arg1, err := vecEvalLogicArgs(&b.baseBuiltinFunc, input, func(val int64, isNull bool) bool {
+		return isNull || val == 0
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	result.ResizeInt64(input.NumRows())
+	vals0, vals1, res := arg0.Int64s(), arg1.Int64s(), result.Int64s()
+	for i := range res {
+		isNull0, isNull1 := arg0.IsNull(i), arg1.IsNull(i)
+		switch {
+		case !isNull0 && vals0[i] != 0, !isNull1 && vals1[i] != 0:
+			res[i] = 1
+		case isNull0 || isNull1:
+			result.SetNull(i, true)
+		default:
+			res[i] = 0
+		}
+	}
+	return nil
+}
+
+func (b *builtinUnaryNotSig) vectorized() bool {
+	return true
+}
+
+func (b *builtinUnaryNotSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column) error {
+	if err := vecEvalExprInt(b.ctx.GetSessionVars().StmtCtx, b.args[0], input, result); err != nil {
+		return errors.Trace(err)
+	}
+	vals := result.Int64s()
+	for i, val := range vals {
+		if val != 0 {
+			vals[i] = 0
+		} else {
+			vals[i] = 1
+		}
+	}
+	return nil
+}
@@ -73,6 +73,11 @@ func (c *inFunctionClass) getFunction(ctx context.Context, args []Expression) (s
 	for i := range args {
 		argTps[i] = args[0].GetType().EvalType()
 	}
+	if argTps[0] == types.ETString {
+		if _, err = checkIllegalMixCollation(c.funcName, args); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
 	bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETInt, argTps...)
 	bf.tp.Flen = 1
 	switch args[0].GetType().EvalType() {
@@ -166,7 +171,7 @@ func (b *builtinInStringSig) evalInt(row types.Row) (int64, bool, error) {
 			hasNull = true
 			continue
 		}
-		if arg0 == evaledArg {
+		if compareStringWithCollation(arg0, evaledArg, b.collation) == 0 {
 			return 1, false, nil
 		}
 	}
@@ -0,0 +1,432 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	"regexp"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/stmtctx"
+	"github.com/pingcap/tidb/types"
+)
+
+var (
+	_ functionClass = &regexpLikeFunctionClass{}
+	_ functionClass = &regexpInStrFunctionClass{}
+	_ functionClass = &regexpSubstrFunctionClass{}
+	_ functionClass = &regexpReplaceFunctionClass{}
+)
+
+var (
+	_ builtinFunc = &builtinRegexpLikeSig{}
+	_ builtinFunc = &builtinRegexpInStrSig{}
+	_ builtinFunc = &builtinRegexpSubstrSig{}
+	_ builtinFunc = &builtinRegexpReplaceSig{}
+)
+
+// regexpMemorizedSig holds what the regexp signatures share. Like the REGEXP operator, the patterns
+// of the binary strings are case sensitive and their positions count bytes, the patterns of the
+// other strings are case insensitive and their positions count characters.
+type regexpMemorizedSig struct {
+	binary bool
+
+	// The pattern is compiled only once if both the pattern and the match type are constant.
+	once         sync.Once
+	memorizedRe  *regexp.Regexp
+	memorizedErr error
+}
+
+// isMemorizable checks whether the compiled pattern can be reused by all the rows.
+func isMemorizable(args ...Expression) bool {
+	for _, arg := range args {
+		if arg == nil {
+			continue
+		}
+		if c, ok := arg.(*Constant); !ok || c.DeferredExpr != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// compile builds the regexp with the flags of matchType, see
+// https://dev.mysql.com/doc/refman/8.0/en/regexp.html#function_regexp-like for the flags.
+func (s *regexpMemorizedSig) compile(pat, matchType string) (*regexp.Regexp, error) {
+	caseInsensitive, multiLine, dotAll := !s.binary, false, false
+	for _, c := range matchType {
+		switch c {
+		case 'c':
+			caseInsensitive = false
+		case 'i':
+			caseInsensitive = true
+		case 'm':
+			multiLine = true
+		case 'n':
+			dotAll = true
+		case 'u':
+			// Only '\n' is a line terminator in Go regexps.
+		default:
+			return nil, errRegexpIllegalArgument
+		}
+	}
+	flags := ""
+	if caseInsensitive {
+		flags += "i"
+	}
+	if multiLine {
+		flags += "m"
+	}
+	if dotAll {
+		flags += "s"
+	}
+	if flags != "" {
+		pat = "(?" + flags + ")" + pat
+	}
+	re, err := regexp.Compile(pat)
+	if err != nil {
+		return nil, errRegexp.GenByArgs(err.Error())
+	}
+	return re, nil
+}
+
+// getRegexp evaluates the pattern and the optional match type of a row, and compiles them.
+func (s *regexpMemorizedSig) getRegexp(row types.Row, sc *stmtctx.StatementContext, patArg, matchTypeArg Expression) (*regexp.Regexp, bool, error) {
+	pat, isNull, err := patArg.EvalString(row, sc)
+	if isNull || err != nil {
+		return nil, isNull, errors.Trace(err)
+	}
+	var matchType string
+	if matchTypeArg != nil {
+		matchType, isNull, err = matchTypeArg.EvalString(row, sc)
+		if isNull || err != nil {
+			return nil, isNull, errors.Trace(err)
+		}
+	}
+	if !isMemorizable(patArg, matchTypeArg) {
+		re, err := s.compile(pat, matchType)
+		return re, false, errors.Trace(err)
+	}
+	s.once.Do(func() {
+		s.memorizedRe, s.memorizedErr = s.compile(pat, matchType)
+	})
+	return s.memorizedRe, false, errors.Trace(s.memorizedErr)
+}
+
+// toByteOffset converts the 1-based position pos of str to a byte offset.
+func (s *regexpMemorizedSig) toByteOffset(str string, pos int64) (int, error) {
+	if pos < 1 {
+		return 0, errRegexpIndexOutOfBounds
+	}
+	if s.binary {
+		if pos > int64(len(str))+1 {
+			return 0, errRegexpIndexOutOfBounds
+		}
+		return int(pos - 1), nil
+	}
+	offset := 0
+	for i := int64(1); i < pos; i++ {
+		if offset >= len(str) {
+			return 0, errRegexpIndexOutOfBounds
+		}
+		_, size := utf8.DecodeRuneInString(str[offset:])
+		offset += size
+	}
+	return offset, nil
+}
+
+// toPosition converts the byte offset of str to a 1-based position.
+func (s *regexpMemorizedSig) toPosition(str string, offset int) int64 {
+	if s.binary {
+		return int64(offset + 1)
+	}
+	return int64(utf8.RuneCountInString(str[:offset]) + 1)
+}
+
+// evalOptionalInt evaluates the idx-th argument if it's given, or returns the default value.
+func evalOptionalInt(args []Expression, idx int, defaultVal int64, row types.Row, sc *stmtctx.StatementContext) (int64, bool, error) {
+	if idx >= len(args) {
+		return defaultVal, false, nil
+	}
+	val, isNull, err := args[idx].EvalInt(row, sc)
+	return val, isNull, errors.Trace(err)
+}
+
+// optionalArg returns the idx-th argument, or nil if it isn't given.
+func optionalArg(args []Expression, idx int) Expression {
+	if idx >= len(args) {
+		return nil
+	}
+	return args[idx]
+}
+
+// regexpArgTps returns the types of the arguments, the first n arguments are strings and the rest
+// are integers except the match type which is always the last one.
+func regexpArgTps(args []Expression, n, matchTypeIdx int) []types.EvalType {
+	argTps := make([]types.EvalType, 0, len(args))
+	for i := range args {
+		if i < n || i == matchTypeIdx {
+			argTps = append(argTps, types.ETString)
+		} else {
+			argTps = append(argTps, types.ETInt)
+		}
+	}
+	return argTps
+}
+
+type regexpLikeFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *regexpLikeFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETInt, regexpArgTps(args, 2, 2)...)
+	bf.tp.Flen = 1
+	sig := &builtinRegexpLikeSig{baseBuiltinFunc: bf}
+	sig.binary = types.IsBinaryStr(args[0].GetType())
+	return sig, nil
+}
+
+type builtinRegexpLikeSig struct {
+	baseBuiltinFunc
+	regexpMemorizedSig
+}
+
+// evalInt evals `REGEXP_LIKE(expr, pat[, match_type])`.
+// See https://dev.mysql.com/doc/refman/8.0/en/regexp.html#function_regexp-like
+func (b *builtinRegexpLikeSig) evalInt(row types.Row) (int64, bool, error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	expr, isNull, err := b.args[0].EvalString(row, sc)
+	if isNull || err != nil {
+		return 0, isNull, errors.Trace(err)
+	}
+	re, isNull, err := b.getRegexp(row, sc, b.args[1], optionalArg(b.args, 2))
+	if isNull || err != nil {
+		return 0, isNull, errors.Trace(err)
+	}
+	return boolToInt64(re.MatchString(expr)), false, nil
+}
+
+type regexpInStrFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *regexpInStrFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETInt, regexpArgTps(args, 2, 5)...)
+	bf.tp.Flen = mysql.MaxIntWidth
+	sig := &builtinRegexpInStrSig{baseBuiltinFunc: bf}
+	sig.binary = types.IsBinaryStr(args[0].GetType())
+	return sig, nil
+}
+
+type builtinRegexpInStrSig struct {
+	baseBuiltinFunc
+	regexpMemorizedSig
+}
+
+// evalInt evals `REGEXP_INSTR(expr, pat[, pos[, occurrence[, return_option[, match_type]]]])`.
+// See https://dev.mysql.com/doc/refman/8.0/en/regexp.html#function_regexp-instr
+func (b *builtinRegexpInStrSig) evalInt(row types.Row) (int64, bool, error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	expr, isNull, err := b.args[0].EvalString(row, sc)
+	if isNull || err != nil {
+		return 0, isNull, errors.Trace(err)
+	}
+	re, isNull, err := b.getRegexp(row, sc, b.args[1], optionalArg(b.args, 5))
+	if isNull || err != nil {
+		return 0, isNull, errors.Trace(err)
+	}
+	pos, isNull, err := evalOptionalInt(b.args, 2, 1, row, sc)
+	if isNull || err != nil {
+		return 0, isNull, errors.Trace(err)
+	}
+	occurrence, isNull, err := evalOptionalInt(b.args, 3, 1, row, sc)
+	if isNull || err != nil {
+		return 0, isNull, errors.Trace(err)
+	}
+	returnOption, isNull, err := evalOptionalInt(b.args, 4, 0, row, sc)
+	if isNull || err != nil {
+		return 0, isNull, errors.Trace(err)
+	}
+	if returnOption != 0 && returnOption != 1 {
+		return 0, true, errRegexpIllegalArgument
+	}
+	offset, err := b.toByteOffset(expr, pos)
+	if err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	loc := findOccurrence(re, expr, offset, occurrence)
+	if loc == nil {
+		return 0, false, nil
+	}
+	return b.toPosition(expr, loc[returnOption]), false, nil
+}
+
+// findOccurrence returns the byte indexes of the occurrence-th match and its sub matches in str
+// starting from offset, or nil if there aren't so many matches.
+func findOccurrence(re *regexp.Regexp, str string, offset int, occurrence int64) []int {
+	if occurrence < 1 {
+		occurrence = 1
+	}
+	var loc []int
+	for ; occurrence > 0; occurrence-- {
+		if offset > len(str) {
+			return nil
+		}
+		loc = re.FindStringSubmatchIndex(str[offset:])
+		if loc == nil {
+			return nil
+		}
+		for i := range loc {
+			if loc[i] >= 0 {
+				loc[i] += offset
+			}
+		}
+		// An empty match must not be found again.
+		offset = loc[1]
+		if loc[0] == loc[1] {
+			_, size := utf8.DecodeRuneInString(str[offset:])
+			offset += size
+			if size == 0 {
+				offset++
+			}
+		}
+	}
+	return loc
+}
+
+type regexpSubstrFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *regexpSubstrFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETString, regexpArgTps(args, 2, 4)...)
+	argType := args[0].GetType()
+	bf.tp.Flen = argType.Flen
+	SetBinFlagOrBinStr(argType, bf.tp)
+	sig := &builtinRegexpSubstrSig{baseBuiltinFunc: bf}
+	sig.binary = types.IsBinaryStr(argType)
+	return sig, nil
+}
+
+type builtinRegexpSubstrSig struct {
+	baseBuiltinFunc
+	regexpMemorizedSig
+}
+
+// evalString evals `REGEXP_SUBSTR(expr, pat[, pos[, occurrence[, match_type]]])`.
+// See https://dev.mysql.com/doc/refman/8.0/en/regexp.html#function_regexp-substr
+func (b *builtinRegexpSubstrSig) evalString(row types.Row) (string, bool, error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	expr, isNull, err := b.args[0].EvalString(row, sc)
+	if isNull || err != nil {
+		return "", isNull, errors.Trace(err)
+	}
+	re, isNull, err := b.getRegexp(row, sc, b.args[1], optionalArg(b.args, 4))
+	if isNull || err != nil {
+		return "", isNull, errors.Trace(err)
+	}
+	pos, isNull, err := evalOptionalInt(b.args, 2, 1, row, sc)
+	if isNull || err != nil {
+		return "", isNull, errors.Trace(err)
+	}
+	occurrence, isNull, err := evalOptionalInt(b.args, 3, 1, row, sc)
+	if isNull || err != nil {
+		return "", isNull, errors.Trace(err)
+	}
+	offset, err := b.toByteOffset(expr, pos)
+	if err != nil {
+		return "", true, errors.Trace(err)
+	}
+	loc := findOccurrence(re, expr, offset, occurrence)
+	if loc == nil {
+		return "", true, nil
+	}
+	return expr[loc[0]:loc[1]], false, nil
+}
+
+type regexpReplaceFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *regexpReplaceFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETString, regexpArgTps(args, 3, 5)...)
+	bf.tp.Flen = mysql.MaxBlobWidth
+	for _, a := range args[:3] {
+		SetBinFlagOrBinStr(a.GetType(), bf.tp)
+	}
+	sig := &builtinRegexpReplaceSig{baseBuiltinFunc: bf}
+	sig.binary = types.IsBinaryStr(args[0].GetType())
+	return sig, nil
+}
+
+type builtinRegexpReplaceSig struct {
+	baseBuiltinFunc
+	regexpMemorizedSig
+}
+
+// evalString evals `REGEXP_REPLACE(expr, pat, repl[, pos[, occurrence[, match_type]]])`.
+// The replacement refers to the captured groups by `$1` or `${1}`.
+// See https://dev.mysql.com/doc/refman/8.0/en/regexp.html#function_regexp-replace
+func (b *builtinRegexpReplaceSig) evalString(row types.Row) (string, bool, error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	expr, isNull, err := b.args[0].EvalString(row, sc)
+	if isNull || err != nil {
+		return "", isNull, errors.Trace(err)
+	}
+	re, isNull, err := b.getRegexp(row, sc, b.args[1], optionalArg(b.args, 5))
+	if isNull || err != nil {
+		return "", isNull, errors.Trace(err)
+	}
+	repl, isNull, err := b.args[2].EvalString(row, sc)
+	if isNull || err != nil {
+		return "", isNull, errors.Trace(err)
+	}
+	pos, isNull, err := evalOptionalInt(b.args, 3, 1, row, sc)
+	if isNull || err != nil {
+		return "", isNull, errors.Trace(err)
+	}
+	occurrence, isNull, err := evalOptionalInt(b.args, 4, 0, row, sc)
+	if isNull || err != nil {
+		return "", isNull, errors.Trace(err)
+	}
+	offset, err := b.toByteOffset(expr, pos)
+	if err != nil {
+		return "", true, errors.Trace(err)
+	}
+	if occurrence < 1 {
+		// Replace all the occurrences.
+		return expr[:offset] + re.ReplaceAllString(expr[offset:], repl), false, nil
+	}
+	loc := findOccurrence(re, expr, offset, occurrence)
+	if loc == nil {
+		return expr, false, nil
+	}
+	result := re.ExpandString([]byte(expr[:loc[0]]), repl, expr, loc)
+	return string(result) + expr[loc[1]:], false, nil
+}
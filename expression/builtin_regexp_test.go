@@ -0,0 +1,203 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/charset"
+	"github.com/pingcap/tidb/util/testleak"
+	"github.com/pingcap/tidb/util/testutil"
+)
+
+func (s *testEvaluatorSuite) TestRegexpLike(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		args     []interface{}
+		expected interface{}
+	}{
+		{[]interface{}{"abc", "B"}, int64(1)},
+		{[]interface{}{"abc", "B", "c"}, int64(0)},
+		{[]interface{}{"abc", "B", "ci"}, int64(1)},
+		{[]interface{}{"abc", "B", "ic"}, int64(0)},
+		{[]interface{}{"a\nb", "^b$"}, int64(0)},
+		{[]interface{}{"a\nb", "^b$", "m"}, int64(1)},
+		{[]interface{}{"a\nb", "a.b"}, int64(0)},
+		{[]interface{}{"a\nb", "a.b", "n"}, int64(1)},
+		{[]interface{}{"你好", "^.好$"}, int64(1)},
+		{[]interface{}{nil, "a"}, nil},
+		{[]interface{}{"a", nil}, nil},
+		{[]interface{}{"a", "a", nil}, nil},
+	}
+	for _, t := range tbl {
+		f, err := funcs[ast.RegexpLike].getFunction(s.ctx, s.datumsToConstants(types.MakeDatums(t.args...)))
+		c.Assert(err, IsNil)
+		d, err := evalBuiltinFunc(f, nil)
+		c.Assert(err, IsNil)
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.expected), Commentf("%v", t.args))
+	}
+
+	// The binary strings are case sensitive.
+	binTp := types.NewFieldType(mysql.TypeVarString)
+	types.SetBinChsClnFlag(binTp)
+	args := []Expression{
+		&Constant{Value: types.NewStringDatum("abc"), RetType: binTp},
+		&Constant{Value: types.NewStringDatum("B"), RetType: types.NewFieldType(mysql.TypeVarString)},
+	}
+	f, err := funcs[ast.RegexpLike].getFunction(s.ctx, args)
+	c.Assert(err, IsNil)
+	d, err := evalBuiltinFunc(f, nil)
+	c.Assert(err, IsNil)
+	c.Assert(d.GetInt64(), Equals, int64(0))
+
+	errTbl := []struct {
+		args []interface{}
+		err  *terror.Error
+	}{
+		{[]interface{}{"abc", "a", "x"}, errRegexpIllegalArgument},
+		{[]interface{}{"abc", "(a"}, errRegexp},
+	}
+	for _, t := range errTbl {
+		f, err = funcs[ast.RegexpLike].getFunction(s.ctx, s.datumsToConstants(types.MakeDatums(t.args...)))
+		c.Assert(err, IsNil)
+		_, err = evalBuiltinFunc(f, nil)
+		c.Assert(terror.ErrorEqual(err, t.err), IsTrue, Commentf("%v", t.args))
+	}
+}
+
+func (s *testEvaluatorSuite) TestRegexpInStr(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		args     []interface{}
+		expected interface{}
+	}{
+		{[]interface{}{"dog cat dog", "dog"}, int64(1)},
+		{[]interface{}{"dog cat dog", "dog", 2}, int64(9)},
+		{[]interface{}{"dog cat dog", "dog", 1, 2}, int64(9)},
+		{[]interface{}{"dog cat dog", "dog", 1, 3}, int64(0)},
+		{[]interface{}{"dog cat dog", "dog", 1, 1, 1}, int64(4)},
+		{[]interface{}{"dog cat dog", "DOG", 1, 1, 0, "c"}, int64(0)},
+		{[]interface{}{"aa aaa aaaa", "a{4}"}, int64(8)},
+		{[]interface{}{"你好世界你好", "你好", 2}, int64(5)},
+		{[]interface{}{"你好世界你好", "你好", 2, 1, 1}, int64(7)},
+		{[]interface{}{"abc", "x*", 1, 3}, int64(3)},
+		{[]interface{}{"abc", "", 4}, int64(4)},
+		{[]interface{}{"abc", "b", nil}, nil},
+		{[]interface{}{nil, "b"}, nil},
+	}
+	for _, t := range tbl {
+		f, err := funcs[ast.RegexpInStr].getFunction(s.ctx, s.datumsToConstants(types.MakeDatums(t.args...)))
+		c.Assert(err, IsNil)
+		d, err := evalBuiltinFunc(f, nil)
+		c.Assert(err, IsNil)
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.expected), Commentf("%v", t.args))
+	}
+
+	errTbl := []struct {
+		args []interface{}
+		err  *terror.Error
+	}{
+		{[]interface{}{"abc", "b", 0}, errRegexpIndexOutOfBounds},
+		{[]interface{}{"abc", "b", 5}, errRegexpIndexOutOfBounds},
+		{[]interface{}{"abc", "b", 1, 1, 2}, errRegexpIllegalArgument},
+	}
+	for _, t := range errTbl {
+		f, err := funcs[ast.RegexpInStr].getFunction(s.ctx, s.datumsToConstants(types.MakeDatums(t.args...)))
+		c.Assert(err, IsNil)
+		_, err = evalBuiltinFunc(f, nil)
+		c.Assert(terror.ErrorEqual(err, t.err), IsTrue, Commentf("%v", t.args))
+	}
+}
+
+func (s *testEvaluatorSuite) TestRegexpSubstr(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		args     []interface{}
+		expected interface{}
+	}{
+		{[]interface{}{"abc def ghi", "[a-z]+"}, "abc"},
+		{[]interface{}{"abc def ghi", "[a-z]+", 1, 3}, "ghi"},
+		{[]interface{}{"abc def ghi", "[a-z]+", 6}, "ef"},
+		{[]interface{}{"abc def ghi", "[a-z]+", 1, 4}, nil},
+		{[]interface{}{"abc DEF", "[A-Z]+", 1, 1, "c"}, "DEF"},
+		{[]interface{}{"你好 世界", "[^ ]+", 1, 2}, "世界"},
+		{[]interface{}{"abc", nil}, nil},
+	}
+	for _, t := range tbl {
+		f, err := funcs[ast.RegexpSubstr].getFunction(s.ctx, s.datumsToConstants(types.MakeDatums(t.args...)))
+		c.Assert(err, IsNil)
+		d, err := evalBuiltinFunc(f, nil)
+		c.Assert(err, IsNil)
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.expected), Commentf("%v", t.args))
+	}
+}
+
+func (s *testEvaluatorSuite) TestRegexpReplace(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		args     []interface{}
+		expected interface{}
+	}{
+		{[]interface{}{"a b c", "b", "X"}, "a X c"},
+		{[]interface{}{"abc def ghi", "[a-z]+", "X"}, "X X X"},
+		{[]interface{}{"abc def ghi", "[a-z]+", "X", 1, 2}, "abc X ghi"},
+		{[]interface{}{"abc def ghi", "[a-z]+", "X", 5}, "abc X X"},
+		{[]interface{}{"abc def ghi", "[a-z]+", "X", 5, 2}, "abc def X"},
+		{[]interface{}{"abc def ghi", "[a-z]+", "X", 1, 4}, "abc def ghi"},
+		{[]interface{}{"abc DEF", "[a-z]+", "X", 1, 0, "c"}, "X DEF"},
+		{[]interface{}{"2017-12-01", "(\\d+)-(\\d+)-(\\d+)", "$3/$2/$1"}, "01/12/2017"},
+		{[]interface{}{"你好世界", "世", "X"}, "你好X界"},
+		{[]interface{}{"abc", "b", nil}, nil},
+	}
+	for _, t := range tbl {
+		f, err := funcs[ast.RegexpReplace].getFunction(s.ctx, s.datumsToConstants(types.MakeDatums(t.args...)))
+		c.Assert(err, IsNil)
+		d, err := evalBuiltinFunc(f, nil)
+		c.Assert(err, IsNil)
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.expected), Commentf("%v", t.args))
+	}
+}
+
+func (s *testEvaluatorSuite) TestRegexpMemorized(c *C) {
+	defer testleak.AfterTest(c)()
+	strTp := types.NewFieldType(mysql.TypeVarString)
+	strTp.Charset, strTp.Collate = charset.CharsetUTF8, charset.CollationUTF8
+	col := &Column{RetType: strTp, Index: 0}
+	pat := &Constant{Value: types.NewStringDatum("^a"), RetType: strTp}
+	f, err := funcs[ast.RegexpLike].getFunction(s.ctx, []Expression{col, pat})
+	c.Assert(err, IsNil)
+	sig := f.(*builtinRegexpLikeSig)
+	for _, str := range []string{"abc", "bcd", "Abc"} {
+		_, _, err = sig.evalInt(types.DatumRow{types.NewStringDatum(str)})
+		c.Assert(err, IsNil)
+	}
+	// The constant pattern is compiled only once.
+	c.Assert(sig.memorizedRe, NotNil)
+	c.Assert(sig.memorizedRe.String(), Equals, "(?i)^a")
+
+	// The pattern of the column is compiled for every row.
+	f, err = funcs[ast.RegexpLike].getFunction(s.ctx, []Expression{pat, col})
+	c.Assert(err, IsNil)
+	sig = f.(*builtinRegexpLikeSig)
+	res, _, err := sig.evalInt(types.DatumRow{types.NewStringDatum("a")})
+	c.Assert(err, IsNil)
+	c.Assert(res, Equals, int64(1))
+	res, _, err = sig.evalInt(types.DatumRow{types.NewStringDatum("b")})
+	c.Assert(err, IsNil)
+	c.Assert(res, Equals, int64(0))
+	c.Assert(sig.memorizedRe, IsNil)
+}
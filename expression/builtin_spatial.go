@@ -0,0 +1,573 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	"math"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/stmtctx"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/types/geo"
+)
+
+var (
+	_ functionClass = &stGeomFromTextFunctionClass{}
+	_ functionClass = &stGeomFromWKBFunctionClass{}
+	_ functionClass = &stAsTextFunctionClass{}
+	_ functionClass = &stAsBinaryFunctionClass{}
+	_ functionClass = &pointFunctionClass{}
+	_ functionClass = &stXFunctionClass{}
+	_ functionClass = &stYFunctionClass{}
+	_ functionClass = &stSRIDFunctionClass{}
+	_ functionClass = &stDistanceFunctionClass{}
+	_ functionClass = &stContainsFunctionClass{}
+	_ functionClass = &stWithinFunctionClass{}
+	_ functionClass = &mbrContainsFunctionClass{}
+	_ functionClass = &mbrWithinFunctionClass{}
+)
+
+var (
+	_ builtinFunc = &builtinSTGeomFromTextSig{}
+	_ builtinFunc = &builtinSTGeomFromWKBSig{}
+	_ builtinFunc = &builtinSTAsTextSig{}
+	_ builtinFunc = &builtinSTAsBinarySig{}
+	_ builtinFunc = &builtinPointSig{}
+	_ builtinFunc = &builtinSTXSig{}
+	_ builtinFunc = &builtinSTYSig{}
+	_ builtinFunc = &builtinSTSRIDSig{}
+	_ builtinFunc = &builtinSTDistanceSig{}
+	_ builtinFunc = &builtinSTContainsSig{}
+	_ builtinFunc = &builtinSTWithinSig{}
+	_ builtinFunc = &builtinMBRContainsSig{}
+	_ builtinFunc = &builtinMBRWithinSig{}
+)
+
+// Geometries are strings of the internal storage format described in types/geo, like MySQL does.
+
+// setGeometryFieldType sets the return type of a function returning a geometry.
+func setGeometryFieldType(tp *types.FieldType) {
+	tp.Tp = mysql.TypeGeometry
+	tp.Flen = mysql.MaxBlobWidth
+	types.SetBinChsClnFlag(tp)
+}
+
+// evalGeometry evaluates arg and decodes it into a geometry.
+func evalGeometry(arg Expression, row types.Row, sc *stmtctx.StatementContext, funcName string) (g geo.Geometry, isNull bool, err error) {
+	data, isNull, err := arg.EvalString(row, sc)
+	if isNull || err != nil {
+		return g, isNull, errors.Trace(err)
+	}
+	g, err = geo.Decode([]byte(data))
+	if err != nil {
+		return g, true, errGISInvalidData.GenByArgs(funcName)
+	}
+	return g, false, nil
+}
+
+// evalGeometryPair evaluates the two geometry arguments of a binary spatial function.
+func evalGeometryPair(args []Expression, row types.Row, sc *stmtctx.StatementContext, funcName string) (g1, g2 geo.Geometry, isNull bool, err error) {
+	g1, isNull, err = evalGeometry(args[0], row, sc, funcName)
+	if isNull || err != nil {
+		return g1, g2, isNull, errors.Trace(err)
+	}
+	g2, isNull, err = evalGeometry(args[1], row, sc, funcName)
+	if isNull || err != nil {
+		return g1, g2, isNull, errors.Trace(err)
+	}
+	if g1.SRID != g2.SRID {
+		return g1, g2, true, errGISDifferentSRIDs.GenByArgs(funcName, g1.SRID, g2.SRID)
+	}
+	return g1, g2, false, nil
+}
+
+// evalSRID evaluates the optional SRID argument of the geometry constructors.
+func evalSRID(args []Expression, row types.Row, sc *stmtctx.StatementContext, funcName string) (srid uint32, isNull bool, err error) {
+	if len(args) < 2 {
+		return 0, false, nil
+	}
+	val, isNull, err := args[1].EvalInt(row, sc)
+	if isNull || err != nil {
+		return 0, isNull, errors.Trace(err)
+	}
+	if val < 0 || val > math.MaxUint32 {
+		return 0, true, errGISInvalidData.GenByArgs(funcName)
+	}
+	return uint32(val), false, nil
+}
+
+type stGeomFromTextFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinSTGeomFromTextSig struct {
+	baseBuiltinFunc
+}
+
+func (c *stGeomFromTextFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	argTps := []types.EvalType{types.ETString, types.ETInt}
+	bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETString, argTps[:len(args)]...)
+	setGeometryFieldType(bf.tp)
+	sig := &builtinSTGeomFromTextSig{bf}
+	return sig, nil
+}
+
+// evalString evals a builtinSTGeomFromTextSig.
+// See https://dev.mysql.com/doc/refman/5.7/en/gis-wkt-functions.html#function_st-geomfromtext
+func (b *builtinSTGeomFromTextSig) evalString(row types.Row) (string, bool, error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	wkt, isNull, err := b.args[0].EvalString(row, sc)
+	if isNull || err != nil {
+		return "", isNull, errors.Trace(err)
+	}
+	srid, isNull, err := evalSRID(b.args, row, sc, "st_geomfromtext")
+	if isNull || err != nil {
+		return "", isNull, errors.Trace(err)
+	}
+	g, err := geo.ParseWKT(wkt, srid)
+	if err != nil {
+		return "", true, errGISInvalidData.GenByArgs("st_geomfromtext")
+	}
+	return string(g.Encode()), false, nil
+}
+
+type stGeomFromWKBFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinSTGeomFromWKBSig struct {
+	baseBuiltinFunc
+}
+
+func (c *stGeomFromWKBFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	argTps := []types.EvalType{types.ETString, types.ETInt}
+	bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETString, argTps[:len(args)]...)
+	setGeometryFieldType(bf.tp)
+	sig := &builtinSTGeomFromWKBSig{bf}
+	return sig, nil
+}
+
+// evalString evals a builtinSTGeomFromWKBSig.
+// See https://dev.mysql.com/doc/refman/5.7/en/gis-wkb-functions.html#function_st-geomfromwkb
+func (b *builtinSTGeomFromWKBSig) evalString(row types.Row) (string, bool, error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	wkb, isNull, err := b.args[0].EvalString(row, sc)
+	if isNull || err != nil {
+		return "", isNull, errors.Trace(err)
+	}
+	srid, isNull, err := evalSRID(b.args, row, sc, "st_geomfromwkb")
+	if isNull || err != nil {
+		return "", isNull, errors.Trace(err)
+	}
+	g, err := geo.ParseWKB([]byte(wkb), srid)
+	if err != nil {
+		return "", true, errGISInvalidData.GenByArgs("st_geomfromwkb")
+	}
+	return string(g.Encode()), false, nil
+}
+
+type stAsTextFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinSTAsTextSig struct {
+	baseBuiltinFunc
+}
+
+func (c *stAsTextFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETString, types.ETString)
+	bf.tp.Flen = mysql.MaxBlobWidth
+	sig := &builtinSTAsTextSig{bf}
+	return sig, nil
+}
+
+// evalString evals a builtinSTAsTextSig.
+// See https://dev.mysql.com/doc/refman/5.7/en/gis-format-conversion-functions.html#function_st-astext
+func (b *builtinSTAsTextSig) evalString(row types.Row) (string, bool, error) {
+	g, isNull, err := evalGeometry(b.args[0], row, b.ctx.GetSessionVars().StmtCtx, "st_astext")
+	if isNull || err != nil {
+		return "", isNull, errors.Trace(err)
+	}
+	return g.WKT(), false, nil
+}
+
+type stAsBinaryFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinSTAsBinarySig struct {
+	baseBuiltinFunc
+}
+
+func (c *stAsBinaryFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETString, types.ETString)
+	bf.tp.Flen = mysql.MaxBlobWidth
+	types.SetBinChsClnFlag(bf.tp)
+	sig := &builtinSTAsBinarySig{bf}
+	return sig, nil
+}
+
+// evalString evals a builtinSTAsBinarySig.
+// See https://dev.mysql.com/doc/refman/5.7/en/gis-format-conversion-functions.html#function_st-asbinary
+func (b *builtinSTAsBinarySig) evalString(row types.Row) (string, bool, error) {
+	g, isNull, err := evalGeometry(b.args[0], row, b.ctx.GetSessionVars().StmtCtx, "st_asbinary")
+	if isNull || err != nil {
+		return "", isNull, errors.Trace(err)
+	}
+	return string(g.WKB()), false, nil
+}
+
+type pointFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinPointSig struct {
+	baseBuiltinFunc
+}
+
+func (c *pointFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETString, types.ETReal, types.ETReal)
+	setGeometryFieldType(bf.tp)
+	sig := &builtinPointSig{bf}
+	return sig, nil
+}
+
+// evalString evals a builtinPointSig.
+// See https://dev.mysql.com/doc/refman/5.7/en/gis-mysql-specific-functions.html#function_point
+func (b *builtinPointSig) evalString(row types.Row) (string, bool, error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	x, isNull, err := b.args[0].EvalReal(row, sc)
+	if isNull || err != nil {
+		return "", isNull, errors.Trace(err)
+	}
+	y, isNull, err := b.args[1].EvalReal(row, sc)
+	if isNull || err != nil {
+		return "", isNull, errors.Trace(err)
+	}
+	return string(geo.NewPoint(0, x, y).Encode()), false, nil
+}
+
+type stXFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinSTXSig struct {
+	baseBuiltinFunc
+}
+
+func (c *stXFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETReal, types.ETString)
+	sig := &builtinSTXSig{bf}
+	return sig, nil
+}
+
+// evalReal evals a builtinSTXSig.
+// See https://dev.mysql.com/doc/refman/5.7/en/gis-point-property-functions.html#function_st-x
+func (b *builtinSTXSig) evalReal(row types.Row) (float64, bool, error) {
+	g, isNull, err := evalGeometry(b.args[0], row, b.ctx.GetSessionVars().StmtCtx, "st_x")
+	if isNull || err != nil {
+		return 0, isNull, errors.Trace(err)
+	}
+	if g.Type != geo.TypePoint {
+		return 0, true, errGISInvalidData.GenByArgs("st_x")
+	}
+	return g.Points[0].X, false, nil
+}
+
+type stYFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinSTYSig struct {
+	baseBuiltinFunc
+}
+
+func (c *stYFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETReal, types.ETString)
+	sig := &builtinSTYSig{bf}
+	return sig, nil
+}
+
+// evalReal evals a builtinSTYSig.
+// See https://dev.mysql.com/doc/refman/5.7/en/gis-point-property-functions.html#function_st-y
+func (b *builtinSTYSig) evalReal(row types.Row) (float64, bool, error) {
+	g, isNull, err := evalGeometry(b.args[0], row, b.ctx.GetSessionVars().StmtCtx, "st_y")
+	if isNull || err != nil {
+		return 0, isNull, errors.Trace(err)
+	}
+	if g.Type != geo.TypePoint {
+		return 0, true, errGISInvalidData.GenByArgs("st_y")
+	}
+	return g.Points[0].Y, false, nil
+}
+
+type stSRIDFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinSTSRIDSig struct {
+	baseBuiltinFunc
+}
+
+func (c *stSRIDFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETInt, types.ETString)
+	bf.tp.Flag |= mysql.UnsignedFlag
+	sig := &builtinSTSRIDSig{bf}
+	return sig, nil
+}
+
+// evalInt evals a builtinSTSRIDSig.
+// See https://dev.mysql.com/doc/refman/5.7/en/gis-general-property-functions.html#function_st-srid
+func (b *builtinSTSRIDSig) evalInt(row types.Row) (int64, bool, error) {
+	g, isNull, err := evalGeometry(b.args[0], row, b.ctx.GetSessionVars().StmtCtx, "st_srid")
+	if isNull || err != nil {
+		return 0, isNull, errors.Trace(err)
+	}
+	return int64(g.SRID), false, nil
+}
+
+type stDistanceFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinSTDistanceSig struct {
+	baseBuiltinFunc
+}
+
+func (c *stDistanceFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETReal, types.ETString, types.ETString)
+	sig := &builtinSTDistanceSig{bf}
+	return sig, nil
+}
+
+// evalReal evals a builtinSTDistanceSig.
+// See https://dev.mysql.com/doc/refman/5.7/en/spatial-relation-functions-object-shapes.html#function_st-distance
+func (b *builtinSTDistanceSig) evalReal(row types.Row) (float64, bool, error) {
+	g1, g2, isNull, err := evalGeometryPair(b.args, row, b.ctx.GetSessionVars().StmtCtx, "st_distance")
+	if isNull || err != nil {
+		return 0, isNull, errors.Trace(err)
+	}
+	return geo.Distance(g1, g2), false, nil
+}
+
+type stContainsFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinSTContainsSig struct {
+	baseBuiltinFunc
+}
+
+func (c *stContainsFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETInt, types.ETString, types.ETString)
+	bf.tp.Flen = 1
+	sig := &builtinSTContainsSig{bf}
+	return sig, nil
+}
+
+// evalInt evals a builtinSTContainsSig.
+// See https://dev.mysql.com/doc/refman/5.7/en/spatial-relation-functions-object-shapes.html#function_st-contains
+func (b *builtinSTContainsSig) evalInt(row types.Row) (int64, bool, error) {
+	g1, g2, isNull, err := evalGeometryPair(b.args, row, b.ctx.GetSessionVars().StmtCtx, "st_contains")
+	if isNull || err != nil {
+		return 0, isNull, errors.Trace(err)
+	}
+	return boolToInt64(geo.Contains(g1, g2)), false, nil
+}
+
+type stWithinFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinSTWithinSig struct {
+	baseBuiltinFunc
+}
+
+func (c *stWithinFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETInt, types.ETString, types.ETString)
+	bf.tp.Flen = 1
+	sig := &builtinSTWithinSig{bf}
+	return sig, nil
+}
+
+// evalInt evals a builtinSTWithinSig.
+// See https://dev.mysql.com/doc/refman/5.7/en/spatial-relation-functions-object-shapes.html#function_st-within
+func (b *builtinSTWithinSig) evalInt(row types.Row) (int64, bool, error) {
+	g1, g2, isNull, err := evalGeometryPair(b.args, row, b.ctx.GetSessionVars().StmtCtx, "st_within")
+	if isNull || err != nil {
+		return 0, isNull, errors.Trace(err)
+	}
+	return boolToInt64(geo.Within(g1, g2)), false, nil
+}
+
+type mbrContainsFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinMBRContainsSig struct {
+	baseBuiltinFunc
+}
+
+func (c *mbrContainsFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETInt, types.ETString, types.ETString)
+	bf.tp.Flen = 1
+	sig := &builtinMBRContainsSig{bf}
+	return sig, nil
+}
+
+// evalInt evals a builtinMBRContainsSig.
+// See https://dev.mysql.com/doc/refman/5.7/en/spatial-relation-functions-mbr.html#function_mbrcontains
+func (b *builtinMBRContainsSig) evalInt(row types.Row) (int64, bool, error) {
+	g1, g2, isNull, err := evalGeometryPair(b.args, row, b.ctx.GetSessionVars().StmtCtx, "mbrcontains")
+	if isNull || err != nil {
+		return 0, isNull, errors.Trace(err)
+	}
+	return boolToInt64(g1.Envelope().Contains(g2.Envelope())), false, nil
+}
+
+type mbrWithinFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinMBRWithinSig struct {
+	baseBuiltinFunc
+}
+
+func (c *mbrWithinFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETInt, types.ETString, types.ETString)
+	bf.tp.Flen = 1
+	sig := &builtinMBRWithinSig{bf}
+	return sig, nil
+}
+
+// evalInt evals a builtinMBRWithinSig.
+// See https://dev.mysql.com/doc/refman/5.7/en/spatial-relation-functions-mbr.html#function_mbrwithin
+func (b *builtinMBRWithinSig) evalInt(row types.Row) (int64, bool, error) {
+	g1, g2, isNull, err := evalGeometryPair(b.args, row, b.ctx.GetSessionVars().StmtCtx, "mbrwithin")
+	if isNull || err != nil {
+		return 0, isNull, errors.Trace(err)
+	}
+	return boolToInt64(g2.Envelope().Contains(g1.Envelope())), false, nil
+}
+
+// DeriveMBRConds derives the conditions on the coordinates of the POINT columns from the spatial relations between
+// them and the constant geometries, such as ST_Contains(g, p), ST_Within(p, g), MBRContains(g, p) and MBRWithin(p, g):
+// the point lies in the minimum bounding rectangle of g. The conditions are implied by the relations, so they can be
+// the access conditions of the expression indexes on ST_X(p) and ST_Y(p), which filter out the rows before the
+// relations are refined by the exact computation.
+func DeriveMBRConds(ctx context.Context, conds []Expression) []Expression {
+	var derived []Expression
+	for _, cond := range conds {
+		f, ok := cond.(*ScalarFunction)
+		if !ok {
+			continue
+		}
+		var geomArg, pointArg Expression
+		switch f.FuncName.L {
+		case ast.STContains, ast.MBRContains:
+			geomArg, pointArg = f.GetArgs()[0], f.GetArgs()[1]
+		case ast.STWithin, ast.MBRWithin:
+			pointArg, geomArg = f.GetArgs()[0], f.GetArgs()[1]
+		default:
+			continue
+		}
+		col, ok := pointArg.(*Column)
+		if !ok || col.RetType.Tp != mysql.TypeGeometry || col.RetType.GeomType != geo.TypePoint {
+			continue
+		}
+		con, ok := geomArg.(*Constant)
+		if !ok {
+			continue
+		}
+		g, isNull, err := evalGeometry(con, nil, ctx.GetSessionVars().StmtCtx, f.FuncName.L)
+		if isNull || err != nil {
+			continue
+		}
+		mbrConds, err := pointInMBRConds(ctx, col, g.Envelope())
+		if err != nil {
+			continue
+		}
+		derived = append(derived, mbrConds...)
+	}
+	return derived
+}
+
+// pointInMBRConds builds the conditions that the point lies in the rectangle, boundaries included.
+func pointInMBRConds(ctx context.Context, col *Column, m geo.MBR) ([]Expression, error) {
+	bounds := []struct {
+		cmp, coord string
+		val        float64
+	}{
+		{ast.GE, ast.STX, m.MinX},
+		{ast.LE, ast.STX, m.MaxX},
+		{ast.GE, ast.STY, m.MinY},
+		{ast.LE, ast.STY, m.MaxY},
+	}
+	conds := make([]Expression, 0, len(bounds))
+	for _, b := range bounds {
+		coord, err := NewFunction(ctx, b.coord, types.NewFieldType(mysql.TypeDouble), col)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		val := &Constant{Value: types.NewFloat64Datum(b.val), RetType: types.NewFieldType(mysql.TypeDouble)}
+		cond, err := NewFunction(ctx, b.cmp, types.NewFieldType(mysql.TypeTiny), coord, val)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		conds = append(conds, cond)
+	}
+	return conds, nil
+}
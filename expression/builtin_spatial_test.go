@@ -0,0 +1,156 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/types/geo"
+	"github.com/pingcap/tidb/util/testleak"
+	"github.com/pingcap/tidb/util/testutil"
+)
+
+func (s *testEvaluatorSuite) mustGeometry(c *C, wkt string, srid uint32) string {
+	g, err := geo.ParseWKT(wkt, srid)
+	c.Assert(err, IsNil)
+	return string(g.Encode())
+}
+
+func (s *testEvaluatorSuite) TestSTGeomFromTextAndAsText(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		Input    []interface{}
+		Expected interface{}
+		Success  bool
+	}{
+		{[]interface{}{nil}, nil, true},
+		{[]interface{}{"POINT(1 2)"}, s.mustGeometry(c, "POINT(1 2)", 0), true},
+		{[]interface{}{"linestring(0 0, 1 1)", 4326}, s.mustGeometry(c, "LINESTRING(0 0,1 1)", 4326), true},
+		{[]interface{}{"POINT(1 2)", nil}, nil, true},
+		{[]interface{}{"POINT(1)"}, nil, false},
+		{[]interface{}{"POINT(1 2)", -1}, nil, false},
+	}
+	for _, t := range tbl {
+		f, err := funcs[ast.STGeomFromText].getFunction(s.ctx, s.datumsToConstants(types.MakeDatums(t.Input...)))
+		c.Assert(err, IsNil)
+		c.Assert(f.getRetTp().Tp, Equals, mysql.TypeGeometry)
+		d, err := evalBuiltinFunc(f, nil)
+		if !t.Success {
+			c.Assert(terror.ErrorEqual(err, errGISInvalidData), IsTrue, Commentf("%v", t.Input))
+			continue
+		}
+		c.Assert(err, IsNil)
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.Expected))
+	}
+
+	for _, wkt := range []string{"POINT(-1.5 2)", "POLYGON((0 0,0 1,1 1,0 0))"} {
+		f, err := funcs[ast.STAsText].getFunction(s.ctx, s.datumsToConstants(types.MakeDatums(s.mustGeometry(c, wkt, 0))))
+		c.Assert(err, IsNil)
+		d, err := evalBuiltinFunc(f, nil)
+		c.Assert(err, IsNil)
+		c.Assert(d.GetString(), Equals, wkt)
+	}
+	f, err := funcs[ast.STAsText].getFunction(s.ctx, s.datumsToConstants(types.MakeDatums("not a geometry")))
+	c.Assert(err, IsNil)
+	_, err = evalBuiltinFunc(f, nil)
+	c.Assert(terror.ErrorEqual(err, errGISInvalidData), IsTrue)
+}
+
+func (s *testEvaluatorSuite) TestSTWKB(c *C) {
+	defer testleak.AfterTest(c)()
+	pt := s.mustGeometry(c, "POINT(1 -1)", 0)
+	f, err := funcs[ast.STAsBinary].getFunction(s.ctx, s.datumsToConstants(types.MakeDatums(pt)))
+	c.Assert(err, IsNil)
+	d, err := evalBuiltinFunc(f, nil)
+	c.Assert(err, IsNil)
+	c.Assert(d.GetString(), Equals, pt[4:])
+
+	f, err = funcs[ast.STGeomFromWKB].getFunction(s.ctx, s.datumsToConstants(types.MakeDatums(d.GetString(), 7)))
+	c.Assert(err, IsNil)
+	d, err = evalBuiltinFunc(f, nil)
+	c.Assert(err, IsNil)
+	c.Assert(d.GetString(), Equals, s.mustGeometry(c, "POINT(1 -1)", 7))
+}
+
+func (s *testEvaluatorSuite) TestPointAndProperties(c *C) {
+	defer testleak.AfterTest(c)()
+	f, err := funcs[ast.Point].getFunction(s.ctx, s.datumsToConstants(types.MakeDatums(1.5, 2)))
+	c.Assert(err, IsNil)
+	d, err := evalBuiltinFunc(f, nil)
+	c.Assert(err, IsNil)
+	pt := d.GetString()
+	c.Assert(pt, Equals, s.mustGeometry(c, "POINT(1.5 2)", 0))
+
+	tbl := []struct {
+		fn       string
+		arg      interface{}
+		Expected interface{}
+	}{
+		{ast.STX, pt, 1.5},
+		{ast.STY, pt, float64(2)},
+		{ast.STX, nil, nil},
+		{ast.STSRID, s.mustGeometry(c, "POINT(0 0)", 4326), uint64(4326)},
+	}
+	for _, t := range tbl {
+		f, err = funcs[t.fn].getFunction(s.ctx, s.datumsToConstants(types.MakeDatums(t.arg)))
+		c.Assert(err, IsNil)
+		d, err = evalBuiltinFunc(f, nil)
+		c.Assert(err, IsNil)
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.Expected))
+	}
+
+	f, err = funcs[ast.STX].getFunction(s.ctx, s.datumsToConstants(types.MakeDatums(s.mustGeometry(c, "LINESTRING(0 0,1 1)", 0))))
+	c.Assert(err, IsNil)
+	_, err = evalBuiltinFunc(f, nil)
+	c.Assert(terror.ErrorEqual(err, errGISInvalidData), IsTrue)
+}
+
+func (s *testEvaluatorSuite) TestSpatialRelations(c *C) {
+	defer testleak.AfterTest(c)()
+	square := s.mustGeometry(c, "POLYGON((0 0,0 4,4 4,4 0,0 0))", 0)
+	triangle := s.mustGeometry(c, "POLYGON((0 0,0 4,4 0,0 0))", 0)
+	tbl := []struct {
+		fn       string
+		a, b     interface{}
+		Expected interface{}
+	}{
+		{ast.STContains, square, s.mustGeometry(c, "POINT(1 1)", 0), int64(1)},
+		{ast.STContains, square, s.mustGeometry(c, "POINT(4 1)", 0), int64(0)},
+		{ast.STWithin, s.mustGeometry(c, "POINT(1 1)", 0), square, int64(1)},
+		{ast.STContains, triangle, s.mustGeometry(c, "POINT(3 3)", 0), int64(0)},
+		// The bounding rectangle of the triangle contains the point.
+		{ast.MBRContains, triangle, s.mustGeometry(c, "POINT(3 3)", 0), int64(1)},
+		{ast.MBRWithin, s.mustGeometry(c, "POINT(3 3)", 0), triangle, int64(1)},
+		{ast.MBRContains, triangle, s.mustGeometry(c, "POINT(5 3)", 0), int64(0)},
+		{ast.STDistance, s.mustGeometry(c, "POINT(0 0)", 0), s.mustGeometry(c, "POINT(3 4)", 0), float64(5)},
+		{ast.STDistance, square, s.mustGeometry(c, "POINT(2 2)", 0), float64(0)},
+		{ast.STContains, nil, square, nil},
+		{ast.STDistance, square, nil, nil},
+	}
+	for _, t := range tbl {
+		f, err := funcs[t.fn].getFunction(s.ctx, s.datumsToConstants(types.MakeDatums(t.a, t.b)))
+		c.Assert(err, IsNil)
+		d, err := evalBuiltinFunc(f, nil)
+		c.Assert(err, IsNil)
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.Expected), Commentf("%s", t.fn))
+	}
+
+	f, err := funcs[ast.STContains].getFunction(s.ctx, s.datumsToConstants(types.MakeDatums(square, s.mustGeometry(c, "POINT(1 1)", 4326))))
+	c.Assert(err, IsNil)
+	_, err = evalBuiltinFunc(f, nil)
+	c.Assert(terror.ErrorEqual(err, errGISDifferentSRIDs), IsTrue)
+}
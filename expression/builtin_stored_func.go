@@ -0,0 +1,158 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/types/json"
+	"github.com/pingcap/tidb/util/sqlexec"
+)
+
+var (
+	_ functionClass = &storedFunctionClass{}
+	_ builtinFunc   = &builtinStoredFuncSig{}
+)
+
+// loadStoredFunction returns the function class of the stored function name, it returns nil if
+// the function doesn't exist or ctx can't run stored routines.
+func loadStoredFunction(ctx context.Context, name string) (functionClass, error) {
+	re, ok := ctx.(sqlexec.RoutineExecutor)
+	if !ok {
+		return nil, nil
+	}
+	fn, err := re.LoadFunction(name)
+	if fn == nil || err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &storedFunctionClass{name: name, fn: fn}, nil
+}
+
+// IsStoredFunction returns whether expr calls a stored function.
+func IsStoredFunction(expr Expression) bool {
+	if sf, ok := expr.(*ScalarFunction); ok {
+		_, ok = sf.Function.(*builtinStoredFuncSig)
+		return ok
+	}
+	return false
+}
+
+// storedFunctionClass creates the signatures of a stored function.
+type storedFunctionClass struct {
+	name string
+	fn   sqlexec.StoredFunction
+}
+
+func (c *storedFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	paramTps := c.fn.ParamTypes()
+	if len(args) != len(paramTps) {
+		return nil, errWrongNoOfArgs.GenByArgs("FUNCTION", c.name, len(paramTps), len(args))
+	}
+	ctx.GetSessionVars().StmtCtx.CallsStoredFunction = true
+	bf := newBaseBuiltinFunc(ctx, args)
+	tp := *c.fn.RetType()
+	bf.tp = &tp
+	sig := &builtinStoredFuncSig{baseBuiltinFunc: bf, fn: c.fn}
+	return sig, nil
+}
+
+// builtinStoredFuncSig calls a stored function. It's never folded or pushed down, because
+// the function runs statements in the session.
+type builtinStoredFuncSig struct {
+	baseBuiltinFunc
+
+	fn sqlexec.StoredFunction
+}
+
+// eval evaluates the arguments and calls the function.
+func (b *builtinStoredFuncSig) eval(row types.Row) (types.Datum, error) {
+	args := make([]types.Datum, 0, len(b.args))
+	for _, arg := range b.args {
+		d, err := arg.Eval(row)
+		if err != nil {
+			return d, errors.Trace(err)
+		}
+		args = append(args, d)
+	}
+	d, err := b.fn.Call(args)
+	return d, errors.Trace(err)
+}
+
+// evalInt evals a builtinStoredFuncSig which returns an integer.
+func (b *builtinStoredFuncSig) evalInt(row types.Row) (int64, bool, error) {
+	d, err := b.eval(row)
+	if d.IsNull() || err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	val, err := d.ToInt64(b.ctx.GetSessionVars().StmtCtx)
+	return val, false, errors.Trace(err)
+}
+
+// evalReal evals a builtinStoredFuncSig which returns a float or a double.
+func (b *builtinStoredFuncSig) evalReal(row types.Row) (float64, bool, error) {
+	d, err := b.eval(row)
+	if d.IsNull() || err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	val, err := d.ToFloat64(b.ctx.GetSessionVars().StmtCtx)
+	return val, false, errors.Trace(err)
+}
+
+// evalDecimal evals a builtinStoredFuncSig which returns a decimal.
+func (b *builtinStoredFuncSig) evalDecimal(row types.Row) (*types.MyDecimal, bool, error) {
+	d, err := b.eval(row)
+	if d.IsNull() || err != nil {
+		return nil, true, errors.Trace(err)
+	}
+	val, err := d.ToDecimal(b.ctx.GetSessionVars().StmtCtx)
+	return val, false, errors.Trace(err)
+}
+
+// evalString evals a builtinStoredFuncSig which returns a string.
+func (b *builtinStoredFuncSig) evalString(row types.Row) (string, bool, error) {
+	d, err := b.eval(row)
+	if d.IsNull() || err != nil {
+		return "", true, errors.Trace(err)
+	}
+	val, err := d.ToString()
+	return val, false, errors.Trace(err)
+}
+
+// evalTime evals a builtinStoredFuncSig which returns a date, a datetime or a timestamp.
+func (b *builtinStoredFuncSig) evalTime(row types.Row) (types.Time, bool, error) {
+	d, err := b.eval(row)
+	if d.IsNull() || err != nil {
+		return types.Time{}, true, errors.Trace(err)
+	}
+	return d.GetMysqlTime(), false, nil
+}
+
+// evalDuration evals a builtinStoredFuncSig which returns a time.
+func (b *builtinStoredFuncSig) evalDuration(row types.Row) (types.Duration, bool, error) {
+	d, err := b.eval(row)
+	if d.IsNull() || err != nil {
+		return types.Duration{}, true, errors.Trace(err)
+	}
+	return d.GetMysqlDuration(), false, nil
+}
+
+// evalJSON evals a builtinStoredFuncSig which returns a JSON.
+func (b *builtinStoredFuncSig) evalJSON(row types.Row) (json.BinaryJSON, bool, error) {
+	d, err := b.eval(row)
+	if d.IsNull() || err != nil {
+		return json.BinaryJSON{}, true, errors.Trace(err)
+	}
+	return d.GetMysqlJSON(), false, nil
+}
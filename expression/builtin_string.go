@@ -709,6 +709,9 @@ func (c *strcmpFunctionClass) getFunction(ctx context.Context, args []Expression
 	if err := c.verifyArgs(args); err != nil {
 		return nil, errors.Trace(err)
 	}
+	if _, err := checkIllegalMixCollation(c.funcName, args); err != nil {
+		return nil, errors.Trace(err)
+	}
 	bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETInt, types.ETString, types.ETString)
 	bf.tp.Flen = 2
 	types.SetBinChsClnFlag(bf.tp)
@@ -738,7 +741,7 @@ func (b *builtinStrcmpSig) evalInt(row types.Row) (int64, bool, error) {
 	if isNull || err != nil {
 		return 0, isNull, errors.Trace(err)
 	}
-	res := types.CompareString(left, right)
+	res := compareStringWithCollation(left, right, b.collation)
 	return int64(res), false, nil
 }
 
@@ -0,0 +1,75 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/chunk"
+)
+
+func (b *builtinLengthSig) vectorized() bool {
+	return true
+}
+
+func (b *builtinLengthSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column) error {
+	numRows := input.NumRows()
+	arg := newVecBuffer(types.ETString, numRows)
+	if err := vecEvalExprString(b.ctx.GetSessionVars().StmtCtx, b.args[0], input, arg); err != nil {
+		return errors.Trace(err)
+	}
+	result.ResizeInt64(numRows)
+	result.MergeNulls(arg)
+	res := result.Int64s()
+	for i := range res {
+		res[i] = int64(len(arg.GetBytes(i)))
+	}
+	return nil
+}
+
+func (b *builtinConcatSig) vectorized() bool {
+	return true
+}
+
+func (b *builtinConcatSig) vecEvalString(input *chunk.Chunk, result *chunk.Column) error {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	numRows := input.NumRows()
+	args := make([]*chunk.Column, 0, len(b.args))
+	for _, arg := range b.args {
+		buf := newVecBuffer(types.ETString, numRows)
+		if err := vecEvalExprString(sc, arg, input, buf); err != nil {
+			return errors.Trace(err)
+		}
+		args = append(args, buf)
+	}
+	result.Reset()
+	var s []byte
+	for i := 0; i < numRows; i++ {
+		s = s[:0]
+		isNull := false
+		for _, arg := range args {
+			if arg.IsNull(i) {
+				isNull = true
+				break
+			}
+			s = append(s, arg.GetBytes(i)...)
+		}
+		if isNull {
+			result.AppendNull()
+		} else {
+			result.AppendBytes(s)
+		}
+	}
+	return nil
+}
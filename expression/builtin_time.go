@@ -32,6 +32,7 @@ import (
 	"github.com/pingcap/tidb/sessionctx/stmtctx"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/timeutil"
 	"github.com/pingcap/tipb/go-tipb"
 	log "github.com/sirupsen/logrus"
 )
@@ -3592,25 +3593,15 @@ func (c *convertTzFunctionClass) getFunction(ctx context.Context, args []Express
 	if err := c.verifyArgs(args); err != nil {
 		return nil, errors.Trace(err)
 	}
-	// tzRegex holds the regex to check whether a string is a time zone.
-	tzRegex, err := regexp.Compile(`(^(\+|-)(0?[0-9]|1[0-2]):[0-5]?\d$)|(^\+13:00$)`)
-	if err != nil {
-		return nil, errors.Trace(err)
-	}
-
 	decimal := c.getDecimal(ctx, args[0])
 	bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETDatetime, types.ETDatetime, types.ETString, types.ETString)
 	bf.tp.Decimal = decimal
-	sig := &builtinConvertTzSig{
-		baseBuiltinFunc: bf,
-		timezoneRegex:   tzRegex,
-	}
+	sig := &builtinConvertTzSig{bf}
 	return sig, nil
 }
 
 type builtinConvertTzSig struct {
 	baseBuiltinFunc
-	timezoneRegex *regexp.Regexp
 }
 
 // evalTime evals CONVERT_TZ(dt,from_tz,to_tz).
@@ -3633,44 +3624,27 @@ func (b *builtinConvertTzSig) evalTime(row types.Row) (types.Time, bool, error)
 		return types.Time{}, true, errors.Trace(err)
 	}
 
-	fromTzMatched := b.timezoneRegex.MatchString(fromTzStr)
-	toTzMatched := b.timezoneRegex.MatchString(toTzStr)
-
-	if !fromTzMatched && !toTzMatched {
-		fromTz, err := time.LoadLocation(fromTzStr)
-		if err != nil {
-			return types.Time{}, true, errors.Trace(err)
-		}
-
-		toTz, err := time.LoadLocation(toTzStr)
-		if err != nil {
-			return types.Time{}, true, errors.Trace(err)
-		}
-
-		t, err := dt.Time.GoTime(fromTz)
-		if err != nil {
-			return types.Time{}, true, errors.Trace(err)
-		}
-
-		return types.Time{
-			Time: types.FromGoTime(t.In(toTz)),
-			Type: mysql.TypeDatetime,
-			Fsp:  b.tp.Decimal,
-		}, false, nil
+	// The time zones can be offsets like '+10:00' or names like 'Europe/Moscow', the result is NULL if
+	// any of them is invalid.
+	fromTz, err := timeutil.ParseTimeZone(fromTzStr)
+	if err != nil {
+		return types.Time{}, true, nil
+	}
+	toTz, err := timeutil.ParseTimeZone(toTzStr)
+	if err != nil {
+		return types.Time{}, true, nil
 	}
-	if fromTzMatched && toTzMatched {
-		t, err := dt.Time.GoTime(time.Local)
-		if err != nil {
-			return types.Time{}, true, errors.Trace(err)
-		}
 
-		return types.Time{
-			Time: types.FromGoTime(t.Add(timeZone2Duration(toTzStr) - timeZone2Duration(fromTzStr))),
-			Type: mysql.TypeDatetime,
-			Fsp:  b.tp.Decimal,
-		}, false, nil
+	t, err := dt.Time.GoTime(fromTz)
+	if err != nil {
+		return types.Time{}, true, errors.Trace(err)
 	}
-	return types.Time{}, true, nil
+
+	return types.Time{
+		Time: types.FromGoTime(t.In(toTz)),
+		Type: mysql.TypeDatetime,
+		Fsp:  b.tp.Decimal,
+	}, false, nil
 }
 
 type makeDateFunctionClass struct {
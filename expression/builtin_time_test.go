@@ -2090,9 +2090,14 @@ func (s *testEvaluatorSuite) TestConvertTz(c *C) {
 		{"2004-01-01 12:00:00", "-00:00", "+13:00", true, "2004-01-02 01:00:00"},
 		{"2004-01-01 12:00:00", "-00:00", "-13:00", true, ""},
 		{"2004-01-01 12:00:00", "-00:00", "-12:88", true, ""},
-		{"2004-01-01 12:00:00", "+10:82", "GMT", false, ""},
-		{"2004-01-01 12:00:00", "+00:00", "GMT", true, ""},
-		{"2004-01-01 12:00:00", "GMT", "+00:00", true, ""},
+		{"2004-01-01 12:00:00", "+10:82", "GMT", true, ""},
+		{"2004-01-01 12:00:00", "+00:00", "GMT", true, "2004-01-01 12:00:00"},
+		{"2004-01-01 12:00:00", "GMT", "+00:00", true, "2004-01-01 12:00:00"},
+		{"2004-01-01 12:00:00", "+00:00", "Unknown/Zone", true, ""},
+		{"2017-03-12 06:59:59", "+00:00", "America/New_York", true, "2017-03-12 01:59:59"},
+		{"2017-03-12 07:00:00", "+00:00", "America/New_York", true, "2017-03-12 03:00:00"},
+		{"2017-11-05 01:30:00", "America/New_York", "UTC", true, "2017-11-05 05:30:00"},
+		{"2017-07-01 12:00:00", "Europe/Berlin", "Asia/Shanghai", true, "2017-07-01 18:00:00"},
 		{20040101, "+00:00", "+10:32", true, "2004-01-01 10:32:00"},
 		{3.14159, "+00:00", "+10:32", false, ""},
 	}
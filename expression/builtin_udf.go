@@ -0,0 +1,175 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	"fmt"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/udf"
+)
+
+var (
+	_ functionClass = &udfFunctionClass{}
+	_ builtinFunc   = &builtinUDFSig{}
+)
+
+// IsBuiltinFunc checks whether name is the name of a builtin scalar function.
+func IsBuiltinFunc(name string) bool {
+	_, ok := funcs[name]
+	return ok || name == ast.Cast
+}
+
+// udfFunctionClass creates the signatures of a user-defined function.
+type udfFunctionClass struct {
+	name string
+	fn   udf.Function
+}
+
+func (c *udfFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	udfArgTps := c.fn.ArgTypes()
+	if len(args) != len(udfArgTps) {
+		return nil, udf.ErrCantInitialize.GenByArgs(c.name, fmt.Sprintf("it requires %d arguments", len(udfArgTps)))
+	}
+	argTps := make([]types.EvalType, 0, len(args))
+	for _, tp := range udfArgTps {
+		argTps = append(argTps, tp.EvalType())
+	}
+	bf := newBaseBuiltinFuncWithTp(ctx, args, c.fn.RetType().EvalType(), argTps...)
+	switch c.fn.RetType() {
+	case udf.TypeString:
+		bf.tp.Flen = mysql.MaxBlobWidth
+	case udf.TypeDecimal:
+		bf.tp.Flen, bf.tp.Decimal = mysql.MaxDecimalWidth, mysql.MaxDecimalScale
+	}
+	sig := &builtinUDFSig{baseBuiltinFunc: bf, name: c.name, fn: c.fn}
+	return sig, nil
+}
+
+// builtinUDFSig calls a user-defined function. It's never folded or pushed down, because
+// the function may be nondeterministic and only the TiDB servers load the plugins.
+type builtinUDFSig struct {
+	baseBuiltinFunc
+
+	name string
+	fn   udf.Function
+}
+
+// evalArgs evaluates the arguments of the function as their declared types, NULL is passed as nil.
+func (b *builtinUDFSig) evalArgs(row types.Row) ([]interface{}, error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	vals := make([]interface{}, len(b.args))
+	for i, tp := range b.fn.ArgTypes() {
+		var (
+			val    interface{}
+			isNull bool
+			err    error
+		)
+		switch tp {
+		case udf.TypeInt:
+			val, isNull, err = b.args[i].EvalInt(row, sc)
+		case udf.TypeReal:
+			val, isNull, err = b.args[i].EvalReal(row, sc)
+		case udf.TypeDecimal:
+			var d *types.MyDecimal
+			d, isNull, err = b.args[i].EvalDecimal(row, sc)
+			if !isNull && err == nil {
+				val = string(d.ToString())
+			}
+		default:
+			val, isNull, err = b.args[i].EvalString(row, sc)
+		}
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if !isNull {
+			vals[i] = val
+		}
+	}
+	return vals, nil
+}
+
+// eval calls the function on row, the result is nil if it returns NULL.
+func (b *builtinUDFSig) eval(row types.Row) (interface{}, error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	res, err := b.fn.Eval(args)
+	return res, errors.Trace(err)
+}
+
+func (b *builtinUDFSig) errResultType(res interface{}) error {
+	return errors.Errorf("function %s returns %T rather than %s", b.name, res, b.fn.RetType())
+}
+
+// evalInt evals a builtinUDFSig which returns INTEGER.
+func (b *builtinUDFSig) evalInt(row types.Row) (int64, bool, error) {
+	res, err := b.eval(row)
+	if res == nil || err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	val, ok := res.(int64)
+	if !ok {
+		return 0, true, b.errResultType(res)
+	}
+	return val, false, nil
+}
+
+// evalReal evals a builtinUDFSig which returns REAL.
+func (b *builtinUDFSig) evalReal(row types.Row) (float64, bool, error) {
+	res, err := b.eval(row)
+	if res == nil || err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	val, ok := res.(float64)
+	if !ok {
+		return 0, true, b.errResultType(res)
+	}
+	return val, false, nil
+}
+
+// evalDecimal evals a builtinUDFSig which returns DECIMAL.
+func (b *builtinUDFSig) evalDecimal(row types.Row) (*types.MyDecimal, bool, error) {
+	res, err := b.eval(row)
+	if res == nil || err != nil {
+		return nil, true, errors.Trace(err)
+	}
+	str, ok := res.(string)
+	if !ok {
+		return nil, true, b.errResultType(res)
+	}
+	val := new(types.MyDecimal)
+	if err = val.FromString([]byte(str)); err != nil {
+		return nil, true, errors.Trace(err)
+	}
+	return val, false, nil
+}
+
+// evalString evals a builtinUDFSig which returns STRING.
+func (b *builtinUDFSig) evalString(row types.Row) (string, bool, error) {
+	res, err := b.eval(row)
+	if res == nil || err != nil {
+		return "", true, errors.Trace(err)
+	}
+	val, ok := res.(string)
+	if !ok {
+		return "", true, b.errResultType(res)
+	}
+	return val, false, nil
+}
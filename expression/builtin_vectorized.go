@@ -0,0 +1,271 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/stmtctx"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/chunk"
+)
+
+// The field types of the intermediate columns used in the vectorized evaluation.
+var (
+	vecIntTp    = types.NewFieldType(mysql.TypeLonglong)
+	vecRealTp   = types.NewFieldType(mysql.TypeDouble)
+	vecStringTp = types.NewFieldType(mysql.TypeVarString)
+)
+
+// newVecBuffer creates a column to hold the intermediate results of evalTp for numRows rows.
+func newVecBuffer(evalTp types.EvalType, numRows int) *chunk.Column {
+	switch evalTp {
+	case types.ETInt:
+		return chunk.NewColumn(vecIntTp, numRows)
+	case types.ETReal:
+		return chunk.NewColumn(vecRealTp, numRows)
+	default:
+		return chunk.NewColumn(vecStringTp, numRows)
+	}
+}
+
+// isVecExpr checks whether expr is a function which can be evaluated column by column.
+// The arguments of such a function can be any expression, they fall back to the row based evaluation if necessary.
+func isVecExpr(expr Expression) bool {
+	sf, ok := expr.(*ScalarFunction)
+	return ok && sf.Function.vectorized()
+}
+
+// vecEvalExprInt evaluates the int results of expr for all the rows in input and stores them in result.
+func vecEvalExprInt(sc *stmtctx.StatementContext, expr Expression, input *chunk.Chunk, result *chunk.Column) error {
+	switch x := expr.(type) {
+	case *Column:
+		if tp := x.GetType(); tp.EvalType() == types.ETInt && !tp.Hybrid() {
+			result.CopyFrom(input.Column(x.Index))
+			return nil
+		}
+	case *Constant:
+		val, isNull, err := x.EvalInt(nil, sc)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		result.ResizeInt64(input.NumRows())
+		vals := result.Int64s()
+		for i := range vals {
+			vals[i] = val
+			if isNull {
+				result.SetNull(i, true)
+			}
+		}
+		return nil
+	case *ScalarFunction:
+		if x.Function.vectorized() && x.Function.getRetTp().EvalType() == types.ETInt {
+			return errors.Trace(x.Function.vecEvalInt(input, result))
+		}
+	}
+	result.Reset()
+	for row := input.Begin(); row != input.End(); row = row.Next() {
+		val, isNull, err := expr.EvalInt(row, sc)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if isNull {
+			result.AppendNull()
+		} else {
+			result.AppendInt64(val)
+		}
+	}
+	return nil
+}
+
+// vecEvalExprReal evaluates the real results of expr for all the rows in input and stores them in result.
+func vecEvalExprReal(sc *stmtctx.StatementContext, expr Expression, input *chunk.Chunk, result *chunk.Column) error {
+	switch x := expr.(type) {
+	case *Column:
+		if tp := x.GetType(); tp.EvalType() == types.ETReal && tp.Tp == mysql.TypeDouble {
+			result.CopyFrom(input.Column(x.Index))
+			return nil
+		}
+	case *Constant:
+		val, isNull, err := x.EvalReal(nil, sc)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		result.ResizeFloat64(input.NumRows())
+		vals := result.Float64s()
+		for i := range vals {
+			vals[i] = val
+			if isNull {
+				result.SetNull(i, true)
+			}
+		}
+		return nil
+	case *ScalarFunction:
+		if x.Function.vectorized() && x.Function.getRetTp().EvalType() == types.ETReal {
+			return errors.Trace(x.Function.vecEvalReal(input, result))
+		}
+	}
+	result.Reset()
+	for row := input.Begin(); row != input.End(); row = row.Next() {
+		val, isNull, err := expr.EvalReal(row, sc)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if isNull {
+			result.AppendNull()
+		} else {
+			result.AppendFloat64(val)
+		}
+	}
+	return nil
+}
+
+// vecEvalExprString evaluates the string results of expr for all the rows in input and stores them in result.
+func vecEvalExprString(sc *stmtctx.StatementContext, expr Expression, input *chunk.Chunk, result *chunk.Column) error {
+	switch x := expr.(type) {
+	case *Column:
+		tp := x.GetType()
+		if tp.EvalType() == types.ETString && !tp.Hybrid() && !(sc.PadCharToFullLength && tp.Tp == mysql.TypeString) {
+			result.CopyFrom(input.Column(x.Index))
+			return nil
+		}
+	case *ScalarFunction:
+		if x.Function.vectorized() && x.Function.getRetTp().EvalType() == types.ETString {
+			return errors.Trace(x.Function.vecEvalString(input, result))
+		}
+	}
+	result.Reset()
+	for row := input.Begin(); row != input.End(); row = row.Next() {
+		val, isNull, err := expr.EvalString(row, sc)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if isNull {
+			result.AppendNull()
+		} else {
+			result.AppendString(val)
+		}
+	}
+	return nil
+}
+
+// vecEvalExprIntSelected is like vecEvalExprInt, but expr is only evaluated on the rows marked in sel,
+// the other rows of result are null. Evaluating expr on the unmarked rows may report the errors or
+// warnings which the row based evaluation doesn't, so the evaluation is only vectorized when all the
+// rows are marked.
+func vecEvalExprIntSelected(sc *stmtctx.StatementContext, expr Expression, input *chunk.Chunk, sel []bool, result *chunk.Column) error {
+	allSelected := true
+	for _, selected := range sel {
+		if !selected {
+			allSelected = false
+			break
+		}
+	}
+	if allSelected {
+		return errors.Trace(vecEvalExprInt(sc, expr, input, result))
+	}
+	result.ResizeInt64(input.NumRows())
+	vals := result.Int64s()
+	for i := range vals {
+		if !sel[i] {
+			result.SetNull(i, true)
+			continue
+		}
+		val, isNull, err := expr.EvalInt(input.GetRow(i), sc)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		vals[i] = val
+		result.SetNull(i, isNull)
+	}
+	return nil
+}
+
+// vecEvalExprRealSelected is like vecEvalExprIntSelected, but expr is evaluated as real.
+func vecEvalExprRealSelected(sc *stmtctx.StatementContext, expr Expression, input *chunk.Chunk, sel []bool, result *chunk.Column) error {
+	allSelected := true
+	for _, selected := range sel {
+		if !selected {
+			allSelected = false
+			break
+		}
+	}
+	if allSelected {
+		return errors.Trace(vecEvalExprReal(sc, expr, input, result))
+	}
+	result.ResizeFloat64(input.NumRows())
+	vals := result.Float64s()
+	for i := range vals {
+		if !sel[i] {
+			result.SetNull(i, true)
+			continue
+		}
+		val, isNull, err := expr.EvalReal(input.GetRow(i), sc)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		vals[i] = val
+		result.SetNull(i, isNull)
+	}
+	return nil
+}
+
+// notNullSel marks the rows of col which are not null.
+func notNullSel(col *chunk.Column, numRows int) []bool {
+	sel := make([]bool, numRows)
+	for i := range sel {
+		sel[i] = !col.IsNull(i)
+	}
+	return sel
+}
+
+// vecEvalIntArgs evaluates the two int arguments of b, result is resized to hold the results of b
+// and the rows where any argument is null are set to null. Like the row based evaluation, the second
+// argument is not evaluated on the rows where the first one is null.
+func vecEvalIntArgs(b *baseBuiltinFunc, input *chunk.Chunk, result *chunk.Column) (arg0, arg1 *chunk.Column, err error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	numRows := input.NumRows()
+	arg0, arg1 = newVecBuffer(types.ETInt, numRows), newVecBuffer(types.ETInt, numRows)
+	if err = vecEvalExprInt(sc, b.args[0], input, arg0); err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	if err = vecEvalExprIntSelected(sc, b.args[1], input, notNullSel(arg0, numRows), arg1); err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	result.ResizeInt64(numRows)
+	result.MergeNulls(arg0, arg1)
+	return arg0, arg1, nil
+}
+
+// vecEvalRealArgs evaluates the two real arguments of b, result is resized to hold the results of
+// b, whose eval type is retTp, and the rows where any argument is null are set to null. The second
+// argument is not evaluated on the rows where the first one is null.
+func vecEvalRealArgs(b *baseBuiltinFunc, input *chunk.Chunk, retTp types.EvalType, result *chunk.Column) (arg0, arg1 *chunk.Column, err error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	numRows := input.NumRows()
+	arg0, arg1 = newVecBuffer(types.ETReal, numRows), newVecBuffer(types.ETReal, numRows)
+	if err = vecEvalExprReal(sc, b.args[0], input, arg0); err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	if err = vecEvalExprRealSelected(sc, b.args[1], input, notNullSel(arg0, numRows), arg1); err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	if retTp == types.ETInt {
+		result.ResizeInt64(numRows)
+	} else {
+		result.ResizeFloat64(numRows)
+	}
+	result.MergeNulls(arg0, arg1)
+	return arg0, arg1, nil
+}
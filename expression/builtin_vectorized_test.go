@@ -0,0 +1,195 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	"fmt"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/testleak"
+	"github.com/pingcap/tidb/util/testutil"
+)
+
+func (s *testEvaluatorSuite) vecTestChunk() ([]*types.FieldType, *chunk.Chunk) {
+	unsignedTp := types.NewFieldType(mysql.TypeLonglong)
+	unsignedTp.Flag |= mysql.UnsignedFlag
+	fields := []*types.FieldType{
+		types.NewFieldType(mysql.TypeLonglong),
+		unsignedTp,
+		types.NewFieldType(mysql.TypeDouble),
+		types.NewFieldType(mysql.TypeVarString),
+	}
+	for _, tp := range fields {
+		tp.Flen, tp.Decimal = mysql.GetDefaultFieldLengthAndDecimal(tp.Tp)
+	}
+	chk := chunk.NewChunk(fields)
+	for i := 0; i < 20; i++ {
+		if i%7 == 3 {
+			chk.AppendNull(0)
+		} else {
+			chk.AppendInt64(0, int64(i%5-2))
+		}
+		chk.AppendUint64(1, uint64(i)<<60)
+		if i%6 == 5 {
+			chk.AppendNull(2)
+		} else {
+			chk.AppendFloat64(2, float64(i)*1.5-10)
+		}
+		if i%4 == 1 {
+			chk.AppendNull(3)
+		} else {
+			chk.AppendString(3, fmt.Sprintf("s%d", i%3))
+		}
+	}
+	return fields, chk
+}
+
+func (s *testEvaluatorSuite) newVecTestFunc(c *C, name string, args ...Expression) Expression {
+	f, err := NewFunction(s.ctx, name, types.NewFieldType(mysql.TypeUnspecified), args...)
+	c.Assert(err, IsNil)
+	return f
+}
+
+func (s *testEvaluatorSuite) TestVectorizedExecute(c *C) {
+	defer testleak.AfterTest(c)()
+	fields, input := s.vecTestChunk()
+	a := &Column{RetType: fields[0], Index: 0}
+	b := &Column{RetType: fields[1], Index: 1}
+	r := &Column{RetType: fields[2], Index: 2}
+	str := &Column{RetType: fields[3], Index: 3}
+	exprs := []Expression{
+		s.newVecTestFunc(c, ast.LT, a, r),
+		s.newVecTestFunc(c, ast.GE, a, b),
+		s.newVecTestFunc(c, ast.NE, b, a),
+		s.newVecTestFunc(c, ast.EQ, str, &Constant{Value: types.NewStringDatum("s1"), RetType: types.NewFieldType(mysql.TypeVarString)}),
+		s.newVecTestFunc(c, ast.GT, str, &Constant{Value: types.NewDatum(nil), RetType: types.NewFieldType(mysql.TypeNull)}),
+		s.newVecTestFunc(c, ast.Plus, a, a),
+		s.newVecTestFunc(c, ast.Minus, a, &Constant{Value: types.NewIntDatum(3), RetType: types.NewFieldType(mysql.TypeLonglong)}),
+		s.newVecTestFunc(c, ast.Mul, a, r),
+		s.newVecTestFunc(c, ast.Div, r, a),
+		s.newVecTestFunc(c, ast.LogicAnd, a, r),
+		s.newVecTestFunc(c, ast.LogicOr, a, s.newVecTestFunc(c, ast.IsNull, str)),
+		s.newVecTestFunc(c, ast.UnaryNot, a),
+		s.newVecTestFunc(c, ast.Length, str),
+		s.newVecTestFunc(c, ast.Concat, str, a, str),
+		BuildCastFunction(s.ctx, r, types.NewFieldType(mysql.TypeLonglong)),
+		BuildCastFunction(s.ctx, b, types.NewFieldType(mysql.TypeDouble)),
+	}
+	sc := s.ctx.GetSessionVars().StmtCtx
+	for _, expr := range exprs {
+		c.Assert(isVecExpr(expr), IsTrue, Commentf("%s", expr))
+		tps := []*types.FieldType{expr.GetType()}
+		vecResult, rowResult := chunk.NewChunk(tps), chunk.NewChunk(tps)
+
+		sc.SetWarnings(nil)
+		c.Assert(VectorizedExecute(s.ctx, []Expression{expr}, input, vecResult), IsNil)
+		vecWarnings := sc.WarningCount()
+		sc.SetWarnings(nil)
+		c.Assert(UnVectorizedExecute(s.ctx, []Expression{expr}, input, rowResult), IsNil)
+		c.Assert(vecWarnings, Equals, sc.WarningCount())
+
+		c.Assert(vecResult.NumRows(), Equals, input.NumRows())
+		for i := 0; i < input.NumRows(); i++ {
+			vecDatum := vecResult.GetRow(i).GetDatum(0, expr.GetType())
+			rowDatum := rowResult.GetRow(i).GetDatum(0, expr.GetType())
+			c.Assert(vecDatum, testutil.DatumEquals, rowDatum, Commentf("%s at row %d", expr, i))
+		}
+	}
+	sc.SetWarnings(nil)
+
+	// The overflow error is reported like the row based evaluation.
+	overflow := s.newVecTestFunc(c, ast.Plus, b, b)
+	err := VectorizedExecute(s.ctx, []Expression{overflow}, input, chunk.NewChunk([]*types.FieldType{overflow.GetType()}))
+	c.Assert(types.ErrOverflow.Equal(err), IsTrue)
+}
+
+func (s *testEvaluatorSuite) TestVectorizedFilter(c *C) {
+	defer testleak.AfterTest(c)()
+	fields, input := s.vecTestChunk()
+	a := &Column{RetType: fields[0], Index: 0}
+	r := &Column{RetType: fields[2], Index: 2}
+	zero := &Constant{Value: types.NewIntDatum(0), RetType: types.NewFieldType(mysql.TypeLonglong)}
+	filters := []Expression{
+		s.newVecTestFunc(c, ast.NE, a, zero),
+		s.newVecTestFunc(c, ast.GT, s.newVecTestFunc(c, ast.Div, r, a), zero),
+	}
+	sc := s.ctx.GetSessionVars().StmtCtx
+	sc.SetWarnings(nil)
+	selected, err := VectorizedFilter(s.ctx, filters, input, nil)
+	c.Assert(err, IsNil)
+	// The division is not evaluated on the rows filtered out by "a != 0".
+	c.Assert(sc.WarningCount(), Equals, uint16(0))
+	for i := 0; i < input.NumRows(); i++ {
+		expected, err := EvalBool(filters, input.GetRow(i), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(selected[i], Equals, expected, Commentf("row %d", i))
+	}
+
+	// Neither is the right side of AND when the left side is false.
+	filter := s.newVecTestFunc(c, ast.LogicAnd, filters[0], filters[1])
+	selected, err = VectorizedFilter(s.ctx, []Expression{filter}, input, selected)
+	c.Assert(err, IsNil)
+	c.Assert(sc.WarningCount(), Equals, uint16(0))
+	for i := 0; i < input.NumRows(); i++ {
+		expected, err := EvalBool(filters, input.GetRow(i), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(selected[i], Equals, expected, Commentf("row %d", i))
+	}
+}
+
+func (s *testEvaluatorSuite) TestVectorizedNullFirstArg(c *C) {
+	defer testleak.AfterTest(c)()
+	unsignedTp := types.NewFieldType(mysql.TypeLonglong)
+	unsignedTp.Flag |= mysql.UnsignedFlag
+	fields := []*types.FieldType{types.NewFieldType(mysql.TypeLonglong), unsignedTp, types.NewFieldType(mysql.TypeDouble)}
+	input := chunk.NewChunk(fields)
+	input.AppendNull(0)
+	input.AppendUint64(1, 1<<63)
+	input.AppendNull(2)
+	input.AppendInt64(0, 1)
+	input.AppendUint64(1, 1)
+	input.AppendFloat64(2, 1)
+	a := &Column{RetType: fields[0], Index: 0}
+	b := &Column{RetType: fields[1], Index: 1}
+	r := &Column{RetType: fields[2], Index: 2}
+	zero := &Constant{Value: types.NewFloat64Datum(0), RetType: types.NewFieldType(mysql.TypeDouble)}
+	// The second arguments are not evaluated on the rows where the first ones are null, so neither
+	// the overflow of "b + b" nor the division by zero of "r / 0" is reported on the first row.
+	exprs := []Expression{
+		s.newVecTestFunc(c, ast.Plus, a, s.newVecTestFunc(c, ast.Plus, b, b)),
+		s.newVecTestFunc(c, ast.LT, r, s.newVecTestFunc(c, ast.Div, r, zero)),
+	}
+	sc := s.ctx.GetSessionVars().StmtCtx
+	for _, expr := range exprs {
+		tps := []*types.FieldType{expr.GetType()}
+		vecResult, rowResult := chunk.NewChunk(tps), chunk.NewChunk(tps)
+
+		sc.SetWarnings(nil)
+		c.Assert(VectorizedExecute(s.ctx, []Expression{expr}, input, vecResult), IsNil, Commentf("%s", expr))
+		vecWarnings := sc.WarningCount()
+		sc.SetWarnings(nil)
+		c.Assert(UnVectorizedExecute(s.ctx, []Expression{expr}, input, rowResult), IsNil)
+		c.Assert(vecWarnings, Equals, sc.WarningCount(), Commentf("%s", expr))
+		for i := 0; i < input.NumRows(); i++ {
+			vecDatum := vecResult.GetRow(i).GetDatum(0, expr.GetType())
+			rowDatum := rowResult.GetRow(i).GetDatum(0, expr.GetType())
+			c.Assert(vecDatum, testutil.DatumEquals, rowDatum, Commentf("%s at row %d", expr, i))
+		}
+	}
+	sc.SetWarnings(nil)
+}
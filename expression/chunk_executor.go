@@ -65,6 +65,9 @@ func VectorizedExecute(ctx context.Context, exprs []Expression, input, output *c
 }
 
 func evalOneColumn(sc *stmtctx.StatementContext, expr Expression, input, output *chunk.Chunk, colID int) (err error) {
+	if isVecExpr(expr) && canAppendVecResult(expr.GetType()) {
+		return errors.Trace(vecEvalOneColumn(sc, expr, input, output, colID))
+	}
 	switch fieldType, evalType := expr.GetType(), expr.GetType().EvalType(); evalType {
 	case types.ETInt:
 		for row := input.Begin(); err == nil && row != input.End(); row = row.Next() {
@@ -98,6 +101,39 @@ func evalOneColumn(sc *stmtctx.StatementContext, expr Expression, input, output
 	return errors.Trace(err)
 }
 
+// canAppendVecResult checks whether the results of the vectorized evaluation can be appended to the
+// column of fieldType directly, i.e. the column stores the results in the same layout.
+func canAppendVecResult(fieldType *types.FieldType) bool {
+	switch fieldType.EvalType() {
+	case types.ETInt:
+		return fieldType.Tp != mysql.TypeBit
+	case types.ETReal:
+		return fieldType.Tp != mysql.TypeFloat
+	case types.ETString:
+		return fieldType.Tp != mysql.TypeEnum && fieldType.Tp != mysql.TypeSet
+	}
+	return false
+}
+
+// vecEvalOneColumn evaluates expr column by column and appends the results to the colID-th column of output.
+func vecEvalOneColumn(sc *stmtctx.StatementContext, expr Expression, input, output *chunk.Chunk, colID int) (err error) {
+	evalType := expr.GetType().EvalType()
+	buf := newVecBuffer(evalType, input.NumRows())
+	switch evalType {
+	case types.ETInt:
+		err = vecEvalExprInt(sc, expr, input, buf)
+	case types.ETReal:
+		err = vecEvalExprReal(sc, expr, input, buf)
+	case types.ETString:
+		err = vecEvalExprString(sc, expr, input, buf)
+	}
+	if err != nil {
+		return errors.Trace(err)
+	}
+	output.Column(colID).AppendColumn(buf)
+	return nil
+}
+
 // UnVectorizedExecute evaluates a list of expressions row by row and append their results to "output" Chunk.
 func UnVectorizedExecute(ctx context.Context, exprs []Expression, input, output *chunk.Chunk) error {
 	sc := ctx.GetSessionVars().StmtCtx
@@ -242,7 +278,22 @@ func VectorizedFilter(ctx context.Context, filters []Expression, input *chunk.Ch
 	for i, numRows := 0, input.NumRows(); i < numRows; i++ {
 		selected = append(selected, true)
 	}
+	var buf *chunk.Column
 	for _, filter := range filters {
+		if filter.GetType().EvalType() == types.ETInt && isVecExpr(filter) {
+			if buf == nil {
+				buf = newVecBuffer(types.ETInt, input.NumRows())
+			}
+			// The rows filtered out by the former filters are not evaluated again.
+			err := vecEvalExprIntSelected(ctx.GetSessionVars().StmtCtx, filter, input, selected, buf)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			for i, val := range buf.Int64s() {
+				selected[i] = selected[i] && !buf.IsNull(i) && val != 0
+			}
+			continue
+		}
 		isIntType := true
 		if filter.GetType().EvalType() != types.ETInt {
 			isIntType = false
@@ -0,0 +1,218 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	"strings"
+
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/charset"
+	"github.com/pingcap/tidb/util/collate"
+)
+
+// Coercibility is the coercibility of the collation of an expression. When the strings of different
+// collations are compared, the collation of the expression with the lowest coercibility is used.
+// See https://dev.mysql.com/doc/refman/5.7/en/charset-collation-coercibility.html
+type Coercibility int
+
+const (
+	// CoercibilityExplicit is derived from an explicit COLLATE clause.
+	CoercibilityExplicit Coercibility = 0
+	// CoercibilityNone is derived from the concatenation of the strings with different collations.
+	CoercibilityNone Coercibility = 1
+	// CoercibilityImplicit is derived from a column.
+	CoercibilityImplicit Coercibility = 2
+	// CoercibilitySysconst is derived from a system constant.
+	CoercibilitySysconst Coercibility = 3
+	// CoercibilityCoercible is derived from a literal.
+	CoercibilityCoercible Coercibility = 4
+	// CoercibilityNumeric is derived from a numeric or temporal value.
+	CoercibilityNumeric Coercibility = 5
+	// CoercibilityIgnorable is derived from NULL.
+	CoercibilityIgnorable Coercibility = 6
+)
+
+// collationInfo holds the coercibility which is set explicitly, like by the COLLATE clause.
+type collationInfo struct {
+	coer    Coercibility
+	coerSet bool
+}
+
+// SetCoercibility sets the coercibility of the expression.
+func (c *collationInfo) SetCoercibility(coer Coercibility) {
+	c.coer, c.coerSet = coer, true
+}
+
+// Coercibility returns the coercibility of the collation of the expression.
+func (col *Column) Coercibility() Coercibility {
+	if col.coerSet {
+		return col.coer
+	}
+	if col.GetType().EvalType() == types.ETString {
+		return CoercibilityImplicit
+	}
+	return CoercibilityNumeric
+}
+
+// Coercibility returns the coercibility of the collation of the expression.
+func (c *Constant) Coercibility() Coercibility {
+	if c.coerSet {
+		return c.coer
+	}
+	if c.DeferredExpr == nil && c.Value.IsNull() || c.GetType().Tp == mysql.TypeNull {
+		return CoercibilityIgnorable
+	}
+	if c.GetType().EvalType() == types.ETString {
+		return CoercibilityCoercible
+	}
+	return CoercibilityNumeric
+}
+
+// Coercibility returns the coercibility of the collation of the expression.
+func (sf *ScalarFunction) Coercibility() Coercibility {
+	if sf.coerSet {
+		return sf.coer
+	}
+	if sf.GetType().EvalType() != types.ETString {
+		return CoercibilityNumeric
+	}
+	_, _, coer, conflict := deriveCollation(sf.GetArgs())
+	if conflict {
+		return CoercibilityNone
+	}
+	return coer
+}
+
+// deriveCollation derives the collation which the strings of the expressions are compared by.
+// conflict is true if the collations of the strings with the same coercibility are different.
+func deriveCollation(exprs []Expression) (chs, coll string, coer Coercibility, conflict bool) {
+	found := false
+	coer = CoercibilityCoercible
+	for _, expr := range exprs {
+		tp := expr.GetType()
+		if tp.EvalType() != types.ETString {
+			continue
+		}
+		c := expr.Coercibility()
+		switch {
+		case !found || c < coer:
+			chs, coll, coer, conflict, found = tp.Charset, tp.Collate, c, false, true
+		case c > coer || tp.Collate == "" || collate.GetCollator(tp.Collate) == collate.GetCollator(coll):
+		case tp.Collate == charset.CollationBin:
+			// The binary collation wins, like MySQL does.
+			chs, coll, conflict = tp.Charset, tp.Collate, false
+		case coll != charset.CollationBin:
+			conflict = true
+		}
+	}
+	if !found || coll == "" {
+		chs, coll = charset.CharsetUTF8, charset.CollationUTF8
+	}
+	return
+}
+
+// DeriveCollationFromExprs returns the collation which the strings of the expressions are compared by.
+func DeriveCollationFromExprs(exprs []Expression) string {
+	_, coll, _, _ := deriveCollation(exprs)
+	return coll
+}
+
+// checkIllegalMixCollation checks the strings of the arguments of the function can be compared,
+// the collation to compare them is returned.
+func checkIllegalMixCollation(funcName string, args []Expression) (string, error) {
+	_, coll, coer, conflict := deriveCollation(args)
+	if !collate.NewCollationEnabled() || !conflict && coer != CoercibilityNone {
+		return coll, nil
+	}
+	var colls []string
+	for _, arg := range args {
+		if arg.GetType().EvalType() == types.ETString && len(colls) < 4 {
+			colls = append(colls, arg.GetType().Collate, coercibilityName(arg.Coercibility()))
+		}
+	}
+	for len(colls) < 4 {
+		colls = append(colls, "")
+	}
+	return "", errIllegalMixCollation.GenByArgs(colls[0], colls[1], colls[2], colls[3], funcName)
+}
+
+func coercibilityName(coer Coercibility) string {
+	switch coer {
+	case CoercibilityExplicit:
+		return "EXPLICIT"
+	case CoercibilityNone:
+		return "NONE"
+	case CoercibilityImplicit:
+		return "IMPLICIT"
+	case CoercibilitySysconst:
+		return "SYSCONST"
+	case CoercibilityCoercible:
+		return "COERCIBLE"
+	case CoercibilityNumeric:
+		return "NUMERIC"
+	}
+	return "IGNORABLE"
+}
+
+// compareStringWithCollation compares the strings by the collation.
+func compareStringWithCollation(a, b, coll string) int {
+	return collate.GetCollator(coll).Compare(a, b)
+}
+
+// IsCollationKeyNeeded returns whether the values of the type must be converted to the sort keys of
+// their collation before they are hashed or encoded, so the equal strings get the same keys.
+func IsCollationKeyNeeded(tp *types.FieldType) bool {
+	return tp.EvalType() == types.ETString && !collate.IsBinCollation(tp.Collate)
+}
+
+// IsPaddingCollationKey returns whether the values of the type are converted to the sort keys of a
+// padding _bin collation, which keep the order of the strings and only drop their trailing spaces.
+func IsPaddingCollationKey(tp *types.FieldType) bool {
+	return tp.EvalType() == types.ETString && collate.IsPaddingBinCollation(tp.Collate)
+}
+
+// CollationKeyDatum converts the string datum to the sort key of the collation of tp,
+// other datums are returned as they are.
+func CollationKeyDatum(d types.Datum, tp *types.FieldType) types.Datum {
+	switch d.Kind() {
+	case types.KindString, types.KindBytes:
+		if IsCollationKeyNeeded(tp) {
+			d.SetBytes(collate.GetCollator(tp.Collate).Key(d.GetString()))
+		}
+	}
+	return d
+}
+
+// SetCollationExpr returns the copy of the string expression with the explicit collation.
+func SetCollationExpr(expr Expression, chs, coll string) Expression {
+	expr = expr.Clone()
+	tp := *expr.GetType()
+	tp.Charset, tp.Collate = chs, strings.ToLower(coll)
+	switch x := expr.(type) {
+	case *Column:
+		x.RetType = &tp
+		x.SetCoercibility(CoercibilityExplicit)
+	case *CorrelatedColumn:
+		x.RetType = &tp
+		x.SetCoercibility(CoercibilityExplicit)
+	case *Constant:
+		x.RetType = &tp
+		x.SetCoercibility(CoercibilityExplicit)
+	case *ScalarFunction:
+		x.RetType = &tp
+		x.SetCoercibility(CoercibilityExplicit)
+	}
+	return expr
+}
@@ -160,11 +160,15 @@ type Column struct {
 	// IsAggOrSubq means if this column is referenced to a Aggregation column or a Subquery column.
 	// If so, this column's name will be the plain sql text.
 	IsAggOrSubq bool
+	// IsHidden means the column is the hidden column of an expression index, it isn't expanded by the wildcard.
+	IsHidden bool
 
 	// Index is only used for execution.
 	Index int
 
 	hashcode []byte
+
+	collationInfo
 }
 
 // Equal implements Expression interface.
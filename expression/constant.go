@@ -53,6 +53,8 @@ type Constant struct {
 	Value        types.Datum
 	RetType      *types.FieldType
 	DeferredExpr Expression // parameter getter expression
+
+	collationInfo
 }
 
 // String implements fmt.Stringer interface.
@@ -29,6 +29,10 @@ func foldConstant(expr Expression) (Expression, bool) {
 		if _, ok := unFoldableFunctions[x.FuncName.L]; ok {
 			return expr, false
 		}
+		switch x.Function.(type) {
+		case *builtinUDFSig, *builtinStoredFuncSig:
+			return expr, false
+		}
 		args := x.GetArgs()
 		canFold := true
 		isDeferredConst := false
@@ -24,12 +24,24 @@ import (
 var (
 	ErrIncorrectParameterCount = terror.ClassExpression.New(mysql.ErrWrongParamcountToNativeFct, mysql.MySQLErrName[mysql.ErrWrongParamcountToNativeFct])
 	ErrDivisionByZero          = terror.ClassExpression.New(mysql.ErrDivisionByZero, mysql.MySQLErrName[mysql.ErrDivisionByZero])
+	// ErrUnknownCollation is returned when the collation of the COLLATE clause doesn't exist.
+	ErrUnknownCollation = terror.ClassExpression.New(mysql.ErrUnknownCollation, mysql.MySQLErrName[mysql.ErrUnknownCollation])
+	// ErrCollationCharsetMismatch is returned when the collation of the COLLATE clause doesn't belong to the charset of the expression.
+	ErrCollationCharsetMismatch = terror.ClassExpression.New(mysql.ErrCollationCharsetMismatch, mysql.MySQLErrName[mysql.ErrCollationCharsetMismatch])
 
 	errFunctionNotExists   = terror.ClassExpression.New(mysql.ErrSpDoesNotExist, mysql.MySQLErrName[mysql.ErrSpDoesNotExist])
+	errWrongNoOfArgs       = terror.ClassExpression.New(mysql.ErrSpWrongNoOfArgs, "Incorrect number of arguments for %s %s; expected %d, got %d")
 	errZlibZData           = terror.ClassTypes.New(mysql.ErrZlibZData, mysql.MySQLErrName[mysql.ErrZlibZData])
 	errIncorrectArgs       = terror.ClassExpression.New(mysql.ErrWrongArguments, mysql.MySQLErrName[mysql.ErrWrongArguments])
 	errUnknownCharacterSet = terror.ClassExpression.New(mysql.ErrUnknownCharacterSet, mysql.MySQLErrName[mysql.ErrUnknownCharacterSet])
 	errDefaultValue        = terror.ClassExpression.New(mysql.ErrInvalidDefault, "invalid default value")
+	errIllegalMixCollation = terror.ClassExpression.New(mysql.ErrCantAggregate2collations, mysql.MySQLErrName[mysql.ErrCantAggregate2collations])
+	errGISDifferentSRIDs   = terror.ClassExpression.New(mysql.ErrGISDifferentSRIDs, mysql.MySQLErrName[mysql.ErrGISDifferentSRIDs])
+	errGISInvalidData      = terror.ClassExpression.New(mysql.ErrGISInvalidData, mysql.MySQLErrName[mysql.ErrGISInvalidData])
+
+	errRegexp                 = terror.ClassExpression.New(mysql.ErrRegexp, mysql.MySQLErrName[mysql.ErrRegexp])
+	errRegexpIllegalArgument  = terror.ClassExpression.New(mysql.ErrRegexpIllegalArgument, mysql.MySQLErrName[mysql.ErrRegexpIllegalArgument])
+	errRegexpIndexOutOfBounds = terror.ClassExpression.New(mysql.ErrRegexpIndexOutOfBounds, mysql.MySQLErrName[mysql.ErrRegexpIndexOutOfBounds])
 )
 
 func init() {
@@ -37,10 +49,19 @@ func init() {
 		mysql.ErrWrongParamcountToNativeFct: mysql.ErrWrongParamcountToNativeFct,
 		mysql.ErrDivisionByZero:             mysql.ErrDivisionByZero,
 		mysql.ErrSpDoesNotExist:             mysql.ErrSpDoesNotExist,
+		mysql.ErrSpWrongNoOfArgs:            mysql.ErrSpWrongNoOfArgs,
 		mysql.ErrZlibZData:                  mysql.ErrZlibZData,
 		mysql.ErrWrongArguments:             mysql.ErrWrongArguments,
 		mysql.ErrUnknownCharacterSet:        mysql.ErrUnknownCharacterSet,
 		mysql.ErrInvalidDefault:             mysql.ErrInvalidDefault,
+		mysql.ErrUnknownCollation:           mysql.ErrUnknownCollation,
+		mysql.ErrCollationCharsetMismatch:   mysql.ErrCollationCharsetMismatch,
+		mysql.ErrCantAggregate2collations:   mysql.ErrCantAggregate2collations,
+		mysql.ErrGISDifferentSRIDs:          mysql.ErrGISDifferentSRIDs,
+		mysql.ErrGISInvalidData:             mysql.ErrGISInvalidData,
+		mysql.ErrRegexp:                     mysql.ErrRegexp,
+		mysql.ErrRegexpIllegalArgument:      mysql.ErrRegexpIllegalArgument,
+		mysql.ErrRegexpIndexOutOfBounds:     mysql.ErrRegexpIndexOutOfBounds,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassExpression] = expressionMySQLErrCodes
 }
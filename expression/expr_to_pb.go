@@ -24,6 +24,8 @@ import (
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/collate"
+	"github.com/pingcap/tidb/util/timeutil"
 	"github.com/pingcap/tipb/go-tipb"
 	log "github.com/sirupsen/logrus"
 )
@@ -177,6 +179,12 @@ func (pc PbConverter) columnToPBExpr(column *Column) *tipb.Expr {
 	switch column.GetType().Tp {
 	case mysql.TypeBit, mysql.TypeSet, mysql.TypeEnum, mysql.TypeGeometry, mysql.TypeUnspecified:
 		return nil
+	case mysql.TypeTimestamp:
+		// The storage converts the timestamps by a single offset of the time zone, the timestamps
+		// can only be converted correctly if the offset never changes.
+		if pc.sc.TimeZone != nil && !timeutil.IsFixedZone(pc.sc.TimeZone) {
+			return nil
+		}
 	}
 
 	if pc.client.IsRequestTypeSupported(kv.ReqTypeDAG, kv.ReqSubTypeBasic) {
@@ -198,12 +206,21 @@ func (pc PbConverter) columnToPBExpr(column *Column) *tipb.Expr {
 }
 
 func (pc PbConverter) scalarFuncToPBExpr(expr *ScalarFunction) *tipb.Expr {
+	switch expr.FuncName.L {
+	case ast.LT, ast.LE, ast.EQ, ast.NE, ast.GE, ast.GT, ast.NullEQ, ast.In:
+		// The storage compares the strings by their bytes, the comparisons of other collations can't be pushed down.
+		if hasStringArg(expr.GetArgs()) && !collate.IsBinCollation(DeriveCollationFromExprs(expr.GetArgs())) {
+			return nil
+		}
+	}
 	switch expr.FuncName.L {
 	case ast.LT, ast.LE, ast.EQ, ast.NE, ast.GE, ast.GT,
 		ast.NullEQ:
 		return pc.compareOpsToPBExpr(expr)
 	case ast.Like:
 		return pc.likeToPBExpr(expr)
+	// The REGEXP operator and the REGEXP_* functions are evaluated by TiDB, they can't be pushed
+	// down until tipb has signatures for them.
 	case ast.Plus, ast.Minus, ast.Mul, ast.Div:
 		return pc.arithmeticalOpsToPBExpr(expr)
 	case ast.LogicAnd, ast.LogicOr, ast.UnaryNot, ast.LogicXor:
@@ -223,6 +240,15 @@ func (pc PbConverter) scalarFuncToPBExpr(expr *ScalarFunction) *tipb.Expr {
 	}
 }
 
+func hasStringArg(args []Expression) bool {
+	for _, arg := range args {
+		if arg.GetType().EvalType() == types.ETString {
+			return true
+		}
+	}
+	return false
+}
+
 func (pc PbConverter) compareOpsToPBExpr(expr *ScalarFunction) *tipb.Expr {
 	var tp tipb.ExprType
 	switch expr.FuncName.L {
@@ -24,6 +24,7 @@ import (
 	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/mock"
+	"github.com/pingcap/tidb/util/timeutil"
 	"github.com/pingcap/tipb/go-tipb"
 	goctx "golang.org/x/net/context"
 )
@@ -280,6 +281,27 @@ func (s *testEvaluatorSuite) TestColumn2Pb(c *C) {
 	}
 }
 
+func (s *testEvaluatorSuite) TestTimestampColumn2Pb(c *C) {
+	sc := new(stmtctx.StatementContext)
+	client := new(mockKvClient)
+	dg := new(dataGen4Expr2PbTest)
+	colExprs := []Expression{dg.genColumn(mysql.TypeTimestamp, 1)}
+
+	// The timestamps are only pushed down in the time zones whose offsets never change.
+	for _, tz := range []string{"UTC", "+08:00", "Etc/GMT-3"} {
+		loc, err := timeutil.ParseTimeZone(tz)
+		c.Assert(err, IsNil)
+		sc.TimeZone = loc
+		c.Assert(ExpressionsToPBList(sc, colExprs, client)[0], NotNil, Commentf("%s", tz))
+	}
+	for _, tz := range []string{"America/New_York", "Asia/Shanghai"} {
+		loc, err := timeutil.ParseTimeZone(tz)
+		c.Assert(err, IsNil)
+		sc.TimeZone = loc
+		c.Assert(ExpressionsToPBList(sc, colExprs, client)[0], IsNil, Commentf("%s", tz))
+	}
+}
+
 func (s *testEvaluatorSuite) TestCompareFunc2Pb(c *C) {
 	var compareExprs []Expression
 	sc := new(stmtctx.StatementContext)
@@ -407,6 +429,26 @@ func (s *testEvaluatorSuite) TestDateFunc2Pb(c *C) {
 	c.Assert(string(js), Equals, "{\"tp\":6001,\"children\":[{\"tp\":201,\"val\":\"gAAAAAAAAAE=\",\"sig\":0},{\"tp\":201,\"val\":\"gAAAAAAAAAI=\",\"sig\":0}],\"sig\":0}")
 }
 
+func (s *testEvaluatorSuite) TestRegexpFunc2Pb(c *C) {
+	sc := new(stmtctx.StatementContext)
+	client := new(mockKvClient)
+	dg := new(dataGen4Expr2PbTest)
+	ctx := mock.NewContext()
+	fc, err := NewFunction(ctx, ast.Regexp, types.NewFieldType(mysql.TypeUnspecified),
+		dg.genColumn(mysql.TypeString, 1), dg.genColumn(mysql.TypeString, 2))
+	c.Assert(err, IsNil)
+	regexpFuncs := []Expression{fc}
+	fc, err = NewFunction(ctx, ast.RegexpLike, types.NewFieldType(mysql.TypeUnspecified),
+		dg.genColumn(mysql.TypeString, 1), dg.genColumn(mysql.TypeString, 2))
+	c.Assert(err, IsNil)
+	regexpFuncs = append(regexpFuncs, fc)
+
+	// The regexp functions aren't pushed down until the coprocessor supports them.
+	pbExprs := ExpressionsToPBList(sc, regexpFuncs, client)
+	c.Assert(pbExprs[0], IsNil)
+	c.Assert(pbExprs[1], IsNil)
+}
+
 func (s *testEvaluatorSuite) TestLogicalFunc2Pb(c *C) {
 	var logicalFuncs []Expression
 	sc := new(stmtctx.StatementContext)
@@ -31,6 +31,9 @@ import (
 // EvalAstExpr evaluates ast expression directly.
 var EvalAstExpr func(expr ast.ExprNode, ctx context.Context) (types.Datum, error)
 
+// RewriteAstExpr rewrites ast expression directly, the columns in it are resolved by the schema.
+var RewriteAstExpr func(ctx context.Context, expr ast.ExprNode, schema *Schema) (Expression, error)
+
 // Expression represents all scalar expression in SQL.
 type Expression interface {
 	fmt.Stringer
@@ -63,6 +66,9 @@ type Expression interface {
 	// GetType gets the type that the expression returns.
 	GetType() *types.FieldType
 
+	// Coercibility returns the coercibility of the collation of the expression.
+	Coercibility() Coercibility
+
 	// Clone copies an expression totally.
 	Clone() Expression
 
@@ -3052,6 +3052,139 @@ func (s *testIntegrationSuite) TestFuncJSON(c *C) {
 	tk.MustExec(`update table_json set a=json_set(a,'$.a',json_object('a',1,'b',2)) where json_extract(a,'$.a[1]') = '2'`)
 	r = tk.MustQuery(`select json_extract(a, '$.a.a'), json_extract(a, '$.a.b') from table_json`)
 	r.Check(testkit.Rows("1 2", "<nil> <nil>"))
+
+	r = tk.MustQuery(`select a->'$.a.a', a->>'$.c[0]', b->>'$[3]' from table_json`)
+	r.Check(testkit.Rows("1 d <nil>", "<nil> <nil> hello, world"))
+
+	r = tk.MustQuery(`select json_keys(a), json_keys(a, '$.a'), json_length(a), json_length(a, '$.c'), json_depth(a) from table_json`)
+	r.Check(testkit.Rows(`["\"hello\"","a","b","c"] ["a","b"] 4 1 3`, "<nil> <nil> 6 <nil> 3"))
+
+	r = tk.MustQuery(`select json_contains_path(b, 'one', '$[0].a', '$.x'), json_contains_path(b, 'all', '$[0].a', '$[9]') from table_json`)
+	r.Check(testkit.Rows("1 0", "1 0"))
+
+	r = tk.MustQuery(`select json_search(a, 'one', 'd'), json_search(b, 'all', 'hello%') from table_json`)
+	r.Check(testkit.Rows(`"$.c[0]" <nil>`, `<nil> "$[3]"`))
+
+	r = tk.MustQuery(`select json_array_append('[1, [2]]', '$[1]', 3), json_array_insert('[1, [2]]', '$[0]', 'x')`)
+	r.Check(testkit.Rows(`[1,[2,3]] ["x",1,[2]]`))
+
+	r = tk.MustQuery(`select json_merge_patch('{"a": 1, "b": 2}', '{"b": null, "c": 3}'), json_merge_preserve('{"a": 1}', '{"a": 2}')`)
+	r.Check(testkit.Rows(`{"a":1,"c":3} {"a":[1,2]}`))
+
+	r = tk.MustQuery(`select json_quote('a"b'), json_quote(null), json_pretty('[1, {"a": 2}]'), json_storage_size('true')`)
+	r.Check(testkit.Rows("\"a\\\"b\" <nil> [\n  1,\n  {\n    \"a\": 2\n  }\n] 2"))
+
+	goCtx := goctx.Background()
+	for _, sql := range []string{
+		`select json_keys(a, '$.*') from table_json`,
+		`select json_contains_path(a, 'some', '$.a') from table_json`,
+	} {
+		rs, err := tk.Exec(sql)
+		c.Assert(err, IsNil)
+		_, err = tidb.GetRows4Test(goCtx, rs)
+		c.Assert(err, NotNil, Commentf("sql: %s", sql))
+		c.Assert(rs.Close(), IsNil)
+	}
+}
+
+func (s *testIntegrationSuite) TestFuncSpatial(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	defer s.cleanEnv(c)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t(id int primary key, p point, g geometry)")
+	tk.MustQuery("show create table t").Check(testkit.Rows("t CREATE TABLE `t` (\n" +
+		"  `id` int(11) NOT NULL,\n" +
+		"  `p` point DEFAULT NULL,\n" +
+		"  `g` geometry DEFAULT NULL,\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin"))
+	tk.MustExec(`insert into t values
+		(1, st_geomfromtext('point(1 1)'), st_geomfromtext('polygon((0 0,0 4,4 4,4 0,0 0))')),
+		(2, point(3, 3), st_geomfromtext('polygon((0 0,0 4,4 0,0 0))')),
+		(3, st_geomfromtext('point(5 5)'), st_geomfromtext('linestring(0 0,1 1)')),
+		(4, null, null)`)
+
+	tk.MustQuery("select id, st_astext(p), st_astext(g), st_x(p), st_y(p), st_srid(p) from t order by id").Check(testkit.Rows(
+		"1 POINT(1 1) POLYGON((0 0,0 4,4 4,4 0,0 0)) 1 1 0",
+		"2 POINT(3 3) POLYGON((0 0,0 4,4 0,0 0)) 3 3 0",
+		"3 POINT(5 5) LINESTRING(0 0,1 1) 5 5 0",
+		"4 <nil> <nil> <nil> <nil> <nil>"))
+	tk.MustQuery("select id from t where st_contains(g, p) order by id").Check(testkit.Rows("1"))
+	tk.MustQuery("select id from t where st_within(p, g) order by id").Check(testkit.Rows("1"))
+	tk.MustQuery("select id from t where mbrcontains(g, p) order by id").Check(testkit.Rows("1", "2"))
+	tk.MustQuery("select id, st_distance(p, g) from t where id = 3").Check(testkit.Rows("3 5.656854249492381"))
+	tk.MustQuery("select st_astext(st_geomfromwkb(st_asbinary(p))) from t where id = 2").Check(testkit.Rows("POINT(3 3)"))
+
+	// Only points can be stored in a POINT column.
+	_, err := tk.Exec("insert into t values (5, st_geomfromtext('linestring(0 0,1 1)'), null)")
+	c.Assert(terror.ErrorEqual(err, types.ErrCantCreateGeometryObject), IsTrue, Commentf("err %v", err))
+	_, err = tk.Exec("insert into t values (5, 'abc', null)")
+	c.Assert(terror.ErrorEqual(err, types.ErrCantCreateGeometryObject), IsTrue, Commentf("err %v", err))
+
+	// Check the DDL limits of the spatial columns.
+	_, err = tk.Exec("create table t1(p point default 'abc')")
+	c.Assert(errors.Cause(err).(*terror.Error).Code(), Equals, terror.ErrCode(mysql.ErrBlobCantHaveDefault))
+	_, err = tk.Exec("create table t1(p point, key(p))")
+	c.Assert(errors.Cause(err).(*terror.Error).Code(), Equals, terror.ErrCode(mysql.ErrBlobKeyWithoutLength))
+	_, err = tk.Exec("alter table t modify g polygon")
+	c.Assert(err, NotNil)
+	tk.MustExec("alter table t modify p geometry")
+	tk.MustExec("insert into t values (5, st_geomfromtext('linestring(0 0,1 1)'), null)")
+	tk.MustQuery("select st_astext(p) from t where id = 5").Check(testkit.Rows("LINESTRING(0 0,1 1)"))
+
+	goCtx := goctx.Background()
+	for _, sql := range []string{
+		`select st_astext(st_geomfromtext('point(1)'))`,
+		`select st_contains(st_geomfromtext('point(1 1)', 4326), st_geomfromtext('point(1 1)'))`,
+		`select st_x(g) from t where id = 1`,
+	} {
+		rs, err := tk.Exec(sql)
+		c.Assert(err, IsNil)
+		_, err = tidb.GetRows4Test(goCtx, rs)
+		c.Assert(err, NotNil, Commentf("sql: %s", sql))
+		c.Assert(rs.Close(), IsNil)
+	}
+}
+
+func (s *testIntegrationSuite) TestFuncRegexp(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	defer s.cleanEnv(c)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t(id int primary key, a varchar(50), b varbinary(50))")
+	tk.MustExec("insert into t values (1, 'abc@example.com', 'abc@example.com'), (2, 'Bob@Example.COM', 'Bob@Example.COM'), (3, 'not an email', null)")
+
+	tk.MustQuery("select regexp_like('Michael!', '.*'), regexp_like('a', 'A'), regexp_like('a', 'A', 'c'), regexp_like('a\nb', 'a$', 'm')").Check(testkit.Rows("1 1 0 1"))
+	tk.MustQuery("select regexp_instr('dog cat dog', 'dog', 2), regexp_instr('aa aaa aaaa', 'a{2}', 1, 3, 1)").Check(testkit.Rows("9 10"))
+	tk.MustQuery("select regexp_substr('abc def ghi', '[a-z]+', 1, 3), regexp_substr('abc', 'x')").Check(testkit.Rows("ghi <nil>"))
+	tk.MustQuery("select regexp_replace('a b c', 'b', 'X'), regexp_replace('abc def ghi', '[a-z]+', 'X', 1, 3)").Check(testkit.Rows("a X c abc def X"))
+
+	tk.MustQuery("select id from t where regexp_like(a, '^[a-z]+@example\\.com$') order by id").Check(testkit.Rows("1", "2"))
+	tk.MustQuery("select id from t where regexp_like(b, '^[a-z]+@example\\.com$') order by id").Check(testkit.Rows("1"))
+	tk.MustQuery("select id from t where a regexp 'EXAMPLE' order by id").Check(testkit.Rows("1", "2"))
+	tk.MustQuery("select id, regexp_substr(a, '[^@]+', 1, 2), regexp_replace(b, '@.*', '') from t order by id").Check(testkit.Rows(
+		"1 example.com abc", "2 Example.COM Bob", "3 <nil> <nil>"))
+	tk.MustQuery("select id from t where regexp_instr(a, 'c@') = 3").Check(testkit.Rows("1"))
+
+	// The filters are evaluated by TiDB until the coprocessor supports the regexp functions.
+	rows := tk.MustQuery("explain select id from t where regexp_like(a, '^bob', 'i')").Rows()
+	c.Assert(rows[2][0], Matches, "Selection.*")
+	c.Assert(rows[2][3], Equals, "root")
+	tk.MustQuery("select id from t where regexp_like(a, '^bob', 'i')").Check(testkit.Rows("2"))
+
+	goCtx := goctx.Background()
+	for _, sql := range []string{
+		"select regexp_like('a', 'a', 'x')",
+		"select regexp_like('a', '(a')",
+		"select regexp_instr('abc', 'b', 5)",
+	} {
+		rs, err := tk.Exec(sql)
+		c.Assert(err, IsNil)
+		_, err = tidb.GetRows4Test(goCtx, rs)
+		c.Assert(err, NotNil, Commentf("sql: %s", sql))
+		c.Assert(rs.Close(), IsNil)
+	}
 }
 
 func (s *testIntegrationSuite) TestColumnInfoModified(c *C) {
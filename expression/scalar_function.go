@@ -26,6 +26,7 @@ import (
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/types/json"
+	"github.com/pingcap/tidb/udf"
 	"github.com/pingcap/tidb/util/codec"
 )
 
@@ -36,6 +37,8 @@ type ScalarFunction struct {
 	// TODO: Implement type inference here, now we use ast's return type temporarily.
 	RetType  *types.FieldType
 	Function builtinFunc
+
+	collationInfo
 }
 
 // GetArgs gets arguments of function.
@@ -77,7 +80,17 @@ func NewFunction(ctx context.Context, funcName string, retType *types.FieldType,
 	}
 	fc, ok := funcs[funcName]
 	if !ok {
-		return nil, errFunctionNotExists.GenByArgs("FUNCTION", funcName)
+		if fn, isUDF := udf.Get(funcName); isUDF {
+			fc = &udfFunctionClass{name: funcName, fn: fn}
+		} else {
+			var err error
+			if fc, err = loadStoredFunction(ctx, funcName); err != nil {
+				return nil, errors.Trace(err)
+			}
+			if fc == nil {
+				return nil, errFunctionNotExists.GenByArgs("FUNCTION", funcName)
+			}
+		}
 	}
 	funcArgs := make([]Expression, len(args))
 	copy(funcArgs, args)
@@ -142,6 +155,12 @@ func (sf *ScalarFunction) Clone() Expression {
 		return NewValuesFunc(offset, sf.GetType(), sf.GetCtx())
 	}
 	newFunc := NewFunctionInternal(sf.GetCtx(), sf.FuncName.L, sf.RetType, newArgs...)
+	if newSf, ok := newFunc.(*ScalarFunction); ok && sf.coerSet {
+		// The collation set by the COLLATE clause isn't derived from the arguments.
+		tp := *sf.RetType
+		newSf.RetType = &tp
+		newSf.SetCoercibility(sf.coer)
+	}
 	return newFunc
 }
 
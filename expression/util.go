@@ -14,9 +14,6 @@
 package expression
 
 import (
-	"strconv"
-	"strings"
-	"time"
 	"unicode"
 
 	"github.com/juju/errors"
@@ -24,7 +21,6 @@ import (
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/parser/opcode"
-	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/types"
 )
 
@@ -102,6 +98,37 @@ func ColumnSubstitute(expr Expression, schema *Schema, newExprs []Expression) Ex
 	return expr
 }
 
+// SubstituteExprs substitutes the sub-expressions of expr which are equal to exprs with the corresponding columns,
+// it returns whether something is substituted.
+// e.g. lower(a) = 'x' => c = 'x' if the column c is the result of lower(a).
+func SubstituteExprs(ctx context.Context, expr Expression, exprs []Expression, cols []*Column) (Expression, bool) {
+	for i, e := range exprs {
+		if expr.Equal(e, ctx) {
+			return cols[i], true
+		}
+	}
+	v, ok := expr.(*ScalarFunction)
+	if !ok {
+		return expr, false
+	}
+	substituted := false
+	newArgs := make([]Expression, 0, len(v.GetArgs()))
+	for _, arg := range v.GetArgs() {
+		newArg, ok := SubstituteExprs(ctx, arg, exprs, cols)
+		substituted = substituted || ok
+		newArgs = append(newArgs, newArg)
+	}
+	if !substituted {
+		return expr, false
+	}
+	if v.FuncName.L == ast.Cast {
+		newFunc := v.Clone().(*ScalarFunction)
+		newFunc.GetArgs()[0] = newArgs[0]
+		return newFunc, true
+	}
+	return NewFunctionInternal(v.GetCtx(), v.FuncName.L, v.RetType, newArgs...), true
+}
+
 // getValidPrefix gets a prefix of string which can parsed to a number with base. the minimum base is 2 and the maximum is 36.
 func getValidPrefix(s string, base int64) string {
 	var (
@@ -182,22 +209,6 @@ func SubstituteCorCol2Constant(expr Expression) (Expression, error) {
 	return expr.Clone(), nil
 }
 
-// timeZone2Duration converts timezone whose format should satisfy the regular condition
-// `(^(+|-)(0?[0-9]|1[0-2]):[0-5]?\d$)|(^+13:00$)` to time.Duration.
-func timeZone2Duration(tz string) time.Duration {
-	sign := 1
-	if strings.HasPrefix(tz, "-") {
-		sign = -1
-	}
-
-	i := strings.Index(tz, ":")
-	h, err := strconv.Atoi(tz[1:i])
-	terror.Log(errors.Trace(err))
-	m, err := strconv.Atoi(tz[i+1:])
-	terror.Log(errors.Trace(err))
-	return time.Duration(sign) * (time.Duration(h)*time.Hour + time.Duration(m)*time.Minute)
-}
-
 var oppositeOp = map[string]string{
 	ast.LT: ast.GE,
 	ast.GE: ast.LT,
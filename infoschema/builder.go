@@ -41,6 +41,10 @@ func (b *Builder) ApplyDiff(m *meta.Meta, diff *model.SchemaDiff) ([]int64, erro
 	} else if diff.Type == model.ActionDropSchema {
 		tblIDs := b.applyDropSchema(diff.SchemaID)
 		return tblIDs, nil
+	} else if diff.Type == model.ActionModifySchemaCharsetAndCollate {
+		return nil, b.applyModifySchemaCharsetAndCollate(m, diff)
+	} else if diff.Type == model.ActionRenameTables {
+		return b.applyRenameTables(m, diff)
 	}
 
 	roDBInfo, ok := b.is.SchemaByID(diff.SchemaID)
@@ -52,7 +56,7 @@ func (b *Builder) ApplyDiff(m *meta.Meta, diff *model.SchemaDiff) ([]int64, erro
 	var oldTableID, newTableID int64
 	tblIDs := make([]int64, 0, 2)
 	switch diff.Type {
-	case model.ActionCreateTable:
+	case model.ActionCreateTable, model.ActionRecoverTable:
 		newTableID = diff.TableID
 		tblIDs = append(tblIDs, newTableID)
 	case model.ActionDropTable:
@@ -98,6 +102,25 @@ func (b *Builder) ApplyDiff(m *meta.Meta, diff *model.SchemaDiff) ([]int64, erro
 	return tblIDs, nil
 }
 
+// applyRenameTables applies the renames of the tables in order, like they are renamed one by one.
+func (b *Builder) applyRenameTables(m *meta.Meta, diff *model.SchemaDiff) ([]int64, error) {
+	tblIDs := make([]int64, 0, len(diff.AffectedOpts))
+	for _, opt := range diff.AffectedOpts {
+		ids, err := b.ApplyDiff(m, &model.SchemaDiff{
+			Version:     diff.Version,
+			Type:        model.ActionRenameTable,
+			SchemaID:    opt.SchemaID,
+			TableID:     opt.TableID,
+			OldSchemaID: opt.OldSchemaID,
+		})
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		tblIDs = append(tblIDs, ids...)
+	}
+	return tblIDs, nil
+}
+
 // copySortedTables copies sortedTables for old table and new table for later modification.
 func (b *Builder) copySortedTables(oldTableID, newTableID int64) {
 	buckets := b.is.sortedTablesBuckets
@@ -132,6 +155,27 @@ func (b *Builder) applyCreateSchema(m *meta.Meta, diff *model.SchemaDiff) error
 	return nil
 }
 
+func (b *Builder) applyModifySchemaCharsetAndCollate(m *meta.Meta, diff *model.SchemaDiff) error {
+	di, err := m.GetDatabase(diff.SchemaID)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if di == nil {
+		// This should never happen.
+		return ErrDatabaseNotExists.GenByArgs(
+			fmt.Sprintf("(Schema ID %d)", diff.SchemaID),
+		)
+	}
+	// The old DBInfo may be used by the other InfoSchemas, so copy it before changing it.
+	b.copySchemaTables(di.Name.L)
+	schemaTables := b.is.schemaMap[di.Name.L]
+	newDBInfo := *schemaTables.dbInfo
+	newDBInfo.Charset = di.Charset
+	newDBInfo.Collate = di.Collate
+	schemaTables.dbInfo = &newDBInfo
+	return nil
+}
+
 func (b *Builder) applyDropSchema(schemaID int64) []int64 {
 	di, ok := b.is.SchemaByID(schemaID)
 	if !ok {
@@ -160,6 +204,9 @@ func (b *Builder) applyCreateTable(m *meta.Meta, roDBInfo *model.DBInfo, tableID
 			fmt.Sprintf("(Table ID %d)", tableID),
 		)
 	}
+	if isInvisibleTable(tblInfo) {
+		return nil
+	}
 	if alloc == nil {
 		schemaID := roDBInfo.ID
 		alloc = autoid.NewAllocator(b.handle.store, tblInfo.GetDBID(schemaID))
@@ -257,6 +304,7 @@ func (b *Builder) InitWithDBInfos(dbInfos []*model.DBInfo, schemaVersion int64)
 }
 
 func (b *Builder) createSchemaTablesForDB(di *model.DBInfo) error {
+	di = visibleTablesDBInfo(di)
 	schTbls := &schemaTables{
 		dbInfo: di,
 		tables: make(map[string]table.Table, len(di.Tables)),
@@ -277,6 +325,36 @@ func (b *Builder) createSchemaTablesForDB(di *model.DBInfo) error {
 	return nil
 }
 
+// visibleTablesDBInfo returns the database info without the invisible tables. The database info is
+// copied if it has invisible tables, because its tables may be shared with the other users of the meta.
+func visibleTablesDBInfo(di *model.DBInfo) *model.DBInfo {
+	var tblInfos []*model.TableInfo
+	for i, t := range di.Tables {
+		if !isInvisibleTable(t) {
+			if tblInfos != nil {
+				tblInfos = append(tblInfos, t)
+			}
+			continue
+		}
+		if tblInfos == nil {
+			tblInfos = make([]*model.TableInfo, i, len(di.Tables))
+			copy(tblInfos, di.Tables[:i])
+		}
+	}
+	if tblInfos == nil {
+		return di
+	}
+	newDI := *di
+	newDI.Tables = tblInfos
+	return &newDI
+}
+
+// isInvisibleTable returns whether the table is being filled by CREATE TABLE ... SELECT,
+// the table isn't visible until it's filled.
+func isInvisibleTable(tblInfo *model.TableInfo) bool {
+	return tblInfo.State == model.StateWriteReorganization
+}
+
 func (b *Builder) createSchemaTablesForPerfSchemaDB() {
 	perfSchemaDB := perfschema.GetDBMeta()
 	perfSchemaTblNames := &schemaTables{
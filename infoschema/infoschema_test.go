@@ -218,6 +218,34 @@ func (testSuite) TestConcurrent(c *C) {
 	wg.Wait()
 }
 
+// TestInvisibleTables makes sure that the tables being filled by CREATE TABLE ... SELECT are skipped
+// without modifying the DBInfo passed in.
+func (*testSuite) TestInvisibleTables(c *C) {
+	defer testleak.AfterTest(c)()
+	store, err := tikv.NewMockTikvStore()
+	c.Assert(err, IsNil)
+	defer store.Close()
+	handle := infoschema.NewHandle(store)
+	colInfo := &model.ColumnInfo{
+		Name:      model.NewCIStr("a"),
+		FieldType: *types.NewFieldType(mysql.TypeLonglong),
+		State:     model.StatePublic,
+	}
+	tbl1 := &model.TableInfo{ID: 2, Name: model.NewCIStr("t1"), Columns: []*model.ColumnInfo{colInfo}, State: model.StateWriteReorganization}
+	tbl2 := &model.TableInfo{ID: 3, Name: model.NewCIStr("t2"), Columns: []*model.ColumnInfo{colInfo}, State: model.StatePublic}
+	dbInfo := &model.DBInfo{ID: 1, Name: model.NewCIStr("test"), Tables: []*model.TableInfo{tbl1, tbl2}, State: model.StatePublic}
+	builder, err := infoschema.NewBuilder(handle).InitWithDBInfos([]*model.DBInfo{dbInfo}, 1)
+	c.Assert(err, IsNil)
+	builder.Build()
+	is := handle.Get()
+	c.Assert(is.TableExists(dbInfo.Name, tbl1.Name), IsFalse)
+	c.Assert(is.TableExists(dbInfo.Name, tbl2.Name), IsTrue)
+	db, ok := is.SchemaByName(dbInfo.Name)
+	c.Assert(ok, IsTrue)
+	c.Assert(db.Tables, DeepEquals, []*model.TableInfo{tbl2})
+	c.Assert(dbInfo.Tables, DeepEquals, []*model.TableInfo{tbl1, tbl2})
+}
+
 // TestInfoTables makes sure that all tables of information_schema could be found in infoschema handle.
 func (*testSuite) TestInfoTables(c *C) {
 	defer testleak.AfterTest(c)()
@@ -16,6 +16,7 @@ package infoschema
 import (
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/context"
@@ -29,6 +30,7 @@ import (
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/charset"
+	"github.com/pingcap/tidb/util/sqlexec"
 )
 
 const (
@@ -185,6 +187,7 @@ var statisticsCols = []columnInfo{
 	{"INDEX_TYPE", mysql.TypeVarchar, 16, 0, nil, nil},
 	{"COMMENT", mysql.TypeVarchar, 16, 0, nil, nil},
 	{"INDEX_COMMENT", mysql.TypeVarchar, 1024, 0, nil, nil},
+	{"IS_VISIBLE", mysql.TypeVarchar, 3, 0, nil, nil},
 }
 
 var profilingCols = []columnInfo{
@@ -561,6 +564,71 @@ func dataForUserPrivileges(ctx context.Context) [][]types.Datum {
 	return pm.UserPrivilegesTable()
 }
 
+// dataForRoutines reads the stored routines from mysql.proc.
+func dataForRoutines(ctx context.Context) (records [][]types.Datum, err error) {
+	exec, ok := ctx.(sqlexec.RestrictedSQLExecutor)
+	if !ok {
+		return nil, nil
+	}
+	sql := `SELECT db, name, type, returns, body, is_deterministic, sql_data_access, security_type,
+		created, modified, sql_mode, comment, definer, character_set_client, collation_connection, db_collation
+		FROM mysql.proc ORDER BY db, name, type`
+	rows, _, err := exec.ExecRestrictedSQL(ctx, sql)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, row := range rows {
+		var dataType string
+		var dtdIdentifier interface{}
+		tp := row.GetEnum(2).String()
+		// The data access is stored as an enum like "CONTAINS_SQL".
+		dataAccess := strings.Replace(row.GetEnum(6).String(), "_", " ", -1)
+		if tp == "FUNCTION" {
+			returns := row.GetString(3)
+			dtdIdentifier = returns
+			dataType = strings.ToLower(returns)
+			if i := strings.IndexAny(dataType, "( "); i >= 0 {
+				dataType = dataType[:i]
+			}
+		}
+		record := types.MakeDatums(
+			row.GetString(1),        // SPECIFIC_NAME
+			catalogVal,              // ROUTINE_CATALOG
+			row.GetString(0),        // ROUTINE_SCHEMA
+			row.GetString(1),        // ROUTINE_NAME
+			tp,                      // ROUTINE_TYPE
+			dataType,                // DATA_TYPE
+			nil,                     // CHARACTER_MAXIMUM_LENGTH
+			nil,                     // CHARACTER_OCTET_LENGTH
+			nil,                     // NUMERIC_PRECISION
+			nil,                     // NUMERIC_SCALE
+			nil,                     // DATETIME_PRECISION
+			nil,                     // CHARACTER_SET_NAME
+			nil,                     // COLLATION_NAME
+			dtdIdentifier,           // DTD_IDENTIFIER
+			"SQL",                   // ROUTINE_BODY
+			row.GetString(4),        // ROUTINE_DEFINITION
+			nil,                     // EXTERNAL_NAME
+			nil,                     // EXTERNAL_LANGUAGE
+			"SQL",                   // PARAMETER_STYLE
+			row.GetEnum(5).String(), // IS_DETERMINISTIC
+			dataAccess,              // SQL_DATA_ACCESS
+			nil,                     // SQL_PATH
+			row.GetEnum(7).String(), // SECURITY_TYPE
+			row.GetTime(8),          // CREATED
+			row.GetTime(9),          // LAST_ALTERED
+			row.GetString(10),       // SQL_MODE
+			row.GetString(11),       // ROUTINE_COMMENT
+			row.GetString(12),       // DEFINER
+			row.GetString(13),       // CHARACTER_SET_CLIENT
+			row.GetString(14),       // COLLATION_CONNECTION
+			row.GetString(15),       // DATABASE_COLLATION
+		)
+		records = append(records, record)
+	}
+	return records, nil
+}
+
 func dataForEngines() (records [][]types.Datum) {
 	records = append(records,
 		types.MakeDatums("InnoDB", "DEFAULT", "Supports transactions, row-level locking, and foreign keys", "YES", "YES", "YES"),
@@ -676,6 +744,9 @@ func dataForColumns(schemas []*model.DBInfo) [][]types.Datum {
 func dataForColumnsInTable(schema *model.DBInfo, tbl *model.TableInfo) [][]types.Datum {
 	rows := [][]types.Datum{}
 	for i, col := range tbl.Columns {
+		if col.Hidden {
+			continue
+		}
 		colLen, decimal := col.Flen, col.Decimal
 		defaultFlen, defaultDecimal := mysql.GetDefaultFieldLengthAndDecimal(col.Tp)
 		if colLen == types.UnspecifiedLength {
@@ -699,18 +770,18 @@ func dataForColumnsInTable(schema *model.DBInfo, tbl *model.TableInfo) [][]types
 			columnDefault,                        // COLUMN_DEFAULT
 			columnDesc.Null,                      // IS_NULLABLE
 			types.TypeToStr(col.Tp, col.Charset), // DATA_TYPE
-			colLen,                            // CHARACTER_MAXIMUM_LENGTH
-			colLen,                            // CHARACTER_OCTET_LENGTH
-			decimal,                           // NUMERIC_PRECISION
-			0,                                 // NUMERIC_SCALE
-			0,                                 // DATETIME_PRECISION
-			col.Charset,                       // CHARACTER_SET_NAME
-			col.Collate,                       // COLLATION_NAME
-			columnType,                        // COLUMN_TYPE
-			columnDesc.Key,                    // COLUMN_KEY
-			columnDesc.Extra,                  // EXTRA
-			"select,insert,update,references", // PRIVILEGES
-			columnDesc.Comment,                // COLUMN_COMMENT
+			colLen,                               // CHARACTER_MAXIMUM_LENGTH
+			colLen,                               // CHARACTER_OCTET_LENGTH
+			decimal,                              // NUMERIC_PRECISION
+			0,                                    // NUMERIC_SCALE
+			0,                                    // DATETIME_PRECISION
+			col.Charset,                          // CHARACTER_SET_NAME
+			col.Collate,                          // COLLATION_NAME
+			columnType,                           // COLUMN_TYPE
+			columnDesc.Key,                       // COLUMN_KEY
+			columnDesc.Extra,                     // EXTRA
+			"select,insert,update,references",    // PRIVILEGES
+			columnDesc.Comment,                   // COLUMN_COMMENT
 		)
 		rows = append(rows, record)
 	}
@@ -750,6 +821,7 @@ func dataForStatisticsInTable(schema *model.DBInfo, table *model.TableInfo) [][]
 					"BTREE",       // INDEX_TYPE
 					"",            // COMMENT
 					"",            // INDEX_COMMENT
+					"YES",         // IS_VISIBLE
 				)
 				rows = append(rows, record)
 			}
@@ -764,12 +836,21 @@ func dataForStatisticsInTable(schema *model.DBInfo, table *model.TableInfo) [][]
 		if index.Unique {
 			nonUnique = "0"
 		}
+		visible := "YES"
+		if index.Invisible {
+			visible = "NO"
+		}
 		for i, key := range index.Columns {
 			col := nameToCol[key.Name.L]
 			nullable := "YES"
 			if mysql.HasNotNullFlag(col.Flag) {
 				nullable = ""
 			}
+			// The key part which is an expression has no column name.
+			var colName interface{} = key.Name.O
+			if col.Hidden {
+				colName = nil
+			}
 			record := types.MakeDatums(
 				catalogVal,    // TABLE_CATALOG
 				schema.Name.O, // TABLE_SCHEMA
@@ -778,7 +859,7 @@ func dataForStatisticsInTable(schema *model.DBInfo, table *model.TableInfo) [][]
 				schema.Name.O, // INDEX_SCHEMA
 				index.Name.O,  // INDEX_NAME
 				i+1,           // SEQ_IN_INDEX
-				key.Name.O,    // COLUMN_NAME
+				colName,       // COLUMN_NAME
 				"A",           // COLLATION
 				0,             // CARDINALITY
 				nil,           // SUB_PART
@@ -787,6 +868,7 @@ func dataForStatisticsInTable(schema *model.DBInfo, table *model.TableInfo) [][]
 				"BTREE",       // INDEX_TYPE
 				"",            // COMMENT
 				"",            // INDEX_COMMENT
+				visible,       // IS_VISIBLE
 			)
 			rows = append(rows, record)
 		}
@@ -798,6 +880,7 @@ const (
 	primaryKeyType    = "PRIMARY KEY"
 	primaryConstraint = "PRIMARY"
 	uniqueKeyType     = "UNIQUE"
+	checkType         = "CHECK"
 )
 
 // dataForTableConstraints constructs data for table information_schema.constraints.See https://dev.mysql.com/doc/refman/5.7/en/table-constraints-table.html
@@ -839,6 +922,21 @@ func dataForTableConstraints(schemas []*model.DBInfo) [][]types.Datum {
 				)
 				rows = append(rows, record)
 			}
+
+			for _, c := range tbl.Constraints {
+				if c.State != model.StatePublic {
+					continue
+				}
+				record := types.MakeDatums(
+					catalogVal,    // CONSTRAINT_CATALOG
+					schema.Name.O, // CONSTRAINT_SCHEMA
+					c.Name.O,      // CONSTRAINT_NAME
+					schema.Name.O, // TABLE_SCHEMA
+					tbl.Name.O,    // TABLE_NAME
+					checkType,     // CONSTRAINT_TYPE
+				)
+				rows = append(rows, record)
+			}
 		}
 	}
 	return rows
@@ -1040,6 +1138,7 @@ func (it *infoschemaTable) getRows(ctx context.Context, cols []*table.Column) (f
 		fullRows = dataForEngines()
 	case tableViews:
 	case tableRoutines:
+		fullRows, err = dataForRoutines(ctx)
 	// TODO: Fill the following tables.
 	case tableSchemaPrivileges:
 	case tableTablePrivileges:
@@ -565,6 +565,19 @@ func (m *Meta) GetAllHistoryDDLJobs() ([]*model.Job, error) {
 	return jobs, nil
 }
 
+// IterateHistoryDDLJobs iterates the history DDL jobs in the order of their IDs,
+// the jobs are decoded one by one instead of being loaded at once.
+func (m *Meta) IterateHistoryDDLJobs(fn func(*model.Job) error) error {
+	err := m.txn.HIterate(mDDLJobHistoryKey, func(field []byte, value []byte) error {
+		job := &model.Job{}
+		if err := job.Decode(value); err != nil {
+			return errors.Trace(err)
+		}
+		return errors.Trace(fn(job))
+	})
+	return errors.Trace(err)
+}
+
 // jobsSorter implements the sort.Interface interface.
 type jobsSorter struct {
 	jobs []*model.Job
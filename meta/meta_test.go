@@ -310,6 +310,13 @@ func (s *testSuite) TestDDL(c *C) {
 		c.Assert(job.ID, Greater, lastID)
 		lastID = job.ID
 	}
+	var iterated []*model.Job
+	err = t.IterateHistoryDDLJobs(func(job *model.Job) error {
+		iterated = append(iterated, job)
+		return nil
+	})
+	c.Assert(err, IsNil)
+	c.Assert(iterated, DeepEquals, all)
 
 	err = txn.Commit(goctx.Background())
 	c.Assert(err, IsNil)
@@ -43,6 +43,19 @@ const (
 	ActionRebaseAutoID
 	ActionRenameTable
 	ActionSetDefaultValue
+	ActionRecoverTable
+	ActionMultiSchemaChange
+	ActionAddPrimaryKey
+	ActionDropPrimaryKey
+	ActionPublishTable
+	ActionRenameIndex
+	ActionModifyTableComment
+	ActionModifyTableCharsetAndCollate
+	ActionModifySchemaCharsetAndCollate
+	ActionRenameTables
+	ActionAddCheckConstraint
+	ActionDropCheckConstraint
+	ActionAlterIndexVisibility
 )
 
 func (action ActionType) String() string {
@@ -77,6 +90,32 @@ func (action ActionType) String() string {
 		return "rename table"
 	case ActionSetDefaultValue:
 		return "set default value"
+	case ActionRecoverTable:
+		return "recover table"
+	case ActionMultiSchemaChange:
+		return "multi-schema change"
+	case ActionAddPrimaryKey:
+		return "add primary key"
+	case ActionDropPrimaryKey:
+		return "drop primary key"
+	case ActionPublishTable:
+		return "publish table"
+	case ActionRenameIndex:
+		return "rename index"
+	case ActionModifyTableComment:
+		return "modify table comment"
+	case ActionModifyTableCharsetAndCollate:
+		return "modify table charset and collate"
+	case ActionModifySchemaCharsetAndCollate:
+		return "modify schema charset and collate"
+	case ActionRenameTables:
+		return "rename tables"
+	case ActionAddCheckConstraint:
+		return "add check constraint"
+	case ActionDropCheckConstraint:
+		return "drop check constraint"
+	case ActionAlterIndexVisibility:
+		return "alter index visibility"
 	default:
 		return "none"
 	}
@@ -87,6 +126,8 @@ type HistoryInfo struct {
 	SchemaVersion int64
 	DBInfo        *DBInfo
 	TableInfo     *TableInfo
+	// MultipleTableInfos are the tables changed by the operations on several tables, like renaming tables.
+	MultipleTableInfos []*TableInfo `json:",omitempty"`
 }
 
 // AddDBInfo adds schema version and schema information that are used for binlog.
@@ -103,11 +144,19 @@ func (h *HistoryInfo) AddTableInfo(schemaVer int64, tblInfo *TableInfo) {
 	h.TableInfo = tblInfo
 }
 
+// AddMultipleTableInfos adds schema version and the information of the tables that are used for binlog.
+// tblInfos are added in the following operations: rename tables.
+func (h *HistoryInfo) AddMultipleTableInfos(schemaVer int64, tblInfos []*TableInfo) {
+	h.SchemaVersion = schemaVer
+	h.MultipleTableInfos = tblInfos
+}
+
 // Clean cleans history information.
 func (h *HistoryInfo) Clean() {
 	h.SchemaVersion = 0
 	h.DBInfo = nil
 	h.TableInfo = nil
+	h.MultipleTableInfos = nil
 }
 
 // Job is for a DDL operation.
@@ -138,6 +187,80 @@ type Job struct {
 
 	// Version indicates the DDL job version. For old jobs, it will be 0.
 	Version int64 `json:"version"`
+
+	// MultiSchemaInfo keeps the sub-jobs of an ActionMultiSchemaChange job.
+	// For the job passed to the handler of a sub-job, it is only used to
+	// mark whether the sub-job can be rolled back.
+	MultiSchemaInfo *MultiSchemaInfo `json:"multi_schema_info"`
+}
+
+// MultiSchemaInfo keeps the information of a multi-schema change job.
+type MultiSchemaInfo struct {
+	SubJobs []*SubJob `json:"sub_jobs"`
+	// Revertible means no sub-job has reached the state that can't be rolled back.
+	Revertible bool `json:"revertible"`
+	// NonRevertibleErrorCount is the number of the steps which fail after the job becomes non-revertible.
+	NonRevertibleErrorCount int64 `json:"non_revertible_err_count"`
+}
+
+// SubJob is a part of a multi-schema change job. It runs through the schema
+// states like a normal job, the fields have the same meaning as in Job.
+type SubJob struct {
+	Type        ActionType      `json:"type"`
+	Args        []interface{}   `json:"-"`
+	RawArgs     json.RawMessage `json:"raw_args"`
+	SchemaState SchemaState     `json:"schema_state"`
+	SnapshotVer uint64          `json:"snapshot_ver"`
+	RowCount    int64           `json:"row_count"`
+	State       JobState        `json:"state"`
+	// Revertible means the sub-job hasn't reached the state that can't be rolled back.
+	Revertible bool `json:"revertible"`
+}
+
+// NewSubJob creates a sub-job from the job that would run the same operation alone.
+func NewSubJob(job *Job) *SubJob {
+	return &SubJob{
+		Type:       job.Type,
+		Args:       job.Args,
+		Revertible: true,
+	}
+}
+
+// ToProxyJob builds a job that runs the sub-job with the handler of its action type.
+func (sub *SubJob) ToProxyJob(parent *Job) *Job {
+	return &Job{
+		ID:              parent.ID,
+		Type:            sub.Type,
+		SchemaID:        parent.SchemaID,
+		TableID:         parent.TableID,
+		State:           sub.State,
+		RowCount:        sub.RowCount,
+		Args:            sub.Args,
+		RawArgs:         sub.RawArgs,
+		SchemaState:     sub.SchemaState,
+		SnapshotVer:     sub.SnapshotVer,
+		LastUpdateTS:    parent.LastUpdateTS,
+		Query:           parent.Query,
+		BinlogInfo:      parent.BinlogInfo,
+		Version:         parent.Version,
+		MultiSchemaInfo: &MultiSchemaInfo{Revertible: sub.Revertible},
+	}
+}
+
+// FromProxyJob updates the sub-job with the result of running the proxy job.
+func (sub *SubJob) FromProxyJob(proxy *Job) {
+	sub.Args = proxy.Args
+	sub.RawArgs = proxy.RawArgs
+	sub.SchemaState = proxy.SchemaState
+	sub.SnapshotVer = proxy.SnapshotVer
+	sub.RowCount = proxy.GetRowCount()
+	sub.State = proxy.State
+	sub.Revertible = proxy.MultiSchemaInfo.Revertible
+}
+
+// IsFinished returns whether the sub-job is finished or not.
+func (sub *SubJob) IsFinished() bool {
+	return sub.State == JobStateDone || sub.State == JobStateRollbackDone || sub.State == JobStateCancelled
 }
 
 // SetRowCount sets the number of rows. Make sure it can pass `make race`.
@@ -165,6 +288,18 @@ func (job *Job) Encode(updateRawArgs bool) ([]byte, error) {
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
+		if job.MultiSchemaInfo != nil {
+			for _, sub := range job.MultiSchemaInfo.SubJobs {
+				// The args of a sub-job are only decoded when it runs.
+				if sub.Args == nil {
+					continue
+				}
+				sub.RawArgs, err = json.Marshal(sub.Args)
+				if err != nil {
+					return nil, errors.Trace(err)
+				}
+			}
+		}
 	}
 
 	var b []byte
@@ -287,4 +422,15 @@ type SchemaDiff struct {
 	OldTableID int64 `json:"old_table_id"`
 	// OldSchemaID is the schema ID before rename table, only used by rename table DDL.
 	OldSchemaID int64 `json:"old_schema_id"`
+
+	// AffectedOpts are the changes of the tables, only used by rename tables DDL.
+	AffectedOpts []*AffectedOption `json:"affected_options"`
+}
+
+// AffectedOption is the change of a table affected by a DDL job which changes several tables.
+type AffectedOption struct {
+	SchemaID    int64 `json:"schema_id"`
+	TableID     int64 `json:"table_id"`
+	OldTableID  int64 `json:"old_table_id"`
+	OldSchemaID int64 `json:"old_schema_id"`
 }
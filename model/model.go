@@ -15,6 +15,8 @@ package model
 
 import (
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/types"
@@ -70,6 +72,67 @@ type ColumnInfo struct {
 	types.FieldType     `json:"type"`
 	State               SchemaState `json:"state"`
 	Comment             string      `json:"comment"`
+	// ChangeStateInfo is set when the column is the changing column of a
+	// column type change, which holds the converted data of another column.
+	ChangeStateInfo *ChangeStateInfo `json:"change_state_info"`
+	// Hidden is set when the column is the virtual generated column of an
+	// expression index, it's invisible to users.
+	Hidden bool `json:"hidden"`
+}
+
+// ChangeStateInfo is the information of a changing column.
+type ChangeStateInfo struct {
+	// DependencyColumnOffset is the offset of the column whose data is converted into the changing column.
+	DependencyColumnOffset int `json:"dependency_column_offset"`
+	// TimeZone is the time zone of the statement which changes the column, the values are
+	// converted in it by both the DDL job and the DMLs. It's nil for the older versions, which
+	// convert the values in UTC.
+	TimeZone *TimeZoneLocation `json:"time_zone"`
+}
+
+// TimeZoneLocation is a time zone which can be persisted in the meta.
+type TimeZoneLocation struct {
+	// Name is the name of the time zone in the tz database, it's empty if the time zone is
+	// recorded by its offset.
+	Name string `json:"name"`
+	// Offset is the offset of the time zone in seconds east of UTC if Name is empty.
+	Offset int `json:"offset"`
+
+	once     sync.Once
+	location *time.Location
+	err      error
+}
+
+// NewTimeZoneLocation returns the TimeZoneLocation of loc. The time zones which aren't in the tz
+// database, e.g. the local time zone and the time zones of fixed offsets, are recorded by their
+// current offsets.
+func NewTimeZoneLocation(loc *time.Location) *TimeZoneLocation {
+	now := time.Now()
+	_, offset := now.In(loc).Zone()
+	if name := loc.String(); name != "Local" {
+		// The time zones of fixed offsets may be named UTC too.
+		if named, err := time.LoadLocation(name); err == nil {
+			if _, namedOffset := now.In(named).Zone(); namedOffset == offset {
+				return &TimeZoneLocation{Name: name}
+			}
+		}
+	}
+	return &TimeZoneLocation{Offset: offset}
+}
+
+// GetLocation returns the time zone, it's UTC if tz is nil.
+func (tz *TimeZoneLocation) GetLocation() (*time.Location, error) {
+	if tz == nil {
+		return time.UTC, nil
+	}
+	tz.once.Do(func() {
+		if tz.Name == "" {
+			tz.location = time.FixedZone("UTC", tz.Offset)
+			return
+		}
+		tz.location, tz.err = time.LoadLocation(tz.Name)
+	})
+	return tz.location, tz.err
 }
 
 // Clone clones ColumnInfo.
@@ -97,15 +160,16 @@ type TableInfo struct {
 	Charset string `json:"charset"`
 	Collate string `json:"collate"`
 	// Columns are listed in the order in which they appear in the schema.
-	Columns     []*ColumnInfo `json:"cols"`
-	Indices     []*IndexInfo  `json:"index_info"`
-	ForeignKeys []*FKInfo     `json:"fk_info"`
-	State       SchemaState   `json:"state"`
-	PKIsHandle  bool          `json:"pk_is_handle"`
-	Comment     string        `json:"comment"`
-	AutoIncID   int64         `json:"auto_inc_id"`
-	MaxColumnID int64         `json:"max_col_id"`
-	MaxIndexID  int64         `json:"max_idx_id"`
+	Columns     []*ColumnInfo     `json:"cols"`
+	Indices     []*IndexInfo      `json:"index_info"`
+	ForeignKeys []*FKInfo         `json:"fk_info"`
+	Constraints []*ConstraintInfo `json:"constraint_info"`
+	State       SchemaState       `json:"state"`
+	PKIsHandle  bool              `json:"pk_is_handle"`
+	Comment     string            `json:"comment"`
+	AutoIncID   int64             `json:"auto_inc_id"`
+	MaxColumnID int64             `json:"max_col_id"`
+	MaxIndexID  int64             `json:"max_idx_id"`
 	// OldSchemaID :
 	// Because auto increment ID has schemaID as prefix,
 	// We need to save original schemaID to keep autoID unchanged
@@ -130,6 +194,7 @@ func (t *TableInfo) Clone() *TableInfo {
 	nt.Columns = make([]*ColumnInfo, len(t.Columns))
 	nt.Indices = make([]*IndexInfo, len(t.Indices))
 	nt.ForeignKeys = make([]*FKInfo, len(t.ForeignKeys))
+	nt.Constraints = make([]*ConstraintInfo, len(t.Constraints))
 
 	for i := range t.Columns {
 		nt.Columns[i] = t.Columns[i].Clone()
@@ -143,6 +208,10 @@ func (t *TableInfo) Clone() *TableInfo {
 		nt.ForeignKeys[i] = t.ForeignKeys[i].Clone()
 	}
 
+	for i := range t.Constraints {
+		nt.Constraints[i] = t.Constraints[i].Clone()
+	}
+
 	return &nt
 }
 
@@ -233,15 +302,16 @@ const (
 // It corresponds to the statement `CREATE INDEX Name ON Table (Column);`
 // See https://dev.mysql.com/doc/refman/5.7/en/create-index.html
 type IndexInfo struct {
-	ID      int64          `json:"id"`
-	Name    CIStr          `json:"idx_name"`   // Index name.
-	Table   CIStr          `json:"tbl_name"`   // Table name.
-	Columns []*IndexColumn `json:"idx_cols"`   // Index columns.
-	Unique  bool           `json:"is_unique"`  // Whether the index is unique.
-	Primary bool           `json:"is_primary"` // Whether the index is primary key.
-	State   SchemaState    `json:"state"`
-	Comment string         `json:"comment"`    // Comment
-	Tp      IndexType      `json:"index_type"` // Index type: Btree or Hash
+	ID        int64          `json:"id"`
+	Name      CIStr          `json:"idx_name"`   // Index name.
+	Table     CIStr          `json:"tbl_name"`   // Table name.
+	Columns   []*IndexColumn `json:"idx_cols"`   // Index columns.
+	Unique    bool           `json:"is_unique"`  // Whether the index is unique.
+	Primary   bool           `json:"is_primary"` // Whether the index is primary key.
+	State     SchemaState    `json:"state"`
+	Comment   string         `json:"comment"`      // Comment
+	Tp        IndexType      `json:"index_type"`   // Index type: Btree or Hash
+	Invisible bool           `json:"is_invisible"` // Whether the index is ignored by the optimizer, it's still maintained by DML.
 }
 
 // Clone clones IndexInfo.
@@ -288,6 +358,36 @@ func (fk *FKInfo) Clone() *FKInfo {
 	return &nfk
 }
 
+// ConstraintInfo provides meta data describing a CHECK constraint.
+type ConstraintInfo struct {
+	ID         int64       `json:"id"`
+	Name       CIStr       `json:"constraint_name"`
+	ExprString string      `json:"expr_string"`
+	Cols       []CIStr     `json:"cols"` // The columns referred in the expression.
+	State      SchemaState `json:"state"`
+	// TimeZone is the time zone of the statement which adds the constraint, the constraint is
+	// evaluated in it by both the DDL job and the DMLs. It's UTC if it's nil.
+	TimeZone *TimeZoneLocation `json:"time_zone"`
+}
+
+// Clone clones ConstraintInfo.
+func (c *ConstraintInfo) Clone() *ConstraintInfo {
+	nc := *c
+	nc.Cols = make([]CIStr, len(c.Cols))
+	copy(nc.Cols, c.Cols)
+	return &nc
+}
+
+// FindConstraintByName finds the CHECK constraint by its lowercase name.
+func (t *TableInfo) FindConstraintByName(name string) *ConstraintInfo {
+	for _, c := range t.Constraints {
+		if c.Name.L == name {
+			return c
+		}
+	}
+	return nil
+}
+
 // DBInfo provides meta data describing a DB.
 type DBInfo struct {
 	ID      int64        `json:"id"`      // Database ID
@@ -66,6 +66,12 @@ func (*testModelSuite) TestModelBasic(c *C) {
 		Cols:    []CIStr{NewCIStr("a")},
 	}
 
+	constraint := &ConstraintInfo{
+		Name:       NewCIStr("chk"),
+		ExprString: "`c` > 0",
+		Cols:       []CIStr{NewCIStr("c")},
+	}
+
 	table := &TableInfo{
 		ID:          1,
 		Name:        NewCIStr("t"),
@@ -74,6 +80,7 @@ func (*testModelSuite) TestModelBasic(c *C) {
 		Columns:     []*ColumnInfo{column},
 		Indices:     []*IndexInfo{index},
 		ForeignKeys: []*FKInfo{fk},
+		Constraints: []*ConstraintInfo{constraint},
 		PKIsHandle:  true,
 	}
 
@@ -102,6 +109,8 @@ func (*testModelSuite) TestModelBasic(c *C) {
 	c.Assert(tp.String(), Equals, "")
 	has := index.HasPrefixIndex()
 	c.Assert(has, Equals, true)
+	c.Assert(table.FindConstraintByName("chk"), Equals, constraint)
+	c.Assert(table.FindConstraintByName("chk1"), IsNil)
 
 	// Corner cases
 	column.Flag ^= mysql.PriKeyFlag
@@ -184,6 +193,44 @@ func (*testModelSuite) TestJobCodec(c *C) {
 	c.Assert(job.GetRowCount(), Equals, int64(3))
 }
 
+func (*testModelSuite) TestMultiSchemaJobCodec(c *C) {
+	sub := NewSubJob(&Job{Type: ActionDropColumn, Args: []interface{}{NewCIStr("a")}})
+	job := &Job{
+		ID:              1,
+		Type:            ActionMultiSchemaChange,
+		SchemaID:        2,
+		TableID:         3,
+		BinlogInfo:      &HistoryInfo{},
+		MultiSchemaInfo: &MultiSchemaInfo{SubJobs: []*SubJob{sub}, Revertible: true},
+	}
+	b, err := job.Encode(true)
+	c.Assert(err, IsNil)
+	newJob := &Job{}
+	c.Assert(newJob.Decode(b), IsNil)
+	c.Assert(newJob.MultiSchemaInfo.Revertible, IsTrue)
+	c.Assert(newJob.MultiSchemaInfo.SubJobs, HasLen, 1)
+
+	// The proxy job runs the sub-job with the IDs of the parent job.
+	newSub := newJob.MultiSchemaInfo.SubJobs[0]
+	proxy := newSub.ToProxyJob(newJob)
+	c.Assert(proxy.Type, Equals, ActionDropColumn)
+	c.Assert(proxy.TableID, Equals, int64(3))
+	c.Assert(proxy.MultiSchemaInfo.Revertible, IsTrue)
+	var name CIStr
+	c.Assert(proxy.DecodeArgs(&name), IsNil)
+	c.Assert(name, DeepEquals, NewCIStr("a"))
+
+	proxy.SchemaState = StateWriteOnly
+	proxy.MultiSchemaInfo.Revertible = false
+	newSub.FromProxyJob(proxy)
+	c.Assert(newSub.SchemaState, Equals, StateWriteOnly)
+	c.Assert(newSub.Revertible, IsFalse)
+	c.Assert(newSub.IsFinished(), IsFalse)
+	newSub.State = JobStateDone
+	c.Assert(newSub.IsFinished(), IsTrue)
+	c.Assert(ActionMultiSchemaChange.String(), Equals, "multi-schema change")
+}
+
 func (testModelSuite) TestState(c *C) {
 	schemaTbl := []SchemaState{
 		StateDeleteOnly,
@@ -882,6 +882,8 @@ const (
 	ErrMustChangePasswordLogin                                      = 1862
 	ErrRowInWrongPartition                                          = 1863
 	ErrErrorLast                                                    = 1863
+	ErrGISDifferentSRIDs                                            = 3033
+	ErrGISInvalidData                                               = 3037
 	ErrBadGeneratedColumn                                           = 3105
 	ErrUnsupportedOnGeneratedColumn                                 = 3106
 	ErrGeneratedColumnNonPrior                                      = 3107
@@ -889,7 +891,31 @@ const (
 	ErrInvalidJSONText                                              = 3140
 	ErrInvalidJSONPath                                              = 3143
 	ErrInvalidJSONData                                              = 3146
+	ErrInvalidJSONPathWildcard                                      = 3149
 	ErrJSONUsedAsKey                                                = 3152
+	ErrJSONVacuousPath                                              = 3153
+	ErrJSONBadOneOrAllArg                                           = 3154
+	ErrJSONDocumentNULLKey                                          = 3158
+	ErrInvalidJSONPathArrayCell                                     = 3165
+	ErrPKIndexCantBeInvisible                                       = 3522
+	ErrRegexpIllegalArgument                                        = 3685
+	ErrRegexpIndexOutOfBounds                                       = 3686
+	ErrFunctionalIndexOnJSONOrGeometryFunction                      = 3753
+	ErrFunctionalIndexRefAutoIncrement                              = 3754
+	ErrCannotDropColumnFunctionalIndex                              = 3755
+	ErrFunctionalIndexPrimaryKey                                    = 3756
+	ErrFunctionalIndexOnLob                                         = 3757
+	ErrFunctionalIndexFunctionIsNotAllowed                          = 3758
+	ErrFunctionalIndexOnField                                       = 3762
+	ErrColumnCheckConstraintReferencesOtherColumn                   = 3813
+	ErrCheckConstraintVariables                                     = 3814
+	ErrCheckConstraintFunctionIsNotAllowed                          = 3815
+	ErrCheckConstraintRefersAutoIncrementColumn                     = 3818
+	ErrCheckConstraintViolated                                      = 3819
+	ErrCheckConstraintRefersUnknownColumn                           = 3820
+	ErrCheckConstraintNotFound                                      = 3821
+	ErrCheckConstraintDupName                                       = 3822
+	ErrDependentByCheckConstraint                                   = 3959
 
 	// TiKV/PD errors.
 	ErrPDServerTimeout    = 9001
@@ -643,7 +643,7 @@ var MySQLErrName = map[uint16]string{
 	ErrNdbReplicationSchema:                                  "Bad schema for mysql.ndbReplication table. Message: %-.64s",
 	ErrConflictFnParse:                                       "Error in parsing conflict function. Message: %-.64s",
 	ErrExceptionsWrite:                                       "Write to exceptions table failed. Message: %-.128s\"",
-	ErrTooLongTableComment:                                   "Comment for table '%-.64s' is too long (max = %lu)",
+	ErrTooLongTableComment:                                   "Comment for table '%-.64s' is too long (max = %d)",
 	ErrTooLongFieldComment:                                   "Comment for field '%-.64s' is too long (max = %lu)",
 	ErrFuncInexistentNameCollision:                           "FUNCTION %s does not exist. Check the 'Function Name Parsing and Resolution' section in the Reference Manual",
 	ErrDatabaseName:                                          "Database",
@@ -879,6 +879,8 @@ var MySQLErrName = map[uint16]string{
 	ErrAlterOperationNotSupportedReasonNotNull:               "cannot silently convert NULL values, as required in this SQLMODE",
 	ErrMustChangePasswordLogin:                               "Your password has expired. To log in you must change it using a client that supports expired passwords.",
 	ErrRowInWrongPartition:                                   "Found a row in wrong partition %s",
+	ErrGISDifferentSRIDs:                                     "Binary geometry function %s given two geometries of different srids: %d and %d, which should have been identical.",
+	ErrGISInvalidData:                                        "Invalid GIS data provided to function %s.",
 	ErrBadGeneratedColumn:                                    "The value specified for generated column '%s' in table '%s' is not allowed.",
 	ErrUnsupportedOnGeneratedColumn:                          "'%s' is not supported for generated columns.",
 	ErrGeneratedColumnNonPrior:                               "Generated column can refer only to generated columns defined prior to it.",
@@ -886,7 +888,31 @@ var MySQLErrName = map[uint16]string{
 	ErrInvalidJSONText:                                       "Invalid JSON text: %-.192s",
 	ErrInvalidJSONPath:                                       "Invalid JSON path expression %s.",
 	ErrInvalidJSONData:                                       "Invalid data type for JSON data",
+	ErrInvalidJSONPathWildcard:                               "In this situation, path expressions may not contain the * and ** tokens.",
 	ErrJSONUsedAsKey:                                         "JSON column '%-.192s' cannot be used in key specification.",
+	ErrJSONVacuousPath:                                       "The path expression '$' is not allowed in this context.",
+	ErrJSONBadOneOrAllArg:                                    "The oneOrAll argument to %s may take these values: 'one' or 'all'.",
+	ErrJSONDocumentNULLKey:                                   "JSON documents may not contain NULL member names.",
+	ErrInvalidJSONPathArrayCell:                              "A path expression is not a path to a cell in an array.",
+	ErrPKIndexCantBeInvisible:                                "A primary key index cannot be invisible",
+	ErrRegexpIllegalArgument:                                 "Illegal argument to a regular expression.",
+	ErrRegexpIndexOutOfBounds:                                "Index out of bounds in regular expression search.",
+	ErrFunctionalIndexOnJSONOrGeometryFunction:               "Cannot create a functional index on a function that returns a JSON or GEOMETRY value.",
+	ErrFunctionalIndexRefAutoIncrement:                       "Functional index '%-.64s' cannot refer to an auto-increment column.",
+	ErrCannotDropColumnFunctionalIndex:                       "Cannot drop column '%-.64s' because it is used by a functional index. In order to drop the column, you must remove the functional index.",
+	ErrFunctionalIndexPrimaryKey:                             "The primary key cannot be a functional index",
+	ErrFunctionalIndexOnLob:                                  "Cannot create a functional index on an expression that returns a BLOB or TEXT. Please consider using CAST.",
+	ErrFunctionalIndexFunctionIsNotAllowed:                   "Expression of functional index '%s' contains a disallowed function.",
+	ErrFunctionalIndexOnField:                                "Functional index on a column is not supported. Consider using a regular index instead.",
+	ErrColumnCheckConstraintReferencesOtherColumn:            "Column check constraint '%-.192s' references other column.",
+	ErrCheckConstraintVariables:                              "An expression of a check constraint '%-.192s' cannot refer to a user or system variable.",
+	ErrCheckConstraintFunctionIsNotAllowed:                   "An expression of a check constraint '%-.192s' contains disallowed function.",
+	ErrCheckConstraintRefersAutoIncrementColumn:              "Check constraint '%-.192s' cannot refer to an auto-increment column.",
+	ErrCheckConstraintViolated:                               "Check constraint '%-.192s' is violated.",
+	ErrCheckConstraintRefersUnknownColumn:                    "Check constraint '%-.192s' refers to non-existing column '%-.192s'.",
+	ErrCheckConstraintNotFound:                               "Check constraint '%-.192s' is not found in the table.",
+	ErrCheckConstraintDupName:                                "Duplicate check constraint name '%-.192s'.",
+	ErrDependentByCheckConstraint:                            "Check constraint '%-.192s' uses column '%-.192s', hence column cannot be dropped or renamed.",
 
 	// TiKV/PD errors.
 	ErrPDServerTimeout:    "PD server timeout",
@@ -246,6 +246,8 @@ var MySQLState = map[uint16]string{
 	ErrAlterOperationNotSupported:          "0A000",
 	ErrAlterOperationNotSupportedReason:    "0A000",
 	ErrDupUnknownInIndex:                   "23000",
+	ErrGISDifferentSRIDs:                   "HY000",
+	ErrGISInvalidData:                      "22023",
 	ErrBadGeneratedColumn:                  "HY000",
 	ErrUnsupportedOnGeneratedColumn:        "HY000",
 	ErrGeneratedColumnNonPrior:             "HY000",
@@ -253,5 +255,10 @@ var MySQLState = map[uint16]string{
 	ErrInvalidJSONText:                     "22032",
 	ErrInvalidJSONPath:                     "42000",
 	ErrInvalidJSONData:                     "22032",
+	ErrInvalidJSONPathWildcard:             "42000",
 	ErrJSONUsedAsKey:                       "42000",
+	ErrJSONVacuousPath:                     "42000",
+	ErrJSONBadOneOrAllArg:                  "42000",
+	ErrJSONDocumentNULLKey:                 "22032",
+	ErrInvalidJSONPathArrayCell:            "42000",
 }
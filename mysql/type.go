@@ -74,6 +74,8 @@ const (
 	BinCmpFlag         uint = 131072 /* Intern: Used by sql_yacc */
 	ParseToJSONFlag    uint = 262144 /* Intern: Used when we want to parse string to JSON in CAST */
 	IsBooleanFlag      uint = 524288 /* Intern: Used for telling boolean literal from integer */
+
+	PreventNullInsertFlag uint = 1048576 /* Intern: Prevent inserting NULL values when the column is being added to a primary key */
 )
 
 // TypeInt24 bounds.
@@ -88,6 +90,11 @@ func HasNotNullFlag(flag uint) bool {
 	return (flag & NotNullFlag) > 0
 }
 
+// HasPreventNullInsertFlag checks if PreventNullInsertFlag is set.
+func HasPreventNullInsertFlag(flag uint) bool {
+	return (flag & PreventNullInsertFlag) > 0
+}
+
 // HasNoDefaultValueFlag checks if NoDefaultValueFlag is set.
 func HasNoDefaultValueFlag(flag uint) bool {
 	return (flag & NoDefaultValueFlag) > 0
@@ -1306,6 +1306,27 @@ func (s *testSessionSuite) TestCaseInsensitive(c *C) {
 }
 
 // for delete panic
+func (s *testSessionSuite) TestNamedTimeZone(c *C) {
+	tk := testkit.NewTestKitWithInit(c, s.store)
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (id int, ts timestamp)")
+	tk.MustExec("set time_zone = 'America/New_York'")
+	// The daylight saving time of New York starts at 2017-03-12 02:00:00 EST and ends at 2017-11-05 02:00:00 EDT.
+	tk.MustExec("insert into t values (1, '2017-03-12 01:30:00'), (2, '2017-03-12 03:30:00'), (3, '2017-11-05 03:30:00')")
+	tk.MustQuery("select ts from t order by id").Check(testkit.Rows("2017-03-12 01:30:00", "2017-03-12 03:30:00", "2017-11-05 03:30:00"))
+	tk.MustQuery("select id from t where ts > '2017-03-12 01:59:59' and ts < '2017-03-12 04:00:00'").Check(testkit.Rows("2"))
+	tk.MustExec("set time_zone = '+00:00'")
+	tk.MustQuery("select ts from t order by id").Check(testkit.Rows("2017-03-12 06:30:00", "2017-03-12 07:30:00", "2017-11-05 08:30:00"))
+	tk.MustExec("set time_zone = 'Europe/Berlin'")
+	tk.MustQuery("select ts from t order by id").Check(testkit.Rows("2017-03-12 07:30:00", "2017-03-12 08:30:00", "2017-11-05 09:30:00"))
+
+	tk.MustQuery("select convert_tz('2017-03-12 06:59:59', 'UTC', 'America/New_York'), convert_tz('2017-03-12 07:00:00', '+00:00', 'America/New_York')").
+		Check(testkit.Rows("2017-03-12 01:59:59 2017-03-12 03:00:00"))
+	tk.MustQuery("select convert_tz('2017-01-01 00:00:00', 'Unknown/Zone', '+00:00')").Check(testkit.Rows("<nil>"))
+	_, err := tk.Exec("set time_zone = 'Unknown/Zone'")
+	c.Assert(terror.ErrorEqual(err, variable.ErrUnknownTimeZone), IsTrue)
+}
+
 func (s *testSessionSuite) TestDeletePanic(c *C) {
 	tk := testkit.NewTestKitWithInit(c, s.store)
 	tk.MustExec("create table t (c int)")
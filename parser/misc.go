@@ -131,196 +131,220 @@ func init() {
 }
 
 var tokenMap = map[string]int{
-	"ACTION":            action,
-	"ADD":               add,
-	"ADDDATE":           addDate,
-	"ADMIN":             admin,
-	"AFTER":             after,
-	"ALL":               all,
-	"ALGORITHM":         algorithm,
-	"ALTER":             alter,
-	"ALWAYS":            always,
-	"ANALYZE":           analyze,
-	"AND":               and,
-	"ANY":               any,
-	"AS":                as,
-	"ASC":               asc,
-	"ASCII":             ascii,
-	"AUTO_INCREMENT":    autoIncrement,
-	"AVG":               avg,
-	"AVG_ROW_LENGTH":    avgRowLength,
-	"BEGIN":             begin,
-	"BETWEEN":           between,
-	"BIGINT":            bigIntType,
-	"BINARY":            binaryType,
-	"BINLOG":            binlog,
-	"BIT":               bitType,
-	"BIT_AND":           bitAnd,
-	"BIT_OR":            bitOr,
-	"BIT_XOR":           bitXor,
-	"BLOB":              blobType,
-	"BOOL":              boolType,
-	"BOOLEAN":           booleanType,
-	"BOTH":              both,
-	"BTREE":             btree,
-	"BY":                by,
-	"BYTE":              byteType,
-	"CANCEL":            cancel,
-	"CASCADE":           cascade,
-	"CASCADED":          cascaded,
-	"CASE":              caseKwd,
-	"CAST":              cast,
-	"CHANGE":            change,
-	"CHAR":              charType,
-	"CHARACTER":         character,
-	"CHARSET":           charsetKwd,
-	"CHECK":             check,
-	"CHECKSUM":          checksum,
-	"CLIENT":            client,
-	"COALESCE":          coalesce,
-	"COLLATE":           collate,
-	"COLLATION":         collation,
-	"COLUMN":            column,
-	"COLUMNS":           columns,
-	"COMMENT":           comment,
-	"COMMIT":            commit,
-	"COMMITTED":         committed,
-	"COMPACT":           compact,
-	"COMPRESSED":        compressed,
-	"COMPRESSION":       compression,
-	"CONNECTION":        connection,
-	"CONSISTENT":        consistent,
-	"CONSTRAINT":        constraint,
-	"CONVERT":           convert,
-	"COUNT":             count,
-	"CREATE":            create,
-	"CROSS":             cross,
-	"CURRENT_DATE":      currentDate,
-	"CURRENT_TIME":      currentTime,
-	"CURRENT_TIMESTAMP": currentTs,
-	"CURRENT_USER":      currentUser,
-	"CURTIME":           curTime,
-	"DATA":              data,
-	"DATABASE":          database,
-	"DATABASES":         databases,
-	"DATE":              dateType,
-	"DATE_ADD":          dateAdd,
-	"DATE_SUB":          dateSub,
-	"DATETIME":          datetimeType,
-	"DAY":               day,
-	"DAY_HOUR":          dayHour,
-	"DAY_MICROSECOND":   dayMicrosecond,
-	"DAY_MINUTE":        dayMinute,
-	"DAY_SECOND":        daySecond,
-	"DDL":               ddl,
-	"DEALLOCATE":        deallocate,
-	"DEC":               decimalType,
-	"DECIMAL":           decimalType,
-	"DEFAULT":           defaultKwd,
-	"DEFINER":           definer,
-	"DELAY_KEY_WRITE":   delayKeyWrite,
-	"DELAYED":           delayed,
-	"DELETE":            deleteKwd,
-	"DESC":              desc,
-	"DESCRIBE":          describe,
-	"DISABLE":           disable,
-	"DISTINCT":          distinct,
-	"DISTINCTROW":       distinct,
-	"DIV":               div,
-	"DO":                do,
-	"DOUBLE":            doubleType,
-	"DROP":              drop,
-	"DUAL":              dual,
-	"DUPLICATE":         duplicate,
-	"DYNAMIC":           dynamic,
-	"ELSE":              elseKwd,
-	"ENABLE":            enable,
-	"ENCLOSED":          enclosed,
-	"END":               end,
-	"ENGINE":            engine,
-	"ENGINES":           engines,
-	"ENUM":              enum,
-	"ESCAPE":            escape,
-	"ESCAPED":           escaped,
-	"EVENT":             event,
-	"EVENTS":            events,
-	"EXCLUSIVE":         exclusive,
-	"EXECUTE":           execute,
-	"EXISTS":            exists,
-	"EXPLAIN":           explain,
-	"EXTRACT":           extract,
-	"FALSE":             falseKwd,
-	"FIELDS":            fields,
-	"FIRST":             first,
-	"FIXED":             fixed,
-	"FLOAT":             floatType,
-	"FLUSH":             flush,
-	"FOR":               forKwd,
-	"FORCE":             force,
-	"FOREIGN":           foreign,
-	"FORMAT":            format,
-	"FROM":              from,
-	"FULL":              full,
-	"FULLTEXT":          fulltext,
-	"FUNCTION":          function,
-	"GENERATED":         generated,
-	"GET_FORMAT":        getFormat,
-	"GLOBAL":            global,
-	"GRANT":             grant,
-	"GRANTS":            grants,
-	"GROUP":             group,
-	"GROUP_CONCAT":      groupConcat,
-	"HASH":              hash,
-	"HAVING":            having,
-	"HIGH_PRIORITY":     highPriority,
-	"HOUR":              hour,
-	"HOUR_MICROSECOND":  hourMicrosecond,
-	"HOUR_MINUTE":       hourMinute,
-	"HOUR_SECOND":       hourSecond,
-	"IDENTIFIED":        identified,
-	"IF":                ifKwd,
-	"IGNORE":            ignore,
-	"IN":                in,
-	"INDEX":             index,
-	"INDEXES":           indexes,
-	"INFILE":            infile,
-	"INNER":             inner,
-	"INSERT":            insert,
-	"INT":               intType,
-	"INT1":              int1Type,
-	"INT2":              int2Type,
-	"INT3":              int3Type,
-	"INT4":              int4Type,
-	"INT8":              int8Type,
-	"INTEGER":           integerType,
-	"INTERVAL":          interval,
-	"INTO":              into,
-	"INVOKER":           invoker,
-	"IS":                is,
-	"ISOLATION":         isolation,
-	"JOBS":              jobs,
-	"JOIN":              join,
-	"JSON":              jsonType,
-	"KEY":               key,
-	"KEY_BLOCK_SIZE":    keyBlockSize,
-	"KEYS":              keys,
-	"KILL":              kill,
-	"LEADING":           leading,
-	"LEFT":              left,
-	"LESS":              less,
-	"LEVEL":             level,
-	"LIKE":              like,
-	"LIMIT":             limit,
-	"LINES":             lines,
-	"LOAD":              load,
-	"LOCAL":             local,
-	"LOCALTIME":         localTime,
-	"LOCALTIMESTAMP":    localTs,
-	"LOCK":              lock,
-	"LONGBLOB":          longblobType,
-	"LONGTEXT":          longtextType,
-	"LOW_PRIORITY":      lowPriority,
-	"MAX":               max,
+	"ACTION":                   action,
+	"ADD":                      add,
+	"ADDDATE":                  addDate,
+	"ADMIN":                    admin,
+	"AFTER":                    after,
+	"ALL":                      all,
+	"ALGORITHM":                algorithm,
+	"ALTER":                    alter,
+	"ALWAYS":                   always,
+	"ANALYZE":                  analyze,
+	"AND":                      and,
+	"ANY":                      any,
+	"AS":                       as,
+	"ASC":                      asc,
+	"ASCII":                    ascii,
+	"AUTO_INCREMENT":           autoIncrement,
+	"AVG":                      avg,
+	"AVG_ROW_LENGTH":           avgRowLength,
+	"BEGIN":                    begin,
+	"BETWEEN":                  between,
+	"BIGINT":                   bigIntType,
+	"BINARY":                   binaryType,
+	"BINLOG":                   binlog,
+	"BIT":                      bitType,
+	"BIT_AND":                  bitAnd,
+	"BIT_OR":                   bitOr,
+	"BIT_XOR":                  bitXor,
+	"BLOB":                     blobType,
+	"BOOL":                     boolType,
+	"BOOLEAN":                  booleanType,
+	"BOTH":                     both,
+	"BTREE":                    btree,
+	"BY":                       by,
+	"BYTE":                     byteType,
+	"CALL":                     call,
+	"CANCEL":                   cancel,
+	"CASCADE":                  cascade,
+	"CASCADED":                 cascaded,
+	"CASE":                     caseKwd,
+	"CAST":                     cast,
+	"CHANGE":                   change,
+	"CHAR":                     charType,
+	"CHARACTER":                character,
+	"CHARSET":                  charsetKwd,
+	"CHECK":                    check,
+	"CHECKSUM":                 checksum,
+	"CLEANUP":                  cleanup,
+	"CLIENT":                   client,
+	"CLOSE":                    closeKwd,
+	"COALESCE":                 coalesce,
+	"COLLATE":                  collate,
+	"COLLATION":                collation,
+	"COLUMN":                   column,
+	"COLUMNS":                  columns,
+	"COMMENT":                  comment,
+	"COMMIT":                   commit,
+	"COMMITTED":                committed,
+	"COMPACT":                  compact,
+	"COMPRESSED":               compressed,
+	"COMPRESSION":              compression,
+	"CONNECTION":               connection,
+	"CONSISTENT":               consistent,
+	"CONSTRAINT":               constraint,
+	"CONTAINS":                 contains,
+	"CONTINUE":                 continueKwd,
+	"CONVERT":                  convert,
+	"COUNT":                    count,
+	"CREATE":                   create,
+	"CROSS":                    cross,
+	"CURRENT_DATE":             currentDate,
+	"CURRENT_TIME":             currentTime,
+	"CURRENT_TIMESTAMP":        currentTs,
+	"CURRENT_USER":             currentUser,
+	"CURSOR":                   cursor,
+	"CURTIME":                  curTime,
+	"DATA":                     data,
+	"DATABASE":                 database,
+	"DATABASES":                databases,
+	"DATE":                     dateType,
+	"DATE_ADD":                 dateAdd,
+	"DATE_SUB":                 dateSub,
+	"DATETIME":                 datetimeType,
+	"DAY":                      day,
+	"DAY_HOUR":                 dayHour,
+	"DAY_MICROSECOND":          dayMicrosecond,
+	"DAY_MINUTE":               dayMinute,
+	"DAY_SECOND":               daySecond,
+	"DDL":                      ddl,
+	"DEALLOCATE":               deallocate,
+	"DEC":                      decimalType,
+	"DECIMAL":                  decimalType,
+	"DECLARE":                  declare,
+	"DEFAULT":                  defaultKwd,
+	"DEFINER":                  definer,
+	"DELAY_KEY_WRITE":          delayKeyWrite,
+	"DELAYED":                  delayed,
+	"DELETE":                   deleteKwd,
+	"DESC":                     desc,
+	"DESCRIBE":                 describe,
+	"DETERMINISTIC":            deterministic,
+	"DISABLE":                  disable,
+	"DISTINCT":                 distinct,
+	"DISTINCTROW":              distinct,
+	"DIV":                      div,
+	"DO":                       do,
+	"DOUBLE":                   doubleType,
+	"DROP":                     drop,
+	"DUAL":                     dual,
+	"DUMPFILE":                 dumpfile,
+	"DUPLICATE":                duplicate,
+	"DYNAMIC":                  dynamic,
+	"ELSE":                     elseKwd,
+	"ELSEIF":                   elseIfKwd,
+	"ENABLE":                   enable,
+	"ENCLOSED":                 enclosed,
+	"END":                      end,
+	"ENGINE":                   engine,
+	"ENGINES":                  engines,
+	"ENUM":                     enum,
+	"ESCAPE":                   escape,
+	"ESCAPED":                  escaped,
+	"EVENT":                    event,
+	"EVENTS":                   events,
+	"EXCLUSIVE":                exclusive,
+	"EXECUTE":                  execute,
+	"EXISTS":                   exists,
+	"EXIT":                     exit,
+	"EXPLAIN":                  explain,
+	"EXTRACT":                  extract,
+	"FALSE":                    falseKwd,
+	"FETCH":                    fetch,
+	"FIELDS":                   fields,
+	"FILE":                     file,
+	"FIRST":                    first,
+	"FIXED":                    fixed,
+	"FLASHBACK":                flashback,
+	"FLOAT":                    floatType,
+	"FLUSH":                    flush,
+	"FOR":                      forKwd,
+	"FORCE":                    force,
+	"FOREIGN":                  foreign,
+	"FORMAT":                   format,
+	"FOUND":                    found,
+	"FROM":                     from,
+	"FULL":                     full,
+	"FULLTEXT":                 fulltext,
+	"FUNCTION":                 function,
+	"GENERATED":                generated,
+	"GEOMETRY":                 geometryType,
+	"GET_FORMAT":               getFormat,
+	"GLOBAL":                   global,
+	"GRANT":                    grant,
+	"GRANTS":                   grants,
+	"GROUP":                    group,
+	"GROUP_CONCAT":             groupConcat,
+	"HANDLER":                  handler,
+	"HASH":                     hash,
+	"HAVING":                   having,
+	"HIGH_PRIORITY":            highPriority,
+	"HOUR":                     hour,
+	"HOUR_MICROSECOND":         hourMicrosecond,
+	"HOUR_MINUTE":              hourMinute,
+	"HOUR_SECOND":              hourSecond,
+	"IDENTIFIED":               identified,
+	"IF":                       ifKwd,
+	"IGNORE":                   ignore,
+	"IN":                       in,
+	"INDEX":                    index,
+	"INDEXES":                  indexes,
+	"INFILE":                   infile,
+	"INNER":                    inner,
+	"INOUT":                    inout,
+	"INSERT":                   insert,
+	"INT":                      intType,
+	"INT1":                     int1Type,
+	"INT2":                     int2Type,
+	"INT3":                     int3Type,
+	"INT4":                     int4Type,
+	"INT8":                     int8Type,
+	"INTEGER":                  integerType,
+	"INTERVAL":                 interval,
+	"INTO":                     into,
+	"INVISIBLE":                invisible,
+	"INVOKER":                  invoker,
+	"IS":                       is,
+	"ISOLATION":                isolation,
+	"ITERATE":                  iterate,
+	"JOBS":                     jobs,
+	"JOIN":                     join,
+	"JSON":                     jsonType,
+	"KEY":                      key,
+	"KEY_BLOCK_SIZE":           keyBlockSize,
+	"KEYS":                     keys,
+	"KILL":                     kill,
+	"LANGUAGE":                 language,
+	"LEADING":                  leading,
+	"LEAVE":                    leave,
+	"LEFT":                     left,
+	"LESS":                     less,
+	"LEVEL":                    level,
+	"LIKE":                     like,
+	"LIMIT":                    limit,
+	"LINES":                    lines,
+	"LINESTRING":               lineStringType,
+	"LOAD":                     load,
+	"LOCAL":                    local,
+	"LOCALTIME":                localTime,
+	"LOCALTIMESTAMP":           localTs,
+	"LOCK":                     lock,
+	"LONGBLOB":                 longblobType,
+	"LONGTEXT":                 longtextType,
+	"LOOP":                     loop,
+	"LOW_PRIORITY":             lowPriority,
+	"MAX":                      max,
 	"MAX_CONNECTIONS_PER_HOUR": maxConnectionsPerHour,
 	"MAX_QUERIES_PER_HOUR":     maxQueriesPerHour,
 	"MAX_ROWS":                 maxRows,
@@ -339,11 +363,13 @@ var tokenMap = map[string]int{
 	"MINUTE_SECOND":            minuteSecond,
 	"MOD":                      mod,
 	"MODE":                     mode,
+	"MODIFIES":                 modifies,
 	"MODIFY":                   modify,
 	"MONTH":                    month,
 	"NAMES":                    names,
 	"NATIONAL":                 national,
 	"NATURAL":                  natural,
+	"NEXT":                     next,
 	"NO":                       no,
 	"NO_WRITE_TO_BINLOG":       noWriteToBinLog,
 	"NONE":                     none,
@@ -355,14 +381,19 @@ var tokenMap = map[string]int{
 	"OFFSET":                   offset,
 	"ON":                       on,
 	"ONLY":                     only,
+	"OPEN":                     open,
 	"OPTION":                   option,
 	"OR":                       or,
 	"ORDER":                    order,
+	"OUT":                      out,
 	"OUTER":                    outer,
+	"OUTFILE":                  outfile,
 	"PARTITION":                partition,
 	"PARTITIONS":               partitions,
 	"PASSWORD":                 password,
 	"PLUGINS":                  plugins,
+	"POINT":                    pointType,
+	"POLYGON":                  polygonType,
 	"POSITION":                 position,
 	"PRECISION":                precisionType,
 	"PREPARE":                  prepare,
@@ -377,7 +408,9 @@ var tokenMap = map[string]int{
 	"QUICK":                    quick,
 	"RANGE":                    rangeKwd,
 	"READ":                     read,
+	"READS":                    reads,
 	"REAL":                     realType,
+	"RECOVER":                  recover,
 	"REDUNDANT":                redundant,
 	"REFERENCES":               references,
 	"REGEXP":                   regexpKwd,
@@ -388,6 +421,8 @@ var tokenMap = map[string]int{
 	"REPLACE":                  replace,
 	"REPLICATION":              replication,
 	"RESTRICT":                 restrict,
+	"RETURN":                   returnKwd,
+	"RETURNS":                  returns,
 	"REVERSE":                  reverse,
 	"REVOKE":                   revoke,
 	"RIGHT":                    right,
@@ -397,6 +432,7 @@ var tokenMap = map[string]int{
 	"ROW":                      row,
 	"ROW_COUNT":                rowCount,
 	"ROW_FORMAT":               rowFormat,
+	"ROWS":                     rows,
 	"SCHEMA":                   database,
 	"SCHEMAS":                  databases,
 	"SECOND":                   second,
@@ -415,7 +451,11 @@ var tokenMap = map[string]int{
 	"SMALLINT":                 smallIntType,
 	"SNAPSHOT":                 snapshot,
 	"SOME":                     some,
+	"SONAME":                   soname,
 	"SQL":                      sql,
+	"SQLEXCEPTION":             sqlexception,
+	"SQLSTATE":                 sqlstate,
+	"SQLWARNING":               sqlwarning,
 	"SQL_CACHE":                sqlCache,
 	"SQL_CALC_FOUND_ROWS":      sqlCalcFoundRows,
 	"SQL_NO_CACHE":             sqlNoCache,
@@ -429,6 +469,7 @@ var tokenMap = map[string]int{
 	"STATUS":                   status,
 	"STORED":                   stored,
 	"STRAIGHT_JOIN":            straightJoin,
+	"STRING":                   stringType,
 	"SUBDATE":                  subDate,
 	"SUBSTR":                   substring,
 	"SUBSTRING":                substring,
@@ -468,6 +509,7 @@ var tokenMap = map[string]int{
 	"UNKNOWN":                  unknown,
 	"UNLOCK":                   unlock,
 	"UNSIGNED":                 unsigned,
+	"UNTIL":                    until,
 	"UPDATE":                   update,
 	"USAGE":                    usage,
 	"USE":                      use,
@@ -482,11 +524,13 @@ var tokenMap = map[string]int{
 	"VARCHAR":                  varcharType,
 	"VARIABLES":                variables,
 	"VIEW":                     view,
+	"VISIBLE":                  visible,
 	"VIRTUAL":                  virtual,
 	"WARNINGS":                 warnings,
 	"WEEK":                     week,
 	"WHEN":                     when,
 	"WHERE":                    where,
+	"WHILE":                    while,
 	"WITH":                     with,
 	"WRITE":                    write,
 	"XOR":                      xor,
@@ -497,38 +541,40 @@ var tokenMap = map[string]int{
 
 // See https://dev.mysql.com/doc/refman/5.7/en/function-resolution.html for details
 var btFuncTokenMap = map[string]int{
-	"ADDDATE":      builtinAddDate,
-	"BIT_AND":      builtinBitAnd,
-	"BIT_OR":       builtinBitOr,
-	"BIT_XOR":      builtinBitXor,
-	"CAST":         builtinCast,
-	"COUNT":        builtinCount,
-	"CURDATE":      builtinCurDate,
-	"CURTIME":      builtinCurTime,
-	"DATE_ADD":     builtinDateAdd,
-	"DATE_SUB":     builtinDateSub,
-	"EXTRACT":      builtinExtract,
-	"GROUP_CONCAT": builtinGroupConcat,
-	"MAX":          builtinMax,
-	"MID":          builtinSubstring,
-	"MIN":          builtinMin,
-	"NOW":          builtinNow,
-	"POSITION":     builtinPosition,
-	"SESSION_USER": builtinUser,
-	"STD":          builtinStddevPop,
-	"STDDEV":       builtinStddevPop,
-	"STDDEV_POP":   builtinStddevPop,
-	"STDDEV_SAMP":  builtinVarSamp,
-	"SUBDATE":      builtinSubDate,
-	"SUBSTR":       builtinSubstring,
-	"SUBSTRING":    builtinSubstring,
-	"SUM":          builtinSum,
-	"SYSDATE":      builtinSysDate,
-	"SYSTEM_USER":  builtinUser,
-	"TRIM":         builtinTrim,
-	"VARIANCE":     builtinVarPop,
-	"VAR_POP":      builtinVarPop,
-	"VAR_SAMP":     builtinVarSamp,
+	"ADDDATE":        builtinAddDate,
+	"BIT_AND":        builtinBitAnd,
+	"BIT_OR":         builtinBitOr,
+	"BIT_XOR":        builtinBitXor,
+	"CAST":           builtinCast,
+	"COUNT":          builtinCount,
+	"CURDATE":        builtinCurDate,
+	"CURTIME":        builtinCurTime,
+	"DATE_ADD":       builtinDateAdd,
+	"DATE_SUB":       builtinDateSub,
+	"EXTRACT":        builtinExtract,
+	"GROUP_CONCAT":   builtinGroupConcat,
+	"JSON_ARRAYAGG":  builtinJSONArrayAgg,
+	"JSON_OBJECTAGG": builtinJSONObjectAgg,
+	"MAX":            builtinMax,
+	"MID":            builtinSubstring,
+	"MIN":            builtinMin,
+	"NOW":            builtinNow,
+	"POSITION":       builtinPosition,
+	"SESSION_USER":   builtinUser,
+	"STD":            builtinStddevPop,
+	"STDDEV":         builtinStddevPop,
+	"STDDEV_POP":     builtinStddevPop,
+	"STDDEV_SAMP":    builtinVarSamp,
+	"SUBDATE":        builtinSubDate,
+	"SUBSTR":         builtinSubstring,
+	"SUBSTRING":      builtinSubstring,
+	"SUM":            builtinSum,
+	"SYSDATE":        builtinSysDate,
+	"SYSTEM_USER":    builtinUser,
+	"TRIM":           builtinTrim,
+	"VARIANCE":       builtinVarPop,
+	"VAR_POP":        builtinVarPop,
+	"VAR_SAMP":       builtinVarSamp,
 }
 
 // aliases are strings directly map to another string and use the same token.
@@ -26,6 +26,7 @@
 package parser
 
 import (
+	"math"
 	"strings"
 
 	"github.com/pingcap/tidb/mysql"
@@ -35,6 +36,7 @@ import (
 	"github.com/pingcap/tidb/util/auth"
 	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/types/geo"
 )
 
 %}
@@ -73,6 +75,7 @@ import (
 	blobType		"BLOB"
 	both			"BOTH"
 	by			"BY"
+	call			"CALL"
 	cascade			"CASCADE"
 	caseKwd			"CASE"
 	change        		"CHANGE"
@@ -82,6 +85,7 @@ import (
 	collate 		"COLLATE"
 	column			"COLUMN"
 	constraint		"CONSTRAINT"
+	continueKwd		"CONTINUE"
 	convert			"CONVERT"
 	create			"CREATE"
 	cross 			"CROSS"
@@ -89,6 +93,7 @@ import (
 	currentTime 		"CURRENT_TIME"
 	currentTs		"CURRENT_TIMESTAMP"
 	currentUser		"CURRENT_USER"
+	cursor			"CURSOR"
 	database		"DATABASE"
 	databases		"DATABASES"
 	dayHour			"DAY_HOUR"
@@ -96,11 +101,13 @@ import (
 	dayMinute		"DAY_MINUTE"
 	daySecond 		"DAY_SECOND"
 	decimalType		"DECIMAL"
+	declare			"DECLARE"
 	defaultKwd		"DEFAULT"
 	delayed			"DELAYED"
 	deleteKwd		"DELETE"
 	desc			"DESC"
 	describe		"DESCRIBE"
+	deterministic		"DETERMINISTIC"
 	distinct		"DISTINCT"
 	distinctRow		"DISTINCTROW"
 	div 			"DIV"
@@ -108,11 +115,14 @@ import (
 	drop			"DROP"
 	dual 			"DUAL"
 	elseKwd			"ELSE"
+	elseIfKwd		"ELSEIF"
 	enclosed		"ENCLOSED"
 	escaped 		"ESCAPED"
 	exists			"EXISTS"
+	exit			"EXIT"
 	explain			"EXPLAIN"
 	falseKwd		"FALSE"
+	fetch			"FETCH"
 	floatType		"FLOAT"
 	forKwd			"FOR"
 	force			"FORCE"
@@ -133,10 +143,12 @@ import (
 	index			"INDEX"
 	infile			"INFILE"
 	inner 			"INNER"
+	inout			"INOUT"
 	integerType		"INTEGER"
 	interval		"INTERVAL"
 	into			"INTO"
 	is			"IS"
+	iterate			"ITERATE"
 	insert			"INSERT"
 	intType			"INT"
 	int1Type		"INT1"
@@ -149,6 +161,7 @@ import (
 	keys			"KEYS"
 	kill			"KILL"
 	leading			"LEADING"
+	leave			"LEAVE"
 	left			"LEFT"
 	like			"LIKE"
 	limit			"LIMIT"
@@ -157,6 +170,7 @@ import (
 	localTime		"LOCALTIME"
 	localTs			"LOCALTIMESTAMP"
 	lock			"LOCK"
+	loop			"LOOP"
 	longblobType		"LONGBLOB"
 	longtextType		"LONGTEXT"
 	lowPriority		"LOW_PRIORITY"
@@ -167,6 +181,7 @@ import (
 	minuteMicrosecond	"MINUTE_MICROSECOND"
 	minuteSecond 		"MINUTE_SECOND"
 	mod 			"MOD"
+	modifies		"MODIFIES"
 	not			"NOT"
 	noWriteToBinLog 	"NO_WRITE_TO_BINLOG"
 	null			"NULL"
@@ -176,13 +191,16 @@ import (
 	option			"OPTION"
 	or			"OR"
 	order			"ORDER"
+	out			"OUT"
 	outer			"OUTER"
+	outfile			"OUTFILE"
 	partition		"PARTITION"
 	precisionType		"PRECISION"
 	primary			"PRIMARY"
 	procedure		"PROCEDURE"
 	rangeKwd		"RANGE"
 	read			"READ"
+	reads			"READS"
 	realType		"REAL"
 	references		"REFERENCES"
 	regexpKwd		"REGEXP"
@@ -190,6 +208,7 @@ import (
 	repeat			"REPEAT"
 	replace			"REPLACE"
 	restrict		"RESTRICT"
+	returnKwd		"RETURN"
 	revoke			"REVOKE"
 	right			"RIGHT"
 	rlike			"RLIKE"
@@ -199,6 +218,9 @@ import (
 	show			"SHOW"
 	smallIntType		"SMALLINT"
 	sql			"SQL"
+	sqlexception		"SQLEXCEPTION"
+	sqlstate		"SQLSTATE"
+	sqlwarning		"SQLWARNING"
 	sqlCalcFoundRows	"SQL_CALC_FOUND_ROWS"
 	starting		"STARTING"
 	straightJoin		"STRAIGHT_JOIN"
@@ -217,6 +239,7 @@ import (
 	union			"UNION"
 	unlock			"UNLOCK"
 	unsigned		"UNSIGNED"
+	until			"UNTIL"
 	update			"UPDATE"
 	usage			"USAGE"
 	use			"USE"
@@ -230,6 +253,7 @@ import (
 	virtual			"VIRTUAL"
 	when			"WHEN"
 	where			"WHERE"
+	while			"WHILE"
 	write			"WRITE"
 	with			"WITH"
 	xor 			"XOR"
@@ -258,6 +282,7 @@ import (
 	charsetKwd	"CHARSET"
 	checksum	"CHECKSUM"
 	client		"CLIENT"
+	closeKwd	"CLOSE"
 	coalesce	"COALESCE"
 	collation	"COLLATION"
 	columns		"COLUMNS"
@@ -269,6 +294,7 @@ import (
 	compression	"COMPRESSION"
 	connection 	"CONNECTION"
 	consistent	"CONSISTENT"
+	contains	"CONTAINS"
 	day		"DAY"
 	data 		"DATA"
 	dateType	"DATE"
@@ -278,6 +304,7 @@ import (
 	delayKeyWrite	"DELAY_KEY_WRITE"
 	disable		"DISABLE"
 	do		"DO"
+	dumpfile	"DUMPFILE"
 	duplicate	"DUPLICATE"
 	dynamic		"DYNAMIC"
 	enable		"ENABLE"
@@ -291,24 +318,31 @@ import (
 	exclusive       "EXCLUSIVE"
 	execute		"EXECUTE"
 	fields		"FIELDS"
+	file		"FILE"
 	first		"FIRST"
 	fixed		"FIXED"
 	flush		"FLUSH"
 	format		"FORMAT"
+	found		"FOUND"
 	full		"FULL"
 	function	"FUNCTION"
+	geometryType	"GEOMETRY"
 	grants		"GRANTS"
+	handler		"HANDLER"
 	hash		"HASH"
 	hour		"HOUR"
 	identified	"IDENTIFIED"
 	isolation	"ISOLATION"
 	indexes		"INDEXES"
+	invisible	"INVISIBLE"
 	invoker		"INVOKER"
 	jsonType	"JSON"
 	keyBlockSize	"KEY_BLOCK_SIZE"
+	language	"LANGUAGE"
 	local		"LOCAL"
 	less		"LESS"
 	level		"LEVEL"
+	lineStringType	"LINESTRING"
 	microsecond	"MICROSECOND"
 	minute		"MINUTE"
 	mode		"MODE"
@@ -323,14 +357,18 @@ import (
 	minRows		"MIN_ROWS"
 	names		"NAMES"
 	national	"NATIONAL"
+	next		"NEXT"
 	no		"NO"
 	none		"NONE"
 	offset		"OFFSET"
 	only		"ONLY"
+	open		"OPEN"
 	password	"PASSWORD"
 	partitions	"PARTITIONS"
 	pipesAsOr
 	plugins		"PLUGINS"
+	pointType	"POINT"
+	polygonType	"POLYGON"
 	prepare		"PREPARE"
 	privileges	"PRIVILEGES"
 	process		"PROCESS"
@@ -343,12 +381,14 @@ import (
 	reload		"RELOAD"
 	repeatable	"REPEATABLE"
 	replication	"REPLICATION"
+	returns		"RETURNS"
 	reverse		"REVERSE"
 	rollback	"ROLLBACK"
 	routine		"ROUTINE"
 	row 		"ROW"
 	rowCount	"ROW_COUNT"
 	rowFormat	"ROW_FORMAT"
+	rows		"ROWS"
 	second		"SECOND"
 	security	"SECURITY"
 	separator 	"SEPARATOR"
@@ -359,11 +399,13 @@ import (
 	signed		"SIGNED"
 	slave		"SLAVE"
 	snapshot	"SNAPSHOT"
+	soname		"SONAME"
 	sqlCache	"SQL_CACHE"
 	sqlNoCache	"SQL_NO_CACHE"
 	start		"START"
 	statsPersistent	"STATS_PERSISTENT"
 	status		"STATUS"
+	stringType	"STRING"
 	super		"SUPER"
 	some 		"SOME"
 	global		"GLOBAL"
@@ -384,6 +426,7 @@ import (
 	value		"VALUE"
 	variables	"VARIABLES"
 	view		"VIEW"
+	visible		"VISIBLE"
 	warnings	"WARNINGS"
 	week		"WEEK"
 	yearType	"YEAR"
@@ -415,8 +458,11 @@ import (
 	/* The following tokens belong to TiDBKeyword. */
 	admin		"ADMIN"
 	cancel		"CANCEL"
+	cleanup		"CLEANUP"
 	ddl		"DDL"
+	flashback	"FLASHBACK"
 	jobs		"JOBS"
+	recover		"RECOVER"
 	stats		"STATS"
 	statsMeta       "STATS_META"
 	statsHistograms "STATS_HISTOGRAMS"
@@ -438,6 +484,8 @@ import (
 	builtinDateSub
 	builtinExtract
 	builtinGroupConcat
+	builtinJSONArrayAgg
+	builtinJSONObjectAgg
 	builtinMax
 	builtinMin
 	builtinNow
@@ -485,6 +533,7 @@ import (
 	BitExpr				"bit expression"
 	SimpleExpr			"simple expression"
 	SimpleIdent			"Simple Identifier expression"
+	SelectIntoVar			"variable of SELECT INTO"
 	SumExpr				"aggregate functions"
 	FunctionCallGeneric		"Function call with Identifier"
 	FunctionCallKeyword		"Function call with keyword as function name"
@@ -502,20 +551,26 @@ import (
 
 %type	<statement>
 	AdminStmt			"Check table statement or show ddl statement"
+	AlterDatabaseStmt		"Alter database statement"
 	AlterTableStmt			"Alter table statement"
 	AlterUserStmt			"Alter user statement"
 	AnalyzeTableStmt		"Analyze table statement"
 	BeginTransactionStmt		"BEGIN TRANSACTION statement"
 	BinlogStmt			"Binlog base64 statement"
+	CallStmt			"CALL statement"
 	CommitStmt			"COMMIT statement"
 	CreateTableStmt			"CREATE TABLE statement"
 	CreateViewStmt			"CREATE VIEW  stetement"
 	CreateUserStmt			"CREATE User statement"
 	CreateDatabaseStmt		"Create Database Statement"
+	CreateFunctionStmt		"CREATE FUNCTION statement"
 	CreateIndexStmt			"CREATE INDEX statement"
+	CreateRoutineStmt		"CREATE PROCEDURE/FUNCTION statement"
 	DoStmt				"Do statement"
 	DropDatabaseStmt		"DROP DATABASE statement"
+	DropFunctionStmt		"DROP FUNCTION statement"
 	DropIndexStmt			"DROP INDEX statement"
+	DropProcedureStmt		"DROP PROCEDURE statement"
 	DropStatsStmt			"DROP STATS statement"
 	DropTableStmt			"DROP TABLE statement"
 	DropUserStmt			"DROP USER"
@@ -532,6 +587,14 @@ import (
 	LoadDataStmt			"Load data statement"
 	LockTablesStmt			"Lock tables statement"
 	PreparedStmt			"PreparedStmt"
+	ProcedureBlockStmt		"BEGIN ... END statement in stored routines"
+	ProcedureDecl			"DECLARE statement in stored routines"
+	ProcedureIfStmt			"IF statement in stored routines"
+	ProcedureLoop			"WHILE/REPEAT/LOOP statement in stored routines"
+	ProcedureLoopStmt		"optionally labeled loop statement in stored routines"
+	ProcedureStatement		"statement in stored routines"
+	ProcedureStatementNoUnion	"statement in stored routines except UNION"
+	RecoverTableStmt		"RECOVER TABLE statement"
 	SelectStmt			"SELECT statement"
 	RenameTableStmt         	"rename table statement"
 	ReplaceIntoStmt			"REPLACE INTO statement"
@@ -567,6 +630,10 @@ import (
 	ColumnList			"column list"
 	ColumnNameListOpt		"column name list opt"
 	ColumnNameListOptWithBrackets 	"column name list opt with brackets"
+	ColumnNameOrUserVar		"column name or user variable"
+	ColumnNameOrUserVarList		"column name or user variable list"
+	ColumnNameOrUserVarListOpt	"column name or user variable list opt"
+	ColumnNameOrUserVarListOptWithBrackets	"column name or user variable list opt with brackets"
 	ColumnSetValue			"insert statement set value by column name"
 	ColumnSetValueList		"insert statement set value by column name list"
 	CompareOp			"Compare opcode"
@@ -579,6 +646,8 @@ import (
 	ConstraintKeywordOpt		"Constraint Keyword or empty"
 	CreateIndexStmtUnique		"CREATE INDEX optional UNIQUE clause"
 	CreateTableOptionListOpt	"create table option list opt"
+	CreateTableSelect		"select statement of CREATE TABLE ... SELECT"
+	CreateTableSelectOpt		"optional select statement of CREATE TABLE ... SELECT"
 	DatabaseOption			"CREATE Database specification"
 	DatabaseOptionList		"CREATE Database specification list"
 	DatabaseOptionListOpt		"CREATE Database specification list opt"
@@ -604,6 +673,12 @@ import (
 	FlushOption			"Flush option"
 	TableRefsClause			"Table references clause"
 	FuncDatetimePrec		"Function datetime precision"
+	FunctionParam			"stored function parameter"
+	FunctionParamList		"stored function parameter list"
+	FunctionParamListOpt		"stored function parameter list opt"
+	FunctionReturnType		"Function return type"
+	HandlerCondition		"handler condition"
+	HandlerConditionList		"handler condition list"
 	GlobalScope			"The scope of variable"
 	GroupByClause			"GROUP BY clause"
 	HashString			"Hashed string"
@@ -624,6 +699,7 @@ import (
 	IndexOptionList			"Index Option List or empty"
 	IndexType			"index type"
 	IndexTypeOpt			"Optional index type"
+	IndexVisibility			"index visibility"
 	InsertValues			"Rest part of INSERT/REPLACE INTO statement"
 	JoinTable 			"join table"
 	JoinType			"join type"
@@ -633,6 +709,9 @@ import (
 	LimitOption			"Limit option could be integer or parameter marker."
 	Lines				"Lines clause"
 	LinesTerminated			"Lines terminated by"
+	LoadDataDuplicateOpt		"REPLACE or IGNORE or empty in load data statement"
+	LoadDataIgnoreLines		"IGNORE n LINES or empty in load data statement"
+	LoadDataSetSpecOpt		"SET clause or empty in load data statement"
 	LocalOpt			"Local opt"
 	LockClause         		"Alter table lock clause"
 	LowPriorityOptional		"LOW_PRIORITY or empty"
@@ -659,6 +738,15 @@ import (
 	ColumnPosition			"Column position [First|After ColumnName]"
 	PrepareSQL			"Prepare statement sql string"
 	Priority			"insert statement priority"
+	ProcedureDeclListOpt		"declaration list opt in stored routines"
+	ProcedureElseOpt		"ELSE or ELSEIF of IF statement in stored routines"
+	ProcedureLabelOpt		"label opt in stored routines"
+	ProcedureParam			"stored procedure parameter"
+	ProcedureParamList		"stored procedure parameter list"
+	ProcedureParamListOpt		"stored procedure parameter list opt"
+	ProcedureStmtList		"statement list in stored routines"
+	ProcedureStmtListOpt		"statement list opt in stored routines"
+	ProcedureVarList		"local variable list in stored routines"
 	PrivElem			"Privilege element"
 	PrivElemList			"Privilege element list"
 	PrivLevel			"Privilege scope"
@@ -669,13 +757,17 @@ import (
 	OptGConcatSeparator		"optional GROUP_CONCAT SEPARATOR"
 	ReferOpt			"reference option"
 	ReplacePriority			"replace statement priority"
+	RoutineCharacteristicListOpt	"stored routine characteristic list opt"
 	RowFormat			"Row format option"
 	RowValue			"Row value"
 	SelectLockOpt			"FOR UPDATE or LOCK IN SHARE MODE,"
+	SelectStmtIntoOption		"SELECT statement optional INTO OUTFILE, INTO DUMPFILE or INTO variables clause"
 	SelectStmtCalcFoundRows		"SELECT statement optional SQL_CALC_FOUND_ROWS"
 	SelectStmtSQLCache		"SELECT statement optional SQL_CAHCE/SQL_NO_CACHE"
 	SelectStmtFieldList		"SELECT statement field list"
 	SelectStmtLimit			"SELECT statement optional LIMIT clause"
+	SelectStmtLimitClause		"SELECT statement LIMIT clause"
+	SelectIntoVarList		"SELECT INTO variable list"
 	SelectStmtOpts			"Select statement options"
 	SelectStmtGroup			"SELECT statement optional GROUP BY clause"
 	ShowTargetFilterable    	"Show target that can be filtered by WHERE or LIKE"
@@ -751,6 +843,7 @@ import (
 	BlobType		"Blob types"
 	TextType		"Text types"
 	DateAndTimeType		"Date and Time types"
+	SpatialType		"Spatial types"
 
 	OptFieldLen		"Field length or empty"
 	FieldLen		"Field length"
@@ -763,6 +856,9 @@ import (
 	OptCollate		"Optional Collate setting"
 	NUM			"A number"
 	NumList			"Some numbers"
+	HandleRange		"Handle range"
+	HandleRangeList		"Handle range list"
+	SignedNum		"Signed number"
 	LengthNum		"Field length num(uint64)"
 	HintTableList		"Table list in optimizer hint"
 	TableOptimizerHintOpt	"Table level optimizer hint"
@@ -864,6 +960,24 @@ import (
 Start:
 	StatementList
 
+/*******************************************************************
+ *
+ *  Alter Database Statement
+ *  ALTER {DATABASE | SCHEMA} db_name
+ *      alter_specification ...
+ *
+ *  alter_specification:
+ *      [DEFAULT] CHARACTER SET [=] charset_name
+ *    | [DEFAULT] COLLATE [=] collation_name
+ *******************************************************************/
+AlterDatabaseStmt:
+	"ALTER" DatabaseSym DBName DatabaseOptionList
+	{
+		$$ = &ast.AlterDatabaseStmt{
+			Name:		$3.(string),
+			Options:	$4.([]*ast.DatabaseOption),
+		}
+	}
 /**************************************AlterTableStmt***************************************
  * See https://dev.mysql.com/doc/refman/5.7/en/alter-table.html
  *******************************************************************************************/
@@ -932,6 +1046,13 @@ AlterTableSpec:
 			Name: $4.(string),
 		}
 	}
+|	"DROP" "CHECK" Symbol
+	{
+		$$ = &ast.AlterTableSpec{
+			Tp: ast.AlterTableDropCheck,
+			Name: $3.(string),
+		}
+	}
 |	"DISABLE" "KEYS"
 	{
 		$$ = &ast.AlterTableSpec{}
@@ -1000,6 +1121,33 @@ AlterTableSpec:
 			NewTable:      $3.(*ast.TableName),
 		}
 	}
+|	"RENAME" KeyOrIndex Identifier "TO" Identifier
+	{
+		$$ = &ast.AlterTableSpec{
+			Tp:		ast.AlterTableRenameIndex,
+			FromKey:	model.NewCIStr($3),
+			ToKey:		model.NewCIStr($5),
+		}
+	}
+|	"ALTER" "INDEX" Identifier IndexVisibility
+	{
+		$$ = &ast.AlterTableSpec{
+			Tp:		ast.AlterTableIndexVisibility,
+			Name:		$3,
+			Visibility:	$4.(ast.IndexVisibility),
+		}
+	}
+|	"CONVERT" "TO" CharsetKw CharsetName OptCollate
+	{
+		options := []*ast.TableOption{{Tp: ast.TableOptionCharset, StrValue: $4.(string)}}
+		if $5 != "" {
+			options = append(options, &ast.TableOption{Tp: ast.TableOptionCollate, StrValue: $5.(string)})
+		}
+		$$ = &ast.AlterTableSpec{
+			Tp:		ast.AlterTableConvertCharset,
+			Options:	options,
+		}
+	}
 |	LockClause
 	{
 		$$ = &ast.AlterTableSpec{
@@ -1174,6 +1322,18 @@ BinlogStmt:
 		$$ = &ast.BinlogStmt{Str: $2}
 	}
 
+CallStmt:
+	"CALL" TableName
+	{
+		name := $2.(*ast.TableName)
+		$$ = &ast.CallStmt{Schema: name.Schema, Name: name.Name}
+	}
+|	"CALL" TableName '(' ExpressionListOpt ')'
+	{
+		name := $2.(*ast.TableName)
+		$$ = &ast.CallStmt{Schema: name.Schema, Name: name.Name, Args: $4.([]ast.ExprNode)}
+	}
+
 ColumnDefList:
 	ColumnDef
 	{
@@ -1287,9 +1447,11 @@ ColumnOption:
 	}
 |	"CHECK" '(' Expression ')'
 	{
-		// See https://dev.mysql.com/doc/refman/5.7/en/create-table.html
-		// The CHECK clause is parsed but ignored by all storage engines.
-		$$ = &ast.ColumnOption{}
+		startOffset := parser.startOffset(&yyS[yypt-1])
+		endOffset := parser.endOffset(&yyS[yypt])
+		expr := $3
+		expr.SetText(parser.src[startOffset:endOffset])
+		$$ = &ast.ColumnOption{Tp: ast.ColumnOptionCheck, Expr: expr}
 	}
 |	GeneratedAlways "AS" '(' Expression ')' VirtualOrStored
 	{
@@ -1414,6 +1576,17 @@ ConstraintElem:
 			Refer:	$7.(*ast.ReferenceDef),
 		}
 	}
+|	"CHECK" '(' Expression ')'
+	{
+		startOffset := parser.startOffset(&yyS[yypt-1])
+		endOffset := parser.endOffset(&yyS[yypt])
+		expr := $3
+		expr.SetText(parser.src[startOffset:endOffset])
+		$$ = &ast.Constraint{
+			Tp:	ast.ConstraintCheck,
+			Expr:	expr,
+		}
+	}
 
 ReferDef:
 	"REFERENCES" TableName '(' IndexColNameList ')' OnDeleteOpt OnUpdateOpt
@@ -1569,6 +1742,10 @@ IndexColName:
 		//Order is parsed but just ignored as MySQL did
 		$$ = &ast.IndexColName{Column: $1.(*ast.ColumnName), Length: $2.(int)}
 	}
+|	'(' Expression ')' Order
+	{
+		$$ = &ast.IndexColName{Expr: $2, Length: types.UnspecifiedLength}
+	}
 
 IndexColNameList:
 	IndexColName
@@ -1650,7 +1827,7 @@ DatabaseOptionList:
  *      )
  *******************************************************************/
 CreateTableStmt:
-	"CREATE" "TABLE" IfNotExists TableName '(' TableElementList ')' CreateTableOptionListOpt PartitionOpt
+	"CREATE" "TABLE" IfNotExists TableName '(' TableElementList ')' CreateTableOptionListOpt PartitionOpt CreateTableSelectOpt
 	{
 		tes := $6.([]interface {})
 		var columnDefs []*ast.ColumnDef
@@ -1663,17 +1840,30 @@ CreateTableStmt:
 				constraints = append(constraints, te)
 			}
 		}
-		if len(columnDefs) == 0 {
+		if len(columnDefs) == 0 && $10 == nil {
 			yylex.Errorf("Column Definition List can't be empty.")
 			return 1
 		}
-		$$ = &ast.CreateTableStmt{
+		stmt := &ast.CreateTableStmt{
 			Table:          $4.(*ast.TableName),
 			IfNotExists:    $3.(bool),
 			Cols:           columnDefs,
 			Constraints:    constraints,
 			Options:        $8.([]*ast.TableOption),
 		}
+		if $10 != nil {
+			stmt.Select = $10.(ast.ResultSetNode)
+		}
+		$$ = stmt
+	}
+|	"CREATE" "TABLE" IfNotExists TableName CreateTableOptionListOpt CreateTableSelect
+	{
+		$$ = &ast.CreateTableStmt{
+			Table:          $4.(*ast.TableName),
+			IfNotExists:    $3.(bool),
+			Options:        $5.([]*ast.TableOption),
+			Select:         $6.(ast.ResultSetNode),
+		}
 	}
 |	"CREATE" "TABLE" IfNotExists TableName "LIKE" TableName
 	{
@@ -1684,6 +1874,33 @@ CreateTableStmt:
 		}
 	}
 
+CreateTableSelectOpt:
+	{
+		$$ = nil
+	}
+|	CreateTableSelect
+	{
+		$$ = $1
+	}
+
+CreateTableSelect:
+	SelectStmt
+	{
+		$$ = $1
+	}
+|	"AS" SelectStmt
+	{
+		$$ = $2
+	}
+|	UnionStmt
+	{
+		$$ = $1
+	}
+|	"AS" UnionStmt
+	{
+		$$ = $2
+	}
+
 DefaultKwdOpt:
 	{}
 |	"DEFAULT"
@@ -1705,33 +1922,488 @@ PartitionNumOpt:
 	{}
 
 PartitionDefinitionListOpt:
+	%prec empty
 	{}
 |	'(' PartitionDefinitionList ')'
 	{}
 
-PartitionDefinitionList:
-	PartitionDefinition
-	{}
-|	PartitionDefinitionList ',' PartitionDefinition
-	{}
+PartitionDefinitionList:
+	PartitionDefinition
+	{}
+|	PartitionDefinitionList ',' PartitionDefinition
+	{}
+
+PartitionDefinition:
+	"PARTITION" Identifier PartDefValuesOpt PartDefStorageOpt
+	{}
+
+PartDefValuesOpt:
+	{}
+|	"VALUES" "LESS" "THAN" "MAXVALUE"
+	{}
+|	"VALUES" "LESS" "THAN" '(' "MAXVALUE" ')'
+	{}
+|	"VALUES" "LESS" "THAN" '(' ExpressionList ')'
+	{}
+
+PartDefStorageOpt:
+	{}
+|	"ENGINE" eq Identifier
+	{}
+
+/*******************************************************************
+ *
+ *  Create Function Statement
+ *
+ *  Example:
+ *      CREATE FUNCTION metaphon RETURNS STRING SONAME 'udf_example.so'
+ *
+ *  See https://dev.mysql.com/doc/refman/5.7/en/create-function-udf.html
+ *******************************************************************/
+CreateFunctionStmt:
+	"CREATE" OrReplace ViewAlgorithm ViewDefiner "FUNCTION" Identifier "RETURNS" FunctionReturnType "SONAME" stringLit
+	{
+		// The prefix is shared with CREATE VIEW and CREATE PROCEDURE to avoid conflicts, but none of it is allowed.
+		if $2.(bool) || $3.(string) != "UNDEFINED" || $4 != nil {
+			yylex.Errorf("")
+			return 1
+		}
+		$$ = &ast.CreateFunctionStmt{
+			FunctionName:	model.NewCIStr($6),
+			ReturnType:	$8.(types.EvalType),
+			SharedLib:	$10,
+		}
+	}
+
+FunctionReturnType:
+	"STRING"
+	{
+		$$ = types.ETString
+	}
+|	"INTEGER"
+	{
+		$$ = types.ETInt
+	}
+|	"REAL"
+	{
+		$$ = types.ETReal
+	}
+|	"DECIMAL"
+	{
+		$$ = types.ETDecimal
+	}
+
+/*******************************************************************
+ *
+ *  Create Procedure/Function Statement
+ *
+ *  Example:
+ *      CREATE PROCEDURE p(IN a INT, OUT b INT) BEGIN SELECT a INTO b; END
+ *      CREATE FUNCTION f(a INT) RETURNS INT DETERMINISTIC RETURN a + 1
+ *
+ *  See https://dev.mysql.com/doc/refman/5.7/en/create-procedure.html
+ *******************************************************************/
+CreateRoutineStmt:
+	"CREATE" OrReplace ViewAlgorithm ViewDefiner "PROCEDURE" TableName '(' ProcedureParamListOpt ')' RoutineCharacteristicListOpt ProcedureStatement
+	{
+		if $2.(bool) || $3.(string) != "UNDEFINED" {
+			yylex.Errorf("")
+			return 1
+		}
+		name := $6.(*ast.TableName)
+		x := $10.(*ast.CreateRoutineStmt)
+		x.Tp = ast.RoutineProcedure
+		x.Schema, x.Name = name.Schema, name.Name
+		x.Params = $8.([]*ast.RoutineParam)
+		x.ParamsText = parser.src[yyS[yypt-4].offset+1:yyS[yypt-2].offset]
+		x.Body = $11
+		x.BodyText = parser.src[parser.startOffset(&yyS[yypt]):parser.endOffset(&parser.yylval)]
+		if $4 != nil {
+			x.Definer = $4.(*auth.UserIdentity)
+		}
+		$$ = x
+	}
+|	"CREATE" OrReplace ViewAlgorithm ViewDefiner "FUNCTION" TableName '(' FunctionParamListOpt ')' "RETURNS" Type RoutineCharacteristicListOpt ProcedureStatementNoUnion
+	{
+		if $2.(bool) || $3.(string) != "UNDEFINED" {
+			yylex.Errorf("")
+			return 1
+		}
+		name := $6.(*ast.TableName)
+		x := $12.(*ast.CreateRoutineStmt)
+		x.Tp = ast.RoutineFunction
+		x.Schema, x.Name = name.Schema, name.Name
+		x.Params = $8.([]*ast.RoutineParam)
+		x.ParamsText = parser.src[yyS[yypt-6].offset+1:yyS[yypt-4].offset]
+		x.ReturnType = $11.(*types.FieldType)
+		x.Body = $13
+		x.BodyText = parser.src[parser.startOffset(&yyS[yypt]):parser.endOffset(&parser.yylval)]
+		if $4 != nil {
+			x.Definer = $4.(*auth.UserIdentity)
+		}
+		$$ = x
+	}
+
+ProcedureParamListOpt:
+	{
+		$$ = []*ast.RoutineParam{}
+	}
+|	ProcedureParamList
+
+ProcedureParamList:
+	ProcedureParam
+	{
+		$$ = []*ast.RoutineParam{$1.(*ast.RoutineParam)}
+	}
+|	ProcedureParamList ',' ProcedureParam
+	{
+		$$ = append($1.([]*ast.RoutineParam), $3.(*ast.RoutineParam))
+	}
+
+ProcedureParam:
+	FunctionParam
+|	"IN" FunctionParam
+	{
+		$$ = $2
+	}
+|	"OUT" FunctionParam
+	{
+		x := $2.(*ast.RoutineParam)
+		x.Mode = ast.ParamOut
+		$$ = x
+	}
+|	"INOUT" FunctionParam
+	{
+		x := $2.(*ast.RoutineParam)
+		x.Mode = ast.ParamInOut
+		$$ = x
+	}
+
+FunctionParamListOpt:
+	{
+		$$ = []*ast.RoutineParam{}
+	}
+|	FunctionParamList
+
+FunctionParamList:
+	FunctionParam
+	{
+		$$ = []*ast.RoutineParam{$1.(*ast.RoutineParam)}
+	}
+|	FunctionParamList ',' FunctionParam
+	{
+		$$ = append($1.([]*ast.RoutineParam), $3.(*ast.RoutineParam))
+	}
+
+FunctionParam:
+	Identifier Type
+	{
+		$$ = &ast.RoutineParam{Mode: ast.ParamIn, Name: $1, Tp: $2.(*types.FieldType)}
+	}
+
+RoutineCharacteristicListOpt:
+	{
+		$$ = &ast.CreateRoutineStmt{DataAccess: ast.ContainsSQL, Security: "DEFINER"}
+	}
+|	RoutineCharacteristicListOpt "COMMENT" stringLit
+	{
+		x := $1.(*ast.CreateRoutineStmt)
+		x.Comment = $3
+		$$ = x
+	}
+|	RoutineCharacteristicListOpt "LANGUAGE" "SQL"
+	{
+		$$ = $1
+	}
+|	RoutineCharacteristicListOpt "DETERMINISTIC"
+	{
+		x := $1.(*ast.CreateRoutineStmt)
+		x.Deterministic = true
+		$$ = x
+	}
+|	RoutineCharacteristicListOpt "NOT" "DETERMINISTIC"
+	{
+		x := $1.(*ast.CreateRoutineStmt)
+		x.Deterministic = false
+		$$ = x
+	}
+|	RoutineCharacteristicListOpt "CONTAINS" "SQL"
+	{
+		x := $1.(*ast.CreateRoutineStmt)
+		x.DataAccess = ast.ContainsSQL
+		$$ = x
+	}
+|	RoutineCharacteristicListOpt "NO" "SQL"
+	{
+		x := $1.(*ast.CreateRoutineStmt)
+		x.DataAccess = ast.NoSQL
+		$$ = x
+	}
+|	RoutineCharacteristicListOpt "READS" "SQL" "DATA"
+	{
+		x := $1.(*ast.CreateRoutineStmt)
+		x.DataAccess = ast.ReadsSQLData
+		$$ = x
+	}
+|	RoutineCharacteristicListOpt "MODIFIES" "SQL" "DATA"
+	{
+		x := $1.(*ast.CreateRoutineStmt)
+		x.DataAccess = ast.ModifiesSQLData
+		$$ = x
+	}
+|	RoutineCharacteristicListOpt "SQL" "SECURITY" "DEFINER"
+	{
+		x := $1.(*ast.CreateRoutineStmt)
+		x.Security = "DEFINER"
+		$$ = x
+	}
+|	RoutineCharacteristicListOpt "SQL" "SECURITY" "INVOKER"
+	{
+		x := $1.(*ast.CreateRoutineStmt)
+		x.Security = "INVOKER"
+		$$ = x
+	}
+
+/* ProcedureStatement is a statement in the body of a stored routine. */
+ProcedureStatement:
+	ProcedureStatementNoUnion
+|	UnionStmt
+
+/*
+ * A stored function can't return a result set, so the body of a stored function
+ * isn't a UNION, which conflicts with the length of the return type.
+ */
+ProcedureStatementNoUnion:
+	AlterTableStmt
+|	AnalyzeTableStmt
+|	CallStmt
+|	CreateIndexStmt
+|	CreateTableStmt
+|	DeleteFromStmt
+|	DoStmt
+|	DropIndexStmt
+|	DropTableStmt
+|	InsertIntoStmt
+|	ReplaceIntoStmt
+|	SelectStmt
+|	SetStmt
+|	TruncateTableStmt
+|	UpdateStmt
+|	ProcedureBlockStmt
+|	ProcedureIfStmt
+|	ProcedureLoopStmt
+|	"LEAVE" Identifier
+	{
+		$$ = &ast.LeaveStmt{Label: $2}
+	}
+|	"ITERATE" Identifier
+	{
+		$$ = &ast.IterateStmt{Label: $2}
+	}
+|	"RETURN" Expression
+	{
+		$$ = &ast.ReturnStmt{Expr: $2}
+	}
+|	"OPEN" Identifier
+	{
+		$$ = &ast.OpenCursorStmt{Name: $2}
+	}
+|	"CLOSE" Identifier
+	{
+		$$ = &ast.CloseCursorStmt{Name: $2}
+	}
+|	"FETCH" Identifier "INTO" ProcedureVarList
+	{
+		$$ = &ast.FetchCursorStmt{Name: $2, Vars: $4.([]string)}
+	}
+|	"FETCH" "FROM" Identifier "INTO" ProcedureVarList
+	{
+		$$ = &ast.FetchCursorStmt{Name: $3, Vars: $5.([]string)}
+	}
+|	"FETCH" "NEXT" "FROM" Identifier "INTO" ProcedureVarList
+	{
+		$$ = &ast.FetchCursorStmt{Name: $4, Vars: $6.([]string)}
+	}
+
+ProcedureStmtList:
+	ProcedureStatement ';'
+	{
+		$$ = []ast.StmtNode{$1}
+	}
+|	ProcedureStmtList ProcedureStatement ';'
+	{
+		$$ = append($1.([]ast.StmtNode), $2)
+	}
+
+ProcedureVarList:
+	Identifier
+	{
+		$$ = []string{$1}
+	}
+|	ProcedureVarList ',' Identifier
+	{
+		$$ = append($1.([]string), $3)
+	}
+
+ProcedureBlockStmt:
+	"BEGIN" ProcedureDeclListOpt ProcedureStmtListOpt "END"
+	{
+		$$ = &ast.BlockStmt{Stmts: append($2.([]ast.StmtNode), $3.([]ast.StmtNode)...)}
+	}
+|	identifier ':' "BEGIN" ProcedureDeclListOpt ProcedureStmtListOpt "END" ProcedureLabelOpt
+	{
+		if !checkEndLabel(yylex, $1, $7.(string)) {
+			return 1
+		}
+		$$ = &ast.BlockStmt{Label: $1, Stmts: append($4.([]ast.StmtNode), $5.([]ast.StmtNode)...)}
+	}
+
+/* Only the identifiers which aren't keywords can be labels, otherwise they conflict with the statements. */
+ProcedureLabelOpt:
+	{
+		$$ = ""
+	}
+|	identifier
+	{
+		$$ = $1
+	}
+
+ProcedureDeclListOpt:
+	{
+		$$ = []ast.StmtNode{}
+	}
+|	ProcedureDeclListOpt ProcedureDecl ';'
+	{
+		$$ = append($1.([]ast.StmtNode), $2)
+	}
+
+ProcedureStmtListOpt:
+	{
+		$$ = []ast.StmtNode{}
+	}
+|	ProcedureStmtList
+
+/*
+ * The declarations must be in the order of variables, cursors and handlers, which is checked by the executor.
+ * See https://dev.mysql.com/doc/refman/5.7/en/declare.html
+ */
+ProcedureDecl:
+	"DECLARE" ProcedureVarList Type
+	{
+		$$ = &ast.DeclareVarStmt{Names: $2.([]string), Tp: $3.(*types.FieldType)}
+	}
+|	"DECLARE" ProcedureVarList Type "DEFAULT" Expression
+	{
+		$$ = &ast.DeclareVarStmt{Names: $2.([]string), Tp: $3.(*types.FieldType), Default: $5}
+	}
+|	"DECLARE" Identifier "CURSOR" "FOR" SelectStmt
+	{
+		$$ = &ast.DeclareCursorStmt{Name: $2, Select: $5}
+	}
+|	"DECLARE" Identifier "CURSOR" "FOR" UnionStmt
+	{
+		$$ = &ast.DeclareCursorStmt{Name: $2, Select: $5}
+	}
+|	"DECLARE" "CONTINUE" "HANDLER" "FOR" HandlerConditionList ProcedureStatement
+	{
+		$$ = &ast.DeclareHandlerStmt{Action: ast.HandlerContinue, Conditions: $5.([]*ast.HandlerCondition), Stmt: $6}
+	}
+|	"DECLARE" "EXIT" "HANDLER" "FOR" HandlerConditionList ProcedureStatement
+	{
+		$$ = &ast.DeclareHandlerStmt{Action: ast.HandlerExit, Conditions: $5.([]*ast.HandlerCondition), Stmt: $6}
+	}
+
+HandlerConditionList:
+	HandlerCondition
+	{
+		$$ = []*ast.HandlerCondition{$1.(*ast.HandlerCondition)}
+	}
+|	HandlerConditionList ',' HandlerCondition
+	{
+		$$ = append($1.([]*ast.HandlerCondition), $3.(*ast.HandlerCondition))
+	}
+
+HandlerCondition:
+	NUM
+	{
+		code := getUint64FromNUM($1)
+		if code == 0 || code > math.MaxUint16 {
+			yylex.Errorf("Incorrect error code %d", code)
+			return 1
+		}
+		$$ = &ast.HandlerCondition{Tp: ast.HandlerErrorCode, ErrorCode: uint16(code)}
+	}
+|	"SQLSTATE" stringLit
+	{
+		$$ = &ast.HandlerCondition{Tp: ast.HandlerSQLState, SQLState: $2}
+	}
+|	"SQLSTATE" "VALUE" stringLit
+	{
+		$$ = &ast.HandlerCondition{Tp: ast.HandlerSQLState, SQLState: $3}
+	}
+|	"SQLWARNING"
+	{
+		$$ = &ast.HandlerCondition{Tp: ast.HandlerSQLWarning}
+	}
+|	"NOT" "FOUND"
+	{
+		$$ = &ast.HandlerCondition{Tp: ast.HandlerNotFound}
+	}
+|	"SQLEXCEPTION"
+	{
+		$$ = &ast.HandlerCondition{Tp: ast.HandlerSQLException}
+	}
+
+ProcedureIfStmt:
+	"IF" Expression "THEN" ProcedureStmtList ProcedureElseOpt "END" "IF"
+	{
+		$$ = &ast.IfStmt{Cond: $2, Then: $4.([]ast.StmtNode), Else: $5.([]ast.StmtNode)}
+	}
 
-PartitionDefinition:
-	"PARTITION" Identifier PartDefValuesOpt PartDefStorageOpt
-	{}
+ProcedureElseOpt:
+	{
+		$$ = []ast.StmtNode{}
+	}
+|	"ELSE" ProcedureStmtList
+	{
+		$$ = $2
+	}
+|	"ELSEIF" Expression "THEN" ProcedureStmtList ProcedureElseOpt
+	{
+		$$ = []ast.StmtNode{&ast.IfStmt{Cond: $2, Then: $4.([]ast.StmtNode), Else: $5.([]ast.StmtNode)}}
+	}
 
-PartDefValuesOpt:
-	{}
-|	"VALUES" "LESS" "THAN" "MAXVALUE"
-	{}
-|	"VALUES" "LESS" "THAN" '(' "MAXVALUE" ')'
-	{}
-|	"VALUES" "LESS" "THAN" '(' ExpressionList ')'
-	{}
+ProcedureLoopStmt:
+	ProcedureLoop
+|	identifier ':' ProcedureLoop ProcedureLabelOpt
+	{
+		if !checkEndLabel(yylex, $1, $4.(string)) {
+			return 1
+		}
+		switch x := $3.(type) {
+		case *ast.WhileStmt:
+			x.Label = $1
+		case *ast.RepeatStmt:
+			x.Label = $1
+		case *ast.LoopStmt:
+			x.Label = $1
+		}
+		$$ = $3
+	}
 
-PartDefStorageOpt:
-	{}
-|	"ENGINE" eq Identifier
-	{}
+ProcedureLoop:
+	"WHILE" Expression "DO" ProcedureStmtList "END" "WHILE"
+	{
+		$$ = &ast.WhileStmt{Cond: $2, Body: $4.([]ast.StmtNode)}
+	}
+|	"REPEAT" ProcedureStmtList "UNTIL" Expression "END" "REPEAT"
+	{
+		$$ = &ast.RepeatStmt{Body: $2.([]ast.StmtNode), Until: $4}
+	}
+|	"LOOP" ProcedureStmtList "END" "LOOP"
+	{
+		$$ = &ast.LoopStmt{Body: $2.([]ast.StmtNode)}
+	}
 
 /*******************************************************************
  *
@@ -1970,6 +2642,20 @@ DropStatsStmt:
 		$$ = &ast.DropStatsStmt{Table: $3.(*ast.TableName)}
 	}
 
+DropFunctionStmt:
+	"DROP" "FUNCTION" IfExists TableName
+	{
+		name := $4.(*ast.TableName)
+		$$ = &ast.DropFunctionStmt{IfExists: $3.(bool), Schema: name.Schema, FunctionName: name.Name}
+	}
+
+DropProcedureStmt:
+	"DROP" "PROCEDURE" IfExists TableName
+	{
+		name := $4.(*ast.TableName)
+		$$ = &ast.DropProcedureStmt{IfExists: $3.(bool), Schema: name.Schema, Name: name.Name}
+	}
+
 TableOrTables:
 	"TABLE"
 |	"TABLES"
@@ -2482,6 +3168,16 @@ IndexTypeOpt:
 		$$ = $1
 	}
 
+IndexVisibility:
+	"VISIBLE"
+	{
+		$$ = ast.IndexVisibilityVisible
+	}
+|	"INVISIBLE"
+	{
+		$$ = ast.IndexVisibilityInvisible
+	}
+
 /**********************************Identifier********************************************/
 Identifier:
 identifier | UnReservedKeyword | NotKeywordToken | TiDBKeyword
@@ -2499,10 +3195,12 @@ UnReservedKeyword:
 | "SQL_NO_CACHE" | "DISABLE"  | "ENABLE" | "REVERSE" | "PRIVILEGES" | "NO" | "BINLOG" | "FUNCTION" | "VIEW" | "MODIFY" | "EVENTS" | "PARTITIONS"
 | "NONE" | "SUPER" | "EXCLUSIVE" | "STATS_PERSISTENT" | "ROW_COUNT" | "COALESCE" | "MONTH" | "PROCESS" | "PROFILES"
 | "MICROSECOND" | "MINUTE" | "PLUGINS" | "QUERY" | "SECOND" | "SEPARATOR" | "SHARE" | "SHARED" | "MAX_CONNECTIONS_PER_HOUR" | "MAX_QUERIES_PER_HOUR" | "MAX_UPDATES_PER_HOUR"
-| "MAX_USER_CONNECTIONS" | "REPLICATION" | "CLIENT" | "SLAVE" | "RELOAD" | "TEMPORARY" | "ROUTINE" | "EVENT" | "ALGORITHM" | "DEFINER" | "INVOKER" | "MERGE" | "TEMPTABLE" | "UNDEFINED" | "SECURITY" | "CASCADED"
+| "MAX_USER_CONNECTIONS" | "REPLICATION" | "CLIENT" | "SLAVE" | "RELOAD" | "TEMPORARY" | "ROUTINE" | "EVENT" | "ALGORITHM" | "DEFINER" | "INVOKER" | "MERGE" | "TEMPTABLE" | "UNDEFINED" | "SECURITY" | "CASCADED" | "VISIBLE" | "INVISIBLE"
+| "DUMPFILE" | "FILE" | "ROWS" | "GEOMETRY" | "POINT" | "LINESTRING" | "POLYGON" | "RETURNS" | "SONAME" | "STRING"
+| "CLOSE" | "CONTAINS" | "FOUND" | "HANDLER" | "LANGUAGE" | "NEXT" | "OPEN"
 
 TiDBKeyword:
-"ADMIN" | "CANCEL" | "DDL" | "JOBS" | "STATS" | "STATS_META" | "STATS_HISTOGRAMS" | "STATS_BUCKETS" | "TIDB" | "TIDB_HJ" | "TIDB_SMJ" | "TIDB_INLJ"
+"ADMIN" | "CANCEL" | "CLEANUP" | "DDL" | "FLASHBACK" | "JOBS" | "RECOVER" | "STATS" | "STATS_META" | "STATS_HISTOGRAMS" | "STATS_BUCKETS" | "TIDB" | "TIDB_HJ" | "TIDB_SMJ" | "TIDB_INLJ"
 
 NotKeywordToken:
  "ADDDATE" | "BIT_AND" | "BIT_OR" | "BIT_XOR" | "CAST" | "COUNT" | "CURTIME" | "DATE_ADD" | "DATE_SUB" | "EXTRACT" | "GET_FORMAT" | "GROUP_CONCAT" | "MIN" | "MAX" | "NOW" | "POSITION"
@@ -2920,8 +3618,7 @@ SimpleExpr:
 |	FunctionCallGeneric
 |	SimpleExpr "COLLATE" StringName %prec neg
 	{
-		// TODO: Create a builtin function hold expr and collation. When do evaluation, convert expr result using the collation.
-		$$ = $1
+		$$ = &ast.SetCollationExpr{Expr: $1.(ast.ExprNode), Collate: $3.(string)}
 	}
 |	Literal
 |	paramMarker
@@ -3113,10 +3810,13 @@ FunctionNameConflict:
 |	"INTERVAL" %prec lowerThanIntervalKeyword
 |	"FORMAT"
 |	"LEFT"
+|	"LINESTRING"
 |	"MICROSECOND"
 |	"MINUTE"
 |	"MONTH"
 |	builtinNow
+|	"POINT"
+|	"POLYGON"
 |	"QUARTER"
 |	"REPEAT"
 |	"REPLACE"
@@ -3436,6 +4136,14 @@ SumExpr:
 		args = append(args, $5.(ast.ExprNode))
 		$$ = &ast.AggregateFuncExpr{F: $1, Args: args, Distinct: $3.(bool)}
 	}
+|	builtinJSONArrayAgg '(' Expression ')'
+	{
+		$$ = &ast.AggregateFuncExpr{F: $1, Args: []ast.ExprNode{$3}}
+	}
+|	builtinJSONObjectAgg '(' Expression ',' Expression ')'
+	{
+		$$ = &ast.AggregateFuncExpr{F: $1, Args: []ast.ExprNode{$3, $5}}
+	}
 |	builtinMax '(' BuggyDefaultFalseDistinctOpt Expression ')'
 	{
 		$$ = &ast.AggregateFuncExpr{F: $1, Args: []ast.ExprNode{$4}, Distinct: $3.(bool)}
@@ -3880,7 +4588,7 @@ RollbackStmt:
 	}
 
 SelectStmt:
-	"SELECT" SelectStmtOpts SelectStmtFieldList SelectStmtLimit SelectLockOpt
+	"SELECT" SelectStmtOpts SelectStmtFieldList SelectStmtIntoOption SelectLockOpt
 	{
 		st := &ast.SelectStmt {
 			SelectStmtOpts: $2.(*ast.SelectStmtOpts),
@@ -3905,42 +4613,70 @@ SelectStmt:
 			lastField.SetText(src[lastField.Offset:lastEnd])
 		}
 		if $4 != nil {
-			st.Limit = $4.(*ast.Limit)
+			st.SelectIntoOpt = $4.(*ast.SelectIntoOption)
 		}
 		$$ = st
 	}
-|	"SELECT" SelectStmtOpts SelectStmtFieldList FromDual WhereClauseOptional SelectStmtLimit SelectLockOpt
+|	"SELECT" SelectStmtOpts SelectStmtFieldList SelectStmtLimitClause SelectStmtIntoOption SelectLockOpt
 	{
 		st := &ast.SelectStmt {
 			SelectStmtOpts: $2.(*ast.SelectStmtOpts),
 			Distinct:      $2.(*ast.SelectStmtOpts).Distinct,
 			Fields:        $3.(*ast.FieldList),
-			LockTp:	       $7.(ast.SelectLockType),
+			Limit:         $4.(*ast.Limit),
+			LockTp:	       $6.(ast.SelectLockType),
 		}
 		lastField := st.Fields.Fields[len(st.Fields.Fields)-1]
 		if lastField.Expr != nil && lastField.AsName.O == "" {
-			lastEnd := yyS[yypt-3].offset-1
+			lastEnd := yyS[yypt-2].offset-1
 			lastField.SetText(parser.src[lastField.Offset:lastEnd])
 		}
 		if $5 != nil {
-			st.Where = $5.(ast.ExprNode)
+			st.SelectIntoOpt = $5.(*ast.SelectIntoOption)
+		}
+		$$ = st
+	}
+|	"SELECT" SelectStmtOpts SelectStmtFieldList SelectStmtIntoOption FromDual WhereClauseOptional SelectStmtLimit SelectStmtIntoOption SelectLockOpt
+	{
+		st := &ast.SelectStmt {
+			SelectStmtOpts: $2.(*ast.SelectStmtOpts),
+			Distinct:      $2.(*ast.SelectStmtOpts).Distinct,
+			Fields:        $3.(*ast.FieldList),
+			LockTp:	       $9.(ast.SelectLockType),
+		}
+		lastField := st.Fields.Fields[len(st.Fields.Fields)-1]
+		if lastField.Expr != nil && lastField.AsName.O == "" {
+			lastEnd := yyS[yypt-4].offset-1
+			if $4 != nil {
+				lastEnd = yyS[yypt-5].offset-1
+			}
+			lastField.SetText(parser.src[lastField.Offset:lastEnd])
 		}
 		if $6 != nil {
-			st.Limit = $6.(*ast.Limit)
+			st.Where = $6.(ast.ExprNode)
+		}
+		if $7 != nil {
+			st.Limit = $7.(*ast.Limit)
+		}
+		into, ok := mergeSelectInto($4, $8)
+		if !ok {
+			yylex.Errorf("Multiple INTO clauses in one query block")
+			return 1
 		}
+		st.SelectIntoOpt = into
 		$$ = st
 	}
-|	"SELECT" SelectStmtOpts SelectStmtFieldList "FROM"
+|	"SELECT" SelectStmtOpts SelectStmtFieldList SelectStmtIntoOption "FROM"
 	TableRefsClause WhereClauseOptional SelectStmtGroup HavingClause OrderByOptional
-	SelectStmtLimit SelectLockOpt
+	SelectStmtLimit SelectStmtIntoOption SelectLockOpt
 	{
 		opts := $2.(*ast.SelectStmtOpts)
 		st := &ast.SelectStmt{
 			SelectStmtOpts: $2.(*ast.SelectStmtOpts),
 			Distinct:		opts.Distinct,
 			Fields:		$3.(*ast.FieldList),
-			From:		$5.(*ast.TableRefsClause),
-			LockTp:		$11.(ast.SelectLockType),
+			From:		$6.(*ast.TableRefsClause),
+			LockTp:		$13.(ast.SelectLockType),
 		}
 		if opts.TableHints != nil {
 			st.TableHints = opts.TableHints
@@ -3948,29 +4684,39 @@ SelectStmt:
 
 		lastField := st.Fields.Fields[len(st.Fields.Fields)-1]
 		if lastField.Expr != nil && lastField.AsName.O == "" {
-			lastEnd := parser.endOffset(&yyS[yypt-7])
+			lastEnd := parser.endOffset(&yyS[yypt-8])
+			if $4 != nil {
+				lastEnd = parser.endOffset(&yyS[yypt-9])
+			}
 			lastField.SetText(parser.src[lastField.Offset:lastEnd])
 		}
 
-		if $6 != nil {
-			st.Where = $6.(ast.ExprNode)
-		}
-
 		if $7 != nil {
-			st.GroupBy = $7.(*ast.GroupByClause)
+			st.Where = $7.(ast.ExprNode)
 		}
 
 		if $8 != nil {
-			st.Having = $8.(*ast.HavingClause)
+			st.GroupBy = $8.(*ast.GroupByClause)
 		}
 
 		if $9 != nil {
-			st.OrderBy = $9.(*ast.OrderByClause)
+			st.Having = $9.(*ast.HavingClause)
 		}
 
 		if $10 != nil {
-			st.Limit = $10.(*ast.Limit)
+			st.OrderBy = $10.(*ast.OrderByClause)
+		}
+
+		if $11 != nil {
+			st.Limit = $11.(*ast.Limit)
+		}
+
+		into, ok := mergeSelectInto($4, $12)
+		if !ok {
+			yylex.Errorf("Multiple INTO clauses in one query block")
+			return 1
 		}
+		st.SelectIntoOpt = into
 
 		$$ = st
 	}
@@ -4223,7 +4969,10 @@ SelectStmtLimit:
 	{
 		$$ = nil
 	}
-|	"LIMIT" LimitOption
+|	SelectStmtLimitClause
+
+SelectStmtLimitClause:
+	"LIMIT" LimitOption
 	{
 		$$ = &ast.Limit{Count: $2.(ast.ExprNode)}
 	}
@@ -4361,6 +5110,53 @@ SubSelect:
 	}
 
 // See https://dev.mysql.com/doc/refman/5.7/en/innodb-locking-reads.html
+// See https://dev.mysql.com/doc/refman/5.7/en/select-into.html
+SelectStmtIntoOption:
+	{
+		$$ = nil
+	}
+|	"INTO" "OUTFILE" stringLit Fields Lines
+	{
+		$$ = &ast.SelectIntoOption{
+			Tp:         ast.SelectIntoOutfile,
+			FileName:   $3,
+			FieldsInfo: $4.(*ast.FieldsClause),
+			LinesInfo:  $5.(*ast.LinesClause),
+		}
+	}
+|	"INTO" "DUMPFILE" stringLit
+	{
+		$$ = &ast.SelectIntoOption{
+			Tp:       ast.SelectIntoDumpfile,
+			FileName: $3,
+		}
+	}
+|	"INTO" SelectIntoVarList
+	{
+		$$ = &ast.SelectIntoOption{
+			Tp:   ast.SelectIntoVars,
+			Vars: $2.([]ast.ExprNode),
+		}
+	}
+
+SelectIntoVarList:
+	SelectIntoVar
+	{
+		$$ = []ast.ExprNode{$1}
+	}
+|	SelectIntoVarList ',' SelectIntoVar
+	{
+		$$ = append($1.([]ast.ExprNode), $3)
+	}
+
+/* A local variable of a stored routine is represented as a column name. */
+SelectIntoVar:
+	UserVariable
+|	Identifier
+	{
+		$$ = &ast.ColumnNameExpr{Name: &ast.ColumnName{Name: model.NewCIStr($1)}}
+	}
+
 SelectLockOpt:
 	/* empty */
 	{
@@ -4716,6 +5512,46 @@ AdminStmt:
 			Tables: $4.([]*ast.TableName),
 		}
 	}
+|	"ADMIN" "CHECKSUM" "TABLE" TableNameList
+	{
+		$$ = &ast.AdminStmt{
+			Tp:	ast.AdminChecksumTable,
+			Tables: $4.([]*ast.TableName),
+		}
+	}
+|	"ADMIN" "CHECK" "INDEX" TableName Identifier
+	{
+		$$ = &ast.AdminStmt{
+			Tp:	ast.AdminCheckIndex,
+			Tables: []*ast.TableName{$4.(*ast.TableName)},
+			Index: string($5),
+		}
+	}
+|	"ADMIN" "CHECK" "INDEX" TableName Identifier HandleRangeList
+	{
+		$$ = &ast.AdminStmt{
+			Tp:	ast.AdminCheckIndex,
+			Tables: []*ast.TableName{$4.(*ast.TableName)},
+			Index: string($5),
+			HandleRanges: $6.([]ast.HandleRange),
+		}
+	}
+|	"ADMIN" "RECOVER" "INDEX" TableName Identifier
+	{
+		$$ = &ast.AdminStmt{
+			Tp:	ast.AdminRecoverIndex,
+			Tables: []*ast.TableName{$4.(*ast.TableName)},
+			Index: string($5),
+		}
+	}
+|	"ADMIN" "CLEANUP" "INDEX" TableName Identifier
+	{
+		$$ = &ast.AdminStmt{
+			Tp:	ast.AdminCleanupIndex,
+			Tables: []*ast.TableName{$4.(*ast.TableName)},
+			Index: string($5),
+		}
+	}
 |	"ADMIN" "CANCEL" "DDL" "JOBS" NumList
 	{
 		$$ = &ast.AdminStmt{
@@ -4735,6 +5571,32 @@ NumList:
 	        $$ = append($1.([]int64), $3.(int64))
        }
 
+HandleRangeList:
+	HandleRange
+	{
+		$$ = []ast.HandleRange{$1.(ast.HandleRange)}
+	}
+|	HandleRangeList ',' HandleRange
+	{
+		$$ = append($1.([]ast.HandleRange), $3.(ast.HandleRange))
+	}
+
+HandleRange:
+	'(' SignedNum ',' SignedNum ')'
+	{
+		$$ = ast.HandleRange{Begin: $2.(int64), End: $4.(int64)}
+	}
+
+SignedNum:
+	NUM
+	{
+		$$ = $1.(int64)
+	}
+|	'-' NUM
+	{
+		$$ = -$2.(int64)
+	}
+
 /****************************Show Statement*******************************/
 ShowStmt:
 	"SHOW" ShowTargetFilterable ShowLikeOrWhereOpt
@@ -4943,10 +5805,8 @@ ShowTargetFilterable:
 	{
 		// This statement is similar to SHOW PROCEDURE STATUS but for stored functions.
 		// See http://dev.mysql.com/doc/refman/5.7/en/show-function-status.html
-		// We do not support neither stored functions nor stored procedures.
-		// So we reuse show procedure status process logic.
 		$$ = &ast.ShowStmt {
-			Tp: ast.ShowProcedureStatus,
+			Tp: ast.ShowFunctionStatus,
 		}
 	}
 |	"EVENTS" ShowDatabaseNameOpt
@@ -5082,24 +5942,30 @@ WithReadLockOpt:
 Statement:
 	EmptyStmt
 |	AdminStmt
+|	AlterDatabaseStmt
 |	AlterTableStmt
 |	AlterUserStmt
 |	AnalyzeTableStmt
 |	BeginTransactionStmt
 |	BinlogStmt
+|	CallStmt
 |	CommitStmt
 |	DeallocateStmt
 |	DeleteFromStmt
 |	ExecuteStmt
 |	ExplainStmt
 |	CreateDatabaseStmt
+|	CreateFunctionStmt
 |	CreateIndexStmt
+|	CreateRoutineStmt
 |	CreateTableStmt
 |	CreateViewStmt
 |	CreateUserStmt
 |	DoStmt
 |	DropDatabaseStmt
+|	DropFunctionStmt
 |	DropIndexStmt
+|	DropProcedureStmt
 |	DropTableStmt
 |	DropViewStmt
 |	DropUserStmt
@@ -5111,6 +5977,7 @@ Statement:
 |	LoadDataStmt
 |	PreparedStmt
 |	RollbackStmt
+|	RecoverTableStmt
 |	RenameTableStmt
 |	ReplaceIntoStmt
 |	RevokeStmt
@@ -5179,11 +6046,6 @@ TableElement:
 	{
 		$$ = $1.(*ast.Constraint)
 	}
-|	"CHECK" '(' Expression ')'
-	{
-		/* Nothing to do now */
-		$$ = nil
-	}
 
 TableElementList:
 	TableElement
@@ -5286,6 +6148,7 @@ AlterTableOptionListOpt:
 |	TableOptionList %prec higherThanComma
 
 CreateTableOptionListOpt:
+	%prec empty
 	{
 		$$ = []*ast.TableOption{}
 	}
@@ -5315,6 +6178,25 @@ TruncateTableStmt:
 		$$ = &ast.TruncateTableStmt{Table: $3.(*ast.TableName)}
 	}
 
+/*******************************************************************
+ *
+ *  Recover Table Statement
+ *
+ *  Example:
+ *	RECOVER TABLE t
+ *	FLASHBACK TABLE t TO t_new
+ *
+ *******************************************************************/
+RecoverTableStmt:
+	"RECOVER" "TABLE" TableName
+	{
+		$$ = &ast.RecoverTableStmt{Table: $3.(*ast.TableName)}
+	}
+|	"FLASHBACK" "TABLE" TableName "TO" Identifier
+	{
+		$$ = &ast.RecoverTableStmt{Table: $3.(*ast.TableName), NewName: model.NewCIStr($5)}
+	}
+
 RowFormat:
 	 "ROW_FORMAT" EqOpt "DEFAULT"
 	{
@@ -5355,6 +6237,10 @@ Type:
 	{
 		$$ = $1
 	}
+|	SpatialType
+	{
+		$$ = $1
+	}
 
 NumericType:
 	IntegerType OptFieldLen FieldOpts
@@ -5622,6 +6508,44 @@ Varchar:
 | "NVARCHAR"
 
 
+SpatialType:
+	"GEOMETRY"
+	{
+		x := types.NewFieldType(mysql.TypeGeometry)
+		x.GeomType = geo.TypeGeometry
+		x.Charset = charset.CharsetBin
+		x.Collate = charset.CollationBin
+		x.Flag |= mysql.BinaryFlag
+		$$ = x
+	}
+|	"POINT"
+	{
+		x := types.NewFieldType(mysql.TypeGeometry)
+		x.GeomType = geo.TypePoint
+		x.Charset = charset.CharsetBin
+		x.Collate = charset.CollationBin
+		x.Flag |= mysql.BinaryFlag
+		$$ = x
+	}
+|	"LINESTRING"
+	{
+		x := types.NewFieldType(mysql.TypeGeometry)
+		x.GeomType = geo.TypeLineString
+		x.Charset = charset.CharsetBin
+		x.Collate = charset.CollationBin
+		x.Flag |= mysql.BinaryFlag
+		$$ = x
+	}
+|	"POLYGON"
+	{
+		x := types.NewFieldType(mysql.TypeGeometry)
+		x.GeomType = geo.TypePolygon
+		x.Charset = charset.CharsetBin
+		x.Collate = charset.CollationBin
+		x.Flag |= mysql.BinaryFlag
+		$$ = x
+	}
+
 BlobType:
 	"TINYBLOB"
 	{
@@ -6079,6 +7003,10 @@ PrivType:
 	{
 		$$ = mysql.ProcessPriv
 	}
+|	"FILE"
+	{
+		$$ = mysql.FilePriv
+	}
 |	"EXECUTE"
 	{
 		$$ = mysql.ExecutePriv
@@ -6223,25 +7151,110 @@ RevokeStmt:
  * See https://dev.mysql.com/doc/refman/5.7/en/load-data.html
  *******************************************************************************************/
 LoadDataStmt:
-	"LOAD" "DATA" LocalOpt "INFILE" stringLit "INTO" "TABLE" TableName Fields Lines ColumnNameListOptWithBrackets
+	"LOAD" "DATA" LocalOpt "INFILE" stringLit LoadDataDuplicateOpt "INTO" "TABLE" TableName Fields Lines LoadDataIgnoreLines
+	ColumnNameOrUserVarListOptWithBrackets LoadDataSetSpecOpt
 	{
 		x := &ast.LoadDataStmt{
-			Path:       $5,
-			Table:      $8.(*ast.TableName),
-			Columns:    $11.([]*ast.ColumnName),
+			Path:               $5,
+			OnDuplicate:        $6.(ast.OnDuplicateKeyHandlingType),
+			Table:              $9.(*ast.TableName),
+			IgnoreLines:        $12.(uint64),
+			ColumnsAndUserVars: $13.([]*ast.ColumnNameOrUserVar),
+			Columns:            []*ast.ColumnName{},
 		}
 		if $3 != nil {
 			x.IsLocal = true
 		}
-		if $9 != nil {
-			x.FieldsInfo = $9.(*ast.FieldsClause)
-		}
 		if $10 != nil {
-			x.LinesInfo = $10.(*ast.LinesClause)
+			x.FieldsInfo = $10.(*ast.FieldsClause)
+		}
+		if $11 != nil {
+			x.LinesInfo = $11.(*ast.LinesClause)
+		}
+		for _, v := range x.ColumnsAndUserVars {
+			if v.ColumnName != nil {
+				x.Columns = append(x.Columns, v.ColumnName)
+			}
+		}
+		if $14 != nil {
+			x.ColumnAssignments = $14.([]*ast.Assignment)
 		}
 		$$ = x
 	}
 
+LoadDataDuplicateOpt:
+	{
+		$$ = ast.OnDuplicateKeyHandlingError
+	}
+|	"IGNORE"
+	{
+		$$ = ast.OnDuplicateKeyHandlingIgnore
+	}
+|	"REPLACE"
+	{
+		$$ = ast.OnDuplicateKeyHandlingReplace
+	}
+
+LoadDataIgnoreLines:
+	{
+		$$ = uint64(0)
+	}
+|	"IGNORE" NUM "LINES"
+	{
+		$$ = getUint64FromNUM($2)
+	}
+|	"IGNORE" NUM "ROWS"
+	{
+		$$ = getUint64FromNUM($2)
+	}
+
+ColumnNameOrUserVarListOptWithBrackets:
+	{
+		$$ = []*ast.ColumnNameOrUserVar{}
+	}
+|	'(' ColumnNameOrUserVarListOpt ')'
+	{
+		$$ = $2.([]*ast.ColumnNameOrUserVar)
+	}
+
+ColumnNameOrUserVarListOpt:
+	{
+		$$ = []*ast.ColumnNameOrUserVar{}
+	}
+|	ColumnNameOrUserVarList
+	{
+		$$ = $1.([]*ast.ColumnNameOrUserVar)
+	}
+
+ColumnNameOrUserVarList:
+	ColumnNameOrUserVar
+	{
+		$$ = []*ast.ColumnNameOrUserVar{$1.(*ast.ColumnNameOrUserVar)}
+	}
+|	ColumnNameOrUserVarList ',' ColumnNameOrUserVar
+	{
+		$$ = append($1.([]*ast.ColumnNameOrUserVar), $3.(*ast.ColumnNameOrUserVar))
+	}
+
+ColumnNameOrUserVar:
+	ColumnName
+	{
+		$$ = &ast.ColumnNameOrUserVar{ColumnName: $1.(*ast.ColumnName)}
+	}
+|	UserVariable
+	{
+		$$ = &ast.ColumnNameOrUserVar{UserVar: $1.(*ast.VariableExpr)}
+	}
+
+LoadDataSetSpecOpt:
+	{
+		$$ = nil
+	}
+|	"SET" AssignmentList
+	{
+		$$ = $2.([]*ast.Assignment)
+	}
+
 LocalOpt:
 	{
 		$$ = nil
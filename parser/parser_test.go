@@ -24,6 +24,7 @@ import (
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/testleak"
 )
@@ -97,6 +98,7 @@ func (s *testParserSuite) TestSimple(c *C) {
 		"ln", "log", "log2", "log10", "timestampdiff", "pi", "quote", "none", "super", "shared", "exclusive",
 		"always", "stats", "stats_meta", "stats_histogram", "stats_buckets", "tidb_version", "replication", "slave", "client",
 		"max_connections_per_hour", "max_queries_per_hour", "max_updates_per_hour", "max_user_connections", "event", "reload", "routine", "temporary",
+		"recover", "cleanup", "flashback", "visible", "invisible",
 	}
 	for _, kw := range unreservedKws {
 		src := fmt.Sprintf("SELECT %s FROM tbl;", kw)
@@ -377,11 +379,31 @@ func (s *testParserSuite) TestDMLStmt(c *C) {
 		{"load data local infile '/tmp/t.csv' into table t lines starting by 'ab' terminated by 'xy' (a,b)", true},
 		{"load data local infile '/tmp/t.csv' into table t fields terminated by 'ab' lines terminated by 'xy' (a,b)", true},
 		{"load data local infile '/tmp/t.csv' into table t (a,b) fields terminated by 'ab'", false},
+		{"load data infile '/tmp/t.csv' replace into table t", true},
+		{"load data local infile '/tmp/t.csv' ignore into table t", true},
+		{"load data infile '/tmp/t.csv' into table t fields terminated by ',' ignore 1 lines", true},
+		{"load data infile '/tmp/t.csv' into table t lines terminated by '\\n' ignore 2 rows (a, b)", true},
+		{"load data infile '/tmp/t.csv' into table t (a, @b, c) set d = @b + 1, e = a", true},
+		{"load data infile '/tmp/t.csv' into table t set d = 1", true},
+		{"load data infile '/tmp/t.csv' into table t ignore lines", false},
+		{"load data infile '/tmp/t.csv' into table t (a, b) ignore 1 lines", false},
+		{"load data infile '/tmp/t.csv' into table t replace", false},
 
 		// select for update
 		{"SELECT * from t for update", true},
 		{"SELECT * from t lock in share mode", true},
 
+		// select into
+		{"select * from t into outfile '/tmp/t.csv'", true},
+		{"select a, b from t where a > 1 into outfile '/tmp/t.csv' fields terminated by ',' enclosed by '\"' escaped by '\\\\' lines starting by 'x' terminated by '\\r\\n'", true},
+		{"select 1 into outfile '/tmp/t.csv' columns terminated by ','", true},
+		{"select 1 from dual into outfile '/tmp/t.csv' lines terminated by ';'", true},
+		{"select * from t limit 1 into dumpfile '/tmp/t.bin'", true},
+		{"select * from t into outfile '/tmp/t.csv' for update", true},
+		{"select * from t into dumpfile '/tmp/t.bin' fields terminated by ','", false},
+		{"select * from t into outfile", false},
+		{"select * from t into outfile '/tmp/t.csv' where a > 1", false},
+
 		// from join
 		{"SELECT * from t1, t2, t3", true},
 		{"select * from t1 join t2 left join t3 on t2.id = t3.id", true},
@@ -403,6 +425,13 @@ func (s *testParserSuite) TestDMLStmt(c *C) {
 		{"admin show ddl;", true},
 		{"admin show ddl jobs;", true},
 		{"admin check table t1, t2;", true},
+		{"admin checksum table t1, t2;", true},
+		{"admin checksum table;", false},
+		{"admin check index t1 idx;", true},
+		{"admin check index t1 idx (1, 10), (-5, 0);", true},
+		{"admin check index t1;", false},
+		{"admin recover index t1 idx;", true},
+		{"admin cleanup index test.t1 idx;", true},
 		{"admin cancel ddl jobs 1", true},
 		{"admin cancel ddl jobs 1, 2", true},
 
@@ -946,6 +975,8 @@ func (s *testParserSuite) TestBuiltin(c *C) {
 
 		// for issue 224
 		{`SELECT CAST('test collated returns' AS CHAR CHARACTER SET utf8) COLLATE utf8_bin;`, true},
+		{`SELECT a COLLATE utf8mb4_general_ci = 'A' FROM t`, true},
+		{`SELECT * FROM t ORDER BY a COLLATE utf8mb4_unicode_ci`, true},
 
 		// for string functions
 		// trim
@@ -1187,6 +1218,10 @@ func (s *testParserSuite) TestBuiltin(c *C) {
 		{`select group_concat(c2,c1 SEPARATOR ';') from t group by c1;`, true},
 		{`select group_concat(distinct c2,c1) from t group by c1;`, true},
 		{`select group_concat(distinctrow c2,c1) from t group by c1;`, true},
+		{`select json_arrayagg(c2) from t group by c1;`, true},
+		{`select json_arrayagg(c1, c2) from t group by c1;`, false},
+		{`select json_objectagg(c1, c2) from t;`, true},
+		{`select json_objectagg(c1) from t;`, false},
 
 		// for encryption and compression functions
 		{`select AES_ENCRYPT('text',UNHEX('F3229A0B371ED2D9441B830D21A390C3'))`, true},
@@ -1346,6 +1381,9 @@ func (s *testParserSuite) TestDDL(c *C) {
 		// for check clause
 		{"create table t (c1 bool, c2 bool, check (c1 in (0, 1)), check (c2 in (0, 1)))", true},
 		{"CREATE TABLE Customer (SD integer CHECK (SD > 0), First_Name varchar(30));", true},
+		{"create table t (c1 int, c2 int, constraint c1_gt_c2 check (c1 > c2))", true},
+		{"create table t (c1 int, constraint check (c1 > 0))", true},
+		{"create table t (c1 int, check c1 > 0)", false},
 
 		{"create database xxx", true},
 		{"create database if exists xxx", false},
@@ -1353,6 +1391,11 @@ func (s *testParserSuite) TestDDL(c *C) {
 		{"create schema xxx", true},
 		{"create schema if exists xxx", false},
 		{"create schema if not exists xxx", true},
+		// for alter database/schema
+		{"alter database xxx charset = utf8mb4", true},
+		{"alter database xxx default character set utf8mb4 collate = utf8mb4_bin", true},
+		{"alter schema xxx collate utf8mb4_bin", true},
+		{"alter database xxx", false},
 		// for drop database/schema/table/stats
 		{"drop database xxx", true},
 		{"drop database if exists xxx", true},
@@ -1368,6 +1411,15 @@ func (s *testParserSuite) TestDDL(c *C) {
 		{"drop table if not exists xxx", false},
 		{"drop view if exists xxx", true},
 		{"drop stats t", true},
+		{"drop function f", true},
+		{"drop function if exists f", true},
+		{"create function f returns string soname 'f.so'", true},
+		{"create function f returns integer soname 'f.so'", true},
+		{"create function f returns real soname 'f.so'", true},
+		{"create function f returns decimal soname 'f.so'", true},
+		{"create function f returns int soname 'f.so'", false},
+		{"create function f soname 'f.so'", false},
+		{"create table returns (soname int, string int)", true},
 		// for issue 974
 		{`CREATE TABLE address (
 		id bigint(20) NOT NULL AUTO_INCREMENT,
@@ -1467,6 +1519,17 @@ func (s *testParserSuite) TestDDL(c *C) {
 		// Create table with like.
 		{"create table a like b", true},
 		{"create table if not exists a like b", true},
+		// Create table with select.
+		{"create table a select * from b", true},
+		{"create table a as select * from b where c > 1", true},
+		{"create table if not exists a engine = innodb as select c, d from b", true},
+		{"create table a (c int, primary key(c)) select c, d from b", true},
+		{"create table a (c int) as select 1 union select 2", true},
+		{"create table a (unique key(c)) select c from b", true},
+		{"create table a (unique key(c))", false},
+		{"create table a select 1 union select 2", true},
+		{"create table a as (select 1) union (select 2)", true},
+		{"create table a as", false},
 		{"create table t (a timestamp default now)", false},
 		{"create table t (a timestamp default now())", true},
 		{"create table t (a timestamp default now() on update now)", false},
@@ -1523,6 +1586,21 @@ func (s *testParserSuite) TestDDL(c *C) {
 		{"ALTER TABLE t ENGINE = '', COMMENT='', default COLLATE = utf8_general_ci", true},
 		{"ALTER TABLE t ENGINE = '', ADD COLUMN a SMALLINT", true},
 		{"ALTER TABLE t default COLLATE = utf8_general_ci, ENGINE = '', ADD COLUMN a SMALLINT", true},
+		{"ALTER TABLE t RENAME INDEX a TO b", true},
+		{"ALTER TABLE t RENAME KEY `a` TO `b`", true},
+		{"ALTER TABLE t RENAME INDEX a b", false},
+		{"ALTER TABLE t ADD CHECK (a > 0)", true},
+		{"ALTER TABLE t ADD CONSTRAINT a_positive CHECK (a > 0)", true},
+		{"ALTER TABLE t DROP CHECK a_positive", true},
+		{"ALTER TABLE t DROP CHECK", false},
+		{"ALTER TABLE t ALTER INDEX a INVISIBLE", true},
+		{"ALTER TABLE t ALTER INDEX `a` VISIBLE, ALTER INDEX b INVISIBLE", true},
+		{"ALTER TABLE t ALTER INDEX a", false},
+		{"ALTER TABLE t ALTER KEY a VISIBLE", false},
+		{"ALTER TABLE t CONVERT TO CHARACTER SET utf8mb4", true},
+		{"ALTER TABLE t CONVERT TO CHARSET utf8mb4 COLLATE utf8mb4_bin", true},
+		{"ALTER TABLE t CONVERT TO CHARSET = utf8mb4", false},
+		{"ALTER TABLE t COMMENT = 'a', CHARSET = utf8mb4", true},
 
 		// For create index statement
 		{"CREATE INDEX idx ON t (a)", true},
@@ -1531,6 +1609,11 @@ func (s *testParserSuite) TestDDL(c *C) {
 		{"CREATE INDEX idx ON t (a) USING HASH COMMENT 'foo'", true},
 		{"CREATE INDEX idx USING BTREE ON t (a) USING HASH COMMENT 'foo'", true},
 		{"CREATE INDEX idx USING BTREE ON t (a)", true},
+		{"CREATE INDEX idx ON t ((lower(a)))", true},
+		{"CREATE INDEX idx ON t ((a + b) DESC, c)", true},
+		{"CREATE INDEX idx ON t (lower(a))", false},
+		{"create table t (a varchar(10), key idx ((lower(a))))", true},
+		{"alter table t add unique index idx ((a + 1), b)", true},
 
 		// for rename table statement
 		{"RENAME TABLE t TO t1", true},
@@ -1542,6 +1625,14 @@ func (s *testParserSuite) TestDDL(c *C) {
 		{"TRUNCATE TABLE t1", true},
 		{"TRUNCATE t1", true},
 
+		// for recover table statement
+		{"RECOVER TABLE t1", true},
+		{"RECOVER TABLE test.t1", true},
+		{"RECOVER TABLE t1 TO t2", false},
+		{"FLASHBACK TABLE t1 TO t2", true},
+		{"FLASHBACK TABLE test.t1 TO t2", true},
+		{"FLASHBACK TABLE t1", false},
+
 		// for empty alert table index
 		{"ALTER TABLE t ADD INDEX () ", false},
 		{"ALTER TABLE t ADD UNIQUE ()", false},
@@ -1641,6 +1732,13 @@ func (s *testParserSuite) TestType(c *C) {
 
 		// for json type
 		{`create table t (a JSON);`, true},
+
+		// for spatial types
+		{"create table t (g geometry, p point not null, l linestring, pg polygon)", true},
+		{"create table t (p point(1))", false},
+		{"create table point (point int, polygon int, linestring int, geometry int)", true},
+		{"select point(1, 2), polygon(a), linestring(a, b)", true},
+		{"select st_geomfromtext('POINT(1 1)'), st_astext(p), mbrcontains(a, b) from t", true},
 	}
 	s.RunTest(c, table)
 }
@@ -1696,6 +1794,7 @@ func (s *testParserSuite) TestPrivilege(c *C) {
 		{"GRANT SELECT ON test.* to 'test'", true},                                                                                                            // For issue 2654.
 		{"grant PROCESS,usage, REPLICATION SLAVE, REPLICATION CLIENT on *.* to 'xxxxxxxxxx'@'%' identified by password 'xxxxxxxxxxxxxxxxxxxxxxxxxxxx'", true}, // For issue 4865
 		{"/* rds internal mark */ GRANT SELECT, INSERT, UPDATE, DELETE, CREATE, DROP, REFERENCES, RELOAD, PROCESS, INDEX, ALTER, CREATE TEMPORARY TABLES, LOCK TABLES,      EXECUTE, REPLICATION SLAVE, REPLICATION CLIENT, CREATE VIEW, SHOW VIEW, CREATE ROUTINE, ALTER ROUTINE, CREATE USER, EVENT,      TRIGGER on *.* to 'root2'@'%' identified by password '*sdsadsdsadssadsadsadsadsada' with grant option", true},
+		{"grant file on *.* to 'test'@'%'", true},
 
 		// for revoke statement
 		{"REVOKE ALL ON db1.* FROM 'jeffrey'@'localhost';", true},
@@ -2103,3 +2202,117 @@ func (s *testParserSuite) TestSetTransaction(c *C) {
 		c.Assert(vars.Value.GetValue(), Equals, t.value)
 	}
 }
+
+func (s *testParserSuite) TestSelectInto(c *C) {
+	defer testleak.AfterTest(c)()
+	tests := []struct {
+		input      string
+		tp         ast.SelectIntoType
+		fieldText  string
+		terminated string
+		enclosed   byte
+		lines      string
+	}{
+		{"select a + 1 into outfile '/tmp/t.csv'", ast.SelectIntoOutfile, "a + 1", "\t", 0, "\n"},
+		{"select a + 1 from dual into outfile '/tmp/t.csv' fields terminated by ',' enclosed by '\"' lines terminated by '\\r\\n'", ast.SelectIntoOutfile, "a + 1", ",", '"', "\r\n"},
+		{"select a + 1 from t where a > 1 limit 1 into dumpfile '/tmp/t.csv' for update", ast.SelectIntoDumpfile, "a + 1", "", 0, ""},
+	}
+	parser := New()
+	for _, t := range tests {
+		stmt, err := parser.ParseOneStmt(t.input, "", "")
+		c.Assert(err, IsNil)
+		sel := stmt.(*ast.SelectStmt)
+		c.Assert(sel.Fields.Fields[0].Text(), Equals, t.fieldText)
+		into := sel.SelectIntoOpt
+		c.Assert(into.Tp, Equals, t.tp)
+		c.Assert(into.FileName, Equals, "/tmp/t.csv")
+		if t.tp == ast.SelectIntoOutfile {
+			c.Assert(into.FieldsInfo.Terminated, Equals, t.terminated)
+			c.Assert(into.FieldsInfo.Enclosed, Equals, t.enclosed)
+			c.Assert(into.LinesInfo.Terminated, Equals, t.lines)
+		}
+	}
+}
+
+func (s *testParserSuite) TestLoadData(c *C) {
+	defer testleak.AfterTest(c)()
+	parser := New()
+	stmt, err := parser.ParseOneStmt("load data infile '/tmp/t.csv' replace into table t ignore 1 lines (a, @b, c) set d = @b", "", "")
+	c.Assert(err, IsNil)
+	ld := stmt.(*ast.LoadDataStmt)
+	c.Assert(ld.OnDuplicate, Equals, ast.OnDuplicateKeyHandlingReplace)
+	c.Assert(ld.IgnoreLines, Equals, uint64(1))
+	c.Assert(ld.ColumnsAndUserVars, HasLen, 3)
+	c.Assert(ld.ColumnsAndUserVars[1].UserVar.Name, Equals, "b")
+	c.Assert(ld.Columns, HasLen, 2)
+	c.Assert(ld.Columns[1].Name.L, Equals, "c")
+	c.Assert(ld.ColumnAssignments, HasLen, 1)
+	c.Assert(ld.ColumnAssignments[0].Column.Name.L, Equals, "d")
+}
+
+func (s *testParserSuite) TestUDF(c *C) {
+	defer testleak.AfterTest(c)()
+	parser := New()
+	stmt, err := parser.ParseOneStmt("create function Metaphon returns real soname 'udf_example.so'", "", "")
+	c.Assert(err, IsNil)
+	cf := stmt.(*ast.CreateFunctionStmt)
+	c.Assert(cf.FunctionName.L, Equals, "metaphon")
+	c.Assert(cf.ReturnType, Equals, types.ETReal)
+	c.Assert(cf.SharedLib, Equals, "udf_example.so")
+
+	stmt, err = parser.ParseOneStmt("drop function if exists metaphon", "", "")
+	c.Assert(err, IsNil)
+	df := stmt.(*ast.DropFunctionStmt)
+	c.Assert(df.IfExists, IsTrue)
+	c.Assert(df.FunctionName.L, Equals, "metaphon")
+}
+
+func (s *testParserSuite) TestStoredRoutine(c *C) {
+	defer testleak.AfterTest(c)()
+	table := []testCase{
+		{"create procedure p() begin end", true},
+		{"create procedure test.p(in a int, out b varchar(10), inout c decimal(5,2)) comment 'p' sql security invoker select 1", true},
+		{"create definer = 'root'@'localhost' procedure p() modifies sql data begin select 1; end", true},
+		{"create function f(a int) returns int deterministic return a + 1", true},
+		{"create function f(out a int) returns int return 1", false},
+		{"create procedure p() begin declare a, b int default 1; declare c cursor for select 1; declare continue handler for not found, sqlstate value '23000', 1062 set a = 0; end", true},
+		{"create procedure p() begin declare exit handler for sqlexception, sqlwarning begin end; end", true},
+		{"create procedure p() l: begin if a > 1 then leave l; elseif a > 0 then set a = 1; else set a = 2; end if; end l", true},
+		{"create procedure p() begin l: while a > 0 do iterate l; end while l; repeat set a = a - 1; until a < 0 end repeat; loop leave l; end loop; end", true},
+		{"create procedure p() begin open c; fetch c into a, b; fetch next from c into a; close c; end", true},
+		{"create procedure p() begin if a then select 1; end", false},
+		{"drop procedure p", true},
+		{"drop procedure if exists test.p", true},
+		{"drop function test.f", true},
+		{"call p", true},
+		{"call test.p()", true},
+		{"call p(1, @a, 'x')", true},
+		{"show function status like 'f%'", true},
+	}
+	s.RunTest(c, table)
+
+	parser := New()
+	stmt, err := parser.ParseOneStmt("create procedure test.P(in a int, inout b int) begin declare c int; set b = a + c; end", "", "")
+	c.Assert(err, IsNil)
+	cr := stmt.(*ast.CreateRoutineStmt)
+	c.Assert(cr.Tp, Equals, ast.RoutineProcedure)
+	c.Assert(cr.Schema.L, Equals, "test")
+	c.Assert(cr.Name.L, Equals, "p")
+	c.Assert(cr.Params, HasLen, 2)
+	c.Assert(cr.Params[1].Mode, Equals, ast.ParamInOut)
+	c.Assert(cr.Params[1].Name, Equals, "b")
+	c.Assert(cr.ParamsText, Equals, "in a int, inout b int")
+	c.Assert(cr.BodyText, Equals, "begin declare c int; set b = a + c; end")
+	c.Assert(cr.DataAccess, Equals, ast.ContainsSQL)
+	block := cr.Body.(*ast.BlockStmt)
+	c.Assert(block.Stmts, HasLen, 2)
+	c.Assert(block.Stmts[0].(*ast.DeclareVarStmt).Names, DeepEquals, []string{"c"})
+
+	stmt, err = parser.ParseOneStmt("create function f() returns varchar(10) no sql return 'a'", "", "")
+	c.Assert(err, IsNil)
+	cr = stmt.(*ast.CreateRoutineStmt)
+	c.Assert(cr.Tp, Equals, ast.RoutineFunction)
+	c.Assert(cr.ReturnType.Flen, Equals, 10)
+	c.Assert(cr.DataAccess, Equals, ast.NoSQL)
+	c.Assert(cr.Body, FitsTypeOf, &ast.ReturnStmt{})
+}
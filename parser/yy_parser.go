@@ -17,6 +17,7 @@ import (
 	"math"
 	"regexp"
 	"strconv"
+	"strings"
 	"unicode"
 
 	"github.com/juju/errors"
@@ -240,3 +241,27 @@ func getUint64FromNUM(num interface{}) uint64 {
 	}
 	return 0
 }
+
+// checkEndLabel checks that the end label of a labeled compound statement in a stored routine is
+// empty or the same as the begin label.
+func checkEndLabel(l yyLexer, begin, end string) bool {
+	if end != "" && !strings.EqualFold(begin, end) {
+		l.Errorf("End-label %s without match", end)
+		return false
+	}
+	return true
+}
+
+// mergeSelectInto returns the INTO clause of a SELECT statement, which is either before FROM or at the end.
+// It's false if both of them are specified.
+func mergeSelectInto(before, after interface{}) (*ast.SelectIntoOption, bool) {
+	switch {
+	case before != nil && after != nil:
+		return nil, false
+	case before != nil:
+		return before.(*ast.SelectIntoOption), true
+	case after != nil:
+		return after.(*ast.SelectIntoOption), true
+	}
+	return nil, true
+}
@@ -32,7 +32,8 @@ func Cacheable(node ast.Node) bool {
 
 // cacheableChecker checks whether a query's plan can be cached, querys that:
 //	 1. have ExistsSubqueryExpr, or
-//	 2. have VariableExpr
+//	 2. have VariableExpr, or
+//	 3. call user-defined functions or stored functions
 // will not be cached currently.
 // NOTE: we can add more rules in the future.
 type cacheableChecker struct {
@@ -50,6 +51,11 @@ func (checker *cacheableChecker) Enter(in ast.Node) (out ast.Node, skipChildren
 			checker.cacheable = false
 			return in, true
 		}
+		// The user-defined functions and the stored functions may be dropped after the plan is cached.
+		if !expression.IsBuiltinFunc(node.FnName.L) {
+			checker.cacheable = false
+			return in, true
+		}
 	case *ast.Limit:
 		if node.Count != nil {
 			if _, isParamMarker := node.Count.(*ast.ParamMarkerExpr); isParamMarker {
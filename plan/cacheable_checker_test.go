@@ -46,7 +46,7 @@ func (s *testCacheableSuite) TestCacheable(c *C) {
 	c.Assert(Cacheable(stmt), IsFalse)
 
 	// test SelectStmt
-	whereExpr := &ast.FuncCallExpr{}
+	whereExpr := &ast.FuncCallExpr{FnName: model.NewCIStr(ast.Abs)}
 	stmt = &ast.SelectStmt{
 		Where: whereExpr,
 	}
@@ -60,6 +60,10 @@ func (s *testCacheableSuite) TestCacheable(c *C) {
 	whereExpr.FnName = model.NewCIStr(ast.Rand)
 	c.Assert(Cacheable(stmt), IsTrue)
 
+	// The stored functions aren't builtin functions.
+	whereExpr.FnName = model.NewCIStr("stored_func")
+	c.Assert(Cacheable(stmt), IsFalse)
+
 	stmt = &ast.SelectStmt{
 		Where: &ast.ExistsSubqueryExpr{},
 	}
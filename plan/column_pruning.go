@@ -151,6 +151,21 @@ func (p *LogicalUnionScan) PruneColumns(parentUsedCols []*expression.Column) {
 // PruneColumns implements LogicalPlan interface.
 func (p *DataSource) PruneColumns(parentUsedCols []*expression.Column) {
 	used := getUsedList(parentUsedCols, p.schema)
+	// Keep the hidden columns whose expressions only use the used columns,
+	// so the predicates on the expressions can be matched to the expression indexes.
+	for i, col := range p.hiddenCols {
+		idx := p.schema.ColumnIndex(col)
+		if idx == -1 || used[idx] {
+			continue
+		}
+		used[idx] = true
+		for _, dep := range expression.ExtractColumns(p.hiddenExprs[i]) {
+			if depIdx := p.schema.ColumnIndex(dep); depIdx == -1 || !used[depIdx] {
+				used[idx] = false
+				break
+			}
+		}
+	}
 	for i := len(used) - 1; i >= 0; i-- {
 		if !used[i] {
 			p.schema.Columns = append(p.schema.Columns[:i], p.schema.Columns[i+1:]...)
@@ -29,6 +29,8 @@ import (
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/sessionctx/varsutil"
 	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/charset"
+	"github.com/pingcap/tidb/util/collate"
 )
 
 // EvalSubquery evaluates incorrelated subqueries once.
@@ -53,6 +55,24 @@ func evalAstExpr(expr ast.ExprNode, ctx context.Context) (types.Datum, error) {
 	return newExpr.Eval(nil)
 }
 
+func rewriteAstExpr(ctx context.Context, expr ast.ExprNode, schema *expression.Schema) (expression.Expression, error) {
+	b := &planBuilder{
+		ctx:       ctx,
+		colMapper: make(map[*ast.ColumnNameExpr]int),
+	}
+	if ctx.GetSessionVars().TxnCtx.InfoSchema != nil {
+		b.is = ctx.GetSessionVars().TxnCtx.InfoSchema.(infoschema.InfoSchema)
+	}
+	mockPlan := LogicalTableDual{}.init(ctx)
+	mockPlan.SetSchema(schema)
+	newExpr, _, err := b.rewrite(expr, mockPlan, nil, true)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	newExpr.ResolveIndices(schema)
+	return newExpr, nil
+}
+
 // rewrite function rewrites ast expr to expression.Expression.
 // aggMapper maps ast.AggregateFuncExpr to the columns offset in p's output schema.
 // asScalar means whether this expression must be treated as a scalar expression.
@@ -736,6 +756,8 @@ func (er *expressionRewriter) Leave(originInNode ast.Node) (retNode ast.Node, ok
 		er.isNullToExpression(v)
 	case *ast.IsTruthExpr:
 		er.isTrueToScalarFunc(v)
+	case *ast.SetCollationExpr:
+		er.setCollationToExpression(v)
 	default:
 		er.err = errors.Errorf("UnknownType: %T", v)
 		return retNode, false
@@ -888,6 +910,30 @@ func (er *expressionRewriter) isNullToExpression(v *ast.IsNullExpr) {
 	er.ctxStack = append(er.ctxStack, function)
 }
 
+func (er *expressionRewriter) setCollationToExpression(v *ast.SetCollationExpr) {
+	stkLen := len(er.ctxStack)
+	arg := er.ctxStack[stkLen-1]
+	if getRowLen(arg) != 1 {
+		er.err = ErrOperandColumns.GenByArgs(1)
+		return
+	}
+	coll, err := charset.GetCollationByName(v.Collate)
+	if err != nil || !collate.IsSupportedCollation(coll.Name) {
+		er.err = expression.ErrUnknownCollation.GenByArgs(v.Collate)
+		return
+	}
+	chs := arg.GetType().Charset
+	if chs == "" {
+		chs = charset.CharsetUTF8
+	}
+	// The utf8 strings are valid utf8mb4 strings, so the utf8mb4 collations can be used on them.
+	if coll.CharsetName != chs && !(chs == charset.CharsetUTF8 && coll.CharsetName == charset.CharsetUTF8MB4) {
+		er.err = expression.ErrCollationCharsetMismatch.GenByArgs(coll.Name, chs)
+		return
+	}
+	er.ctxStack[stkLen-1] = expression.SetCollationExpr(arg, coll.CharsetName, coll.Name)
+}
+
 func (er *expressionRewriter) positionToScalarFunc(v *ast.PositionExpr) {
 	if v.N > 0 && v.N <= er.schema.Len() {
 		er.ctxStack = append(er.ctxStack, er.schema.Columns[v.N-1])
@@ -1157,11 +1203,23 @@ func (er *expressionRewriter) funcCallToExpression(v *ast.FuncCallExpr) {
 	}
 	var function expression.Expression
 	function, er.err = expression.NewFunction(er.ctx, v.FnName.L, &v.Type, args...)
+	// Like CALL, calling a stored function requires the EXECUTE privilege on its database.
+	if er.err == nil && expression.IsStoredFunction(function) {
+		er.b.visitInfo = appendVisitInfo(er.b.visitInfo, mysql.ExecutePriv, er.b.routineDB(model.CIStr{}), "", "")
+	}
 	er.ctxStack = er.ctxStack[:stackLen-len(v.Args)]
 	er.ctxStack = append(er.ctxStack, function)
 }
 
 func (er *expressionRewriter) toColumn(v *ast.ColumnName) {
+	// Like MySQL, the local variables of stored routines take precedence over the columns.
+	if v.Table.L == "" {
+		if rv, ok := er.ctx.GetSessionVars().RoutineVars.Get(v.Name.L); ok {
+			tp := *rv.Tp
+			er.ctxStack = append(er.ctxStack, &expression.Constant{Value: rv.Value, RetType: &tp})
+			return
+		}
+	}
 	column, err := er.schema.FindColumn(v)
 	if err != nil {
 		er.err = ErrAmbiguous.GenByArgs(v.Name)
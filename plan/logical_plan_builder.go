@@ -1380,7 +1380,7 @@ func (b *planBuilder) unfoldWildStar(p LogicalPlan, selectFields []*ast.SelectFi
 		for _, col := range p.Schema().Columns {
 			if (dbName.L == "" || dbName.L == col.DBName.L) &&
 				(tblName.L == "" || tblName.L == col.TblName.L) &&
-				col.ID != model.ExtraHandleID {
+				col.ID != model.ExtraHandleID && !col.IsHidden {
 				findTblNameInSchema = true
 				colName := &ast.ColumnNameExpr{
 					Name: &ast.ColumnName{
@@ -1619,7 +1619,8 @@ func (b *planBuilder) buildDataSource(tn *ast.TableName) LogicalPlan {
 			DBName:   schemaName,
 			RetType:  &col.FieldType,
 			Position: i,
-			ID:       col.ID})
+			ID:       col.ID,
+			IsHidden: col.Hidden})
 		if tableInfo.PKIsHandle && mysql.HasPriKeyFlag(col.Flag) {
 			handleCol = schema.Columns[schema.Len()-1]
 		}
@@ -1683,6 +1684,10 @@ func (b *planBuilder) projectVirtualColumns(ds *DataSource, columns []*table.Col
 					b.err = errors.Trace(err)
 					return nil
 				}
+				if column.Hidden {
+					ds.hiddenCols = append(ds.hiddenCols, colExpr)
+					ds.hiddenExprs = append(ds.hiddenExprs, expr)
+				}
 				// Because the expression maybe return different type from
 				// the generated column, we should wrap a CAST on the result.
 				expr = expression.BuildCastFunction(b.ctx, expr, colExpr.GetType())
@@ -1309,6 +1309,20 @@ func (s *testPlanSuite) TestVisitInfo(c *C) {
 				{mysql.DropPriv, "test", "t", ""},
 			},
 		},
+		{
+			sql: "recover table t",
+			ans: []visitInfo{
+				{mysql.DropPriv, "test", "t", ""},
+				{mysql.CreatePriv, "test", "t", ""},
+			},
+		},
+		{
+			sql: "flashback table t to t1",
+			ans: []visitInfo{
+				{mysql.DropPriv, "test", "t", ""},
+				{mysql.CreatePriv, "test", "t1", ""},
+			},
+		},
 		{
 			sql: "create table t (a int)",
 			ans: []visitInfo{
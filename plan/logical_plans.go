@@ -281,11 +281,19 @@ type DataSource struct {
 
 	// pushedDownConds are the conditions that will be pushed down to coprocessor.
 	pushedDownConds []expression.Expression
+	// remainedConds are the conditions that can't be pushed down to coprocessor, they may still be
+	// computed by the expression indexes or be the access conditions of the indexes.
+	remainedConds []expression.Expression
 
 	statisticTable *statistics.Table
 
 	// availableIndices is used for storing result of avalableIndices function.
 	availableIndices *avalableIndices
+
+	// hiddenCols are the hidden columns of the expression indexes, and hiddenExprs are their expressions,
+	// the predicates on the expressions are matched to the indexes.
+	hiddenCols  []*expression.Column
+	hiddenExprs []expression.Expression
 }
 
 type avalableIndices struct {
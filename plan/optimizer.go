@@ -186,6 +186,9 @@ const (
 	CodeWrongGroupField      = mysql.ErrWrongGroupField
 	CodeDupFieldName         = mysql.ErrDupFieldName
 	CodeNonUpdatableTable    = mysql.ErrNonUpdatableTable
+	CodeWrongUsage           = mysql.ErrWrongUsage
+	CodeWrongNumberOfColumns = mysql.ErrWrongNumberOfColumnsInSelect
+	CodeUndeclaredVar        = mysql.ErrSpUndeclaredVar
 )
 
 // Optimizer base errors.
@@ -203,6 +206,9 @@ var (
 	ErrWrongGroupField             = terror.ClassOptimizer.New(CodeWrongGroupField, mysql.MySQLErrName[mysql.ErrWrongGroupField])
 	ErrDupFieldName                = terror.ClassOptimizer.New(CodeDupFieldName, mysql.MySQLErrName[mysql.ErrDupFieldName])
 	ErrNonUpdatableTable           = terror.ClassOptimizer.New(CodeNonUpdatableTable, mysql.MySQLErrName[mysql.ErrNonUpdatableTable])
+	ErrWrongUsage                  = terror.ClassOptimizer.New(CodeWrongUsage, mysql.MySQLErrName[mysql.ErrWrongUsage])
+	ErrWrongNumberOfColumns        = terror.ClassOptimizer.New(CodeWrongNumberOfColumns, mysql.MySQLErrName[mysql.ErrWrongNumberOfColumnsInSelect])
+	ErrUndeclaredVar               = terror.ClassOptimizer.New(CodeUndeclaredVar, mysql.MySQLErrName[mysql.ErrSpUndeclaredVar])
 )
 
 func init() {
@@ -216,7 +222,11 @@ func init() {
 		CodeWrongGroupField:      mysql.ErrWrongGroupField,
 		CodeDupFieldName:         mysql.ErrDupFieldName,
 		CodeNonUpdatableTable:    mysql.ErrUnknownTable,
+		CodeWrongUsage:           mysql.ErrWrongUsage,
+		CodeWrongNumberOfColumns: mysql.ErrWrongNumberOfColumnsInSelect,
+		CodeUndeclaredVar:        mysql.ErrSpUndeclaredVar,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassOptimizer] = mySQLErrCodes
 	expression.EvalAstExpr = evalAstExpr
+	expression.RewriteAstExpr = rewriteAstExpr
 }
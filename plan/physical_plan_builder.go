@@ -213,7 +213,7 @@ func (p *DataSource) convert2NewPhysicalPlan(prop *requiredProp) (task, error) {
 			return nil, errors.Trace(err)
 		}
 	}
-	if !includeTableScan || len(p.pushedDownConds) > 0 || len(prop.cols) > 0 {
+	if !includeTableScan || len(p.pushedDownConds) > 0 || len(p.remainedConds) > 0 || len(prop.cols) > 0 {
 		for _, idx := range indices {
 			idxTask, err := p.convertToIndexScan(prop, idx)
 			if err != nil {
@@ -239,7 +239,10 @@ func isCoveringIndex(columns []*model.ColumnInfo, indexColumns []*model.IndexCol
 		isIndexColumn := false
 		for _, indexCol := range indexColumns {
 			isFullLen := indexCol.Length == types.UnspecifiedLength || indexCol.Length == colInfo.Flen
-			if colInfo.Name.L == indexCol.Name.L && isFullLen {
+			// The index keys of the strings whose collation isn't binary are their sort keys,
+			// the strings can't be read from the index, except the strings of the padding _bin
+			// collations, whose trailing spaces are kept in the index values.
+			if colInfo.Name.L == indexCol.Name.L && isFullLen && isIndexReadable(&colInfo.FieldType) {
 				isIndexColumn = true
 				break
 			}
@@ -308,9 +311,12 @@ func (p *DataSource) convertToIndexScan(prop *requiredProp, idx *model.IndexInfo
 	sc := p.ctx.GetSessionVars().StmtCtx
 	idxCols, colLengths := expression.IndexInfo2Cols(p.Schema().Columns, idx)
 	is.Ranges = ranger.FullNewRange()
-	if len(p.pushedDownConds) > 0 {
+	if conds := p.matchExpressionIndex(idx); len(conds) > 0 || len(p.remainedConds) > 0 {
 		if len(idxCols) > 0 {
-			is.AccessCondition, is.filterCondition = ranger.DetachIndexConditions(p.pushedDownConds, idxCols, colLengths)
+			conds = append(p.collationAccessConds(), conds...)
+			is.AccessCondition, is.filterCondition = ranger.DetachIndexConditions(conds, idxCols, colLengths)
+			// The conditions which can't be pushed down are evaluated above the reader.
+			_, is.filterCondition, _ = expression.ExpressionsToPB(sc, is.filterCondition, p.ctx.GetClient())
 			is.Ranges, err = ranger.BuildIndexRange(sc, idxCols, colLengths, is.AccessCondition)
 			if err != nil {
 				return nil, errors.Trace(err)
@@ -320,7 +326,7 @@ func (p *DataSource) convertToIndexScan(prop *requiredProp, idx *model.IndexInfo
 				return nil, errors.Trace(err)
 			}
 		} else {
-			is.filterCondition = p.pushedDownConds
+			is.filterCondition = conds
 		}
 	}
 	cop := &copTask{indexPlan: is}
@@ -391,6 +397,71 @@ func (p *DataSource) convertToIndexScan(prop *requiredProp, idx *model.IndexInfo
 	return task, nil
 }
 
+// collationAccessConds returns the remained conditions on the strings whose collation isn't binary. They
+// can't be pushed down because the storage compares the strings by their bytes, but they can still be
+// the access conditions of the indexes, whose keys are the sort keys of the strings.
+func (p *DataSource) collationAccessConds() []expression.Expression {
+	var conds []expression.Expression
+	for _, cond := range p.remainedConds {
+		for _, col := range expression.ExtractColumns(cond) {
+			if expression.IsCollationKeyNeeded(col.RetType) {
+				conds = append(conds, cond)
+				break
+			}
+		}
+	}
+	return conds
+}
+
+// matchExpressionIndex substitutes the columns of the hidden columns in the index for their expressions in the
+// conditions. The substituted conditions are only used if they can be computed by the index, because the values
+// of the hidden columns are computed rather than stored in the table. The remained conditions which can be pushed
+// down after the substitution are added too, they are still evaluated above the reader.
+func (p *DataSource) matchExpressionIndex(idx *model.IndexInfo) []expression.Expression {
+	var cols []*expression.Column
+	var exprs []expression.Expression
+	for i, col := range p.hiddenCols {
+		if p.schema.ColumnIndex(col) == -1 {
+			continue
+		}
+		for _, idxCol := range idx.Columns {
+			if idxCol.Name.L == col.ColName.L {
+				cols = append(cols, col)
+				exprs = append(exprs, p.hiddenExprs[i])
+				break
+			}
+		}
+	}
+	if len(cols) == 0 {
+		return p.pushedDownConds
+	}
+	pkName := model.CIStr{}
+	if pkCol := p.getPKIsHandleCol(); pkCol != nil {
+		pkName = pkCol.ColName
+	}
+	conds := make([]expression.Expression, 0, len(p.pushedDownConds))
+	for _, cond := range p.pushedDownConds {
+		newCond, ok := expression.SubstituteExprs(p.ctx, cond, exprs, cols)
+		if ok && checkIndexCondition(newCond, idx.Columns, pkName) {
+			cond = newCond
+		}
+		conds = append(conds, cond)
+	}
+	sc := p.ctx.GetSessionVars().StmtCtx
+	// The conditions on the coordinates implied by the spatial relations can be computed by the indexes on them.
+	remained := append(expression.DeriveMBRConds(p.ctx, p.remainedConds), p.remainedConds...)
+	for _, cond := range remained {
+		newCond, ok := expression.SubstituteExprs(p.ctx, cond, exprs, cols)
+		if !ok || !checkIndexCondition(newCond, idx.Columns, pkName) {
+			continue
+		}
+		if _, pushed, _ := expression.ExpressionsToPB(sc, []expression.Expression{newCond}, p.ctx.GetClient()); len(pushed) > 0 {
+			conds = append(conds, newCond)
+		}
+	}
+	return conds
+}
+
 // TODO: refine this.
 func (is *PhysicalIndexScan) initSchema(id int, idx *model.IndexInfo, isDoubleRead bool) {
 	var indexCols []*expression.Column
@@ -475,6 +546,11 @@ func splitIndexFilterConditions(conditions []expression.Expression, indexColumns
 	return indexConditions, tableConditions
 }
 
+// isIndexReadable checks whether the values of the type can be read from the index.
+func isIndexReadable(tp *types.FieldType) bool {
+	return !expression.IsCollationKeyNeeded(tp) || expression.IsPaddingCollationKey(tp)
+}
+
 // checkIndexCondition will check whether all columns of condition is index columns or primary key column.
 func checkIndexCondition(condition expression.Expression, indexColumns []*model.IndexColumn, pkName model.CIStr) bool {
 	cols := expression.ExtractColumns(condition)
@@ -484,7 +560,7 @@ func checkIndexCondition(condition expression.Expression, indexColumns []*model.
 		}
 		isIndexColumn := false
 		for _, indCol := range indexColumns {
-			if col.ColName.L == indCol.Name.L && indCol.Length == types.UnspecifiedLength {
+			if col.ColName.L == indCol.Name.L && indCol.Length == types.UnspecifiedLength && isIndexReadable(col.RetType) {
 				isIndexColumn = true
 				break
 			}
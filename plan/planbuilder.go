@@ -28,6 +28,7 @@ import (
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/auth"
 )
 
 // Error instances.
@@ -186,6 +187,9 @@ func (b *planBuilder) build(node ast.Node) Plan {
 	case *ast.PrepareStmt:
 		return b.buildPrepare(x)
 	case *ast.SelectStmt:
+		if x.SelectIntoOpt != nil {
+			return b.buildSelectInto(x)
+		}
 		return b.buildSelect(x)
 	case *ast.UnionStmt:
 		return b.buildUnion(x)
@@ -201,7 +205,8 @@ func (b *planBuilder) build(node ast.Node) Plan {
 		return b.buildAnalyze(x)
 	case *ast.BinlogStmt, *ast.FlushStmt, *ast.UseStmt,
 		*ast.BeginStmt, *ast.CommitStmt, *ast.RollbackStmt, *ast.CreateUserStmt, *ast.SetPwdStmt,
-		*ast.GrantStmt, *ast.DropUserStmt, *ast.AlterUserStmt, *ast.RevokeStmt, *ast.KillStmt, *ast.DropStatsStmt:
+		*ast.GrantStmt, *ast.DropUserStmt, *ast.AlterUserStmt, *ast.RevokeStmt, *ast.KillStmt, *ast.DropStatsStmt,
+		*ast.CreateFunctionStmt, *ast.DropFunctionStmt, *ast.CreateRoutineStmt, *ast.DropProcedureStmt, *ast.CallStmt:
 		return b.buildSimple(node.(ast.StmtNode))
 	case ast.DDLNode:
 		return b.buildDDL(x)
@@ -260,8 +265,10 @@ func (b *planBuilder) buildSet(v *ast.SetStmt) Plan {
 		}
 		if _, ok := vars.Value.(*ast.DefaultExpr); !ok {
 			if cn, ok2 := vars.Value.(*ast.ColumnNameExpr); ok2 && cn.Name.Table.L == "" {
-				// Convert column name expression to string value expression.
-				vars.Value = ast.NewValueExpr(cn.Name.Name.O)
+				if _, isLocal := b.ctx.GetSessionVars().RoutineVars.Get(cn.Name.Name.L); !isLocal {
+					// Convert column name expression to string value expression.
+					vars.Value = ast.NewValueExpr(cn.Name.Name.O)
+				}
 			}
 			mockTablePlan := LogicalTableDual{}.init(b.ctx)
 			mockTablePlan.SetSchema(expression.NewSchema())
@@ -316,9 +323,10 @@ func availableIndices(hints []*ast.IndexHint, tableInfo *model.TableInfo) (indic
 			usableHints = append(usableHints, hint)
 		}
 	}
+	// The invisible indices can't be used by the optimizer, even if they're in the hints.
 	publicIndices := make([]*model.IndexInfo, 0, len(tableInfo.Indices))
 	for _, index := range tableInfo.Indices {
-		if index.State == model.StatePublic {
+		if index.State == model.StatePublic && !index.Invisible {
 			publicIndices = append(publicIndices, index)
 		}
 	}
@@ -413,6 +421,18 @@ func (b *planBuilder) buildAdmin(as *ast.AdminStmt) Plan {
 	case ast.AdminCheckTable:
 		p = &CheckTable{Tables: as.Tables}
 		p.SetSchema(expression.NewSchema())
+	case ast.AdminChecksumTable:
+		p = &ChecksumTable{Tables: as.Tables}
+		p.SetSchema(buildChecksumTableFields())
+	case ast.AdminCheckIndex:
+		p = &CheckIndex{Table: as.Tables[0], IndexName: as.Index, HandleRanges: as.HandleRanges}
+		p.SetSchema(expression.NewSchema())
+	case ast.AdminRecoverIndex:
+		p = &RecoverIndex{Table: as.Tables[0], IndexName: as.Index}
+		p.SetSchema(buildRecoverIndexFields())
+	case ast.AdminCleanupIndex:
+		p = &CleanupIndex{Table: as.Tables[0], IndexName: as.Index}
+		p.SetSchema(buildCleanupIndexFields())
 	case ast.AdminShowDDL:
 		p = &ShowDDL{}
 		p.SetSchema(buildShowDDLFields())
@@ -528,9 +548,11 @@ func buildShowDDLFields() *expression.Schema {
 }
 
 func buildShowDDLJobsFields() *expression.Schema {
-	schema := expression.NewSchema(make([]*expression.Column, 0, 2)...)
+	schema := expression.NewSchema(make([]*expression.Column, 0, 4)...)
 	schema.Append(buildColumn("", "JOBS", mysql.TypeVarchar, 128))
 	schema.Append(buildColumn("", "STATE", mysql.TypeVarchar, 64))
+	schema.Append(buildColumn("", "ROW_COUNT", mysql.TypeLonglong, 4))
+	schema.Append(buildColumn("", "ETA", mysql.TypeVarchar, 64))
 
 	return schema
 }
@@ -543,6 +565,32 @@ func buildCancelDDLJobsFields() *expression.Schema {
 	return schema
 }
 
+func buildChecksumTableFields() *expression.Schema {
+	schema := expression.NewSchema(make([]*expression.Column, 0, 5)...)
+	schema.Append(buildColumn("", "Db_name", mysql.TypeVarchar, 128))
+	schema.Append(buildColumn("", "Table_name", mysql.TypeVarchar, 128))
+	schema.Append(buildColumn("", "Checksum_crc64_xor", mysql.TypeLonglong, 22))
+	schema.Append(buildColumn("", "Total_kvs", mysql.TypeLonglong, 22))
+	schema.Append(buildColumn("", "Total_bytes", mysql.TypeLonglong, 22))
+
+	return schema
+}
+
+func buildRecoverIndexFields() *expression.Schema {
+	schema := expression.NewSchema(make([]*expression.Column, 0, 2)...)
+	schema.Append(buildColumn("", "ADDED_COUNT", mysql.TypeLonglong, 4))
+	schema.Append(buildColumn("", "SCAN_COUNT", mysql.TypeLonglong, 4))
+
+	return schema
+}
+
+func buildCleanupIndexFields() *expression.Schema {
+	schema := expression.NewSchema(make([]*expression.Column, 0, 1)...)
+	schema.Append(buildColumn("", "REMOVED_COUNT", mysql.TypeLonglong, 4))
+
+	return schema
+}
+
 func buildColumn(tableName, name string, tp byte, size int) *expression.Column {
 	cs, cl := types.DefaultCharsetForType(tp)
 	flag := mysql.UnsignedFlag
@@ -599,7 +647,7 @@ func (b *planBuilder) buildShow(show *ast.ShowStmt) Plan {
 		GlobalScope: show.GlobalScope,
 	}.init(b.ctx)
 	switch showTp := show.Tp; showTp {
-	case ast.ShowProcedureStatus:
+	case ast.ShowProcedureStatus, ast.ShowFunctionStatus:
 		p.SetSchema(buildShowProcedureSchema())
 	case ast.ShowTriggers:
 		p.SetSchema(buildShowTriggerSchema())
@@ -623,8 +671,13 @@ func (b *planBuilder) buildShow(show *ast.ShowStmt) Plan {
 	mockTablePlan := LogicalTableDual{}.init(b.ctx)
 	mockTablePlan.SetSchema(p.schema)
 	if show.Pattern != nil {
+		col := p.Schema().Columns[0]
+		if show.Tp == ast.ShowProcedureStatus || show.Tp == ast.ShowFunctionStatus {
+			// The pattern of SHOW PROCEDURE/FUNCTION STATUS matches the Name column.
+			col = p.Schema().Columns[1]
+		}
 		show.Pattern.Expr = &ast.ColumnNameExpr{
-			Name: &ast.ColumnName{Name: p.Schema().Columns[0].ColName},
+			Name: &ast.ColumnName{Name: col.ColName},
 		}
 		expr, _, err := b.rewrite(show.Pattern, mockTablePlan, nil, false)
 		if err != nil {
@@ -658,10 +711,38 @@ func (b *planBuilder) buildSimple(node ast.StmtNode) Plan {
 		b.visitInfo = collectVisitInfoFromGrantStmt(b.visitInfo, raw)
 	case *ast.SetPwdStmt, *ast.RevokeStmt, *ast.KillStmt:
 		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SuperPriv, "", "", "")
+	case *ast.CreateFunctionStmt:
+		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.InsertPriv, mysql.SystemDB, "func", "")
+	case *ast.DropFunctionStmt:
+		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.DeletePriv, mysql.SystemDB, "func", "")
+	case *ast.CreateRoutineStmt:
+		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.CreatePriv, b.routineDB(raw.Schema), "", "")
+		// Like MySQL, the routines which run with the privileges of other users need the SUPER privilege.
+		if raw.Definer != nil && !b.isCurrentUser(raw.Definer) {
+			b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SuperPriv, "", "", "")
+		}
+	case *ast.DropProcedureStmt:
+		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.DropPriv, b.routineDB(raw.Schema), "", "")
+	case *ast.CallStmt:
+		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.ExecutePriv, b.routineDB(raw.Schema), "", "")
 	}
 	return p
 }
 
+// isCurrentUser checks whether user is the user of the session.
+func (b *planBuilder) isCurrentUser(user *auth.UserIdentity) bool {
+	current := b.ctx.GetSessionVars().User
+	return current != nil && current.Username == user.Username && strings.EqualFold(current.Hostname, user.Hostname)
+}
+
+// routineDB returns the database of a stored routine, it's the current database if schema is empty.
+func (b *planBuilder) routineDB(schema model.CIStr) string {
+	if schema.L != "" {
+		return schema.L
+	}
+	return strings.ToLower(b.ctx.GetSessionVars().CurrentDB)
+}
+
 func collectVisitInfoFromGrantStmt(vi []visitInfo, stmt *ast.GrantStmt) []visitInfo {
 	// To use GRANT, you must have the GRANT OPTION privilege,
 	// and you must have the privileges that you are granting.
@@ -815,7 +896,13 @@ func (b *planBuilder) buildInsert(insert *ast.InsertStmt) Plan {
 		return n
 	}
 
-	cols := insertPlan.Table.Cols()
+	// The values of the hidden columns can't be specified.
+	cols := make([]*table.Column, 0, len(insertPlan.Table.Cols()))
+	for _, col := range insertPlan.Table.Cols() {
+		if !col.Hidden {
+			cols = append(cols, col)
+		}
+	}
 	maxValuesItemLength := 0 // the max length of items in VALUES list.
 	for _, valuesItem := range insert.Lists {
 		exprList := make([]expression.Expression, 0, len(valuesItem))
@@ -852,13 +939,13 @@ func (b *planBuilder) buildInsert(insert *ast.InsertStmt) Plan {
 		// The length of VALUES list maybe exceed table width,
 		// we ignore this here but do checking in executor.
 		var effectiveValuesLen int
-		if maxValuesItemLength <= len(tableInfo.Columns) {
+		if maxValuesItemLength <= len(cols) {
 			effectiveValuesLen = maxValuesItemLength
 		} else {
-			effectiveValuesLen = len(tableInfo.Columns)
+			effectiveValuesLen = len(cols)
 		}
 		for i := 0; i < effectiveValuesLen; i++ {
-			col := tableInfo.Columns[i]
+			col := cols[i]
 			if col.IsGenerated() {
 				b.err = ErrBadGeneratedColumn.GenByArgs(col.Name.O, tableInfo.Name.O)
 				return nil
@@ -924,13 +1011,13 @@ func (b *planBuilder) buildInsert(insert *ast.InsertStmt) Plan {
 		}
 		// If the schema of selectPlan contains any generated column, raises error.
 		var effectiveSelectLen int
-		if selectPlan.Schema().Len() <= len(tableInfo.Columns) {
+		if selectPlan.Schema().Len() <= len(cols) {
 			effectiveSelectLen = selectPlan.Schema().Len()
 		} else {
-			effectiveSelectLen = len(tableInfo.Columns)
+			effectiveSelectLen = len(cols)
 		}
 		for i := 0; i < effectiveSelectLen; i++ {
-			col := tableInfo.Columns[i]
+			col := cols[i]
 			if col.IsGenerated() {
 				b.err = ErrBadGeneratedColumn.GenByArgs(col.Name.O, tableInfo.Name.O)
 				return nil
@@ -953,14 +1040,33 @@ func (b *planBuilder) buildInsert(insert *ast.InsertStmt) Plan {
 
 func (b *planBuilder) buildLoadData(ld *ast.LoadDataStmt) Plan {
 	p := &LoadData{
-		IsLocal:    ld.IsLocal,
-		Path:       ld.Path,
-		Table:      ld.Table,
-		Columns:    ld.Columns,
-		FieldsInfo: ld.FieldsInfo,
-		LinesInfo:  ld.LinesInfo,
+		IsLocal:            ld.IsLocal,
+		Path:               ld.Path,
+		OnDuplicate:        ld.OnDuplicate,
+		Table:              ld.Table,
+		Columns:            ld.Columns,
+		ColumnsAndUserVars: ld.ColumnsAndUserVars,
+		FieldsInfo:         ld.FieldsInfo,
+		LinesInfo:          ld.LinesInfo,
+		IgnoreLines:        ld.IgnoreLines,
 	}
 	tableInfo := p.Table.TableInfo
+	b.visitInfo = append(b.visitInfo, visitInfo{
+		privilege: mysql.InsertPriv,
+		db:        ld.Table.Schema.L,
+		table:     tableInfo.Name.L,
+	})
+	if ld.OnDuplicate == ast.OnDuplicateKeyHandlingReplace {
+		b.visitInfo = append(b.visitInfo, visitInfo{
+			privilege: mysql.DeletePriv,
+			db:        ld.Table.Schema.L,
+			table:     tableInfo.Name.L,
+		})
+	}
+	if !ld.IsLocal {
+		// The file is read from the server host.
+		b.visitInfo = append(b.visitInfo, visitInfo{privilege: mysql.FilePriv})
+	}
 	tableInPlan, ok := b.is.TableByID(tableInfo.ID)
 	if !ok {
 		db := b.ctx.GetSessionVars().CurrentDB
@@ -970,6 +1076,28 @@ func (b *planBuilder) buildLoadData(ld *ast.LoadDataStmt) Plan {
 	schema := expression.TableInfo2Schema(tableInfo)
 	mockTablePlan := LogicalTableDual{}.init(b.ctx)
 	mockTablePlan.SetSchema(schema)
+	for _, assign := range ld.ColumnAssignments {
+		col, _, err := mockTablePlan.findColumn(assign.Column)
+		if err != nil {
+			b.err = errors.Trace(err)
+			return nil
+		}
+		column := table.FindCol(tableInPlan.Cols(), assign.Column.Name.L)
+		if column.IsGenerated() {
+			b.err = ErrBadGeneratedColumn.GenByArgs(assign.Column.Name.O, tableInfo.Name.O)
+			return nil
+		}
+		// The expression is evaluated on the row of the fields, the user variables are read when it's evaluated.
+		expr, _, err := b.rewrite(assign.Expr, mockTablePlan, nil, true)
+		if err != nil {
+			b.err = errors.Trace(err)
+			return nil
+		}
+		p.ColumnAssignments = append(p.ColumnAssignments, &expression.Assignment{
+			Col:  col,
+			Expr: expr,
+		})
+	}
 	p.GenCols = b.resolveGeneratedColumns(tableInPlan.Cols(), nil, mockTablePlan)
 	p.SetSchema(expression.NewSchema())
 	return p
@@ -977,6 +1105,11 @@ func (b *planBuilder) buildLoadData(ld *ast.LoadDataStmt) Plan {
 
 func (b *planBuilder) buildDDL(node ast.DDLNode) Plan {
 	switch v := node.(type) {
+	case *ast.AlterDatabaseStmt:
+		b.visitInfo = append(b.visitInfo, visitInfo{
+			privilege: mysql.AlterPriv,
+			db:        v.Name,
+		})
 	case *ast.AlterTableStmt:
 		b.visitInfo = append(b.visitInfo, visitInfo{
 			privilege: mysql.AlterPriv,
@@ -1032,20 +1165,79 @@ func (b *planBuilder) buildDDL(node ast.DDLNode) Plan {
 			db:        v.Table.Schema.L,
 			table:     v.Table.Name.L,
 		})
-	case *ast.RenameTableStmt:
+	case *ast.RecoverTableStmt:
 		b.visitInfo = append(b.visitInfo, visitInfo{
-			privilege: mysql.AlterPriv,
-			db:        v.OldTable.Schema.L,
-			table:     v.OldTable.Name.L,
+			privilege: mysql.DropPriv,
+			db:        v.Table.Schema.L,
+			table:     v.Table.Name.L,
 		})
+		// The table is recovered with its own name if no new name is given.
+		newName := v.NewName.L
+		if newName == "" {
+			newName = v.Table.Name.L
+		}
 		b.visitInfo = append(b.visitInfo, visitInfo{
-			privilege: mysql.AlterPriv,
-			db:        v.NewTable.Schema.L,
-			table:     v.NewTable.Name.L,
+			privilege: mysql.CreatePriv,
+			db:        v.Table.Schema.L,
+			table:     newName,
 		})
+	case *ast.RenameTableStmt:
+		for _, tables := range v.TableToTables {
+			b.visitInfo = append(b.visitInfo, visitInfo{
+				privilege: mysql.AlterPriv,
+				db:        tables.OldTable.Schema.L,
+				table:     tables.OldTable.Name.L,
+			})
+			b.visitInfo = append(b.visitInfo, visitInfo{
+				privilege: mysql.AlterPriv,
+				db:        tables.NewTable.Schema.L,
+				table:     tables.NewTable.Name.L,
+			})
+		}
 	}
 
 	p := &DDL{Statement: node}
+	if v, ok := node.(*ast.CreateTableStmt); ok && v.Select != nil {
+		selectPlan := b.build(v.Select)
+		if b.err != nil {
+			return nil
+		}
+		p.SelectPlan, b.err = doOptimize(b.optFlag, selectPlan.(LogicalPlan), b.ctx)
+		if b.err != nil {
+			return nil
+		}
+	}
+	p.SetSchema(expression.NewSchema())
+	return p
+}
+
+func (b *planBuilder) buildSelectInto(sel *ast.SelectStmt) Plan {
+	intoVars := sel.SelectIntoOpt.Tp == ast.SelectIntoVars
+	if intoVars {
+		for _, v := range sel.SelectIntoOpt.Vars {
+			if cn, ok := v.(*ast.ColumnNameExpr); ok {
+				if _, ok = b.ctx.GetSessionVars().RoutineVars.Get(cn.Name.Name.L); !ok {
+					b.err = ErrUndeclaredVar.GenByArgs(cn.Name.Name.O)
+					return nil
+				}
+			}
+		}
+	} else {
+		b.visitInfo = append(b.visitInfo, visitInfo{privilege: mysql.FilePriv})
+	}
+	selectPlan := b.buildSelect(sel)
+	if b.err != nil {
+		return nil
+	}
+	if intoVars && selectPlan.Schema().Len() != len(sel.SelectIntoOpt.Vars) {
+		b.err = ErrWrongNumberOfColumns
+		return nil
+	}
+	p := &SelectInto{IntoOpt: sel.SelectIntoOpt}
+	p.TargetPlan, b.err = doOptimize(b.optFlag, selectPlan, b.ctx)
+	if b.err != nil {
+		return nil
+	}
 	p.SetSchema(expression.NewSchema())
 	return p
 }
@@ -1070,6 +1262,8 @@ func (b *planBuilder) buildExplain(explain *ast.ExplainStmt) Plan {
 			if x.SelectPlan != nil {
 				pp = x.SelectPlan
 			}
+		case *SelectInto:
+			pp = x.TargetPlan
 		}
 		if pp == nil {
 			b.err = ErrUnsupportedType.GenByArgs(targetPlan)
@@ -1211,10 +1405,10 @@ func buildShowSchema(s *ast.ShowStmt) (schema *expression.Schema) {
 	case ast.ShowIndex:
 		names = []string{"Table", "Non_unique", "Key_name", "Seq_in_index",
 			"Column_name", "Collation", "Cardinality", "Sub_part", "Packed",
-			"Null", "Index_type", "Comment", "Index_comment"}
+			"Null", "Index_type", "Comment", "Index_comment", "Visible"}
 		ftypes = []byte{mysql.TypeVarchar, mysql.TypeLonglong, mysql.TypeVarchar, mysql.TypeLonglong,
 			mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeLonglong, mysql.TypeLonglong,
-			mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar}
+			mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar}
 	case ast.ShowPlugins:
 		names = []string{"Name", "Status", "Type", "Library", "License"}
 		ftypes = []byte{
@@ -50,6 +50,38 @@ type CheckTable struct {
 	Tables []*ast.TableName
 }
 
+// ChecksumTable is used for calculating table checksum, built from the 'admin checksum table' statement.
+type ChecksumTable struct {
+	basePlan
+
+	Tables []*ast.TableName
+}
+
+// CheckIndex is used for checking an index in handle ranges, built from the 'admin check index' statement.
+type CheckIndex struct {
+	basePlan
+
+	Table        *ast.TableName
+	IndexName    string
+	HandleRanges []ast.HandleRange
+}
+
+// RecoverIndex is used for adding the missing index entries, built from the 'admin recover index' statement.
+type RecoverIndex struct {
+	basePlan
+
+	Table     *ast.TableName
+	IndexName string
+}
+
+// CleanupIndex is used for removing the dangling index entries, built from the 'admin cleanup index' statement.
+type CleanupIndex struct {
+	basePlan
+
+	Table     *ast.TableName
+	IndexName string
+}
+
 // CancelDDLJobs represents a cancel DDL jobs plan.
 type CancelDDLJobs struct {
 	basePlan
@@ -329,21 +361,35 @@ type Analyze struct {
 type LoadData struct {
 	basePlan
 
-	IsLocal    bool
-	Path       string
-	Table      *ast.TableName
-	Columns    []*ast.ColumnName
-	FieldsInfo *ast.FieldsClause
-	LinesInfo  *ast.LinesClause
+	IsLocal            bool
+	Path               string
+	OnDuplicate        ast.OnDuplicateKeyHandlingType
+	Table              *ast.TableName
+	Columns            []*ast.ColumnName
+	ColumnsAndUserVars []*ast.ColumnNameOrUserVar
+	ColumnAssignments  []*expression.Assignment
+	FieldsInfo         *ast.FieldsClause
+	LinesInfo          *ast.LinesClause
+	IgnoreLines        uint64
 
 	GenCols InsertGeneratedColumns
 }
 
+// SelectInto represents a select-into plan, the result of TargetPlan is written into a file.
+type SelectInto struct {
+	basePlan
+
+	TargetPlan PhysicalPlan
+	IntoOpt    *ast.SelectIntoOption
+}
+
 // DDL represents a DDL statement plan.
 type DDL struct {
 	basePlan
 
 	Statement ast.DDLNode
+	// SelectPlan is the plan of the select in CREATE TABLE ... SELECT.
+	SelectPlan PhysicalPlan
 }
 
 // Explain represents a explain plan.
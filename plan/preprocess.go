@@ -31,7 +31,7 @@ import (
 
 // Preprocess resolves table names of the node, and checks some statements validation.
 func Preprocess(ctx context.Context, node ast.Node, is infoschema.InfoSchema, inPrepare bool) error {
-	v := preprocessor{is: is, ctx: ctx, inPrepare: inPrepare}
+	v := preprocessor{is: is, ctx: ctx, inPrepare: inPrepare, stmt: node}
 	node.Accept(&v)
 	return errors.Trace(v.err)
 }
@@ -43,6 +43,8 @@ type preprocessor struct {
 	ctx       context.Context
 	err       error
 	inPrepare bool
+	// stmt is the statement being preprocessed.
+	stmt ast.Node
 	// When visiting create/drop table statement.
 	inCreateOrDropTable bool
 }
@@ -55,7 +57,7 @@ func (p *preprocessor) Enter(in ast.Node) (out ast.Node, skipChildren bool) {
 	case *ast.DropTableStmt:
 		p.inCreateOrDropTable = true
 		p.checkDropTableGrammar(node)
-	case *ast.RenameTableStmt:
+	case *ast.RenameTableStmt, *ast.RecoverTableStmt:
 		p.inCreateOrDropTable = true
 	case *ast.CreateIndexStmt:
 		p.checkCreateIndexGrammar(node)
@@ -64,10 +66,26 @@ func (p *preprocessor) Enter(in ast.Node) (out ast.Node, skipChildren bool) {
 		p.checkAlterTableGrammar(node)
 	case *ast.CreateDatabaseStmt:
 		p.checkCreateDatabaseGrammar(node)
+	case *ast.AlterDatabaseStmt:
+		p.checkAlterDatabaseGrammar(node)
 	case *ast.DropDatabaseStmt:
 		p.checkDropDatabaseGrammar(node)
 	case *ast.ShowStmt:
 		p.resolveShowStmt(node)
+	case *ast.SelectStmt:
+		// The tables in the select of CREATE TABLE ... SELECT must exist.
+		p.inCreateOrDropTable = false
+		if node.SelectIntoOpt != nil && !p.isOutermostStmt(node) {
+			// Only the outermost select can write its result into a file.
+			p.err = ErrWrongUsage.GenByArgs("INTO", "subquery")
+		}
+	case *ast.UnionStmt:
+		p.inCreateOrDropTable = false
+		for _, sel := range node.SelectList.Selects {
+			if sel.SelectIntoOpt != nil {
+				p.err = ErrWrongUsage.GenByArgs("UNION", "INTO")
+			}
+		}
 	case *ast.DeleteTableList:
 		return in, true
 	}
@@ -79,7 +97,7 @@ func (p *preprocessor) Leave(in ast.Node) (out ast.Node, ok bool) {
 	case *ast.CreateTableStmt:
 		p.inCreateOrDropTable = false
 		p.checkAutoIncrement(x)
-	case *ast.DropTableStmt, *ast.AlterTableStmt, *ast.RenameTableStmt:
+	case *ast.DropTableStmt, *ast.AlterTableStmt, *ast.RenameTableStmt, *ast.RecoverTableStmt:
 		p.inCreateOrDropTable = false
 	case *ast.ParamMarkerExpr:
 		if !p.inPrepare {
@@ -107,6 +125,14 @@ func (p *preprocessor) Leave(in ast.Node) (out ast.Node, ok bool) {
 	return in, p.err == nil
 }
 
+// isOutermostStmt checks whether the node is the statement being preprocessed or the statement explained by it.
+func (p *preprocessor) isOutermostStmt(node ast.StmtNode) bool {
+	if explain, ok := p.stmt.(*ast.ExplainStmt); ok {
+		return explain.Stmt == node
+	}
+	return p.stmt == node
+}
+
 func checkAutoIncrementOp(colDef *ast.ColumnDef, num int) (bool, error) {
 	var hasAutoIncrement bool
 
@@ -139,7 +165,7 @@ func isConstraintKeyTp(constraints []*ast.Constraint, colDef *ast.ColumnDef) boo
 	for _, c := range constraints {
 		// If the constraint as follows: primary key(c1, c2)
 		// we only support c1 column can be auto_increment.
-		if colDef.Name.Name.L != c.Keys[0].Column.Name.L {
+		if len(c.Keys) == 0 || c.Keys[0].Column == nil || colDef.Name.Name.L != c.Keys[0].Column.Name.L {
 			continue
 		}
 		switch c.Tp {
@@ -211,6 +237,12 @@ func (p *preprocessor) checkCreateDatabaseGrammar(stmt *ast.CreateDatabaseStmt)
 	}
 }
 
+func (p *preprocessor) checkAlterDatabaseGrammar(stmt *ast.AlterDatabaseStmt) {
+	if isIncorrectName(stmt.Name) {
+		p.err = ddl.ErrWrongDBName.GenByArgs(stmt.Name)
+	}
+}
+
 func (p *preprocessor) checkDropDatabaseGrammar(stmt *ast.DropDatabaseStmt) {
 	if isIncorrectName(stmt.Name) {
 		p.err = ddl.ErrWrongDBName.GenByArgs(stmt.Name)
@@ -330,6 +362,12 @@ func (p *preprocessor) checkAlterTableGrammar(stmt *ast.AlterTableStmt) {
 				if p.err != nil {
 					return
 				}
+			case ast.ConstraintPrimaryKey:
+				// The name of the primary key is always PRIMARY.
+				p.err = checkIndexInfo("", spec.Constraint.Keys)
+				if p.err != nil {
+					return
+				}
 			default:
 				// Nothing to do now.
 			}
@@ -342,8 +380,15 @@ func (p *preprocessor) checkAlterTableGrammar(stmt *ast.AlterTableStmt) {
 // checkDuplicateColumnName checks if index exists duplicated columns.
 func checkDuplicateColumnName(indexColNames []*ast.IndexColName) error {
 	for i := 0; i < len(indexColNames); i++ {
+		if indexColNames[i].Column == nil {
+			// The key part is an expression.
+			continue
+		}
 		name1 := indexColNames[i].Column.Name
 		for j := i + 1; j < len(indexColNames); j++ {
+			if indexColNames[j].Column == nil {
+				continue
+			}
 			name2 := indexColNames[j].Column.Name
 			if name1.L == name2.L {
 				return infoschema.ErrColumnExists.GenByArgs(name2)
@@ -37,6 +37,14 @@ func toString(in Plan, strs []string, idxs []int) ([]string, []int) {
 	switch x := in.(type) {
 	case *CheckTable:
 		str = "CheckTable"
+	case *ChecksumTable:
+		str = "ChecksumTable"
+	case *CheckIndex:
+		str = "CheckIndex"
+	case *RecoverIndex:
+		str = "RecoverIndex"
+	case *CleanupIndex:
+		str = "CleanupIndex"
 	case *PhysicalIndexScan:
 		str = fmt.Sprintf("Index(%s.%s)%v", x.Table.Name.L, x.Index.Name.L, x.Ranges)
 	case *PhysicalTableScan:
@@ -207,6 +215,8 @@ func toString(in Plan, strs []string, idxs []int) ([]string, []int) {
 		if x.SelectPlan != nil {
 			str = fmt.Sprintf("%s->Insert", ToString(x.SelectPlan))
 		}
+	case *SelectInto:
+		str = fmt.Sprintf("%s->SelectInto", ToString(x.TargetPlan))
 	default:
 		str = fmt.Sprintf("%T", in)
 	}
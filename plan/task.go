@@ -310,6 +310,10 @@ func (p *PhysicalTopN) getCost(count float64) float64 {
 func (p *PhysicalTopN) canPushDown() bool {
 	exprs := make([]expression.Expression, 0, len(p.ByItems))
 	for _, item := range p.ByItems {
+		// The storage sorts the strings by their bytes.
+		if expression.IsCollationKeyNeeded(item.Expr.GetType()) {
+			return false
+		}
 		exprs = append(exprs, item.Expr)
 	}
 	_, _, remained := expression.ExpressionsToPB(p.ctx.GetSessionVars().StmtCtx, exprs, p.ctx.GetClient())
@@ -52,6 +52,12 @@ type UserPrivileges struct {
 	*Handle
 }
 
+// WithUser returns the privileges of the user, they share the privilege handle. The stored routines
+// whose SQL SECURITY is DEFINER run with the privileges of their definers.
+func (p *UserPrivileges) WithUser(user, host string) *UserPrivileges {
+	return &UserPrivileges{user: user, host: host, Handle: p.Handle}
+}
+
 // RequestVerification implements the Manager interface.
 func (p *UserPrivileges) RequestVerification(db, table, column string, priv mysql.PrivilegeType) bool {
 	if !Enable || SkipWithGrant {
@@ -15,8 +15,12 @@ package privileges_test
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/juju/errors"
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb"
 	"github.com/pingcap/tidb/context"
@@ -25,6 +29,7 @@ import (
 	"github.com/pingcap/tidb/privilege"
 	"github.com/pingcap/tidb/privilege/privileges"
 	"github.com/pingcap/tidb/store/tikv"
+	"github.com/pingcap/tidb/udf"
 	"github.com/pingcap/tidb/util/auth"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/testutil"
@@ -260,6 +265,121 @@ func (s *testPrivilegeSuite) TestDropTablePriv(c *C) {
 	mustExec(c, se, `DROP TABLE todrop;`)
 }
 
+func (s *testPrivilegeSuite) TestSelectIntoFilePriv(c *C) {
+	defer testleak.AfterTest(c)()
+	dir, err := ioutil.TempDir("", "select_into_priv")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+
+	se := newSession(c, s.store, s.dbName)
+	mustExec(c, se, `CREATE USER 'file'@'localhost';`)
+	mustExec(c, se, `GRANT Select ON *.* TO 'file'@'localhost';`)
+	mustExec(c, se, `FLUSH PRIVILEGES;`)
+	c.Assert(se.Auth(&auth.UserIdentity{Username: "file", Hostname: "localhost"}, nil, nil), IsTrue)
+	_, err = se.Execute(goctx.Background(), fmt.Sprintf("select 1 into outfile '%s'", filepath.Join(dir, "t1.csv")))
+	c.Assert(err, NotNil)
+
+	se = newSession(c, s.store, s.dbName)
+	mustExec(c, se, `GRANT File ON *.* TO 'file'@'localhost';`)
+	mustExec(c, se, `FLUSH PRIVILEGES;`)
+	c.Assert(se.Auth(&auth.UserIdentity{Username: "file", Hostname: "localhost"}, nil, nil), IsTrue)
+	mustExec(c, se, fmt.Sprintf("select 1 into outfile '%s'", filepath.Join(dir, "t2.csv")))
+}
+
+func (s *testPrivilegeSuite) TestLoadDataPriv(c *C) {
+	defer testleak.AfterTest(c)()
+	dir, err := ioutil.TempDir("", "load_data_priv")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "t.csv")
+	c.Assert(ioutil.WriteFile(path, []byte("1\n"), 0644), IsNil)
+
+	se := newSession(c, s.store, s.dbName)
+	mustExec(c, se, `CREATE TABLE load_data_priv (id int);`)
+	mustExec(c, se, `CREATE USER 'loader'@'localhost';`)
+	mustExec(c, se, `GRANT Insert ON *.* TO 'loader'@'localhost';`)
+	mustExec(c, se, `FLUSH PRIVILEGES;`)
+	c.Assert(se.Auth(&auth.UserIdentity{Username: "loader", Hostname: "localhost"}, nil, nil), IsTrue)
+	_, err = se.Execute(goctx.Background(), fmt.Sprintf("load data infile '%s' into table load_data_priv", path))
+	c.Assert(err, NotNil)
+
+	se = newSession(c, s.store, s.dbName)
+	mustExec(c, se, `GRANT File ON *.* TO 'loader'@'localhost';`)
+	mustExec(c, se, `FLUSH PRIVILEGES;`)
+	c.Assert(se.Auth(&auth.UserIdentity{Username: "loader", Hostname: "localhost"}, nil, nil), IsTrue)
+	mustExec(c, se, fmt.Sprintf("load data infile '%s' into table load_data_priv", path))
+	// REPLACE needs the DELETE privilege.
+	_, err = se.Execute(goctx.Background(), fmt.Sprintf("load data infile '%s' replace into table load_data_priv", path))
+	c.Assert(err, NotNil)
+}
+
+func (s *testPrivilegeSuite) TestUDFPriv(c *C) {
+	defer testleak.AfterTest(c)()
+	se := newSession(c, s.store, s.dbName)
+	mustExec(c, se, `CREATE USER 'udf'@'localhost';`)
+	mustExec(c, se, `GRANT Select ON *.* TO 'udf'@'localhost';`)
+	mustExec(c, se, `FLUSH PRIVILEGES;`)
+	c.Assert(se.Auth(&auth.UserIdentity{Username: "udf", Hostname: "localhost"}, nil, nil), IsTrue)
+	_, err := se.Execute(goctx.Background(), "CREATE FUNCTION udf_priv RETURNS STRING SONAME 'udf_priv.so'")
+	c.Assert(err, ErrorMatches, ".*privilege check fail")
+	_, err = se.Execute(goctx.Background(), "DROP FUNCTION IF EXISTS udf_priv")
+	c.Assert(err, ErrorMatches, ".*privilege check fail")
+
+	se = newSession(c, s.store, s.dbName)
+	mustExec(c, se, `GRANT Insert, Delete ON mysql.func TO 'udf'@'localhost';`)
+	mustExec(c, se, `FLUSH PRIVILEGES;`)
+	c.Assert(se.Auth(&auth.UserIdentity{Username: "udf", Hostname: "localhost"}, nil, nil), IsTrue)
+	// The privilege check passes, but the plugin can't be opened without plugin-dir.
+	_, err = se.Execute(goctx.Background(), "CREATE FUNCTION udf_priv RETURNS STRING SONAME 'udf_priv.so'")
+	c.Assert(udf.ErrCantOpenLibrary.Equal(errors.Cause(err)), IsTrue)
+	mustExec(c, se, `DROP FUNCTION IF EXISTS udf_priv;`)
+}
+
+func (s *testPrivilegeSuite) TestRoutinePriv(c *C) {
+	defer testleak.AfterTest(c)()
+	se := newSession(c, s.store, s.dbName)
+	mustExec(c, se, `CREATE TABLE routine_priv (a int);`)
+	mustExec(c, se, `INSERT INTO routine_priv VALUES (1), (2);`)
+	mustExec(c, se, `CREATE FUNCTION routine_priv_func() RETURNS INT RETURN 1;`)
+	mustExec(c, se, `CREATE PROCEDURE routine_priv_proc() SELECT 1;`)
+	mustExec(c, se, `CREATE DEFINER = 'root'@'%' FUNCTION routine_priv_definer() RETURNS INT READS SQL DATA
+		BEGIN DECLARE c INT; SELECT COUNT(*) INTO c FROM routine_priv; RETURN c; END;`)
+	mustExec(c, se, `CREATE DEFINER = 'root'@'%' FUNCTION routine_priv_invoker() RETURNS INT READS SQL DATA SQL SECURITY INVOKER
+		BEGIN DECLARE c INT; SELECT COUNT(*) INTO c FROM routine_priv; RETURN c; END;`)
+	mustExec(c, se, `CREATE USER 'routine'@'localhost';`)
+	mustExec(c, se, `FLUSH PRIVILEGES;`)
+	c.Assert(se.Auth(&auth.UserIdentity{Username: "routine", Hostname: "localhost"}, nil, nil), IsTrue)
+	_, err := se.Execute(goctx.Background(), "SELECT routine_priv_func()")
+	c.Assert(err, ErrorMatches, ".*privilege check fail")
+	_, err = se.Execute(goctx.Background(), "CALL routine_priv_proc()")
+	c.Assert(err, ErrorMatches, ".*privilege check fail")
+
+	se = newSession(c, s.store, s.dbName)
+	mustExec(c, se, fmt.Sprintf("GRANT Execute, Create ON %s.* TO 'routine'@'localhost';", s.dbName))
+	mustExec(c, se, `FLUSH PRIVILEGES;`)
+	c.Assert(se.Auth(&auth.UserIdentity{Username: "routine", Hostname: "localhost"}, nil, nil), IsTrue)
+	mustExec(c, se, "SELECT routine_priv_func()")
+	mustExec(c, se, "CALL routine_priv_proc()")
+	// The routine runs with the privileges of its definer unless its SQL SECURITY is INVOKER.
+	rs, err := se.Execute(goctx.Background(), "SELECT routine_priv_definer()")
+	c.Assert(err, IsNil)
+	row, err := rs[0].Next(goctx.Background())
+	c.Assert(err, IsNil)
+	c.Assert(row.GetInt64(0), Equals, int64(2))
+	c.Assert(rs[0].Close(), IsNil)
+	rs, err = se.Execute(goctx.Background(), "SELECT routine_priv_invoker()")
+	c.Assert(err, IsNil)
+	_, err = rs[0].Next(goctx.Background())
+	c.Assert(err, ErrorMatches, ".*privilege check fail")
+	c.Assert(rs[0].Close(), IsNil)
+	_, err = se.Execute(goctx.Background(), "SELECT COUNT(*) FROM routine_priv")
+	c.Assert(err, ErrorMatches, ".*privilege check fail")
+	// Only the users who have the SUPER privilege can create the routines of other definers.
+	_, err = se.Execute(goctx.Background(), "CREATE DEFINER = 'root'@'%' FUNCTION routine_priv_root() RETURNS INT RETURN 1")
+	c.Assert(err, ErrorMatches, ".*privilege check fail")
+	mustExec(c, se, "CREATE DEFINER = 'routine'@'localhost' FUNCTION routine_priv_self() RETURNS INT RETURN 1")
+}
+
 func (s *testPrivilegeSuite) TestCheckAuthenticate(c *C) {
 	defer testleak.AfterTest(c)()
 
@@ -0,0 +1,910 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tidb
+
+import (
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/domain"
+	"github.com/pingcap/tidb/executor"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/privilege"
+	"github.com/pingcap/tidb/privilege/privileges"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/sessionctx/varsutil"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/sqlexec"
+	goctx "golang.org/x/net/context"
+)
+
+// routine is a stored routine loaded from mysql.proc.
+type routine struct {
+	*ast.CreateRoutineStmt
+
+	db       string
+	sqlMode  string
+	definer  string
+	paramTps []*types.FieldType
+	retTp    *types.FieldType
+}
+
+// fullName returns the name of the routine qualified by its database.
+func (r *routine) fullName() string {
+	return r.db + "." + r.Name.O
+}
+
+// loadRoutine loads the stored routine from the routines cached in the domain, it returns nil if the
+// routine doesn't exist.
+func (s *session) loadRoutine(tp ast.RoutineType, db, name string) (*routine, error) {
+	info := domain.GetDomain(s).Routine(tp, db, name)
+	if info == nil {
+		return nil, nil
+	}
+	sqlMode, err := mysql.GetSQLMode(info.SQLMode)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	// The definition is parsed by a new parser, because the parser of the session may be parsing
+	// the statement which calls the routine.
+	p := parser.New()
+	p.SetSQLMode(sqlMode)
+	stmt, err := p.ParseOneStmt(info.Definition, "", "")
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	create, ok := stmt.(*ast.CreateRoutineStmt)
+	if !ok {
+		return nil, errors.Errorf("invalid definition of %s %s.%s", tp, info.DB, name)
+	}
+	// The body isn't visited when the flags of the statement are set by the parser.
+	ast.SetFlag(create.Body)
+	r := &routine{CreateRoutineStmt: create, db: info.DB, sqlMode: info.SQLMode, definer: info.Definer}
+	for _, param := range create.Params {
+		r.paramTps = append(r.paramTps, executor.RoutineFieldType(param.Tp))
+	}
+	if create.ReturnType != nil {
+		r.retTp = executor.RoutineFieldType(create.ReturnType)
+	}
+	return r, nil
+}
+
+// enterRoutine switches the session to run r with the parameters vars, the returned function
+// switches it back.
+func (s *session) enterRoutine(r *routine, vars *variable.RoutineVars) (func(), error) {
+	key := r.Tp.String() + " " + strings.ToLower(r.fullName())
+	for _, running := range s.runningRoutines {
+		if running != key {
+			continue
+		}
+		if r.Tp == ast.RoutineFunction {
+			return nil, executor.ErrNoRecursion
+		}
+		return nil, executor.ErrRecursionLimit.GenByArgs(0, r.Name.O)
+	}
+	sessionVars := s.sessionVars
+	oldSQLMode, err := varsutil.GetSessionSystemVar(sessionVars, variable.SQLModeVar)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	// Like MySQL, the routine runs with the sql_mode when it's created.
+	err = varsutil.SetSessionSystemVar(sessionVars, variable.SQLModeVar, types.NewStringDatum(r.sqlMode))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	oldVars, oldDB := sessionVars.RoutineVars, sessionVars.CurrentDB
+	sessionVars.RoutineVars = vars
+	sessionVars.CurrentDB = r.db
+	// The routine whose SQL SECURITY is DEFINER runs with the privileges of its definer.
+	oldPM := privilege.GetPrivilegeManager(s)
+	if pm, ok := oldPM.(*privileges.UserPrivileges); ok && strings.EqualFold(r.Security, "DEFINER") {
+		if i := strings.LastIndex(r.definer, "@"); i >= 0 {
+			privilege.BindPrivilegeManager(s, pm.WithUser(r.definer[:i], r.definer[i+1:]))
+		}
+	}
+	s.runningRoutines = append(s.runningRoutines, key)
+	return func() {
+		s.runningRoutines = s.runningRoutines[:len(s.runningRoutines)-1]
+		if oldPM != nil {
+			privilege.BindPrivilegeManager(s, oldPM)
+		}
+		sessionVars.RoutineVars = oldVars
+		sessionVars.CurrentDB = oldDB
+		err := varsutil.SetSessionSystemVar(sessionVars, variable.SQLModeVar, types.NewStringDatum(oldSQLMode))
+		terror.Log(errors.Trace(err))
+	}, nil
+}
+
+// inStoredFunction checks whether a stored function is running, the statements of the routines called
+// by it run in the statement which calls the function.
+func (s *session) inStoredFunction() bool {
+	prefix := ast.RoutineFunction.String() + " "
+	for _, running := range s.runningRoutines {
+		if strings.HasPrefix(running, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// CallProcedure implements sqlexec.RoutineExecutor interface.
+func (s *session) CallProcedure(goCtx goctx.Context, call *ast.CallStmt) ([]ast.RecordSet, error) {
+	db := call.Schema.O
+	if db == "" {
+		db = s.sessionVars.CurrentDB
+	}
+	if db == "" {
+		return nil, plan.ErrNoDB
+	}
+	r, err := s.loadRoutine(ast.RoutineProcedure, db, call.Name.O)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if r == nil {
+		return nil, executor.ErrRoutineNotExists.GenByArgs("PROCEDURE", db+"."+call.Name.O)
+	}
+	if len(call.Args) != len(r.Params) {
+		return nil, executor.ErrWrongNoOfArgs.GenByArgs("PROCEDURE", r.fullName(), len(r.Params), len(call.Args))
+	}
+
+	// The arguments are evaluated in the caller, before the session is switched to the procedure.
+	sc := s.sessionVars.StmtCtx
+	vars := variable.NewRoutineVars()
+	for i, param := range r.Params {
+		v := vars.Declare(param.Name, r.paramTps[i], types.Datum{})
+		if param.Mode != ast.ParamIn && !s.isVarArg(call.Args[i]) {
+			return nil, executor.ErrNotVarArg.GenByArgs(i+1, r.fullName())
+		}
+		if param.Mode == ast.ParamOut {
+			continue
+		}
+		val, err1 := expression.EvalAstExpr(call.Args[i], s)
+		if err1 != nil {
+			return nil, errors.Trace(err1)
+		}
+		if err1 = v.SetValue(sc, val); err1 != nil {
+			return nil, errors.Trace(err1)
+		}
+	}
+
+	restore, err := s.enterRoutine(r, vars)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	e := &routineExec{se: s, goCtx: goCtx, routine: r, vars: vars}
+	err = e.exec(r.Body)
+	restore()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	// Pass the OUT and INOUT parameters back to the caller.
+	for i, param := range r.Params {
+		if param.Mode == ast.ParamIn {
+			continue
+		}
+		v, _ := vars.Get(param.Name)
+		if err = s.setVarArg(call.Args[i], v.Value); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return e.recordSets, nil
+}
+
+// isVarArg checks whether arg is a variable which can be passed to an OUT or INOUT parameter.
+func (s *session) isVarArg(arg ast.ExprNode) bool {
+	switch x := arg.(type) {
+	case *ast.VariableExpr:
+		return !x.IsSystem
+	case *ast.ColumnNameExpr:
+		_, ok := s.sessionVars.RoutineVars.Get(x.Name.Name.L)
+		return ok && x.Name.Table.L == ""
+	}
+	return false
+}
+
+// setVarArg assigns val to the variable arg passed to an OUT or INOUT parameter.
+func (s *session) setVarArg(arg ast.ExprNode, val types.Datum) error {
+	switch x := arg.(type) {
+	case *ast.VariableExpr:
+		name := strings.ToLower(x.Name)
+		if val.IsNull() {
+			delete(s.sessionVars.Users, name)
+			return nil
+		}
+		str, err := val.ToString()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		s.sessionVars.Users[name] = str
+	case *ast.ColumnNameExpr:
+		v, _ := s.sessionVars.RoutineVars.Get(x.Name.Name.L)
+		return errors.Trace(v.SetValue(s.sessionVars.StmtCtx, val))
+	}
+	return nil
+}
+
+// LoadFunction implements sqlexec.RoutineExecutor interface.
+func (s *session) LoadFunction(name string) (sqlexec.StoredFunction, error) {
+	db := s.sessionVars.CurrentDB
+	if db == "" || s.sessionVars.InRestrictedSQL {
+		return nil, nil
+	}
+	r, err := s.loadRoutine(ast.RoutineFunction, db, name)
+	if r == nil || err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &storedFunction{se: s, routine: r}, nil
+}
+
+// storedFunction implements sqlexec.StoredFunction interface.
+type storedFunction struct {
+	se      *session
+	routine *routine
+}
+
+// ParamTypes implements sqlexec.StoredFunction ParamTypes interface.
+func (f *storedFunction) ParamTypes() []*types.FieldType {
+	return f.routine.paramTps
+}
+
+// RetType implements sqlexec.StoredFunction RetType interface.
+func (f *storedFunction) RetType() *types.FieldType {
+	return f.routine.retTp
+}
+
+// Call implements sqlexec.StoredFunction Call interface.
+func (f *storedFunction) Call(args []types.Datum) (types.Datum, error) {
+	s, r := f.se, f.routine
+	sc := s.sessionVars.StmtCtx
+	vars := variable.NewRoutineVars()
+	for i, param := range r.Params {
+		v := vars.Declare(param.Name, r.paramTps[i], types.Datum{})
+		if err := v.SetValue(sc, args[i]); err != nil {
+			return types.Datum{}, errors.Trace(err)
+		}
+	}
+	restore, err := s.enterRoutine(r, vars)
+	if err != nil {
+		return types.Datum{}, errors.Trace(err)
+	}
+	e := &routineExec{se: s, goCtx: goctx.Background(), routine: r, vars: vars}
+	err = e.exec(r.Body)
+	restore()
+	ret, ok := errors.Cause(err).(*returnSignal)
+	if !ok {
+		if err == nil {
+			err = executor.ErrNoReturnEnd.GenByArgs(r.Name.O)
+		}
+		return types.Datum{}, errors.Trace(err)
+	}
+	if ret.value.IsNull() {
+		return ret.value, nil
+	}
+	d, err := ret.value.ConvertTo(sc, r.retTp)
+	return d, errors.Trace(err)
+}
+
+// leaveSignal is raised by LEAVE, it's caught by the block or the loop with the label.
+type leaveSignal struct {
+	label string
+}
+
+func (s *leaveSignal) Error() string {
+	return "LEAVE " + s.label
+}
+
+// iterateSignal is raised by ITERATE, it's caught by the loop with the label.
+type iterateSignal struct {
+	label string
+}
+
+func (s *iterateSignal) Error() string {
+	return "ITERATE " + s.label
+}
+
+// returnSignal is raised by RETURN, it's caught by the stored function.
+type returnSignal struct {
+	value types.Datum
+}
+
+func (s *returnSignal) Error() string {
+	return "RETURN"
+}
+
+// exitSignal is raised after an EXIT handler runs, it's caught by the block which declares the handler.
+type exitSignal struct {
+	block *routineBlock
+}
+
+func (s *exitSignal) Error() string {
+	return "EXIT"
+}
+
+// isSignal checks whether err is raised by the flow control of stored routines, the handlers
+// don't handle them.
+func isSignal(err error) bool {
+	switch errors.Cause(err).(type) {
+	case *leaveSignal, *iterateSignal, *returnSignal, *exitSignal:
+		return true
+	}
+	return false
+}
+
+// routineBlock is a running BEGIN ... END block.
+type routineBlock struct {
+	cursors  map[string]*routineCursor
+	handlers []*ast.DeclareHandlerStmt
+}
+
+// routineCursor is a cursor declared in a block, the rows are read when it's opened.
+type routineCursor struct {
+	stmt *ast.DeclareCursorStmt
+	// rs is nil when the cursor is closed.
+	rs *routineRecordSet
+}
+
+// routineExec runs the body of a stored routine statement by statement.
+type routineExec struct {
+	se      *session
+	goCtx   goctx.Context
+	routine *routine
+	vars    *variable.RoutineVars
+	// blocks are the running blocks, the innermost one is the last.
+	blocks []*routineBlock
+	// activeHandlers are the running handlers, a handler doesn't handle the conditions raised by itself.
+	activeHandlers map[*ast.DeclareHandlerStmt]bool
+	recordSets     []ast.RecordSet
+}
+
+func (e *routineExec) exec(stmt ast.StmtNode) error {
+	switch x := stmt.(type) {
+	case *ast.BlockStmt:
+		return errors.Trace(e.execBlock(x))
+	case *ast.IfStmt:
+		ok, err := e.evalCond(x.Cond)
+		if err != nil {
+			return errors.Trace(e.handle(err))
+		}
+		if ok {
+			return errors.Trace(e.execStmts(x.Then))
+		}
+		return errors.Trace(e.execStmts(x.Else))
+	case *ast.WhileStmt:
+		for {
+			ok, err := e.evalCond(x.Cond)
+			if err != nil {
+				return errors.Trace(e.handle(err))
+			}
+			if !ok {
+				return nil
+			}
+			if done, err := e.execLoopBody(x.Label, x.Body); done || err != nil {
+				return errors.Trace(err)
+			}
+		}
+	case *ast.RepeatStmt:
+		for {
+			if done, err := e.execLoopBody(x.Label, x.Body); done || err != nil {
+				return errors.Trace(err)
+			}
+			ok, err := e.evalCond(x.Until)
+			if err != nil {
+				return errors.Trace(e.handle(err))
+			}
+			if ok {
+				return nil
+			}
+		}
+	case *ast.LoopStmt:
+		for {
+			if done, err := e.execLoopBody(x.Label, x.Body); done || err != nil {
+				return errors.Trace(err)
+			}
+		}
+	case *ast.LeaveStmt:
+		return &leaveSignal{label: strings.ToLower(x.Label)}
+	case *ast.IterateStmt:
+		return &iterateSignal{label: strings.ToLower(x.Label)}
+	}
+	return errors.Trace(e.handle(e.execSimple(stmt)))
+}
+
+func (e *routineExec) execStmts(stmts []ast.StmtNode) error {
+	for _, stmt := range stmts {
+		if err := e.exec(stmt); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+func (e *routineExec) execBlock(block *ast.BlockStmt) error {
+	blk := &routineBlock{cursors: make(map[string]*routineCursor)}
+	e.vars.PushScope()
+	e.blocks = append(e.blocks, blk)
+	defer func() {
+		e.vars.PopScope()
+		e.blocks = e.blocks[:len(e.blocks)-1]
+	}()
+	for _, stmt := range block.Stmts {
+		var err error
+		switch x := stmt.(type) {
+		case *ast.DeclareVarStmt:
+			err = e.handle(e.declareVars(x))
+		case *ast.DeclareCursorStmt:
+			blk.cursors[strings.ToLower(x.Name)] = &routineCursor{stmt: x}
+		case *ast.DeclareHandlerStmt:
+			blk.handlers = append(blk.handlers, x)
+		default:
+			err = e.exec(stmt)
+		}
+		if err == nil {
+			continue
+		}
+		switch x := errors.Cause(err).(type) {
+		case *leaveSignal:
+			if block.Label != "" && x.label == strings.ToLower(block.Label) {
+				return nil
+			}
+		case *exitSignal:
+			if x.block == blk {
+				return nil
+			}
+		}
+		return errors.Trace(err)
+	}
+	return nil
+}
+
+// execLoopBody runs an iteration of the loop with the label, done reports whether the loop is left.
+func (e *routineExec) execLoopBody(label string, body []ast.StmtNode) (done bool, err error) {
+	err = e.execStmts(body)
+	if err == nil {
+		return false, nil
+	}
+	label = strings.ToLower(label)
+	switch x := errors.Cause(err).(type) {
+	case *leaveSignal:
+		if label != "" && x.label == label {
+			return true, nil
+		}
+	case *iterateSignal:
+		if label != "" && x.label == label {
+			return false, nil
+		}
+	}
+	return true, errors.Trace(err)
+}
+
+func (e *routineExec) declareVars(stmt *ast.DeclareVarStmt) error {
+	var val types.Datum
+	if stmt.Default != nil {
+		e.prepareTxnCtx()
+		var err error
+		val, err = expression.EvalAstExpr(stmt.Default, e.se)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	tp := executor.RoutineFieldType(stmt.Tp)
+	for _, name := range stmt.Names {
+		v := e.vars.Declare(name, tp, types.Datum{})
+		if err := v.SetValue(e.se.sessionVars.StmtCtx, val); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// prepareTxnCtx makes sure the expressions of a stored procedure are evaluated with the information
+// schema, a stored function runs in the statement which calls it.
+func (e *routineExec) prepareTxnCtx() {
+	if e.routine.Tp == ast.RoutineProcedure {
+		e.se.PrepareTxnCtx(e.goCtx)
+	}
+}
+
+func (e *routineExec) evalCond(expr ast.ExprNode) (bool, error) {
+	e.prepareTxnCtx()
+	d, err := expression.EvalAstExpr(expr, e.se)
+	if err != nil || d.IsNull() {
+		return false, errors.Trace(err)
+	}
+	b, err := d.ToBool(e.se.sessionVars.StmtCtx)
+	return b != 0, errors.Trace(err)
+}
+
+// execSimple runs a statement which isn't a compound statement.
+func (e *routineExec) execSimple(stmt ast.StmtNode) error {
+	switch x := stmt.(type) {
+	case *ast.ReturnStmt:
+		e.prepareTxnCtx()
+		d, err := expression.EvalAstExpr(x.Expr, e.se)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		return &returnSignal{value: d}
+	case *ast.OpenCursorStmt:
+		return errors.Trace(e.openCursor(x))
+	case *ast.FetchCursorStmt:
+		return errors.Trace(e.fetchCursor(x))
+	case *ast.CloseCursorStmt:
+		return errors.Trace(e.closeCursor(x))
+	}
+	rs, warns, err := e.execSQL(stmt)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if rs != nil {
+		e.recordSets = append(e.recordSets, rs)
+	}
+	for _, warn := range warns {
+		code, state := conditionOf(warn)
+		if blk, h := e.findHandler(code, state, true); h != nil {
+			return errors.Trace(e.runHandler(blk, h))
+		}
+	}
+	return nil
+}
+
+// execSQL runs a SQL statement and returns its result set and warnings.
+func (e *routineExec) execSQL(node ast.StmtNode) (*routineRecordSet, []error, error) {
+	s := e.se
+	if s.inStoredFunction() {
+		return e.execSQLInFunction(node)
+	}
+	s.PrepareTxnCtx(e.goCtx)
+	executor.ResetStmtCtx(s, node)
+	if call, ok := node.(*ast.CallStmt); ok {
+		// The result sets of the nested procedure are returned by the outermost CALL.
+		recordSets, err := s.CallProcedure(e.goCtx, call)
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		e.recordSets = append(e.recordSets, recordSets...)
+		return nil, s.sessionVars.StmtCtx.GetWarnings(), nil
+	}
+	compiler := executor.Compiler{Ctx: s}
+	stmt, err := compiler.Compile(e.goCtx, node)
+	if err != nil {
+		if !s.sessionVars.InTxn() {
+			terror.Log(errors.Trace(s.RollbackTxn(e.goCtx)))
+		}
+		return nil, nil, errors.Trace(err)
+	}
+	rs, err := runStmt(e.goCtx, s, stmt)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	var result *routineRecordSet
+	if rs != nil {
+		if result, err = newRoutineRecordSet(e.goCtx, rs); err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+	}
+	return result, s.sessionVars.StmtCtx.GetWarnings(), nil
+}
+
+// execSQLInFunction runs a SQL statement of a stored function, or of a procedure called by it, as a part
+// of the statement which calls the function: it runs in the transaction of the caller, it's neither
+// committed nor retried by itself, and its warnings are added to the caller. The statement context of
+// the caller is restored after it runs.
+func (e *routineExec) execSQLInFunction(node ast.StmtNode) (*routineRecordSet, []error, error) {
+	s := e.se
+	sessionVars := s.sessionVars
+	sc := sessionVars.StmtCtx
+	lastInsertID, prevLastInsertID, insertID := sessionVars.LastInsertID, sessionVars.PrevLastInsertID, sessionVars.InsertID
+	prevAffectedRows := sessionVars.PrevAffectedRows
+	defer func() {
+		sessionVars.StmtCtx = sc
+		sessionVars.LastInsertID, sessionVars.PrevLastInsertID, sessionVars.InsertID = lastInsertID, prevLastInsertID, insertID
+		sessionVars.PrevAffectedRows = prevAffectedRows
+	}()
+
+	// The transaction of the caller is kept until its rows are read, see runStmt. It's pending when
+	// the function is called by the expressions of a procedure.
+	s.PrepareTxnCtx(e.goCtx)
+	if err := s.ActivePendingTxn(); err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	executor.ResetStmtCtx(s, node)
+	compiler := executor.Compiler{Ctx: s}
+	stmt, err := compiler.Compile(e.goCtx, node)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	rs, err := stmt.Exec(e.goCtx)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	var result *routineRecordSet
+	if rs != nil {
+		if result, err = newRoutineRecordSet(e.goCtx, rs); err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+	}
+	warns := sessionVars.StmtCtx.GetWarnings()
+	for _, warn := range warns {
+		sc.AppendWarning(warn)
+	}
+	return result, warns, nil
+}
+
+// findCursor looks up the cursor name from the innermost block.
+func (e *routineExec) findCursor(name string) (*routineCursor, error) {
+	lower := strings.ToLower(name)
+	for i := len(e.blocks) - 1; i >= 0; i-- {
+		if c, ok := e.blocks[i].cursors[lower]; ok {
+			return c, nil
+		}
+	}
+	return nil, executor.ErrCursorMismatch.GenByArgs(name)
+}
+
+func (e *routineExec) openCursor(stmt *ast.OpenCursorStmt) error {
+	c, err := e.findCursor(stmt.Name)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if c.rs != nil {
+		return executor.ErrCursorAlreadyOpen
+	}
+	rs, _, err := e.execSQL(c.stmt.Select)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	c.rs = rs
+	return nil
+}
+
+func (e *routineExec) fetchCursor(stmt *ast.FetchCursorStmt) error {
+	c, err := e.findCursor(stmt.Name)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if c.rs == nil {
+		return executor.ErrCursorNotOpen
+	}
+	if len(stmt.Vars) != len(c.rs.fields) {
+		return executor.ErrWrongNoOfFetchArgs
+	}
+	row, err := c.rs.Next(e.goCtx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if row == nil {
+		return executor.ErrNoData
+	}
+	sc := e.se.sessionVars.StmtCtx
+	for i, name := range stmt.Vars {
+		v, ok := e.vars.Get(name)
+		if !ok {
+			return plan.ErrUndeclaredVar.GenByArgs(name)
+		}
+		if err = v.SetValue(sc, row.GetDatum(i, &c.rs.fields[i].Column.FieldType)); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+func (e *routineExec) closeCursor(stmt *ast.CloseCursorStmt) error {
+	c, err := e.findCursor(stmt.Name)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if c.rs == nil {
+		return executor.ErrCursorNotOpen
+	}
+	c.rs = nil
+	return nil
+}
+
+// handle runs the handler of the condition err raised by a statement. It returns nil if the execution
+// goes on with the next statement, or err if no handler handles it.
+func (e *routineExec) handle(err error) error {
+	if err == nil || isSignal(err) {
+		return errors.Trace(err)
+	}
+	code, state := conditionOf(err)
+	blk, h := e.findHandler(code, state, false)
+	if h == nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(e.runHandler(blk, h))
+}
+
+func (e *routineExec) runHandler(blk *routineBlock, h *ast.DeclareHandlerStmt) error {
+	if e.activeHandlers == nil {
+		e.activeHandlers = make(map[*ast.DeclareHandlerStmt]bool)
+	}
+	e.activeHandlers[h] = true
+	err := e.exec(h.Stmt)
+	delete(e.activeHandlers, h)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if h.Action == ast.HandlerExit {
+		return &exitSignal{block: blk}
+	}
+	return nil
+}
+
+// findHandler finds the handler of the condition from the innermost block.
+func (e *routineExec) findHandler(code uint16, state string, isWarning bool) (*routineBlock, *ast.DeclareHandlerStmt) {
+	for i := len(e.blocks) - 1; i >= 0; i-- {
+		var (
+			blk      = e.blocks[i]
+			best     *ast.DeclareHandlerStmt
+			bestRank int
+		)
+		for _, h := range blk.handlers {
+			if e.activeHandlers[h] {
+				continue
+			}
+			for _, cond := range h.Conditions {
+				if rank := matchCondition(cond, code, state, isWarning); rank > bestRank {
+					best, bestRank = h, rank
+				}
+			}
+		}
+		if best != nil {
+			return blk, best
+		}
+	}
+	return nil, nil
+}
+
+// matchCondition returns the priority of cond if it matches the condition, or 0 if it doesn't match.
+// Like MySQL, an error code is more specific than a SQLSTATE value, and a SQLSTATE value is more
+// specific than SQLWARNING, NOT FOUND and SQLEXCEPTION.
+func matchCondition(cond *ast.HandlerCondition, code uint16, state string, isWarning bool) int {
+	class := state[:2]
+	switch cond.Tp {
+	case ast.HandlerErrorCode:
+		if cond.ErrorCode == code {
+			return 3
+		}
+	case ast.HandlerSQLState:
+		if cond.SQLState == state {
+			return 2
+		}
+	case ast.HandlerSQLWarning:
+		if class == "01" || (isWarning && class != "02") {
+			return 1
+		}
+	case ast.HandlerNotFound:
+		if class == "02" {
+			return 1
+		}
+	case ast.HandlerSQLException:
+		if !isWarning && class != "00" && class != "01" && class != "02" {
+			return 1
+		}
+	}
+	return 0
+}
+
+// conditionOf returns the MySQL error code and the SQLSTATE value of err.
+func conditionOf(err error) (uint16, string) {
+	switch x := errors.Cause(err).(type) {
+	case *terror.Error:
+		sqlErr := x.ToSQLError()
+		return sqlErr.Code, sqlErr.State
+	case *mysql.SQLError:
+		return x.Code, x.State
+	}
+	return mysql.ErrUnknown, mysql.DefaultMySQLState
+}
+
+// routineRecordSet is a result set of a stored procedure. The statement which returns it is done
+// before the next statement runs, so the rows are kept in memory.
+type routineRecordSet struct {
+	fields []*ast.ResultField
+	rows   []types.Row
+	cursor int
+}
+
+func newRoutineRecordSet(goCtx goctx.Context, rs ast.RecordSet) (*routineRecordSet, error) {
+	rows, err := drainRecordSet(goCtx, rs)
+	if err != nil {
+		terror.Log(errors.Trace(rs.Close()))
+		return nil, errors.Trace(err)
+	}
+	fields := rs.Fields()
+	if err = rs.Close(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &routineRecordSet{fields: fields, rows: rows}, nil
+}
+
+// Fields implements ast.RecordSet Fields interface.
+func (rs *routineRecordSet) Fields() []*ast.ResultField {
+	return rs.fields
+}
+
+// Next implements ast.RecordSet Next interface.
+func (rs *routineRecordSet) Next(goCtx goctx.Context) (types.Row, error) {
+	if rs.cursor >= len(rs.rows) {
+		return nil, nil
+	}
+	row := rs.rows[rs.cursor]
+	rs.cursor++
+	return row, nil
+}
+
+// NextChunk implements ast.RecordSet NextChunk interface.
+func (rs *routineRecordSet) NextChunk(goCtx goctx.Context, chk *chunk.Chunk) error {
+	return errors.New("routineRecordSet doesn't support chunk")
+}
+
+// NewChunk implements ast.RecordSet NewChunk interface.
+func (rs *routineRecordSet) NewChunk() *chunk.Chunk {
+	return nil
+}
+
+// SupportChunk implements ast.RecordSet SupportChunk interface.
+func (rs *routineRecordSet) SupportChunk() bool {
+	return false
+}
+
+// Close implements ast.RecordSet Close interface.
+func (rs *routineRecordSet) Close() error {
+	rs.cursor = 0
+	return nil
+}
+
+// autocommitRecordSet is the result set of an autocommit statement which calls stored functions. The
+// functions run their statements in the transaction of the statement while the rows are read, so the
+// transaction is committed when the result set is closed, or rolled back if the rows fail to be read.
+type autocommitRecordSet struct {
+	ast.RecordSet
+	se  *session
+	err error
+}
+
+// Next implements ast.RecordSet Next interface.
+func (rs *autocommitRecordSet) Next(goCtx goctx.Context) (types.Row, error) {
+	row, err := rs.RecordSet.Next(goCtx)
+	if err != nil {
+		rs.err = err
+	}
+	return row, errors.Trace(err)
+}
+
+// NextChunk implements ast.RecordSet NextChunk interface.
+func (rs *autocommitRecordSet) NextChunk(goCtx goctx.Context, chk *chunk.Chunk) error {
+	err := rs.RecordSet.NextChunk(goCtx, chk)
+	if err != nil {
+		rs.err = err
+	}
+	return errors.Trace(err)
+}
+
+// Close implements ast.RecordSet Close interface.
+func (rs *autocommitRecordSet) Close() error {
+	err := rs.RecordSet.Close()
+	if rs.err != nil || err != nil {
+		terror.Log(errors.Trace(rs.se.RollbackTxn(goctx.Background())))
+		return errors.Trace(err)
+	}
+	return errors.Trace(rs.se.CommitTxn(goctx.Background()))
+}
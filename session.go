@@ -133,6 +133,9 @@ type session struct {
 	sessionManager util.SessionManager
 
 	statsCollector *statistics.SessionStatsCollector
+
+	// runningRoutines are the stored routines being called, it's used to detect recursion.
+	runningRoutines []string
 }
 
 func (s *session) cleanRetryInfo() {
@@ -688,6 +691,17 @@ func (s *session) executeStatement(goCtx goctx.Context, connID uint64, stmtNode
 	return recordSets, nil
 }
 
+func (s *session) executeCall(goCtx goctx.Context, connID uint64, call *ast.CallStmt, recordSets []ast.RecordSet) ([]ast.RecordSet, error) {
+	s.SetValue(context.QueryString, call.Text())
+	s.ClearValue(context.LastExecuteDDL)
+	callRecordSets, err := s.CallProcedure(goCtx, call)
+	if err != nil {
+		log.Warnf("[%d] call procedure error:\n%v\n%s", connID, errors.ErrorStack(err), s)
+		return nil, errors.Trace(err)
+	}
+	return append(recordSets, callRecordSets...), nil
+}
+
 func (s *session) Execute(goCtx goctx.Context, sql string) (recordSets []ast.RecordSet, err error) {
 	if span := opentracing.SpanFromContext(goCtx); span != nil {
 		span, goCtx = opentracing.StartSpanFromContext(goCtx, "session.Execute")
@@ -760,6 +774,13 @@ func (s *session) Execute(goCtx goctx.Context, sql string) (recordSets []ast.Rec
 			}
 
 			// Step4: Execute the physical plan.
+			if call, ok := stmtNode.(*ast.CallStmt); ok {
+				// CALL runs the statements of the procedure one by one, their result sets are returned.
+				if recordSets, err = s.executeCall(goCtx, connID, call, recordSets); err != nil {
+					return nil, errors.Trace(err)
+				}
+				continue
+			}
 			if recordSets, err = s.executeStatement(goCtx, connID, stmtNode, stmt, recordSets); err != nil {
 				return nil, errors.Trace(err)
 			}
@@ -1013,6 +1034,12 @@ func BootstrapSession(store kv.Storage) (*domain.Domain, error) {
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	if err = loadNewCollationEnabled(se); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err = loadTimeZones(se); err != nil {
+		return nil, errors.Trace(err)
+	}
 	dom := domain.GetDomain(se)
 	err = dom.LoadPrivilegeLoop(se)
 	if err != nil {
@@ -1022,7 +1049,23 @@ func BootstrapSession(store kv.Storage) (*domain.Domain, error) {
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	err = dom.UpdateTableStatsLoop(se1)
+	err = dom.LoadUDFLoop(se1)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	se2, err := createSession(store)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	err = dom.LoadRoutineLoop(se2)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	se3, err := createSession(store)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	err = dom.UpdateTableStatsLoop(se3)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -1104,7 +1147,7 @@ func createSessionWithDomain(store kv.Storage, dom *domain.Domain) (*session, er
 
 const (
 	notBootstrapped         = 0
-	currentBootstrapVersion = 16
+	currentBootstrapVersion = 20
 )
 
 func getStoreBootstrapVersion(store kv.Storage) int64 {
@@ -1315,8 +1358,9 @@ func (s *session) ShowProcess() util.ProcessInfo {
 func logCrucialStmt(node ast.StmtNode, user *auth.UserIdentity) {
 	switch stmt := node.(type) {
 	case *ast.CreateUserStmt, *ast.DropUserStmt, *ast.AlterUserStmt, *ast.SetPwdStmt, *ast.GrantStmt,
-		*ast.RevokeStmt, *ast.AlterTableStmt, *ast.CreateDatabaseStmt, *ast.CreateIndexStmt, *ast.CreateTableStmt,
-		*ast.DropDatabaseStmt, *ast.DropIndexStmt, *ast.DropTableStmt, *ast.RenameTableStmt, *ast.TruncateTableStmt:
+		*ast.RevokeStmt, *ast.AlterDatabaseStmt, *ast.AlterTableStmt, *ast.CreateDatabaseStmt, *ast.CreateIndexStmt, *ast.CreateTableStmt,
+		*ast.DropDatabaseStmt, *ast.DropIndexStmt, *ast.DropTableStmt, *ast.RenameTableStmt, *ast.TruncateTableStmt,
+		*ast.CreateFunctionStmt, *ast.DropFunctionStmt, *ast.CreateRoutineStmt, *ast.DropProcedureStmt:
 		if ss, ok := node.(ast.SensitiveStmtNode); ok {
 			log.Infof("[CRUCIAL OPERATION] %s (by %s).", ss.SecureText(), user)
 		} else {
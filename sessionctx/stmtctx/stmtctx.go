@@ -37,6 +37,9 @@ type StatementContext struct {
 	InShowWarning          bool
 	UseCache               bool
 	PadCharToFullLength    bool
+	// CallsStoredFunction is set when the statement calls stored functions, which run their statements
+	// in the transaction of the statement while it's executed.
+	CallsStoredFunction bool
 
 	// mu struct holds variables that change during execution.
 	mu struct {
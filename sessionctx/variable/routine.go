@@ -0,0 +1,86 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package variable
+
+import (
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/sessionctx/stmtctx"
+	"github.com/pingcap/tidb/types"
+)
+
+// RoutineVar is a parameter or a local variable of a stored routine.
+type RoutineVar struct {
+	Tp    *types.FieldType
+	Value types.Datum
+}
+
+// SetValue converts val to the declared type of the variable and assigns it.
+func (v *RoutineVar) SetValue(sc *stmtctx.StatementContext, val types.Datum) error {
+	if val.IsNull() {
+		v.Value.SetNull()
+		return nil
+	}
+	d, err := val.ConvertTo(sc, v.Tp)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	v.Value = d
+	return nil
+}
+
+// RoutineVars holds the parameters and the local variables of the running stored routine.
+// Every BEGIN ... END block pushes a scope, and the names are looked up from the innermost scope.
+type RoutineVars struct {
+	scopes []map[string]*RoutineVar
+}
+
+// NewRoutineVars creates a RoutineVars with a scope for the parameters.
+func NewRoutineVars() *RoutineVars {
+	rv := &RoutineVars{}
+	rv.PushScope()
+	return rv
+}
+
+// PushScope enters a new block.
+func (rv *RoutineVars) PushScope() {
+	rv.scopes = append(rv.scopes, make(map[string]*RoutineVar))
+}
+
+// PopScope leaves the innermost block, the variables declared in it are dropped.
+func (rv *RoutineVars) PopScope() {
+	rv.scopes = rv.scopes[:len(rv.scopes)-1]
+}
+
+// Declare adds a variable to the innermost scope.
+func (rv *RoutineVars) Declare(name string, tp *types.FieldType, val types.Datum) *RoutineVar {
+	v := &RoutineVar{Tp: tp, Value: val}
+	rv.scopes[len(rv.scopes)-1][strings.ToLower(name)] = v
+	return v
+}
+
+// Get returns the variable name, it's safe to be called on a nil RoutineVars.
+func (rv *RoutineVars) Get(name string) (*RoutineVar, bool) {
+	if rv == nil {
+		return nil, false
+	}
+	name = strings.ToLower(name)
+	for i := len(rv.scopes) - 1; i >= 0; i-- {
+		if v, ok := rv.scopes[i][name]; ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
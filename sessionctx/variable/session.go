@@ -112,6 +112,9 @@ type SessionVars struct {
 	UsersLock sync.RWMutex
 	// Users are user defined variables.
 	Users map[string]string
+	// RoutineVars are the parameters and the local variables of the running stored routine,
+	// it's nil outside stored routines.
+	RoutineVars *RoutineVars
 	// Systems are system variables.
 	Systems map[string]string
 	// PreparedStmts stores prepared statement.
@@ -620,6 +620,9 @@ var defaultSysVars = []*SysVar{
 	{ScopeSession, TiDBDMLBatchSize, strconv.Itoa(DefDMLBatchSize)},
 	{ScopeSession, TiDBCurrentTS, strconv.Itoa(DefCurretTS)},
 	{ScopeSession, TiDBMaxChunkSize, strconv.Itoa(DefMaxChunkSize)},
+	{ScopeGlobal, TiDBDDLReorgWorkerCount, strconv.Itoa(DefTiDBDDLReorgWorkerCount)},
+	{ScopeGlobal, TiDBDDLReorgBatchSize, strconv.Itoa(DefTiDBDDLReorgBatchSize)},
+	{ScopeGlobal, TiDBDDLReorgRateLimit, strconv.Itoa(DefTiDBDDLReorgRateLimit)},
 }
 
 // SetNamesVariables is the system variable names related to set names statements.
@@ -630,6 +633,8 @@ var SetNamesVariables = []string{
 }
 
 const (
+	// CharacterSetClient is the name for character_set_client system variable.
+	CharacterSetClient = "character_set_client"
 	// CharacterSetConnection is the name for character_set_connection system variable.
 	CharacterSetConnection = "character_set_connection"
 	// CollationConnection is the name for collation_connection system variable.
@@ -13,6 +13,10 @@
 
 package variable
 
+import (
+	"sync/atomic"
+)
+
 /*
 	Steps to add a new TiDB specific system variable:
 
@@ -104,6 +108,17 @@ const (
 
 	// tidb_max_chunk_capacity is used to control the max chunk size during query execution.
 	TiDBMaxChunkSize = "tidb_max_chunk_size"
+
+	// tidb_ddl_reorg_worker_cnt is the number of workers that backfill the index of ADD INDEX concurrently.
+	// Every worker backfills a region of the table at a time.
+	TiDBDDLReorgWorkerCount = "tidb_ddl_reorg_worker_cnt"
+
+	// tidb_ddl_reorg_batch_size is the number of rows backfilled in a transaction by ADD INDEX.
+	TiDBDDLReorgBatchSize = "tidb_ddl_reorg_batch_size"
+
+	// tidb_ddl_reorg_rate_limit is the maximum number of rows backfilled per second by ADD INDEX, it's used to
+	// limit the impact on the online traffic. 0 means no limit.
+	TiDBDDLReorgRateLimit = "tidb_ddl_reorg_rate_limit"
 )
 
 // Default TiDB system variable values.
@@ -122,4 +137,45 @@ const (
 	DefCurretTS                   = 0
 	DefMaxChunkSize               = 1024
 	DefDMLBatchSize               = 20000
+	DefTiDBDDLReorgWorkerCount    = 16
+	DefTiDBDDLReorgBatchSize      = 256
+	DefTiDBDDLReorgRateLimit      = 0
+)
+
+// The values of the global variables which control the backfilling of ADD INDEX, they are loaded by the DDL owner
+// before every round of the backfilling, so they take effect on the running job.
+var (
+	ddlReorgWorkerCounter int32 = DefTiDBDDLReorgWorkerCount
+	ddlReorgBatchSize     int32 = DefTiDBDDLReorgBatchSize
+	ddlReorgRateLimit     int64 = DefTiDBDDLReorgRateLimit
 )
+
+// SetDDLReorgWorkerCounter sets ddlReorgWorkerCounter count.
+func SetDDLReorgWorkerCounter(cnt int32) {
+	atomic.StoreInt32(&ddlReorgWorkerCounter, cnt)
+}
+
+// GetDDLReorgWorkerCounter gets ddlReorgWorkerCounter.
+func GetDDLReorgWorkerCounter() int32 {
+	return atomic.LoadInt32(&ddlReorgWorkerCounter)
+}
+
+// SetDDLReorgBatchSize sets ddlReorgBatchSize.
+func SetDDLReorgBatchSize(cnt int32) {
+	atomic.StoreInt32(&ddlReorgBatchSize, cnt)
+}
+
+// GetDDLReorgBatchSize gets ddlReorgBatchSize.
+func GetDDLReorgBatchSize() int32 {
+	return atomic.LoadInt32(&ddlReorgBatchSize)
+}
+
+// SetDDLReorgRateLimit sets ddlReorgRateLimit.
+func SetDDLReorgRateLimit(limit int64) {
+	atomic.StoreInt64(&ddlReorgRateLimit, limit)
+}
+
+// GetDDLReorgRateLimit gets ddlReorgRateLimit.
+func GetDDLReorgRateLimit() int64 {
+	return atomic.LoadInt64(&ddlReorgRateLimit)
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/timeutil"
 )
 
 // GetSessionSystemVar gets a system variable.
@@ -168,6 +169,12 @@ func SetSessionSystemVar(vars *variable.SessionVars, name string, value types.Da
 		return variable.ErrReadOnly
 	case variable.TiDBMaxChunkSize:
 		vars.MaxChunkSize = tidbOptPositiveInt(sVal, variable.DefMaxChunkSize)
+	case variable.TiDBDDLReorgWorkerCount:
+		variable.SetDDLReorgWorkerCounter(int32(tidbOptPositiveInt(sVal, variable.DefTiDBDDLReorgWorkerCount)))
+	case variable.TiDBDDLReorgBatchSize:
+		variable.SetDDLReorgBatchSize(int32(tidbOptPositiveInt(sVal, variable.DefTiDBDDLReorgBatchSize)))
+	case variable.TiDBDDLReorgRateLimit:
+		variable.SetDDLReorgRateLimit(tidbOptNonNegativeInt64(sVal, variable.DefTiDBDDLReorgRateLimit))
 	}
 	vars.Systems[name] = sVal
 	return nil
@@ -186,30 +193,20 @@ func tidbOptPositiveInt(opt string, defaultVal int) int {
 	return val
 }
 
-func parseTimeZone(s string) (*time.Location, error) {
-	if s == "SYSTEM" {
-		// TODO: Support global time_zone variable, it should be set to global time_zone value.
-		return time.Local, nil
-	}
-
-	loc, err := time.LoadLocation(s)
-	if err == nil {
-		return loc, nil
+func tidbOptNonNegativeInt64(opt string, defaultVal int64) int64 {
+	val, err := strconv.ParseInt(opt, 10, 64)
+	if err != nil || val < 0 {
+		return defaultVal
 	}
+	return val
+}
 
-	// The value can be given as a string indicating an offset from UTC, such as '+10:00' or '-6:00'.
-	if strings.HasPrefix(s, "+") || strings.HasPrefix(s, "-") {
-		d, err := types.ParseDuration(s[1:], 0)
-		if err == nil {
-			ofst := int(d.Duration / time.Second)
-			if s[0] == '-' {
-				ofst = -ofst
-			}
-			return time.FixedZone("UTC", ofst), nil
-		}
+func parseTimeZone(s string) (*time.Location, error) {
+	loc, err := timeutil.ParseTimeZone(s)
+	if err != nil {
+		return nil, variable.ErrUnknownTimeZone.GenByArgs(s)
 	}
-
-	return nil, variable.ErrUnknownTimeZone.GenByArgs(s)
+	return loc, nil
 }
 
 func setSnapshotTS(s *variable.SessionVars, sVal string) error {
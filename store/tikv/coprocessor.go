@@ -60,6 +60,10 @@ func (c *CopClient) IsRequestTypeSupported(reqType, subType int64) bool {
 		return c.supportExpr(tipb.ExprType(subType))
 	case kv.ReqTypeAnalyze:
 		return true
+	case kv.ReqTypeChecksum:
+		// Now we only support checksum requests on mocktikv.
+		// TODO: Remove it after TiKV supports checksum requests.
+		return c.store.mock
 	}
 	return false
 }
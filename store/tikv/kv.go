@@ -415,7 +415,8 @@ func (s *tikvStore) GetOracle() oracle.Oracle {
 
 func (s *tikvStore) SupportDeleteRange() (supported bool) {
 	if s.mock {
-		return false
+		// Only a unistore runs GC worker to delete the ranges.
+		return s.enableGC
 	}
 	return true
 }
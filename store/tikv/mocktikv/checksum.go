@@ -0,0 +1,82 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mocktikv
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/kvproto/pkg/coprocessor"
+	"github.com/pingcap/kvproto/pkg/kvrpcpb"
+	"github.com/pingcap/tidb/distsql"
+	"github.com/pingcap/tidb/kv"
+)
+
+// checksumScanBatch is the number of pairs read from the MVCCStore at a time.
+const checksumScanBatch = 1024
+
+func (h *rpcHandler) handleCopChecksumRequest(req *coprocessor.Request) *coprocessor.Response {
+	resp := &coprocessor.Response{}
+	if err := h.checkRequestContext(req.GetContext()); err != nil {
+		resp.RegionError = err
+		return resp
+	}
+	checksumReq := new(distsql.ChecksumRequest)
+	if err := checksumReq.Unmarshal(req.Data); err != nil {
+		resp.OtherError = err.Error()
+		return resp
+	}
+	if checksumReq.Algorithm != distsql.ChecksumCrc64Xor {
+		resp.OtherError = "unknown checksum algorithm"
+		return resp
+	}
+	checksumResp, err := h.checksumRanges(h.extractKVRanges(req.Ranges, false), checksumReq.StartTs)
+	if err != nil {
+		if locked, ok := errors.Cause(err).(*ErrLocked); ok {
+			resp.Locked = &kvrpcpb.LockInfo{
+				Key:         locked.Key,
+				PrimaryLock: locked.Primary,
+				LockVersion: locked.StartTS,
+				LockTtl:     locked.TTL,
+			}
+		} else {
+			resp.OtherError = err.Error()
+		}
+		return resp
+	}
+	resp.Data, err = checksumResp.Marshal()
+	if err != nil {
+		resp.OtherError = err.Error()
+	}
+	return resp
+}
+
+func (h *rpcHandler) checksumRanges(ranges []kv.KeyRange, startTS uint64) (*distsql.ChecksumResponse, error) {
+	checksum := &distsql.ChecksumResponse{}
+	for _, ran := range ranges {
+		startKey := ran.StartKey
+		for {
+			pairs := h.mvccStore.Scan(startKey, ran.EndKey, checksumScanBatch, startTS, h.isolationLevel)
+			for _, pair := range pairs {
+				if pair.Err != nil {
+					return nil, errors.Trace(pair.Err)
+				}
+				checksum.Update(pair.Key, pair.Value)
+			}
+			if len(pairs) < checksumScanBatch {
+				break
+			}
+			startKey = kv.Key(pairs[len(pairs)-1].Key).Next()
+		}
+	}
+	return checksum, nil
+}
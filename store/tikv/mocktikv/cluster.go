@@ -40,6 +40,8 @@ type Cluster struct {
 	id      uint64
 	stores  map[uint64]*Store
 	regions map[uint64]*Region
+	// meta is used to persist the meta data, nil if the Cluster is in memory.
+	meta *MetaStore
 }
 
 // NewCluster creates an empty cluster. It needs to be bootstrapped before
@@ -57,7 +59,9 @@ func (c *Cluster) AllocID() uint64 {
 	c.Lock()
 	defer c.Unlock()
 
-	return c.allocID()
+	id := c.allocID()
+	c.persist()
+	return id
 }
 
 // AllocIDs creates multiple IDs.
@@ -69,6 +73,7 @@ func (c *Cluster) AllocIDs(n int) []uint64 {
 	for len(ids) < n {
 		ids = append(ids, c.allocID())
 	}
+	c.persist()
 	return ids
 }
 
@@ -105,6 +110,7 @@ func (c *Cluster) StopStore(storeID uint64) {
 	if store := c.stores[storeID]; store != nil {
 		store.meta.State = metapb.StoreState_Offline
 	}
+	c.persist()
 }
 
 // StartStore starts a store with storeID.
@@ -115,6 +121,7 @@ func (c *Cluster) StartStore(storeID uint64) {
 	if store := c.stores[storeID]; store != nil {
 		store.meta.State = metapb.StoreState_Up
 	}
+	c.persist()
 }
 
 // CancelStore makes the store with cancel state true.
@@ -173,6 +180,7 @@ func (c *Cluster) AddStore(storeID uint64, addr string) {
 	defer c.Unlock()
 
 	c.stores[storeID] = newStore(storeID, addr)
+	c.persist()
 }
 
 // RemoveStore removes a Store from the cluster.
@@ -181,6 +189,7 @@ func (c *Cluster) RemoveStore(storeID uint64) {
 	defer c.Unlock()
 
 	delete(c.stores, storeID)
+	c.persist()
 }
 
 // UpdateStoreAddr updates store address for cluster.
@@ -188,6 +197,7 @@ func (c *Cluster) UpdateStoreAddr(storeID uint64, addr string) {
 	c.Lock()
 	defer c.Unlock()
 	c.stores[storeID] = newStore(storeID, addr)
+	c.persist()
 }
 
 // GetRegion returns a Region's meta and leader ID.
@@ -238,6 +248,7 @@ func (c *Cluster) Bootstrap(regionID uint64, storeIDs, peerIDs []uint64, leaderS
 		panic("len(storeIDs) != len(peerIDs)")
 	}
 	c.regions[regionID] = newRegion(regionID, storeIDs, peerIDs, leaderStoreID)
+	c.persist()
 }
 
 // AddPeer adds a new Peer for the Region on the Store.
@@ -246,6 +257,7 @@ func (c *Cluster) AddPeer(regionID, storeID, peerID uint64) {
 	defer c.Unlock()
 
 	c.regions[regionID].addPeer(peerID, storeID)
+	c.persist()
 }
 
 // RemovePeer removes the Peer from the Region. Note that if the Peer is leader,
@@ -255,6 +267,7 @@ func (c *Cluster) RemovePeer(regionID, storeID uint64) {
 	defer c.Unlock()
 
 	c.regions[regionID].removePeer(storeID)
+	c.persist()
 }
 
 // ChangeLeader sets the Region's leader Peer. Caller should guarantee the Peer
@@ -264,6 +277,7 @@ func (c *Cluster) ChangeLeader(regionID, leaderStoreID uint64) {
 	defer c.Unlock()
 
 	c.regions[regionID].changeLeader(leaderStoreID)
+	c.persist()
 }
 
 // GiveUpLeader sets the Region's leader to 0. The Region will have no leader
@@ -284,6 +298,7 @@ func (c *Cluster) SplitRaw(regionID, newRegionID uint64, rawKey []byte, peerIDs
 
 	newRegion := c.regions[regionID].split(newRegionID, rawKey, peerIDs, leaderPeerID)
 	c.regions[newRegionID] = newRegion
+	c.persist()
 }
 
 // Merge merges 2 regions, their key ranges should be adjacent.
@@ -293,6 +308,7 @@ func (c *Cluster) Merge(regionID1, regionID2 uint64) {
 
 	c.regions[regionID1].merge(c.regions[regionID2].Meta.GetEndKey())
 	delete(c.regions, regionID2)
+	c.persist()
 }
 
 // SplitTable evenly splits the data in table into count regions.
@@ -317,6 +333,7 @@ func (c *Cluster) splitRange(mvccStore *MvccStore, start, end MvccKey, count int
 	c.evacuateOldRegionRanges(start, end)
 	regionPairs := c.getEntriesGroupByRegions(mvccStore, start, end, count)
 	c.createNewRegions(regionPairs, start, end)
+	c.persist()
 }
 
 // getPairsGroupByRegions groups the key value pairs into splitted regions.
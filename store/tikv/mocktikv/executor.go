@@ -255,6 +255,17 @@ func (e *indexScanExec) decodeIndexKV(pair Pair) ([][]byte, error) {
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	// The value is '0' for the non-unique index and the handle for the unique index,
+	// which may be followed by the trailing spaces of the values.
+	handleLen := 8
+	if len(b) > 0 {
+		handleLen = 1
+	}
+	if len(pair.Value) > handleLen {
+		if err = tablecodec.RestoreIndexTailSpaces(values, pair.Value[handleLen:]); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
 	if len(b) > 0 {
 		if e.pkStatus != pkColNotExists {
 			values = append(values, b)
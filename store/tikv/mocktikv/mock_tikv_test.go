@@ -421,6 +421,50 @@ func (s *testMockTiKVSuite) TestRC(c *C) {
 	s.mustGetRC(c, "key", 20, "v1")
 }
 
+func (s *testMockTiKVSuite) TestDeleteRange(c *C) {
+	for i := 1; i <= 5; i++ {
+		key := string(byte(i) + byte('0'))
+		value := "v" + key
+		s.mustPutOK(c, key, value, uint64(1+2*i), uint64(2+2*i))
+	}
+
+	s.mustScanOK(c, "0", 10, 20, "1", "v1", "2", "v2", "3", "v3", "4", "v4", "5", "v5")
+
+	c.Assert(s.store.DeleteRange([]byte("2"), []byte("4")), IsNil)
+	s.mustScanOK(c, "0", 10, 20, "1", "v1", "4", "v4", "5", "v5")
+
+	c.Assert(s.store.DeleteRange([]byte("5"), nil), IsNil)
+	s.mustScanOK(c, "0", 10, 20, "1", "v1", "4", "v4")
+
+	c.Assert(s.store.DeleteRange(nil, nil), IsNil)
+	s.mustScanOK(c, "0", 10, 20)
+}
+
+func (s *testMockTiKVSuite) TestGC(c *C) {
+	s.mustPutOK(c, "x", "x5", 4, 5)
+	s.mustPutOK(c, "x", "x10", 9, 10)
+	s.mustPutOK(c, "x", "x20", 19, 20)
+	s.mustPutOK(c, "y", "y5", 4, 5)
+	s.mustDeleteOK(c, "y", 9, 10)
+	s.mustRollbackOK(c, [][]byte{[]byte("z")}, 12)
+	s.mustPrewriteOK(c, putMutations("z", "z30"), "z", 30)
+
+	c.Assert(s.store.GC(nil, nil, 15), IsNil)
+	// The latest version before the safe point is kept.
+	s.mustGetOK(c, "x", 15, "x10")
+	s.mustGetOK(c, "x", 25, "x20")
+	s.mustGetNone(c, "y", 15)
+	// Older versions are collected.
+	s.mustGetNone(c, "x", 7)
+	s.mustGetNone(c, "y", 7)
+	// Locks are not touched.
+	s.mustScanLock(c, 30, []*kvrpcpb.LockInfo{lock("z", "z", 30)})
+
+	c.Assert(s.store.GC(nil, nil, 25), IsNil)
+	s.mustGetOK(c, "x", 25, "x20")
+	s.mustGetNone(c, "x", 15)
+}
+
 func (s testMarshal) TestMarshalmvccLock(c *C) {
 	l := mvccLock{
 		startTS: 47,
@@ -19,6 +19,7 @@ import (
 	"io"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/google/btree"
 	"github.com/juju/errors"
@@ -402,6 +403,8 @@ func (e *mvccEntry) dumpMvccInfo() *kvrpcpb.MvccInfo {
 type rawEntry struct {
 	key   []byte
 	value []byte
+	// expireAt is zero if the entry never expires.
+	expireAt time.Time
 }
 
 func newRawEntry(key []byte) *rawEntry {
@@ -414,6 +417,10 @@ func (e *rawEntry) Less(than btree.Item) bool {
 	return bytes.Compare(e.key, than.(*rawEntry).key) < 0
 }
 
+func (e *rawEntry) isExpired(now time.Time) bool {
+	return !e.expireAt.IsZero() && !now.Before(e.expireAt)
+}
+
 // MVCCStore is a mvcc key-value storage.
 type MVCCStore interface {
 	Get(key []byte, startTS uint64, isoLevel kvrpcpb.IsolationLevel) ([]byte, error)
@@ -426,14 +433,23 @@ type MVCCStore interface {
 	Cleanup(key []byte, startTS uint64) error
 	ScanLock(startKey, endKey []byte, maxTS uint64) ([]*kvrpcpb.LockInfo, error)
 	ResolveLock(startKey, endKey []byte, startTS, commitTS uint64) error
+	DeleteRange(startKey, endKey []byte) error
+	GC(startKey, endKey []byte, safePoint uint64) error
 }
 
 // RawKV is a key-value storage. MVCCStore can be implemented upon it with timestamp encoded into key.
 type RawKV interface {
 	RawGet(key []byte) []byte
+	RawBatchGet(keys [][]byte) [][]byte
 	RawScan(startKey, endKey []byte, limit int) []Pair
+	RawReverseScan(startKey, endKey []byte, limit int) []Pair
 	RawPut(key, value []byte)
+	RawBatchPut(keys, values [][]byte, ttl uint64)
 	RawDelete(key []byte)
+	RawBatchDelete(keys [][]byte)
+	RawDeleteRange(startKey, endKey []byte)
+	RawCompareAndSwap(key, previousValue []byte, previousNotExist bool, value []byte, ttl uint64) ([]byte, bool)
+	RawGetKeyTTL(key []byte) (uint64, bool)
 }
 
 // MVCCDebugger is for debugging.
@@ -707,33 +723,154 @@ func (s *MvccStore) ResolveLock(startKey, endKey []byte, startTS, commitTS uint6
 	return nil
 }
 
+// DeleteRange physically removes all versions and locks of the keys in [startKey, endKey).
+func (s *MvccStore) DeleteRange(startKey, endKey []byte) error {
+	s.Lock()
+	defer s.Unlock()
+
+	startKey = NewMvccKey(startKey)
+	endKey = NewMvccKey(endKey)
+
+	var ents []*mvccEntry
+	iterator := func(item btree.Item) bool {
+		ent := item.(*mvccEntry)
+		if !regionContains(startKey, endKey, ent.key) {
+			return false
+		}
+		ents = append(ents, ent)
+		return true
+	}
+	s.tree.AscendGreaterOrEqual(newEntry(startKey), iterator)
+	for _, ent := range ents {
+		s.tree.Delete(ent)
+	}
+	return nil
+}
+
+// GC removes the versions of the keys in [startKey, endKey) that are invisible
+// to any transaction whose startTS is not less than safePoint.
+func (s *MvccStore) GC(startKey, endKey []byte, safePoint uint64) error {
+	s.Lock()
+	defer s.Unlock()
+
+	startKey = NewMvccKey(startKey)
+	endKey = NewMvccKey(endKey)
+
+	var ents []*mvccEntry
+	iterator := func(item btree.Item) bool {
+		ent := item.(*mvccEntry)
+		if !regionContains(startKey, endKey, ent.key) {
+			return false
+		}
+		if len(obsoleteValues(ent.values, safePoint)) > 0 {
+			ents = append(ents, ent)
+		}
+		return true
+	}
+	s.tree.AscendGreaterOrEqual(newEntry(startKey), iterator)
+	for _, ent := range ents {
+		ent = ent.Clone()
+		obsolete := obsoleteValues(ent.values, safePoint)
+		ent.values = ent.values[:len(ent.values)-len(obsolete)]
+		if len(ent.values) == 0 && ent.lock == nil {
+			s.tree.Delete(ent)
+			continue
+		}
+		s.submit(ent)
+	}
+	return nil
+}
+
+// obsoleteValues returns the versions that can be collected at safePoint. The
+// values are sorted by commitTS in descending order, so the obsolete ones are
+// always a suffix of them: once the latest Put or Delete committed before
+// safePoint is found, everything older than it can never be read again.
+// Besides, the latest version is also obsolete if it is a Delete.
+func obsoleteValues(values []mvccValue, safePoint uint64) []mvccValue {
+	for i, v := range values {
+		if v.commitTS > safePoint || v.valueType == typeRollback {
+			continue
+		}
+		if v.valueType == typeDelete {
+			return values[i:]
+		}
+		return values[i+1:]
+	}
+	return nil
+}
+
+// getRaw returns the rawEntry of the key, it returns nil if the key does not
+// exist or is expired. It should be called with the store locked.
+func (s *MvccStore) getRaw(key []byte) *rawEntry {
+	item := s.rawkv.Get(newRawEntry(key))
+	if item == nil {
+		return nil
+	}
+	entry := item.(*rawEntry)
+	if entry.isExpired(time.Now()) {
+		return nil
+	}
+	return entry
+}
+
+// putRaw stores a key-value pair which expires after ttl seconds, a ttl of 0
+// means it never expires. It should be called with the store locked.
+func (s *MvccStore) putRaw(key, value []byte, ttl uint64) {
+	if value == nil {
+		value = []byte{}
+	}
+	var expireAt time.Time
+	if ttl != 0 {
+		expireAt = time.Now().Add(time.Duration(ttl) * time.Second)
+	}
+	s.rawkv.ReplaceOrInsert(&rawEntry{
+		key:      key,
+		value:    value,
+		expireAt: expireAt,
+	})
+}
+
 // RawGet queries value with the key.
 func (s *MvccStore) RawGet(key []byte) []byte {
 	s.RLock()
 	defer s.RUnlock()
 
-	entry := s.rawkv.Get(newRawEntry(key))
+	entry := s.getRaw(key)
 	if entry == nil {
 		return nil
 	}
-	return entry.(*rawEntry).value
+	return entry.value
+}
+
+// RawBatchGet queries values with the keys, the value of a key that does not
+// exist is nil.
+func (s *MvccStore) RawBatchGet(keys [][]byte) [][]byte {
+	s.RLock()
+	defer s.RUnlock()
+
+	values := make([][]byte, len(keys))
+	for i, key := range keys {
+		if entry := s.getRaw(key); entry != nil {
+			values[i] = entry.value
+		}
+	}
+	return values
 }
 
 // RawPut stores a key-value pair.
 func (s *MvccStore) RawPut(key, value []byte) {
 	s.Lock()
 	defer s.Unlock()
-	if value == nil {
-		value = []byte{}
-	}
-	entry := s.rawkv.Get(newRawEntry(key))
-	if entry != nil {
-		entry.(*rawEntry).value = value
-	} else {
-		s.rawkv.ReplaceOrInsert(&rawEntry{
-			key:   key,
-			value: value,
-		})
+	s.putRaw(key, value, 0)
+}
+
+// RawBatchPut stores key-value pairs, they expire after ttl seconds if ttl is
+// not 0.
+func (s *MvccStore) RawBatchPut(keys, values [][]byte, ttl uint64) {
+	s.Lock()
+	defer s.Unlock()
+	for i, key := range keys {
+		s.putRaw(key, values[i], ttl)
 	}
 }
 
@@ -744,30 +881,137 @@ func (s *MvccStore) RawDelete(key []byte) {
 	s.rawkv.Delete(newRawEntry(key))
 }
 
+// RawBatchDelete deletes key-value pairs.
+func (s *MvccStore) RawBatchDelete(keys [][]byte) {
+	s.Lock()
+	defer s.Unlock()
+	for _, key := range keys {
+		s.rawkv.Delete(newRawEntry(key))
+	}
+}
+
+// RawDeleteRange deletes the key-value pairs in [startKey, endKey).
+func (s *MvccStore) RawDeleteRange(startKey, endKey []byte) {
+	s.Lock()
+	defer s.Unlock()
+
+	var toDelete []btree.Item
+	iterator := func(item btree.Item) bool {
+		if !regionContains(startKey, endKey, item.(*rawEntry).key) {
+			return false
+		}
+		toDelete = append(toDelete, item)
+		return true
+	}
+	s.rawkv.AscendGreaterOrEqual(newRawEntry(startKey), iterator)
+	for _, item := range toDelete {
+		s.rawkv.Delete(item)
+	}
+}
+
 // RawScan reads up to a limited number of rawkv Pairs.
 func (s *MvccStore) RawScan(startKey, endKey []byte, limit int) []Pair {
 	s.RLock()
 	defer s.RUnlock()
 
 	var pairs []Pair
+	now := time.Now()
 	iterator := func(item btree.Item) bool {
 		if len(pairs) >= limit {
 			return false
 		}
-		k := item.(*rawEntry).key
-		if !regionContains(startKey, endKey, k) {
+		entry := item.(*rawEntry)
+		if !regionContains(startKey, endKey, entry.key) {
 			return false
 		}
-		pairs = append(pairs, Pair{
-			Key:   k,
-			Value: item.(*rawEntry).value,
-		})
+		if !entry.isExpired(now) {
+			pairs = append(pairs, Pair{
+				Key:   entry.key,
+				Value: entry.value,
+			})
+		}
 		return true
 	}
 	s.rawkv.AscendGreaterOrEqual(newRawEntry(startKey), iterator)
 	return pairs
 }
 
+// RawReverseScan reads up to a limited number of rawkv Pairs in [startKey,
+// endKey) in descending order. An empty endKey means the end of the key space.
+func (s *MvccStore) RawReverseScan(startKey, endKey []byte, limit int) []Pair {
+	s.RLock()
+	defer s.RUnlock()
+
+	var pairs []Pair
+	now := time.Now()
+	iterator := func(item btree.Item) bool {
+		if len(pairs) >= limit {
+			return false
+		}
+		entry := item.(*rawEntry)
+		if bytes.Compare(entry.key, startKey) < 0 {
+			return false
+		}
+		if len(endKey) > 0 && bytes.Compare(entry.key, endKey) >= 0 {
+			return true
+		}
+		if !entry.isExpired(now) {
+			pairs = append(pairs, Pair{
+				Key:   entry.key,
+				Value: entry.value,
+			})
+		}
+		return true
+	}
+	if len(endKey) == 0 {
+		s.rawkv.Descend(iterator)
+	} else {
+		s.rawkv.DescendLessOrEqual(newRawEntry(endKey), iterator)
+	}
+	return pairs
+}
+
+// RawCompareAndSwap sets the value of the key to value if its current value is
+// previousValue, or if it does not exist when previousNotExist is true. It
+// returns the value before the operation and whether the value is swapped.
+func (s *MvccStore) RawCompareAndSwap(key, previousValue []byte, previousNotExist bool, value []byte, ttl uint64) ([]byte, bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	entry := s.getRaw(key)
+	if entry == nil {
+		if !previousNotExist {
+			return nil, false
+		}
+	} else if previousNotExist || !bytes.Equal(entry.value, previousValue) {
+		return entry.value, false
+	}
+	var prev []byte
+	if entry != nil {
+		prev = entry.value
+	}
+	s.putRaw(key, value, ttl)
+	return prev, true
+}
+
+// RawGetKeyTTL returns the remaining time to live of the key in seconds, 0
+// means the key never expires. The second return value is false if the key
+// does not exist.
+func (s *MvccStore) RawGetKeyTTL(key []byte) (uint64, bool) {
+	s.RLock()
+	defer s.RUnlock()
+
+	entry := s.getRaw(key)
+	if entry == nil {
+		return 0, false
+	}
+	if entry.expireAt.IsZero() {
+		return 0, true
+	}
+	ttl := entry.expireAt.Sub(time.Now())
+	return uint64((ttl + time.Second - 1) / time.Second), true
+}
+
 // MvccGetByStartTS gets mvcc info for the primary key with startTS
 func (s *MvccStore) MvccGetByStartTS(startKey, endKey []byte, starTS uint64) (*kvrpcpb.MvccInfo, []byte) {
 	s.RLock()
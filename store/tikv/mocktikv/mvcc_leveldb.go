@@ -823,6 +823,69 @@ func (mvcc *MVCCLevelDB) ResolveLock(startKey, endKey []byte, startTS, commitTS
 	return mvcc.db.Write(batch, nil)
 }
 
+// DeleteRange implements the MVCCStore interface.
+func (mvcc *MVCCLevelDB) DeleteRange(startKey, endKey []byte) error {
+	mvcc.mu.Lock()
+	defer mvcc.mu.Unlock()
+
+	var start, end []byte
+	if len(startKey) > 0 {
+		start = mvccEncode(startKey, lockVer)
+	}
+	if len(endKey) > 0 {
+		end = mvccEncode(endKey, lockVer)
+	}
+	iter := newIterator(mvcc.db, &util.Range{
+		Start: start,
+		Limit: end,
+	})
+	defer iter.Release()
+
+	batch := &leveldb.Batch{}
+	for iter.Valid() {
+		batch.Delete(append([]byte(nil), iter.Key()...))
+		iter.Next()
+	}
+	if err := iter.Error(); err != nil {
+		return errors.Trace(err)
+	}
+	return mvcc.db.Write(batch, nil)
+}
+
+// GC implements the MVCCStore interface.
+func (mvcc *MVCCLevelDB) GC(startKey, endKey []byte, safePoint uint64) error {
+	mvcc.mu.Lock()
+	defer mvcc.mu.Unlock()
+
+	iter, currKey, err := newScanIterator(mvcc.db, startKey, endKey)
+	defer iter.Release()
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	batch := &leveldb.Batch{}
+	for iter.Valid() {
+		dec := mvccEntryDecoder{expectKey: currKey}
+		ok, err := dec.Decode(iter)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if ok {
+			for _, v := range obsoleteValues(dec.values, safePoint) {
+				batch.Delete(mvccEncode(currKey, v.commitTS))
+			}
+		}
+
+		skip := skipDecoder{currKey: currKey}
+		_, err = skip.Decode(iter)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		currKey = skip.currKey
+	}
+	return mvcc.db.Write(batch, nil)
+}
+
 // Close calls leveldb's Close to free resources.
 func (mvcc *MVCCLevelDB) Close() error {
 	return mvcc.db.Close()
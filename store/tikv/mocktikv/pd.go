@@ -14,9 +14,11 @@
 package mocktikv
 
 import (
+	"strconv"
 	"sync"
 	"time"
 
+	"github.com/juju/errors"
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/pd/pd-client"
 	"golang.org/x/net/context"
@@ -29,8 +31,17 @@ var tsMu = struct {
 	logicalTS  int64
 }{}
 
+// tsSaveInterval is the window of physical timestamps that a persistent
+// pdClient reserves every time it saves the upper bound, in milliseconds.
+const tsSaveInterval = 3000
+
 type pdClient struct {
 	cluster *Cluster
+	// meta is used to persist the upper bound of allocated timestamps, nil if
+	// the timestamps are not required to grow across restarts.
+	meta *MetaStore
+	// tsLimit is the saved upper bound, protected by tsMu.
+	tsLimit int64
 }
 
 // NewPDClient creates a mock pd.Client that uses local timestamp and meta data
@@ -41,6 +52,35 @@ func NewPDClient(cluster *Cluster) pd.Client {
 	}
 }
 
+// NewPersistentPDClient creates a mock pd.Client like NewPDClient, but it saves
+// the upper bound of the allocated timestamps in meta, so that the timestamps
+// keep increasing after a restart, even if the local clock goes backward.
+func NewPersistentPDClient(cluster *Cluster, meta *MetaStore) (pd.Client, error) {
+	data, err := meta.Get(tsoMetaKey)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var limit int64
+	if data != "" {
+		limit, err = strconv.ParseInt(data, 10, 64)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+
+	tsMu.Lock()
+	defer tsMu.Unlock()
+	if tsMu.physicalTS < limit {
+		tsMu.physicalTS = limit
+		tsMu.logicalTS = 0
+	}
+	return &pdClient{
+		cluster: cluster,
+		meta:    meta,
+		tsLimit: limit,
+	}, nil
+}
+
 func (c *pdClient) GetClusterID(context.Context) uint64 {
 	return 1
 }
@@ -56,6 +96,13 @@ func (c *pdClient) GetTS(context.Context) (int64, int64, error) {
 		tsMu.physicalTS = ts
 		tsMu.logicalTS = 0
 	}
+	if c.meta != nil && tsMu.physicalTS >= c.tsLimit {
+		limit := tsMu.physicalTS + tsSaveInterval
+		if err := c.meta.Put(tsoMetaKey, strconv.FormatInt(limit, 10)); err != nil {
+			return 0, 0, errors.Trace(err)
+		}
+		c.tsLimit = limit
+	}
 	return tsMu.physicalTS, tsMu.logicalTS, nil
 }
 
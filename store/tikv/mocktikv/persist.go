@@ -0,0 +1,175 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mocktikv
+
+import (
+	"bytes"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/juju/errors"
+	"github.com/pingcap/goleveldb/leveldb"
+	"github.com/pingcap/goleveldb/leveldb/opt"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	log "github.com/sirupsen/logrus"
+)
+
+// Keys of the meta data saved in MetaStore.
+const (
+	clusterMetaKey = "/mocktikv/cluster"
+	tsoMetaKey     = "/mocktikv/tso"
+)
+
+// MetaStore persists the meta data of a mock cluster, i.e. the Stores, the
+// Regions, the ID allocator and the timestamp oracle, so that a mocktikv with
+// a data directory survives restarts. It also implements tikv.SafePointKV.
+// Every write is synced to disk before it returns.
+type MetaStore struct {
+	db *leveldb.DB
+}
+
+// NewMetaStore opens or creates a MetaStore in the directory path.
+func NewMetaStore(path string) (*MetaStore, error) {
+	db, err := leveldb.OpenFile(path, nil)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &MetaStore{db: db}, nil
+}
+
+// Put saves a key-value pair.
+func (s *MetaStore) Put(k string, v string) error {
+	err := s.db.Put([]byte(k), []byte(v), &opt.WriteOptions{Sync: true})
+	return errors.Trace(err)
+}
+
+// Get returns the value of the key, it returns an empty string if the key
+// does not exist.
+func (s *MetaStore) Get(k string) (string, error) {
+	v, err := s.db.Get([]byte(k), nil)
+	if err == leveldb.ErrNotFound {
+		return "", nil
+	}
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	return string(v), nil
+}
+
+// Close closes the MetaStore.
+func (s *MetaStore) Close() error {
+	return s.db.Close()
+}
+
+// NewPersistentCluster creates a Cluster whose meta data is saved in meta. If
+// the meta data of a Cluster was saved before, it is loaded. Otherwise the
+// returned Cluster is empty and needs to be bootstrapped.
+func NewPersistentCluster(meta *MetaStore) (*Cluster, error) {
+	c := NewCluster()
+	data, err := meta.Get(clusterMetaKey)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if data != "" {
+		if err = c.unmarshal([]byte(data)); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	c.meta = meta
+	return c, nil
+}
+
+// persist saves the meta data of the Cluster if it's a persistent one. It
+// should be called with the Cluster locked, after the meta data is changed.
+func (c *Cluster) persist() {
+	if c.meta == nil {
+		return
+	}
+	data, err := c.marshal()
+	if err == nil {
+		err = c.meta.Put(clusterMetaKey, string(data))
+	}
+	if err != nil {
+		log.Errorf("[mocktikv] persist cluster meta failed: %v", errors.ErrorStack(err))
+	}
+}
+
+func (c *Cluster) marshal() ([]byte, error) {
+	var (
+		mh  marshalHelper
+		buf bytes.Buffer
+	)
+	mh.WriteNumber(&buf, c.id)
+	mh.WriteNumber(&buf, uint64(len(c.stores)))
+	for _, s := range c.stores {
+		data, err := proto.Marshal(s.meta)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		mh.WriteSlice(&buf, data)
+	}
+	mh.WriteNumber(&buf, uint64(len(c.regions)))
+	for _, r := range c.regions {
+		data, err := proto.Marshal(r.Meta)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		mh.WriteSlice(&buf, data)
+		mh.WriteNumber(&buf, r.leader)
+	}
+	return buf.Bytes(), errors.Trace(mh.err)
+}
+
+func (c *Cluster) unmarshal(data []byte) error {
+	var (
+		mh          marshalHelper
+		storeCount  uint64
+		regionCount uint64
+		stores      = make(map[uint64]*Store)
+		regions     = make(map[uint64]*Region)
+		buf         = bytes.NewBuffer(data)
+		id          uint64
+		item        []byte
+	)
+	mh.ReadNumber(buf, &id)
+	mh.ReadNumber(buf, &storeCount)
+	for i := uint64(0); i < storeCount && mh.err == nil; i++ {
+		mh.ReadSlice(buf, &item)
+		if mh.err != nil {
+			break
+		}
+		meta := &metapb.Store{}
+		if err := proto.Unmarshal(item, meta); err != nil {
+			return errors.Trace(err)
+		}
+		stores[meta.GetId()] = &Store{meta: meta}
+	}
+	mh.ReadNumber(buf, &regionCount)
+	for i := uint64(0); i < regionCount && mh.err == nil; i++ {
+		mh.ReadSlice(buf, &item)
+		r := &Region{Meta: &metapb.Region{}}
+		mh.ReadNumber(buf, &r.leader)
+		if mh.err != nil {
+			break
+		}
+		if err := proto.Unmarshal(item, r.Meta); err != nil {
+			return errors.Trace(err)
+		}
+		regions[r.Meta.GetId()] = r
+	}
+	if mh.err != nil {
+		return errors.Trace(mh.err)
+	}
+	c.id, c.stores, c.regions = id, stores, regions
+	return nil
+}
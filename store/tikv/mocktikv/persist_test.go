@@ -0,0 +1,92 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mocktikv
+
+import (
+	"io/ioutil"
+	"os"
+	"strconv"
+	"time"
+
+	. "github.com/pingcap/check"
+	goctx "golang.org/x/net/context"
+)
+
+var _ = Suite(&testPersistSuite{})
+
+type testPersistSuite struct {
+	dir string
+}
+
+func (s *testPersistSuite) SetUpTest(c *C) {
+	var err error
+	s.dir, err = ioutil.TempDir("", "mocktikv-meta")
+	c.Assert(err, IsNil)
+}
+
+func (s *testPersistSuite) TearDownTest(c *C) {
+	os.RemoveAll(s.dir)
+}
+
+func (s *testPersistSuite) TestPersistentCluster(c *C) {
+	meta, err := NewMetaStore(s.dir)
+	c.Assert(err, IsNil)
+	cluster, err := NewPersistentCluster(meta)
+	c.Assert(err, IsNil)
+	c.Assert(cluster.GetAllRegions(), HasLen, 0)
+	storeID, regionIDs, peerIDs := BootstrapWithMultiRegions(cluster, []byte("m"))
+	cluster.ChangeLeader(regionIDs[1], peerIDs[1])
+	lastID := cluster.AllocID()
+	c.Assert(meta.Close(), IsNil)
+
+	meta, err = NewMetaStore(s.dir)
+	c.Assert(err, IsNil)
+	defer meta.Close()
+	loaded, err := NewPersistentCluster(meta)
+	c.Assert(err, IsNil)
+	c.Assert(loaded.GetStore(storeID), DeepEquals, cluster.GetStore(storeID))
+	c.Assert(loaded.GetAllRegions(), HasLen, 2)
+	for _, id := range regionIDs {
+		region, leader := loaded.GetRegion(id)
+		expectRegion, expectLeader := cluster.GetRegion(id)
+		c.Assert(region, DeepEquals, expectRegion)
+		c.Assert(leader, Equals, expectLeader)
+	}
+	region, _ := loaded.GetRegionByKey(NewMvccKey([]byte("z")))
+	c.Assert(region.GetId(), Equals, regionIDs[1])
+	// IDs are never allocated twice.
+	c.Assert(loaded.AllocID(), Equals, lastID+1)
+}
+
+func (s *testPersistSuite) TestPersistentTSO(c *C) {
+	meta, err := NewMetaStore(s.dir)
+	c.Assert(err, IsNil)
+	defer meta.Close()
+
+	// Pretend the timestamps up to a while later were allocated before restart.
+	limit := time.Now().Add(500*time.Millisecond).UnixNano() / int64(time.Millisecond)
+	c.Assert(meta.Put(tsoMetaKey, strconv.FormatInt(limit, 10)), IsNil)
+
+	cluster, err := NewPersistentCluster(meta)
+	c.Assert(err, IsNil)
+	pdCli, err := NewPersistentPDClient(cluster, meta)
+	c.Assert(err, IsNil)
+	physical, _, err := pdCli.GetTS(goctx.Background())
+	c.Assert(err, IsNil)
+	c.Assert(physical, GreaterEqual, limit)
+
+	saved, err := meta.Get(tsoMetaKey)
+	c.Assert(err, IsNil)
+	c.Assert(saved, Equals, strconv.FormatInt(physical+tsSaveInterval, 10))
+}
@@ -345,10 +345,28 @@ func (h *rpcHandler) handleKvResolveLock(req *kvrpcpb.ResolveLockRequest) *kvrpc
 	return &kvrpcpb.ResolveLockResponse{}
 }
 
+func (h *rpcHandler) handleKvGC(req *kvrpcpb.GCRequest) *kvrpcpb.GCResponse {
+	startKey := MvccKey(h.startKey).Raw()
+	endKey := MvccKey(h.endKey).Raw()
+	err := h.mvccStore.GC(startKey, endKey, req.GetSafePoint())
+	if err != nil {
+		return &kvrpcpb.GCResponse{
+			Error: convertToKeyError(err),
+		}
+	}
+	return &kvrpcpb.GCResponse{}
+}
+
 func (h *rpcHandler) handleKvDeleteRange(req *kvrpcpb.DeleteRangeRequest) *kvrpcpb.DeleteRangeResponse {
-	return &kvrpcpb.DeleteRangeResponse{
-		Error: "not implemented",
+	if !h.checkKeyInRegion(req.StartKey) {
+		panic("KvDeleteRange: key not in region")
 	}
+	var resp kvrpcpb.DeleteRangeResponse
+	err := h.mvccStore.DeleteRange(req.StartKey, req.EndKey)
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	return &resp
 }
 
 func (h *rpcHandler) handleKvRawGet(req *kvrpcpb.RawGetRequest) *kvrpcpb.RawGetResponse {
@@ -401,6 +419,117 @@ func (h *rpcHandler) handleKvRawScan(req *kvrpcpb.RawScanRequest) *kvrpcpb.RawSc
 	}
 }
 
+func (h *rpcHandler) handleKvRawBatchGet(req *tikvrpc.RawBatchGetRequest) *tikvrpc.RawBatchGetResponse {
+	kv, ok := h.mvccStore.(RawKV)
+	if !ok {
+		return &tikvrpc.RawBatchGetResponse{
+			RegionError: &errorpb.Error{
+				Message: "not implemented",
+			},
+		}
+	}
+	values := kv.RawBatchGet(req.Keys)
+	var pairs []*kvrpcpb.KvPair
+	for i, value := range values {
+		if value != nil {
+			pairs = append(pairs, &kvrpcpb.KvPair{
+				Key:   req.Keys[i],
+				Value: value,
+			})
+		}
+	}
+	return &tikvrpc.RawBatchGetResponse{
+		Pairs: pairs,
+	}
+}
+
+func (h *rpcHandler) handleKvRawBatchPut(req *tikvrpc.RawBatchPutRequest) *tikvrpc.RawBatchPutResponse {
+	kv, ok := h.mvccStore.(RawKV)
+	if !ok {
+		return &tikvrpc.RawBatchPutResponse{
+			Error: "not implemented",
+		}
+	}
+	keys := make([][]byte, 0, len(req.Pairs))
+	values := make([][]byte, 0, len(req.Pairs))
+	for _, pair := range req.Pairs {
+		keys = append(keys, pair.Key)
+		values = append(values, pair.Value)
+	}
+	kv.RawBatchPut(keys, values, req.Ttl)
+	return &tikvrpc.RawBatchPutResponse{}
+}
+
+func (h *rpcHandler) handleKvRawBatchDelete(req *tikvrpc.RawBatchDeleteRequest) *tikvrpc.RawBatchDeleteResponse {
+	kv, ok := h.mvccStore.(RawKV)
+	if !ok {
+		return &tikvrpc.RawBatchDeleteResponse{
+			Error: "not implemented",
+		}
+	}
+	kv.RawBatchDelete(req.Keys)
+	return &tikvrpc.RawBatchDeleteResponse{}
+}
+
+func (h *rpcHandler) handleKvRawDeleteRange(req *tikvrpc.RawDeleteRangeRequest) *tikvrpc.RawDeleteRangeResponse {
+	kv, ok := h.mvccStore.(RawKV)
+	if !ok {
+		return &tikvrpc.RawDeleteRangeResponse{
+			Error: "not implemented",
+		}
+	}
+	kv.RawDeleteRange(req.StartKey, req.EndKey)
+	return &tikvrpc.RawDeleteRangeResponse{}
+}
+
+func (h *rpcHandler) handleKvRawReverseScan(req *tikvrpc.RawReverseScanRequest) *tikvrpc.RawReverseScanResponse {
+	kv, ok := h.mvccStore.(RawKV)
+	if !ok {
+		return &tikvrpc.RawReverseScanResponse{
+			RegionError: &errorpb.Error{
+				Message: "not implemented",
+			},
+		}
+	}
+	endKey := req.StartKey
+	if len(endKey) == 0 || (len(h.endKey) > 0 && bytes.Compare(h.endKey, endKey) < 0) {
+		endKey = h.endKey
+	}
+	pairs := kv.RawReverseScan(h.startKey, endKey, int(req.Limit))
+	return &tikvrpc.RawReverseScanResponse{
+		Kvs: convertToPbPairs(pairs),
+	}
+}
+
+func (h *rpcHandler) handleKvRawCompareAndSwap(req *tikvrpc.RawCompareAndSwapRequest) *tikvrpc.RawCompareAndSwapResponse {
+	kv, ok := h.mvccStore.(RawKV)
+	if !ok {
+		return &tikvrpc.RawCompareAndSwapResponse{
+			Error: "not implemented",
+		}
+	}
+	prev, succeed := kv.RawCompareAndSwap(req.Key, req.PreviousValue, req.PreviousNotExist, req.Value, req.Ttl)
+	return &tikvrpc.RawCompareAndSwapResponse{
+		PreviousValue:    prev,
+		PreviousNotExist: prev == nil,
+		Succeed:          succeed,
+	}
+}
+
+func (h *rpcHandler) handleKvRawGetKeyTTL(req *tikvrpc.RawGetKeyTTLRequest) *tikvrpc.RawGetKeyTTLResponse {
+	kv, ok := h.mvccStore.(RawKV)
+	if !ok {
+		return &tikvrpc.RawGetKeyTTLResponse{
+			Error: "not implemented",
+		}
+	}
+	ttl, found := kv.RawGetKeyTTL(req.Key)
+	return &tikvrpc.RawGetKeyTTLResponse{
+		Ttl:      ttl,
+		NotFound: !found,
+	}
+}
+
 func (h *rpcHandler) handleSplitRegion(req *kvrpcpb.SplitRegionRequest) *kvrpcpb.SplitRegionResponse {
 	key := NewMvccKey(req.GetSplitKey())
 	region, _ := h.cluster.GetRegionByKey(key)
@@ -563,14 +692,14 @@ func (c *RPCClient) SendReq(ctx goctx.Context, addr string, req *tikvrpc.Request
 			resp.GC = &kvrpcpb.GCResponse{RegionError: err}
 			return resp, nil
 		}
-		resp.GC = &kvrpcpb.GCResponse{}
+		resp.GC = handler.handleKvGC(r)
 	case tikvrpc.CmdDeleteRange:
 		r := req.DeleteRange
 		if err := handler.checkRequest(reqCtx, r.Size()); err != nil {
 			resp.DeleteRange = &kvrpcpb.DeleteRangeResponse{RegionError: err}
 			return resp, nil
 		}
-		resp.DeleteRange = &kvrpcpb.DeleteRangeResponse{}
+		resp.DeleteRange = handler.handleKvDeleteRange(r)
 	case tikvrpc.CmdRawGet:
 		r := req.RawGet
 		if err := handler.checkRequest(reqCtx, r.Size()); err != nil {
@@ -599,6 +728,55 @@ func (c *RPCClient) SendReq(ctx goctx.Context, addr string, req *tikvrpc.Request
 			return resp, nil
 		}
 		resp.RawScan = handler.handleKvRawScan(r)
+	case tikvrpc.CmdRawBatchGet:
+		r := req.RawBatchGet
+		if err := handler.checkRequest(reqCtx, r.Size()); err != nil {
+			resp.RawBatchGet = &tikvrpc.RawBatchGetResponse{RegionError: err}
+			return resp, nil
+		}
+		resp.RawBatchGet = handler.handleKvRawBatchGet(r)
+	case tikvrpc.CmdRawBatchPut:
+		r := req.RawBatchPut
+		if err := handler.checkRequest(reqCtx, r.Size()); err != nil {
+			resp.RawBatchPut = &tikvrpc.RawBatchPutResponse{RegionError: err}
+			return resp, nil
+		}
+		resp.RawBatchPut = handler.handleKvRawBatchPut(r)
+	case tikvrpc.CmdRawBatchDelete:
+		r := req.RawBatchDelete
+		if err := handler.checkRequest(reqCtx, r.Size()); err != nil {
+			resp.RawBatchDelete = &tikvrpc.RawBatchDeleteResponse{RegionError: err}
+			return resp, nil
+		}
+		resp.RawBatchDelete = handler.handleKvRawBatchDelete(r)
+	case tikvrpc.CmdRawDeleteRange:
+		r := req.RawDeleteRange
+		if err := handler.checkRequest(reqCtx, r.Size()); err != nil {
+			resp.RawDeleteRange = &tikvrpc.RawDeleteRangeResponse{RegionError: err}
+			return resp, nil
+		}
+		resp.RawDeleteRange = handler.handleKvRawDeleteRange(r)
+	case tikvrpc.CmdRawReverseScan:
+		r := req.RawReverseScan
+		if err := handler.checkRequest(reqCtx, r.Size()); err != nil {
+			resp.RawReverseScan = &tikvrpc.RawReverseScanResponse{RegionError: err}
+			return resp, nil
+		}
+		resp.RawReverseScan = handler.handleKvRawReverseScan(r)
+	case tikvrpc.CmdRawCompareAndSwap:
+		r := req.RawCompareAndSwap
+		if err := handler.checkRequest(reqCtx, r.Size()); err != nil {
+			resp.RawCompareAndSwap = &tikvrpc.RawCompareAndSwapResponse{RegionError: err}
+			return resp, nil
+		}
+		resp.RawCompareAndSwap = handler.handleKvRawCompareAndSwap(r)
+	case tikvrpc.CmdRawGetKeyTTL:
+		r := req.RawGetKeyTTL
+		if err := handler.checkRequest(reqCtx, r.Size()); err != nil {
+			resp.RawGetKeyTTL = &tikvrpc.RawGetKeyTTLResponse{RegionError: err}
+			return resp, nil
+		}
+		resp.RawGetKeyTTL = handler.handleKvRawGetKeyTTL(r)
 	case tikvrpc.CmdCop:
 		r := req.Cop
 		if err := handler.checkRequestContext(reqCtx); err != nil {
@@ -608,9 +786,12 @@ func (c *RPCClient) SendReq(ctx goctx.Context, addr string, req *tikvrpc.Request
 		handler.rawStartKey = MvccKey(handler.startKey).Raw()
 		handler.rawEndKey = MvccKey(handler.endKey).Raw()
 		var res *coprocessor.Response
-		if r.GetTp() == kv.ReqTypeDAG {
+		switch r.GetTp() {
+		case kv.ReqTypeDAG:
 			res = handler.handleCopDAGRequest(r)
-		} else {
+		case kv.ReqTypeChecksum:
+			res = handler.handleCopChecksumRequest(r)
+		default:
 			res = handler.handleCopAnalyzeRequest(r)
 		}
 		resp.Cop = res
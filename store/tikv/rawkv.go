@@ -14,6 +14,7 @@
 package tikv
 
 import (
+	"bytes"
 	"time"
 
 	"github.com/juju/errors"
@@ -29,10 +30,18 @@ var (
 	MaxRawKVScanLimit = 10240
 	// ErrMaxScanLimitExceeded is returned when the limit for rawkv Scan is to large.
 	ErrMaxScanLimitExceeded = errors.New("limit should be less than MaxRawKVScanLimit")
+	// ErrRawKVUnsupported is returned by the rawkv operations which TiKV doesn't support yet.
+	ErrRawKVUnsupported = errors.New("the rawkv operation is not supported by TiKV yet")
 )
 
+// rawBatchSize is the maximum size of keys and values sent in one request by
+// the batch operations.
+const rawBatchSize = 16 * 1024
+
 // RawKVClient is a client of TiKV server which is used as a key-value storage,
-// only GET/PUT/DELETE commands are supported.
+// only GET/PUT/DELETE/SCAN commands, their batch versions, DELETE RANGE,
+// COMPARE AND SWAP and TTL are supported. TiKV only serves GET/PUT/DELETE/SCAN
+// now, the others return ErrRawKVUnsupported unless the client runs on mocktikv.
 type RawKVClient struct {
 	clusterID   uint64
 	regionCache *RegionCache
@@ -80,7 +89,7 @@ func (c *RawKVClient) Get(key []byte) ([]byte, error) {
 			Key: key,
 		},
 	}
-	resp, _, err := c.sendReq(key, req)
+	resp, _, err := c.sendReq(key, req, false)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -115,7 +124,7 @@ func (c *RawKVClient) Put(key, value []byte) error {
 			Value: value,
 		},
 	}
-	resp, _, err := c.sendReq(key, req)
+	resp, _, err := c.sendReq(key, req, false)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -140,7 +149,7 @@ func (c *RawKVClient) Delete(key []byte) error {
 			Key: key,
 		},
 	}
-	resp, _, err := c.sendReq(key, req)
+	resp, _, err := c.sendReq(key, req, false)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -154,6 +163,193 @@ func (c *RawKVClient) Delete(key []byte) error {
 	return nil
 }
 
+// BatchGet queries values with the keys. The returned values are in the same
+// order as the keys, the value of a key that does not exist is nil.
+func (c *RawKVClient) BatchGet(keys [][]byte) ([][]byte, error) {
+	if err := c.checkExtendedRequest(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	start := time.Now()
+	defer func() { rawkvCmdHistogram.WithLabelValues("batch_get").Observe(time.Since(start).Seconds()) }()
+
+	bo := NewBackoffer(rawkvMaxBackoff, goctx.Background())
+	resps, err := c.sendBatchReq(bo, keys, keySize, func(keys [][]byte) *tikvrpc.Request {
+		return &tikvrpc.Request{
+			Type: tikvrpc.CmdRawBatchGet,
+			RawBatchGet: &tikvrpc.RawBatchGetRequest{
+				Keys: keys,
+			},
+		}
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	found := make(map[string][]byte, len(keys))
+	for _, resp := range resps {
+		cmdResp := resp.RawBatchGet
+		if cmdResp == nil {
+			return nil, errors.Trace(ErrBodyMissing)
+		}
+		for _, pair := range cmdResp.Pairs {
+			found[string(pair.Key)] = pair.Value
+		}
+	}
+	values := make([][]byte, len(keys))
+	for i, key := range keys {
+		if value := found[string(key)]; len(value) > 0 {
+			values[i] = value
+		}
+	}
+	return values, nil
+}
+
+// PutWithTTL stores a key-value pair to TiKV, which expires after ttl seconds.
+func (c *RawKVClient) PutWithTTL(key, value []byte, ttl uint64) error {
+	if err := c.checkExtendedRequest(); err != nil {
+		return errors.Trace(err)
+	}
+	start := time.Now()
+	defer func() { rawkvCmdHistogram.WithLabelValues("put_ttl").Observe(time.Since(start).Seconds()) }()
+	rawkvSizeHistogram.WithLabelValues("key").Observe(float64(len(key)))
+	rawkvSizeHistogram.WithLabelValues("value").Observe(float64(len(value)))
+
+	if len(value) == 0 {
+		return errors.New("empty value is not supported")
+	}
+
+	req := &tikvrpc.Request{
+		Type: tikvrpc.CmdRawBatchPut,
+		RawBatchPut: &tikvrpc.RawBatchPutRequest{
+			Pairs: []*kvrpcpb.KvPair{{Key: key, Value: value}},
+			Ttl:   ttl,
+		},
+	}
+	resp, _, err := c.sendReq(key, req, false)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	cmdResp := resp.RawBatchPut
+	if cmdResp == nil {
+		return errors.Trace(ErrBodyMissing)
+	}
+	if cmdResp.Error != "" {
+		return errors.New(cmdResp.Error)
+	}
+	return nil
+}
+
+// BatchPut stores key-value pairs to TiKV.
+func (c *RawKVClient) BatchPut(keys, values [][]byte) error {
+	if err := c.checkExtendedRequest(); err != nil {
+		return errors.Trace(err)
+	}
+	start := time.Now()
+	defer func() { rawkvCmdHistogram.WithLabelValues("batch_put").Observe(time.Since(start).Seconds()) }()
+
+	if len(keys) != len(values) {
+		return errors.New("the length of keys is not equal to the length of values")
+	}
+	pairs := make(map[string][]byte, len(keys))
+	for i, value := range values {
+		if len(value) == 0 {
+			return errors.New("empty value is not supported")
+		}
+		pairs[string(keys[i])] = value
+	}
+
+	bo := NewBackoffer(rawkvMaxBackoff, goctx.Background())
+	sizeFn := func(key []byte) int {
+		return len(key) + len(pairs[string(key)])
+	}
+	resps, err := c.sendBatchReq(bo, keys, sizeFn, func(keys [][]byte) *tikvrpc.Request {
+		kvPairs := make([]*kvrpcpb.KvPair, 0, len(keys))
+		for _, key := range keys {
+			kvPairs = append(kvPairs, &kvrpcpb.KvPair{Key: key, Value: pairs[string(key)]})
+		}
+		return &tikvrpc.Request{
+			Type: tikvrpc.CmdRawBatchPut,
+			RawBatchPut: &tikvrpc.RawBatchPutRequest{
+				Pairs: kvPairs,
+			},
+		}
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, resp := range resps {
+		cmdResp := resp.RawBatchPut
+		if cmdResp == nil {
+			return errors.Trace(ErrBodyMissing)
+		}
+		if cmdResp.Error != "" {
+			return errors.New(cmdResp.Error)
+		}
+	}
+	return nil
+}
+
+// BatchDelete deletes key-value pairs from TiKV.
+func (c *RawKVClient) BatchDelete(keys [][]byte) error {
+	if err := c.checkExtendedRequest(); err != nil {
+		return errors.Trace(err)
+	}
+	start := time.Now()
+	defer func() { rawkvCmdHistogram.WithLabelValues("batch_delete").Observe(time.Since(start).Seconds()) }()
+
+	bo := NewBackoffer(rawkvMaxBackoff, goctx.Background())
+	resps, err := c.sendBatchReq(bo, keys, keySize, func(keys [][]byte) *tikvrpc.Request {
+		return &tikvrpc.Request{
+			Type: tikvrpc.CmdRawBatchDelete,
+			RawBatchDelete: &tikvrpc.RawBatchDeleteRequest{
+				Keys: keys,
+			},
+		}
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, resp := range resps {
+		cmdResp := resp.RawBatchDelete
+		if cmdResp == nil {
+			return errors.Trace(ErrBodyMissing)
+		}
+		if cmdResp.Error != "" {
+			return errors.New(cmdResp.Error)
+		}
+	}
+	return nil
+}
+
+// DeleteRange deletes all key-value pairs in [startKey, endKey) from TiKV.
+// An empty endKey means deleting to the end of the key space.
+func (c *RawKVClient) DeleteRange(startKey, endKey []byte) error {
+	if err := c.checkExtendedRequest(); err != nil {
+		return errors.Trace(err)
+	}
+	start := time.Now()
+	defer func() { rawkvCmdHistogram.WithLabelValues("delete_range").Observe(time.Since(start).Seconds()) }()
+
+	for len(endKey) == 0 || bytes.Compare(startKey, endKey) < 0 {
+		resp, actualEndKey, err := c.sendDeleteRangeReq(startKey, endKey)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		cmdResp := resp.RawDeleteRange
+		if cmdResp == nil {
+			return errors.Trace(ErrBodyMissing)
+		}
+		if cmdResp.Error != "" {
+			return errors.New(cmdResp.Error)
+		}
+		if len(actualEndKey) == 0 {
+			break
+		}
+		startKey = actualEndKey
+	}
+	return nil
+}
+
 // Scan queries continuous kv pairs, starts from startKey, up to limit pairs.
 // If you want to exclude the startKey, append a '\0' to the key: `Scan(append(startKey, '\0'), limit)`.
 func (c *RawKVClient) Scan(startKey []byte, limit int) (keys [][]byte, values [][]byte, err error) {
@@ -172,7 +368,7 @@ func (c *RawKVClient) Scan(startKey []byte, limit int) (keys [][]byte, values []
 				Limit:    uint32(limit - len(keys)),
 			},
 		}
-		resp, loc, err := c.sendReq(startKey, req)
+		resp, loc, err := c.sendReq(startKey, req, false)
 		if err != nil {
 			return nil, nil, errors.Trace(err)
 		}
@@ -192,11 +388,144 @@ func (c *RawKVClient) Scan(startKey []byte, limit int) (keys [][]byte, values []
 	return
 }
 
-func (c *RawKVClient) sendReq(key []byte, req *tikvrpc.Request) (*tikvrpc.Response, *KeyLocation, error) {
+// ReverseScan queries continuous kv pairs in descending order, starts from the
+// key before startKey, up to limit pairs. An empty startKey means scanning from
+// the last key. If you want to include the startKey, append a '\0' to the key:
+// `ReverseScan(append(startKey, '\0'), limit)`.
+func (c *RawKVClient) ReverseScan(startKey []byte, limit int) (keys [][]byte, values [][]byte, err error) {
+	if err := c.checkExtendedRequest(); err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	start := time.Now()
+	defer func() { rawkvCmdHistogram.WithLabelValues("raw_reverse_scan").Observe(time.Since(start).Seconds()) }()
+
+	if limit > MaxRawKVScanLimit {
+		return nil, nil, errors.Trace(ErrMaxScanLimitExceeded)
+	}
+
+	for len(keys) < limit {
+		req := &tikvrpc.Request{
+			Type: tikvrpc.CmdRawReverseScan,
+			RawReverseScan: &tikvrpc.RawReverseScanRequest{
+				StartKey: startKey,
+				Limit:    uint32(limit - len(keys)),
+			},
+		}
+		resp, loc, err := c.sendReq(startKey, req, true)
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		cmdResp := resp.RawReverseScan
+		if cmdResp == nil {
+			return nil, nil, errors.Trace(ErrBodyMissing)
+		}
+		for _, pair := range cmdResp.Kvs {
+			keys = append(keys, pair.Key)
+			values = append(values, pair.Value)
+		}
+		startKey = loc.StartKey
+		if len(startKey) == 0 {
+			break
+		}
+	}
+	return
+}
+
+// CompareAndSwap sets the value of the key to newValue if its current value is
+// previousValue. A nil previousValue means the key should not exist. It
+// returns the value before the operation, and whether the swap is done.
+func (c *RawKVClient) CompareAndSwap(key, previousValue, newValue []byte) ([]byte, bool, error) {
+	if err := c.checkExtendedRequest(); err != nil {
+		return nil, false, errors.Trace(err)
+	}
+	start := time.Now()
+	defer func() { rawkvCmdHistogram.WithLabelValues("cas").Observe(time.Since(start).Seconds()) }()
+
+	if len(newValue) == 0 {
+		return nil, false, errors.New("empty value is not supported")
+	}
+
+	req := &tikvrpc.Request{
+		Type: tikvrpc.CmdRawCompareAndSwap,
+		RawCompareAndSwap: &tikvrpc.RawCompareAndSwapRequest{
+			Key:              key,
+			Value:            newValue,
+			PreviousValue:    previousValue,
+			PreviousNotExist: previousValue == nil,
+		},
+	}
+	resp, _, err := c.sendReq(key, req, false)
+	if err != nil {
+		return nil, false, errors.Trace(err)
+	}
+	cmdResp := resp.RawCompareAndSwap
+	if cmdResp == nil {
+		return nil, false, errors.Trace(ErrBodyMissing)
+	}
+	if cmdResp.Error != "" {
+		return nil, false, errors.New(cmdResp.Error)
+	}
+	if cmdResp.PreviousNotExist {
+		return nil, cmdResp.Succeed, nil
+	}
+	return cmdResp.PreviousValue, cmdResp.Succeed, nil
+}
+
+// GetKeyTTL returns the remaining time to live of the key in seconds, 0 means
+// the key never expires. When the key does not exist, it returns `nil, nil`.
+func (c *RawKVClient) GetKeyTTL(key []byte) (*uint64, error) {
+	if err := c.checkExtendedRequest(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	start := time.Now()
+	defer func() { rawkvCmdHistogram.WithLabelValues("get_ttl").Observe(time.Since(start).Seconds()) }()
+
+	req := &tikvrpc.Request{
+		Type: tikvrpc.CmdRawGetKeyTTL,
+		RawGetKeyTTL: &tikvrpc.RawGetKeyTTLRequest{
+			Key: key,
+		},
+	}
+	resp, _, err := c.sendReq(key, req, false)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	cmdResp := resp.RawGetKeyTTL
+	if cmdResp == nil {
+		return nil, errors.Trace(ErrBodyMissing)
+	}
+	if cmdResp.Error != "" {
+		return nil, errors.New(cmdResp.Error)
+	}
+	if cmdResp.NotFound {
+		return nil, nil
+	}
+	return &cmdResp.Ttl, nil
+}
+
+// sendReq sends the request to the region which contains the key. If reverse
+// is true, the key is treated as an end key, see RegionCache.LocateEndKey.
+// checkExtendedRequest returns ErrRawKVUnsupported if the client runs on TiKV.
+// The kvproto in use doesn't define the requests beyond GET/PUT/DELETE/SCAN, so
+// only mocktikv serves them.
+func (c *RawKVClient) checkExtendedRequest() error {
+	if _, ok := c.rpcClient.(*rpcClient); ok {
+		return errors.Trace(ErrRawKVUnsupported)
+	}
+	return nil
+}
+
+func (c *RawKVClient) sendReq(key []byte, req *tikvrpc.Request, reverse bool) (*tikvrpc.Response, *KeyLocation, error) {
 	bo := NewBackoffer(rawkvMaxBackoff, goctx.Background())
 	sender := NewRegionRequestSender(c.regionCache, c.rpcClient)
 	for {
-		loc, err := c.regionCache.LocateKey(bo, key)
+		var loc *KeyLocation
+		var err error
+		if reverse {
+			loc, err = c.regionCache.LocateEndKey(bo, key)
+		} else {
+			loc, err = c.regionCache.LocateKey(bo, key)
+		}
 		if err != nil {
 			return nil, nil, errors.Trace(err)
 		}
@@ -218,3 +547,118 @@ func (c *RawKVClient) sendReq(key []byte, req *tikvrpc.Request) (*tikvrpc.Respon
 		return resp, loc, nil
 	}
 }
+
+// sendBatchReq groups the keys by region and sends the requests built by
+// reqFn to the regions concurrently, each request carries keys whose total
+// size measured by sizeFn is about rawBatchSize.
+func (c *RawKVClient) sendBatchReq(bo *Backoffer, keys [][]byte, sizeFn func([]byte) int, reqFn func([][]byte) *tikvrpc.Request) ([]*tikvrpc.Response, error) {
+	groups, _, err := c.regionCache.GroupKeysByRegion(bo, keys)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var batches []batchKeys
+	for id, g := range groups {
+		batches = appendBatchBySize(batches, id, g, sizeFn, rawBatchSize)
+	}
+	if len(batches) == 1 {
+		return c.doBatchReq(bo, batches[0], sizeFn, reqFn)
+	}
+
+	// Stop sending other requests after receiving the first error.
+	bo, cancel := bo.Fork()
+	defer cancel()
+	type batchResult struct {
+		resps []*tikvrpc.Response
+		err   error
+	}
+	ch := make(chan batchResult, len(batches))
+	for _, batch1 := range batches {
+		batch := batch1
+		go func() {
+			singleBatchBackoffer, singleBatchCancel := bo.Fork()
+			defer singleBatchCancel()
+			resps, err := c.doBatchReq(singleBatchBackoffer, batch, sizeFn, reqFn)
+			ch <- batchResult{resps: resps, err: err}
+		}()
+	}
+	var resps []*tikvrpc.Response
+	for i := 0; i < len(batches); i++ {
+		res := <-ch
+		if res.err != nil {
+			if err == nil {
+				cancel()
+				err = res.err
+			}
+			continue
+		}
+		resps = append(resps, res.resps...)
+	}
+	return resps, errors.Trace(err)
+}
+
+func (c *RawKVClient) doBatchReq(bo *Backoffer, batch batchKeys, sizeFn func([]byte) int, reqFn func([][]byte) *tikvrpc.Request) ([]*tikvrpc.Response, error) {
+	sender := NewRegionRequestSender(c.regionCache, c.rpcClient)
+	resp, err := sender.SendReq(bo, reqFn(batch.keys), batch.region, readTimeoutShort)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	regionErr, err := resp.GetRegionError()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if regionErr != nil {
+		err = bo.Backoff(BoRegionMiss, errors.New(regionErr.String()))
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		// The region may be split or merged, group the keys again.
+		resps, err := c.sendBatchReq(bo, batch.keys, sizeFn, reqFn)
+		return resps, errors.Trace(err)
+	}
+	return []*tikvrpc.Response{resp}, nil
+}
+
+// sendDeleteRangeReq sends a delete range request to the region which contains
+// startKey, the range is cut at the end of the region. It returns the end key
+// of the range actually deleted.
+func (c *RawKVClient) sendDeleteRangeReq(startKey, endKey []byte) (*tikvrpc.Response, []byte, error) {
+	bo := NewBackoffer(rawkvMaxBackoff, goctx.Background())
+	sender := NewRegionRequestSender(c.regionCache, c.rpcClient)
+	for {
+		loc, err := c.regionCache.LocateKey(bo, startKey)
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		actualEndKey := endKey
+		if len(loc.EndKey) > 0 && (len(endKey) == 0 || bytes.Compare(loc.EndKey, endKey) < 0) {
+			actualEndKey = loc.EndKey
+		}
+		req := &tikvrpc.Request{
+			Type: tikvrpc.CmdRawDeleteRange,
+			RawDeleteRange: &tikvrpc.RawDeleteRangeRequest{
+				StartKey: startKey,
+				EndKey:   actualEndKey,
+			},
+		}
+		resp, err := sender.SendReq(bo, req, loc.Region, readTimeoutShort)
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		regionErr, err := resp.GetRegionError()
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		if regionErr != nil {
+			err := bo.Backoff(BoRegionMiss, errors.New(regionErr.String()))
+			if err != nil {
+				return nil, nil, errors.Trace(err)
+			}
+			continue
+		}
+		return resp, actualEndKey, nil
+	}
+}
+
+func keySize(key []byte) int {
+	return len(key)
+}
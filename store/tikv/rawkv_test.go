@@ -14,7 +14,12 @@
 package tikv
 
 import (
+	"fmt"
+	"time"
+
+	"github.com/juju/errors"
 	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/store/tikv/mocktikv"
 	goctx "golang.org/x/net/context"
 )
@@ -77,6 +82,23 @@ func (s *testRawKVSuite) mustScan(c *C, startKey string, limit int, expect ...st
 	}
 }
 
+func (s *testRawKVSuite) mustReverseScan(c *C, startKey string, limit int, expect ...string) {
+	keys, values, err := s.client.ReverseScan([]byte(startKey), limit)
+	c.Assert(err, IsNil)
+	c.Assert(len(keys)*2, Equals, len(expect))
+	for i := range keys {
+		c.Assert(string(keys[i]), Equals, expect[i*2])
+		c.Assert(string(values[i]), Equals, expect[i*2+1])
+	}
+}
+
+func (s *testRawKVSuite) split(c *C, regionKey, splitKey string) {
+	loc, err := s.client.regionCache.LocateKey(s.bo, []byte(regionKey))
+	c.Assert(err, IsNil)
+	newRegionID, peerID := s.cluster.AllocID(), s.cluster.AllocID()
+	s.cluster.SplitRaw(loc.Region.id, newRegionID, []byte(splitKey), []uint64{peerID}, peerID)
+}
+
 func (s *testRawKVSuite) TestSimple(c *C) {
 	s.mustNotExist(c, []byte("key"))
 	s.mustPut(c, []byte("key"), []byte("value"))
@@ -114,16 +136,172 @@ func (s *testRawKVSuite) TestScan(c *C) {
 		s.mustScan(c, "k2", 3, "k3", "v3", "k5", "v5", "k7", "v7")
 	}
 
-	split := func(regionKey, splitKey string) {
-		loc, err := s.client.regionCache.LocateKey(s.bo, []byte(regionKey))
-		c.Assert(err, IsNil)
-		newRegionID, peerID := s.cluster.AllocID(), s.cluster.AllocID()
-		s.cluster.SplitRaw(loc.Region.id, newRegionID, []byte(splitKey), []uint64{peerID}, peerID)
+	check()
+	s.split(c, "k", "k2")
+	check()
+	s.split(c, "k2", "k5")
+	check()
+}
+
+func (s *testRawKVSuite) TestReverseScan(c *C) {
+	s.mustPut(c, []byte("k1"), []byte("v1"))
+	s.mustPut(c, []byte("k3"), []byte("v3"))
+	s.mustPut(c, []byte("k5"), []byte("v5"))
+	s.mustPut(c, []byte("k7"), []byte("v7"))
+
+	check := func() {
+		s.mustReverseScan(c, "", 1, "k7", "v7")
+		s.mustReverseScan(c, "k7", 2, "k5", "v5", "k3", "v3")
+		s.mustReverseScan(c, "", 10, "k7", "v7", "k5", "v5", "k3", "v3", "k1", "v1")
+		s.mustReverseScan(c, "k6", 2, "k5", "v5", "k3", "v3")
+		s.mustReverseScan(c, "k6", 3, "k5", "v5", "k3", "v3", "k1", "v1")
+		s.mustReverseScan(c, "k1", 3)
 	}
 
 	check()
-	split("k", "k2")
+	s.split(c, "k", "k2")
 	check()
-	split("k2", "k5")
+	s.split(c, "k2", "k5")
 	check()
 }
+
+func (s *testRawKVSuite) TestBatch(c *C) {
+	var keys, values [][]byte
+	for i := 0; i < 1000; i++ {
+		keys = append(keys, []byte(fmt.Sprintf("k%03d", i)))
+		values = append(values, []byte(fmt.Sprintf("v%03d", i)))
+	}
+	s.split(c, "k", "k300")
+	s.split(c, "k300", "k600")
+
+	c.Assert(s.client.BatchPut(keys, values), IsNil)
+	s.mustGet(c, []byte("k500"), []byte("v500"))
+	got, err := s.client.BatchGet(append(keys, []byte("k1000")))
+	c.Assert(err, IsNil)
+	c.Assert(got, HasLen, len(keys)+1)
+	for i := range keys {
+		c.Assert(got[i], BytesEquals, values[i])
+	}
+	c.Assert(got[len(keys)], IsNil)
+
+	// Regions split after the keys are grouped are retried.
+	s.split(c, "k400", "k450")
+	c.Assert(s.client.BatchDelete(keys[:500]), IsNil)
+	got, err = s.client.BatchGet(keys)
+	c.Assert(err, IsNil)
+	for i := range keys {
+		if i < 500 {
+			c.Assert(got[i], IsNil)
+		} else {
+			c.Assert(got[i], BytesEquals, values[i])
+		}
+	}
+
+	err = s.client.BatchPut(keys, values[:1])
+	c.Assert(err, NotNil)
+	err = s.client.BatchPut(keys[:1], [][]byte{{}})
+	c.Assert(err, NotNil)
+}
+
+func (s *testRawKVSuite) TestDeleteRange(c *C) {
+	for _, k := range []string{"k1", "k2", "k3", "k4", "k5"} {
+		s.mustPut(c, []byte(k), []byte("v"))
+	}
+	s.split(c, "k", "k3")
+
+	c.Assert(s.client.DeleteRange([]byte("k2"), []byte("k4")), IsNil)
+	s.mustScan(c, "", 10, "k1", "v", "k4", "v", "k5", "v")
+	c.Assert(s.client.DeleteRange([]byte(""), []byte("k5")), IsNil)
+	s.mustScan(c, "", 10, "k5", "v")
+	// An empty range deletes nothing.
+	c.Assert(s.client.DeleteRange([]byte("k6"), []byte("k5")), IsNil)
+	s.mustGet(c, []byte("k5"), []byte("v"))
+
+	// An empty end key deletes to the end, across the regions.
+	for _, k := range []string{"k1", "k2", "k3", "k4"} {
+		s.mustPut(c, []byte(k), []byte("v"))
+	}
+	c.Assert(s.client.DeleteRange([]byte("k2"), nil), IsNil)
+	s.mustScan(c, "", 10, "k1", "v")
+	c.Assert(s.client.DeleteRange(nil, nil), IsNil)
+	s.mustNotExist(c, []byte("k1"))
+}
+
+func (s *testRawKVSuite) TestCompareAndSwap(c *C) {
+	key := []byte("key")
+	prev, ok, err := s.client.CompareAndSwap(key, []byte("v0"), []byte("v1"))
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsFalse)
+	c.Assert(prev, IsNil)
+	s.mustNotExist(c, key)
+
+	prev, ok, err = s.client.CompareAndSwap(key, nil, []byte("v1"))
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+	c.Assert(prev, IsNil)
+	s.mustGet(c, key, []byte("v1"))
+
+	prev, ok, err = s.client.CompareAndSwap(key, nil, []byte("v2"))
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsFalse)
+	c.Assert(prev, BytesEquals, []byte("v1"))
+
+	prev, ok, err = s.client.CompareAndSwap(key, []byte("v1"), []byte("v2"))
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+	c.Assert(prev, BytesEquals, []byte("v1"))
+	s.mustGet(c, key, []byte("v2"))
+}
+
+func (s *testRawKVSuite) TestTTL(c *C) {
+	ttl, err := s.client.GetKeyTTL([]byte("key"))
+	c.Assert(err, IsNil)
+	c.Assert(ttl, IsNil)
+
+	s.mustPut(c, []byte("key"), []byte("value"))
+	ttl, err = s.client.GetKeyTTL([]byte("key"))
+	c.Assert(err, IsNil)
+	c.Assert(*ttl, Equals, uint64(0))
+
+	c.Assert(s.client.PutWithTTL([]byte("key"), []byte("value"), 100), IsNil)
+	ttl, err = s.client.GetKeyTTL([]byte("key"))
+	c.Assert(err, IsNil)
+	c.Assert(*ttl > 0 && *ttl <= 100, IsTrue)
+
+	c.Assert(s.client.PutWithTTL([]byte("key1"), []byte("value1"), 1), IsNil)
+	s.mustGet(c, []byte("key1"), []byte("value1"))
+	time.Sleep(time.Second)
+	s.mustNotExist(c, []byte("key1"))
+	s.mustScan(c, "", 10, "key", "value")
+	ttl, err = s.client.GetKeyTTL([]byte("key1"))
+	c.Assert(err, IsNil)
+	c.Assert(ttl, IsNil)
+}
+
+func (s *testRawKVSuite) TestUnsupportedByTiKV(c *C) {
+	client := &RawKVClient{
+		clusterID:   0,
+		regionCache: s.client.regionCache,
+		pdClient:    s.client.pdClient,
+		rpcClient:   newRPCClient(config.Security{}),
+	}
+	defer client.rpcClient.Close()
+
+	key, value := []byte("key"), []byte("value")
+	_, err := client.BatchGet([][]byte{key})
+	c.Assert(errors.Cause(err), Equals, ErrRawKVUnsupported)
+	err = client.PutWithTTL(key, value, 1)
+	c.Assert(errors.Cause(err), Equals, ErrRawKVUnsupported)
+	err = client.BatchPut([][]byte{key}, [][]byte{value})
+	c.Assert(errors.Cause(err), Equals, ErrRawKVUnsupported)
+	err = client.BatchDelete([][]byte{key})
+	c.Assert(errors.Cause(err), Equals, ErrRawKVUnsupported)
+	err = client.DeleteRange(key, nil)
+	c.Assert(errors.Cause(err), Equals, ErrRawKVUnsupported)
+	_, _, err = client.ReverseScan(key, 1)
+	c.Assert(errors.Cause(err), Equals, ErrRawKVUnsupported)
+	_, _, err = client.CompareAndSwap(key, nil, value)
+	c.Assert(errors.Cause(err), Equals, ErrRawKVUnsupported)
+	_, err = client.GetKeyTTL(key)
+	c.Assert(errors.Cause(err), Equals, ErrRawKVUnsupported)
+}
@@ -133,6 +133,16 @@ func (l *KeyLocation) Contains(key []byte) bool {
 		(bytes.Compare(key, l.EndKey) < 0 || len(l.EndKey) == 0)
 }
 
+// ContainsByEnd checks if key is in (StartKey, EndKey], an empty key is
+// considered to be the end of the key space.
+func (l *KeyLocation) ContainsByEnd(key []byte) bool {
+	if len(key) == 0 {
+		return len(l.EndKey) == 0
+	}
+	return bytes.Compare(l.StartKey, key) < 0 &&
+		(bytes.Compare(key, l.EndKey) <= 0 || len(l.EndKey) == 0)
+}
+
 // LocateKey searches for the region and range that the key is located.
 func (c *RegionCache) LocateKey(bo *Backoffer, key []byte) (*KeyLocation, error) {
 	c.mu.RLock()
@@ -164,6 +174,34 @@ func (c *RegionCache) LocateKey(bo *Backoffer, key []byte) (*KeyLocation, error)
 	}, nil
 }
 
+// LocateEndKey searches for the region and range that the key is located,
+// the key is treated as an end key: it belongs to the region which ends at
+// it, rather than the one starts from it. An empty key locates the last region.
+func (c *RegionCache) LocateEndKey(bo *Backoffer, key []byte) (*KeyLocation, error) {
+	// PD only locates a region by a key inside it, so start from a key
+	// before the given one and move forward until the region is found.
+	var searchKey []byte
+	if len(key) > 0 {
+		searchKey = key[:len(key)-1]
+	} else {
+		c.mu.RLock()
+		if item := c.mu.sorted.Max(); item != nil {
+			searchKey = item.(*btreeItem).key
+		}
+		c.mu.RUnlock()
+	}
+	for {
+		loc, err := c.LocateKey(bo, searchKey)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if loc.ContainsByEnd(key) {
+			return loc, nil
+		}
+		searchKey = loc.EndKey
+	}
+}
+
 // LocateRegionByID searches for the region with ID.
 func (c *RegionCache) LocateRegionByID(bo *Backoffer, regionID uint64) (*KeyLocation, error) {
 	c.mu.RLock()
@@ -0,0 +1,229 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tikvrpc
+
+import (
+	"github.com/pingcap/kvproto/pkg/errorpb"
+	"github.com/pingcap/kvproto/pkg/kvrpcpb"
+)
+
+// The raw kv requests and responses below are not defined in the kvproto in
+// use yet, they are laid out the way kvproto defines the others so that they
+// can be replaced by the generated ones once they are available.
+
+// RawBatchGetRequest gets the values of a batch of keys in a Region.
+type RawBatchGetRequest struct {
+	Context *kvrpcpb.Context
+	Keys    [][]byte
+}
+
+// Size returns the size of the request in bytes.
+func (m *RawBatchGetRequest) Size() int {
+	return keysSize(m.Keys)
+}
+
+// RawBatchGetResponse is the response of RawBatchGetRequest, keys that do not
+// exist are absent from Pairs.
+type RawBatchGetResponse struct {
+	RegionError *errorpb.Error
+	Pairs       []*kvrpcpb.KvPair
+}
+
+// GetRegionError returns the RegionError of the response.
+func (m *RawBatchGetResponse) GetRegionError() *errorpb.Error {
+	if m != nil {
+		return m.RegionError
+	}
+	return nil
+}
+
+// RawBatchPutRequest puts a batch of key-value pairs in a Region. If Ttl is
+// not 0, the pairs expire after Ttl seconds.
+type RawBatchPutRequest struct {
+	Context *kvrpcpb.Context
+	Pairs   []*kvrpcpb.KvPair
+	Ttl     uint64
+}
+
+// Size returns the size of the request in bytes.
+func (m *RawBatchPutRequest) Size() int {
+	var size int
+	for _, pair := range m.Pairs {
+		size += len(pair.Key) + len(pair.Value)
+	}
+	return size
+}
+
+// RawBatchPutResponse is the response of RawBatchPutRequest.
+type RawBatchPutResponse struct {
+	RegionError *errorpb.Error
+	Error       string
+}
+
+// GetRegionError returns the RegionError of the response.
+func (m *RawBatchPutResponse) GetRegionError() *errorpb.Error {
+	if m != nil {
+		return m.RegionError
+	}
+	return nil
+}
+
+// RawBatchDeleteRequest deletes a batch of keys in a Region.
+type RawBatchDeleteRequest struct {
+	Context *kvrpcpb.Context
+	Keys    [][]byte
+}
+
+// Size returns the size of the request in bytes.
+func (m *RawBatchDeleteRequest) Size() int {
+	return keysSize(m.Keys)
+}
+
+// RawBatchDeleteResponse is the response of RawBatchDeleteRequest.
+type RawBatchDeleteResponse struct {
+	RegionError *errorpb.Error
+	Error       string
+}
+
+// GetRegionError returns the RegionError of the response.
+func (m *RawBatchDeleteResponse) GetRegionError() *errorpb.Error {
+	if m != nil {
+		return m.RegionError
+	}
+	return nil
+}
+
+// RawDeleteRangeRequest deletes the keys in [StartKey, EndKey) of a Region.
+type RawDeleteRangeRequest struct {
+	Context  *kvrpcpb.Context
+	StartKey []byte
+	EndKey   []byte
+}
+
+// Size returns the size of the request in bytes.
+func (m *RawDeleteRangeRequest) Size() int {
+	return len(m.StartKey) + len(m.EndKey)
+}
+
+// RawDeleteRangeResponse is the response of RawDeleteRangeRequest.
+type RawDeleteRangeResponse struct {
+	RegionError *errorpb.Error
+	Error       string
+}
+
+// GetRegionError returns the RegionError of the response.
+func (m *RawDeleteRangeResponse) GetRegionError() *errorpb.Error {
+	if m != nil {
+		return m.RegionError
+	}
+	return nil
+}
+
+// RawReverseScanRequest scans the keys before StartKey (exclusive) of a Region
+// in descending order, up to Limit pairs.
+type RawReverseScanRequest struct {
+	Context  *kvrpcpb.Context
+	StartKey []byte
+	Limit    uint32
+}
+
+// Size returns the size of the request in bytes.
+func (m *RawReverseScanRequest) Size() int {
+	return len(m.StartKey)
+}
+
+// RawReverseScanResponse is the response of RawReverseScanRequest.
+type RawReverseScanResponse struct {
+	RegionError *errorpb.Error
+	Kvs         []*kvrpcpb.KvPair
+}
+
+// GetRegionError returns the RegionError of the response.
+func (m *RawReverseScanResponse) GetRegionError() *errorpb.Error {
+	if m != nil {
+		return m.RegionError
+	}
+	return nil
+}
+
+// RawCompareAndSwapRequest sets the value of Key to Value if its current value
+// is PreviousValue, or if it does not exist when PreviousNotExist is set. If
+// Ttl is not 0, the new value expires after Ttl seconds.
+type RawCompareAndSwapRequest struct {
+	Context          *kvrpcpb.Context
+	Key              []byte
+	Value            []byte
+	PreviousValue    []byte
+	PreviousNotExist bool
+	Ttl              uint64
+}
+
+// Size returns the size of the request in bytes.
+func (m *RawCompareAndSwapRequest) Size() int {
+	return len(m.Key) + len(m.Value) + len(m.PreviousValue)
+}
+
+// RawCompareAndSwapResponse is the response of RawCompareAndSwapRequest, it
+// carries the value of the key before the request.
+type RawCompareAndSwapResponse struct {
+	RegionError      *errorpb.Error
+	Error            string
+	PreviousValue    []byte
+	PreviousNotExist bool
+	Succeed          bool
+}
+
+// GetRegionError returns the RegionError of the response.
+func (m *RawCompareAndSwapResponse) GetRegionError() *errorpb.Error {
+	if m != nil {
+		return m.RegionError
+	}
+	return nil
+}
+
+// RawGetKeyTTLRequest gets the remaining time to live of a key.
+type RawGetKeyTTLRequest struct {
+	Context *kvrpcpb.Context
+	Key     []byte
+}
+
+// Size returns the size of the request in bytes.
+func (m *RawGetKeyTTLRequest) Size() int {
+	return len(m.Key)
+}
+
+// RawGetKeyTTLResponse is the response of RawGetKeyTTLRequest, a Ttl of 0
+// means the key never expires.
+type RawGetKeyTTLResponse struct {
+	RegionError *errorpb.Error
+	Error       string
+	Ttl         uint64
+	NotFound    bool
+}
+
+// GetRegionError returns the RegionError of the response.
+func (m *RawGetKeyTTLResponse) GetRegionError() *errorpb.Error {
+	if m != nil {
+		return m.RegionError
+	}
+	return nil
+}
+
+func keysSize(keys [][]byte) int {
+	var size int
+	for _, k := range keys {
+		size += len(k)
+	}
+	return size
+}
@@ -44,6 +44,13 @@ const (
 	CmdRawPut
 	CmdRawDelete
 	CmdRawScan
+	CmdRawBatchGet
+	CmdRawBatchPut
+	CmdRawBatchDelete
+	CmdRawDeleteRange
+	CmdRawReverseScan
+	CmdRawCompareAndSwap
+	CmdRawGetKeyTTL
 
 	CmdCop CmdType = 512 + iota
 	CmdCopStream
@@ -85,6 +92,20 @@ func (t CmdType) String() string {
 		return "RawDelete"
 	case CmdRawScan:
 		return "RawScan"
+	case CmdRawBatchGet:
+		return "RawBatchGet"
+	case CmdRawBatchPut:
+		return "RawBatchPut"
+	case CmdRawBatchDelete:
+		return "RawBatchDelete"
+	case CmdRawDeleteRange:
+		return "RawDeleteRange"
+	case CmdRawReverseScan:
+		return "RawReverseScan"
+	case CmdRawCompareAndSwap:
+		return "RawCompareAndSwap"
+	case CmdRawGetKeyTTL:
+		return "RawGetKeyTTL"
 	case CmdCop:
 		return "Cop"
 	case CmdCopStream:
@@ -102,51 +123,65 @@ func (t CmdType) String() string {
 // Request wraps all kv/coprocessor requests.
 type Request struct {
 	kvrpcpb.Context
-	Type             CmdType
-	Get              *kvrpcpb.GetRequest
-	Scan             *kvrpcpb.ScanRequest
-	Prewrite         *kvrpcpb.PrewriteRequest
-	Commit           *kvrpcpb.CommitRequest
-	Cleanup          *kvrpcpb.CleanupRequest
-	BatchGet         *kvrpcpb.BatchGetRequest
-	BatchRollback    *kvrpcpb.BatchRollbackRequest
-	ScanLock         *kvrpcpb.ScanLockRequest
-	ResolveLock      *kvrpcpb.ResolveLockRequest
-	GC               *kvrpcpb.GCRequest
-	DeleteRange      *kvrpcpb.DeleteRangeRequest
-	RawGet           *kvrpcpb.RawGetRequest
-	RawPut           *kvrpcpb.RawPutRequest
-	RawDelete        *kvrpcpb.RawDeleteRequest
-	RawScan          *kvrpcpb.RawScanRequest
-	Cop              *coprocessor.Request
-	MvccGetByKey     *kvrpcpb.MvccGetByKeyRequest
-	MvccGetByStartTs *kvrpcpb.MvccGetByStartTsRequest
-	SplitRegion      *kvrpcpb.SplitRegionRequest
+	Type              CmdType
+	Get               *kvrpcpb.GetRequest
+	Scan              *kvrpcpb.ScanRequest
+	Prewrite          *kvrpcpb.PrewriteRequest
+	Commit            *kvrpcpb.CommitRequest
+	Cleanup           *kvrpcpb.CleanupRequest
+	BatchGet          *kvrpcpb.BatchGetRequest
+	BatchRollback     *kvrpcpb.BatchRollbackRequest
+	ScanLock          *kvrpcpb.ScanLockRequest
+	ResolveLock       *kvrpcpb.ResolveLockRequest
+	GC                *kvrpcpb.GCRequest
+	DeleteRange       *kvrpcpb.DeleteRangeRequest
+	RawGet            *kvrpcpb.RawGetRequest
+	RawPut            *kvrpcpb.RawPutRequest
+	RawDelete         *kvrpcpb.RawDeleteRequest
+	RawScan           *kvrpcpb.RawScanRequest
+	RawBatchGet       *RawBatchGetRequest
+	RawBatchPut       *RawBatchPutRequest
+	RawBatchDelete    *RawBatchDeleteRequest
+	RawDeleteRange    *RawDeleteRangeRequest
+	RawReverseScan    *RawReverseScanRequest
+	RawCompareAndSwap *RawCompareAndSwapRequest
+	RawGetKeyTTL      *RawGetKeyTTLRequest
+	Cop               *coprocessor.Request
+	MvccGetByKey      *kvrpcpb.MvccGetByKeyRequest
+	MvccGetByStartTs  *kvrpcpb.MvccGetByStartTsRequest
+	SplitRegion       *kvrpcpb.SplitRegionRequest
 }
 
 // Response wraps all kv/coprocessor responses.
 type Response struct {
-	Type             CmdType
-	Get              *kvrpcpb.GetResponse
-	Scan             *kvrpcpb.ScanResponse
-	Prewrite         *kvrpcpb.PrewriteResponse
-	Commit           *kvrpcpb.CommitResponse
-	Cleanup          *kvrpcpb.CleanupResponse
-	BatchGet         *kvrpcpb.BatchGetResponse
-	BatchRollback    *kvrpcpb.BatchRollbackResponse
-	ScanLock         *kvrpcpb.ScanLockResponse
-	ResolveLock      *kvrpcpb.ResolveLockResponse
-	GC               *kvrpcpb.GCResponse
-	DeleteRange      *kvrpcpb.DeleteRangeResponse
-	RawGet           *kvrpcpb.RawGetResponse
-	RawPut           *kvrpcpb.RawPutResponse
-	RawDelete        *kvrpcpb.RawDeleteResponse
-	RawScan          *kvrpcpb.RawScanResponse
-	Cop              *coprocessor.Response
-	CopStream        tikvpb.Tikv_CoprocessorStreamClient
-	MvccGetByKey     *kvrpcpb.MvccGetByKeyResponse
-	MvccGetByStartTS *kvrpcpb.MvccGetByStartTsResponse
-	SplitRegion      *kvrpcpb.SplitRegionResponse
+	Type              CmdType
+	Get               *kvrpcpb.GetResponse
+	Scan              *kvrpcpb.ScanResponse
+	Prewrite          *kvrpcpb.PrewriteResponse
+	Commit            *kvrpcpb.CommitResponse
+	Cleanup           *kvrpcpb.CleanupResponse
+	BatchGet          *kvrpcpb.BatchGetResponse
+	BatchRollback     *kvrpcpb.BatchRollbackResponse
+	ScanLock          *kvrpcpb.ScanLockResponse
+	ResolveLock       *kvrpcpb.ResolveLockResponse
+	GC                *kvrpcpb.GCResponse
+	DeleteRange       *kvrpcpb.DeleteRangeResponse
+	RawGet            *kvrpcpb.RawGetResponse
+	RawPut            *kvrpcpb.RawPutResponse
+	RawDelete         *kvrpcpb.RawDeleteResponse
+	RawScan           *kvrpcpb.RawScanResponse
+	RawBatchGet       *RawBatchGetResponse
+	RawBatchPut       *RawBatchPutResponse
+	RawBatchDelete    *RawBatchDeleteResponse
+	RawDeleteRange    *RawDeleteRangeResponse
+	RawReverseScan    *RawReverseScanResponse
+	RawCompareAndSwap *RawCompareAndSwapResponse
+	RawGetKeyTTL      *RawGetKeyTTLResponse
+	Cop               *coprocessor.Response
+	CopStream         tikvpb.Tikv_CoprocessorStreamClient
+	MvccGetByKey      *kvrpcpb.MvccGetByKeyResponse
+	MvccGetByStartTS  *kvrpcpb.MvccGetByStartTsResponse
+	SplitRegion       *kvrpcpb.SplitRegionResponse
 }
 
 // SetContext set the Context field for the given req to the specified ctx.
@@ -187,6 +222,20 @@ func SetContext(req *Request, region *metapb.Region, peer *metapb.Peer) error {
 		req.RawDelete.Context = ctx
 	case CmdRawScan:
 		req.RawScan.Context = ctx
+	case CmdRawBatchGet:
+		req.RawBatchGet.Context = ctx
+	case CmdRawBatchPut:
+		req.RawBatchPut.Context = ctx
+	case CmdRawBatchDelete:
+		req.RawBatchDelete.Context = ctx
+	case CmdRawDeleteRange:
+		req.RawDeleteRange.Context = ctx
+	case CmdRawReverseScan:
+		req.RawReverseScan.Context = ctx
+	case CmdRawCompareAndSwap:
+		req.RawCompareAndSwap.Context = ctx
+	case CmdRawGetKeyTTL:
+		req.RawGetKeyTTL.Context = ctx
 	case CmdCop:
 		req.Cop.Context = ctx
 	case CmdMvccGetByKey:
@@ -267,6 +316,34 @@ func GenRegionErrorResp(req *Request, e *errorpb.Error) (*Response, error) {
 		resp.RawScan = &kvrpcpb.RawScanResponse{
 			RegionError: e,
 		}
+	case CmdRawBatchGet:
+		resp.RawBatchGet = &RawBatchGetResponse{
+			RegionError: e,
+		}
+	case CmdRawBatchPut:
+		resp.RawBatchPut = &RawBatchPutResponse{
+			RegionError: e,
+		}
+	case CmdRawBatchDelete:
+		resp.RawBatchDelete = &RawBatchDeleteResponse{
+			RegionError: e,
+		}
+	case CmdRawDeleteRange:
+		resp.RawDeleteRange = &RawDeleteRangeResponse{
+			RegionError: e,
+		}
+	case CmdRawReverseScan:
+		resp.RawReverseScan = &RawReverseScanResponse{
+			RegionError: e,
+		}
+	case CmdRawCompareAndSwap:
+		resp.RawCompareAndSwap = &RawCompareAndSwapResponse{
+			RegionError: e,
+		}
+	case CmdRawGetKeyTTL:
+		resp.RawGetKeyTTL = &RawGetKeyTTLResponse{
+			RegionError: e,
+		}
 	case CmdCop:
 		resp.Cop = &coprocessor.Response{
 			RegionError: e,
@@ -323,6 +400,20 @@ func (resp *Response) GetRegionError() (*errorpb.Error, error) {
 		e = resp.RawDelete.GetRegionError()
 	case CmdRawScan:
 		e = resp.RawScan.GetRegionError()
+	case CmdRawBatchGet:
+		e = resp.RawBatchGet.GetRegionError()
+	case CmdRawBatchPut:
+		e = resp.RawBatchPut.GetRegionError()
+	case CmdRawBatchDelete:
+		e = resp.RawBatchDelete.GetRegionError()
+	case CmdRawDeleteRange:
+		e = resp.RawDeleteRange.GetRegionError()
+	case CmdRawReverseScan:
+		e = resp.RawReverseScan.GetRegionError()
+	case CmdRawCompareAndSwap:
+		e = resp.RawCompareAndSwap.GetRegionError()
+	case CmdRawGetKeyTTL:
+		e = resp.RawGetKeyTTL.GetRegionError()
 	case CmdCop:
 		e = resp.Cop.GetRegionError()
 	case CmdMvccGetByKey:
@@ -0,0 +1,135 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tikv
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/store/tikv/mocktikv"
+	"github.com/pingcap/tidb/terror"
+)
+
+// Sub directories of a unistore data directory.
+const (
+	uniStoreDataDir = "data"
+	uniStoreMetaDir = "meta"
+)
+
+// UniStoreDriver is the driver of a persistent single node store. It runs
+// mocktikv on a local data directory, the data, the Regions, the timestamp
+// oracle and the GC safe point all survive restarts, and the GC worker works
+// as it does on TiKV.
+type UniStoreDriver struct {
+}
+
+// Open opens or creates a unistore with given path.
+// Path example: unistore:///var/lib/tidb
+func (d UniStoreDriver) Open(path string) (kv.Storage, error) {
+	u, err := url.Parse(path)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if !strings.EqualFold(u.Scheme, "unistore") {
+		return nil, errors.Errorf("Uri scheme expected(unistore) but found (%s)", u.Scheme)
+	}
+	dir := u.Host + u.Path
+	if dir == "" {
+		return nil, errors.New("unistore requires a data directory")
+	}
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	mc.Lock()
+	defer mc.Unlock()
+
+	uuid := "unistore-" + dir
+	if store, ok := mc.cache[uuid]; ok {
+		return store, nil
+	}
+	s, err := newUniStore(uuid, dir)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	mc.cache[uuid] = s
+	return s, nil
+}
+
+// NewUniStore creates a unistore in the directory dir, a new one is created if
+// dir is empty, otherwise the saved one is loaded.
+func NewUniStore(dir string) (kv.Storage, error) {
+	return UniStoreDriver{}.Open("unistore://" + dir)
+}
+
+func newUniStore(uuid, dir string) (s *tikvStore, err error) {
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.Trace(err)
+	}
+	meta, err := mocktikv.NewMetaStore(filepath.Join(dir, uniStoreMetaDir))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	mvccStore, err := mocktikv.NewMVCCLevelDB(filepath.Join(dir, uniStoreDataDir))
+	if err != nil {
+		terror.Log(meta.Close())
+		return nil, errors.Trace(err)
+	}
+	defer func() {
+		if err != nil {
+			terror.Log(mvccStore.Close())
+			terror.Log(meta.Close())
+		}
+	}()
+
+	cluster, err := mocktikv.NewPersistentCluster(meta)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(cluster.GetAllRegions()) == 0 {
+		mocktikv.BootstrapWithSingleStore(cluster)
+	}
+	pdCli, err := mocktikv.NewPersistentPDClient(cluster, meta)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	client := &uniStoreClient{
+		Client: mocktikv.NewRPCClient(cluster, mvccStore),
+		meta:   meta,
+	}
+	s, err = newTikvStore(uuid, &codecPDClient{pdCli}, meta, client, true)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	s.mock = true
+	return s, nil
+}
+
+// uniStoreClient closes the MetaStore together with the mocktikv client.
+type uniStoreClient struct {
+	Client
+	meta *mocktikv.MetaStore
+}
+
+func (c *uniStoreClient) Close() error {
+	err := c.Client.Close()
+	terror.Log(c.meta.Close())
+	return errors.Trace(err)
+}
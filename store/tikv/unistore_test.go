@@ -0,0 +1,91 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tikv
+
+import (
+	"io/ioutil"
+	"os"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/kv"
+	goctx "golang.org/x/net/context"
+)
+
+type testUniStoreSuite struct {
+	dir string
+}
+
+var _ = Suite(&testUniStoreSuite{})
+
+func (s *testUniStoreSuite) SetUpTest(c *C) {
+	var err error
+	s.dir, err = ioutil.TempDir("", "unistore")
+	c.Assert(err, IsNil)
+}
+
+func (s *testUniStoreSuite) TearDownTest(c *C) {
+	os.RemoveAll(s.dir)
+}
+
+func (s *testUniStoreSuite) TestOpen(c *C) {
+	_, err := UniStoreDriver{}.Open("mocktikv:///tmp")
+	c.Assert(err, NotNil)
+	_, err = UniStoreDriver{}.Open("unistore://")
+	c.Assert(err, NotNil)
+
+	store, err := NewUniStore(s.dir)
+	c.Assert(err, IsNil)
+	defer store.Close()
+	// Opening the same directory again returns the cached store.
+	store1, err := UniStoreDriver{}.Open("unistore://" + s.dir)
+	c.Assert(err, IsNil)
+	c.Assert(store1, Equals, store)
+	c.Assert(store.SupportDeleteRange(), IsTrue)
+}
+
+func (s *testUniStoreSuite) TestRestart(c *C) {
+	store, err := NewUniStore(s.dir)
+	c.Assert(err, IsNil)
+	txn, err := store.Begin()
+	c.Assert(err, IsNil)
+	c.Assert(txn.Set(kv.Key("a"), []byte("1")), IsNil)
+	c.Assert(txn.Set(kv.Key("b"), []byte("2")), IsNil)
+	c.Assert(txn.Commit(goctx.Background()), IsNil)
+	// Split a region, it should be remembered after restart.
+	c.Assert(store.(*tikvStore).SplitRegion(kv.Key("b")), IsNil)
+	region, _, err := store.(*tikvStore).pdClient.GetRegion(goctx.Background(), []byte("b"))
+	c.Assert(err, IsNil)
+	lastVer, err := store.CurrentVersion()
+	c.Assert(err, IsNil)
+	c.Assert(store.Close(), IsNil)
+
+	store, err = NewUniStore(s.dir)
+	c.Assert(err, IsNil)
+	defer store.Close()
+	ver, err := store.CurrentVersion()
+	c.Assert(err, IsNil)
+	c.Assert(ver.Cmp(lastVer), Greater, 0)
+	snapshot, err := store.GetSnapshot(ver)
+	c.Assert(err, IsNil)
+	value, err := snapshot.Get(kv.Key("a"))
+	c.Assert(err, IsNil)
+	c.Assert(value, BytesEquals, []byte("1"))
+	value, err = snapshot.Get(kv.Key("b"))
+	c.Assert(err, IsNil)
+	c.Assert(value, BytesEquals, []byte("2"))
+	region1, _, err := store.(*tikvStore).pdClient.GetRegion(goctx.Background(), []byte("b"))
+	c.Assert(err, IsNil)
+	c.Assert(region1, DeepEquals, region)
+	c.Assert(region1.GetStartKey(), BytesEquals, []byte("b"))
+}
@@ -216,6 +216,11 @@ func (t *TxStructure) HGetAll(key []byte) ([]HashPair, error) {
 	return res, errors.Trace(err)
 }
 
+// HIterate iterates all the fields and values in a hash in the order of the fields.
+func (t *TxStructure) HIterate(key []byte, fn func(field []byte, value []byte) error) error {
+	return errors.Trace(t.iterateHash(key, fn))
+}
+
 // HClear removes the hash value of the key.
 func (t *TxStructure) HClear(key []byte) error {
 	metaKey := t.encodeHashMetaKey(key)
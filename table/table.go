@@ -67,6 +67,8 @@ var (
 	ErrInvalidRecordKey = terror.ClassTable.New(codeInvalidRecordKey, "invalid record key")
 	// ErrTruncateWrongValue returns for truncate wrong value for field.
 	ErrTruncateWrongValue = terror.ClassTable.New(codeTruncateWrongValue, "Incorrect value")
+	// ErrCheckConstraintViolated returns for the row which doesn't satisfy a CHECK constraint.
+	ErrCheckConstraintViolated = terror.ClassTable.New(codeCheckConstraintViolated, mysql.MySQLErrName[mysql.ErrCheckConstraintViolated])
 )
 
 // RecordIterFunc is used for low-level record iteration.
@@ -166,6 +168,8 @@ const (
 	codeDuplicateColumn    = 1110
 	codeNoDefaultValue     = 1364
 	codeTruncateWrongValue = 1366
+
+	codeCheckConstraintViolated = 3819
 )
 
 // Slice is used for table sorting.
@@ -186,6 +190,8 @@ func init() {
 		codeDuplicateColumn:    mysql.ErrFieldSpecifiedTwice,
 		codeNoDefaultValue:     mysql.ErrNoDefaultForField,
 		codeTruncateWrongValue: mysql.ErrTruncatedWrongValueForField,
+
+		codeCheckConstraintViolated: mysql.ErrCheckConstraintViolated,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassTable] = tableMySQLErrCodes
 }
@@ -0,0 +1,150 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tables
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/types"
+)
+
+// CheckConstraints checks the rows written to a table against its CHECK constraints.
+type CheckConstraints struct {
+	ctx   context.Context
+	infos []*model.ConstraintInfo
+	exprs []expression.Expression
+}
+
+// NewCheckConstraints builds the CHECK constraints of the table which must be
+// satisfied by the written rows, the constraints which are being added are
+// included. It returns nil if there are no such constraints.
+func NewCheckConstraints(ctx context.Context, tblInfo *model.TableInfo) (*CheckConstraints, error) {
+	var checks *CheckConstraints
+	for _, info := range tblInfo.Constraints {
+		if info.State == model.StateNone || info.State == model.StateDeleteOnly {
+			continue
+		}
+		expr, err := BuildCheckConstraintExpr(ctx, tblInfo, info)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if checks == nil {
+			checks = &CheckConstraints{ctx: ctx}
+		}
+		checks.infos = append(checks.infos, info)
+		checks.exprs = append(checks.exprs, expr)
+	}
+	return checks, nil
+}
+
+// BuildCheckConstraintExpr builds the expression of the CHECK constraint, the
+// columns in it are resolved by their offsets in the rows of the table.
+func BuildCheckConstraintExpr(ctx context.Context, tblInfo *model.TableInfo, info *model.ConstraintInfo) (expression.Expression, error) {
+	node, err := parseExpression(info.ExprString)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	expr, err := RewriteTableExpr(ctx, tblInfo, node)
+	return expr, errors.Trace(err)
+}
+
+// RewriteTableExpr rewrites the expression which refers to the public columns of the table,
+// the columns in it are resolved by their offsets in the rows of the table.
+func RewriteTableExpr(ctx context.Context, tblInfo *model.TableInfo, node ast.ExprNode) (expression.Expression, error) {
+	cols := make([]*model.ColumnInfo, 0, len(tblInfo.Columns))
+	for _, col := range tblInfo.Columns {
+		if col.State == model.StatePublic {
+			cols = append(cols, col)
+		}
+	}
+	schema := expression.NewSchema(expression.ColumnInfos2ColumnsWithDBName(model.CIStr{}, tblInfo.Name, cols)...)
+	expr, err := expression.RewriteAstExpr(ctx, node, schema)
+	return expr, errors.Trace(err)
+}
+
+// Check checks the row, the constraint is satisfied unless its expression is false.
+func (c *CheckConstraints) Check(row []types.Datum) error {
+	if c == nil {
+		return nil
+	}
+	for i, expr := range c.exprs {
+		if err := CheckConstraint(c.ctx, c.infos[i], expr, row); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// CheckConstraint checks the row against the expression of the CHECK constraint. The row is in
+// the session time zone, it's evaluated in the time zone of the constraint.
+func CheckConstraint(ctx context.Context, info *model.ConstraintInfo, expr expression.Expression, row []types.Datum) error {
+	loc, err := info.TimeZone.GetLocation()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	row, err = convertRowTimeZone(row, ctx.GetSessionVars().GetTimeZone(), loc)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	sc := ctx.GetSessionVars().StmtCtx
+	originLoc := sc.TimeZone
+	sc.TimeZone = loc
+	val, err := expr.Eval(types.DatumRow(row))
+	sc.TimeZone = originLoc
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if val.IsNull() {
+		return nil
+	}
+	ok, err := val.ToBool(ctx.GetSessionVars().StmtCtx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if ok == 0 {
+		return table.ErrCheckConstraintViolated.GenByArgs(info.Name.O)
+	}
+	return nil
+}
+
+// convertRowTimeZone returns the row whose timestamps are converted from one time zone to another,
+// the row is copied if it has timestamps.
+func convertRowTimeZone(row []types.Datum, from, to *time.Location) ([]types.Datum, error) {
+	if from == to {
+		return row, nil
+	}
+	var converted []types.Datum
+	for i := range row {
+		if row[i].Kind() != types.KindMysqlTime || row[i].GetMysqlTime().Type != mysql.TypeTimestamp {
+			continue
+		}
+		if converted == nil {
+			converted = append(make([]types.Datum, 0, len(row)), row...)
+		}
+		if err := convertTimestampTimeZone(&converted[i], from, to); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	if converted == nil {
+		return row, nil
+	}
+	return converted, nil
+}
@@ -19,12 +19,15 @@ import (
 	"io"
 
 	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/collate"
 )
 
 func encodeHandle(h int64) []byte {
@@ -157,6 +160,7 @@ func (c *index) GenIndexKey(indexedValues []types.Datum, h int64) (key []byte, d
 			}
 		}
 	}
+	indexedValues = c.collationKeys(indexedValues)
 
 	if c.buffer != nil {
 		key = c.buffer[:0]
@@ -174,6 +178,60 @@ func (c *index) GenIndexKey(indexedValues []types.Datum, h int64) (key []byte, d
 	return
 }
 
+// collationKeys converts the strings to the sort keys of their collation, so the equal strings
+// get the same index keys. The values are copied before they are converted.
+func (c *index) collationKeys(indexedValues []types.Datum) []types.Datum {
+	if !collate.NewCollationEnabled() {
+		return indexedValues
+	}
+	var keys []types.Datum
+	for i, v := range indexedValues {
+		if i >= len(c.idxInfo.Columns) {
+			break
+		}
+		tp := &c.tblInfo.Columns[c.idxInfo.Columns[i].Offset].FieldType
+		if !expression.IsCollationKeyNeeded(tp) || v.IsNull() {
+			continue
+		}
+		if keys == nil {
+			keys = append(keys, indexedValues...)
+		}
+		keys[i] = expression.CollationKeyDatum(v, tp)
+	}
+	if keys == nil {
+		return indexedValues
+	}
+	return keys
+}
+
+// tailSpaces returns the numbers of the trailing spaces of the values whose index keys are the keys of
+// a padding _bin collation, so the values can be read from the index. It returns nil if no spaces are trimmed.
+func (c *index) tailSpaces(indexedValues []types.Datum) []uint64 {
+	if !collate.NewCollationEnabled() {
+		return nil
+	}
+	var spaces []uint64
+	for i, v := range indexedValues {
+		if i >= len(c.idxInfo.Columns) {
+			break
+		}
+		tp := &c.tblInfo.Columns[c.idxInfo.Columns[i].Offset].FieldType
+		if !expression.IsPaddingCollationKey(tp) || (v.Kind() != types.KindString && v.Kind() != types.KindBytes) {
+			continue
+		}
+		str := v.GetBytes()
+		n := len(str) - len(bytes.TrimRight(str, " "))
+		if n == 0 {
+			continue
+		}
+		if spaces == nil {
+			spaces = make([]uint64, len(c.idxInfo.Columns))
+		}
+		spaces[i] = uint64(n)
+	}
+	return spaces
+}
+
 // Create creates a new entry in the kvIndex data.
 // If the index is unique and there is an existing entry with the same key,
 // Create will return the existing entry's handle as the first return value, ErrKeyExists as the second return value.
@@ -182,15 +240,17 @@ func (c *index) Create(rm kv.RetrieverMutator, indexedValues []types.Datum, h in
 	if err != nil {
 		return 0, errors.Trace(err)
 	}
+	// The values are cut to the prefix length by GenIndexKey.
+	tailSpaces := c.tailSpaces(indexedValues)
 	if !distinct {
 		// non-unique index doesn't need store value, write a '0' to reduce space
-		err = rm.Set(key, []byte{'0'})
+		err = rm.Set(key, tablecodec.EncodeIndexTailSpaces([]byte{'0'}, tailSpaces))
 		return 0, errors.Trace(err)
 	}
 
 	value, err := rm.Get(key)
 	if kv.IsErrNotFound(err) {
-		err = rm.Set(key, encodeHandle(h))
+		err = rm.Set(key, tablecodec.EncodeIndexTailSpaces(encodeHandle(h), tailSpaces))
 		return 0, errors.Trace(err)
 	}
 	handle, err := decodeHandle(value)
@@ -304,3 +364,26 @@ func (c *index) FetchValues(r []types.Datum) ([]types.Datum, error) {
 	}
 	return vals, nil
 }
+
+// BuildIndexGenExprs builds the expressions of the virtual generated columns in the index, keyed by
+// the column IDs. The columns aren't stored, so their values are computed from the columns they
+// depend on, whose values are indexed by the column offsets. It returns nil if there are no such columns.
+func BuildIndexGenExprs(ctx context.Context, t table.Table, indexInfo *model.IndexInfo) (map[int64]expression.Expression, error) {
+	var genExprs map[int64]expression.Expression
+	cols := t.WritableCols()
+	for _, v := range indexInfo.Columns {
+		col := cols[v.Offset]
+		if !col.IsGenerated() || col.GeneratedStored {
+			continue
+		}
+		expr, err := RewriteTableExpr(ctx, t.Meta(), col.GeneratedExpr)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if genExprs == nil {
+			genExprs = make(map[int64]expression.Expression)
+		}
+		genExprs[col.ID] = expr
+	}
+	return genExprs, nil
+}
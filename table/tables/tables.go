@@ -20,10 +20,12 @@ package tables
 import (
 	"math"
 	"strings"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/meta/autoid"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
@@ -214,8 +216,13 @@ func (t *Table) UpdateRecord(ctx context.Context, h int64, oldData, newData []ty
 	txn := ctx.Txn()
 	bs := kv.NewBufferStore(txn)
 
+	err := t.fillChangingColumns(ctx, newData, touched)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
 	// rebuild index
-	err := t.rebuildIndices(bs, h, touched, oldData, newData)
+	err = t.rebuildIndices(bs, h, touched, oldData, newData)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -232,7 +239,7 @@ func (t *Table) UpdateRecord(ctx context.Context, h int64, oldData, newData []ty
 
 	for _, col := range t.WritableCols() {
 		var value types.Datum
-		if col.State != model.StatePublic {
+		if col.State != model.StatePublic && col.ChangeStateInfo == nil {
 			// If col is in write only or write reorganization state we should keep the oldData.
 			// Because the oldData must be the orignal data(it's changed by other TiDBs.) or the orignal default value.
 			// TODO: Use newData directly.
@@ -271,8 +278,98 @@ func (t *Table) UpdateRecord(ctx context.Context, h int64, oldData, newData []ty
 	return nil
 }
 
+// fillChangingColumns sets the values of the changing columns in newData, which
+// are converted from the values of the columns they depend on.
+func (t *Table) fillChangingColumns(ctx context.Context, newData []types.Datum, touched []bool) error {
+	for _, col := range t.WritableCols() {
+		if col == nil || col.ChangeStateInfo == nil {
+			continue
+		}
+		offset := col.ChangeStateInfo.DependencyColumnOffset
+		if !touched[offset] {
+			continue
+		}
+		value, err := t.castChangingColumn(ctx, col, newData[offset])
+		if err != nil {
+			return errors.Trace(err)
+		}
+		newData[col.Offset] = value
+		touched[col.Offset] = true
+	}
+	return nil
+}
+
+// castChangingColumn converts the value of the column which the changing column col depends on
+// in the time zone of the DDL job which changes the column, as the job does when it backfills col.
+// If the value can't be converted, the job is cancelled instead of failing the statement, and the
+// value of col is null, which is removed with col when the job is rolled back.
+func (t *Table) castChangingColumn(ctx context.Context, col *table.Column, val types.Datum) (types.Datum, error) {
+	jobLoc, err := col.ChangeStateInfo.TimeZone.GetLocation()
+	if err != nil {
+		return types.Datum{}, errors.Trace(err)
+	}
+	sessLoc := ctx.GetSessionVars().GetTimeZone()
+	if err = convertTimestampTimeZone(&val, sessLoc, jobLoc); err != nil {
+		return types.Datum{}, errors.Trace(err)
+	}
+	sc := ctx.GetSessionVars().StmtCtx
+	originLoc := sc.TimeZone
+	sc.TimeZone = jobLoc
+	casted, err := table.CastValue(ctx, val, col.ToInfo())
+	sc.TimeZone = originLoc
+	if err == nil {
+		// The row is encoded in the session time zone.
+		err = convertTimestampTimeZone(&casted, jobLoc, sessLoc)
+		return casted, errors.Trace(err)
+	}
+	log.Warnf("[tables] convert the value of the changing column %s of table %s err %v, cancel the DDL job",
+		col.Name, t.Name, err)
+	return types.Datum{}, errors.Trace(cancelModifyColumnJob(ctx.Txn(), t.ID))
+}
+
+func convertTimestampTimeZone(d *types.Datum, from, to *time.Location) error {
+	if d.Kind() != types.KindMysqlTime || d.GetMysqlTime().Type != mysql.TypeTimestamp || from == to {
+		return nil
+	}
+	t := d.GetMysqlTime()
+	if err := t.ConvertTimeZone(from, to); err != nil {
+		return errors.Trace(err)
+	}
+	d.SetMysqlTime(t)
+	return nil
+}
+
+// cancelModifyColumnJob cancels the running DDL job which modifies a column of the table in txn,
+// like ADMIN CANCEL DDL JOBS does. The running job is the first one in the DDL job queue.
+func cancelModifyColumnJob(txn kv.Transaction, tableID int64) error {
+	// The job queue exists, it can't be read as a key which is presumed not to exist by INSERT.
+	txn.DelOption(kv.PresumeKeyNotExists)
+	t := meta.NewMeta(txn)
+	job, err := t.GetDDLJob(0)
+	if err != nil || job == nil {
+		return errors.Trace(err)
+	}
+	if job.TableID != tableID || (job.Type != model.ActionModifyColumn && job.Type != model.ActionMultiSchemaChange) {
+		return nil
+	}
+	if job.IsDone() || job.IsSynced() || job.IsCancelled() || job.IsRollingback() || job.IsCancelling() {
+		return nil
+	}
+	job.State = model.JobStateCancelling
+	// Make sure RawArgs isn't overwritten.
+	if err = job.DecodeArgs(job.RawArgs); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(t.UpdateDDLJob(0, job))
+}
+
 func (t *Table) rebuildIndices(rm kv.RetrieverMutator, h int64, touched []bool, oldData []types.Datum, newData []types.Datum) error {
 	for _, idx := range t.DeletableIndices() {
+		if !coveredByRow(idx, touched) {
+			// The index covers a column in delete only state, which isn't in the row.
+			// No one writes the index, and it's going to be removed with its data.
+			continue
+		}
 		for _, ic := range idx.Meta().Columns {
 			if !touched[ic.Offset] {
 				continue
@@ -305,6 +402,42 @@ func (t *Table) rebuildIndices(rm kv.RetrieverMutator, h int64, touched []bool,
 	return nil
 }
 
+func coveredByRow(idx table.Index, touched []bool) bool {
+	for _, ic := range idx.Meta().Columns {
+		if ic.Offset >= len(touched) {
+			return false
+		}
+	}
+	return true
+}
+
+// writableRow returns the row with the values of all the writable columns. The
+// non-public columns take their original default values, except the changing
+// columns, which take the values converted from the columns they depend on.
+func (t *Table) writableRow(ctx context.Context, r []types.Datum) ([]types.Datum, error) {
+	cols := t.WritableCols()
+	if len(cols) == len(t.Cols()) {
+		return r, nil
+	}
+	row := make([]types.Datum, len(cols))
+	copy(row, r)
+	for _, col := range cols {
+		if col == nil || col.State == model.StatePublic {
+			continue
+		}
+		var err error
+		if col.ChangeStateInfo != nil {
+			row[col.Offset], err = t.castChangingColumn(ctx, col, r[col.ChangeStateInfo.DependencyColumnOffset])
+		} else {
+			row[col.Offset], err = table.GetColOriginDefaultValue(ctx, col.ToInfo())
+		}
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return row, nil
+}
+
 // AddRecord implements table.Table AddRecord interface.
 func (t *Table) AddRecord(ctx context.Context, r []types.Datum, skipHandleCheck bool) (recordID int64, err error) {
 	var hasRecordID bool
@@ -322,6 +455,11 @@ func (t *Table) AddRecord(ctx context.Context, r []types.Datum, skipHandleCheck
 		}
 	}
 
+	r, err = t.writableRow(ctx, r)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+
 	txn := ctx.Txn()
 	bs := kv.NewBufferStore(txn)
 
@@ -342,16 +480,7 @@ func (t *Table) AddRecord(ctx context.Context, r []types.Datum, skipHandleCheck
 	row = make([]types.Datum, 0, len(r))
 
 	for _, col := range t.WritableCols() {
-		var value types.Datum
-		if col.State != model.StatePublic {
-			// If col is in write only or write reorganization state, we must add it with its default value.
-			value, err = table.GetColOriginDefaultValue(ctx, col.ToInfo())
-			if err != nil {
-				return 0, errors.Trace(err)
-			}
-		} else {
-			value = r[col.Offset]
-		}
+		value := r[col.Offset]
 		if !t.canSkip(col, value) {
 			colIDs = append(colIDs, col.ID)
 			row = append(row, value)
@@ -499,11 +628,15 @@ func (t *Table) Row(ctx context.Context, h int64) ([]types.Datum, error) {
 
 // RemoveRecord implements table.Table RemoveRecord interface.
 func (t *Table) RemoveRecord(ctx context.Context, h int64, r []types.Datum) error {
-	err := t.removeRowData(ctx, h)
+	idxRow, err := t.rowForDeletableIndices(ctx, h, r)
 	if err != nil {
 		return errors.Trace(err)
 	}
-	err = t.removeRowIndices(ctx, h, r)
+	err = t.removeRowData(ctx, h)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	err = t.removeRowIndices(ctx, h, idxRow)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -517,6 +650,57 @@ func (t *Table) RemoveRecord(ctx context.Context, h int64, r []types.Datum) erro
 	return errors.Trace(err)
 }
 
+// rowForDeletableIndices returns the row used to remove the index entries. If
+// some index covers the non-public columns which aren't in r, their values are
+// read from the stored row.
+func (t *Table) rowForDeletableIndices(ctx context.Context, h int64, r []types.Datum) ([]types.Datum, error) {
+	needed := false
+	for _, idx := range t.DeletableIndices() {
+		for _, ic := range idx.Meta().Columns {
+			if ic.Offset >= len(r) {
+				needed = true
+			}
+		}
+	}
+	if !needed {
+		return r, nil
+	}
+
+	value, err := ctx.Txn().Get(t.RecordKey(h))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	colTps := make(map[int64]*types.FieldType)
+	for _, col := range t.Columns {
+		if col.Offset >= len(r) {
+			colTps[col.ID] = &col.FieldType
+		}
+	}
+	rowMap, err := tablecodec.DecodeRow(value, colTps, ctx.GetSessionVars().GetTimeZone())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	row := make([]types.Datum, len(t.Columns))
+	copy(row, r)
+	for _, col := range t.Columns {
+		if col.Offset < len(r) {
+			continue
+		}
+		if v, ok := rowMap[col.ID]; ok {
+			row[col.Offset] = v
+			continue
+		}
+		// A changing column is null in the rows which aren't backfilled, they have no index entries of it.
+		if col.ChangeStateInfo == nil {
+			row[col.Offset], err = table.GetColOriginDefaultValue(ctx, col.ToInfo())
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+	}
+	return row, nil
+}
+
 func (t *Table) addInsertBinlog(ctx context.Context, h int64, row []types.Datum, colIDs []int64) error {
 	mutation := t.getMutation(ctx)
 	pk, err := codec.EncodeValue(nil, types.NewIntDatum(h))
@@ -352,7 +352,7 @@ func unflatten(datum types.Datum, ft *types.FieldType, loc *time.Location) (type
 	case mysql.TypeTiny, mysql.TypeShort, mysql.TypeYear, mysql.TypeInt24,
 		mysql.TypeLong, mysql.TypeLonglong, mysql.TypeDouble, mysql.TypeTinyBlob,
 		mysql.TypeMediumBlob, mysql.TypeBlob, mysql.TypeLongBlob, mysql.TypeVarchar,
-		mysql.TypeString:
+		mysql.TypeString, mysql.TypeGeometry:
 		return datum, nil
 	case mysql.TypeDate, mysql.TypeDatetime, mysql.TypeTimestamp:
 		var t types.Time
@@ -441,6 +441,44 @@ func CutIndexKeyNew(key kv.Key, length int) (values [][]byte, b []byte, err erro
 	return
 }
 
+// EncodeIndexTailSpaces appends the numbers of the trailing spaces of the index column values to the index value.
+// The index keys of the padding _bin collations are the strings without the trailing spaces,
+// the spaces are restored by RestoreIndexTailSpaces when the values are read from the index.
+func EncodeIndexTailSpaces(b []byte, tailSpaces []uint64) []byte {
+	for _, n := range tailSpaces {
+		b = codec.EncodeUvarint(b, n)
+	}
+	return b
+}
+
+// RestoreIndexTailSpaces appends the trailing spaces encoded by EncodeIndexTailSpaces to the values cut from the index key.
+func RestoreIndexTailSpaces(values [][]byte, tailSpaces []byte) error {
+	for i := 0; i < len(values) && len(tailSpaces) > 0; i++ {
+		var (
+			n   uint64
+			d   types.Datum
+			err error
+		)
+		tailSpaces, n, err = codec.DecodeUvarint(tailSpaces)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if n == 0 {
+			continue
+		}
+		_, d, err = codec.DecodeOne(values[i])
+		if err != nil {
+			return errors.Trace(err)
+		}
+		d.SetBytes(append(d.GetBytes(), bytes.Repeat([]byte{' '}, int(n))...))
+		values[i], err = codec.EncodeKey(nil, d)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
 // EncodeTableIndexPrefix encodes index prefix with tableID and idxID.
 func EncodeTableIndexPrefix(tableID, idxID int64) kv.Key {
 	key := make([]byte, 0, prefixLen)
@@ -252,6 +252,23 @@ func (s *testTableCodecSuite) TestCutKeyNew(c *C) {
 	c.Assert(handleVal, DeepEquals, types.NewIntDatum(100))
 }
 
+func (s *testTableCodecSuite) TestIndexTailSpaces(c *C) {
+	values := []types.Datum{types.NewBytesDatum([]byte("abc")), types.NewIntDatum(1), types.NewBytesDatum([]byte("d"))}
+	encodedValue, err := codec.EncodeKey(nil, values...)
+	c.Assert(err, IsNil)
+	indexKey := EncodeIndexSeekKey(4, 5, encodedValue)
+	valuesBytes, _, err := CutIndexKeyNew(indexKey, 3)
+	c.Assert(err, IsNil)
+	value := EncodeIndexTailSpaces([]byte{'0'}, []uint64{2, 0, 1})
+	c.Assert(RestoreIndexTailSpaces(valuesBytes, value[1:]), IsNil)
+	expected := []types.Datum{types.NewBytesDatum([]byte("abc  ")), types.NewIntDatum(1), types.NewBytesDatum([]byte("d "))}
+	for i := range valuesBytes {
+		_, val, err := codec.DecodeOne(valuesBytes[i])
+		c.Assert(err, IsNil)
+		c.Assert(val, DeepEquals, expected[i])
+	}
+}
+
 func (s *testTableCodecSuite) TestCutKey(c *C) {
 	colIDs := []int64{1, 2, 3}
 	values := []types.Datum{types.NewIntDatum(1), types.NewBytesDatum([]byte("abc")), types.NewFloat64Datum(5.5)}
@@ -83,6 +83,7 @@ const (
 	ClassMockTikv
 	ClassJSON
 	ClassTiKV
+	ClassUDF
 	// Add more as needed.
 )
 
@@ -110,6 +111,7 @@ var errClz2Str = map[ErrClass]string{
 	ClassMockTikv:      "mocktikv",
 	ClassJSON:          "json",
 	ClassTiKV:          "tikv",
+	ClassUDF:           "udf",
 }
 
 // String implements fmt.Stringer interface.
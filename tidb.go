@@ -169,6 +169,9 @@ func runStmt(goCtx goctx.Context, ctx context.Context, s ast.Statement) (ast.Rec
 			log.Info("RollbackTxn for ddl/autocommit error.")
 			err1 := se.RollbackTxn(ctx1)
 			terror.Log(errors.Trace(err1))
+		} else if rs != nil && se.sessionVars.StmtCtx.CallsStoredFunction {
+			// The stored functions run their statements in the transaction when the rows are read.
+			rs = &autocommitRecordSet{RecordSet: rs, se: se}
 		} else {
 			err = se.CommitTxn(ctx1)
 		}
@@ -24,6 +24,7 @@ import (
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx/stmtctx"
+	"github.com/pingcap/tidb/types/geo"
 	"github.com/pingcap/tidb/types/json"
 	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/hack"
@@ -679,6 +680,8 @@ func (d *Datum) ConvertTo(sc *stmtctx.StatementContext, target *FieldType) (Datu
 		return d.convertToMysqlSet(sc, target)
 	case mysql.TypeJSON:
 		return d.convertToMysqlJSON(sc, target)
+	case mysql.TypeGeometry:
+		return d.convertToMysqlGeometry(sc, target)
 	case mysql.TypeNull:
 		return Datum{}, nil
 	default:
@@ -1248,6 +1251,21 @@ func (d *Datum) convertToMysqlJSON(sc *stmtctx.StatementContext, target *FieldTy
 	return ret, errors.Trace(err)
 }
 
+// convertToMysqlGeometry checks the datum holds a geometry in the internal format which
+// is allowed by the target spatial type, the geometry is kept as bytes.
+func (d *Datum) convertToMysqlGeometry(sc *stmtctx.StatementContext, target *FieldType) (Datum, error) {
+	var ret Datum
+	if d.k != KindString && d.k != KindBytes {
+		return ret, ErrCantCreateGeometryObject
+	}
+	g, err := geo.Decode(d.GetBytes())
+	if err != nil || (target.GeomType != geo.TypeGeometry && g.Type != target.GeomType) {
+		return ret, ErrCantCreateGeometryObject
+	}
+	ret.SetBytes(d.GetBytes())
+	return ret, nil
+}
+
 // ToBool converts to a bool.
 // We will use 1 for true, and 0 for false.
 func (d *Datum) ToBool(sc *stmtctx.StatementContext) (int64, error) {
@@ -49,6 +49,8 @@ var (
 	ErrInvalidDefault = terror.ClassTypes.New(codeInvalidDefault, "Invalid default value for '%s'")
 	// ErrMBiggerThanD is returned when precision less than the scale.
 	ErrMBiggerThanD = terror.ClassTypes.New(codeMBiggerThanD, mysql.MySQLErrName[mysql.ErrMBiggerThanD])
+	// ErrCantCreateGeometryObject is returned when the value stored into a spatial column isn't a geometry of its type.
+	ErrCantCreateGeometryObject = terror.ClassTypes.New(codeCantCreateGeometryObject, mysql.MySQLErrName[mysql.ErrCantCreateGeometryObject])
 )
 
 const (
@@ -67,6 +69,8 @@ const (
 	codeUnknown             terror.ErrCode = terror.ErrCode(mysql.ErrUnknown)
 	codeInvalidDefault      terror.ErrCode = terror.ErrCode(mysql.ErrInvalidDefault)
 	codeMBiggerThanD        terror.ErrCode = terror.ErrCode(mysql.ErrMBiggerThanD)
+
+	codeCantCreateGeometryObject terror.ErrCode = terror.ErrCode(mysql.ErrCantCreateGeometryObject)
 )
 
 var (
@@ -91,6 +95,8 @@ func init() {
 		codeUnknown:             mysql.ErrUnknown,
 		codeInvalidDefault:      mysql.ErrInvalidDefault,
 		codeMBiggerThanD:        mysql.ErrMBiggerThanD,
+
+		codeCantCreateGeometryObject: mysql.ErrCantCreateGeometryObject,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassTypes] = typesMySQLErrCodes
 }
@@ -20,6 +20,7 @@ import (
 	"strings"
 
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/types/geo"
 	"github.com/pingcap/tidb/types/json"
 	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/format"
@@ -40,6 +41,8 @@ type FieldType struct {
 	Collate string
 	// Elems is the element list for enum and set type.
 	Elems []string
+	// GeomType is the geometry type of a spatial column, it's geo.TypeGeometry if any geometry is allowed.
+	GeomType geo.Type `json:",omitempty"`
 }
 
 // NewFieldType returns a FieldType,
@@ -59,7 +62,8 @@ func (ft *FieldType) Equal(other *FieldType) bool {
 		ft.Flen == other.Flen &&
 		ft.Decimal == other.Decimal &&
 		ft.Charset == other.Charset &&
-		ft.Collate == other.Collate
+		ft.Collate == other.Collate &&
+		ft.GeomType == other.GeomType
 	if !partialEqual || len(ft.Elems) != len(other.Elems) {
 		return false
 	}
@@ -235,6 +239,8 @@ func (ft *FieldType) CompactStr() string {
 	case mysql.TypeBit, mysql.TypeShort, mysql.TypeTiny, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong, mysql.TypeVarchar, mysql.TypeString, mysql.TypeVarString:
 		// Flen is always shown.
 		suffix = fmt.Sprintf("(%d)", displayFlen)
+	case mysql.TypeGeometry:
+		ts = ft.GeomType.String()
 	}
 	return ts + suffix
 }
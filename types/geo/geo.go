@@ -0,0 +1,333 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package geo implements the geometry values of the spatial types.
+//
+// A geometry is stored the same way MySQL stores it: a 4-byte little-endian
+// SRID followed by the WKB (well-known binary) representation of the shape.
+// POINT, LINESTRING and POLYGON are supported, all relations are computed on
+// a Cartesian plane.
+package geo
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/juju/errors"
+)
+
+// Type is the type of a geometry, the values are the WKB type codes.
+type Type byte
+
+const (
+	// TypeGeometry stands for any geometry, it is only used for column types.
+	TypeGeometry Type = 0
+	// TypePoint is the type of POINT.
+	TypePoint Type = 1
+	// TypeLineString is the type of LINESTRING.
+	TypeLineString Type = 2
+	// TypePolygon is the type of POLYGON.
+	TypePolygon Type = 3
+)
+
+var typeNames = map[Type]string{
+	TypeGeometry:   "geometry",
+	TypePoint:      "point",
+	TypeLineString: "linestring",
+	TypePolygon:    "polygon",
+}
+
+// String implements fmt.Stringer interface.
+func (t Type) String() string {
+	return typeNames[t]
+}
+
+// ErrInvalidData is returned when the WKB, WKT or internal format can't be decoded into a geometry.
+var ErrInvalidData = errors.New("invalid GIS data")
+
+const (
+	sridLen    = 4
+	wkbHdrLen  = 5
+	pointLen   = 16
+	uint32Len  = 4
+	wkbXDR     = 0
+	wkbNDR     = 1
+	minRingLen = 4
+)
+
+// Point is a point on the plane.
+type Point struct {
+	X float64
+	Y float64
+}
+
+// Geometry is a geometry value with its spatial reference system identifier.
+type Geometry struct {
+	SRID uint32
+	Type Type
+	// Points holds the point of a POINT and the vertices of a LINESTRING.
+	Points []Point
+	// Rings holds the rings of a POLYGON, the first one is the exterior ring.
+	Rings [][]Point
+}
+
+// NewPoint creates a POINT.
+func NewPoint(srid uint32, x, y float64) Geometry {
+	return Geometry{SRID: srid, Type: TypePoint, Points: []Point{{X: x, Y: y}}}
+}
+
+// Decode decodes a geometry from the internal storage format.
+func Decode(data []byte) (Geometry, error) {
+	if len(data) < sridLen {
+		return Geometry{}, ErrInvalidData
+	}
+	return ParseWKB(data[sridLen:], binary.LittleEndian.Uint32(data))
+}
+
+// Encode encodes the geometry into the internal storage format.
+func (g Geometry) Encode() []byte {
+	buf := make([]byte, sridLen, sridLen+wkbHdrLen+g.wkbDataLen())
+	binary.LittleEndian.PutUint32(buf, g.SRID)
+	return g.appendWKB(buf)
+}
+
+// WKB returns the well-known binary representation of the geometry.
+func (g Geometry) WKB() []byte {
+	return g.appendWKB(make([]byte, 0, wkbHdrLen+g.wkbDataLen()))
+}
+
+func (g Geometry) wkbDataLen() int {
+	switch g.Type {
+	case TypePoint:
+		return pointLen
+	case TypeLineString:
+		return uint32Len + pointLen*len(g.Points)
+	}
+	n := uint32Len
+	for _, ring := range g.Rings {
+		n += uint32Len + pointLen*len(ring)
+	}
+	return n
+}
+
+func (g Geometry) appendWKB(buf []byte) []byte {
+	buf = append(buf, wkbNDR)
+	buf = appendUint32(buf, uint32(g.Type))
+	switch g.Type {
+	case TypePoint:
+		buf = appendPoint(buf, g.Points[0])
+	case TypeLineString:
+		buf = appendPoints(buf, g.Points)
+	case TypePolygon:
+		buf = appendUint32(buf, uint32(len(g.Rings)))
+		for _, ring := range g.Rings {
+			buf = appendPoints(buf, ring)
+		}
+	}
+	return buf
+}
+
+func appendUint32(buf []byte, v uint32) []byte {
+	var b [uint32Len]byte
+	binary.LittleEndian.PutUint32(b[:], v)
+	return append(buf, b[:]...)
+}
+
+func appendPoint(buf []byte, p Point) []byte {
+	var b [pointLen]byte
+	binary.LittleEndian.PutUint64(b[:], math.Float64bits(p.X))
+	binary.LittleEndian.PutUint64(b[8:], math.Float64bits(p.Y))
+	return append(buf, b[:]...)
+}
+
+func appendPoints(buf []byte, points []Point) []byte {
+	buf = appendUint32(buf, uint32(len(points)))
+	for _, p := range points {
+		buf = appendPoint(buf, p)
+	}
+	return buf
+}
+
+// ParseWKB parses a geometry from its well-known binary representation.
+func ParseWKB(data []byte, srid uint32) (Geometry, error) {
+	r := wkbReader{data: data}
+	g, err := r.readGeometry()
+	if err != nil {
+		return Geometry{}, errors.Trace(err)
+	}
+	if len(r.data) != 0 {
+		return Geometry{}, ErrInvalidData
+	}
+	g.SRID = srid
+	return g, errors.Trace(g.validate())
+}
+
+type wkbReader struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+func (r *wkbReader) readUint32() (uint32, error) {
+	if len(r.data) < uint32Len {
+		return 0, ErrInvalidData
+	}
+	v := r.order.Uint32(r.data)
+	r.data = r.data[uint32Len:]
+	return v, nil
+}
+
+func (r *wkbReader) readPoint() (Point, error) {
+	if len(r.data) < pointLen {
+		return Point{}, ErrInvalidData
+	}
+	p := Point{
+		X: math.Float64frombits(r.order.Uint64(r.data)),
+		Y: math.Float64frombits(r.order.Uint64(r.data[8:])),
+	}
+	r.data = r.data[pointLen:]
+	if math.IsNaN(p.X) || math.IsNaN(p.Y) || math.IsInf(p.X, 0) || math.IsInf(p.Y, 0) {
+		return Point{}, ErrInvalidData
+	}
+	return p, nil
+}
+
+func (r *wkbReader) readPoints() ([]Point, error) {
+	n, err := r.readUint32()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	// Each point takes 16 bytes, this guards the allocation against a corrupted count.
+	if uint64(n)*pointLen > uint64(len(r.data)) {
+		return nil, ErrInvalidData
+	}
+	points := make([]Point, 0, n)
+	for i := uint32(0); i < n; i++ {
+		p, err := r.readPoint()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		points = append(points, p)
+	}
+	return points, nil
+}
+
+func (r *wkbReader) readGeometry() (g Geometry, err error) {
+	if len(r.data) < wkbHdrLen {
+		return g, ErrInvalidData
+	}
+	switch r.data[0] {
+	case wkbXDR:
+		r.order = binary.BigEndian
+	case wkbNDR:
+		r.order = binary.LittleEndian
+	default:
+		return g, ErrInvalidData
+	}
+	r.data = r.data[1:]
+	tp, err := r.readUint32()
+	if err != nil {
+		return g, errors.Trace(err)
+	}
+	g.Type = Type(tp)
+	switch g.Type {
+	case TypePoint:
+		p, err := r.readPoint()
+		if err != nil {
+			return g, errors.Trace(err)
+		}
+		g.Points = []Point{p}
+	case TypeLineString:
+		g.Points, err = r.readPoints()
+		if err != nil {
+			return g, errors.Trace(err)
+		}
+	case TypePolygon:
+		n, err := r.readUint32()
+		if err != nil {
+			return g, errors.Trace(err)
+		}
+		if uint64(n)*uint32Len > uint64(len(r.data)) {
+			return g, ErrInvalidData
+		}
+		g.Rings = make([][]Point, 0, n)
+		for i := uint32(0); i < n; i++ {
+			ring, err := r.readPoints()
+			if err != nil {
+				return g, errors.Trace(err)
+			}
+			g.Rings = append(g.Rings, ring)
+		}
+	default:
+		return g, ErrInvalidData
+	}
+	return g, nil
+}
+
+// validate checks the shape is well formed: a line string has at least two
+// points, a polygon has at least one ring and every ring is closed.
+func (g Geometry) validate() error {
+	switch g.Type {
+	case TypePoint:
+		if len(g.Points) != 1 {
+			return ErrInvalidData
+		}
+	case TypeLineString:
+		if len(g.Points) < 2 {
+			return ErrInvalidData
+		}
+	case TypePolygon:
+		if len(g.Rings) == 0 {
+			return ErrInvalidData
+		}
+		for _, ring := range g.Rings {
+			if len(ring) < minRingLen || ring[0] != ring[len(ring)-1] {
+				return ErrInvalidData
+			}
+		}
+	default:
+		return ErrInvalidData
+	}
+	return nil
+}
+
+// MBR is the minimum bounding rectangle of a geometry.
+type MBR struct {
+	MinX, MinY, MaxX, MaxY float64
+}
+
+// Envelope returns the minimum bounding rectangle of the geometry.
+func (g Geometry) Envelope() MBR {
+	m := MBR{MinX: math.Inf(1), MinY: math.Inf(1), MaxX: math.Inf(-1), MaxY: math.Inf(-1)}
+	points := g.Points
+	if g.Type == TypePolygon {
+		// The holes are inside the exterior ring.
+		points = g.Rings[0]
+	}
+	for _, p := range points {
+		m.MinX = math.Min(m.MinX, p.X)
+		m.MinY = math.Min(m.MinY, p.Y)
+		m.MaxX = math.Max(m.MaxX, p.X)
+		m.MaxY = math.Max(m.MaxY, p.Y)
+	}
+	return m
+}
+
+// Contains checks whether the rectangle covers the other one, boundaries included.
+func (m MBR) Contains(other MBR) bool {
+	return m.MinX <= other.MinX && other.MaxX <= m.MaxX && m.MinY <= other.MinY && other.MaxY <= m.MaxY
+}
+
+// Intersects checks whether the two rectangles have any point in common.
+func (m MBR) Intersects(other MBR) bool {
+	return m.MinX <= other.MaxX && other.MinX <= m.MaxX && m.MinY <= other.MaxY && other.MinY <= m.MaxY
+}
@@ -0,0 +1,168 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geo
+
+import (
+	"encoding/hex"
+	"math"
+	"testing"
+
+	"github.com/juju/errors"
+	. "github.com/pingcap/check"
+)
+
+var _ = Suite(&testGeoSuite{})
+
+type testGeoSuite struct{}
+
+func TestT(t *testing.T) {
+	TestingT(t)
+}
+
+func mustParseWKT(c *C, s string) Geometry {
+	g, err := ParseWKT(s, 0)
+	c.Assert(err, IsNil, Commentf("%s", s))
+	return g
+}
+
+func (s *testGeoSuite) TestWKT(c *C) {
+	tbl := []struct {
+		in  string
+		out string
+	}{
+		{"POINT(1 2)", "POINT(1 2)"},
+		{" point ( -1.5  2e3 ) ", "POINT(-1.5 2000)"},
+		{"LineString(0 0, 1 1,2 0.25)", "LINESTRING(0 0,1 1,2 0.25)"},
+		{"POLYGON((0 0,0 3,3 3,3 0,0 0),(1 1,1 2,2 2,1 1))", "POLYGON((0 0,0 3,3 3,3 0,0 0),(1 1,1 2,2 2,1 1))"},
+	}
+	for _, t := range tbl {
+		g := mustParseWKT(c, t.in)
+		c.Assert(g.WKT(), Equals, t.out)
+	}
+
+	invalids := []string{
+		"", "POINT", "POINT()", "POINT(1)", "POINT(1 2 3)", "POINT(1 2", "POINT(1 2) x", "POINT(a b)",
+		"LINESTRING(0 0)", "POLYGON((0 0,1 1,0 0))", "POLYGON((0 0,0 1,1 1,1 0))", "POLYGON()",
+		"MULTIPOINT(0 0,1 1)", "CIRCLE(0 0)",
+	}
+	for _, in := range invalids {
+		_, err := ParseWKT(in, 0)
+		c.Assert(errors.Cause(err), Equals, ErrInvalidData, Commentf("%s", in))
+	}
+}
+
+func (s *testGeoSuite) TestWKBAndEncoding(c *C) {
+	g := NewPoint(0, 1, -1)
+	c.Assert(hex.EncodeToString(g.WKB()), Equals, "0101000000000000000000f03f000000000000f0bf")
+
+	// The same point in big endian.
+	bigEndian, err := hex.DecodeString("00000000013ff0000000000000bff0000000000000")
+	c.Assert(err, IsNil)
+	g1, err := ParseWKB(bigEndian, 0)
+	c.Assert(err, IsNil)
+	c.Assert(g1, DeepEquals, g)
+
+	for _, wkt := range []string{"POINT(1 2)", "LINESTRING(0 0,1 1)", "POLYGON((0 0,0 3,3 3,3 0,0 0),(1 1,1 2,2 2,1 1))"} {
+		g = mustParseWKT(c, wkt)
+		g.SRID = 4326
+		data := g.Encode()
+		c.Assert(len(data), Equals, sridLen+wkbHdrLen+g.wkbDataLen())
+		g1, err = Decode(data)
+		c.Assert(err, IsNil)
+		c.Assert(g1, DeepEquals, g)
+		c.Assert(g1.WKT(), Equals, wkt)
+
+		// Truncated data must not be decoded.
+		_, err = Decode(data[:len(data)-1])
+		c.Assert(err, NotNil)
+		_, err = Decode(append(data, 0))
+		c.Assert(err, NotNil)
+	}
+	_, err = Decode([]byte{1, 2})
+	c.Assert(err, NotNil)
+	_, err = Decode([]byte("\x00\x00\x00\x00\x01\x09\x00\x00\x00"))
+	c.Assert(err, NotNil)
+	// A line string claiming to have a huge number of points.
+	_, err = Decode([]byte("\x00\x00\x00\x00\x01\x02\x00\x00\x00\xff\xff\xff\xff"))
+	c.Assert(err, NotNil)
+}
+
+func (s *testGeoSuite) TestRelations(c *C) {
+	square := "POLYGON((0 0,0 4,4 4,4 0,0 0))"
+	donut := "POLYGON((0 0,0 4,4 4,4 0,0 0),(1 1,1 3,3 3,3 1,1 1))"
+	tbl := []struct {
+		a, b     string
+		contains bool
+	}{
+		{square, "POINT(1 1)", true},
+		{square, "POINT(0 1)", false},
+		{square, "POINT(5 1)", false},
+		{donut, "POINT(2 2)", false},
+		{donut, "POINT(0.5 0.5)", true},
+		{square, "LINESTRING(0 0,4 4)", true},
+		{square, "LINESTRING(0 0,4 0)", false},
+		{square, "LINESTRING(1 1,5 1)", false},
+		{donut, "LINESTRING(0.5 0.5,3.5 3.5)", false},
+		{square, "POLYGON((1 1,1 2,2 2,1 1))", true},
+		{square, square, true},
+		{donut, "POLYGON((0.5 0.5,0.5 3.5,3.5 3.5,3.5 0.5,0.5 0.5))", false},
+		{"POLYGON((0 0,0 4,2 2,4 4,4 0,0 0))", "POLYGON((0 3,4 3,4 3.5,0 3.5,0 3))", false},
+		{"LINESTRING(0 0,2 2)", "POINT(1 1)", true},
+		{"LINESTRING(0 0,2 2)", "POINT(0 0)", false},
+		{"LINESTRING(0 0,2 2,4 0)", "LINESTRING(1 1,2 2,3 1)", true},
+		{"LINESTRING(0 0,2 2)", "LINESTRING(0 0,2 0)", false},
+		{"POINT(1 1)", "POINT(1 1)", true},
+		{"POINT(1 1)", "POINT(1 2)", false},
+		{"POINT(1 1)", square, false},
+	}
+	for _, t := range tbl {
+		a, b := mustParseWKT(c, t.a), mustParseWKT(c, t.b)
+		c.Assert(Contains(a, b), Equals, t.contains, Commentf("%s contains %s", t.a, t.b))
+		c.Assert(Within(b, a), Equals, t.contains, Commentf("%s within %s", t.b, t.a))
+	}
+}
+
+func (s *testGeoSuite) TestDistance(c *C) {
+	tbl := []struct {
+		a, b     string
+		distance float64
+	}{
+		{"POINT(0 0)", "POINT(3 4)", 5},
+		{"POINT(0 0)", "LINESTRING(1 -1,1 1)", 1},
+		{"POINT(2 2)", "LINESTRING(0 0,1 1)", math.Sqrt2},
+		{"LINESTRING(0 0,2 2)", "LINESTRING(0 2,2 0)", 0},
+		{"LINESTRING(0 0,1 0)", "LINESTRING(0 2,1 3)", 2},
+		{"POLYGON((0 0,0 4,4 4,4 0,0 0))", "POINT(2 2)", 0},
+		{"POLYGON((0 0,0 4,4 4,4 0,0 0))", "POINT(7 8)", 5},
+		{"POLYGON((0 0,0 4,4 4,4 0,0 0),(1 1,1 3,3 3,3 1,1 1))", "POINT(2 2.5)", 0.5},
+		{"POLYGON((0 0,0 1,1 1,1 0,0 0))", "POLYGON((3 0,3 1,4 1,4 0,3 0))", 2},
+		{"POLYGON((0 0,0 4,4 4,4 0,0 0))", "POLYGON((1 1,1 2,2 2,1 1))", 0},
+	}
+	for _, t := range tbl {
+		a, b := mustParseWKT(c, t.a), mustParseWKT(c, t.b)
+		c.Assert(Distance(a, b), Equals, t.distance, Commentf("%s, %s", t.a, t.b))
+		c.Assert(Distance(b, a), Equals, t.distance, Commentf("%s, %s", t.b, t.a))
+	}
+}
+
+func (s *testGeoSuite) TestEnvelope(c *C) {
+	g := mustParseWKT(c, "POLYGON((0 0,0 4,4 4,4 0,0 0),(1 1,1 3,3 3,3 1,1 1))")
+	c.Assert(g.Envelope(), Equals, MBR{MinX: 0, MinY: 0, MaxX: 4, MaxY: 4})
+	p := mustParseWKT(c, "POINT(4 2)")
+	c.Assert(g.Envelope().Contains(p.Envelope()), IsTrue)
+	c.Assert(p.Envelope().Contains(g.Envelope()), IsFalse)
+	c.Assert(p.Envelope().Intersects(g.Envelope()), IsTrue)
+	l := mustParseWKT(c, "LINESTRING(5 5,6 7)")
+	c.Assert(l.Envelope().Intersects(g.Envelope()), IsFalse)
+}
@@ -0,0 +1,294 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geo
+
+import (
+	"math"
+)
+
+// The relations below are evaluated in two steps: the minimum bounding
+// rectangles filter out the pairs which can't match, and only the candidates
+// left are refined with the exact computation. There are no spatial indexes,
+// the rows are only filtered before the evaluation by the expression indexes
+// on the coordinates of the points.
+
+// Contains checks whether no point of b lies in the exterior of a, and at
+// least one point of the interior of b lies in the interior of a.
+func Contains(a, b Geometry) bool {
+	if !a.Envelope().Contains(b.Envelope()) {
+		return false
+	}
+	switch a.Type {
+	case TypePoint:
+		return b.Type == TypePoint && a.Points[0] == b.Points[0]
+	case TypeLineString:
+		return lineStringContains(a, b)
+	case TypePolygon:
+		return polygonContains(a, b)
+	}
+	return false
+}
+
+// Within checks whether a is within b, it's the inverse of Contains.
+func Within(a, b Geometry) bool {
+	return Contains(b, a)
+}
+
+// Intersects checks whether a and b have at least one point in common.
+func Intersects(a, b Geometry) bool {
+	if !a.Envelope().Intersects(b.Envelope()) {
+		return false
+	}
+	aEdges, bEdges := a.edges(), b.edges()
+	for _, ea := range aEdges {
+		for _, eb := range bEdges {
+			if segmentsIntersect(ea[0], ea[1], eb[0], eb[1]) {
+				return true
+			}
+		}
+	}
+	// No boundary intersects, so one of them is either entirely inside a polygon or outside of it.
+	if a.Type == TypePolygon && locate(b.vertices()[0], a.Rings) != exterior {
+		return true
+	}
+	if b.Type == TypePolygon && locate(a.vertices()[0], b.Rings) != exterior {
+		return true
+	}
+	return false
+}
+
+// Distance returns the minimum Cartesian distance between a and b.
+func Distance(a, b Geometry) float64 {
+	if Intersects(a, b) {
+		return 0
+	}
+	// The shapes are disjoint, so the shortest path starts from a vertex of one of them.
+	d := math.Inf(1)
+	aEdges, bEdges := a.edges(), b.edges()
+	for _, p := range a.vertices() {
+		for _, e := range bEdges {
+			d = math.Min(d, pointSegmentDistance(p, e[0], e[1]))
+		}
+	}
+	for _, p := range b.vertices() {
+		for _, e := range aEdges {
+			d = math.Min(d, pointSegmentDistance(p, e[0], e[1]))
+		}
+	}
+	return d
+}
+
+func lineStringContains(a, b Geometry) bool {
+	if b.Type == TypePolygon {
+		return false
+	}
+	for _, p := range b.Points {
+		if !onLineString(p, a.Points) {
+			return false
+		}
+	}
+	if b.Type == TypePoint {
+		// The end points of an open line string are its boundary.
+		first, last := a.Points[0], a.Points[len(a.Points)-1]
+		return first == last || (b.Points[0] != first && b.Points[0] != last)
+	}
+	for i := 1; i < len(b.Points); i++ {
+		if !onLineString(midPoint(b.Points[i-1], b.Points[i]), a.Points) {
+			return false
+		}
+	}
+	return true
+}
+
+func polygonContains(a, b Geometry) bool {
+	if b.Type == TypePoint {
+		return locate(b.Points[0], a.Rings) == interior
+	}
+	hasInterior := b.Type == TypePolygon
+	for _, p := range b.vertices() {
+		switch locate(p, a.Rings) {
+		case exterior:
+			return false
+		case interior:
+			hasInterior = true
+		}
+	}
+	aEdges := a.edges()
+	for _, eb := range b.edges() {
+		for _, ea := range aEdges {
+			if segmentsCross(eb[0], eb[1], ea[0], ea[1]) {
+				return false
+			}
+		}
+		switch locate(midPoint(eb[0], eb[1]), a.Rings) {
+		case exterior:
+			return false
+		case interior:
+			hasInterior = true
+		}
+	}
+	if b.Type == TypePolygon {
+		// A hole of a lying inside of b is a part of b outside of a.
+		for _, hole := range a.Rings[1:] {
+			for _, p := range hole {
+				if locate(p, b.Rings) == interior {
+					return false
+				}
+			}
+		}
+	}
+	return hasInterior
+}
+
+func (g Geometry) vertices() []Point {
+	if g.Type != TypePolygon {
+		return g.Points
+	}
+	var points []Point
+	for _, ring := range g.Rings {
+		points = append(points, ring...)
+	}
+	return points
+}
+
+// edges returns the segments of the geometry, a point is a degenerate segment.
+func (g Geometry) edges() [][2]Point {
+	switch g.Type {
+	case TypePoint:
+		return [][2]Point{{g.Points[0], g.Points[0]}}
+	case TypeLineString:
+		return appendEdges(nil, g.Points)
+	}
+	var edges [][2]Point
+	for _, ring := range g.Rings {
+		edges = appendEdges(edges, ring)
+	}
+	return edges
+}
+
+func appendEdges(edges [][2]Point, points []Point) [][2]Point {
+	for i := 1; i < len(points); i++ {
+		edges = append(edges, [2]Point{points[i-1], points[i]})
+	}
+	return edges
+}
+
+type location int
+
+const (
+	exterior location = iota
+	boundary
+	interior
+)
+
+// locate finds where p is relative to the polygon made up of rings.
+func locate(p Point, rings [][]Point) location {
+	for i, ring := range rings {
+		switch locateInRing(p, ring) {
+		case boundary:
+			return boundary
+		case exterior:
+			if i == 0 {
+				return exterior
+			}
+		case interior:
+			if i > 0 {
+				// p is inside a hole.
+				return exterior
+			}
+		}
+	}
+	return interior
+}
+
+func locateInRing(p Point, ring []Point) location {
+	inside := false
+	for i := 1; i < len(ring); i++ {
+		a, b := ring[i-1], ring[i]
+		if onSegment(p, a, b) {
+			return boundary
+		}
+		// Cast a ray to the right of p and count the edges it crosses.
+		if (a.Y > p.Y) != (b.Y > p.Y) && p.X < (b.X-a.X)*(p.Y-a.Y)/(b.Y-a.Y)+a.X {
+			inside = !inside
+		}
+	}
+	if inside {
+		return interior
+	}
+	return exterior
+}
+
+func onLineString(p Point, points []Point) bool {
+	for i := 1; i < len(points); i++ {
+		if onSegment(p, points[i-1], points[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+func midPoint(a, b Point) Point {
+	return Point{X: (a.X + b.X) / 2, Y: (a.Y + b.Y) / 2}
+}
+
+// cross returns the cross product of the vectors (b - a) and (c - a).
+func cross(a, b, c Point) float64 {
+	return (b.X-a.X)*(c.Y-a.Y) - (b.Y-a.Y)*(c.X-a.X)
+}
+
+func sign(f float64) int {
+	switch {
+	case f > 0:
+		return 1
+	case f < 0:
+		return -1
+	}
+	return 0
+}
+
+// onSegment checks whether p lies on the segment ab.
+func onSegment(p, a, b Point) bool {
+	return cross(a, b, p) == 0 &&
+		math.Min(a.X, b.X) <= p.X && p.X <= math.Max(a.X, b.X) &&
+		math.Min(a.Y, b.Y) <= p.Y && p.Y <= math.Max(a.Y, b.Y)
+}
+
+// segmentsIntersect checks whether the segments ab and cd have any point in common.
+func segmentsIntersect(a, b, c, d Point) bool {
+	d1, d2 := sign(cross(c, d, a)), sign(cross(c, d, b))
+	d3, d4 := sign(cross(a, b, c)), sign(cross(a, b, d))
+	if d1*d2 < 0 && d3*d4 < 0 {
+		return true
+	}
+	return onSegment(a, c, d) || onSegment(b, c, d) || onSegment(c, a, b) || onSegment(d, a, b)
+}
+
+// segmentsCross checks whether the segments ab and cd cross each other at a
+// single point which isn't an end point of either of them.
+func segmentsCross(a, b, c, d Point) bool {
+	d1, d2 := sign(cross(c, d, a)), sign(cross(c, d, b))
+	d3, d4 := sign(cross(a, b, c)), sign(cross(a, b, d))
+	return d1*d2 < 0 && d3*d4 < 0
+}
+
+func pointSegmentDistance(p, a, b Point) float64 {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	if dx == 0 && dy == 0 {
+		return math.Hypot(p.X-a.X, p.Y-a.Y)
+	}
+	t := ((p.X-a.X)*dx + (p.Y-a.Y)*dy) / (dx*dx + dy*dy)
+	t = math.Max(0, math.Min(1, t))
+	return math.Hypot(p.X-a.X-t*dx, p.Y-a.Y-t*dy)
+}
@@ -0,0 +1,189 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geo
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// ParseWKT parses a geometry from its well-known text representation, e.g. "POINT(1 2)".
+func ParseWKT(s string, srid uint32) (Geometry, error) {
+	p := wktParser{s: s}
+	name := strings.ToLower(p.readWord())
+	var (
+		g   Geometry
+		err error
+	)
+	switch name {
+	case "point":
+		g.Type = TypePoint
+		var pt Point
+		if err = p.expect('('); err == nil {
+			pt, err = p.readPoint()
+		}
+		if err == nil {
+			err = p.expect(')')
+		}
+		g.Points = []Point{pt}
+	case "linestring":
+		g.Type = TypeLineString
+		g.Points, err = p.readPointList()
+	case "polygon":
+		g.Type = TypePolygon
+		err = p.expect('(')
+		for err == nil {
+			var ring []Point
+			ring, err = p.readPointList()
+			if err != nil {
+				break
+			}
+			g.Rings = append(g.Rings, ring)
+			if !p.skip(',') {
+				err = p.expect(')')
+				break
+			}
+		}
+	default:
+		return g, ErrInvalidData
+	}
+	if err != nil {
+		return Geometry{}, errors.Trace(err)
+	}
+	if p.skipSpaces(); p.pos != len(p.s) {
+		return Geometry{}, ErrInvalidData
+	}
+	g.SRID = srid
+	return g, errors.Trace(g.validate())
+}
+
+type wktParser struct {
+	s   string
+	pos int
+}
+
+func (p *wktParser) skipSpaces() {
+	for p.pos < len(p.s) && strings.IndexByte(" \t\r\n", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+func (p *wktParser) skip(c byte) bool {
+	p.skipSpaces()
+	if p.pos < len(p.s) && p.s[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *wktParser) expect(c byte) error {
+	if !p.skip(c) {
+		return ErrInvalidData
+	}
+	return nil
+}
+
+func (p *wktParser) readWord() string {
+	p.skipSpaces()
+	start := p.pos
+	for p.pos < len(p.s) && (p.s[p.pos] >= 'a' && p.s[p.pos] <= 'z' || p.s[p.pos] >= 'A' && p.s[p.pos] <= 'Z') {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+func (p *wktParser) readNumber() (float64, error) {
+	p.skipSpaces()
+	start := p.pos
+	for p.pos < len(p.s) && strings.IndexByte("+-.0123456789eE", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+	f, err := strconv.ParseFloat(p.s[start:p.pos], 64)
+	if err != nil {
+		return 0, ErrInvalidData
+	}
+	return f, nil
+}
+
+func (p *wktParser) readPoint() (pt Point, err error) {
+	if pt.X, err = p.readNumber(); err != nil {
+		return
+	}
+	pt.Y, err = p.readNumber()
+	return
+}
+
+// readPointList reads a parenthesized, comma separated point list like "(0 0, 1 1)".
+func (p *wktParser) readPointList() ([]Point, error) {
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+	var points []Point
+	for {
+		pt, err := p.readPoint()
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, pt)
+		if !p.skip(',') {
+			break
+		}
+	}
+	return points, p.expect(')')
+}
+
+// WKT returns the well-known text representation of the geometry, e.g. "POLYGON((0 0,0 1,1 1,0 0))".
+func (g Geometry) WKT() string {
+	var buf bytes.Buffer
+	buf.WriteString(strings.ToUpper(g.Type.String()))
+	switch g.Type {
+	case TypePoint:
+		buf.WriteByte('(')
+		writePoint(&buf, g.Points[0])
+		buf.WriteByte(')')
+	case TypeLineString:
+		writePointList(&buf, g.Points)
+	case TypePolygon:
+		buf.WriteByte('(')
+		for i, ring := range g.Rings {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writePointList(&buf, ring)
+		}
+		buf.WriteByte(')')
+	}
+	return buf.String()
+}
+
+func writePoint(buf *bytes.Buffer, p Point) {
+	buf.WriteString(strconv.FormatFloat(p.X, 'g', -1, 64))
+	buf.WriteByte(' ')
+	buf.WriteString(strconv.FormatFloat(p.Y, 'g', -1, 64))
+}
+
+func writePointList(buf *bytes.Buffer, points []Point) {
+	buf.WriteByte('(')
+	for i, p := range points {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		writePoint(buf, p)
+	}
+	buf.WriteByte(')')
+}
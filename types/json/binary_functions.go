@@ -281,8 +281,7 @@ func (bj BinaryJSON) Modify(pathExprList []PathExpression, values []BinaryJSON,
 	}
 	for _, pathExpr := range pathExprList {
 		if pathExpr.flags.containsAnyAsterisk() {
-			// TODO: should return 3149(42000)
-			return retj, errors.New("Invalid path expression")
+			return retj, ErrInvalidJSONPathWildcard
 		}
 	}
 	for i := 0; i < len(pathExprList); i++ {
@@ -304,12 +303,10 @@ func (bj BinaryJSON) Modify(pathExprList []PathExpression, values []BinaryJSON,
 func (bj BinaryJSON) Remove(pathExprList []PathExpression) (BinaryJSON, error) {
 	for _, pathExpr := range pathExprList {
 		if len(pathExpr.legs) == 0 {
-			// TODO: should return 3153(42000)
-			return bj, errors.New("Invalid path expression")
+			return bj, ErrJSONVacuousPath
 		}
 		if pathExpr.flags.containsAnyAsterisk() {
-			// TODO: should return 3149(42000)
-			return bj, errors.New("Invalid path expression")
+			return bj, ErrInvalidJSONPathWildcard
 		}
 		modifer := &binaryModifier{bj: bj}
 		bj = modifer.remove(pathExpr)
@@ -689,3 +686,357 @@ func PeekBytesAsJSON(b []byte) (n int, err error) {
 	err = errors.New("Invalid JSON bytes")
 	return
 }
+
+// GetElemCount returns the number of the elements of an object or an array.
+func (bj BinaryJSON) GetElemCount() int {
+	return bj.getElemCount()
+}
+
+// GetKeys returns the keys of an object as an array of strings.
+func (bj BinaryJSON) GetKeys() BinaryJSON {
+	elemCount := bj.getElemCount()
+	keys := make([]BinaryJSON, 0, elemCount)
+	for i := 0; i < elemCount; i++ {
+		keys = append(keys, CreateBinary(string(bj.objectGetKey(i))))
+	}
+	return buildBinaryArray(keys)
+}
+
+// GetElemDepth returns the maximum depth of the JSON. A scalar, an empty
+// array or an empty object has depth 1.
+func (bj BinaryJSON) GetElemDepth() int {
+	var maxDepth int
+	switch bj.TypeCode {
+	case TypeCodeObject:
+		elemCount := bj.getElemCount()
+		for i := 0; i < elemCount; i++ {
+			if depth := bj.objectGetVal(i).GetElemDepth(); depth > maxDepth {
+				maxDepth = depth
+			}
+		}
+	case TypeCodeArray:
+		elemCount := bj.getElemCount()
+		for i := 0; i < elemCount; i++ {
+			if depth := bj.arrayGetElem(i).GetElemDepth(); depth > maxDepth {
+				maxDepth = depth
+			}
+		}
+	}
+	return maxDepth + 1
+}
+
+// ArrayAppend appends value to the end of the array indicated by pathExpr. A scalar
+// or an object indicated by pathExpr is autowrapped as an array before appending.
+// Nothing is changed if pathExpr doesn't exist.
+func (bj BinaryJSON) ArrayAppend(pathExpr PathExpression, value BinaryJSON) (BinaryJSON, error) {
+	if pathExpr.flags.containsAnyAsterisk() {
+		return bj, ErrInvalidJSONPathWildcard
+	}
+	result := make([]BinaryJSON, 0, 1)
+	result = bj.extractTo(result, pathExpr)
+	if len(result) == 0 {
+		return bj, nil
+	}
+	obj := result[0]
+	var elems []BinaryJSON
+	if obj.TypeCode == TypeCodeArray {
+		elemCount := obj.getElemCount()
+		elems = make([]BinaryJSON, 0, elemCount+1)
+		for i := 0; i < elemCount; i++ {
+			elems = append(elems, obj.arrayGetElem(i))
+		}
+	} else {
+		elems = []BinaryJSON{obj}
+	}
+	elems = append(elems, value)
+	modifier := &binaryModifier{bj: bj, modifyPtr: &obj.Value[0], modifyValue: buildBinaryArray(elems)}
+	return modifier.rebuild(), nil
+}
+
+// ArrayInsert inserts value into the array cell indicated by pathExpr, the elements
+// since the cell are shifted. The value is appended if the cell is beyond the end of
+// the array, and nothing is changed if the parent of the cell isn't an array.
+func (bj BinaryJSON) ArrayInsert(pathExpr PathExpression, value BinaryJSON) (BinaryJSON, error) {
+	if pathExpr.flags.containsAnyAsterisk() {
+		return bj, ErrInvalidJSONPathWildcard
+	}
+	if len(pathExpr.legs) == 0 {
+		return bj, ErrInvalidJSONPathArrayCell
+	}
+	parentPath, lastLeg := pathExpr.popOneLastLeg()
+	if lastLeg.typ != pathLegIndex {
+		return bj, ErrInvalidJSONPathArrayCell
+	}
+	result := make([]BinaryJSON, 0, 1)
+	result = bj.extractTo(result, parentPath)
+	if len(result) == 0 || result[0].TypeCode != TypeCodeArray {
+		return bj, nil
+	}
+	parent := result[0]
+	elemCount := parent.getElemCount()
+	insertIdx := lastLeg.arrayIndex
+	if insertIdx > elemCount {
+		insertIdx = elemCount
+	}
+	elems := make([]BinaryJSON, 0, elemCount+1)
+	for i := 0; i < insertIdx; i++ {
+		elems = append(elems, parent.arrayGetElem(i))
+	}
+	elems = append(elems, value)
+	for i := insertIdx; i < elemCount; i++ {
+		elems = append(elems, parent.arrayGetElem(i))
+	}
+	modifier := &binaryModifier{bj: bj, modifyPtr: &parent.Value[0], modifyValue: buildBinaryArray(elems)}
+	return modifier.rebuild(), nil
+}
+
+// MergePatchBinary merges the documents by the rules of RFC 7396, a nil document
+// stands for SQL NULL. The result is nil if it depends on a NULL document.
+func MergePatchBinary(bjs []*BinaryJSON) *BinaryJSON {
+	// A patch which isn't an object replaces the whole target,
+	// so the documents before the last one of them are ignored.
+	for i := len(bjs) - 1; i > 0; i-- {
+		if bjs[i] == nil || bjs[i].TypeCode != TypeCodeObject {
+			bjs = bjs[i:]
+			break
+		}
+	}
+	target := bjs[0]
+	for _, patch := range bjs[1:] {
+		if target == nil {
+			break
+		}
+		merged := mergePatchBinary(*target, *patch)
+		target = &merged
+	}
+	return target
+}
+
+func mergePatchBinary(target, patch BinaryJSON) BinaryJSON {
+	if patch.TypeCode != TypeCodeObject {
+		return patch
+	}
+	keyValMap := make(map[string]interface{})
+	if target.TypeCode == TypeCodeObject {
+		elemCount := target.getElemCount()
+		for i := 0; i < elemCount; i++ {
+			keyValMap[string(target.objectGetKey(i))] = target.objectGetVal(i)
+		}
+	}
+	elemCount := patch.getElemCount()
+	for i := 0; i < elemCount; i++ {
+		key, val := string(patch.objectGetKey(i)), patch.objectGetVal(i)
+		if val.TypeCode == TypeCodeLiteral && val.Value[0] == LiteralNil {
+			delete(keyValMap, key)
+			continue
+		}
+		// A missing member is merged as a non-object, which removes the nulls in val.
+		old, ok := keyValMap[key].(BinaryJSON)
+		if !ok {
+			old = CreateBinary(nil)
+		}
+		keyValMap[key] = mergePatchBinary(old, val)
+	}
+	return CreateBinary(keyValMap)
+}
+
+// extractCallbackFn is called by extractToCallback with the full path and the value of every matched element.
+type extractCallbackFn func(fullpath PathExpression, bj BinaryJSON) (stop bool, err error)
+
+// extractToCallback is like extractTo, but the matched elements are passed to callbackFn along with their full paths.
+func (bj BinaryJSON) extractToCallback(pathExpr PathExpression, callbackFn extractCallbackFn, fullpath PathExpression) (stop bool, err error) {
+	if len(pathExpr.legs) == 0 {
+		return callbackFn(fullpath, bj)
+	}
+	currentLeg, subPathExpr := pathExpr.popOneLeg()
+	if currentLeg.typ == pathLegIndex {
+		if bj.TypeCode != TypeCodeArray {
+			if currentLeg.arrayIndex <= 0 {
+				return bj.extractToCallback(subPathExpr, callbackFn, fullpath)
+			}
+			return false, nil
+		}
+		elemCount := bj.getElemCount()
+		if currentLeg.arrayIndex == arrayIndexAsterisk {
+			for i := 0; i < elemCount; i++ {
+				stop, err = bj.arrayGetElem(i).extractToCallback(subPathExpr, callbackFn, fullpath.pushBackOneIndexLeg(i))
+				if stop || err != nil {
+					return stop, errors.Trace(err)
+				}
+			}
+		} else if currentLeg.arrayIndex < elemCount {
+			idx := currentLeg.arrayIndex
+			return bj.arrayGetElem(idx).extractToCallback(subPathExpr, callbackFn, fullpath.pushBackOneIndexLeg(idx))
+		}
+	} else if currentLeg.typ == pathLegKey && bj.TypeCode == TypeCodeObject {
+		elemCount := bj.getElemCount()
+		if currentLeg.dotKey == "*" {
+			for i := 0; i < elemCount; i++ {
+				key := string(bj.objectGetKey(i))
+				stop, err = bj.objectGetVal(i).extractToCallback(subPathExpr, callbackFn, fullpath.pushBackOneKeyLeg(key))
+				if stop || err != nil {
+					return stop, errors.Trace(err)
+				}
+			}
+		} else if child, ok := bj.objectSearchKey(hack.Slice(currentLeg.dotKey)); ok {
+			return child.extractToCallback(subPathExpr, callbackFn, fullpath.pushBackOneKeyLeg(currentLeg.dotKey))
+		}
+	} else if currentLeg.typ == pathLegDoubleAsterisk {
+		stop, err = bj.extractToCallback(subPathExpr, callbackFn, fullpath)
+		if stop || err != nil {
+			return stop, errors.Trace(err)
+		}
+		if bj.TypeCode == TypeCodeArray {
+			elemCount := bj.getElemCount()
+			for i := 0; i < elemCount; i++ {
+				stop, err = bj.arrayGetElem(i).extractToCallback(pathExpr, callbackFn, fullpath.pushBackOneIndexLeg(i))
+				if stop || err != nil {
+					return stop, errors.Trace(err)
+				}
+			}
+		} else if bj.TypeCode == TypeCodeObject {
+			elemCount := bj.getElemCount()
+			for i := 0; i < elemCount; i++ {
+				key := string(bj.objectGetKey(i))
+				stop, err = bj.objectGetVal(i).extractToCallback(pathExpr, callbackFn, fullpath.pushBackOneKeyLeg(key))
+				if stop || err != nil {
+					return stop, errors.Trace(err)
+				}
+			}
+		}
+	}
+	return false, nil
+}
+
+// BinaryJSONWalkFunc is the callback of BinaryJSON.Walk, the walk is stopped if it returns true or an error.
+type BinaryJSONWalkFunc func(fullpath PathExpression, bj BinaryJSON) (stop bool, err error)
+
+// Walk traverses the elements matched by pathExprList and their descendants in pre-order.
+// The whole document is traversed if pathExprList is empty.
+func (bj BinaryJSON) Walk(walkFn BinaryJSONWalkFunc, pathExprList ...PathExpression) error {
+	doWalk := func(fullpath PathExpression, bj BinaryJSON) (bool, error) {
+		return bj.walk(walkFn, fullpath)
+	}
+	if len(pathExprList) == 0 {
+		_, err := doWalk(PathExpression{}, bj)
+		return errors.Trace(err)
+	}
+	for _, pathExpr := range pathExprList {
+		stop, err := bj.extractToCallback(pathExpr, doWalk, PathExpression{})
+		if stop || err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+func (bj BinaryJSON) walk(walkFn BinaryJSONWalkFunc, fullpath PathExpression) (stop bool, err error) {
+	stop, err = walkFn(fullpath, bj)
+	if stop || err != nil {
+		return stop, errors.Trace(err)
+	}
+	switch bj.TypeCode {
+	case TypeCodeArray:
+		elemCount := bj.getElemCount()
+		for i := 0; i < elemCount; i++ {
+			stop, err = bj.arrayGetElem(i).walk(walkFn, fullpath.pushBackOneIndexLeg(i))
+			if stop || err != nil {
+				return stop, errors.Trace(err)
+			}
+		}
+	case TypeCodeObject:
+		elemCount := bj.getElemCount()
+		for i := 0; i < elemCount; i++ {
+			key := string(bj.objectGetKey(i))
+			stop, err = bj.objectGetVal(i).walk(walkFn, fullpath.pushBackOneKeyLeg(key))
+			if stop || err != nil {
+				return stop, errors.Trace(err)
+			}
+		}
+	}
+	return false, nil
+}
+
+// QuoteString encloses s in double quotes and escapes the quotes, backslashes and
+// control characters in it, as JSON_QUOTE does. Unlike MarshalJSON, the HTML
+// characters are not escaped.
+func QuoteString(s string) string {
+	var buf bytes.Buffer
+	buf.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case '"', '\\':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if c < 0x20 {
+				fmt.Fprintf(&buf, `\u%04x`, c)
+			} else {
+				buf.WriteByte(c)
+			}
+		}
+	}
+	buf.WriteByte('"')
+	return buf.String()
+}
+
+// MarshalPrettyJSON formats the JSON as JSON_PRETTY does, every member of an array
+// or an object is put on a separate line and indented by 2 spaces.
+func (bj BinaryJSON) MarshalPrettyJSON() ([]byte, error) {
+	buf := make([]byte, 0, len(bj.Value)*2)
+	return bj.marshalPrettyTo(buf, 0)
+}
+
+func (bj BinaryJSON) marshalPrettyTo(buf []byte, depth int) ([]byte, error) {
+	if bj.TypeCode != TypeCodeArray && bj.TypeCode != TypeCodeObject {
+		return bj.marshalTo(buf)
+	}
+	openChar, closeChar := byte('['), byte(']')
+	if bj.TypeCode == TypeCodeObject {
+		openChar, closeChar = '{', '}'
+	}
+	elemCount := bj.getElemCount()
+	if elemCount == 0 {
+		return append(buf, openChar, closeChar), nil
+	}
+	buf = append(buf, openChar)
+	for i := 0; i < elemCount; i++ {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = appendPrettyIndent(buf, depth+1)
+		var elem BinaryJSON
+		if bj.TypeCode == TypeCodeObject {
+			buf = marshalStringTo(buf, bj.objectGetKey(i))
+			buf = append(buf, ':', ' ')
+			elem = bj.objectGetVal(i)
+		} else {
+			elem = bj.arrayGetElem(i)
+		}
+		var err error
+		if buf, err = elem.marshalPrettyTo(buf, depth+1); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	buf = appendPrettyIndent(buf, depth)
+	return append(buf, closeChar), nil
+}
+
+func appendPrettyIndent(buf []byte, depth int) []byte {
+	buf = append(buf, '\n')
+	for i := 0; i < depth; i++ {
+		buf = append(buf, ' ', ' ')
+	}
+	return buf
+}
@@ -17,6 +17,7 @@ import (
 	"testing"
 
 	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/terror"
 )
 
 var _ = Suite(&testJSONSuite{})
@@ -277,6 +278,160 @@ func (s *testJSONSuite) TestBinaryJSONMerge(c *C) {
 	}
 }
 
+func (s *testJSONSuite) TestBinaryJSONKeysDepth(c *C) {
+	bj := mustParseBinaryFromString(c, `{"b": [1, [2, {"c": 3}]], "a": {}, "": null}`)
+	c.Assert(bj.GetElemCount(), Equals, 3)
+	c.Assert(bj.GetKeys().String(), Equals, `["","a","b"]`)
+	c.Assert(bj.GetElemDepth(), Equals, 5)
+	for _, str := range []string{`1`, `"a"`, `[]`, `{}`} {
+		c.Assert(mustParseBinaryFromString(c, str).GetElemDepth(), Equals, 1)
+	}
+	c.Assert(mustParseBinaryFromString(c, `[[], {}]`).GetElemDepth(), Equals, 2)
+}
+
+func (s *testJSONSuite) TestBinaryJSONArrayAppendInsert(c *C) {
+	var tests = []struct {
+		base     string
+		path     string
+		value    string
+		insert   bool
+		expected string
+		err      error
+	}{
+		{`[1, [2, 3]]`, "$", `4`, false, `[1, [2, 3], 4]`, nil},
+		{`[1, [2, 3]]`, "$[1]", `4`, false, `[1, [2, 3, 4]]`, nil},
+		{`[1, [2, 3]]`, "$[0]", `null`, false, `[[1, null], [2, 3]]`, nil},
+		{`{"a": {"b": 1}}`, "$.a", `2`, false, `{"a": [{"b": 1}, 2]}`, nil},
+		{`{"a": 1}`, "$.b", `2`, false, `{"a": 1}`, nil},
+		{`[1]`, "$[*]", `2`, false, ``, ErrInvalidJSONPathWildcard},
+
+		{`[1, [2, 3]]`, "$[0]", `4`, true, `[4, 1, [2, 3]]`, nil},
+		{`[1, [2, 3]]`, "$[1][1]", `4`, true, `[1, [2, 4, 3]]`, nil},
+		{`[1, [2, 3]]`, "$[9]", `"x"`, true, `[1, [2, 3], "x"]`, nil},
+		{`{"a": [1]}`, "$.a[0]", `true`, true, `{"a": [true, 1]}`, nil},
+		{`{"a": 1}`, "$.a[0]", `2`, true, `{"a": 1}`, nil},
+		{`[1]`, "$", `2`, true, ``, ErrInvalidJSONPathArrayCell},
+		{`{"a": [1]}`, "$.a", `2`, true, ``, ErrInvalidJSONPathArrayCell},
+	}
+	for _, tt := range tests {
+		pathExpr, err := ParseJSONPathExpr(tt.path)
+		c.Assert(err, IsNil)
+		base, value := mustParseBinaryFromString(c, tt.base), mustParseBinaryFromString(c, tt.value)
+		var result BinaryJSON
+		if tt.insert {
+			result, err = base.ArrayInsert(pathExpr, value)
+		} else {
+			result, err = base.ArrayAppend(pathExpr, value)
+		}
+		if tt.err != nil {
+			c.Assert(tt.err.(*terror.Error).Equal(err), IsTrue, Commentf("%s %s", tt.base, tt.path))
+			continue
+		}
+		c.Assert(err, IsNil)
+		expected := mustParseBinaryFromString(c, tt.expected)
+		c.Assert(CompareBinary(result, expected), Equals, 0, Commentf("got %s expect %s", result, expected))
+	}
+}
+
+func (s *testJSONSuite) TestBinaryJSONMergePatch(c *C) {
+	var tests = []struct {
+		docs     []interface{}
+		expected interface{}
+	}{
+		{[]interface{}{`{"a": 1, "b": 2}`, `{"a": 3, "c": 4}`}, `{"a": 3, "b": 2, "c": 4}`},
+		{[]interface{}{`{"a": {"x": 1}}`, `{"a": {"y": 2, "z": null}}`}, `{"a": {"x": 1, "y": 2}}`},
+		{[]interface{}{`{"a": 1, "b": 2}`, `{"b": null}`}, `{"a": 1}`},
+		{[]interface{}{`{"a": 1}`, `{"b": {"c": null, "d": 1}}`}, `{"a": 1, "b": {"d": 1}}`},
+		{[]interface{}{`[1, 2]`, `{"a": 1}`}, `{"a": 1}`},
+		{[]interface{}{`{"a": 1}`, `[1, 2]`}, `[1, 2]`},
+		{[]interface{}{`{"a": 1}`, `{"b": 2}`, `{"a": null}`}, `{"b": 2}`},
+		{[]interface{}{nil, `{"a": 1}`}, nil},
+		{[]interface{}{`{"a": 1}`, nil}, nil},
+		{[]interface{}{nil, `1`, `{"a": 1}`}, `{"a": 1}`},
+	}
+	for _, tt := range tests {
+		docs := make([]*BinaryJSON, 0, len(tt.docs))
+		for _, doc := range tt.docs {
+			if doc == nil {
+				docs = append(docs, nil)
+				continue
+			}
+			bj := mustParseBinaryFromString(c, doc.(string))
+			docs = append(docs, &bj)
+		}
+		result := MergePatchBinary(docs)
+		if tt.expected == nil {
+			c.Assert(result, IsNil)
+			continue
+		}
+		c.Assert(result, NotNil)
+		expected := mustParseBinaryFromString(c, tt.expected.(string))
+		c.Assert(CompareBinary(*result, expected), Equals, 0, Commentf("got %s expect %s", result, expected))
+	}
+}
+
+func (s *testJSONSuite) TestBinaryJSONWalk(c *C) {
+	bj := mustParseBinaryFromString(c, `{"a": [1, {"b": "x"}], "c d": "y"}`)
+	var tests = []struct {
+		paths    []string
+		expected []string
+	}{
+		{nil, []string{`$`, `$.a`, `$.a[0]`, `$.a[1]`, `$.a[1].b`, `$."c d"`}},
+		{[]string{`$.a[1]`}, []string{`$.a[1]`, `$.a[1].b`}},
+		{[]string{`$**.b`, `$.*`}, []string{`$.a[1].b`, `$.a`, `$.a[0]`, `$.a[1]`, `$.a[1].b`, `$."c d"`}},
+		{[]string{`$.x`}, nil},
+	}
+	for _, tt := range tests {
+		pathExprs := make([]PathExpression, 0, len(tt.paths))
+		for _, path := range tt.paths {
+			pathExpr, err := ParseJSONPathExpr(path)
+			c.Assert(err, IsNil)
+			pathExprs = append(pathExprs, pathExpr)
+		}
+		var visited []string
+		err := bj.Walk(func(fullpath PathExpression, elem BinaryJSON) (bool, error) {
+			visited = append(visited, fullpath.String())
+			return false, nil
+		}, pathExprs...)
+		c.Assert(err, IsNil)
+		c.Assert(visited, DeepEquals, tt.expected)
+	}
+
+	var visited int
+	err := bj.Walk(func(fullpath PathExpression, elem BinaryJSON) (bool, error) {
+		visited++
+		return visited == 2, nil
+	})
+	c.Assert(err, IsNil)
+	c.Assert(visited, Equals, 2)
+}
+
+func (s *testJSONSuite) TestQuoteString(c *C) {
+	c.Assert(QuoteString(``), Equals, `""`)
+	c.Assert(QuoteString(`a"b\c`), Equals, `"a\"b\\c"`)
+	c.Assert(QuoteString("<a>\n\t\x01&"), Equals, `"<a>\n\t\u0001&"`)
+	c.Assert(QuoteString(`宽字符`), Equals, `"宽字符"`)
+}
+
+func (s *testJSONSuite) TestBinaryJSONMarshalPretty(c *C) {
+	var tests = []struct {
+		in       string
+		expected string
+	}{
+		{`1`, `1`},
+		{`"a"`, `"a"`},
+		{`[]`, `[]`},
+		{`{}`, `{}`},
+		{`[1, "a"]`, "[\n  1,\n  \"a\"\n]"},
+		{`{"a": [1, {}], "b": {"c": null}}`, "{\n  \"a\": [\n    1,\n    {}\n  ],\n  \"b\": {\n    \"c\": null\n  }\n}"},
+	}
+	for _, tt := range tests {
+		out, err := mustParseBinaryFromString(c, tt.in).MarshalPrettyJSON()
+		c.Assert(err, IsNil)
+		c.Assert(string(out), Equals, tt.expected)
+	}
+}
+
 func mustParseBinaryFromString(c *C, s string) BinaryJSON {
 	bj, err := ParseBinaryFromString(s)
 	c.Assert(err, IsNil)
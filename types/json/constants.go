@@ -212,12 +212,27 @@ var (
 	ErrInvalidJSONPath = terror.ClassJSON.New(mysql.ErrInvalidJSONPath, mysql.MySQLErrName[mysql.ErrInvalidJSONPath])
 	// ErrInvalidJSONData means invalid JSON data.
 	ErrInvalidJSONData = terror.ClassJSON.New(mysql.ErrInvalidJSONData, mysql.MySQLErrName[mysql.ErrInvalidJSONData])
+	// ErrInvalidJSONPathWildcard means invalid JSON path that contain wildcard characters.
+	ErrInvalidJSONPathWildcard = terror.ClassJSON.New(mysql.ErrInvalidJSONPathWildcard, mysql.MySQLErrName[mysql.ErrInvalidJSONPathWildcard])
+	// ErrJSONVacuousPath means the path expression '$' is used where it is meaningless.
+	ErrJSONVacuousPath = terror.ClassJSON.New(mysql.ErrJSONVacuousPath, mysql.MySQLErrName[mysql.ErrJSONVacuousPath])
+	// ErrInvalidJSONPathArrayCell means the path expression is not a path to a cell in an array.
+	ErrInvalidJSONPathArrayCell = terror.ClassJSON.New(mysql.ErrInvalidJSONPathArrayCell, mysql.MySQLErrName[mysql.ErrInvalidJSONPathArrayCell])
+	// ErrJSONBadOneOrAllArg means the oneOrAll argument isn't 'one' or 'all'.
+	ErrJSONBadOneOrAllArg = terror.ClassJSON.New(mysql.ErrJSONBadOneOrAllArg, mysql.MySQLErrName[mysql.ErrJSONBadOneOrAllArg])
+	// ErrJSONDocumentNULLKey means a JSON object is built with a NULL key.
+	ErrJSONDocumentNULLKey = terror.ClassJSON.New(mysql.ErrJSONDocumentNULLKey, mysql.MySQLErrName[mysql.ErrJSONDocumentNULLKey])
 )
 
 func init() {
 	terror.ErrClassToMySQLCodes[terror.ClassJSON] = map[terror.ErrCode]uint16{
-		mysql.ErrInvalidJSONText: mysql.ErrInvalidJSONText,
-		mysql.ErrInvalidJSONPath: mysql.ErrInvalidJSONPath,
-		mysql.ErrInvalidJSONData: mysql.ErrInvalidJSONData,
+		mysql.ErrInvalidJSONText:          mysql.ErrInvalidJSONText,
+		mysql.ErrInvalidJSONPath:          mysql.ErrInvalidJSONPath,
+		mysql.ErrInvalidJSONData:          mysql.ErrInvalidJSONData,
+		mysql.ErrInvalidJSONPathWildcard:  mysql.ErrInvalidJSONPathWildcard,
+		mysql.ErrJSONVacuousPath:          mysql.ErrJSONVacuousPath,
+		mysql.ErrInvalidJSONPathArrayCell: mysql.ErrInvalidJSONPathArrayCell,
+		mysql.ErrJSONBadOneOrAllArg:       mysql.ErrJSONBadOneOrAllArg,
+		mysql.ErrJSONDocumentNULLKey:      mysql.ErrJSONDocumentNULLKey,
 	}
 }
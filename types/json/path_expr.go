@@ -14,6 +14,7 @@
 package json
 
 import (
+	"bytes"
 	"regexp"
 	"strconv"
 	"strings"
@@ -49,6 +50,9 @@ import (
 // "[^"\\]*(\\.[^"\\]*)*" matches any string literal which can carry escaped quotes;
 var jsonPathExprLegRe = regexp.MustCompile(`(\.\s*([a-zA-Z_][a-zA-Z0-9_]*|\*|"[^"\\]*(\\.[^"\\]*)*")|(\[\s*([0-9]+|\*)\s*\])|\*\*)`)
 
+// jsonPathKeyRe matches the keys which needn't be quoted in a path expression.
+var jsonPathKeyRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
 type pathLegType byte
 
 const (
@@ -91,6 +95,11 @@ type PathExpression struct {
 	flags pathExpressionFlag
 }
 
+// ContainsAnyAsterisk returns true if pe contains any asterisk.
+func (pe PathExpression) ContainsAnyAsterisk() bool {
+	return pe.flags.containsAnyAsterisk()
+}
+
 // popOneLeg returns a pathLeg, and a child PathExpression without that leg.
 func (pe PathExpression) popOneLeg() (pathLeg, PathExpression) {
 	newPe := PathExpression{
@@ -117,6 +126,59 @@ func (pe PathExpression) popOneLastLeg() (PathExpression, pathLeg) {
 	return PathExpression{legs: pe.legs[:lastLegIdx]}, lastLeg
 }
 
+// pushBackOneIndexLeg returns a PathExpression with an index leg appended, pe is never changed.
+func (pe PathExpression) pushBackOneIndexLeg(index int) PathExpression {
+	newPe := PathExpression{
+		legs:  append(pe.legs[:len(pe.legs):len(pe.legs)], pathLeg{typ: pathLegIndex, arrayIndex: index}),
+		flags: pe.flags,
+	}
+	if index == arrayIndexAsterisk {
+		newPe.flags |= pathExpressionContainsAsterisk
+	}
+	return newPe
+}
+
+// pushBackOneKeyLeg returns a PathExpression with a key leg appended, pe is never changed.
+func (pe PathExpression) pushBackOneKeyLeg(key string) PathExpression {
+	newPe := PathExpression{
+		legs:  append(pe.legs[:len(pe.legs):len(pe.legs)], pathLeg{typ: pathLegKey, dotKey: key}),
+		flags: pe.flags,
+	}
+	if key == "*" {
+		newPe.flags |= pathExpressionContainsAsterisk
+	}
+	return newPe
+}
+
+// String implements fmt.Stringer interface. The keys are quoted only if they
+// aren't identifiers, like MySQL does in JSON_SEARCH.
+func (pe PathExpression) String() string {
+	var buf bytes.Buffer
+	buf.WriteByte('$')
+	for _, leg := range pe.legs {
+		switch leg.typ {
+		case pathLegIndex:
+			if leg.arrayIndex == arrayIndexAsterisk {
+				buf.WriteString("[*]")
+			} else {
+				buf.WriteByte('[')
+				buf.WriteString(strconv.Itoa(leg.arrayIndex))
+				buf.WriteByte(']')
+			}
+		case pathLegKey:
+			buf.WriteByte('.')
+			if leg.dotKey == "*" || jsonPathKeyRe.MatchString(leg.dotKey) {
+				buf.WriteString(leg.dotKey)
+			} else {
+				buf.WriteString(QuoteString(leg.dotKey))
+			}
+		case pathLegDoubleAsterisk:
+			buf.WriteString("**")
+		}
+	}
+	return buf.String()
+}
+
 // ParseJSONPathExpr parses a JSON path expression. Returns a PathExpression
 // object which can be used in JSON_EXTRACT, JSON_SET and so on.
 func ParseJSONPathExpr(pathExpr string) (pe PathExpression, err error) {
@@ -62,3 +62,22 @@ func (s *testJSONSuite) TestValidatePathExpr(c *C) {
 		}
 	}
 }
+
+func (s *testJSONSuite) TestPathExpressionString(c *C) {
+	var tests = []struct {
+		exprString string
+		expected   string
+	}{
+		{"$", "$"},
+		{"  $ . a [ 1 ]", "$.a[1]"},
+		{"$.*[*]", "$.*[*]"},
+		{"$**.a", "$**.a"},
+		{`$."a b"."c"`, `$."a b".c`},
+		{`$."a\"b"`, `$."a\"b"`},
+	}
+	for _, tt := range tests {
+		pe, err := ParseJSONPathExpr(tt.exprString)
+		c.Assert(err, IsNil)
+		c.Assert(pe.String(), Equals, tt.expected)
+	}
+}
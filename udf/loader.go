@@ -0,0 +1,106 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udf
+
+import (
+	"fmt"
+	"path/filepath"
+	"plugin"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/config"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/sqlexec"
+	log "github.com/sirupsen/logrus"
+	goctx "golang.org/x/net/context"
+)
+
+// NewFunctionSymbol is the name of the symbol which a plugin exports to create the functions.
+const NewFunctionSymbol = "NewFunction"
+
+// Loader opens the plugin lib and creates the function name from it.
+// It can be replaced in tests, which can't build plugins.
+var Loader = loadPlugin
+
+func loadPlugin(name, lib string) (Function, error) {
+	dir := config.GetGlobalConfig().PluginDir
+	if dir == "" {
+		return nil, ErrCantOpenLibrary.GenByArgs(lib, 0, "plugin-dir is not configured")
+	}
+	p, err := plugin.Open(filepath.Join(dir, lib))
+	if err != nil {
+		return nil, ErrCantOpenLibrary.GenByArgs(lib, 0, err.Error())
+	}
+	sym, err := p.Lookup(NewFunctionSymbol)
+	if err != nil {
+		return nil, ErrCantFindDlEntry.GenByArgs(NewFunctionSymbol)
+	}
+	newFunc, ok := sym.(func(string) (Function, error))
+	if !ok {
+		return nil, ErrCantFindDlEntry.GenByArgs(NewFunctionSymbol)
+	}
+	fn, err := newFunc(name)
+	if err != nil {
+		return nil, ErrCantInitialize.GenByArgs(name, err.Error())
+	}
+	return fn, nil
+}
+
+// Load creates the function name from the plugin lib and checks that it returns the values of retTp.
+func Load(name, lib string, retTp Type) (Function, error) {
+	if strings.ContainsAny(lib, `/\`) {
+		return nil, ErrNoPaths
+	}
+	fn, err := Loader(name, lib)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if fn.RetType() != retTp {
+		return nil, ErrCantInitialize.GenByArgs(name, fmt.Sprintf("it returns %s rather than %s", fn.RetType(), retTp))
+	}
+	return fn, nil
+}
+
+// Reload loads all the functions in mysql.func and replaces the loaded ones with them.
+// The functions which fail to load are skipped, so a bad plugin doesn't stop the server from starting.
+func Reload(ctx context.Context) error {
+	rss, err := ctx.(sqlexec.SQLExecutor).Execute(goctx.Background(), "SELECT name, ret, dl FROM mysql.func WHERE type = 'function'")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	rs := rss[0]
+	defer terror.Call(rs.Close)
+
+	funcs := make(map[string]Function)
+	for {
+		row, err := rs.Next(goctx.TODO())
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if row == nil {
+			break
+		}
+		name, lib := row.GetString(0), row.GetString(2)
+		fn, err := Load(name, lib, Type(row.GetInt64(1)))
+		if err != nil {
+			log.Warnf("[udf] load function %s from %s failed: %v", name, lib, err)
+			continue
+		}
+		funcs[strings.ToLower(name)] = fn
+	}
+	set(funcs)
+	return nil
+}
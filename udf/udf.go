@@ -0,0 +1,160 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package udf implements the user-defined functions, which are loaded from Go plugins.
+//
+// A plugin is built with "go build -buildmode=plugin" and put in the plugin-dir of the config,
+// it must export a symbol named NewFunction of type func(name string) (udf.Function, error),
+// which returns the implementation of the function name.
+package udf
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/types"
+)
+
+// Type is the type of the arguments and the return value of a user-defined function,
+// its values are the same as the Item_result of MySQL, which is stored in the ret column of mysql.func.
+type Type byte
+
+// The types of the user-defined functions.
+const (
+	TypeString  Type = 0
+	TypeReal    Type = 1
+	TypeInt     Type = 2
+	TypeDecimal Type = 4
+)
+
+// EvalType returns the evaluation type of the expressions of type t.
+func (t Type) EvalType() types.EvalType {
+	switch t {
+	case TypeInt:
+		return types.ETInt
+	case TypeReal:
+		return types.ETReal
+	case TypeDecimal:
+		return types.ETDecimal
+	default:
+		return types.ETString
+	}
+}
+
+// String implements fmt.Stringer interface.
+func (t Type) String() string {
+	switch t {
+	case TypeInt:
+		return "INTEGER"
+	case TypeReal:
+		return "REAL"
+	case TypeDecimal:
+		return "DECIMAL"
+	default:
+		return "STRING"
+	}
+}
+
+// TypeFromEvalType returns the Type of the user-defined functions which return the values of tp.
+func TypeFromEvalType(tp types.EvalType) Type {
+	switch tp {
+	case types.ETInt:
+		return TypeInt
+	case types.ETReal:
+		return TypeReal
+	case types.ETDecimal:
+		return TypeDecimal
+	default:
+		return TypeString
+	}
+}
+
+// Function is a scalar user-defined function.
+type Function interface {
+	// ArgTypes returns the types of the arguments, the arguments are converted to these types before
+	// they are passed to Eval.
+	ArgTypes() []Type
+	// RetType returns the type of the return value.
+	RetType() Type
+	// Eval evaluates the function. The arguments and the return value are int64 for TypeInt, float64
+	// for TypeReal, string for TypeString and TypeDecimal, and nil for NULL.
+	Eval(args []interface{}) (interface{}, error)
+}
+
+// registry holds the user-defined functions which are loaded, the keys are the lower case names.
+var registry = struct {
+	sync.RWMutex
+	funcs map[string]Function
+}{funcs: make(map[string]Function)}
+
+// Get returns the loaded user-defined function name.
+func Get(name string) (Function, bool) {
+	registry.RLock()
+	fn, ok := registry.funcs[strings.ToLower(name)]
+	registry.RUnlock()
+	return fn, ok
+}
+
+// Register makes fn available as the user-defined function name.
+func Register(name string, fn Function) {
+	registry.Lock()
+	registry.funcs[strings.ToLower(name)] = fn
+	registry.Unlock()
+}
+
+// Unregister removes the user-defined function name.
+func Unregister(name string) {
+	registry.Lock()
+	delete(registry.funcs, strings.ToLower(name))
+	registry.Unlock()
+}
+
+// set replaces all the user-defined functions with funcs.
+func set(funcs map[string]Function) {
+	registry.Lock()
+	registry.funcs = funcs
+	registry.Unlock()
+}
+
+// Error instances.
+var (
+	// ErrCantInitialize is returned when the plugin fails to create the function.
+	ErrCantInitialize = terror.ClassUDF.New(mysql.ErrCantInitializeUdf, mysql.MySQLErrName[mysql.ErrCantInitializeUdf])
+	// ErrNoPaths is returned when the shared library of CREATE FUNCTION contains a path.
+	ErrNoPaths = terror.ClassUDF.New(mysql.ErrUdfNoPaths, mysql.MySQLErrName[mysql.ErrUdfNoPaths])
+	// ErrExists is returned when the function to create already exists.
+	ErrExists = terror.ClassUDF.New(mysql.ErrUdfExists, mysql.MySQLErrName[mysql.ErrUdfExists])
+	// ErrCantOpenLibrary is returned when the plugin can't be opened.
+	ErrCantOpenLibrary = terror.ClassUDF.New(mysql.ErrCantOpenLibrary, mysql.MySQLErrName[mysql.ErrCantOpenLibrary])
+	// ErrCantFindDlEntry is returned when the plugin doesn't export the NewFunction symbol.
+	ErrCantFindDlEntry = terror.ClassUDF.New(mysql.ErrCantFindDlEntry, mysql.MySQLErrName[mysql.ErrCantFindDlEntry])
+	// ErrNotExists is returned when the function to drop doesn't exist.
+	ErrNotExists = terror.ClassUDF.New(mysql.ErrSpDoesNotExist, mysql.MySQLErrName[mysql.ErrSpDoesNotExist])
+	// ErrNativeNameCollision is returned when the function to create has the name of a builtin function.
+	ErrNativeNameCollision = terror.ClassUDF.New(mysql.ErrNativeFctNameCollision, mysql.MySQLErrName[mysql.ErrNativeFctNameCollision])
+)
+
+func init() {
+	udfMySQLErrCodes := map[terror.ErrCode]uint16{
+		mysql.ErrCantInitializeUdf:      mysql.ErrCantInitializeUdf,
+		mysql.ErrUdfNoPaths:             mysql.ErrUdfNoPaths,
+		mysql.ErrUdfExists:              mysql.ErrUdfExists,
+		mysql.ErrCantOpenLibrary:        mysql.ErrCantOpenLibrary,
+		mysql.ErrCantFindDlEntry:        mysql.ErrCantFindDlEntry,
+		mysql.ErrSpDoesNotExist:         mysql.ErrSpDoesNotExist,
+		mysql.ErrNativeFctNameCollision: mysql.ErrNativeFctNameCollision,
+	}
+	terror.ErrClassToMySQLCodes[terror.ClassUDF] = udfMySQLErrCodes
+}
@@ -0,0 +1,89 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udf
+
+import (
+	"testing"
+
+	"github.com/juju/errors"
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testUDFSuite{})
+
+type testUDFSuite struct{}
+
+type constFunction struct {
+	retTp Type
+}
+
+func (f constFunction) ArgTypes() []Type {
+	return nil
+}
+
+func (f constFunction) RetType() Type {
+	return f.retTp
+}
+
+func (f constFunction) Eval(args []interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+func (s *testUDFSuite) TestType(c *C) {
+	defer testleak.AfterTest(c)()
+	for _, tp := range []Type{TypeString, TypeReal, TypeInt, TypeDecimal} {
+		c.Assert(TypeFromEvalType(tp.EvalType()), Equals, tp)
+	}
+	c.Assert(TypeInt.EvalType(), Equals, types.ETInt)
+	c.Assert(TypeDecimal.String(), Equals, "DECIMAL")
+}
+
+func (s *testUDFSuite) TestRegistry(c *C) {
+	defer testleak.AfterTest(c)()
+	Register("Udf_Registry", constFunction{TypeInt})
+	fn, ok := Get("udf_registry")
+	c.Assert(ok, IsTrue)
+	c.Assert(fn.RetType(), Equals, TypeInt)
+	Unregister("UDF_REGISTRY")
+	_, ok = Get("udf_registry")
+	c.Assert(ok, IsFalse)
+}
+
+func (s *testUDFSuite) TestLoad(c *C) {
+	defer testleak.AfterTest(c)()
+	_, err := Load("f", "f.so", TypeString)
+	c.Assert(ErrCantOpenLibrary.Equal(errors.Cause(err)), IsTrue)
+
+	origLoader := Loader
+	Loader = func(name, lib string) (Function, error) {
+		return constFunction{TypeReal}, nil
+	}
+	defer func() { Loader = origLoader }()
+	_, err = Load("f", "/lib/f.so", TypeReal)
+	c.Assert(ErrNoPaths.Equal(err), IsTrue)
+	_, err = Load("f", `lib\f.so`, TypeReal)
+	c.Assert(ErrNoPaths.Equal(err), IsTrue)
+	_, err = Load("f", "f.so", TypeString)
+	c.Assert(ErrCantInitialize.Equal(err), IsTrue)
+	fn, err := Load("f", "f.so", TypeReal)
+	c.Assert(err, IsNil)
+	c.Assert(fn.RetType(), Equals, TypeReal)
+}
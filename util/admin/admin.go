@@ -15,10 +15,13 @@ package admin
 
 import (
 	"io"
+	"math"
 	"reflect"
 	"time"
 
 	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/model"
@@ -237,77 +240,256 @@ func CompareIndexData(txn kv.Transaction, t table.Table, idx table.Index) error
 	return checkRecordAndIndex(txn, t, idx)
 }
 
-func checkIndexAndRecord(txn kv.Transaction, t table.Table, idx table.Index) error {
-	it, err := idx.SeekFirst(txn)
+// CompareIndexDataWithGenCols is like CompareIndexData, but the values of the virtual generated
+// columns in the index aren't stored, they are computed from the records by genExprs, which are
+// keyed by the column IDs.
+func CompareIndexDataWithGenCols(ctx context.Context, txn kv.Transaction, t table.Table, idx table.Index,
+	genExprs map[int64]expression.Expression) error {
+	cols := IndexColumns(t, idx)
+	err := IterIndexEntries(txn, idx, func(entry *RecordData) (bool, error) {
+		row, err := rowWithCols(txn, t, entry.Handle, t.Cols())
+		if kv.ErrNotExist.Equal(err) {
+			return false, errDateNotEqual.Gen("index:%v != record:%v", entry, nil)
+		}
+		if err != nil {
+			return false, errors.Trace(err)
+		}
+		vals, err := indexValuesWithGenCols(ctx, cols, row, genExprs)
+		if err != nil {
+			return false, errors.Trace(err)
+		}
+		// The computed strings aren't decoded from the records, so the values are compared rather than the datums.
+		equal, err := datumsEqual(ctx, entry.Values, indexKeyValues(cols, vals))
+		if err != nil {
+			return false, errors.Trace(err)
+		}
+		if !equal {
+			record := &RecordData{Handle: entry.Handle, Values: vals}
+			return false, errDateNotEqual.Gen("index:%v != record:%v", entry, record)
+		}
+		return true, nil
+	})
 	if err != nil {
 		return errors.Trace(err)
 	}
-	defer it.Close()
 
-	cols := make([]*table.Column, len(idx.Meta().Columns))
-	for i, col := range idx.Meta().Columns {
-		cols[i] = t.Cols()[col.Offset]
-	}
-
-	for {
-		vals1, h, err := it.Next()
-		if terror.ErrorEqual(err, io.EOF) {
-			break
-		} else if err != nil {
-			return errors.Trace(err)
+	return IterRecordsInRange(txn, t, t.Cols(), 0, math.MaxInt64, func(r *RecordData) (bool, error) {
+		vals, err := indexValuesWithGenCols(ctx, cols, r.Values, genExprs)
+		if err != nil {
+			return false, errors.Trace(err)
 		}
+		err = CheckRecordIndex(txn, idx, &RecordData{Handle: r.Handle, Values: vals})
+		return err == nil, errors.Trace(err)
+	})
+}
 
-		vals2, err := rowWithCols(txn, t, h, cols)
-		if kv.ErrNotExist.Equal(err) {
-			record := &RecordData{Handle: h, Values: vals1}
-			err = errDateNotEqual.Gen("index:%v != record:%v", record, nil)
+// indexValuesWithGenCols returns the values of the index columns cols in the row of all the columns,
+// the values of the columns in genExprs are computed.
+func indexValuesWithGenCols(ctx context.Context, cols []*table.Column, row []types.Datum,
+	genExprs map[int64]expression.Expression) ([]types.Datum, error) {
+	vals := make([]types.Datum, len(cols))
+	for i, col := range cols {
+		expr, ok := genExprs[col.ID]
+		if !ok {
+			vals[i] = row[col.Offset]
+			continue
 		}
+		val, err := expr.Eval(types.DatumRow(row))
 		if err != nil {
-			return errors.Trace(err)
+			return nil, errors.Trace(err)
 		}
-		if !reflect.DeepEqual(vals1, vals2) {
-			record1 := &RecordData{Handle: h, Values: vals1}
-			record2 := &RecordData{Handle: h, Values: vals2}
-			return errDateNotEqual.Gen("index:%v != record:%v", record1, record2)
+		if vals[i], err = table.CastValue(ctx, val, col.ToInfo()); err != nil {
+			return nil, errors.Trace(err)
 		}
 	}
+	return vals, nil
+}
 
-	return nil
+func datumsEqual(ctx context.Context, a, b []types.Datum) (bool, error) {
+	if len(a) != len(b) {
+		return false, nil
+	}
+	sc := ctx.GetSessionVars().StmtCtx
+	for i := range a {
+		cmp, err := a[i].CompareDatum(sc, &b[i])
+		if err != nil || cmp != 0 {
+			return false, errors.Trace(err)
+		}
+	}
+	return true, nil
+}
+
+func checkIndexAndRecord(txn kv.Transaction, t table.Table, idx table.Index) error {
+	cols := IndexColumns(t, idx)
+	return IterIndexEntries(txn, idx, func(entry *RecordData) (bool, error) {
+		err := CheckIndexRecord(txn, t, cols, entry)
+		return err == nil, errors.Trace(err)
+	})
 }
 
 func checkRecordAndIndex(txn kv.Transaction, t table.Table, idx table.Index) error {
+	cols := IndexColumns(t, idx)
+	return IterRecordsInRange(txn, t, cols, 0, math.MaxInt64, func(r *RecordData) (bool, error) {
+		err := CheckRecordIndex(txn, idx, r)
+		return err == nil, errors.Trace(err)
+	})
+}
+
+// IndexColumns returns the table columns that make up the index.
+func IndexColumns(t table.Table, idx table.Index) []*table.Column {
 	cols := make([]*table.Column, len(idx.Meta().Columns))
 	for i, col := range idx.Meta().Columns {
 		cols[i] = t.Cols()[col.Offset]
 	}
+	return cols
+}
 
-	startKey := t.RecordKey(0)
-	filterFunc := func(h1 int64, vals1 []types.Datum, cols []*table.Column) (bool, error) {
-		isExist, h2, err := idx.Exist(txn, vals1, h1)
-		if kv.ErrKeyExists.Equal(err) {
-			record1 := &RecordData{Handle: h1, Values: vals1}
-			record2 := &RecordData{Handle: h2, Values: vals1}
-			return false, errDateNotEqual.Gen("index:%v != record:%v", record2, record1)
+// IterRecordsInRange calls fn with the records whose handles are in [begin, end],
+// in ascending order of the handles. Only the values of cols are decoded. The
+// iteration stops when fn returns false or an error.
+func IterRecordsInRange(retriever kv.Retriever, t table.Table, cols []*table.Column, begin, end int64,
+	fn func(r *RecordData) (bool, error)) error {
+	if begin > end {
+		return nil
+	}
+	filterFunc := func(h int64, vals []types.Datum, cols []*table.Column) (bool, error) {
+		if h > end {
+			return false, nil
 		}
-		if err != nil {
-			return false, errors.Trace(err)
+		return fn(&RecordData{Handle: h, Values: vals})
+	}
+	return errors.Trace(iterRecords(retriever, t, t.RecordKey(begin), cols, filterFunc))
+}
+
+// IterIndexEntries calls fn with the entries of the index in the order of the
+// index. The iteration stops when fn returns false or an error.
+func IterIndexEntries(retriever kv.Retriever, idx table.Index, fn func(entry *RecordData) (bool, error)) error {
+	it, err := idx.SeekFirst(retriever)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer it.Close()
+
+	for {
+		vals, h, err := it.Next()
+		if terror.ErrorEqual(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return errors.Trace(err)
 		}
-		if !isExist {
-			record := &RecordData{Handle: h1, Values: vals1}
-			return false, errDateNotEqual.Gen("index:%v != record:%v", nil, record)
+		more, err := fn(&RecordData{Handle: h, Values: vals})
+		if !more || err != nil {
+			return errors.Trace(err)
 		}
-
-		return true, nil
 	}
-	err := iterRecords(txn, t, startKey, cols, filterFunc)
+}
 
+// CheckRecordIndex checks that the index has the entry of the record r, whose
+// values are the values of the index columns. It returns an error which
+// satisfies IsDataNotEqual if the entry is missing or points to another handle.
+func CheckRecordIndex(txn kv.Transaction, idx table.Index, r *RecordData) error {
+	isExist, h, err := idx.Exist(txn, r.Values, r.Handle)
+	if kv.ErrKeyExists.Equal(err) {
+		record := &RecordData{Handle: h, Values: r.Values}
+		return errDateNotEqual.Gen("index:%v != record:%v", record, r)
+	}
 	if err != nil {
 		return errors.Trace(err)
 	}
+	if !isExist {
+		return errDateNotEqual.Gen("index:%v != record:%v", nil, r)
+	}
+	return nil
+}
 
+// CheckIndexRecord checks that the record the index entry points to exists and
+// has the same values as the entry, cols are the index columns. It returns an
+// error which satisfies IsDataNotEqual if they don't match.
+func CheckIndexRecord(retriever kv.Retriever, t table.Table, cols []*table.Column, entry *RecordData) error {
+	vals, err := rowWithCols(retriever, t, entry.Handle, cols)
+	if kv.ErrNotExist.Equal(err) {
+		return errDateNotEqual.Gen("index:%v != record:%v", entry, nil)
+	}
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !reflect.DeepEqual(entry.Values, indexKeyValues(cols, vals)) {
+		record := &RecordData{Handle: entry.Handle, Values: vals}
+		return errDateNotEqual.Gen("index:%v != record:%v", entry, record)
+	}
 	return nil
 }
 
+// indexKeyValues returns the values which are decoded from the index keys of the values of
+// the index columns cols, the strings whose collation isn't binary are their sort keys.
+func indexKeyValues(cols []*table.Column, vals []types.Datum) []types.Datum {
+	keys := make([]types.Datum, len(vals))
+	for i, val := range vals {
+		keys[i] = expression.CollationKeyDatum(val, &cols[i].FieldType)
+	}
+	return keys
+}
+
+// IsDataNotEqual returns true if err is returned because the index data and
+// the record data don't match.
+func IsDataNotEqual(err error) bool {
+	return errDateNotEqual.Equal(errors.Cause(err))
+}
+
+// RecoverRecordIndex adds the missing index entry of the record r, whose values
+// are the values of the index columns cols. The record is checked again in txn
+// first, it returns false if the record has changed or the entry isn't missing
+// anymore. It returns an error if another record takes the entry of a unique index.
+func RecoverRecordIndex(txn kv.Transaction, t table.Table, idx table.Index, cols []*table.Column,
+	r *RecordData) (bool, error) {
+	vals, err := rowWithCols(txn, t, r.Handle, cols)
+	if kv.ErrNotExist.Equal(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	if !reflect.DeepEqual(r.Values, vals) {
+		return false, nil
+	}
+	isExist, _, err := idx.Exist(txn, vals, r.Handle)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	if isExist {
+		return false, nil
+	}
+	if _, err = idx.Create(txn, vals, r.Handle); err != nil {
+		return false, errors.Trace(err)
+	}
+	return true, nil
+}
+
+// CleanupIndexEntry removes the index entry that has no matching record. The
+// entry is checked again in txn first, it returns false if the entry has been
+// removed or has a matching record now.
+func CleanupIndexEntry(txn kv.Transaction, t table.Table, idx table.Index, cols []*table.Column,
+	entry *RecordData) (bool, error) {
+	isExist, h, err := idx.Exist(txn, entry.Values, entry.Handle)
+	if err != nil && !kv.ErrKeyExists.Equal(err) {
+		return false, errors.Trace(err)
+	}
+	if !isExist || h != entry.Handle {
+		return false, nil
+	}
+	err = CheckIndexRecord(txn, t, cols, entry)
+	if err == nil {
+		return false, nil
+	}
+	if !IsDataNotEqual(err) {
+		return false, errors.Trace(err)
+	}
+	if err = idx.Delete(txn, entry.Values, entry.Handle); err != nil {
+		return false, errors.Trace(err)
+	}
+	return true, nil
+}
+
 func scanTableData(retriever kv.Retriever, t table.Table, cols []*table.Column, startHandle, limit int64) (
 	[]*RecordData, int64, error) {
 	var records []*RecordData
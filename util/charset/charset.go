@@ -170,6 +170,17 @@ func GetCollations() []*Collation {
 	return collations
 }
 
+// GetCollationByName returns the collation by its name.
+func GetCollationByName(name string) (*Collation, error) {
+	name = strings.ToLower(name)
+	for _, collation := range collations {
+		if collation.Name == name {
+			return collation, nil
+		}
+	}
+	return nil, errors.Errorf("Unknown collation %s", name)
+}
+
 const (
 	// CharsetBin is used for marking binary charset.
 	CharsetBin = "binary"
@@ -29,7 +29,7 @@ var _ types.Row = Row{}
 // Values are appended in compact format and can be directly accessed without decoding.
 // When the chunk is done processing, we can reuse the allocated memory by resetting it.
 type Chunk struct {
-	columns []*column
+	columns []*Column
 	// numVirtualRows indicates the number of virtual rows, witch have zero columns.
 	// It is used only when this Chunk doesn't hold any data, i.e. "len(columns)==0".
 	numVirtualRows int
@@ -43,7 +43,7 @@ const (
 // NewChunk creates a new chunk with field types.
 func NewChunk(fields []*types.FieldType) *Chunk {
 	chk := new(Chunk)
-	chk.columns = make([]*column, 0, len(fields))
+	chk.columns = make([]*Column, 0, len(fields))
 	chk.numVirtualRows = 0
 	for _, f := range fields {
 		chk.addColumnByFieldType(f, InitialCapacity)
@@ -53,47 +53,27 @@ func NewChunk(fields []*types.FieldType) *Chunk {
 
 // addFixedLenColumn adds a fixed length column with elemLen and initial data capacity.
 func (c *Chunk) addFixedLenColumn(elemLen, initCap int) {
-	c.columns = append(c.columns, &column{
-		elemBuf:    make([]byte, elemLen),
-		data:       make([]byte, 0, initCap*elemLen),
-		nullBitmap: make([]byte, 0, initCap>>3),
-	})
+	c.columns = append(c.columns, newFixedLenColumn(elemLen, initCap))
 }
 
 // addVarLenColumn adds a variable length column with initial data capacity.
 func (c *Chunk) addVarLenColumn(initCap int) {
-	c.columns = append(c.columns, &column{
-		offsets:    make([]int32, 1, initCap+1),
-		data:       make([]byte, 0, initCap*4),
-		nullBitmap: make([]byte, 0, initCap>>3),
-	})
+	c.columns = append(c.columns, newVarLenColumn(initCap))
 }
 
 // addInterfaceColumn adds an interface column which holds element as interface.
 func (c *Chunk) addInterfaceColumn(initCap int) {
-	c.columns = append(c.columns, &column{
-		ifaces:     make([]interface{}, 0, initCap),
-		nullBitmap: make([]byte, 0, initCap>>3),
-	})
+	c.columns = append(c.columns, newInterfaceColumn(initCap))
 }
 
 // addColumnByFieldType adds a column by field type.
 func (c *Chunk) addColumnByFieldType(fieldTp *types.FieldType, initCap int) {
-	switch fieldTp.Tp {
-	case mysql.TypeFloat:
-		c.addFixedLenColumn(4, initCap)
-	case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong,
-		mysql.TypeDouble, mysql.TypeYear:
-		c.addFixedLenColumn(8, initCap)
-	case mysql.TypeDuration:
-		c.addFixedLenColumn(16, initCap)
-	case mysql.TypeNewDecimal:
-		c.addFixedLenColumn(types.MyDecimalStructSize, initCap)
-	case mysql.TypeDate, mysql.TypeDatetime, mysql.TypeTimestamp:
-		c.addInterfaceColumn(initCap)
-	default:
-		c.addVarLenColumn(initCap)
-	}
+	c.columns = append(c.columns, NewColumn(fieldTp, initCap))
+}
+
+// Column returns the column with the colIdx.
+func (c *Chunk) Column(colIdx int) *Column {
+	return c.columns[colIdx]
 }
 
 // SwapColumns swaps columns with another Chunk.
@@ -278,7 +258,8 @@ func (c *Chunk) AppendJSON(colIdx int, j json.BinaryJSON) {
 	c.columns[colIdx].appendJSON(j)
 }
 
-type column struct {
+// Column stores one column of a Chunk, the null values are recorded in a bitmap.
+type Column struct {
 	length     int
 	nullCount  int
 	nullBitmap []byte
@@ -288,19 +269,19 @@ type column struct {
 	ifaces     []interface{}
 }
 
-func (c *column) isFixed() bool {
+func (c *Column) isFixed() bool {
 	return c.elemBuf != nil
 }
 
-func (c *column) isVarlen() bool {
+func (c *Column) isVarlen() bool {
 	return c.offsets != nil
 }
 
-func (c *column) isInterface() bool {
+func (c *Column) isInterface() bool {
 	return c.ifaces != nil
 }
 
-func (c *column) reset() {
+func (c *Column) reset() {
 	c.length = 0
 	c.nullCount = 0
 	c.nullBitmap = c.nullBitmap[:0]
@@ -312,12 +293,12 @@ func (c *column) reset() {
 	c.ifaces = c.ifaces[:0]
 }
 
-func (c *column) isNull(rowIdx int) bool {
+func (c *Column) isNull(rowIdx int) bool {
 	nullByte := c.nullBitmap[rowIdx/8]
 	return nullByte&(1<<(uint(rowIdx)&7)) == 0
 }
 
-func (c *column) appendNullBitmap(on bool) {
+func (c *Column) appendNullBitmap(on bool) {
 	idx := c.length >> 3
 	if idx >= len(c.nullBitmap) {
 		c.nullBitmap = append(c.nullBitmap, 0)
@@ -330,7 +311,7 @@ func (c *column) appendNullBitmap(on bool) {
 	}
 }
 
-func (c *column) appendNull() {
+func (c *Column) appendNull() {
 	c.appendNullBitmap(false)
 	if c.isFixed() {
 		c.data = append(c.data, c.elemBuf...)
@@ -342,65 +323,65 @@ func (c *column) appendNull() {
 	c.length++
 }
 
-func (c *column) finishAppendFixed() {
+func (c *Column) finishAppendFixed() {
 	c.data = append(c.data, c.elemBuf...)
 	c.appendNullBitmap(true)
 	c.length++
 }
 
-func (c *column) appendInt64(i int64) {
+func (c *Column) appendInt64(i int64) {
 	*(*int64)(unsafe.Pointer(&c.elemBuf[0])) = i
 	c.finishAppendFixed()
 }
 
-func (c *column) appendUint64(u uint64) {
+func (c *Column) appendUint64(u uint64) {
 	*(*uint64)(unsafe.Pointer(&c.elemBuf[0])) = u
 	c.finishAppendFixed()
 }
 
-func (c *column) appendFloat32(f float32) {
+func (c *Column) appendFloat32(f float32) {
 	*(*float32)(unsafe.Pointer(&c.elemBuf[0])) = f
 	c.finishAppendFixed()
 }
 
-func (c *column) appendFloat64(f float64) {
+func (c *Column) appendFloat64(f float64) {
 	*(*float64)(unsafe.Pointer(&c.elemBuf[0])) = f
 	c.finishAppendFixed()
 }
 
-func (c *column) finishAppendVar() {
+func (c *Column) finishAppendVar() {
 	c.appendNullBitmap(true)
 	c.offsets = append(c.offsets, int32(len(c.data)))
 	c.length++
 }
 
-func (c *column) appendString(str string) {
+func (c *Column) appendString(str string) {
 	c.data = append(c.data, str...)
 	c.finishAppendVar()
 }
 
-func (c *column) appendBytes(b []byte) {
+func (c *Column) appendBytes(b []byte) {
 	c.data = append(c.data, b...)
 	c.finishAppendVar()
 }
 
-func (c *column) appendInterface(o interface{}) {
+func (c *Column) appendInterface(o interface{}) {
 	c.ifaces = append(c.ifaces, o)
 	c.appendNullBitmap(true)
 	c.length++
 }
 
-func (c *column) appendDuration(dur types.Duration) {
+func (c *Column) appendDuration(dur types.Duration) {
 	*(*types.Duration)(unsafe.Pointer(&c.elemBuf[0])) = dur
 	c.finishAppendFixed()
 }
 
-func (c *column) appendMyDecimal(dec *types.MyDecimal) {
+func (c *Column) appendMyDecimal(dec *types.MyDecimal) {
 	*(*types.MyDecimal)(unsafe.Pointer(&c.elemBuf[0])) = *dec
 	c.finishAppendFixed()
 }
 
-func (c *column) appendNameValue(name string, val uint64) {
+func (c *Column) appendNameValue(name string, val uint64) {
 	var buf [8]byte
 	*(*uint64)(unsafe.Pointer(&buf[0])) = val
 	c.data = append(c.data, buf[:]...)
@@ -408,7 +389,7 @@ func (c *column) appendNameValue(name string, val uint64) {
 	c.finishAppendVar()
 }
 
-func (c *column) appendJSON(j json.BinaryJSON) {
+func (c *Column) appendJSON(j json.BinaryJSON) {
 	c.data = append(c.data, j.TypeCode)
 	c.data = append(c.data, j.Value...)
 	c.finishAppendVar()
@@ -555,7 +536,7 @@ func (r Row) GetDatum(colIdx int, tp *types.FieldType) types.Datum {
 			d.SetFloat64(r.GetFloat64(colIdx))
 		}
 	case mysql.TypeVarchar, mysql.TypeVarString, mysql.TypeString,
-		mysql.TypeBlob, mysql.TypeTinyBlob, mysql.TypeMediumBlob, mysql.TypeLongBlob:
+		mysql.TypeBlob, mysql.TypeTinyBlob, mysql.TypeMediumBlob, mysql.TypeLongBlob, mysql.TypeGeometry:
 		if !r.IsNull(colIdx) {
 			d.SetBytes(r.GetBytes(colIdx))
 		}
@@ -0,0 +1,237 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chunk
+
+import (
+	"math/bits"
+	"reflect"
+	"unsafe"
+
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/hack"
+)
+
+// NewColumn creates a column which can hold the values of fieldTp, initCap is the initial row capacity.
+func NewColumn(fieldTp *types.FieldType, initCap int) *Column {
+	switch fieldTp.Tp {
+	case mysql.TypeFloat:
+		return newFixedLenColumn(4, initCap)
+	case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong,
+		mysql.TypeDouble, mysql.TypeYear:
+		return newFixedLenColumn(8, initCap)
+	case mysql.TypeDuration:
+		return newFixedLenColumn(16, initCap)
+	case mysql.TypeNewDecimal:
+		return newFixedLenColumn(types.MyDecimalStructSize, initCap)
+	case mysql.TypeDate, mysql.TypeDatetime, mysql.TypeTimestamp:
+		return newInterfaceColumn(initCap)
+	default:
+		return newVarLenColumn(initCap)
+	}
+}
+
+func newFixedLenColumn(elemLen, initCap int) *Column {
+	return &Column{
+		elemBuf:    make([]byte, elemLen),
+		data:       make([]byte, 0, initCap*elemLen),
+		nullBitmap: make([]byte, 0, initCap>>3),
+	}
+}
+
+func newVarLenColumn(initCap int) *Column {
+	return &Column{
+		offsets:    make([]int32, 1, initCap+1),
+		data:       make([]byte, 0, initCap*4),
+		nullBitmap: make([]byte, 0, initCap>>3),
+	}
+}
+
+func newInterfaceColumn(initCap int) *Column {
+	return &Column{
+		ifaces:     make([]interface{}, 0, initCap),
+		nullBitmap: make([]byte, 0, initCap>>3),
+	}
+}
+
+// Len returns the number of rows in the column.
+func (c *Column) Len() int {
+	return c.length
+}
+
+// Reset resets the column, so the memory it allocated can be reused.
+func (c *Column) Reset() {
+	c.reset()
+}
+
+// IsNull returns whether the value of the rowIdx-th row is null.
+func (c *Column) IsNull(rowIdx int) bool {
+	return c.isNull(rowIdx)
+}
+
+// SetNull sets the null flag of the rowIdx-th row, the row must have been allocated.
+func (c *Column) SetNull(rowIdx int, isNull bool) {
+	mask := byte(1 << (uint(rowIdx) & 7))
+	wasNull := c.nullBitmap[rowIdx>>3]&mask == 0
+	if isNull {
+		c.nullBitmap[rowIdx>>3] &^= mask
+	} else {
+		c.nullBitmap[rowIdx>>3] |= mask
+	}
+	if isNull && !wasNull {
+		c.nullCount++
+	} else if !isNull && wasNull {
+		c.nullCount--
+	}
+}
+
+// MergeNulls makes a row of the column null if it is null in any of the cols.
+// All the cols must have the same length as the column.
+func (c *Column) MergeNulls(cols ...*Column) {
+	for _, col := range cols {
+		if col.nullCount == 0 {
+			continue
+		}
+		for i := range c.nullBitmap {
+			c.nullBitmap[i] &= col.nullBitmap[i]
+		}
+	}
+	c.nullCount = c.length
+	for i := 0; i < c.length>>3; i++ {
+		c.nullCount -= bits.OnesCount8(c.nullBitmap[i])
+	}
+	if c.length&7 != 0 {
+		c.nullCount -= bits.OnesCount8(c.nullBitmap[c.length>>3] & (byte(1<<uint(c.length&7)) - 1))
+	}
+}
+
+// resizeFixed resets the column to hold n not null elements of elemLen bytes, the old values are not kept.
+func (c *Column) resizeFixed(n, elemLen int) {
+	if cap(c.data) < n*elemLen {
+		c.data = make([]byte, n*elemLen)
+	} else {
+		c.data = c.data[:n*elemLen]
+	}
+	numBytes := (n + 7) >> 3
+	if cap(c.nullBitmap) < numBytes {
+		c.nullBitmap = make([]byte, numBytes)
+	} else {
+		c.nullBitmap = c.nullBitmap[:numBytes]
+	}
+	for i := range c.nullBitmap {
+		c.nullBitmap[i] = 0xFF
+	}
+	// The bits after the last row must be zero, appendNullBitmap relies on it.
+	if n&7 != 0 {
+		c.nullBitmap[numBytes-1] = byte(1<<uint(n&7)) - 1
+	}
+	c.length = n
+	c.nullCount = 0
+}
+
+// ResizeInt64 resets the column to hold n not null int64 values, the old values are not kept.
+func (c *Column) ResizeInt64(n int) {
+	c.resizeFixed(n, 8)
+}
+
+// ResizeFloat64 resets the column to hold n not null float64 values, the old values are not kept.
+func (c *Column) ResizeFloat64(n int) {
+	c.resizeFixed(n, 8)
+}
+
+// Int64s returns the int64 values of the column, the slice shares the memory with the column.
+func (c *Column) Int64s() []int64 {
+	var res []int64
+	if c.length == 0 {
+		return res
+	}
+	hdr := (*reflect.SliceHeader)(unsafe.Pointer(&res))
+	hdr.Data = uintptr(unsafe.Pointer(&c.data[0]))
+	hdr.Len = c.length
+	hdr.Cap = c.length
+	return res
+}
+
+// Float64s returns the float64 values of the column, the slice shares the memory with the column.
+func (c *Column) Float64s() []float64 {
+	var res []float64
+	if c.length == 0 {
+		return res
+	}
+	hdr := (*reflect.SliceHeader)(unsafe.Pointer(&res))
+	hdr.Data = uintptr(unsafe.Pointer(&c.data[0]))
+	hdr.Len = c.length
+	hdr.Cap = c.length
+	return res
+}
+
+// GetString returns the string value of the rowIdx-th row.
+func (c *Column) GetString(rowIdx int) string {
+	return hack.String(c.data[c.offsets[rowIdx]:c.offsets[rowIdx+1]])
+}
+
+// GetBytes returns the bytes value of the rowIdx-th row.
+func (c *Column) GetBytes(rowIdx int) []byte {
+	return c.data[c.offsets[rowIdx]:c.offsets[rowIdx+1]]
+}
+
+// AppendNull appends a null value to the column.
+func (c *Column) AppendNull() {
+	c.appendNull()
+}
+
+// AppendInt64 appends an int64 value to the column.
+func (c *Column) AppendInt64(i int64) {
+	c.appendInt64(i)
+}
+
+// AppendFloat64 appends a float64 value to the column.
+func (c *Column) AppendFloat64(f float64) {
+	c.appendFloat64(f)
+}
+
+// AppendString appends a string value to the column.
+func (c *Column) AppendString(str string) {
+	c.appendString(str)
+}
+
+// AppendBytes appends a bytes value to the column.
+func (c *Column) AppendBytes(b []byte) {
+	c.appendBytes(b)
+}
+
+// AppendColumn appends all the rows of src, which must have the same layout, to the column.
+func (c *Column) AppendColumn(src *Column) {
+	if src.isFixed() {
+		c.data = append(c.data, src.data[:src.length*len(src.elemBuf)]...)
+	} else if src.isVarlen() {
+		base := c.offsets[len(c.offsets)-1]
+		c.data = append(c.data, src.data[:src.offsets[src.length]]...)
+		for _, offset := range src.offsets[1 : src.length+1] {
+			c.offsets = append(c.offsets, base+offset)
+		}
+	} else {
+		c.ifaces = append(c.ifaces, src.ifaces[:src.length]...)
+	}
+	for i := 0; i < src.length; i++ {
+		c.appendNullBitmap(!src.isNull(i))
+		c.length++
+	}
+}
+
+// CopyFrom makes the column a copy of src, which must have the same layout.
+func (c *Column) CopyFrom(src *Column) {
+	c.reset()
+	c.AppendColumn(src)
+}
@@ -0,0 +1,81 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chunk
+
+import (
+	"github.com/pingcap/check"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/types"
+)
+
+func (s *testChunkSuite) TestColumnVectorAccess(c *check.C) {
+	col := NewColumn(types.NewFieldType(mysql.TypeLonglong), 0)
+	col.ResizeInt64(11)
+	c.Assert(col.Len(), check.Equals, 11)
+	vals := col.Int64s()
+	for i := range vals {
+		vals[i] = int64(i)
+		if i%3 == 0 {
+			col.SetNull(i, true)
+		}
+	}
+	col.SetNull(3, false)
+	c.Assert(col.nullCount, check.Equals, 3)
+
+	other := NewColumn(types.NewFieldType(mysql.TypeLonglong), 0)
+	for i := 0; i < 11; i++ {
+		if i%2 == 0 {
+			other.AppendNull()
+		} else {
+			other.AppendInt64(int64(i))
+		}
+	}
+	col.MergeNulls(other)
+	c.Assert(col.nullCount, check.Equals, 7)
+	for i := 0; i < 11; i++ {
+		c.Assert(col.IsNull(i), check.Equals, (i%3 == 0 && i != 3) || i%2 == 0)
+	}
+
+	// The bits after the last row are zero, so the appended values keep their null flags.
+	col.AppendNull()
+	col.AppendInt64(12)
+	c.Assert(col.IsNull(11), check.IsTrue)
+	c.Assert(col.IsNull(12), check.IsFalse)
+	c.Assert(col.Int64s()[12], check.Equals, int64(12))
+
+	chk := NewChunk([]*types.FieldType{types.NewFieldType(mysql.TypeLonglong)})
+	chk.AppendInt64(0, -1)
+	chk.Column(0).AppendColumn(col)
+	c.Assert(chk.NumRows(), check.Equals, 14)
+	c.Assert(chk.GetRow(0).GetInt64(0), check.Equals, int64(-1))
+	c.Assert(chk.GetRow(1).IsNull(0), check.IsTrue)
+	c.Assert(chk.GetRow(2).GetInt64(0), check.Equals, int64(1))
+	c.Assert(chk.GetRow(13).GetInt64(0), check.Equals, int64(12))
+
+	strs := NewColumn(types.NewFieldType(mysql.TypeVarString), 0)
+	strs.AppendString("a")
+	strs.AppendNull()
+	strs.AppendBytes([]byte("bc"))
+	strChk := NewChunk([]*types.FieldType{types.NewFieldType(mysql.TypeVarString)})
+	strChk.AppendString(0, "x")
+	strChk.Column(0).AppendColumn(strs)
+	c.Assert(strChk.GetRow(1).GetString(0), check.Equals, "a")
+	c.Assert(strChk.GetRow(2).IsNull(0), check.IsTrue)
+	c.Assert(strChk.GetRow(3).GetString(0), check.Equals, "bc")
+
+	strs.CopyFrom(strChk.Column(0))
+	c.Assert(strs.Len(), check.Equals, 4)
+	c.Assert(strs.GetString(0), check.Equals, "x")
+	c.Assert(strs.GetString(3), check.Equals, "bc")
+}
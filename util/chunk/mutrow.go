@@ -81,7 +81,7 @@ func zeroValForType(tp *types.FieldType) interface{} {
 		return int64(0)
 	case mysql.TypeString, mysql.TypeVarString, mysql.TypeVarchar:
 		return ""
-	case mysql.TypeBlob, mysql.TypeTinyBlob, mysql.TypeMediumBlob, mysql.TypeLongBlob:
+	case mysql.TypeBlob, mysql.TypeTinyBlob, mysql.TypeMediumBlob, mysql.TypeLongBlob, mysql.TypeGeometry:
 		return []byte{}
 	case mysql.TypeDuration:
 		return types.ZeroDuration
@@ -106,7 +106,7 @@ func zeroValForType(tp *types.FieldType) interface{} {
 	}
 }
 
-func makeMutRowColumn(in interface{}) *column {
+func makeMutRowColumn(in interface{}) *Column {
 	switch x := in.(type) {
 	case nil:
 		col := makeMutRowUint64Column(uint64(0))
@@ -160,9 +160,9 @@ func makeMutRowColumn(in interface{}) *column {
 	}
 }
 
-func newMutRowFixedLenColumn(elemSize int) *column {
+func newMutRowFixedLenColumn(elemSize int) *Column {
 	buf := make([]byte, elemSize+1)
-	col := &column{
+	col := &Column{
 		length:     1,
 		elemBuf:    buf[:elemSize],
 		data:       buf[:elemSize],
@@ -172,9 +172,9 @@ func newMutRowFixedLenColumn(elemSize int) *column {
 	return col
 }
 
-func newMutRowVarLenColumn(valSize int) *column {
+func newMutRowVarLenColumn(valSize int) *Column {
 	buf := make([]byte, valSize+1)
-	col := &column{
+	col := &Column{
 		length:     1,
 		offsets:    []int32{0, int32(valSize)},
 		data:       buf[:valSize],
@@ -184,21 +184,21 @@ func newMutRowVarLenColumn(valSize int) *column {
 	return col
 }
 
-func makeMutRowUint64Column(val uint64) *column {
+func makeMutRowUint64Column(val uint64) *Column {
 	col := newMutRowFixedLenColumn(8)
 	*(*uint64)(unsafe.Pointer(&col.data[0])) = val
 	return col
 }
 
-func makeMutRowBytesColumn(bin []byte) *column {
+func makeMutRowBytesColumn(bin []byte) *Column {
 	col := newMutRowVarLenColumn(len(bin))
 	copy(col.data, bin)
 	col.nullBitmap[0] = 1
 	return col
 }
 
-func makeMutRowInterfaceColumn(in interface{}) *column {
-	col := &column{
+func makeMutRowInterfaceColumn(in interface{}) *Column {
+	col := &Column{
 		length:     1,
 		nullBitmap: []byte{1},
 		ifaces:     []interface{}{in},
@@ -314,7 +314,7 @@ func (mr MutRow) SetDatum(colIdx int, d types.Datum) {
 	col.nullBitmap[0] = 1
 }
 
-func setMutRowBytes(col *column, bin []byte) {
+func setMutRowBytes(col *Column, bin []byte) {
 	if len(col.data) >= len(bin) {
 		col.data = col.data[:len(bin)]
 	} else {
@@ -326,7 +326,7 @@ func setMutRowBytes(col *column, bin []byte) {
 	col.offsets[1] = int32(len(bin))
 }
 
-func setMutRowNameValue(col *column, name string, val uint64) {
+func setMutRowNameValue(col *Column, name string, val uint64) {
 	dataLen := len(name) + 8
 	if len(col.data) >= dataLen {
 		col.data = col.data[:dataLen]
@@ -340,7 +340,7 @@ func setMutRowNameValue(col *column, name string, val uint64) {
 	col.offsets[1] = int32(dataLen)
 }
 
-func setMutRowJSON(col *column, j json.BinaryJSON) {
+func setMutRowJSON(col *Column, j json.BinaryJSON) {
 	dataLen := len(j.Value) + 1
 	if len(col.data) >= dataLen {
 		col.data = col.data[:dataLen]
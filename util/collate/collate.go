@@ -0,0 +1,174 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collate
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"github.com/pingcap/tidb/util/charset"
+)
+
+// newCollationEnabled is 1 if the strings are compared by their collations, or they are compared
+// by their bytes, which is how the clusters bootstrapped by the older versions work.
+var newCollationEnabled int32
+
+// NewCollationEnabled returns whether the strings are compared by their collations.
+func NewCollationEnabled() bool {
+	return atomic.LoadInt32(&newCollationEnabled) == 1
+}
+
+// SetNewCollationEnabled sets whether the strings are compared by their collations. It's decided
+// when the cluster is bootstrapped and can't be changed later, because the index keys depend on it.
+func SetNewCollationEnabled(enabled bool) {
+	if enabled {
+		atomic.StoreInt32(&newCollationEnabled, 1)
+	} else {
+		atomic.StoreInt32(&newCollationEnabled, 0)
+	}
+}
+
+// Collator compares the strings of a collation.
+type Collator interface {
+	// Compare returns an integer comparing the strings a and b.
+	Compare(a, b string) int
+	// Key returns the sort key of the string, the bytes comparison of the sort keys
+	// is the same as the comparison of the strings.
+	Key(str string) []byte
+}
+
+var (
+	binCollatorInstance        = &binCollator{}
+	binPaddingCollatorInstance = &binPaddingCollator{}
+	generalCICollatorInstance  = &generalCICollator{}
+)
+
+// GetCollator returns the collator of the collation. The strings are compared by their bytes
+// if the new collations aren't enabled. The unsupported collations, which are rejected by
+// IsSupportedCollation, fall back to the collator of the _bin collations.
+func GetCollator(collate string) Collator {
+	if !NewCollationEnabled() {
+		return binCollatorInstance
+	}
+	if collator := getSupportedCollator(collate); collator != nil {
+		return collator
+	}
+	return binPaddingCollatorInstance
+}
+
+// IsSupportedCollation returns whether the collation can be used when the new collations are enabled.
+// Only the binary, the _bin and the utf8 and utf8mb4 _general_ci collations have collators, the others
+// are rejected instead of being compared by another collation, because their sort keys are persisted
+// in the indexes. The _unicode_ci collations are among them, they need the weights of the Unicode
+// Collation Algorithm 4.0.0 which aren't shipped.
+func IsSupportedCollation(collate string) bool {
+	return !NewCollationEnabled() || getSupportedCollator(collate) != nil
+}
+
+// getSupportedCollator returns the collator of the collation, or nil if the collation isn't supported.
+func getSupportedCollator(collate string) Collator {
+	collate = strings.ToLower(collate)
+	switch {
+	case collate == charset.CollationBin || collate == "":
+		return binCollatorInstance
+	case strings.HasSuffix(collate, "_bin"):
+		return binPaddingCollatorInstance
+	case collate == "utf8_general_ci" || collate == "utf8mb4_general_ci":
+		return generalCICollatorInstance
+	}
+	return nil
+}
+
+// IsBinCollation returns whether the strings of the collation are compared by their bytes,
+// so the sort keys of the strings are the strings themselves.
+func IsBinCollation(collate string) bool {
+	return GetCollator(collate) == binCollatorInstance
+}
+
+// IsPaddingBinCollation returns whether the strings of the collation are compared by their bytes
+// without the trailing spaces, so the order of the sort keys is the order of the strings.
+func IsPaddingBinCollation(collate string) bool {
+	return GetCollator(collate) == binPaddingCollatorInstance
+}
+
+// binCollator compares the strings by their bytes, it's used by the binary strings.
+type binCollator struct{}
+
+// Compare implements Collator interface.
+func (c *binCollator) Compare(a, b string) int {
+	return strings.Compare(a, b)
+}
+
+// Key implements Collator interface.
+func (c *binCollator) Key(str string) []byte {
+	return []byte(str)
+}
+
+// binPaddingCollator compares the strings by their bytes, but the trailing spaces are ignored
+// like MySQL's PAD SPACE collations, it's used by the _bin collations.
+type binPaddingCollator struct{}
+
+// Compare implements Collator interface.
+func (c *binPaddingCollator) Compare(a, b string) int {
+	return strings.Compare(truncateTailingSpace(a), truncateTailingSpace(b))
+}
+
+// Key implements Collator interface.
+func (c *binPaddingCollator) Key(str string) []byte {
+	return []byte(truncateTailingSpace(str))
+}
+
+// generalCICollator is the case insensitive collator of the _general_ci collations, every
+// character is compared by its weight.
+type generalCICollator struct{}
+
+// Compare implements Collator interface.
+func (c *generalCICollator) Compare(a, b string) int {
+	return compareWeights(generalCIWeights(a), generalCIWeights(b))
+}
+
+// Key implements Collator interface.
+func (c *generalCICollator) Key(str string) []byte {
+	return weightsToKey(generalCIWeights(str))
+}
+
+func truncateTailingSpace(str string) string {
+	return strings.TrimRight(str, " ")
+}
+
+func compareWeights(a, b []uint16) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case len(a) < len(b):
+		return -1
+	case len(a) > len(b):
+		return 1
+	}
+	return 0
+}
+
+func weightsToKey(weights []uint16) []byte {
+	key := make([]byte, 0, len(weights)*2)
+	for _, w := range weights {
+		key = append(key, byte(w>>8), byte(w))
+	}
+	return key
+}
@@ -0,0 +1,91 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collate
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testCollateSuite{})
+
+type testCollateSuite struct {
+}
+
+func (s *testCollateSuite) TestCollator(c *C) {
+	defer testleak.AfterTest(c)()
+	SetNewCollationEnabled(true)
+	defer SetNewCollationEnabled(false)
+
+	tests := []struct {
+		collate string
+		a       string
+		b       string
+		cmp     int
+	}{
+		{"binary", "a", "A", 1},
+		{"binary", "a ", "a", 1},
+		{"utf8mb4_bin", "a ", "a", 0},
+		{"utf8mb4_bin", "a", "A", 1},
+		{"utf8mb4_bin", "a\t", "a", 1},
+		{"utf8mb4_general_ci", "a", "A", 0},
+		{"utf8mb4_general_ci", "abc ", "ABC", 0},
+		{"utf8mb4_general_ci", "Å", "a", 0},
+		{"utf8mb4_general_ci", "ß", "s", 0},
+		{"utf8mb4_general_ci", "ß", "ss", -1},
+		{"utf8mb4_general_ci", "ā", "a", 0},
+		{"utf8mb4_general_ci", "Ø", "o", 1},
+		{"utf8mb4_general_ci", "😀", "😃", 0},
+		{"utf8mb4_general_ci", "a", "b", -1},
+		{"utf8mb4_general_ci", "ab", "a", 1},
+		{"utf8_general_ci", "Z", "z", 0},
+	}
+	for _, t := range tests {
+		collator := GetCollator(t.collate)
+		comment := Commentf("%s: %q vs %q", t.collate, t.a, t.b)
+		c.Assert(collator.Compare(t.a, t.b), Equals, t.cmp, comment)
+		c.Assert(collator.Compare(t.b, t.a), Equals, -t.cmp, comment)
+		c.Assert(bytes.Compare(collator.Key(t.a), collator.Key(t.b)), Equals, t.cmp, comment)
+	}
+	c.Assert(IsBinCollation("binary"), IsTrue)
+	c.Assert(IsBinCollation("utf8_bin"), IsFalse)
+	c.Assert(IsBinCollation("utf8_general_ci"), IsFalse)
+	c.Assert(IsPaddingBinCollation("utf8_bin"), IsTrue)
+	c.Assert(IsPaddingBinCollation("utf8_general_ci"), IsFalse)
+	c.Assert(IsSupportedCollation("utf8mb4_general_ci"), IsTrue)
+	c.Assert(IsSupportedCollation("latin1_bin"), IsTrue)
+	c.Assert(IsSupportedCollation("binary"), IsTrue)
+	c.Assert(IsSupportedCollation("utf8mb4_unicode_ci"), IsFalse)
+	c.Assert(IsSupportedCollation("utf8_unicode_ci"), IsFalse)
+	c.Assert(IsSupportedCollation("latin1_swedish_ci"), IsFalse)
+	c.Assert(IsSupportedCollation("utf8mb4_spanish_ci"), IsFalse)
+	c.Assert(IsSupportedCollation("latin1_general_ci"), IsFalse)
+
+	// The strings are compared by their bytes when the new collations are disabled.
+	SetNewCollationEnabled(false)
+	collator := GetCollator("utf8mb4_general_ci")
+	c.Assert(collator.Compare("a", "A"), Equals, 1)
+	c.Assert(collator.Key("a "), DeepEquals, []byte("a "))
+	c.Assert(IsBinCollation("utf8_general_ci"), IsTrue)
+	c.Assert(IsPaddingBinCollation("utf8_bin"), IsFalse)
+	c.Assert(IsSupportedCollation("utf8mb4_unicode_ci"), IsTrue)
+}
@@ -0,0 +1,64 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collate
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// replacementWeight is the weight of the characters out of the BMP, they are all equal in
+// the _general_ci collations of MySQL.
+const replacementWeight = 0xFFFD
+
+// latin1Weights are the _general_ci weights of the Latin-1 Supplement letters from U+00C0,
+// the accented letters are equal to the letters without the accents.
+var latin1Weights = [64]uint16{
+	'A', 'A', 'A', 'A', 'A', 'A', 0xC6, 'C', 'E', 'E', 'E', 'E', 'I', 'I', 'I', 'I',
+	0xD0, 'N', 'O', 'O', 'O', 'O', 'O', 0xD7, 0xD8, 'U', 'U', 'U', 'U', 'Y', 0xDE, 'S',
+	'A', 'A', 'A', 'A', 'A', 'A', 0xC6, 'C', 'E', 'E', 'E', 'E', 'I', 'I', 'I', 'I',
+	0xD0, 'N', 'O', 'O', 'O', 'O', 'O', 0xF7, 0xD8, 'U', 'U', 'U', 'U', 'Y', 0xDE, 'Y',
+}
+
+// latinExtendedA are the base letters of the Latin Extended-A letters from U+0100, which are
+// equal to them. The ligatures are zeros, they are weighed by their upper cases.
+const latinExtendedA = "" +
+	"AAAAAACCCCCCCCDDDDEEEEEEEEEEGGGGGGGGHHHHIIIIIIIIII\x00\x00JJKKKLLLLLLLLLL" +
+	"NNNNNNNNNOOOOOO\x00\x00RRRRRRSSSSSSSSTTTTTTUUUUUUUUUUUUWWYYYZZZZZZS"
+
+func generalCIWeight(r rune) uint16 {
+	switch {
+	case r > 0xFFFF:
+		return replacementWeight
+	case r >= 0xC0 && r <= 0xFF:
+		return latin1Weights[r-0xC0]
+	case r >= 0x100 && r < 0x100+rune(len(latinExtendedA)) && latinExtendedA[r-0x100] != 0:
+		return uint16(latinExtendedA[r-0x100])
+	}
+	if u := unicode.ToUpper(r); u <= 0xFFFF {
+		return uint16(u)
+	}
+	return uint16(r)
+}
+
+func generalCIWeights(str string) []uint16 {
+	str = truncateTailingSpace(str)
+	weights := make([]uint16, 0, len(str))
+	for len(str) > 0 {
+		r, size := utf8.DecodeRuneInString(str)
+		str = str[size:]
+		weights = append(weights, generalCIWeight(r))
+	}
+	return weights
+}
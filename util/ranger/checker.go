@@ -18,6 +18,7 @@ import (
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/collate"
 )
 
 // conditionChecker checks if this condition can be pushed to index plan.
@@ -40,6 +41,9 @@ func (c *conditionChecker) check(condition expression.Expression) bool {
 }
 
 func (c *conditionChecker) checkScalarFunction(scalar *expression.ScalarFunction) bool {
+	if !c.checkCollation(scalar) {
+		return false
+	}
 	switch scalar.FuncName.L {
 	case ast.LogicOr, ast.LogicAnd:
 		return c.check(scalar.GetArgs()[0]) && c.check(scalar.GetArgs()[1])
@@ -130,6 +134,35 @@ func (c *conditionChecker) checkLikeFunc(scalar *expression.ScalarFunction) bool
 	return true
 }
 
+// checkCollation checks the comparison of the column whose index keys are the sort keys of its collation.
+// Only the equal conditions which compare the strings by the collation of the column can be
+// converted to ranges, because the sort keys of the values can't be converted back to the values.
+// The keys of the padding _bin collations are the strings without the trailing spaces, they keep the
+// order of the strings, so the other comparisons and "like" can be converted to ranges too, unless the
+// column is a prefix column. "like" doesn't ignore the trailing spaces, so it's reserved in the filters.
+func (c *conditionChecker) checkCollation(scalar *expression.ScalarFunction) bool {
+	switch scalar.FuncName.L {
+	case ast.LogicOr, ast.LogicAnd, ast.UnaryNot, ast.IsNull, ast.GetParam:
+		return true
+	}
+	for _, arg := range scalar.GetArgs() {
+		col, ok := arg.(*expression.Column)
+		if !ok || !c.checkColumn(col) || !expression.IsCollationKeyNeeded(col.RetType) {
+			continue
+		}
+		if expression.IsPaddingCollationKey(col.RetType) && c.length == types.UnspecifiedLength {
+			if scalar.FuncName.L == ast.Like {
+				c.shouldReserve = true
+			}
+		} else if scalar.FuncName.L != ast.EQ && scalar.FuncName.L != ast.In {
+			return false
+		}
+		coll := expression.DeriveCollationFromExprs(scalar.GetArgs())
+		return collate.GetCollator(coll) == collate.GetCollator(col.RetType.Collate)
+	}
+	return true
+}
+
 func (c *conditionChecker) checkColumn(expr expression.Expression) bool {
 	col, ok := expr.(*expression.Column)
 	if !ok {
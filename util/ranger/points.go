@@ -14,9 +14,11 @@
 package ranger
 
 import (
+	"bytes"
 	"fmt"
 	"math"
 	"sort"
+	"strings"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
@@ -137,6 +139,24 @@ func FullNewRange() []*NewRange {
 type builder struct {
 	err error
 	sc  *stmtctx.StatementContext
+	// trimPadding is set when the points are built for an index column of a padding _bin collation,
+	// whose index keys are the strings without the trailing spaces.
+	trimPadding bool
+}
+
+// paddingKey trims the trailing spaces of the string when the points are built for the keys of
+// a padding _bin collation, the order of the keys is the order of the strings by the collation.
+func (r *builder) paddingKey(d types.Datum) types.Datum {
+	if !r.trimPadding {
+		return d
+	}
+	switch d.Kind() {
+	case types.KindString:
+		d.SetString(strings.TrimRight(d.GetString(), " "))
+	case types.KindBytes:
+		d.SetBytes(bytes.TrimRight(d.GetBytes(), " "))
+	}
+	return d
 }
 
 func (r *builder) build(expr expression.Expression) []point {
@@ -215,6 +235,7 @@ func (r *builder) buildFormBinOp(expr *expression.ScalarFunction) []point {
 	if value.IsNull() {
 		return nil
 	}
+	value = r.paddingKey(value)
 
 	switch op {
 	case ast.EQ:
@@ -306,6 +327,7 @@ func (r *builder) buildFromIn(expr *expression.ScalarFunction) ([]point, bool) {
 			hasNull = true
 			continue
 		}
+		dt = r.paddingKey(dt)
 		startPoint := point{value: types.NewDatum(dt.GetValue()), start: true}
 		endPoint := point{value: types.NewDatum(dt.GetValue())}
 		rangePoints = append(rangePoints, startPoint, endPoint)
@@ -381,6 +403,12 @@ func (r *builder) newBuildFromPatternLike(expr *expression.ScalarFunction) []poi
 		}
 		lowValue = append(lowValue, pattern[i])
 	}
+	if r.trimPadding {
+		// The keys of the strings which start with "abc " are "abc" or start with "abc ",
+		// and the strings which are longer than the prefix may have the same keys as the prefix.
+		lowValue = bytes.TrimRight(lowValue, " ")
+		exclude = false
+	}
 	if len(lowValue) == 0 {
 		return []point{{value: types.MinNotNullDatum(), start: true}, {value: types.MaxValueDatum()}}
 	}
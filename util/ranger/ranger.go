@@ -271,6 +271,7 @@ func BuildIndexRange(sc *stmtctx.StatementContext, cols []*expression.Column, le
 			break
 		}
 		// Build ranges for equal or in access conditions.
+		rb.trimPadding = isPaddingKeyColumn(cols[eqAndInCount], lengths[eqAndInCount])
 		point := rb.build(accessCondition[eqAndInCount])
 		if rb.err != nil {
 			return nil, errors.Trace(rb.err)
@@ -285,6 +286,9 @@ func BuildIndexRange(sc *stmtctx.StatementContext, cols []*expression.Column, le
 		}
 	}
 	rangePoints := fullRange
+	if eqAndInCount < len(cols) {
+		rb.trimPadding = isPaddingKeyColumn(cols[eqAndInCount], lengths[eqAndInCount])
+	}
 	// Build rangePoints for non-equal access conditions.
 	for i := eqAndInCount; i < len(accessCondition); i++ {
 		rangePoints = rb.intersection(rangePoints, rb.build(accessCondition[i]))
@@ -305,6 +309,7 @@ func BuildIndexRange(sc *stmtctx.StatementContext, cols []*expression.Column, le
 	if hasPrefix(lengths) {
 		fixPrefixColRange(ranges, lengths)
 	}
+	convertCollationKeyRange(ranges, cols, lengths)
 
 	if len(ranges) > 0 && len(ranges[0].LowVal) < len(cols) {
 		for _, ran := range ranges {
@@ -347,6 +352,30 @@ func fixPrefixColRange(ranges []*NewRange, lengths []int) {
 	}
 }
 
+// convertCollationKeyRange converts the strings of the ranges to the sort keys of their collation,
+// which are encoded into the index keys. The points of the padding key columns are already keys.
+func convertCollationKeyRange(ranges []*NewRange, cols []*expression.Column, lengths []int) {
+	for _, ran := range ranges {
+		for i := 0; i < len(ran.LowVal) && i < len(cols); i++ {
+			if !isPaddingKeyColumn(cols[i], lengths[i]) {
+				ran.LowVal[i] = expression.CollationKeyDatum(ran.LowVal[i], cols[i].RetType)
+			}
+		}
+		for i := 0; i < len(ran.HighVal) && i < len(cols); i++ {
+			if !isPaddingKeyColumn(cols[i], lengths[i]) {
+				ran.HighVal[i] = expression.CollationKeyDatum(ran.HighVal[i], cols[i].RetType)
+			}
+		}
+	}
+}
+
+// isPaddingKeyColumn returns whether the points of the index column are built from the keys of its
+// padding _bin collation. The values of a prefix column are cut before they are converted to keys,
+// so its points are built from the values and converted after they are cut.
+func isPaddingKeyColumn(col *expression.Column, length int) bool {
+	return length == types.UnspecifiedLength && expression.IsPaddingCollationKey(col.RetType)
+}
+
 func fixRangeDatum(v *types.Datum, length int) {
 	// If this column is prefix and the prefix length is smaller than the range, cut it.
 	if length != types.UnspecifiedLength && length < len(v.GetBytes()) {
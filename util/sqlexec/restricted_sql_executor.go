@@ -52,3 +52,25 @@ type SQLExecutor interface {
 type SQLParser interface {
 	ParseSQL(sql, charset, collation string) ([]ast.StmtNode, error)
 }
+
+// RoutineExecutor is an interface provides running stored routines.
+// The stored routines run their statements in the session like the normal sql statements,
+// so the executor and the expression packages call them by this interface to break circle dependence.
+// This is implemented in procedure.go of the tidb package.
+type RoutineExecutor interface {
+	// CallProcedure runs the stored procedure of the CALL statement and returns its result sets.
+	CallProcedure(goCtx goctx.Context, call *ast.CallStmt) ([]ast.RecordSet, error)
+	// LoadFunction returns the stored function name of the current database, it returns nil if
+	// the function doesn't exist.
+	LoadFunction(name string) (StoredFunction, error)
+}
+
+// StoredFunction is a stored function which is ready to be called.
+type StoredFunction interface {
+	// ParamTypes returns the types of the parameters.
+	ParamTypes() []*types.FieldType
+	// RetType returns the type of the return value.
+	RetType() *types.FieldType
+	// Call runs the function with args, the result is converted to RetType.
+	Call(args []types.Datum) (types.Datum, error)
+}
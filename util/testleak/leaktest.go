@@ -48,6 +48,7 @@ func interestingGoroutines() (gs []string) {
 			strings.Contains(stack, "domain.NewDomain") ||
 			strings.Contains(stack, "testing.(*T).Run") ||
 			strings.Contains(stack, "domain.(*Domain).LoadPrivilegeLoop") ||
+			strings.Contains(stack, "domain.(*Domain).LoadUDFLoop") ||
 			strings.Contains(stack, "domain.(*Domain).UpdateTableStatsLoop") ||
 			strings.Contains(stack, "testing.Main(") ||
 			strings.Contains(stack, "runtime.goexit") ||
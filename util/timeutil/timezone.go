@@ -0,0 +1,256 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package timeutil
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	// The tz database is embedded, so the named time zones work on the hosts without zoneinfo.
+	_ "time/tzdata"
+
+	"github.com/juju/errors"
+)
+
+// offsetRegex matches the time zones given as the offsets from UTC, such as '+10:00' or '-6:00'.
+var offsetRegex = regexp.MustCompile(`^([+-])(\d{1,2}):(\d{2})$`)
+
+// The offsets range from '-12:59' to '+13:00' like MySQL.
+const (
+	maxPositiveOffset = 13 * 60 * 60
+	maxNegativeOffset = (12*60 + 59) * 60
+)
+
+var (
+	loadedLocationsMu sync.RWMutex
+	// loadedLocations are the time zones loaded from the mysql.time_zone* tables, the keys are
+	// the lower case names. They take precedence over the embedded tz database.
+	loadedLocations map[string]*time.Location
+)
+
+// SetLoadedLocations replaces the time zones loaded from the mysql.time_zone* tables.
+func SetLoadedLocations(locs map[string]*time.Location) {
+	lowered := make(map[string]*time.Location, len(locs))
+	for name, loc := range locs {
+		lowered[strings.ToLower(name)] = loc
+	}
+	loadedLocationsMu.Lock()
+	loadedLocations = lowered
+	loadedLocationsMu.Unlock()
+}
+
+// LoadLocation returns the time zone of the name, such as 'America/New_York'. The time zones
+// loaded from the mysql.time_zone* tables are looked up first, then the tz database.
+func LoadLocation(name string) (*time.Location, error) {
+	loadedLocationsMu.RLock()
+	loc, ok := loadedLocations[strings.ToLower(name)]
+	loadedLocationsMu.RUnlock()
+	if ok {
+		return loc, nil
+	}
+	// time.LoadLocation treats them as UTC and the local time zone, they aren't names of the tz database.
+	if name == "" || name == "Local" {
+		return nil, errors.Errorf("unknown time zone %s", name)
+	}
+	loc, err := time.LoadLocation(name)
+	return loc, errors.Trace(err)
+}
+
+// ParseTimeZone parses the value of the time_zone variable and the arguments of CONVERT_TZ.
+// The value can be 'SYSTEM', an offset from UTC such as '+10:00', or a named time zone.
+func ParseTimeZone(s string) (*time.Location, error) {
+	if strings.EqualFold(s, "SYSTEM") {
+		// TODO: Support global time_zone variable, it should be set to global time_zone value.
+		return time.Local, nil
+	}
+	if m := offsetRegex.FindStringSubmatch(s); m != nil {
+		hour, err := strconv.Atoi(m[2])
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		minute, err := strconv.Atoi(m[3])
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		ofst := hour*60*60 + minute*60
+		if minute > 59 || (m[1] == "+" && ofst > maxPositiveOffset) || (m[1] == "-" && ofst > maxNegativeOffset) {
+			return nil, errors.Errorf("unknown time zone %s", s)
+		}
+		if m[1] == "-" {
+			ofst = -ofst
+		}
+		return time.FixedZone("UTC", ofst), nil
+	}
+	return LoadLocation(s)
+}
+
+// IsFixedZone returns whether the offset of the time zone never changes, such as UTC and the time
+// zones of time.FixedZone. The time zones which have any transitions aren't fixed.
+func IsFixedZone(loc *time.Location) bool {
+	start, end := time.Unix(0, 0).In(loc).ZoneBounds()
+	return start.IsZero() && end.IsZero()
+}
+
+// TransitionType is a local time type of a time zone, it's a row of the mysql.time_zone_transition_type table.
+type TransitionType struct {
+	ID uint32
+	// Offset is the offset from UTC in seconds.
+	Offset       int32
+	IsDST        bool
+	Abbreviation string
+}
+
+// Transition is the time since when a local time type is used, it's a row of the mysql.time_zone_transition table.
+type Transition struct {
+	// Time is the Unix time of the transition.
+	Time   int64
+	TypeID uint32
+}
+
+// NewLocation builds the time zone from its local time types and transitions.
+func NewLocation(name string, types []TransitionType, transitions []Transition) (*time.Location, error) {
+	// The transition types and the abbreviations are indexed by bytes.
+	if len(types) == 0 || len(types) > 256 {
+		return nil, errors.Errorf("time zone %s has %d transition types", name, len(types))
+	}
+	typeIdx := make(map[uint32]int, len(types))
+	var abbrevs []byte
+	abbrevIdx := make([]int, len(types))
+	for i, tp := range types {
+		typeIdx[tp.ID] = i
+		if len(abbrevs) > 255 {
+			return nil, errors.Errorf("time zone %s has too many abbreviations", name)
+		}
+		abbrevIdx[i] = len(abbrevs)
+		abbrevs = append(abbrevs, tp.Abbreviation...)
+		abbrevs = append(abbrevs, 0)
+	}
+	sorted := make([]Transition, len(transitions))
+	copy(sorted, transitions)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Time < sorted[j].Time })
+	for _, tr := range sorted {
+		if _, ok := typeIdx[tr.TypeID]; !ok {
+			return nil, errors.Errorf("time zone %s has no transition type %d", name, tr.TypeID)
+		}
+	}
+
+	// The time zone is encoded in the version 2 TZif format, see RFC 8536. The version 1 data block
+	// is left empty because it's skipped by the readers of version 2.
+	var data []byte
+	appendHeader := func(timeCnt, typeCnt, charCnt int) {
+		data = append(data, "TZif2"...)
+		data = append(data, make([]byte, 15)...)
+		for _, cnt := range []int{0, 0, 0, timeCnt, typeCnt, charCnt} {
+			data = appendUint32(data, uint32(cnt))
+		}
+	}
+	appendHeader(0, 0, 0)
+	appendHeader(len(sorted), len(types), len(abbrevs))
+	for _, tr := range sorted {
+		data = appendUint32(data, uint32(uint64(tr.Time)>>32))
+		data = appendUint32(data, uint32(tr.Time))
+	}
+	for _, tr := range sorted {
+		data = append(data, byte(typeIdx[tr.TypeID]))
+	}
+	for i, tp := range types {
+		data = appendUint32(data, uint32(tp.Offset))
+		if tp.IsDST {
+			data = append(data, 1)
+		} else {
+			data = append(data, 0)
+		}
+		data = append(data, byte(abbrevIdx[i]))
+	}
+	data = append(data, abbrevs...)
+	loc, err := time.LoadLocationFromTZData(name, data)
+	return loc, errors.Trace(err)
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	return append(b, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+// maxTransitionTime is the max time of the transitions of the embedded time zones, it's the max
+// TIMESTAMP. Like the rows written by mysql_tzinfo_to_sql, the later transitions are dropped.
+const maxTransitionTime = math.MaxInt32
+
+// TimeZoneInfo is a time zone in the form of the mysql.time_zone* tables.
+type TimeZoneInfo struct {
+	// Names are the names of the time zone, the links of the tz database share the time zone.
+	Names       []string
+	Types       []TransitionType
+	Transitions []Transition
+}
+
+// EmbeddedTimeZones returns the time zones of the embedded tz database, the mysql.time_zone*
+// tables are populated with them. The names which have the same transitions share a time zone.
+func EmbeddedTimeZones() []*TimeZoneInfo {
+	var infos []*TimeZoneInfo
+	infoByTransitions := make(map[string]*TimeZoneInfo)
+	for _, name := range zoneNames {
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			continue
+		}
+		info := newTimeZoneInfo(loc)
+		key := fmt.Sprintf("%v %v", info.Types, info.Transitions)
+		if shared, ok := infoByTransitions[key]; ok {
+			shared.Names = append(shared.Names, name)
+			continue
+		}
+		info.Names = []string{name}
+		infoByTransitions[key] = info
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// newTimeZoneInfo walks through the periods of the time zone, a transition is added when the local
+// time type changes. The type before the first transition is the first one.
+func newTimeZoneInfo(loc *time.Location) *TimeZoneInfo {
+	info := &TimeZoneInfo{}
+	typeIDs := make(map[TransitionType]uint32)
+	typeIDOf := func(t time.Time) uint32 {
+		abbrev, offset := t.Zone()
+		tp := TransitionType{Offset: int32(offset), IsDST: t.IsDST(), Abbreviation: abbrev}
+		id, ok := typeIDs[tp]
+		if !ok {
+			id = uint32(len(info.Types))
+			typeIDs[tp] = id
+			tp.ID = id
+			info.Types = append(info.Types, tp)
+		}
+		return id
+	}
+	t := time.Date(1, 1, 1, 0, 0, 0, 0, loc)
+	prevID := typeIDOf(t)
+	for {
+		_, end := t.ZoneBounds()
+		if end.IsZero() || end.Unix() > maxTransitionTime {
+			break
+		}
+		if id := typeIDOf(end); id != prevID {
+			info.Transitions = append(info.Transitions, Transition{Time: end.Unix(), TypeID: id})
+			prevID = id
+		}
+		t = end
+	}
+	return info
+}
@@ -0,0 +1,153 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package timeutil
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testTimeZoneSuite{})
+
+type testTimeZoneSuite struct {
+}
+
+func (s *testTimeZoneSuite) TestParseTimeZone(c *C) {
+	defer testleak.AfterTest(c)()
+	tests := []struct {
+		input  string
+		offset int
+	}{
+		{"+00:00", 0},
+		{"-00:00", 0},
+		{"+10:32", 10*3600 + 32*60},
+		{"-6:00", -6 * 3600},
+		{"+13:00", 13 * 3600},
+		{"-12:59", -(12*3600 + 59*60)},
+		{"America/New_York", -5 * 3600},
+		{"Asia/Shanghai", 8 * 3600},
+		{"UTC", 0},
+	}
+	for _, tt := range tests {
+		loc, err := ParseTimeZone(tt.input)
+		c.Assert(err, IsNil, Commentf("%s", tt.input))
+		_, offset := time.Date(2017, 1, 1, 0, 0, 0, 0, loc).Zone()
+		c.Assert(offset, Equals, tt.offset, Commentf("%s", tt.input))
+	}
+	loc, err := ParseTimeZone("system")
+	c.Assert(err, IsNil)
+	c.Assert(loc, Equals, time.Local)
+
+	for _, input := range []string{"", "Local", "6:00", "+13:01", "-13:00", "+10:82", "Mars/Olympus_Mons"} {
+		_, err = ParseTimeZone(input)
+		c.Assert(err, NotNil, Commentf("%s", input))
+	}
+}
+
+func (s *testTimeZoneSuite) TestIsFixedZone(c *C) {
+	defer testleak.AfterTest(c)()
+	// Asia/Shanghai observed the daylight saving time until 1991.
+	for _, name := range []string{"America/New_York", "Europe/Berlin", "Australia/Sydney", "Asia/Shanghai"} {
+		loc, err := ParseTimeZone(name)
+		c.Assert(err, IsNil)
+		c.Assert(IsFixedZone(loc), IsFalse, Commentf("%s", name))
+	}
+	for _, name := range []string{"UTC", "Etc/GMT+5", "+10:00", "-6:30"} {
+		loc, err := ParseTimeZone(name)
+		c.Assert(err, IsNil)
+		c.Assert(IsFixedZone(loc), IsTrue, Commentf("%s", name))
+	}
+	c.Assert(IsFixedZone(time.UTC), IsTrue)
+}
+
+func (s *testTimeZoneSuite) TestNewLocation(c *C) {
+	defer testleak.AfterTest(c)()
+	// A time zone which is 1 hour ahead of UTC, and 2 hours in the summer of 2017.
+	types := []TransitionType{
+		{ID: 0, Offset: 3600, Abbreviation: "TST"},
+		{ID: 1, Offset: 7200, IsDST: true, Abbreviation: "TDT"},
+	}
+	summer := time.Date(2017, 3, 26, 1, 0, 0, 0, time.UTC).Unix()
+	winter := time.Date(2017, 10, 29, 1, 0, 0, 0, time.UTC).Unix()
+	transitions := []Transition{{Time: winter, TypeID: 0}, {Time: summer, TypeID: 1}}
+	loc, err := NewLocation("Test/Zone", types, transitions)
+	c.Assert(err, IsNil)
+	c.Assert(loc.String(), Equals, "Test/Zone")
+
+	tests := []struct {
+		t      time.Time
+		abbrev string
+		offset int
+	}{
+		{time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC), "TST", 3600},
+		{time.Unix(summer-1, 0), "TST", 3600},
+		{time.Unix(summer, 0), "TDT", 7200},
+		{time.Date(2017, 7, 1, 0, 0, 0, 0, time.UTC), "TDT", 7200},
+		{time.Unix(winter, 0), "TST", 3600},
+	}
+	for _, tt := range tests {
+		abbrev, offset := tt.t.In(loc).Zone()
+		c.Assert(abbrev, Equals, tt.abbrev)
+		c.Assert(offset, Equals, tt.offset)
+	}
+
+	SetLoadedLocations(map[string]*time.Location{"Test/Zone": loc})
+	defer SetLoadedLocations(nil)
+	loaded, err := LoadLocation("test/zone")
+	c.Assert(err, IsNil)
+	c.Assert(loaded, Equals, loc)
+
+	_, err = NewLocation("Test/Zone", nil, nil)
+	c.Assert(err, NotNil)
+	_, err = NewLocation("Test/Zone", types, []Transition{{Time: summer, TypeID: 2}})
+	c.Assert(err, NotNil)
+}
+
+func (s *testTimeZoneSuite) TestEmbeddedTimeZones(c *C) {
+	defer testleak.AfterTest(c)()
+	infos := EmbeddedTimeZones()
+	var newYork *TimeZoneInfo
+	for _, info := range infos {
+		for _, name := range info.Names {
+			if name == "America/New_York" {
+				newYork = info
+			}
+		}
+	}
+	c.Assert(newYork, NotNil)
+	// The links share the time zone.
+	c.Assert(newYork.Names, DeepEquals, []string{"America/New_York", "US/Eastern"})
+
+	// The time zone which is built from the transitions agrees with the tz database in the range of TIMESTAMP.
+	loc, err := NewLocation("America/New_York", newYork.Types, newYork.Transitions)
+	c.Assert(err, IsNil)
+	expected, err := time.LoadLocation("America/New_York")
+	c.Assert(err, IsNil)
+	for t := time.Unix(0, 0); t.Unix() <= maxTransitionTime; t = t.Add(6 * time.Hour) {
+		name, offset := t.In(loc).Zone()
+		expectedName, expectedOffset := t.In(expected).Zone()
+		c.Assert(name, Equals, expectedName, Commentf("%v", t))
+		c.Assert(offset, Equals, expectedOffset, Commentf("%v", t))
+	}
+	last := newYork.Transitions[len(newYork.Transitions)-1]
+	c.Assert(last.Time <= maxTransitionTime, IsTrue)
+}